@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"path/filepath"
@@ -105,11 +104,26 @@ func (mApp *DrepApp) Run() error {
 	mApp.Flags = append(mApp.Flags, ConfigFileFlag)
 	mApp.Flags = append(mApp.Flags, HomeDirFlag)
 	mApp.Flags = append(mApp.Flags, PprofFlag)
+	mApp.Flags = append(mApp.Flags, MetricsFlag)
+	mApp.Flags = append(mApp.Flags, MetricsAddrFlag)
+	mApp.Flags = append(mApp.Flags, CacheFlag)
+	mApp.Flags = append(mApp.Flags, CacheTrieFlag)
+	mApp.Flags = append(mApp.Flags, ShutdownTimeoutFlag)
 
 	allCommands, allFlags := mApp.Context.AggerateFlags()
 	for i := 0; i < len(allCommands); i++ {
 		allCommands[i].Flags = append(allCommands[i].Flags, allFlags...)
-		allCommands[i].Action = mApp.action
+		//commands with their own action run standalone without starting services
+		if allCommands[i].Action == nil && len(allCommands[i].Subcommands) == 0 {
+			allCommands[i].Action = mApp.action
+		}
+		for j := range allCommands[i].Subcommands {
+			subcommand := &allCommands[i].Subcommands[j]
+			if subcommand.Action == nil {
+				subcommand.Flags = append(subcommand.Flags, allFlags...)
+				subcommand.Action = mApp.action
+			}
+		}
 	}
 	mApp.Flags = append(mApp.Flags, allFlags...)
 	mApp.App.Commands = allCommands
@@ -127,13 +141,7 @@ func (mApp *DrepApp) action(ctx *cli.Context) error {
 			debug.PrintStack()
 			fmt.Println("app action err", err)
 		}
-		length := len(mApp.Context.Services)
-		for i := length; i > 0; i-- {
-			err := mApp.Context.Services[i-1].Stop(mApp.Context)
-			if err != nil {
-				return
-			}
-		}
+		mApp.shutdown(shutdownTimeout(ctx))
 	}()
 	mApp.Context.Cli = ctx //NOTE this set is for different commmands-\\
 	endIndex := len(mApp.Context.Services)
@@ -185,12 +193,23 @@ func (mApp *DrepApp) action(ctx *cli.Context) error {
 			return err
 		}
 	}
+	for _, service := range mApp.Context.Services {
+		if started, ok := service.(StartedService); ok {
+			started.Started(mApp.Context)
+		}
+	}
 	exit := make(chan struct{})
 	exitSignal(exit)
 	select {
 	case <-exit:
 	case <-mApp.Context.Quit:
 	}
+	// a second signal skips the graceful shutdown
+	go func() {
+		<-exit
+		fmt.Println("interrupted again, exit without graceful shutdown")
+		os.Exit(1)
+	}()
 	return nil
 }
 func (mApp *DrepApp) parserConfig(service Service) error {
@@ -242,13 +261,13 @@ func (mApp *DrepApp) before(ctx *cli.Context) error {
 	}
 	mApp.Context.PhaseConfig = phaseConfig
 
-	if ctx.GlobalIsSet(PprofFlag.Name) {
-		go func() {
-			fmt.Println("http://localhost:8080/debug/pprof")
-			http.ListenAndServe("0.0.0.0:8080", nil)
-		}()
+	SetCacheBudget(ctx.GlobalInt(CacheFlag.Name))
+	if ctx.GlobalIsSet(CacheTrieFlag.Name) {
+		SetTrieShare(ctx.GlobalInt(CacheTrieFlag.Name))
 	}
 
+	setupMetrics(ctx)
+
 	return nil
 }
 
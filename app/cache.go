@@ -0,0 +1,119 @@
+package app
+
+import (
+	"sort"
+	"sync"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+// names of the subsystems sharing the cache budget
+const (
+	CacheDatabase  = "database"
+	CacheTrie      = "trie"
+	CacheTxPool    = "txpool"
+	CacheOrphans   = "orphans"
+	CacheKnownHash = "knownhash"
+
+	// DefaultCache is the default megabytes of memory allocated to internal caching
+	DefaultCache = 256
+)
+
+var (
+	// CacheFlag set the megabytes of memory shared by database, trie, txpool, orphan pool and known hash caches
+	CacheFlag = cli.IntFlag{
+		Name:  "cache",
+		Usage: "Megabytes of memory allocated to internal caching",
+		Value: DefaultCache,
+	}
+
+	// CacheTrieFlag move cache between trie nodes and database blocks, which share 65 percent
+	CacheTrieFlag = cli.IntFlag{
+		Name:  "cache.trie",
+		Usage: "Percentage of the cache allocated to state trie nodes, taken from or given to database blocks",
+	}
+
+	// cacheShares is the percentage of budget allocated to each subsystem
+	cacheShares = map[string]int{
+		CacheDatabase:  40,
+		CacheTrie:      25,
+		CacheTxPool:    15,
+		CacheOrphans:   15,
+		CacheKnownHash: 5,
+	}
+
+	cacheLock   sync.RWMutex
+	cacheBudget = DefaultCache
+	cacheUsages = map[string]func() int64{}
+)
+
+// CacheStat is the memory budget and usage of one subsystem
+type CacheStat struct {
+	Name   string `json:"name"`
+	Budget int64  `json:"budget"` // bytes
+	Used   int64  `json:"used"`   // bytes
+}
+
+// SetCacheBudget set the total cache budget in megabytes
+func SetCacheBudget(megabytes int) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	if megabytes > 0 {
+		cacheBudget = megabytes
+	}
+}
+
+// SetTrieShare set the percentage of the budget allocated to the trie, the database gets
+// what is left of their joint share
+func SetTrieShare(percent int) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	joint := cacheShares[CacheDatabase] + cacheShares[CacheTrie]
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > joint {
+		percent = joint
+	}
+	cacheShares[CacheTrie] = percent
+	cacheShares[CacheDatabase] = joint - percent
+}
+
+// CacheAllowance return the bytes of cache allocated to the subsystem
+func CacheAllowance(name string) int64 {
+	cacheLock.RLock()
+	defer cacheLock.RUnlock()
+	return int64(cacheBudget) * int64(cacheShares[name]) / 100 * 1024 * 1024
+}
+
+// RegisterCacheUsage register a function reporting the bytes used by the subsystem
+func RegisterCacheUsage(name string, usage func() int64) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	cacheUsages[name] = usage
+}
+
+// CacheUsage report budget and usage of all subsystems
+func CacheUsage() []*CacheStat {
+	cacheLock.RLock()
+	names := make([]string, 0, len(cacheShares))
+	for name := range cacheShares {
+		names = append(names, name)
+	}
+	usages := make(map[string]func() int64, len(cacheUsages))
+	for name, usage := range cacheUsages {
+		usages[name] = usage
+	}
+	cacheLock.RUnlock()
+
+	sort.Strings(names)
+	stats := make([]*CacheStat, 0, len(names))
+	for _, name := range names {
+		stat := &CacheStat{Name: name, Budget: CacheAllowance(name)}
+		if usage, ok := usages[name]; ok {
+			stat.Used = usage()
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
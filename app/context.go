@@ -138,6 +138,12 @@ func (econtext *ExecuteContext) GetConfig(phaseName string) json.RawMessage {
 
 }
 
+// ReadConfig reads the config file again, for services that apply config changes while running.
+// The phase configs read at startup are left untouched.
+func (econtext *ExecuteContext) ReadConfig() (map[string]json.RawMessage, error) {
+	return loadConfigFile(econtext.Cli, econtext.CommonConfig.HomeDir)
+}
+
 // FlatConfig marshal json config to map
 func (econtext *ExecuteContext) FlatConfig(phaseName string) error {
 	phaseConfig, ok := econtext.PhaseConfig[phaseName]
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stages of entering maintenance mode, the hooks of a stage run after all hooks of the stages before
+const (
+	MaintenanceRefuse = iota // stop accepting new work, e.g. inbound peers
+	MaintenanceDrain         // wait for the work in flight, e.g. block imports
+	MaintenanceFlush         // write buffered data to disk
+)
+
+var (
+	// ErrMaintenance is returned for the work refused in maintenance mode
+	ErrMaintenance = errors.New("node in maintenance mode")
+)
+
+// MaintenanceStatus report whether the node is in maintenance mode, and once all hooks have
+// run whether it is safe to stop the process
+type MaintenanceStatus struct {
+	On      bool     `json:"on"`
+	Safe    bool     `json:"safe"`
+	Since   int64    `json:"since,omitempty"`   // unix time maintenance mode was entered
+	Pending []string `json:"pending,omitempty"` // hooks not run yet
+	Err     string   `json:"err,omitempty"`     // error of the hook entering failed at
+}
+
+type maintenanceHook struct {
+	name  string
+	stage int
+	enter func() error
+	exit  func()
+}
+
+var (
+	maintenanceLock   sync.RWMutex
+	maintenanceHooks  []*maintenanceHook
+	maintenanceStatus = &MaintenanceStatus{}
+	// maintenanceRun serialize entering and leaving, so exit hooks never run while enter ones are running
+	maintenanceRun sync.Mutex
+)
+
+// RegisterMaintenance add the hooks of a subsystem run when maintenance mode is entered and left.
+// enter is run at its stage, exit is run when maintenance mode is left, both can be nil
+func RegisterMaintenance(name string, stage int, enter func() error, exit func()) {
+	maintenanceLock.Lock()
+	defer maintenanceLock.Unlock()
+	maintenanceHooks = append(maintenanceHooks, &maintenanceHook{name: name, stage: stage, enter: enter, exit: exit})
+	sort.SliceStable(maintenanceHooks, func(i, j int) bool {
+		return maintenanceHooks[i].stage < maintenanceHooks[j].stage
+	})
+}
+
+// InMaintenance report whether maintenance mode is on, new rpc writes and block imports are refused then
+func InMaintenance() bool {
+	maintenanceLock.RLock()
+	defer maintenanceLock.RUnlock()
+	return maintenanceStatus.On
+}
+
+// Maintenance return the current maintenance status
+func Maintenance() *MaintenanceStatus {
+	maintenanceLock.RLock()
+	defer maintenanceLock.RUnlock()
+	status := *maintenanceStatus
+	status.Pending = append([]string{}, maintenanceStatus.Pending...)
+	return &status
+}
+
+// SetMaintenance enter or leave maintenance mode. The hooks run in background, the status
+// turns safe when all enter hooks have run
+func SetMaintenance(on bool) *MaintenanceStatus {
+	maintenanceLock.Lock()
+	if maintenanceStatus.On == on {
+		maintenanceLock.Unlock()
+		return Maintenance()
+	}
+	hooks := append([]*maintenanceHook{}, maintenanceHooks...)
+	maintenanceStatus = &MaintenanceStatus{On: on}
+	if on {
+		maintenanceStatus.Since = time.Now().Unix()
+		for _, hook := range hooks {
+			maintenanceStatus.Pending = append(maintenanceStatus.Pending, hook.name)
+		}
+	}
+	status := maintenanceStatus
+	maintenanceLock.Unlock()
+
+	if on {
+		go enterMaintenance(status, hooks)
+	} else {
+		go exitMaintenance(hooks)
+	}
+	return Maintenance()
+}
+
+func enterMaintenance(status *MaintenanceStatus, hooks []*maintenanceHook) {
+	maintenanceRun.Lock()
+	defer maintenanceRun.Unlock()
+	for _, hook := range hooks {
+		// left, or left and entered again, before this hook was reached
+		maintenanceLock.RLock()
+		current := maintenanceStatus == status
+		maintenanceLock.RUnlock()
+		if !current {
+			return
+		}
+		if hook.enter != nil {
+			if err := hook.enter(); err != nil {
+				maintenanceLock.Lock()
+				status.Err = hook.name + ": " + err.Error()
+				maintenanceLock.Unlock()
+				return
+			}
+		}
+		maintenanceLock.Lock()
+		status.Pending = status.Pending[1:]
+		maintenanceLock.Unlock()
+	}
+	maintenanceLock.Lock()
+	status.Safe = true
+	maintenanceLock.Unlock()
+}
+
+func exitMaintenance(hooks []*maintenanceHook) {
+	maintenanceRun.Lock()
+	defer maintenanceRun.Unlock()
+	// entered again, its hooks take over
+	if InMaintenance() {
+		return
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].exit != nil {
+			hooks[i].exit()
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/metrics/exp"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// DefaultMetricsAddr is the default listen address of the debug http server
+const DefaultMetricsAddr = "0.0.0.0:8080"
+
+var (
+	// MetricsFlag enable metrics collection, exported at /metrics in prometheus format and at /debug/metrics.
+	// The metrics package also checks os.Args for this flag at init, so that meters declared as
+	// package variables are live from the start.
+	MetricsFlag = cli.BoolFlag{
+		Name:  "metrics",
+		Usage: "Enable metrics collection and reporting",
+	}
+	// MetricsAddrFlag set the listen address of the http server exporting metrics and pprof
+	MetricsAddrFlag = cli.StringFlag{
+		Name:  "metrics.addr",
+		Usage: "Listen address of the metrics and pprof http server",
+		Value: DefaultMetricsAddr,
+	}
+)
+
+// setupMetrics enable the metrics registry and start the debug http server if requested
+func setupMetrics(ctx *cli.Context) {
+	if ctx.GlobalBool(MetricsFlag.Name) {
+		metrics.Enabled = true
+		exp.Exp(metrics.DefaultRegistry)
+		http.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+	}
+
+	if ctx.GlobalIsSet(PprofFlag.Name) || ctx.GlobalBool(MetricsFlag.Name) {
+		addr := ctx.GlobalString(MetricsAddrFlag.Name)
+		go func() {
+			fmt.Println("http://" + addr + "/debug/pprof")
+			if err := http.ListenAndServe(addr, nil); err != nil {
+				fmt.Println("debug http server err:", err)
+			}
+		}()
+	}
+}
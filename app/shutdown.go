@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+// stages of shutdown, the hooks of a stage run after all hooks of the stages before and all hooks
+// run before the services are stopped in reverse order, the databases last
+const (
+	ShutdownRefuse = iota // stop accepting new work, e.g. rpc calls
+	ShutdownDrain         // finish the work in flight, e.g. queued broadcasts, the block in production
+	ShutdownFlush         // write the state kept in memory, e.g. block index and trie
+)
+
+// DefaultShutdownTimeout is the default seconds the shutdown may take before the process is killed
+const DefaultShutdownTimeout = 60
+
+var (
+	ShutdownTimeoutFlag = cli.IntFlag{
+		Name:  "shutdown.timeout",
+		Usage: "Seconds the graceful shutdown may take before the process exits anyway, 0 waits forever",
+		Value: DefaultShutdownTimeout,
+	}
+
+	shutdownLock  sync.Mutex
+	shutdownHooks []*shutdownHook
+)
+
+type shutdownHook struct {
+	name  string
+	stage int
+	run   func() error
+}
+
+// RegisterShutdown add a hook of a subsystem run at its stage of the shutdown, the hooks of one
+// stage run in registration order
+func RegisterShutdown(name string, stage int, run func() error) {
+	shutdownLock.Lock()
+	defer shutdownLock.Unlock()
+	shutdownHooks = append(shutdownHooks, &shutdownHook{name: name, stage: stage, run: run})
+	sort.SliceStable(shutdownHooks, func(i, j int) bool {
+		return shutdownHooks[i].stage < shutdownHooks[j].stage
+	})
+}
+
+// shutdown run the shutdown hooks then stop the services. A failing hook or service is reported
+// and the next ones still run, so the databases are always closed. When it takes longer than
+// timeout the step it hangs at is reported and the process exits
+func (mApp *DrepApp) shutdown(timeout time.Duration) {
+	var (
+		stepLock sync.Mutex
+		step     string
+	)
+	setStep := func(name string) {
+		stepLock.Lock()
+		step = name
+		stepLock.Unlock()
+	}
+	done := make(chan struct{})
+	defer close(done)
+	if timeout > 0 {
+		go func() {
+			select {
+			case <-done:
+			case <-time.After(timeout):
+				stepLock.Lock()
+				fmt.Printf("shutdown timeout after %v at %s, exit anyway\n", timeout, step)
+				stepLock.Unlock()
+				os.Exit(1)
+			}
+		}()
+	}
+
+	shutdownLock.Lock()
+	hooks := append([]*shutdownHook{}, shutdownHooks...)
+	shutdownLock.Unlock()
+	for _, hook := range hooks {
+		setStep("shutdown hook " + hook.name)
+		if err := hook.run(); err != nil {
+			fmt.Println("shutdown hook", hook.name, "err", err)
+		}
+	}
+
+	services := mApp.Context.Services
+	for i := len(services) - 1; i >= 0; i-- {
+		setStep("stop service " + services[i].Name())
+		if err := services[i].Stop(mApp.Context); err != nil {
+			fmt.Println("stop service", services[i].Name(), "err", err)
+		}
+	}
+}
+
+// shutdownTimeout return the time the shutdown may take, 0 for no limit
+func shutdownTimeout(ctx *cli.Context) time.Duration {
+	if ctx == nil || !ctx.GlobalIsSet(ShutdownTimeoutFlag.Name) {
+		return DefaultShutdownTimeout * time.Second
+	}
+	return time.Duration(ctx.GlobalInt(ShutdownTimeoutFlag.Name)) * time.Second
+}
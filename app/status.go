@@ -0,0 +1,55 @@
+package app
+
+import (
+	"sync"
+)
+
+// NodeStatus summarize the configuration of a running node, so operators and fleet tooling
+// can compare nodes and spot configuration drift. Every service reports its own section
+type NodeStatus struct {
+	GitCommit string                 `json:"gitCommit,omitempty"`
+	Services  []string               `json:"services"` // services in start order
+	Sections  map[string]interface{} `json:"sections"` // service name -> status reported by the service
+}
+
+var (
+	statusLock    sync.RWMutex
+	statusReports = make(map[string]func() interface{})
+)
+
+// StartedService is implemented by services that act once every service has started
+type StartedService interface {
+	Started(executeContext *ExecuteContext)
+}
+
+// RegisterStatus register a function reporting the status section of a service
+func RegisterStatus(name string, report func() interface{}) {
+	statusLock.Lock()
+	defer statusLock.Unlock()
+	statusReports[name] = report
+}
+
+// Status collect the sections of all services
+func (econtext *ExecuteContext) Status() *NodeStatus {
+	status := &NodeStatus{
+		GitCommit: econtext.GitCommit,
+		Services:  make([]string, 0, len(econtext.Services)),
+		Sections:  make(map[string]interface{}),
+	}
+	for _, service := range econtext.Services {
+		status.Services = append(status.Services, service.Name())
+	}
+
+	// reports may take locks of their service, they are run without holding the registry
+	statusLock.RLock()
+	reports := make(map[string]func() interface{}, len(statusReports))
+	for name, report := range statusReports {
+		reports[name] = report
+	}
+	statusLock.RUnlock()
+
+	for name, report := range reports {
+		status.Sections[name] = report()
+	}
+	return status
+}
@@ -0,0 +1,264 @@
+package blockmgr
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	announceFetchTimeout = 5 * time.Second //A body requested from announce is requested again from other peer after timeout
+)
+
+// announceFetcher records block bodies or transactions requested by announce, so that one
+// body is not requested from every peer which announce it.
+type announceFetcher struct {
+	lock     sync.Mutex
+	fetching map[crypto.Hash]time.Time
+}
+
+// tryFetch return true if the body should be requested now
+func (fetcher *announceFetcher) tryFetch(hash crypto.Hash) bool {
+	return len(fetcher.tryFetchAll([]crypto.Hash{hash})) > 0
+}
+
+// tryFetchAll return the hashes whose bodies should be requested now
+func (fetcher *announceFetcher) tryFetchAll(hashes []crypto.Hash) []crypto.Hash {
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+
+	if fetcher.fetching == nil {
+		fetcher.fetching = make(map[crypto.Hash]time.Time)
+	}
+	now := time.Now()
+	for h, reqTime := range fetcher.fetching {
+		if now.Sub(reqTime) > announceFetchTimeout {
+			delete(fetcher.fetching, h)
+		}
+	}
+	fetch := make([]crypto.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if _, ok := fetcher.fetching[hash]; ok {
+			continue
+		}
+		fetcher.fetching[hash] = now
+		fetch = append(fetch, hash)
+	}
+	return fetch
+}
+
+// done remove the body from fetching list once it arrive
+func (fetcher *announceFetcher) done(hash crypto.Hash) {
+	fetcher.lock.Lock()
+	defer fetcher.lock.Unlock()
+
+	delete(fetcher.fetching, hash)
+}
+
+// BroadcastBlock sends the full block to sqrt(peers) and announces the header to the rest,
+// peers received the announcement request the body if they lack it.
+// Non-local blocks are only relayed to 2/3 of peers.
+func (blockMgr *BlockMgr) BroadcastBlock(msgType int32, block *types.Block, isLocal bool) {
+	peers := []types.PeerInfoInterface{}
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		peer := value.(types.PeerInfoInterface)
+		if peer.KnownBlock(block) {
+			return true
+		}
+		if !peer.MatchBlock(block) {
+			//Light peer not interested in any tx of this block
+			return true
+		}
+		if !isLocal && rand.Intn(broadcastRatio) > 1 {
+			return true
+		}
+		peers = append(peers, peer)
+		return true
+	})
+	if len(peers) == 0 {
+		return
+	}
+
+	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	bodyCount := int(math.Sqrt(float64(len(peers))))
+	if bodyCount < 1 {
+		bodyCount = 1
+	}
+
+	// encode the block and the announcement once for all peers
+	body, err := p2p.EncodePayload(block)
+	if err != nil {
+		log.WithField("hash", block.Header.Hash()).WithField("err", err).Error("encode broadcast block")
+		return
+	}
+	announce, err := p2p.EncodePayload(&types.BlockAnnounce{Header: *block.Header})
+	if err != nil {
+		log.WithField("hash", block.Header.Hash()).WithField("err", err).Error("encode block announce")
+		return
+	}
+	for i, peer := range peers {
+		peer.MarkBlock(block)
+		if i < bodyCount || !types.MsgSupported(peer.Version(), types.MsgTypeBlockAnnounce) {
+			blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(msgType), body)
+		} else {
+			blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlockAnnounce, announce)
+		}
+	}
+}
+
+// handleBlockAnnounce request the body of announced block if it is unknown
+func (blockMgr *BlockMgr) handleBlockAnnounce(peer types.PeerInfoInterface, announce *types.BlockAnnounce) {
+	hash := announce.Header.Hash()
+	peer.MarkBlock(&types.Block{Header: &announce.Header})
+
+	if blockMgr.ChainService.BlockExists(hash) || blockMgr.ChainService.IsKnownOrphan(hash) {
+		return
+	}
+	if !types.MsgSupported(peer.Version(), types.MsgTypeBlockBodyReq) {
+		return
+	}
+	if !blockMgr.fetcher.tryFetch(*hash) {
+		return
+	}
+	log.WithField("height", announce.Header.Height).WithField("hash", hash).WithField("peer", peer.GetAddr()).Trace("request announced block")
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlockBodyReq, &types.BlockBodyReq{Hashes: []crypto.Hash{*hash}})
+}
+
+// handleBlockBodyReq reply the requested blocks one by one
+func (blockMgr *BlockMgr) handleBlockBodyReq(peer types.PeerInfoInterface, req *types.BlockBodyReq) {
+	if len(req.Hashes) > maxBlockCountReq {
+		req.Hashes = req.Hashes[:maxBlockCountReq]
+	}
+	for _, hash := range req.Hashes {
+		hash := hash
+		block, err := blockMgr.chainStore.GetBlock(&hash)
+		if err != nil {
+			log.WithField("hash", hash).WithField("Reason", err).Debug("requested block not found")
+			continue
+		}
+		peer.MarkBlock(block)
+		blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlock, block)
+	}
+}
+
+// BroadcastTx sends the full transaction to sqrt(peers) and announces its hash to the rest,
+// peers received the announcement request the transaction if they lack it. Peers running a
+// protocol older than ProtocolV2 always receive the full transaction.
+// Non-local transactions are only relayed to 2/3 of peers.
+func (blockMgr *BlockMgr) BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool) {
+	atomic.AddInt64(&blockMgr.broadcasts, 1)
+	go func() {
+		defer atomic.AddInt64(&blockMgr.broadcasts, -1)
+		peers := []types.PeerInfoInterface{}
+		blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+			peer := value.(types.PeerInfoInterface)
+			if !peer.MatchTx(tx) {
+				//Light peer not interested in the addresses of this tx
+				return true
+			}
+			if peer.KnownTx(tx) {
+				return true
+			}
+			if !isLocal && rand.Intn(broadcastRatio) > 1 {
+				return true
+			}
+			peers = append(peers, peer)
+			return true
+		})
+		if len(peers) == 0 {
+			return
+		}
+
+		rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+		bodyCount := int(math.Sqrt(float64(len(peers))))
+		if bodyCount < 1 {
+			bodyCount = 1
+		}
+
+		// encode the tx and the announcement once for all peers
+		body, err := p2p.EncodePayload([]*types.Transaction{tx})
+		if err != nil {
+			log.WithField("err", err).Error("encode broadcast tx")
+			return
+		}
+		announce, err := p2p.EncodePayload(&types.TxAnnounce{Hashes: []crypto.Hash{*tx.TxHash()}})
+		if err != nil {
+			log.WithField("err", err).Error("encode tx announce")
+			return
+		}
+		for i, peer := range peers {
+			peer.MarkTx(tx)
+			if i < bodyCount || !types.MsgSupported(peer.Version(), types.MsgTypeTxAnnounce) {
+				blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(msgType), body)
+			} else {
+				blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTxAnnounce, announce)
+			}
+		}
+	}()
+}
+
+// handleTxAnnounce request the announced transactions which are not in pool
+func (blockMgr *BlockMgr) handleTxAnnounce(peer types.PeerInfoInterface, announce *types.TxAnnounce) {
+	if len(announce.Hashes) > maxTxsCount {
+		announce.Hashes = announce.Hashes[:maxTxsCount]
+	}
+	wanted := make([]crypto.Hash, 0, len(announce.Hashes))
+	for _, hash := range announce.Hashes {
+		hash := hash
+		peer.MarkTxHash(&hash)
+		if _, err := blockMgr.transactionPool.GetTxInPool(hash.String()); err == nil {
+			continue
+		}
+		wanted = append(wanted, hash)
+	}
+	wanted = blockMgr.txFetcher.tryFetchAll(wanted)
+	if len(wanted) == 0 {
+		return
+	}
+	log.WithField("count", len(wanted)).WithField("peer", peer.GetAddr()).Trace("request announced txs")
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTxReq, &types.TxReq{Hashes: wanted})
+}
+
+// handleTxReq reply the requested transactions still in pool
+func (blockMgr *BlockMgr) handleTxReq(peer types.PeerInfoInterface, req *types.TxReq) {
+	if len(req.Hashes) > maxTxsCount {
+		req.Hashes = req.Hashes[:maxTxsCount]
+	}
+	txs := make([]*types.Transaction, 0, len(req.Hashes))
+	for _, hash := range req.Hashes {
+		tx, err := blockMgr.transactionPool.GetTxInPool(hash.String())
+		if err != nil || blockMgr.isPrivate(&hash) {
+			continue
+		}
+		peer.MarkTx(tx)
+		txs = append(txs, tx)
+	}
+	if len(txs) > 0 {
+		blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTransaction, txs)
+	}
+}
+
+// announceTxs announce the hashes of txs to peer in batches, used to sync the pool with a new peer
+func (blockMgr *BlockMgr) announceTxs(peer types.PeerInfoInterface, txs []*types.Transaction) {
+	for start := 0; start < len(txs); start += maxTxsCount {
+		end := start + maxTxsCount
+		if end > len(txs) {
+			end = len(txs)
+		}
+		hashes := make([]crypto.Hash, 0, end-start)
+		for _, tx := range txs[start:end] {
+			peer.MarkTx(tx)
+			hashes = append(hashes, *tx.TxHash())
+		}
+		blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeTxAnnounce, &types.TxAnnounce{Hashes: hashes})
+		if end < len(txs) {
+			time.Sleep(time.Millisecond * maxSyncSleepTime)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package blockmgr
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func TestAnnounceFetcher(t *testing.T) {
+	var fetcher announceFetcher
+	a, b := crypto.Hash{1}, crypto.Hash{2}
+
+	if fetch := fetcher.tryFetchAll([]crypto.Hash{a, b}); len(fetch) != 2 {
+		t.Fatalf("fetch %d of 2 new hashes", len(fetch))
+	}
+	if fetcher.tryFetch(a) {
+		t.Fatal("hash in flight fetched again")
+	}
+	fetcher.done(a)
+	if fetch := fetcher.tryFetchAll([]crypto.Hash{a, b}); len(fetch) != 1 || fetch[0] != a {
+		t.Fatalf("fetch %v, want only the delivered hash", fetch)
+	}
+}
+
+// sendRecorder record the message codes sent to each peer
+type sendRecorder struct {
+	p2pServiceMock
+	sent map[p2p.MsgWriter][]uint64
+}
+
+func (rec *sendRecorder) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error {
+	rec.sent[w] = append(rec.sent[w], msgType)
+	return nil
+}
+
+func TestV0PeerNeverSentNewMessages(t *testing.T) {
+	rec := &sendRecorder{sent: make(map[p2p.MsgWriter][]uint64)}
+	blockMgr := &BlockMgr{P2pServer: rec}
+	peers := []*types.PeerInfo{}
+	for i := 0; i < 16; i++ {
+		rw, _ := p2p.MsgPipe()
+		peer := types.NewPeerInfo(nil, rw)
+		if i%2 == 1 {
+			peer.SetVersion(types.ProtocolVersion)
+		}
+		blockMgr.peersInfo.Store(strconv.Itoa(i), peer)
+		peers = append(peers, peer)
+	}
+
+	block := &types.Block{Header: &types.BlockHeader{Height: 1}, Data: &types.BlockData{}}
+	blockMgr.BroadcastBlock(types.MsgTypeBlock, block, true)
+	blockMgr.LoadAddressFilter([]crypto.CommonAddress{{1}})
+	blockMgr.ClearAddressFilter()
+	for _, peer := range peers {
+		blockMgr.sendAddressFilter(peer)
+	}
+
+	announced := false
+	for _, peer := range peers {
+		for _, code := range rec.sent[peer.GetMsgRW()] {
+			if !types.MsgSupported(peer.Version(), code) {
+				t.Fatalf("message %d sent to a peer running version %d", code, peer.Version())
+			}
+			announced = announced || code == types.MsgTypeBlockAnnounce
+		}
+		if len(rec.sent[peer.GetMsgRW()]) == 0 {
+			t.Fatal("block not sent to a peer")
+		}
+	}
+	if !announced {
+		t.Fatal("block not announced to the peers running the current version")
+	}
+}
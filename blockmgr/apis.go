@@ -3,6 +3,7 @@ package blockmgr
 import (
 	"math/big"
 
+	"github.com/drep-project/DREP-Chain/blockmgr/txpool"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database"
@@ -45,6 +46,27 @@ func (blockMgrApi *BlockMgrAPI) SendRawTransaction(txbytes common.Bytes) (string
 	return tx.TxHash().String(), err
 }
 
+/*
+ name: sendPrivateRawTransaction
+ usage: Send signed transaction to the connected producers only, it is not gossiped to the other peers
+ params:
+	1. A signed transaction
+ return: transaction hash
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_sendPrivateRawTransaction","params":["0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":1,"result":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538"}
+*/
+func (blockMgrApi *BlockMgrAPI) SendPrivateRawTransaction(txbytes common.Bytes) (string, error) {
+	tx := &types.Transaction{}
+	if err := binary.Unmarshal(txbytes, tx); err != nil {
+		return "", err
+	}
+	if err := blockMgrApi.blockMgr.SendPrivateTransaction(tx); err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
 /*
  name: gasPrice
  usage: Get the recommended value of gasprice given by the system
@@ -133,3 +155,80 @@ func (blockMgrApi *BlockMgrAPI) GetTxInPool(hash string) (*types.Transaction, er
 	}
 	return tx, nil
 }
+
+/*
+ name: loadAddressFilter
+ usage: Register an address filter with all peers, afterwards peers only announce blocks and transactions touching these addresses
+ params:
+	1. Addresses to watch
+ return: none
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_loadAddressFilter","params":[["0x8a8e541ddd1272d53729164c70197221a3c27486"]], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (blockMgrApi *BlockMgrAPI) LoadAddressFilter(addrs []crypto.CommonAddress) {
+	blockMgrApi.blockMgr.LoadAddressFilter(addrs)
+}
+
+/*
+ name: clearAddressFilter
+ usage: Remove the address filter from all peers
+ params:
+ return: none
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"blockmgr_clearAddressFilter","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (blockMgrApi *BlockMgrAPI) ClearAddressFilter() {
+	blockMgrApi.blockMgr.ClearAddressFilter()
+}
+
+/*
+name: Tx priority
+usage: Manage the senders and contracts whose transactions a producer packs first
+prefix:admin
+*/
+type TxPriorityApi struct {
+	blockMgr *BlockMgr
+}
+
+/*
+ name: setTxPriority
+ usage: Replace the priority rules set through the api, rules of the priority file are kept
+ params:
+	1. Senders whose transactions are packed first
+	2. Contracts whose calls are packed first
+ return: none
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_setTxPriority","params":[["0x8a8e541ddd1272d53729164c70197221a3c27486"],[]], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (txPriorityApi *TxPriorityApi) SetTxPriority(senders []crypto.CommonAddress, contracts []crypto.CommonAddress) {
+	txPriorityApi.blockMgr.SetTxPriority(&txpool.PriorityRules{Senders: senders, Contracts: contracts})
+}
+
+/*
+ name: txPriority
+ usage: Get the priority rules grouped by source (file, api)
+ params:
+ return: senders and contracts of every source
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_txPriority","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"api":{"senders":["0x8a8e541ddd1272d53729164c70197221a3c27486"],"contracts":[]}}}
+*/
+func (txPriorityApi *TxPriorityApi) TxPriority() map[string]*txpool.PriorityRules {
+	return txPriorityApi.blockMgr.TxPriority()
+}
+
+/*
+ name: reloadTxPriority
+ usage: Reload the priority file now
+ params:
+ return: none
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_reloadTxPriority","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (txPriorityApi *TxPriorityApi) ReloadTxPriority() error {
+	return txPriorityApi.blockMgr.ReloadTxPriority()
+}
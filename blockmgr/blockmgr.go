@@ -2,9 +2,10 @@ package blockmgr
 
 import (
 	"math/big"
-	"math/rand"
 	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/trie"
@@ -29,6 +30,20 @@ import (
 )
 
 var (
+	TxPoolPriceLimitFlag = cli.Uint64Flag{
+		Name:  "txpool.pricelimit",
+		Usage: "Minimum gas price of remote transactions accepted by the transaction pool",
+	}
+	TxPoolGlobalSlotsFlag = cli.IntFlag{
+		Name:  "txpool.globalslots",
+		Usage: "Maximum number of transactions held by the transaction pool, the cheapest remote ones are evicted when full",
+		Value: txpool.DefaultGlobalSlots,
+	}
+	TxPoolLocalsFlag = cli.StringFlag{
+		Name:  "txpool.locals",
+		Usage: "Comma separated addresses whose transactions are treated as local, never evicted by price and packed first",
+	}
+
 	rootChain types.ChainIdType
 	// DefaultOracleConfig define default config of oracle
 	DefaultOracleConfig = OracleConfig{
@@ -53,6 +68,7 @@ type IBlockMgr interface {
 	IBlockBlockGenerator
 	IBlockNotify
 	ISendMessage
+	IProducerNodes
 }
 
 // IBlockMgrPool interface
@@ -74,13 +90,17 @@ type IBlockBlockGenerator interface {
 type IBlockNotify interface {
 	//notify
 	SubscribeSyncBlockEvent(subchan chan event.SyncBlockEvent) event.Subscription
+	SubscribeSyncProgress(subchan chan SyncProgress) event.Subscription
 	NewTxFeed() *event.Feed
+	DroppedTxFeed() *event.Feed
+	TxPoolEventFeed() *event.Feed
 }
 
 // ISendMessage interface
 type ISendMessage interface {
 	// send
 	SendTransaction(tx *types.Transaction, islocal bool) error
+	SendPrivateTransaction(tx *types.Transaction) error
 	BroadcastBlock(msgType int32, block *types.Block, isLocal bool)
 	BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool)
 }
@@ -107,6 +127,11 @@ type BlockMgr struct {
 	syncBlockEvent event.Feed
 	syncMut        sync.Mutex
 
+	//Progress of synchronization, sent to subscribers when stage changed
+	progress         SyncProgress
+	progressLock     sync.RWMutex
+	syncProgressFeed event.Feed
+
 	//Receive the bulk hash group from the remote
 	headerHashCh chan []*syncHeaderHash
 
@@ -128,11 +153,29 @@ type BlockMgr struct {
 
 	newPeerCh chan *types.PeerInfo
 
-	gpo  *Oracle
-	quit chan struct{}
+	//Address filter registered with serving peers when running as a light peer
+	addrFilter *types.Bloom
+	filterLock sync.RWMutex
+
+	//Block bodies and transactions requested from announce
+	fetcher   announceFetcher
+	txFetcher announceFetcher
+
+	//Stripe sync requests across peers
+	scheduler *syncScheduler
+
+	gpo     *Oracle
+	homeDir string
+	quit    chan struct{}
+
+	//Count of transaction broadcasts and sync tasks not sent yet, waited for on shutdown
+	broadcasts int64
+
+	//Nodes of the current producers, private transactions are sent to them
+	producerNodes atomic.Value //map[string]struct{}
 }
 
-func getPeersCount(peerInfos sync.Map) int {
+func getPeersCount(peerInfos *sync.Map) int {
 	count := 0
 	peerInfos.Range(func(key, value interface{}) bool {
 		count++
@@ -143,6 +186,7 @@ func getPeersCount(peerInfos sync.Map) int {
 
 type syncHeaderHash struct {
 	headerHash *crypto.Hash
+	prevHash   *crypto.Hash
 	height     uint64
 }
 
@@ -158,7 +202,7 @@ func (blockMgr *BlockMgr) Api() []app.API {
 
 // CommandFlags return an array interface of flag
 func (blockMgr *BlockMgr) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{}
+	return nil, []cli.Flag{TxPriorityFileFlag, TxPoolPriceLimitFlag, TxPoolGlobalSlotsFlag, TxPoolLocalsFlag}
 }
 
 // NewBlockMgr init all need of block management
@@ -171,9 +215,10 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 	blockMgr.headerHashCh = make(chan []*syncHeaderHash)
 	blockMgr.blocksCh = make(chan []*types.Block)
 	blockMgr.allTasks = newHeightSortedMap()
+	blockMgr.scheduler = newSyncScheduler()
 	//blockMgr.pendingSyncTasks = make(map[*time.Timer]map[crypto.Hash]uint64)
 	blockMgr.state = event.StopSyncBlock
-	blockMgr.syncTimerCh = make(chan *time.Timer, pendingTimerCount)
+	blockMgr.syncTimerCh = make(chan *time.Timer, maxLivePeer*maxPeerInflight)
 	//blockMgr.peersInfo = sync.Map{} //make(map[string]types.PeerInfoInterface)
 	blockMgr.newPeerCh = make(chan *types.PeerInfo, maxLivePeer)
 	blockMgr.taskTxsCh = make(chan tasksTxsSync, maxLivePeer)
@@ -185,23 +230,11 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 		return nil
 	}
 	blockMgr.transactionPool = txpool.NewTransactionPool(store, path.Join(homeDir, blockMgr.Config.JournalFile))
+	blockMgr.setupPool(nil)
+	blockMgr.homeDir = homeDir
 
-	blockMgr.P2pServer.AddProtocols([]p2p.Protocol{
-		p2p.Protocol{
-			Name:   "blockMgr",
-			Length: types.NumberOfMsg,
-			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
-				if getPeersCount(blockMgr.peersInfo) >= maxLivePeer {
-					return ErrEnoughPeer
-				}
-				pi := types.NewPeerInfo(peer, rw)
-				blockMgr.peersInfo.Store(peer.ID().String(), pi)
-
-				defer blockMgr.peersInfo.Delete(peer.ID().String()) // (blockMgr.peersInfo, peer.IP())
-				return blockMgr.receiveMsg(pi, rw)
-			},
-		},
-	})
+	blockMgr.P2pServer.SetChainId(uint64(cs.ChainID()))
+	blockMgr.P2pServer.AddProtocols(blockMgr.protocols())
 
 	blockMgr.apis = []app.API{
 		app.API{
@@ -213,16 +246,66 @@ func NewBlockMgr(config *BlockMgrConfig, homeDir string, cs chain.ChainServiceIn
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "chain",
+			Version:   "1.0",
+			Service: &SyncApi{
+				blockMgr: blockMgr,
+			},
+			Public: true,
+		},
 	}
 	return blockMgr
 }
 
+// msgRateLimits are the rates a peer may send requests and transactions at, enough for a
+// peer syncing from us, a peer sending faster is throttled and then disconnected
+var msgRateLimits = map[uint64]p2p.RateLimit{
+	types.MsgTypeTransaction:  {Rate: 100, Burst: 500},
+	types.MsgTypeTxAnnounce:   {Rate: 100, Burst: 500},
+	types.MsgTypeTxReq:        {Rate: 50, Burst: 200},
+	types.MsgTypePrivateTx:    {Rate: 100, Burst: 500},
+	types.MsgTypeBlockReq:     {Rate: 20, Burst: 100},
+	types.MsgTypeHeaderReq:    {Rate: 20, Burst: 100},
+	types.MsgTypeBlockBodyReq: {Rate: 50, Burst: 200},
+	types.MsgTypePeerStateReq: {Rate: 5, Burst: 20},
+	types.MsgTypeFilterLoad:   {Rate: 1, Burst: 10},
+}
+
+// protocols return the blockMgr protocol in every version offered, old versions are kept so
+// peers not upgraded yet still connect
+func (blockMgr *BlockMgr) protocols() []p2p.Protocol {
+	protocols := make([]p2p.Protocol, 0, types.ProtocolVersion+1)
+	for version := types.ProtocolV0; version <= types.ProtocolVersion; version++ {
+		version := version
+		protocols = append(protocols, p2p.Protocol{
+			Name:       "blockMgr",
+			Version:    version,
+			Length:     types.NumberOfMsg,
+			RateLimits: msgRateLimits,
+			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+				if getPeersCount(&blockMgr.peersInfo) >= maxLivePeer {
+					return ErrEnoughPeer
+				}
+				pi := types.NewPeerInfo(peer, rw)
+				pi.SetVersion(version)
+				blockMgr.peersInfo.Store(peer.ID().String(), pi)
+
+				defer blockMgr.peersInfo.Delete(peer.ID().String())
+				return blockMgr.receiveMsg(pi, rw)
+			},
+		})
+	}
+	return protocols
+}
+
 // Init function init block from initial config.
 func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 	blockMgr.headerHashCh = make(chan []*syncHeaderHash)
 	blockMgr.blocksCh = make(chan []*types.Block)
 	blockMgr.allTasks = newHeightSortedMap()
-	blockMgr.syncTimerCh = make(chan *time.Timer, 1)
+	blockMgr.scheduler = newSyncScheduler()
+	blockMgr.syncTimerCh = make(chan *time.Timer, maxLivePeer*maxPeerInflight)
 	blockMgr.state = event.StopSyncBlock
 	//blockMgr.peersInfo = make(map[string]types.PeerInfoInterface)
 	blockMgr.newPeerCh = make(chan *types.PeerInfo, maxLivePeer)
@@ -235,27 +318,29 @@ func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 		return err
 	}
 	blockMgr.transactionPool = txpool.NewTransactionPool(store, path.Join(executeContext.CommonConfig.HomeDir, blockMgr.Config.JournalFile))
-	blockMgr.chainStore = &chain.ChainStore{blockMgr.DatabaseService.LevelDb()}
-	blockMgr.P2pServer.AddProtocols([]p2p.Protocol{
-		p2p.Protocol{
-			Name:   "blockMgr",
-			Length: types.NumberOfMsg,
-
-			Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
-				//blockMgr.lock.Lock()
-				//defer blockMgr.lock.Unlock()
-
-				if getPeersCount(blockMgr.peersInfo) >= maxLivePeer {
-					return ErrEnoughPeer
-				}
-				pi := types.NewPeerInfo(peer, rw)
-				blockMgr.peersInfo.Store(peer.ID().String(), pi)
-
-				defer blockMgr.peersInfo.Delete(peer.ID().String())
-				return blockMgr.receiveMsg(pi, rw)
-			},
-		},
+	blockMgr.setupPool(executeContext.Cli)
+	blockMgr.ChainService.SetPendingTxLookup(func(txHash crypto.Hash) *types.Transaction {
+		tx, _ := blockMgr.transactionPool.GetTxInPool(txHash.String())
+		return tx
 	})
+	blockMgr.homeDir = executeContext.CommonConfig.HomeDir
+	blockMgr.quit = make(chan struct{})
+	app.RegisterShutdown(MODULENAME, app.ShutdownDrain, blockMgr.drainBroadcasts)
+	if executeContext.Cli != nil && executeContext.Cli.GlobalIsSet(TxPriorityFileFlag.Name) {
+		blockMgr.Config.PriorityFile = executeContext.Cli.GlobalString(TxPriorityFileFlag.Name)
+	}
+	if blockMgr.Config.PriorityFile != "" {
+		if err := blockMgr.ReloadTxPriority(); err != nil {
+			return err
+		}
+	}
+	blockMgr.chainStore = &chain.ChainStore{blockMgr.DatabaseService.LevelDb()}
+	blockMgr.P2pServer.SetChainId(uint64(blockMgr.ChainService.ChainID()))
+	blockMgr.P2pServer.SetForks(blockMgr.ChainService.GenesisParams().Forks())
+	if genesis := blockMgr.ChainService.BestChain().Genesis(); genesis != nil {
+		blockMgr.P2pServer.SetGenesis(*genesis.Hash)
+	}
+	blockMgr.P2pServer.AddProtocols(blockMgr.protocols())
 
 	blockMgr.apis = []app.API{
 		app.API{
@@ -267,15 +352,91 @@ func (blockMgr *BlockMgr) Init(executeContext *app.ExecuteContext) error {
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "chain",
+			Version:   "1.0",
+			Service: &SyncApi{
+				blockMgr: blockMgr,
+			},
+			Public: true,
+		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &TxPriorityApi{
+				blockMgr: blockMgr,
+			},
+			Public: true,
+		},
 	}
 	return nil
 }
 
+// setupPool apply the txpool limits of config, overridden by the command line flags
+func (blockMgr *BlockMgr) setupPool(ctx *cli.Context) {
+	if ctx != nil && ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
+		blockMgr.Config.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
+	}
+	if ctx != nil && ctx.GlobalIsSet(TxPoolGlobalSlotsFlag.Name) {
+		blockMgr.Config.GlobalSlots = ctx.GlobalInt(TxPoolGlobalSlotsFlag.Name)
+	}
+	if ctx != nil && ctx.GlobalIsSet(TxPoolLocalsFlag.Name) {
+		blockMgr.Config.Locals = nil
+		for _, account := range strings.Split(ctx.GlobalString(TxPoolLocalsFlag.Name), ",") {
+			account = strings.TrimSpace(account)
+			if !crypto.IsHexAddress(account) {
+				log.WithField("account", account).Warn("invalid local account, ignored")
+				continue
+			}
+			blockMgr.Config.Locals = append(blockMgr.Config.Locals, crypto.HexToAddress(account))
+		}
+	}
+	if blockMgr.Config.MaxFutureTxs > 0 {
+		blockMgr.transactionPool.SetFutureLimit(blockMgr.Config.MaxFutureTxs)
+	}
+	if blockMgr.Config.GlobalSlots > 0 {
+		blockMgr.transactionPool.SetGlobalSlots(blockMgr.Config.GlobalSlots)
+	}
+	blockMgr.transactionPool.SetPriceLimit(new(big.Int).SetUint64(blockMgr.Config.PriceLimit))
+	blockMgr.transactionPool.AddLocals(blockMgr.Config.Locals)
+	blockMgr.setupCache()
+}
+
+// setupCache limit txpool and known hash records by the cache budget and report their usage
+func (blockMgr *BlockMgr) setupCache() {
+	blockMgr.transactionPool.SetMemoryLimit(app.CacheAllowance(app.CacheTxPool))
+	app.RegisterCacheUsage(app.CacheTxPool, blockMgr.transactionPool.MemoryUsage)
+
+	//Half of the known hash budget for blocks, the rest for transactions
+	perPeer := int(app.CacheAllowance(app.CacheKnownHash)) / maxLivePeer / types.KnownHashSize
+	types.SetKnownCacheLimit(perPeer/2, perPeer/2)
+	app.RegisterCacheUsage(app.CacheKnownHash, func() int64 {
+		count := 0
+		blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+			count += value.(types.PeerInfoInterface).KnownCount()
+			return true
+		})
+		return int64(count * types.KnownHashSize)
+	})
+}
+
 // Start syn block and transactions.
 func (blockMgr *BlockMgr) Start(executeContext *app.ExecuteContext) error {
-	blockMgr.transactionPool.Start(blockMgr.ChainService.NewBlockFeed(), blockMgr.ChainService.BestChain().Tip().StateRoot)
+	blockMgr.transactionPool.Start(blockMgr.ChainService.NewBlockFeed(), blockMgr.ChainService.ReorgFeed(), blockMgr.ChainService.BestChain().Tip().StateRoot)
 	go blockMgr.synchronise()
 	go blockMgr.syncTxs()
+	go blockMgr.advertiseHead()
+	if blockMgr.Config.PriorityFile != "" {
+		go blockMgr.watchTxPriority()
+	}
+	return nil
+}
+
+// drainBroadcasts wait for the queued transaction broadcasts to reach the peers
+func (blockMgr *BlockMgr) drainBroadcasts() error {
+	for atomic.LoadInt64(&blockMgr.broadcasts) > 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
 	return nil
 }
 
@@ -299,6 +460,9 @@ func (blockMgr *BlockMgr) SendTransaction(tx *types.Transaction, islocal bool) e
 	//if nonce > tx.Nonce() {
 	//	return fmt.Errorf("SendTransaction local nonce:%d != comming tx nonce:%d", nonce, tx.Nonce())
 	//}
+	if islocal && app.InMaintenance() {
+		return app.ErrMaintenance
+	}
 	err := blockMgr.verifyTransaction(tx)
 
 	if err != nil {
@@ -314,50 +478,6 @@ func (blockMgr *BlockMgr) SendTransaction(tx *types.Transaction, islocal bool) e
 	return nil
 }
 
-// BroadcastBlock broadcasts block until receive more than 2/3 of peers.
-func (blockMgr *BlockMgr) BroadcastBlock(msgType int32, block *types.Block, isLocal bool) {
-	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
-		peer := value.(types.PeerInfoInterface)
-		b := peer.KnownBlock(block)
-		if !b {
-			if !isLocal {
-				//Receive a message from the remote end and only broadcast it to 2/3 of peers
-				rd := rand.Intn(broadcastRatio)
-				if rd > 2 {
-					return false
-				}
-			}
-			peer.MarkBlock(block)
-			blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(msgType), block)
-		}
-		return true
-	})
-}
-
-// BroadcastTx broadcasts transaction until receive more than 2/3 of peers.
-func (blockMgr *BlockMgr) BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool) {
-	go func() {
-
-		blockMgr.peersInfo.Range(func(key, value interface{}) bool {
-			peer := value.(types.PeerInfoInterface)
-			b := peer.KnownTx(tx)
-			if !b {
-				if !isLocal {
-					//Receive a message from the remote end and only broadcast it to 2/3 of peers
-					rd := rand.Intn(broadcastRatio)
-					if rd > 2 {
-						return false
-					}
-				}
-
-				peer.MarkTx(tx)
-				blockMgr.P2pServer.Send(peer.GetMsgRW(), uint64(msgType), []*types.Transaction{tx})
-			}
-			return true
-		})
-	}()
-}
-
 // GetPoolTransactions gets all the trades in the current pool.
 func (blockMgr *BlockMgr) GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions {
 	return blockMgr.transactionPool.GetTransactions(addr)
@@ -383,6 +503,16 @@ func (blockMgr *BlockMgr) NewTxFeed() *event.Feed {
 	return blockMgr.transactionPool.NewTxFeed()
 }
 
+// DroppedTxFeed gets the feed of transactions dropped from the trading pool.
+func (blockMgr *BlockMgr) DroppedTxFeed() *event.Feed {
+	return blockMgr.transactionPool.DroppedTxFeed()
+}
+
+// TxPoolEventFeed gets the feed of transactions accepted into, replaced in and dropped from the trading pool.
+func (blockMgr *BlockMgr) TxPoolEventFeed() *event.Feed {
+	return blockMgr.transactionPool.EventFeed()
+}
+
 // DefaultConfig gets default config of blockchain.
 func (blockMgr *BlockMgr) DefaultConfig() *BlockMgrConfig {
 	return DefaultChainConfig
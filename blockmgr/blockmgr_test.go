@@ -1,77 +1,82 @@
 package blockmgr
 
 import (
-	"crypto/rand"
-	"github.com/drep-project/binary"
-	"github.com/drep-project/DREP-Chain/chain"
-	"github.com/drep-project/DREP-Chain/crypto"
-	"github.com/drep-project/DREP-Chain/crypto/sha3"
-	"github.com/drep-project/DREP-Chain/database"
-	"github.com/drep-project/DREP-Chain/types"
 	"testing"
 	"time"
-)
-
-func generatorChain(t *testing.T, db *database.Database) []*types.Block {
-
-	privKey, _ := crypto.GenerateKey(rand.Reader)
-	ds := database.NewDatabaseService(db)
-
-	producer := types.Producers{Pubkey: privKey.PubKey(), IP: "127.0.0.1"}
-	chain.DefaultChainConfig.Producers = append(chain.DefaultChainConfig.Producers, producer)
-
-	cs := chain.NewChainService(chain.DefaultChainConfig, ds)
-
-	bm := NewBlockMgr(DefaultChainConfig, "./", cs, &p2pServiceMock{})
 
-	blks := make([]*types.Block, 0)
-
-	for i := 0; i < 10; i++ {
-		block, _, err := bm.GenerateTemplate(db, privKey.PubKey())
-		if err != nil {
-			t.Fatal("generate block err:", err)
-			return nil
-		}
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/types"
+)
 
-		msg, err := binary.Marshal(block)
-		if err != nil {
-			t.Fatal("generate block err:", err)
-			return nil
+// generatorChain return a genesis block and count empty blocks linked on top of it
+func generatorChain(t *testing.T, count int) []*types.Block {
+	genesis := &types.Block{
+		Header: &types.BlockHeader{StateRoot: trie.EmptyRoot[:], Timestamp: uint64(time.Now().Unix())},
+		Data:   &types.BlockData{},
+	}
+	blks := []*types.Block{genesis}
+	for i := 0; i < count; i++ {
+		parent := blks[len(blks)-1].Header
+		block := &types.Block{
+			Header: &types.BlockHeader{
+				PreviousHash: *parent.Hash(),
+				Height:       parent.Height + 1,
+				Timestamp:    parent.Timestamp + 1,
+				StateRoot:    trie.EmptyRoot[:],
+			},
+			Data: &types.BlockData{},
 		}
-
-		sig, err := privKey.Sign(sha3.Keccak256(msg))
-		if err != nil {
-			t.Fatal("generate block err:", err)
-			return nil
+		if block.Header.Hash() == nil {
+			t.Fatal("generate block err: header not encoded")
 		}
+		blks = append(blks, block)
+	}
+	return blks
+}
 
-		multiSig := &types.MultiSignature{Sig: *sig, Bitmap: []byte{1}}
+// syncChain is a chain service holding the block index and best chain only, blocks are
+// connected without executing them
+type syncChain struct {
+	chain.ChainServiceInterface
+	index     *chain.BlockIndex
+	bestChain *chain.ChainView
+}
 
-		block.MultiSig = multiSig
-		db = bm.ChainService.GetDatabaseService().BeginTransaction()
-		gp := new(chain.GasPool).AddGas(block.Header.GasLimit.Uint64())
-		//process transaction
-		_, gasFee, err := bm.ChainService.BlockValidator().ExecuteBlock(db, block, gp)
-		if err != nil {
-			log.WithField("ExecuteBlock", err).Debug("multySigVerify")
-			return nil
-		}
-		err = bm.ChainService.AccumulateRewards(db, block, gasFee)
-		if err != nil {
-			return nil
+func newSyncChain(blks []*types.Block) *syncChain {
+	genesis := types.NewBlockNode(blks[0].Header, nil)
+	cs := &syncChain{index: chain.NewBlockIndex(), bestChain: chain.NewChainView(genesis)}
+	cs.index.AddNode(genesis)
+	for _, b := range blks[1:] {
+		if _, _, err := cs.ProcessBlock(b); err != nil {
+			panic(err)
 		}
+	}
+	return cs
+}
+
+func (cs *syncChain) BestChain() *chain.ChainView {
+	return cs.bestChain
+}
 
-		block.Header.StateRoot = db.GetStateRoot()
+func (cs *syncChain) Index() *chain.BlockIndex {
+	return cs.index
+}
 
-		_, _, err = bm.ChainService.ProcessBlock(block)
-		if err != nil {
-			t.Fatal("process block err:", err)
-			return nil
-		}
+func (cs *syncChain) BlockValidator() chain.BlockValidators {
+	return nil
+}
 
-		blks = append(blks, block)
-		time.Sleep(time.Second)
+func (cs *syncChain) ProcessBlock(block *types.Block) (bool, bool, error) {
+	if cs.index.HaveBlock(block.Header.Hash()) {
+		return false, false, chain.ErrBlockExsist
 	}
-
-	return blks
+	tip := cs.bestChain.Tip()
+	if !block.Header.PreviousHash.IsEqual(tip.Hash) {
+		return false, true, nil
+	}
+	node := types.NewBlockNode(block.Header, tip)
+	cs.index.AddNode(node)
+	cs.bestChain.SetTip(node)
+	return true, false, nil
 }
@@ -1,9 +1,19 @@
 package blockmgr
 
+import "github.com/drep-project/DREP-Chain/crypto"
+
 // BlockMgrConfig defines gasprice & journal file type.
 type BlockMgrConfig struct {
-	GasPrice    OracleConfig `json:"gasprice"`
-	JournalFile string       `json:"journalFile"`
+	GasPrice     OracleConfig           `json:"gasprice"`
+	JournalFile  string                 `json:"journalFile"`
+	PriorityFile string                 `json:"priorityFile,omitempty"`
+	MaxFutureTxs int                    `json:"maxFutureTxs,omitempty"` //Future nonce transactions queued per address, 0 use the default
+	PriceLimit   uint64                 `json:"priceLimit,omitempty"`   //Min gas price of remote transactions accepted by the pool
+	GlobalSlots  int                    `json:"globalSlots,omitempty"`  //Max transactions in the pool, 0 use the default
+	Locals       []crypto.CommonAddress `json:"locals,omitempty"`       //Addresses whose transactions are never evicted by price and packed first
+
+	TemplateBudget int `json:"templateBudget,omitempty"` //Percent of the block interval spent executing the transactions of a new block, 0 use the default
+	TxTimeout      int `json:"txTimeout,omitempty"`      //Milliseconds one transaction may execute while packing a block, 0 use the default
 }
 
 // OracleConfig manages gas price of block.
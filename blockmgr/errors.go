@@ -5,6 +5,12 @@ import (
 )
 
 var (
+	// ErrNoPriorityFile print error message.
+	ErrNoPriorityFile = errors.New("no tx priority file configured")
+	// ErrClockDrift print error message.
+	ErrClockDrift = errors.New("system clock drift too large to produce blocks")
+	// ErrNoProducerPeer print error message.
+	ErrNoProducerPeer = errors.New("no producer connected to send private transaction")
 	// ErrBlockNotFound print error message.
 	ErrBlockNotFound = errors.New("block not exist")
 	// ErrTxIndexOutOfRange print error message.
@@ -31,6 +37,10 @@ var (
 	ErrMsgType = errors.New("not expected msg type")
 	// ErrNegativeAmount print error message.
 	ErrNegativeAmount = errors.New("negative amount in tx")
+	// ErrTxChainId print error message.
+	ErrTxChainId = errors.New("transaction chain id not matched")
+	// ErrFeePayerSig print error message.
+	ErrFeePayerSig = errors.New("invalid fee payer signature in sponsored tx")
 	// ErrExceedGasLimit print error message.
 	ErrExceedGasLimit = errors.New("gas limit in tx has exceed block limit")
 	// ErrBalance print error message.
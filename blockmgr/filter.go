@@ -0,0 +1,64 @@
+package blockmgr
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// LoadAddressFilter registers a bloom filter over addrs with all connected peers and with peers
+// connected later, serving peers then only announce blocks and transactions touching these addresses.
+// Peers running a protocol version without filters are not sent it and keep relaying everything.
+func (blockMgr *BlockMgr) LoadAddressFilter(addrs []crypto.CommonAddress) {
+	filter := types.AddressBloom(addrs)
+	blockMgr.filterLock.Lock()
+	blockMgr.addrFilter = &filter
+	blockMgr.filterLock.Unlock()
+	blockMgr.P2pServer.SetNodeRole(enr.RoleLight)
+
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		peer := value.(types.PeerInfoInterface)
+		if types.MsgSupported(peer.Version(), types.MsgTypeFilterLoad) {
+			blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeFilterLoad, &types.FilterLoad{Filter: filter})
+		}
+		return true
+	})
+}
+
+// ClearAddressFilter removes the address filter from all peers.
+func (blockMgr *BlockMgr) ClearAddressFilter() {
+	blockMgr.filterLock.Lock()
+	blockMgr.addrFilter = nil
+	blockMgr.filterLock.Unlock()
+	blockMgr.P2pServer.SetNodeRole(enr.RoleFull)
+
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		peer := value.(types.PeerInfoInterface)
+		if types.MsgSupported(peer.Version(), types.MsgTypeFilterClear) {
+			blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeFilterClear, &types.FilterClear{})
+		}
+		return true
+	})
+}
+
+// sendAddressFilter sends the local address filter to a newly connected peer.
+func (blockMgr *BlockMgr) sendAddressFilter(peer types.PeerInfoInterface) {
+	blockMgr.filterLock.RLock()
+	filter := blockMgr.addrFilter
+	blockMgr.filterLock.RUnlock()
+	if filter == nil || !types.MsgSupported(peer.Version(), types.MsgTypeFilterLoad) {
+		return
+	}
+	blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeFilterLoad, &types.FilterLoad{Filter: *filter})
+}
+
+func (blockMgr *BlockMgr) handleFilterLoad(peer types.PeerInfoInterface, req *types.FilterLoad) {
+	filter := req.Filter
+	peer.SetFilter(&filter)
+	log.WithField("peer", peer.GetAddr()).Debug("peer loaded address filter")
+}
+
+func (blockMgr *BlockMgr) handleFilterClear(peer types.PeerInfoInterface) {
+	peer.SetFilter(nil)
+	log.WithField("peer", peer.GetAddr()).Debug("peer cleared address filter")
+}
@@ -0,0 +1,50 @@
+package blockmgr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+)
+
+// forkServer return a p2p server at head with the forks of the genesis file content
+func forkServer(t *testing.T, genesis string, head uint64) *p2p.Server {
+	params := &chain.GenesisParams{}
+	if err := json.Unmarshal([]byte(genesis), params); err != nil {
+		t.Fatal(err)
+	}
+	srv := &p2p.Server{Config: p2p.Config{ChainId: 1}}
+	srv.SetForks(params.Forks())
+	srv.SetGenesis(crypto.Hash{1})
+	srv.SetChainHead(0, head)
+	return srv
+}
+
+// TestGenesisForkPeers checks nodes of genesis files differing in a fork height only reject
+// each other once one of them passed the fork, nodes of the same genesis file keep peering
+func TestGenesisForkPeers(t *testing.T) {
+	const (
+		genesis      = `{"chainId":1,"txRootFork":10,"chainIdFork":100}`
+		otherGenesis = `{"chainId":1,"txRootFork":10,"chainIdFork":200}`
+	)
+	local, same, other := forkServer(t, genesis, 150), forkServer(t, genesis, 50), forkServer(t, otherGenesis, 150)
+	if err := local.CheckFork(same.ForkEntry()); err != nil {
+		t.Errorf("node of the same genesis rejected: %v", err)
+	}
+	if err := same.CheckFork(local.ForkEntry()); err != nil {
+		t.Errorf("node of the same genesis rejected: %v", err)
+	}
+	if err := local.CheckFork(other.ForkEntry()); err == nil {
+		t.Error("node scheduling the fork at another height accepted")
+	}
+	if err := other.CheckFork(local.ForkEntry()); err == nil {
+		t.Error("node scheduling the fork at another height accepted")
+	}
+
+	// below both heights the schedules only differ in the next fork announced
+	if err := forkServer(t, genesis, 50).CheckFork(forkServer(t, otherGenesis, 50).ForkEntry()); err != nil {
+		t.Errorf("nodes below both fork heights rejected: %v", err)
+	}
+}
@@ -7,13 +7,28 @@ import (
 	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/ntpclock"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
-// GenerateTemplate blockchain t
+const (
+	// DefaultTemplateBudget is the default percent of the block interval spent executing the
+	// transactions of a new block, the rest is left to the consensus round
+	DefaultTemplateBudget = 80
+	// DefaultTxTimeout is the default milliseconds one transaction may execute while packing
+	DefaultTxTimeout = 500
+)
+
+// GenerateTemplate blockchain t, the transactions are executed until the template budget of the
+// block interval is spent and the block holds the ones executed by then. No block is produced
+// while the system clock is off too far, the others would reject its timestamp
 func (blockMgr *BlockMgr) GenerateTemplate(trieStore store.StoreInterface, leaderAddr crypto.CommonAddress, blockInterval int) (*types.Block, *big.Int, error) {
+	if ntpclock.Dangerous() {
+		return nil, nil, ErrClockDrift
+	}
+	deadline := time.Now().Add(blockMgr.templateBudget(blockInterval))
 	parent, err := blockMgr.ChainService.GetHighestBlock()
 	if err != nil {
 		return nil, nil, err
@@ -49,10 +64,30 @@ func (blockMgr *BlockMgr) GenerateTemplate(trieStore store.StoreInterface, leade
 	chainStore := &chain.ChainStore{blockMgr.DatabaseService.LevelDb()}
 	context := chain.NewBlockExecuteContext(trieStore, gp, chainStore, block)
 
-	templateValidator := NewTemplateBlockValidator(blockMgr.ChainService)
-	err = templateValidator.ExecuteBlock(context, blockInterval)
+	templateValidator := NewTemplateBlockValidator(blockMgr.ChainService, blockMgr.txTimeout())
+	err = templateValidator.ExecuteBlock(context, deadline)
 	if err != nil {
 		return nil, nil, err
 	}
+	blockMgr.markPriorityIncluded(context.Block)
 	return context.Block, context.GasFee, nil
 }
+
+// templateBudget return the time spent executing the transactions of a block produced every
+// blockInterval seconds
+func (blockMgr *BlockMgr) templateBudget(blockInterval int) time.Duration {
+	budget := blockMgr.Config.TemplateBudget
+	if budget <= 0 || budget > 100 {
+		budget = DefaultTemplateBudget
+	}
+	return time.Duration(blockInterval) * time.Second * time.Duration(budget) / 100
+}
+
+// txTimeout return the time one transaction may execute while packing
+func (blockMgr *BlockMgr) txTimeout() time.Duration {
+	timeout := blockMgr.Config.TxTimeout
+	if timeout <= 0 {
+		timeout = DefaultTxTimeout
+	}
+	return time.Duration(timeout) * time.Millisecond
+}
@@ -0,0 +1,17 @@
+package blockmgr
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	// syncBlockMeter rate is the sync speed in blocks per second
+	syncBlockMeter   = metrics.NewRegisteredMeter("blockmgr/sync/blocks", nil)
+	syncHighestGauge = metrics.NewRegisteredGauge("blockmgr/sync/highest", nil)
+	// priorityIncludedMeter counts prioritized transactions in blocks packed locally
+	priorityIncludedMeter = metrics.NewRegisteredMeter("blockmgr/priority/included", nil)
+	// templateTimeoutMeter counts templates closed at the deadline with transactions left
+	templateTimeoutMeter = metrics.NewRegisteredMeter("blockmgr/template/timeout", nil)
+	// slowTxMeter counts transactions skipped for running over the tx timeout while packing
+	slowTxMeter = metrics.NewRegisteredMeter("blockmgr/template/slowtx", nil)
+	// privateTxMeter counts transactions sent to the producers only
+	privateTxMeter = metrics.NewRegisteredMeter("blockmgr/tx/private", nil)
+)
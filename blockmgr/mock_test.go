@@ -0,0 +1,85 @@
+package blockmgr
+
+import (
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/nat"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// p2pServiceMock is a p2p service without network, tests embed it and override what they observe
+type p2pServiceMock struct{}
+
+func (ps *p2pServiceMock) SendAsync(w p2p.MsgWriter, msgType uint64, msg interface{}) chan error {
+	return nil
+}
+func (ps *p2pServiceMock) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error {
+	return nil
+}
+func (ps *p2pServiceMock) Peers() []*p2p.Peer {
+	return nil
+}
+func (ps *p2pServiceMock) AddPeer(nodeUrl string) error {
+	return nil
+}
+func (ps *p2pServiceMock) RemovePeer(url string) {
+}
+func (ps *p2pServiceMock) AddProtocols(protocols []p2p.Protocol) {
+}
+func (ps *p2pServiceMock) SetChainId(chainId uint64) {
+}
+func (ps *p2pServiceMock) SetGenesis(hash crypto.Hash) {
+}
+func (ps *p2pServiceMock) SetForks(forks []uint64) {
+}
+func (ps *p2pServiceMock) SetNodeRole(role string) {
+}
+func (ps *p2pServiceMock) SetChainHead(lowest, highest uint64) {
+}
+func (ps *p2pServiceMock) LocalNode() *enode.Node {
+	return nil
+}
+func (ps *p2pServiceMock) NATStatus() (*nat.Status, error) {
+	return nil, nil
+}
+func (ps *p2pServiceMock) NATRemap() (*nat.Status, error) {
+	return nil, nil
+}
+func (ps *p2pServiceMock) Permissioned() bool {
+	return false
+}
+func (ps *p2pServiceMock) SetAllowedNodes(source string, ids []enode.ID) error {
+	return nil
+}
+func (ps *p2pServiceMock) Allowlist() (map[string][]enode.ID, error) {
+	return nil, nil
+}
+func (ps *p2pServiceMock) ReloadAllowlist() error {
+	return nil
+}
+func (ps *p2pServiceMock) SentryMode() bool {
+	return false
+}
+func (ps *p2pServiceMock) Sentry() bool {
+	return false
+}
+func (ps *p2pServiceMock) Name() string {
+	return ""
+}
+func (ps *p2pServiceMock) Api() []app.API {
+	return nil
+}
+func (ps *p2pServiceMock) CommandFlags() ([]cli.Command, []cli.Flag) {
+	return nil, nil
+}
+func (ps *p2pServiceMock) Init(executeContext *app.ExecuteContext) error {
+	return nil
+}
+func (ps *p2pServiceMock) Start(executeContext *app.ExecuteContext) error {
+	return nil
+}
+func (ps *p2pServiceMock) Stop(executeContext *app.ExecuteContext) error {
+	return nil
+}
@@ -44,6 +44,7 @@ func (blockMgr *BlockMgr) receiveMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter)
 
 	//Notify the synchronization coroutine
 	blockMgr.newPeerCh <- peer
+	blockMgr.sendAddressFilter(peer)
 
 	//2 Process all messages
 	return blockMgr.dealMsg(peer, rw)
@@ -63,6 +64,9 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 		if msg.Size > types.MaxMsgSize {
 			return ErrOverFlowMaxMsgSize
 		}
+		if !types.MsgSupported(peer.Version(), msg.Code) {
+			return errors.Wrapf(ErrMsgType, "type:%d not in protocol version:%d", msg.Code, peer.Version())
+		}
 
 		switch msg.Code {
 		case types.MsgTypeBlockReq:
@@ -89,16 +93,25 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 				log.WithField("transaction", tx.Nonce()).WithField("from", from.String()).Trace("comming transaction")
 				tx := tx
 				peer.MarkTx(tx)
+				blockMgr.txFetcher.done(*tx.TxHash())
 				blockMgr.SendTransaction(tx, false)
 			}
 
+		case types.MsgTypePrivateTx:
+			var tx types.Transaction
+			if err := msg.Decode(&tx); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "PrivateTx msg:%v err:%v", msg, err)
+			}
+			blockMgr.handlePrivateTx(peer, &tx)
+
 		case types.MsgTypeBlock:
 			var newBlock types.Block
 			if err := msg.Decode(&newBlock); err != nil {
 				return errors.Wrapf(ErrDecodeMsg, "Block msg:%v err:%v", msg, err)
 			}
 
-			_, isOrPhan, err := blockMgr.ChainService.ProcessBlock(&newBlock)
+			blockMgr.fetcher.done(*newBlock.Header.Hash())
+			_, isOrPhan, err := blockMgr.ChainService.ProcessPeerBlock(&newBlock, peer.GetAddr())
 			if err == nil {
 				//return err
 			}
@@ -133,6 +146,38 @@ func (blockMgr *BlockMgr) dealMsg(peer *types.PeerInfo, rw p2p.MsgReadWriter) er
 				return errors.Wrapf(ErrDecodeMsg, "HeaderRsp msg:%v err:%v", msg, err)
 			}
 			go blockMgr.handleHeaderRsp(peer, &resp)
+		case types.MsgTypeBlockAnnounce:
+			var announce types.BlockAnnounce
+			if err := msg.Decode(&announce); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "BlockAnnounce msg:%v err:%v", msg, err)
+			}
+			go blockMgr.handleBlockAnnounce(peer, &announce)
+		case types.MsgTypeBlockBodyReq:
+			var req types.BlockBodyReq
+			if err := msg.Decode(&req); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "BlockBodyReq msg:%v err:%v", msg, err)
+			}
+			go blockMgr.handleBlockBodyReq(peer, &req)
+		case types.MsgTypeTxAnnounce:
+			var announce types.TxAnnounce
+			if err := msg.Decode(&announce); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "TxAnnounce msg:%v err:%v", msg, err)
+			}
+			go blockMgr.handleTxAnnounce(peer, &announce)
+		case types.MsgTypeTxReq:
+			var req types.TxReq
+			if err := msg.Decode(&req); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "TxReq msg:%v err:%v", msg, err)
+			}
+			go blockMgr.handleTxReq(peer, &req)
+		case types.MsgTypeFilterLoad:
+			var req types.FilterLoad
+			if err := msg.Decode(&req); err != nil {
+				return errors.Wrapf(ErrDecodeMsg, "FilterLoad msg:%v err:%v", msg, err)
+			}
+			blockMgr.handleFilterLoad(peer, &req)
+		case types.MsgTypeFilterClear:
+			blockMgr.handleFilterClear(peer)
 		}
 	}
 
@@ -156,7 +201,8 @@ func (blockMgr *BlockMgr) handleHeaderRsp(peer types.PeerInfoInterface, rsp *typ
 	peer.CalcAverageRtt()
 	headerHashs := make([]*syncHeaderHash, 0, len(rsp.Headers))
 	for _, h := range rsp.Headers {
-		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: h.Hash(), height: h.Height})
+		prevHash := h.PreviousHash
+		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: h.Hash(), prevHash: &prevHash, height: h.Height})
 	}
 
 	//The requested associated coroutine is closed
@@ -230,5 +276,6 @@ func (blockMgr *BlockMgr) HandleBlockReqMsg(peer types.PeerInfoInterface, req *t
 
 func (blockMgr *BlockMgr) HandleBlockRespMsg(peer types.PeerInfoInterface, rsp *types.BlockResp) {
 	peer.CalcAverageRtt()
+	blockMgr.scheduler.delivered(peer, len(rsp.Blocks))
 	blockMgr.blocksCh <- rsp.Blocks
 }
@@ -0,0 +1,23 @@
+package blockmgr
+
+import (
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// advertiseHead keeps the block heights in the local node record in line
+// with the chain head, so discovery can tell how far a node has synced.
+func (blockMgr *BlockMgr) advertiseHead() {
+	newBlockCh := make(chan *types.ChainEvent, 10)
+	sub := blockMgr.ChainService.NewBlockFeed().Subscribe(newBlockCh)
+	defer sub.Unsubscribe()
+
+	blockMgr.P2pServer.SetChainHead(0, blockMgr.ChainService.BestChain().Height())
+	for {
+		select {
+		case e := <-newBlockCh:
+			blockMgr.P2pServer.SetChainHead(0, e.Block.Header.Height)
+		case <-blockMgr.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package blockmgr
+
+import (
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// NonceSource is the pool the nonce manager reads nonces from, the local pool or an upstream node
+type NonceSource interface {
+	GetTransactionCount(addr *crypto.CommonAddress) uint64
+	GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions
+}
+
+// NonceManager assign nonces to the transactions of local accounts.  A nonce is
+// reserved from Reserve until its transaction was sent, so concurrent senders
+// never get the same one, and the lowest nonce neither in the pool nor reserved
+// is assigned, so gaps left by dropped transactions are filled first.
+type NonceManager struct {
+	source   NonceSource
+	lock     sync.Mutex
+	reserved map[crypto.CommonAddress]map[uint64]struct{}
+}
+
+// NewNonceManager create a nonce manager on top of source
+func NewNonceManager(source NonceSource) *NonceManager {
+	return &NonceManager{
+		source:   source,
+		reserved: make(map[crypto.CommonAddress]map[uint64]struct{}),
+	}
+}
+
+// Reserve assign a nonce to a new transaction of addr, it must be released once the
+// transaction was sent, whether the send succeed or not
+func (manager *NonceManager) Reserve(addr *crypto.CommonAddress) uint64 {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	nonce := manager.nextNonce(addr)
+	if manager.reserved[*addr] == nil {
+		manager.reserved[*addr] = make(map[uint64]struct{})
+	}
+	manager.reserved[*addr][nonce] = struct{}{}
+	return nonce
+}
+
+// Hold reserve a given nonce of addr, e.g. the nonce of a transaction waiting to be signed
+// offline across a restart. It is released like a reserved nonce
+func (manager *NonceManager) Hold(addr *crypto.CommonAddress, nonce uint64) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	if manager.reserved[*addr] == nil {
+		manager.reserved[*addr] = make(map[uint64]struct{})
+	}
+	manager.reserved[*addr][nonce] = struct{}{}
+}
+
+// Release end the reservation of nonce, a sent transaction holds it in the pool from then on
+func (manager *NonceManager) Release(addr *crypto.CommonAddress, nonce uint64) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	reserved := manager.reserved[*addr]
+	delete(reserved, nonce)
+	if len(reserved) == 0 {
+		delete(manager.reserved, *addr)
+	}
+}
+
+// Pending return the nonce the next transaction of addr is assigned
+func (manager *NonceManager) Pending(addr *crypto.CommonAddress) uint64 {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	return manager.nextNonce(addr)
+}
+
+// Reset drop the reservations of addr, the nonce is derived from the pool again.
+// Transactions still being sent with a dropped nonce may collide afterwards
+func (manager *NonceManager) Reset(addr *crypto.CommonAddress) uint64 {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	delete(manager.reserved, *addr)
+	return manager.nextNonce(addr)
+}
+
+// Gaps return the missing nonces below the highest transaction of addr waiting in the pool
+func (manager *NonceManager) Gaps(addr *crypto.CommonAddress) []uint64 {
+	used, count := manager.usedNonces(addr)
+	gaps := []uint64{}
+	highest := maxNonce(used)
+	for nonce := count; nonce < highest; nonce++ {
+		if _, ok := used[nonce]; !ok {
+			gaps = append(gaps, nonce)
+		}
+	}
+	return gaps
+}
+
+// nextNonce return the lowest nonce from the pool count which is neither queued nor reserved.
+// The lock must be held
+func (manager *NonceManager) nextNonce(addr *crypto.CommonAddress) uint64 {
+	used, count := manager.usedNonces(addr)
+
+	// reservations below the count were mined or sent by others
+	reserved := manager.reserved[*addr]
+	for nonce := range reserved {
+		if nonce < count {
+			delete(reserved, nonce)
+		} else {
+			used[nonce] = struct{}{}
+		}
+	}
+
+	nonce := count
+	for {
+		if _, ok := used[nonce]; !ok {
+			break
+		}
+		nonce++
+	}
+	if highest := maxNonce(used); highest > nonce {
+		log.WithField("addr", addr.String()).WithField("nonce", nonce).WithField("highest", highest).Debug("fill nonce gap")
+	}
+	return nonce
+}
+
+// usedNonces return the nonces of addr queued in the pool above its transaction count, and the count
+func (manager *NonceManager) usedNonces(addr *crypto.CommonAddress) (map[uint64]struct{}, uint64) {
+	count := manager.source.GetTransactionCount(addr)
+	used := make(map[uint64]struct{})
+	for _, txs := range manager.source.GetPoolTransactions(addr) {
+		for _, tx := range txs {
+			if tx.Nonce() >= count {
+				used[tx.Nonce()] = struct{}{}
+			}
+		}
+	}
+	return used, count
+}
+
+func maxNonce(used map[uint64]struct{}) uint64 {
+	max := uint64(0)
+	for nonce := range used {
+		if nonce > max {
+			max = nonce
+		}
+	}
+	return max
+}
@@ -0,0 +1,94 @@
+package blockmgr
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+type nonceSourceMock struct {
+	count  uint64
+	queued []uint64
+}
+
+func (source *nonceSourceMock) GetTransactionCount(addr *crypto.CommonAddress) uint64 {
+	return source.count
+}
+
+func (source *nonceSourceMock) GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions {
+	txs := types.Transactions{}
+	for _, nonce := range source.queued {
+		txs = append(txs, *types.NewTransaction(*addr, new(big.Int), new(big.Int), new(big.Int), nonce))
+	}
+	return []types.Transactions{txs}
+}
+
+func TestNonceReserve(t *testing.T) {
+	addr := crypto.CommonAddress{}
+	manager := NewNonceManager(&nonceSourceMock{count: 5})
+
+	var lock sync.Mutex
+	seen := map[uint64]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce := manager.Reserve(&addr)
+			lock.Lock()
+			defer lock.Unlock()
+			if seen[nonce] {
+				t.Errorf("nonce %d assigned twice", nonce)
+			}
+			seen[nonce] = true
+		}()
+	}
+	wg.Wait()
+	for nonce := uint64(5); nonce < 15; nonce++ {
+		if !seen[nonce] {
+			t.Fatalf("nonce %d not assigned", nonce)
+		}
+	}
+
+	manager.Release(&addr, 7)
+	if nonce := manager.Pending(&addr); nonce != 7 {
+		t.Fatalf("got pending nonce %d, want released 7", nonce)
+	}
+	if nonce := manager.Reset(&addr); nonce != 5 {
+		t.Fatalf("got nonce %d after reset, want 5", nonce)
+	}
+
+	manager.Hold(&addr, 5)
+	if nonce := manager.Reserve(&addr); nonce != 6 {
+		t.Fatalf("got nonce %d, want 6 next to the held one", nonce)
+	}
+}
+
+func TestNonceGap(t *testing.T) {
+	addr := crypto.CommonAddress{}
+	source := &nonceSourceMock{count: 3, queued: []uint64{4, 6}}
+	manager := NewNonceManager(source)
+
+	gaps := manager.Gaps(&addr)
+	if len(gaps) != 2 || gaps[0] != 3 || gaps[1] != 5 {
+		t.Fatalf("got gaps %v, want [3 5]", gaps)
+	}
+	if nonce := manager.Reserve(&addr); nonce != 3 {
+		t.Fatalf("got nonce %d, want gap 3", nonce)
+	}
+	if nonce := manager.Reserve(&addr); nonce != 5 {
+		t.Fatalf("got nonce %d, want gap 5", nonce)
+	}
+	if nonce := manager.Reserve(&addr); nonce != 7 {
+		t.Fatalf("got nonce %d, want 7", nonce)
+	}
+
+	// the pool caught up, older reservations are dropped
+	source.count, source.queued = 8, nil
+	if nonce := manager.Pending(&addr); nonce != 8 {
+		t.Fatalf("got nonce %d, want 8", nonce)
+	}
+}
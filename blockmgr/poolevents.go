@@ -0,0 +1,88 @@
+package blockmgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/rpc"
+)
+
+// PoolEvent is a transaction accepted into, replaced in or dropped from the pool
+type PoolEvent struct {
+	Type       string                `json:"type"` // added, replaced or dropped
+	Hash       crypto.Hash           `json:"hash"`
+	From       *crypto.CommonAddress `json:"from"`
+	Nonce      uint64                `json:"nonce"`
+	GasPrice   *common.Big           `json:"gasPrice"`
+	Reason     string                `json:"reason,omitempty"`
+	ReplacedBy *crypto.Hash          `json:"replacedBy,omitempty"`
+	Time       int64                 `json:"time"`
+}
+
+// NewPoolEvent convert an event of the pool for subscribers
+func NewPoolEvent(ev *types.TxPoolEvent) *PoolEvent {
+	from, _ := ev.Tx.From()
+	return &PoolEvent{
+		Type:       ev.Type,
+		Hash:       *ev.Tx.TxHash(),
+		From:       from,
+		Nonce:      ev.Tx.Nonce(),
+		GasPrice:   (*common.Big)(ev.Tx.GasPrice()),
+		Reason:     ev.Reason,
+		ReplacedBy: ev.ReplacedBy,
+		Time:       time.Now().Unix(),
+	}
+}
+
+/*
+ name: poolEvents
+ usage: Subscribe the transactions accepted into the pool, and the ones replaced or dropped before being packed, over websocket
+ params:
+	1. senders to watch, all transactions if empty
+ return: subscription id
+ example: wscat -c ws://localhost:10084 -x '{"jsonrpc":"2.0","method":"blockmgr_subscribe","params":["poolEvents", []], "id": 3}'
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"0xcd0c3e8af590364c09d0fa6a1210faf5"}
+	{"jsonrpc":"2.0","method":"blockmgr_subscription","params":{"subscription":"0xcd0c3e8af590364c09d0fa6a1210faf5","result":{"type":"replaced","hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","from":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","nonce":15632,"gasPrice":"0x110","reason":"replaced","replacedBy":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","time":1592365562}}}
+*/
+func (blockMgrApi *BlockMgrAPI) PoolEvents(ctx context.Context, senders []crypto.CommonAddress) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	watched := make(map[crypto.CommonAddress]struct{}, len(senders))
+	for _, sender := range senders {
+		watched[sender] = struct{}{}
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		eventCh := make(chan types.TxPoolEvent, 1024)
+		sub := blockMgrApi.blockMgr.TxPoolEventFeed().Subscribe(eventCh)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-eventCh:
+				poolEvent := NewPoolEvent(&ev)
+				if len(watched) > 0 {
+					if poolEvent.From == nil {
+						continue
+					}
+					if _, ok := watched[*poolEvent.From]; !ok {
+						continue
+					}
+				}
+				notifier.Notify(rpcSub.ID, poolEvent)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,122 @@
+package blockmgr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/blockmgr/txpool"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	// Sources of tx priority rules
+	PrioritySourceFile = "file"
+	PrioritySourceApi  = "api"
+
+	priorityReloadInterval = 10 * time.Second
+)
+
+var (
+	TxPriorityFileFlag = cli.StringFlag{
+		Name:  "txpriority",
+		Usage: "Json file of senders and contracts whose transactions are packed first (relative to the homedir)",
+	}
+)
+
+// priorityPath returns the path of the priority file, empty if none is configured
+func (blockMgr *BlockMgr) priorityPath() string {
+	file := blockMgr.Config.PriorityFile
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(blockMgr.homeDir, file)
+}
+
+// ReloadTxPriority reads the priority file and replaces the file rules.
+// A missing file removes the file rules.
+func (blockMgr *BlockMgr) ReloadTxPriority() error {
+	file := blockMgr.priorityPath()
+	if file == "" {
+		return ErrNoPriorityFile
+	}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		log.WithField("file", file).Warn("Tx priority file not found")
+		blockMgr.transactionPool.Priority().SetRules(PrioritySourceFile, nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rules := &txpool.PriorityRules{}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return fmt.Errorf("tx priority file %s: %v", file, err)
+	}
+	blockMgr.transactionPool.Priority().SetRules(PrioritySourceFile, rules)
+	log.WithField("file", file).WithField("senders", len(rules.Senders)).WithField("contracts", len(rules.Contracts)).Info("Loaded tx priority rules")
+	return nil
+}
+
+// watchTxPriority reloads the priority file when its modification time or size changes.
+func (blockMgr *BlockMgr) watchTxPriority() {
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(blockMgr.priorityPath()); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+
+	ticker := time.NewTicker(priorityReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(blockMgr.priorityPath())
+			if err != nil {
+				if !modTime.IsZero() {
+					modTime, size = time.Time{}, 0
+					blockMgr.ReloadTxPriority()
+				}
+				continue
+			}
+			if info.ModTime().Equal(modTime) && info.Size() == size {
+				continue
+			}
+			modTime, size = info.ModTime(), info.Size()
+			if err := blockMgr.ReloadTxPriority(); err != nil {
+				log.WithField("err", err).Error("Reload tx priority rules")
+			}
+		case <-blockMgr.quit:
+			return
+		}
+	}
+}
+
+// SetTxPriority replaces the rules set through the api
+func (blockMgr *BlockMgr) SetTxPriority(rules *txpool.PriorityRules) {
+	blockMgr.transactionPool.Priority().SetRules(PrioritySourceApi, rules)
+}
+
+// TxPriority returns the priority rules of every source
+func (blockMgr *BlockMgr) TxPriority() map[string]*txpool.PriorityRules {
+	return blockMgr.transactionPool.Priority().Rules()
+}
+
+// markPriorityIncluded counts the prioritized transactions of a packed block
+func (blockMgr *BlockMgr) markPriorityIncluded(block *types.Block) {
+	priority := blockMgr.transactionPool.Priority()
+	if priority.Empty() {
+		return
+	}
+	count := 0
+	for _, tx := range block.Data.TxList {
+		if priority.Match(tx) {
+			count++
+		}
+	}
+	priorityIncludedMeter.Mark(int64(count))
+}
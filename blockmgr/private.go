@@ -0,0 +1,91 @@
+package blockmgr
+
+import (
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// IProducerNodes interface
+type IProducerNodes interface {
+	// SetProducerNodes set the nodes of the current producers, private transactions are sent to them
+	SetProducerNodes(ids []enode.ID)
+}
+
+// SetProducerNodes set the nodes of the current producers
+func (blockMgr *BlockMgr) SetProducerNodes(ids []enode.ID) {
+	nodes := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		nodes[id.String()] = struct{}{}
+	}
+	blockMgr.producerNodes.Store(nodes)
+}
+
+// isProducerNode whether id is the node of a current producer
+func (blockMgr *BlockMgr) isProducerNode(id string) bool {
+	nodes, _ := blockMgr.producerNodes.Load().(map[string]struct{})
+	_, ok := nodes[id]
+	return ok
+}
+
+// SendPrivateTransaction add tx to the pool and send it to the connected producers only, it is
+// neither gossiped nor announced, so it is not seen before it is packed
+func (blockMgr *BlockMgr) SendPrivateTransaction(tx *types.Transaction) error {
+	if app.InMaintenance() {
+		return app.ErrMaintenance
+	}
+	peers := []types.PeerInfoInterface{}
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		peer := value.(types.PeerInfoInterface)
+		if blockMgr.isProducerNode(key.(string)) && types.MsgSupported(peer.Version(), types.MsgTypePrivateTx) {
+			peers = append(peers, peer)
+		}
+		return true
+	})
+	isProducer := false
+	if local := blockMgr.P2pServer.LocalNode(); local != nil {
+		isProducer = blockMgr.isProducerNode(local.ID().String())
+	}
+	if len(peers) == 0 && !isProducer {
+		return ErrNoProducerPeer
+	}
+
+	if err := blockMgr.verifyTransaction(tx); err != nil {
+		return err
+	}
+	if err := blockMgr.transactionPool.AddPrivateTransaction(tx, true); err != nil {
+		return err
+	}
+	for _, peer := range peers {
+		peer.MarkTx(tx)
+		blockMgr.P2pServer.SendAsync(peer.GetMsgRW(), types.MsgTypePrivateTx, tx)
+	}
+	privateTxMeter.Mark(1)
+	log.WithField("hash", tx.TxHash()).WithField("producers", len(peers)).Debug("send private transaction")
+	return nil
+}
+
+// handlePrivateTx add a private transaction sent by peer, only producers take it and it is not
+// relayed any further
+func (blockMgr *BlockMgr) handlePrivateTx(peer types.PeerInfoInterface, tx *types.Transaction) {
+	local := blockMgr.P2pServer.LocalNode()
+	if local == nil || !blockMgr.isProducerNode(local.ID().String()) {
+		log.WithField("peer", peer.GetAddr()).Trace("drop private transaction, not a producer")
+		return
+	}
+	peer.MarkTx(tx)
+	if err := blockMgr.verifyTransaction(tx); err != nil {
+		log.WithField("hash", tx.TxHash()).WithField("Reason", err).Debug("private transaction rejected")
+		return
+	}
+	if err := blockMgr.transactionPool.AddPrivateTransaction(tx, false); err != nil {
+		log.WithField("hash", tx.TxHash()).WithField("Reason", err).Debug("private transaction rejected")
+	}
+}
+
+// isPrivate whether tx was sent privately and must not be announced, the pool keeps the mark
+// until tx is packed or dropped
+func (blockMgr *BlockMgr) isPrivate(hash *crypto.Hash) bool {
+	return blockMgr.transactionPool.IsPrivate(hash)
+}
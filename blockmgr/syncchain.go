@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/drep-project/DREP-Chain/chain"
@@ -24,6 +25,11 @@ func (blockMgr *BlockMgr) syncTxs() {
 	for {
 		select {
 		case task := <-blockMgr.taskTxsCh:
+			if types.MsgSupported(task.peer.Version(), types.MsgTypeTxAnnounce) {
+				blockMgr.announceTxs(task.peer, task.txs)
+				atomic.AddInt64(&blockMgr.broadcasts, -1)
+				continue
+			}
 			count := len(task.txs) / maxTxsCount
 			var i int
 			for i = 0; i < count; i++ {
@@ -32,6 +38,7 @@ func (blockMgr *BlockMgr) syncTxs() {
 			}
 
 			blockMgr.P2pServer.Send(task.peer.GetMsgRW(), types.MsgTypeTransaction, task.txs[count*maxTxsCount:])
+			atomic.AddInt64(&blockMgr.broadcasts, -1)
 		case <-blockMgr.quit:
 			return
 		}
@@ -62,8 +69,14 @@ func (blockMgr *BlockMgr) synchronise() {
 		txs := blockMgr.transactionPool.GetPending(new(big.Int).SetUint64(0xffffffffffffffff))
 		txs2 := blockMgr.transactionPool.GetQueue()
 
-		txs = append(txs, txs2...)
-		blockMgr.taskTxsCh <- tasksTxsSync{peer: peer, txs: append(txs, txs2...)}
+		matched := make([]*types.Transaction, 0, len(txs)+len(txs2))
+		for _, tx := range append(txs, txs2...) {
+			if peer.MatchTx(tx) && !blockMgr.isPrivate(tx.TxHash()) {
+				matched = append(matched, tx)
+			}
+		}
+		atomic.AddInt64(&blockMgr.broadcasts, 1)
+		blockMgr.taskTxsCh <- tasksTxsSync{peer: peer, txs: matched}
 	}
 
 	for {
@@ -189,7 +202,8 @@ func (blockMgr *BlockMgr) clearSyncCh() {
 	})
 }
 
-func (blockMgr *BlockMgr) batchReqBlocks(hashs []crypto.Hash, mapHeightHash map[crypto.Hash]uint64, errCh chan error) {
+// batchReqBlocks request bodies from the fastest idle peer which has the blocks, the peer is returned
+func (blockMgr *BlockMgr) batchReqBlocks(hashs []crypto.Hash, mapHeightHash map[crypto.Hash]uint64, errCh chan error) types.PeerInfoInterface {
 	req := &types.BlockReq{BlockHashs: hashs}
 
 	var maxHeight uint64 = 0
@@ -203,21 +217,22 @@ func (blockMgr *BlockMgr) batchReqBlocks(hashs []crypto.Hash, mapHeightHash map[
 		}
 	}
 
-	bestPeer := blockMgr.GetBestPeerInfo()
-	if bestPeer != nil {
+	peer := blockMgr.selectSyncPeer(maxHeight)
+	if peer != nil {
 		blockMgr.syncMut.Lock()
-		log.WithField("len", len(hashs)).WithField("from", minHeight).WithField("to:", maxHeight).WithField("destIp", bestPeer.GetAddr()).Info("req block body")
-		err := blockMgr.P2pServer.Send(bestPeer.GetMsgRW(), types.MsgTypeBlockReq, req)
-		bestPeer.SetReqTime(time.Now())
+		log.WithField("len", len(hashs)).WithField("from", minHeight).WithField("to:", maxHeight).WithField("destIp", peer.GetAddr()).Info("req block body")
+		blockMgr.scheduler.requested(peer)
+		err := blockMgr.P2pServer.Send(peer.GetMsgRW(), types.MsgTypeBlockReq, req)
+		peer.SetReqTime(time.Now())
 		blockMgr.syncMut.Unlock()
 
 		if err == nil {
-			return
+			return peer
 		}
 	}
 
 	errCh <- fmt.Errorf("p2p no peers")
-	return
+	return nil
 }
 
 func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
@@ -235,6 +250,8 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 	blockMgr.state = event.StartSyncBlock
 
 	height := peer.GetHeight()
+	blockMgr.startSyncProgress(height)
+	defer blockMgr.setSyncStage(SyncStageIdle)
 	blockMgr.clearSyncCh()
 	headerRoutineExit := false
 
@@ -245,57 +262,19 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 	}
 
 	log.Info("commonAncestor=", commonAncestor)
+	blockMgr.setSyncStage(SyncStageHeaders)
 
 	errCh := make(chan error)
 	quit := make(chan struct{})
-	//2 Gets the hash of all blocks that need to be synchronized; It then notifies the coroutine to get the BODY
+	//2 Gets the hash of all blocks that need to be synchronized from all peers; It then notifies the coroutine to get the BODY
 	go func() {
-		commonAncestor++
-		timer := time.NewTimer(time.Second * maxNetworkTimeout)
-
-		for height >= commonAncestor {
-			select {
-			case <-quit:
-				log.Info("fetch headers goroutine quit")
-				return
-			default:
-				timer.Reset(time.Second * maxNetworkTimeout)
-
-				blockMgr.syncMut.Lock()
-				taskLen := blockMgr.allTasks.Len()
-				blockMgr.syncMut.Unlock()
-				if taskLen >= pendingTimerCount {
-					time.Sleep(maxSyncSleepTime)
-					continue
-				}
-
-				blockMgr.syncMut.Lock()
-				err := blockMgr.requestHeaders(peer, commonAncestor, maxHeaderHashCountReq)
-				log.WithField("commonAncestor", commonAncestor).Info("req header")
-				blockMgr.syncMut.Unlock()
-				if err != nil {
-					errCh <- err
-					return
-				}
-
-				select {
-				//Each headerhash is a task
-				case tasks := <-blockMgr.headerHashCh:
-					for _, task := range tasks {
-						blockMgr.syncMut.Lock()
-						blockMgr.allTasks.Put(task)
-						blockMgr.syncMut.Unlock()
-					}
-					commonAncestor += uint64(len(tasks))
-					log.WithField("tasks len", blockMgr.allTasks.Len()).WithField("newtasks", len(tasks)).Info("get headers")
-				case <-timer.C:
-					errCh <- ErrGetHeaderHashTimeout
-					return
-
-				}
-			}
+		err := blockMgr.fetchHeaders(commonAncestor, height, quit)
+		if err != nil {
+			errCh <- err
+			return
 		}
 		log.Info("fetch all headers end ****************************")
+		blockMgr.setSyncStage(SyncStageBodies)
 		blockMgr.syncMut.Lock()
 		headerRoutineExit = true
 		blockMgr.syncMut.Unlock()
@@ -368,6 +347,7 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 							return
 						}
 					} else {
+						blockMgr.addPulledBlock()
 						//Delete the task corresponding to the block height
 						delHash(b)
 					}
@@ -389,14 +369,15 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 			default:
 				//The request was sent too fast and had to wait
 				count := 0
+				maxPending := blockMgr.maxPendingBodyReqs()
 				blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
 					count++
-					if count >= pendingTimerCount {
+					if count >= maxPending {
 						return false
 					}
 					return true
 				})
-				if count >= pendingTimerCount {
+				if count >= maxPending {
 					log.Info("req block body routine wait.......")
 					time.Sleep(time.Millisecond * maxSyncSleepTime)
 					continue
@@ -422,6 +403,7 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 
 				reqTimer := time.NewTimer(time.Second * maxNetworkTimeout)
 				blockMgr.pendingSyncTasks.Store(reqTimer, headerHashs)
+				reqPeer := blockMgr.batchReqBlocks(hashs, headerHashs, errCh)
 
 				go func() {
 					defer reqTimer.Stop()
@@ -444,6 +426,10 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 						}
 
 						blockMgr.pendingSyncTasks.Delete(reqTimer)
+						if reqPeer != nil {
+							//The tasks are reassigned to other peers, the slow peer is less likely selected
+							blockMgr.scheduler.timeout(reqPeer)
+						}
 						log.Errorf("req block body time out time：%p\n", reqTimer)
 
 					case timer := <-blockMgr.syncTimerCh:
@@ -455,7 +441,6 @@ func (blockMgr *BlockMgr) fetchBlocks(peer types.PeerInfoInterface) error {
 						return
 					}
 				}()
-				blockMgr.batchReqBlocks(hashs, headerHashs, errCh)
 			}
 		}
 	}()
@@ -501,7 +486,7 @@ func (blockMgr *BlockMgr) GetBestPeerInfo() types.PeerInfoInterface {
 			}
 
 		} else {
-			okPeers = make([]types.PeerInfoInterface, 0, getPeersCount(blockMgr.peersInfo))
+			okPeers = make([]types.PeerInfoInterface, 0, getPeersCount(&blockMgr.peersInfo))
 			tmpPeer = tmpValue.(types.PeerInfoInterface)
 			okPeers = append(okPeers, tmpValue.(types.PeerInfoInterface))
 		}
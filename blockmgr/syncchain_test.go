@@ -1,61 +1,17 @@
 package blockmgr
 
 import (
-	"math/big"
-	"os"
 	"testing"
 	"time"
 
-	"github.com/drep-project/DREP-Chain/app"
-	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/crypto"
-	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/DREP-Chain/network/p2p"
 	"github.com/drep-project/DREP-Chain/types"
-	"gopkg.in/urfave/cli.v1"
 )
 
-type p2pServiceMock struct {
-	app.Service
-}
-
-func (ps *p2pServiceMock) SendAsync(w p2p.MsgWriter, msgType uint64, msg interface{}) chan error {
-	return nil
-}
-func (ps *p2pServiceMock) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error {
-	return nil
-}
-func (ps *p2pServiceMock) Peers() []*p2p.Peer {
-	return nil
-}
-func (ps *p2pServiceMock) AddPeer(nodeUrl string) error {
-	return nil
-}
-func (ps *p2pServiceMock) RemovePeer(url string) {
-}
-func (ps *p2pServiceMock) AddProtocols(protocols []p2p.Protocol) {
-}
-func (ps *p2pServiceMock) Name() string {
-	return ""
-} // service  name must be unique
-func (ps *p2pServiceMock) Api() []app.API {
-	return nil
-} // Interfaces required for services
-func (ps *p2pServiceMock) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, nil
-}
-func (ps *p2pServiceMock) Init(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *p2pServiceMock) Start(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *p2pServiceMock) Stop(executeContext *app.ExecuteContext) error {
-	return nil
-}
-
 type peerInfoMock struct {
+	types.PeerInfoInterface
 	height uint64
 }
 
@@ -74,231 +30,157 @@ func (p *peerInfoMock) GetAddr() string {
 func (p *peerInfoMock) SetHeight(height uint64) {
 	p.height = height
 }
-func (p *peerInfoMock) KnownTx(tx *types.Transaction) bool {
-	return true
-}
-func (p *peerInfoMock) MarkTx(tx *types.Transaction) {
-
-}
-func (p *peerInfoMock) KnownBlock(blk *types.Block) bool {
-	return true
-}
-func (p *peerInfoMock) MarkBlock(blk *types.Block) {}
-
-//var pi types.PeerInfoInterface = &peerInfoMock{}
-
-type chainServiceMock struct {
-}
-
-func (ps *chainServiceMock) Name() string {
-	return ""
-} // service  name must be unique
-func (ps *chainServiceMock) Api() []app.API {
-	return nil
-} // Interfaces required for services
-func (ps *chainServiceMock) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, nil
-}
-func (ps *chainServiceMock) Init(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *chainServiceMock) Start(executeContext *app.ExecuteContext) error {
-	return nil
-}
-func (ps *chainServiceMock) Stop(executeContext *app.ExecuteContext) error {
-	return nil
-}
-
-func (ps *chainServiceMock) ChainID() app.ChainIdType {
-	return [64]byte{}
-}
-
-func (ps *chainServiceMock) DeriveMerkleRoot(txs []*types.Transaction) []byte {
-	return nil
-}
-func (ps *chainServiceMock) GetBlockByHash(hash *crypto.Hash) (*types.Block, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) GetBlockByHeight(number uint64) (*types.Block, error) {
-	return nil, nil
-}
-
-//DefaultChainConfig
-func (ps *chainServiceMock) GetBlockHeaderByHash(hash *crypto.Hash) (*types.BlockHeader, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) GetBlockHeaderByHeight(number uint64) (*types.BlockHeader, error) {
-	return nil, nil
-}
-
-func (ps *chainServiceMock) GetCurrentState() *database.Database {
-	return nil
-}
-func (ps *chainServiceMock) GetHeader(hash crypto.Hash, number uint64) *types.BlockHeader {
-	return nil
-}
-func (ps *chainServiceMock) GetHighestBlock() (*types.Block, error) {
-	return nil, nil
-}
-func (ps *chainServiceMock) RootChain() app.ChainIdType {
-	return [64]byte{}
-}
-
-func (ps *chainServiceMock) BestChain() *chain.ChainView {
-	cv := &chain.ChainView{}
-	return cv
-}
-func (ps *chainServiceMock) CalcGasLimit(parent *types.BlockHeader, gasFloor, gasCeil uint64) *big.Int {
-	return nil
-}
 
-func (ps *chainServiceMock) ProcessBlock(block *types.Block) (bool, bool, error) {
-	return true, true, nil
-}
-
-func (ps *chainServiceMock) NewBlockFeed() *event.Feed {
-	return nil
-}
-func (ps *chainServiceMock) BlockExists(blockHash *crypto.Hash) bool {
-	return true
-}
-func (ps *chainServiceMock) TransactionValidator() chain.ITransactionValidator {
-	return nil
-}
-func (ps *chainServiceMock) GetDatabaseService() *database.DatabaseService {
-	return nil
-}
-func (ps *chainServiceMock) Index() *chain.BlockIndex {
-	return nil
-}
-func (ps *chainServiceMock) BlockValidator() chain.IBlockValidator {
-	return nil
-}
-func (ps *chainServiceMock) Config() *types.ChainConfig {
-	return nil
-}
-func (ps *chainServiceMock) AccumulateRewards(db *database.Database, b *types.Block, totalGasBalance *big.Int) error {
-	return nil
-}
-func (ps *chainServiceMock) DetachBlockFeed() *event.Feed {
+func (p *peerInfoMock) SetReqTime(t time.Time) {}
+
+func (p *peerInfoMock) CalcAverageRtt() {}
+
+func (p *peerInfoMock) AverageRtt() time.Duration {
+	return 0
+}
+
+// remotePeerMock answer the header and body requests of the block manager with the blocks of
+// the remote chain, the way the message handlers of the remote node would
+type remotePeerMock struct {
+	p2pServiceMock
+	blockMgr *BlockMgr
+	peer     *peerInfoMock
+	blks     []*types.Block
+}
+
+func (ps *remotePeerMock) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error {
+	switch req := msg.(type) {
+	case *types.HeaderReq:
+		rsp := &types.HeaderRsp{}
+		for h := req.FromHeight; h <= req.ToHeight && h < uint64(len(ps.blks)); h++ {
+			rsp.Headers = append(rsp.Headers, *ps.blks[h].Header)
+		}
+		go ps.blockMgr.handleHeaderRsp(ps.peer, rsp)
+	case *types.BlockReq:
+		rsp := &types.BlockResp{}
+		for _, hash := range req.BlockHashs {
+			for _, b := range ps.blks {
+				if b.Header.Hash().IsEqual(&hash) {
+					rsp.Blocks = append(rsp.Blocks, b)
+				}
+			}
+		}
+		go ps.blockMgr.HandleBlockRespMsg(ps.peer, rsp)
+	}
 	return nil
 }
 
-//var bm *BlockMgr
-
-func prepareBase(t *testing.T) (*BlockMgr, []*types.Block) {
-	db, err := database.NewDatabase("./test/")
-	if err != nil {
-		t.Fatal(err)
+// prepareBase return a block manager whose chain holds the first local blocks of a remote
+// chain of 10 blocks, the remote peer is registered when the manager talks to it
+func prepareBase(t *testing.T, local int, remote bool) (*BlockMgr, *peerInfoMock, []*types.Block) {
+	blks := generatorChain(t, 10)
+	peer := &peerInfoMock{height: 10}
+	bm := &BlockMgr{
+		Config:       DefaultChainConfig,
+		ChainService: newSyncChain(blks[:local+1]),
+		headerHashCh: make(chan []*syncHeaderHash),
+		blocksCh:     make(chan []*types.Block),
+		allTasks:     newHeightSortedMap(),
+		scheduler:    newSyncScheduler(),
+		state:        event.StopSyncBlock,
+		syncTimerCh:  make(chan *time.Timer, maxLivePeer*maxPeerInflight),
 	}
-	blks := generatorChain(t, db)
-
-	ds := database.NewDatabaseService(db)
-	cs := chain.NewChainService(chain.DefaultChainConfig, ds)
-
-	bm := NewBlockMgr(DefaultChainConfig, "./", cs, &p2pServiceMock{})
-
-	return bm, blks
+	if remote {
+		bm.P2pServer = &remotePeerMock{blockMgr: bm, peer: peer, blks: blks}
+	} else {
+		bm.P2pServer = &p2pServiceMock{}
+	}
+	bm.peersInfo.Store(peer.GetAddr(), peer)
+	return bm, peer, blks
 }
 
 func TestFindAncestor(t *testing.T) {
-	bm, blks := prepareBase(t)
-
-	peerInfo := &peerInfoMock{}
-	headerHashs := []*syncHeaderHash{}
-
-	blks = blks[9:]
-	for _, b := range blks {
-		headerHashs = append(headerHashs, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
-	}
-
-	//time out
+	bm, peerInfo, _ := prepareBase(t, 4, true)
 	ancestor, err := bm.findAncestor(peerInfo)
-	if err == nil {
+	if err != nil {
 		t.Fatal(err)
 	}
+	if ancestor != 4 {
+		t.Fatal("get ancestor err", "need:", 4, "ancestor：", ancestor)
+	}
 
+	// the remote knows the local chain entirely
+	bm, peerInfo, blks := prepareBase(t, 10, false)
 	go func() {
+		headerHashs := []*syncHeaderHash{}
+		for _, b := range blks[9:] {
+			headerHashs = append(headerHashs, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+		}
 		bm.headerHashCh <- headerHashs
 	}()
 	ancestor, err = bm.findAncestor(peerInfo)
 	if err != nil {
 		t.Fatal(err)
 	}
-
 	if ancestor != 10 {
-		t.Fatal("get ancestor err", "need:", 0, "ancestor：", ancestor)
-	}
-
-	os.RemoveAll("./test")
-}
-
-func TestFetchBlocks(t *testing.T) {
-
-	defer os.RemoveAll("./test")
-
-	peer := &peerInfoMock{}
-	headerHashs1 := []*syncHeaderHash{}
-	headerHashs2 := []*syncHeaderHash{}
-
-	bm, blks := prepareBase(t)
-
-	blks1 := blks[1:2]
-	for _, b := range blks1 {
-		headerHashs1 = append(headerHashs1, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+		t.Fatal("get ancestor err", "need:", 10, "ancestor：", ancestor)
 	}
 
+	// headers unknown locally
 	go func() {
-		time.Sleep(time.Second * 4)
-		bm.headerHashCh <- headerHashs1
+		bm.headerHashCh <- []*syncHeaderHash{{headerHash: &crypto.Hash{1}, height: 11}}
 	}()
+	if _, err := bm.findAncestor(peerInfo); err != ErrNoCommonAncesstor {
+		t.Fatalf("unknown headers: got %v, want %v", err, ErrNoCommonAncesstor)
+	}
 
-	//fake header hash
-	blks2 := blks[2:4]
-	for _, b := range blks2 {
-		headerHashs2 = append(headerHashs2, &syncHeaderHash{headerHash: b.Header.Hash(), height: b.Header.Height})
+	if testing.Short() {
+		return
 	}
+	//time out
+	if _, err := bm.findAncestor(peerInfo); err != ErrFindAncesstorTimeout {
+		t.Fatalf("silent peer: got %v, want %v", err, ErrFindAncesstorTimeout)
+	}
+}
 
-	go func() {
-		time.Sleep(time.Second * 8)
-		bm.headerHashCh <- headerHashs2
-	}()
+func TestFetchBlocks(t *testing.T) {
+	bm, peer, blks := prepareBase(t, 4, true)
 
-	//fake block body
+	errCh := make(chan error, 1)
 	go func() {
-		time.Sleep(time.Second * 12)
-		bm.blocksCh <- blks[2:4]
+		errCh <- bm.fetchBlocks(peer)
 	}()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("fetch blocks did not complete")
+	}
 
-	peer.height = 4
-	bm.peersInfo["127.0.0.1"] = peer
-	err := bm.fetchBlocks(peer)
-	if err != nil {
-		t.Fatal(err)
+	tip := bm.ChainService.BestChain().Tip()
+	if tip.Height != 10 || !tip.Hash.IsEqual(blks[10].Header.Hash()) {
+		t.Fatalf("synced to %d, want the remote tip 10", tip.Height)
+	}
+	if progress := bm.SyncProgress(); progress.Syncing || progress.PulledStates != 6 {
+		t.Fatalf("unexpected progress after sync %+v", progress)
 	}
 }
 
 func TestClearSyncCh(t *testing.T) {
-	//clearSyncCh()
-	//select {
-	//case <-blockMgr.headerHashCh:
-	//default:
-	//}
-	//
-	//select {
-	//case <-blockMgr.blocksCh:
-	//default:
-	//}
-	//
-	//select {
-	//case <-blockMgr.syncTimerCh:
-	//default:
-	//}
-	//
-	//blockMgr.allTasks = newHeightSortedMap()
-	//
-	//blockMgr.pendingSyncTasks.Range(func(key, value interface{}) bool {
-	//	blockMgr.pendingSyncTasks.Delete(key)
-	//	return true
-	//})
+	bm, _, blks := prepareBase(t, 0, false)
+	bm.syncTimerCh <- time.NewTimer(time.Minute)
+	bm.allTasks.Put(&syncHeaderHash{headerHash: blks[1].Header.Hash(), height: 1})
+	bm.pendingSyncTasks.Store(time.NewTimer(time.Minute), map[crypto.Hash]uint64{*blks[2].Header.Hash(): 2})
+
+	bm.clearSyncCh()
+
+	select {
+	case <-bm.syncTimerCh:
+		t.Fatal("sync timer not cleared")
+	default:
+	}
+	if bm.allTasks.Len() != 0 {
+		t.Fatalf("%d sync tasks not cleared", bm.allTasks.Len())
+	}
+	bm.pendingSyncTasks.Range(func(key, value interface{}) bool {
+		t.Fatal("pending sync tasks not cleared")
+		return false
+	})
 }
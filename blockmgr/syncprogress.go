@@ -0,0 +1,142 @@
+package blockmgr
+
+import (
+	"context"
+
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/rpc"
+)
+
+// stages of block synchronization
+const (
+	SyncStageIdle         = "idle"         //Not syncing
+	SyncStageFindAncestor = "findAncestor" //Finding the common ancestor with the best peer
+	SyncStageHeaders      = "headers"      //Downloading headers and bodies
+	SyncStageBodies       = "bodies"       //All headers downloaded, waiting for the remaining bodies
+)
+
+// SyncProgress is the progress of the current or last block synchronization
+type SyncProgress struct {
+	Syncing       bool   `json:"syncing"`
+	StartingBlock uint64 `json:"startingBlock"` //Local height when sync started
+	CurrentBlock  uint64 `json:"currentBlock"`  //Local height
+	HighestBlock  uint64 `json:"highestBlock"`  //The highest height known from peers
+	PulledStates  uint64 `json:"pulledStates"`  //Blocks pulled from peers and imported in this round
+	Stage         string `json:"stage"`
+}
+
+// startSyncProgress reset the progress when a new round of sync begin
+func (blockMgr *BlockMgr) startSyncProgress(highest uint64) {
+	blockMgr.progressLock.Lock()
+	blockMgr.progress = SyncProgress{
+		Syncing:       true,
+		StartingBlock: blockMgr.ChainService.BestChain().Height(),
+		HighestBlock:  highest,
+		Stage:         SyncStageFindAncestor,
+	}
+	blockMgr.progressLock.Unlock()
+	syncHighestGauge.Update(int64(highest))
+	blockMgr.syncProgressFeed.Send(blockMgr.SyncProgress())
+}
+
+// setSyncStage notify subscribers if stage changed
+func (blockMgr *BlockMgr) setSyncStage(stage string) {
+	blockMgr.progressLock.Lock()
+	if blockMgr.progress.Stage == stage {
+		blockMgr.progressLock.Unlock()
+		return
+	}
+	blockMgr.progress.Stage = stage
+	blockMgr.progress.Syncing = stage != SyncStageIdle
+	blockMgr.progressLock.Unlock()
+	blockMgr.syncProgressFeed.Send(blockMgr.SyncProgress())
+}
+
+func (blockMgr *BlockMgr) addPulledBlock() {
+	blockMgr.progressLock.Lock()
+	blockMgr.progress.PulledStates++
+	blockMgr.progressLock.Unlock()
+	syncBlockMeter.Mark(1)
+}
+
+// SyncProgress return the progress of synchronization
+func (blockMgr *BlockMgr) SyncProgress() SyncProgress {
+	blockMgr.progressLock.RLock()
+	progress := blockMgr.progress
+	blockMgr.progressLock.RUnlock()
+
+	if progress.Stage == "" {
+		progress.Stage = SyncStageIdle
+	}
+	progress.CurrentBlock = blockMgr.ChainService.BestChain().Height()
+	if peer := blockMgr.GetBestPeerInfo(); peer != nil && peer.GetHeight() > progress.HighestBlock {
+		progress.HighestBlock = peer.GetHeight()
+	}
+	if progress.HighestBlock < progress.CurrentBlock {
+		progress.HighestBlock = progress.CurrentBlock
+	}
+	return progress
+}
+
+// SubscribeSyncProgress subscribe progress of synchronization, it is sent when stage changed
+func (blockMgr *BlockMgr) SubscribeSyncProgress(subchan chan SyncProgress) event.Subscription {
+	return blockMgr.syncProgressFeed.Subscribe(subchan)
+}
+
+/*
+name: Sync status
+usage: Query the progress of block synchronization
+prefix:chain
+*/
+type SyncApi struct {
+	blockMgr *BlockMgr
+}
+
+/*
+ name: syncing
+ usage: Query the progress of block synchronization
+ params:
+ return: starting block, current block, highest known block, blocks pulled in this round and the sync stage(idle, findAncestor, headers, bodies)
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_syncing","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"syncing":true,"startingBlock":1200,"currentBlock":3400,"highestBlock":10020,"pulledStates":2200,"stage":"headers"}}
+*/
+func (syncApi *SyncApi) Syncing() SyncProgress {
+	return syncApi.blockMgr.SyncProgress()
+}
+
+/*
+ name: syncProgress
+ usage: Subscribe the progress of block synchronization over websocket, a notification is sent each time the sync stage change
+ params:
+ return: subscription id
+ example: wscat -c ws://localhost:10084 -x '{"jsonrpc":"2.0","method":"chain_subscribe","params":["syncProgress"], "id": 3}'
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"0xcd0c3e8af590364c09d0fa6a1210faf5"}
+	{"jsonrpc":"2.0","method":"chain_subscription","params":{"subscription":"0xcd0c3e8af590364c09d0fa6a1210faf5","result":{"syncing":false,"startingBlock":1200,"currentBlock":10020,"highestBlock":10020,"pulledStates":8820,"stage":"idle"}}}
+*/
+func (syncApi *SyncApi) SyncProgress(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		progressCh := make(chan SyncProgress, 4)
+		sub := syncApi.blockMgr.SubscribeSyncProgress(progressCh)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case progress := <-progressCh:
+				notifier.Notify(rpcSub.ID, progress)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,264 @@
+package blockmgr
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	maxPeerInflight      = 2 //The maximum number of outstanding sync requests of a single peer
+	maxHeaderInflight    = 4 //The maximum number of header batches requested at the same time
+	maxHeaderLinkRetries = 3 //Header batches not linked to local chain are requested again from other peer at most times
+)
+
+// peerSyncStat tracks the outstanding requests and the throughput of one peer during sync
+type peerSyncStat struct {
+	inflight int
+	lastReq  time.Time
+	items    uint64        //headers and blocks delivered
+	busy     time.Duration //time spent between request and response
+	timeouts int
+}
+
+// score is the measured throughput of the peer, penalized by timeouts.
+// Peers without any measurement are preferred so that every peer gets tried.
+func (stat *peerSyncStat) score() float64 {
+	if stat.busy == 0 {
+		return 1e9 / float64(1+stat.timeouts)
+	}
+	return float64(stat.items) / stat.busy.Seconds() / float64(1+stat.timeouts)
+}
+
+// syncScheduler stripes sync requests across all live peers
+type syncScheduler struct {
+	lock  sync.Mutex
+	stats map[string]*peerSyncStat
+}
+
+func newSyncScheduler() *syncScheduler {
+	return &syncScheduler{stats: make(map[string]*peerSyncStat)}
+}
+
+func (scheduler *syncScheduler) stat(peer types.PeerInfoInterface) *peerSyncStat {
+	stat, ok := scheduler.stats[peer.GetAddr()]
+	if !ok {
+		stat = &peerSyncStat{}
+		scheduler.stats[peer.GetAddr()] = stat
+	}
+	return stat
+}
+
+// selectPeer return the idle peer with best throughput whose height reach minHeight
+func (scheduler *syncScheduler) selectPeer(peers []types.PeerInfoInterface, minHeight uint64) types.PeerInfoInterface {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	var best types.PeerInfoInterface
+	var bestScore float64
+	for _, i := range rand.Perm(len(peers)) {
+		peer := peers[i]
+		if peer.GetHeight() < minHeight {
+			continue
+		}
+		stat := scheduler.stat(peer)
+		if stat.inflight >= maxPeerInflight {
+			continue
+		}
+		if score := stat.score(); best == nil || score > bestScore {
+			best, bestScore = peer, score
+		}
+	}
+	return best
+}
+
+func (scheduler *syncScheduler) requested(peer types.PeerInfoInterface) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	stat := scheduler.stat(peer)
+	if stat.inflight == 0 {
+		stat.lastReq = time.Now()
+	}
+	stat.inflight++
+}
+
+func (scheduler *syncScheduler) delivered(peer types.PeerInfoInterface, count int) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	stat := scheduler.stat(peer)
+	if stat.inflight > 0 {
+		now := time.Now()
+		stat.busy += now.Sub(stat.lastReq)
+		stat.lastReq = now
+		stat.inflight--
+	}
+	stat.items += uint64(count)
+}
+
+func (scheduler *syncScheduler) timeout(peer types.PeerInfoInterface) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	stat := scheduler.stat(peer)
+	if stat.inflight > 0 {
+		stat.inflight--
+	}
+	stat.timeouts++
+}
+
+// livePeers return all peers which communicate with this module
+func (blockMgr *BlockMgr) livePeers() []types.PeerInfoInterface {
+	peers := []types.PeerInfoInterface{}
+	blockMgr.peersInfo.Range(func(key, value interface{}) bool {
+		peers = append(peers, value.(types.PeerInfoInterface))
+		return true
+	})
+	return peers
+}
+
+// selectSyncPeer pick a peer for the range ending at minHeight, the best peer is used if all peers are busy
+func (blockMgr *BlockMgr) selectSyncPeer(minHeight uint64) types.PeerInfoInterface {
+	if peer := blockMgr.scheduler.selectPeer(blockMgr.livePeers(), minHeight); peer != nil {
+		return peer
+	}
+	return blockMgr.GetBestPeerInfo()
+}
+
+// maxPendingBodyReqs scale the number of concurrent body requests with live peers
+func (blockMgr *BlockMgr) maxPendingBodyReqs() int {
+	count := getPeersCount(&blockMgr.peersInfo) * maxPeerInflight
+	if count < pendingTimerCount {
+		return pendingTimerCount
+	}
+	return count
+}
+
+// headerRange is a header batch request waiting for response
+type headerRange struct {
+	from  uint64
+	count uint64
+	peer  types.PeerInfoInterface
+	sent  time.Time
+}
+
+// fetchHeaders request header batches of (from, to] from several peers at the same time,
+// batches are validated in order that each one must link to the previous one before
+// the hashes become body download tasks.
+func (blockMgr *BlockMgr) fetchHeaders(from, to uint64, quit chan struct{}) error {
+	ancestor := blockMgr.ChainService.BestChain().NodeByHeight(from)
+	if ancestor == nil {
+		return ErrNoCommonAncesstor
+	}
+	lastHash := *ancestor.Hash
+	next := from + 1    //The next height to be validated
+	nextReq := from + 1 //The next height to be requested
+	inflight := map[uint64]*headerRange{}
+	buffered := map[uint64][]*syncHeaderHash{}
+	retries := []*headerRange{}
+	linkFails := 0
+
+	ticker := time.NewTicker(time.Millisecond * maxSyncSleepTime)
+	defer ticker.Stop()
+
+	for next <= to {
+		//The body download is too slow, wait
+		blockMgr.syncMut.Lock()
+		taskLen := blockMgr.allTasks.Len()
+		blockMgr.syncMut.Unlock()
+		throttle := taskLen >= maxHeaderHashCountReq*maxHeaderInflight
+
+		//Fill the pipeline, timed out and broken ranges first
+		for !throttle && len(inflight) < maxHeaderInflight {
+			var req *headerRange
+			if len(retries) > 0 {
+				req, retries = retries[0], retries[1:]
+			} else if nextReq <= to {
+				count := uint64(maxHeaderHashCountReq)
+				if nextReq+count-1 > to {
+					count = to - nextReq + 1
+				}
+				req = &headerRange{from: nextReq, count: count}
+				nextReq += count
+			} else {
+				break
+			}
+
+			req.peer = blockMgr.selectSyncPeer(req.from + req.count - 1)
+			if req.peer == nil {
+				return ErrGetHeaderHashTimeout
+			}
+			req.sent = time.Now()
+			blockMgr.scheduler.requested(req.peer)
+			blockMgr.syncMut.Lock()
+			err := blockMgr.requestHeaders(req.peer, req.from, req.count)
+			blockMgr.syncMut.Unlock()
+			if err != nil {
+				return err
+			}
+			inflight[req.from] = req
+		}
+
+		select {
+		case <-quit:
+			log.Info("fetch headers goroutine quit")
+			return nil
+		case <-ticker.C:
+			//Reassign the timed out ranges to other peers
+			for start, req := range inflight {
+				if time.Since(req.sent) > time.Second*maxNetworkTimeout {
+					log.WithField("from", req.from).WithField("peer", req.peer.GetAddr()).Warn("req headers timeout, reassign")
+					blockMgr.scheduler.timeout(req.peer)
+					delete(inflight, start)
+					retries = append(retries, req)
+				}
+			}
+		case hashs := <-blockMgr.headerHashCh:
+			if len(hashs) == 0 {
+				continue
+			}
+			req, ok := inflight[hashs[0].height]
+			if !ok {
+				continue
+			}
+			delete(inflight, req.from)
+			blockMgr.scheduler.delivered(req.peer, len(hashs))
+			if uint64(len(hashs)) < req.count {
+				//The peer only has part of the range, request the rest from others
+				retries = append(retries, &headerRange{from: req.from + uint64(len(hashs)), count: req.count - uint64(len(hashs))})
+			}
+			buffered[req.from] = hashs
+		}
+
+		//Validate buffered batches in order
+		for {
+			hashs, ok := buffered[next]
+			if !ok {
+				break
+			}
+			delete(buffered, next)
+			if !hashs[0].prevHash.IsEqual(&lastHash) {
+				linkFails++
+				log.WithField("height", next).WithField("fails", linkFails).Warn("header batch not linked to local chain")
+				if linkFails > maxHeaderLinkRetries {
+					return ErrNotContinueHeader
+				}
+				retries = append(retries, &headerRange{from: next, count: uint64(len(hashs))})
+				break
+			}
+
+			blockMgr.syncMut.Lock()
+			for _, task := range hashs {
+				blockMgr.allTasks.Put(task)
+			}
+			blockMgr.syncMut.Unlock()
+			lastHash = *hashs[len(hashs)-1].headerHash
+			next += uint64(len(hashs))
+			log.WithField("tasks len", taskLen+len(hashs)).WithField("newtasks", len(hashs)).Info("get headers")
+		}
+	}
+	return nil
+}
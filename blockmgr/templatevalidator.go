@@ -1,7 +1,6 @@
 package blockmgr
 
 import (
-	"fmt"
 	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
@@ -11,11 +10,12 @@ import (
 )
 
 type TemplateBlockValidator struct {
-	chain chain.ChainServiceInterface
+	chain     chain.ChainServiceInterface
+	txTimeout time.Duration //max time executing one transaction, 0 for no limit
 }
 
-func NewTemplateBlockValidator(chain chain.ChainServiceInterface) *TemplateBlockValidator {
-	return &TemplateBlockValidator{chain}
+func NewTemplateBlockValidator(chain chain.ChainServiceInterface, txTimeout time.Duration) *TemplateBlockValidator {
+	return &TemplateBlockValidator{chain, txTimeout}
 }
 
 func (chainBlockValidator *TemplateBlockValidator) VerifyHeader(header, parent *types.BlockHeader) error {
@@ -26,7 +26,9 @@ func (chainBlockValidator *TemplateBlockValidator) VerifyBody(block *types.Block
 	return nil
 }
 
-func (chainBlockValidator *TemplateBlockValidator) ExecuteBlock(context *chain.BlockExecuteContext, blockInterval int) error {
+// ExecuteBlock execute the transactions of the template until deadline, the block keeps the
+// transactions executed by then. A transaction running over the tx timeout is skipped
+func (chainBlockValidator *TemplateBlockValidator) ExecuteBlock(context *chain.BlockExecuteContext, deadline time.Time) error {
 	context.Receipts = make([]*types.Receipt, context.Block.Data.TxCount)
 	context.Logs = make([]*types.Log, 0)
 	if len(context.Block.Data.TxList) < 0 {
@@ -35,61 +37,66 @@ func (chainBlockValidator *TemplateBlockValidator) ExecuteBlock(context *chain.B
 
 	finalTxs := make([]*types.Transaction, 0, len(context.Block.Data.TxList))
 	finalReceipts := make([]*types.Receipt, 0, len(context.Block.Data.TxList))
-	//time control
-	stopchanel := make(chan struct{}, 1)
-	//80%time ,use for get tx
-	timeout := 1000 * blockInterval * 8 / 10
-	fmt.Println("executeblock", blockInterval, timeout, time.Millisecond*time.Duration(timeout), time.Now())
-
-	tm := time.AfterFunc(time.Millisecond*time.Duration(timeout), func() {
-		fmt.Println("exec block timeout ", time.Now())
-		stopchanel <- struct{}{}
-	})
+	log.WithField("deadline", deadline).WithField("txTimeout", chainBlockValidator.txTimeout).Trace("execute template block")
 	defer func() {
 		context.Block.Data.TxList = finalTxs
 		context.Block.Data.TxCount = uint64(len(finalTxs))
 		context.Receipts = finalReceipts
 		context.Block.Header.GasUsed = *context.GasUsed
-		context.Block.Header.TxRoot = chainBlockValidator.chain.DeriveMerkleRoot(finalTxs)
+		context.Block.Header.TxRoot = chainBlockValidator.chain.DeriveMerkleRoot(context.Block.Header.Height, finalTxs)
 		context.Block.Header.ReceiptRoot = chainBlockValidator.chain.DeriveReceiptRoot(finalReceipts)
 		context.Block.Header.Bloom = types.CreateBloom(finalReceipts)
 	}()
-SELECT_TX:
 	for _, t := range context.Block.Data.TxList {
+		if !time.Now().Before(deadline) {
+			log.WithField("packed", len(finalTxs)).WithField("total", len(context.Block.Data.TxList)).Debug("execute template block timeout")
+			templateTimeoutMeter.Mark(1)
+			break
+		}
 		snap := context.TrieStore.CopyState()
 		backGp := *context.Gp
-		select {
-		case <-stopchanel:
-			break SELECT_TX
-		default:
-			receipt, gasUsed, err := chainBlockValidator.RouteTransaction(context, context.Gp, t)
-			if err == nil {
-				finalTxs = append(finalTxs, t)
-				finalReceipts = append(finalReceipts, receipt)
-				gasUsedBig := new(big.Int).SetUint64(gasUsed)
-				context.AddGasUsed(gasUsedBig)
-				gasFee := new(big.Int).Mul(gasUsedBig, t.GasPrice())
-				context.AddGasFee(gasFee)
-			} else if err == chain.ErrOutOfGas {
-				// return while out of gas
-				context.TrieStore.RevertState(snap)
-				context.Gp = &backGp
-				return nil
+		receipt, gasUsed, err := chainBlockValidator.RouteTransaction(context, context.Gp, t, chainBlockValidator.txDeadline(deadline))
+		if err == nil {
+			finalTxs = append(finalTxs, t)
+			finalReceipts = append(finalReceipts, receipt)
+			gasUsedBig := new(big.Int).SetUint64(gasUsed)
+			context.AddGasUsed(gasUsedBig)
+			gasFee := new(big.Int).Mul(gasUsedBig, t.GasPrice())
+			context.AddGasFee(gasFee)
+		} else if err == chain.ErrOutOfGas {
+			// return while out of gas
+			context.TrieStore.RevertState(snap)
+			context.Gp = &backGp
+			return nil
+		} else {
+			from, _ := t.From()
+			if err == chain.ErrTxTimeout {
+				log.WithField("from", from.String()).WithField("tx nonce", t.Nonce()).Warn("tx execution timeout, skipped")
+				slowTxMeter.Mark(1)
 			} else {
-				from, _ := t.From()
 				log.WithField("err", err).WithField("from", from.String()).WithField("tx nonce", t.Nonce()).Info("route tx")
-				//skip wrong tx
-				context.TrieStore.RevertState(snap)
-				context.Gp = &backGp
-				continue
 			}
+			//skip wrong tx
+			context.TrieStore.RevertState(snap)
+			context.Gp = &backGp
+			continue
 		}
 	}
-	tm.Stop()
 	return nil
 }
 
-func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *chain.BlockExecuteContext, gasPool *chain.GasPool, tx *types.Transaction) (*types.Receipt, uint64, error) {
+// txDeadline return the time the execution of the next transaction is aborted at, its
+// timeout from now but no later than the template deadline
+func (chainBlockValidator *TemplateBlockValidator) txDeadline(deadline time.Time) time.Time {
+	if chainBlockValidator.txTimeout > 0 {
+		if txDeadline := time.Now().Add(chainBlockValidator.txTimeout); txDeadline.Before(deadline) {
+			return txDeadline
+		}
+	}
+	return deadline
+}
+
+func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *chain.BlockExecuteContext, gasPool *chain.GasPool, tx *types.Transaction, deadline time.Time) (*types.Receipt, uint64, error) {
 	//init transaction tx
 	from, err := tx.From()
 	if err != nil {
@@ -97,6 +104,8 @@ func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *cha
 	}
 
 	txContext := chain.NewExecuteTransactionContext(context, context.TrieStore, gasPool, from, tx)
+	txContext.SetGenesisParams(chainBlockValidator.chain.GenesisParams())
+	txContext.SetDeadline(deadline)
 	if err := txContext.PreCheck(); err != nil {
 		return nil, 0, err
 	}
@@ -130,7 +139,6 @@ func (chainBlockValidator *TemplateBlockValidator) RouteTransaction(context *cha
 			// if the transaction created a contract, store the creation address in the receipt.
 			if (tx.To() == nil || tx.To().IsEmpty()) && tx.Type() == types.CreateContractType {
 				receipt.ContractAddress = crypto.CreateAddress(*from, tx.Nonce())
-				fmt.Println("contractAddr:", receipt.ContractAddress)
 			}
 			// Set the receipt logs and create a bloom for filtering
 			receipt.Logs = ret.ContractTxLog
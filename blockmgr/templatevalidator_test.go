@@ -0,0 +1,170 @@
+package blockmgr
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// templateChain is a chain service executing transactions with the validators of the test only
+type templateChain struct {
+	*chain.ChainService
+	validators map[chain.ITransactionSelector]chain.ITransactionValidator
+}
+
+func (c *templateChain) TransactionValidators() map[chain.ITransactionSelector]chain.ITransactionValidator {
+	return c.validators
+}
+
+type anyTxSelector struct{}
+
+func (anyTxSelector) Select(tx *types.Transaction) bool { return true }
+
+// slowTxValidator execute transactions doing nothing, the slow ones run until their deadline
+// and fail the way the evm does when cancelled
+type slowTxValidator struct {
+	slow map[crypto.Hash]bool
+}
+
+func (v *slowTxValidator) ExecuteTransaction(context *chain.ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	if v.slow[*context.Tx().TxHash()] {
+		time.Sleep(time.Until(context.Deadline()))
+		return &types.ExecuteTransactionResult{Txerror: chain.ErrTxTimeout}
+	}
+	return &types.ExecuteTransactionResult{}
+}
+
+// newTemplateContext return the execute context of a template holding count transactions of
+// funded senders, and the validator they are executed with
+func newTemplateContext(t *testing.T, count int) (*chain.BlockExecuteContext, *TemplateBlockValidator, *slowTxValidator) {
+	disk := memorydb.New()
+	if err := disk.Put([]byte(store.ChangeInterval), new(big.Int).SetUint64(100).FillBytes(make([]byte, 8))); err != nil {
+		t.Fatal(err)
+	}
+	trieStore, err := store.TrieStoreFromCache(disk, nil, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txs := make([]*types.Transaction, count)
+	for i := range txs {
+		privKey, _ := crypto.GenerateKey(rand.Reader)
+		from := crypto.PubkeyToAddress(privKey.PubKey())
+		if err := trieStore.PutBalance(&from, 1, big.NewInt(1000000)); err != nil {
+			t.Fatal(err)
+		}
+		tx := types.NewTransaction(crypto.CommonAddress{1}, big.NewInt(int64(i+1)), big.NewInt(1), big.NewInt(100000), 0)
+		sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.Sig = sig
+		txs[i] = tx
+	}
+	block := &types.Block{
+		Header: &types.BlockHeader{Height: 1},
+		Data:   &types.BlockData{TxCount: uint64(count), TxList: txs},
+	}
+	gp := new(chain.GasPool).AddGas(uint64(count) * 100000)
+	context := chain.NewBlockExecuteContext(trieStore, gp, nil, block)
+
+	validator := &slowTxValidator{slow: make(map[crypto.Hash]bool)}
+	templateChain := &templateChain{
+		ChainService: &chain.ChainService{},
+		validators:   map[chain.ITransactionSelector]chain.ITransactionValidator{anyTxSelector{}: validator},
+	}
+	return context, NewTemplateBlockValidator(templateChain, 20*time.Millisecond), validator
+}
+
+func TestTemplateTxTimeout(t *testing.T) {
+	context, templateValidator, validator := newTemplateContext(t, 3)
+	txs := context.Block.Data.TxList
+	validator.slow[*txs[1].TxHash()] = true
+
+	start := time.Now()
+	if err := templateValidator.ExecuteBlock(context, time.Now().Add(10*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("slow tx held the template for %v", elapsed)
+	}
+	packed := context.Block.Data.TxList
+	if len(packed) != 2 || packed[0] != txs[0] || packed[1] != txs[2] || context.Block.Data.TxCount != 2 {
+		t.Fatalf("got %d txs packed, want the 2 fast ones", len(packed))
+	}
+	if len(context.Receipts) != 2 {
+		t.Fatalf("got %d receipts, want 2", len(context.Receipts))
+	}
+}
+
+func TestTemplateDeadline(t *testing.T) {
+	context, templateValidator, _ := newTemplateContext(t, 3)
+	if err := templateValidator.ExecuteBlock(context, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(context.Block.Data.TxList) != 0 || context.Block.Data.TxCount != 0 {
+		t.Fatalf("got %d txs packed after the deadline", len(context.Block.Data.TxList))
+	}
+
+	// the transactions executing when the deadline falls are cancelled at the deadline
+	context, templateValidator, validator := newTemplateContext(t, 3)
+	for _, tx := range context.Block.Data.TxList {
+		validator.slow[*tx.TxHash()] = true
+	}
+	templateValidator.txTimeout = time.Minute
+	start := time.Now()
+	if err := templateValidator.ExecuteBlock(context, time.Now().Add(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("template ran %v over a deadline of 50ms", elapsed)
+	}
+	if len(context.Block.Data.TxList) != 0 {
+		t.Fatalf("got %d txs packed, want none", len(context.Block.Data.TxList))
+	}
+}
+
+func TestTxDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Second)
+	if got := NewTemplateBlockValidator(nil, 0).txDeadline(deadline); !got.Equal(deadline) {
+		t.Errorf("no tx timeout: got %v, want the template deadline", got)
+	}
+	if got := NewTemplateBlockValidator(nil, time.Minute).txDeadline(deadline); !got.Equal(deadline) {
+		t.Errorf("tx timeout past the template deadline: got %v, want the template deadline", got)
+	}
+	if got := NewTemplateBlockValidator(nil, 10*time.Millisecond).txDeadline(deadline); !got.Before(deadline) {
+		t.Errorf("tx timeout before the template deadline: got %v, want it before %v", got, deadline)
+	}
+}
+
+func TestTemplateBudget(t *testing.T) {
+	blockMgr := &BlockMgr{Config: &BlockMgrConfig{}}
+	if budget := blockMgr.templateBudget(5); budget != 4*time.Second {
+		t.Errorf("default budget of 5s interval: got %v, want 4s", budget)
+	}
+	if timeout := blockMgr.txTimeout(); timeout != DefaultTxTimeout*time.Millisecond {
+		t.Errorf("default tx timeout: got %v", timeout)
+	}
+
+	blockMgr.Config = &BlockMgrConfig{TemplateBudget: 50, TxTimeout: 100}
+	if budget := blockMgr.templateBudget(5); budget != 2500*time.Millisecond {
+		t.Errorf("budget of 50%%: got %v, want 2.5s", budget)
+	}
+	if timeout := blockMgr.txTimeout(); timeout != 100*time.Millisecond {
+		t.Errorf("tx timeout of 100ms: got %v", timeout)
+	}
+
+	blockMgr.Config.TemplateBudget = 150
+	if budget := blockMgr.templateBudget(5); budget != 4*time.Second {
+		t.Errorf("budget over 100%%: got %v, want the default 4s", budget)
+	}
+}
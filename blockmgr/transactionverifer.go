@@ -11,12 +11,22 @@ func (blockMgr *BlockMgr) verifyTransaction(tx *types.Transaction) error {
 	//db := blockMgr.ChainService.GetCurrentState()
 	//from, err := tx.From()
 
+	// Kinds and payload versions unknown to this node are rejected, their Data can't be read
+	if _, err := tx.Kind(); err != nil {
+		return err
+	}
+
 	// Transactions can't be negative. This may never happen using RLP decoded
 	// transactions but may occur if you create a transaction using the RPC.
 	if tx.Amount().Sign() < 0 {
 		return ErrNegativeAmount
 	}
 
+	// The chain id is signed with transaction, reject transactions replayed from other networks
+	if tx.ChainId() != blockMgr.ChainService.ChainID() {
+		return ErrTxChainId
+	}
+
 	tip := blockMgr.ChainService.BestChain().Tip()
 	// Check the transaction doesn't exceed the current
 	// block limit gas.
@@ -31,6 +41,16 @@ func (blockMgr *BlockMgr) verifyTransaction(tx *types.Transaction) error {
 	//	return ErrBalance
 	//}
 
+	// The gas of a sponsored transaction is paid by the account recovered from the second signature
+	if tx.IsSponsored() {
+		if !blockMgr.ChainService.GenesisParams().IsSponsorFork(tip.Height + 1) {
+			return chain.ErrSponsorNotActive
+		}
+		if _, err := tx.FeePayer(); err != nil {
+			return ErrFeePayerSig
+		}
+	}
+
 	// Should supply enough intrinsic gas
 	gas, err := tx.IntrinsicGas()
 	if err != nil {
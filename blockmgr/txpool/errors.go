@@ -3,7 +3,8 @@ package txpool
 import "errors"
 
 var (
-	ErrQueueFull  = errors.New("queue full")
-	ErrTxExist    = errors.New("transaction exists")
-	ErrTxPoolFull = errors.New("transaction pool full")
+	ErrQueueFull   = errors.New("queue full")
+	ErrTxExist     = errors.New("transaction exists")
+	ErrTxPoolFull  = errors.New("transaction pool full")
+	ErrUnderpriced = errors.New("transaction underpriced")
 )
@@ -42,7 +42,7 @@ var generateMaxNonce uint64 = 10
 
 func generateTxs() []*types.Transaction {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 
 	txs := make([]*types.Transaction, 0)
 
@@ -71,7 +71,7 @@ func TestLoadAndRotate(t *testing.T) {
 		txs := generateTxs()
 		privateKey, _ := crypto.GenerateKey(rand.Reader)
 		pubkey := privateKey.PubKey()
-		addr := crypto.PubKey2Address(pubkey)
+		addr := crypto.PubkeyToAddress(pubkey)
 		all[addr] = txs
 	}
 
@@ -82,7 +82,7 @@ var insertTxNum uint64 = 10
 
 func insertTx(t *testing.T) {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 
 	for i := generateMaxNonce; i <= generateMaxNonce+insertTxNum; i++ {
 		tx := types.NewTransaction(addr, new(big.Int).SetUint64(100000000), new(big.Int).SetUint64(100000000), new(big.Int).SetUint64(100000000), uint64(i))
@@ -0,0 +1,9 @@
+package txpool
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	txCountGauge = metrics.NewRegisteredGauge("txpool/count", nil)
+	txBytesGauge = metrics.NewRegisteredGauge("txpool/bytes", nil)
+	txDropMeter  = metrics.NewRegisteredMeter("txpool/dropped", nil)
+)
@@ -0,0 +1,115 @@
+package txpool
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var priorityRulesGauge = metrics.NewRegisteredGauge("txpool/priority/rules", nil)
+
+// PriorityRules select transactions packed ahead of the others, by sender or by
+// the contract they call
+type PriorityRules struct {
+	Senders   []crypto.CommonAddress `json:"senders"`
+	Contracts []crypto.CommonAddress `json:"contracts"`
+}
+
+type priorityRuleSet struct {
+	senders   map[crypto.CommonAddress]struct{}
+	contracts map[crypto.CommonAddress]struct{}
+}
+
+// TxPriority merges priority rules from named sources, e.g. a file and the admin api,
+// every source can be replaced while the pool is running
+type TxPriority struct {
+	lock    sync.RWMutex
+	sources map[string]*priorityRuleSet
+}
+
+// NewTxPriority create priority without rules
+func NewTxPriority() *TxPriority {
+	return &TxPriority{sources: make(map[string]*priorityRuleSet)}
+}
+
+// SetRules replace the rules of source, empty rules remove the source
+func (priority *TxPriority) SetRules(source string, rules *PriorityRules) {
+	set := &priorityRuleSet{
+		senders:   make(map[crypto.CommonAddress]struct{}),
+		contracts: make(map[crypto.CommonAddress]struct{}),
+	}
+	if rules != nil {
+		for _, addr := range rules.Senders {
+			set.senders[addr] = struct{}{}
+		}
+		for _, addr := range rules.Contracts {
+			set.contracts[addr] = struct{}{}
+		}
+	}
+
+	priority.lock.Lock()
+	defer priority.lock.Unlock()
+	if len(set.senders) == 0 && len(set.contracts) == 0 {
+		delete(priority.sources, source)
+	} else {
+		priority.sources[source] = set
+	}
+	count := 0
+	for _, s := range priority.sources {
+		count += len(s.senders) + len(s.contracts)
+	}
+	priorityRulesGauge.Update(int64(count))
+}
+
+// Rules return the sorted rules of every source
+func (priority *TxPriority) Rules() map[string]*PriorityRules {
+	sorted := func(set map[crypto.CommonAddress]struct{}) []crypto.CommonAddress {
+		addrs := make([]crypto.CommonAddress, 0, len(set))
+		for addr := range set {
+			addrs = append(addrs, addr)
+		}
+		sort.Slice(addrs, func(i, j int) bool { return addrs[i].String() < addrs[j].String() })
+		return addrs
+	}
+
+	priority.lock.RLock()
+	defer priority.lock.RUnlock()
+	rules := make(map[string]*PriorityRules, len(priority.sources))
+	for source, set := range priority.sources {
+		rules[source] = &PriorityRules{Senders: sorted(set.senders), Contracts: sorted(set.contracts)}
+	}
+	return rules
+}
+
+// Empty reports whether no source has rules
+func (priority *TxPriority) Empty() bool {
+	priority.lock.RLock()
+	defer priority.lock.RUnlock()
+	return len(priority.sources) == 0
+}
+
+// Match reports whether any rule selects tx
+func (priority *TxPriority) Match(tx *types.Transaction) bool {
+	from, err := tx.From()
+	if err != nil {
+		return false
+	}
+	to := tx.To()
+
+	priority.lock.RLock()
+	defer priority.lock.RUnlock()
+	for _, set := range priority.sources {
+		if _, ok := set.senders[*from]; ok {
+			return true
+		}
+		if to != nil {
+			if _, ok := set.contracts[*to]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package txpool
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func signedTx(t *testing.T, to crypto.CommonAddress) (*types.Transaction, crypto.CommonAddress) {
+	privKey, _ := crypto.GenerateKey(rand.Reader)
+	from := crypto.PubkeyToAddress(privKey.PubKey())
+	tx := types.NewTransaction(to, new(big.Int).SetInt64(100), new(big.Int).SetInt64(100), new(big.Int).SetInt64(100), 0)
+	sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx, from
+}
+
+func TestTxPriorityRules(t *testing.T) {
+	priority := NewTxPriority()
+	if !priority.Empty() {
+		t.Fatal("new priority should be empty")
+	}
+
+	sender := crypto.HexToAddress("0x0000000000000000000000000000000000000001")
+	contract := crypto.HexToAddress("0x0000000000000000000000000000000000000002")
+	priority.SetRules("file", &PriorityRules{Senders: []crypto.CommonAddress{sender, sender}})
+	priority.SetRules("api", &PriorityRules{Contracts: []crypto.CommonAddress{contract}})
+
+	rules := priority.Rules()
+	if len(rules) != 2 || len(rules["file"].Senders) != 1 || len(rules["api"].Contracts) != 1 {
+		t.Fatalf("unexpected rules %v", rules)
+	}
+
+	priority.SetRules("file", nil)
+	priority.SetRules("api", &PriorityRules{})
+	if !priority.Empty() {
+		t.Fatal("empty rules should remove the sources")
+	}
+}
+
+func TestTxPriorityMatch(t *testing.T) {
+	contract := crypto.HexToAddress("0x0000000000000000000000000000000000000002")
+	other := crypto.HexToAddress("0x0000000000000000000000000000000000000003")
+	bySender, sender := signedTx(t, other)
+	byContract, _ := signedTx(t, contract)
+	neither, _ := signedTx(t, other)
+
+	priority := NewTxPriority()
+	if priority.Match(bySender) {
+		t.Fatal("empty priority should match nothing")
+	}
+	priority.SetRules("api", &PriorityRules{
+		Senders:   []crypto.CommonAddress{sender},
+		Contracts: []crypto.CommonAddress{contract},
+	})
+	if !priority.Match(bySender) {
+		t.Error("tx of prioritized sender not matched")
+	}
+	if !priority.Match(byContract) {
+		t.Error("tx to prioritized contract not matched")
+	}
+	if priority.Match(neither) {
+		t.Error("unrelated tx matched")
+	}
+}
@@ -1,7 +1,6 @@
 package txpool
 
 import (
-	"container/heap"
 	"errors"
 	"fmt"
 	"math/big"
@@ -15,13 +14,23 @@ import (
 	"github.com/drep-project/DREP-Chain/types"
 )
 
+//DefaultGlobalSlots is the default total number of trades held in a trading pool
+const DefaultGlobalSlots = 100000
+
 const (
-	maxAllTxsCount  = 100000           //The total number of trades held in a trading pool
-	maxTxsOfQueue   = 5                //The maximum number of transactions in an out-of-order queue corresponding to a single address
+	maxTxsOfQueue   = 5                //The default maximum number of future nonce transactions queued for a single address
 	maxTxsOfPending = 20               //The maximum number of transactions in an ordered queue corresponding to a single address
 	expireTimeTx    = 60 * 60 * 24 * 3 //The transaction is discarded if it is not packaged within three days
 )
 
+//Reasons of transactions dropped from the pool, carried by DroppedTxsEvent
+const (
+	DropReasonFutureCap   = "future nonce cap"
+	DropReasonExpired     = "expired"
+	DropReasonUnderpriced = "underpriced"
+	DropReasonReplaced    = "replaced"
+)
+
 //TransactionPool ...
 //1 The transactions in the pool are sorted and sorted in two different queues according to whether or not the nonce is continuous
 //2 The sorted ones can be packed into blocks
@@ -32,7 +41,12 @@ type TransactionPool struct {
 	queue        map[crypto.CommonAddress]*txList
 	pending      map[crypto.CommonAddress]*txList
 	allTxs       map[string]*types.Transaction
+	allTxsSize   int64         //Encoded bytes of all transactions in pool
+	maxTxsSize   int64         //Max bytes of transactions in pool, zero mean no limit
 	allPricedTxs *txPricedList //Tx list sorted by price
+	maxFutureTxs int           //Max future nonce transactions queued per address, the highest nonces are evicted first
+	globalSlots  int           //Max count of transactions in pool, the cheapest remote ones are evicted when exceed
+	priceLimit   *big.Int      //Min gas price of remote transactions accepted
 	mu           sync.Mutex
 	nonceCp      func(a interface{}, b interface{}) int
 	tranCp       func(a interface{}, b interface{}) bool
@@ -41,13 +55,23 @@ type TransactionPool struct {
 	pendingNonce     map[crypto.CommonAddress]uint64
 	eventNewBlockSub event.Subscription
 	newBlockChan     chan *types.ChainEvent
+	eventReorgSub    event.Subscription
+	reorgChan        chan *types.ReorgEvent
 	quit             chan struct{}
 
 	//Provide pending transaction subscriptions
 	txFeed event.Feed
+	//Provide dropped transaction subscriptions, senders learn their transactions will never be packed
+	dropFeed event.Feed
+	//Provide accepted, replaced and dropped transaction subscriptions, one TxPoolEvent each
+	eventFeed event.Feed
+
+	journal        *txJournal
+	privateJournal *txJournal                        //Local private transactions, journaled apart so they stay private after restart
+	locals         map[crypto.CommonAddress]struct{} //The address that the local node contains
+	private        map[string]struct{}               //Ids of the transactions sent privately, kept out of the pool sync until packed or dropped
 
-	journal *txJournal
-	locals  map[crypto.CommonAddress]struct{} //The address that the local node contains
+	priority *TxPriority //Rules of transactions packed ahead of the others
 }
 
 //NewTransactionPool Create a trading pool
@@ -81,57 +105,112 @@ func NewTransactionPool(chainStore store.StoreInterface, journalPath string) *Tr
 	pool.queue = make(map[crypto.CommonAddress]*txList)
 	pool.pending = make(map[crypto.CommonAddress]*txList)
 	pool.newBlockChan = make(chan *types.ChainEvent)
+	pool.reorgChan = make(chan *types.ReorgEvent)
 	pool.pendingNonce = make(map[crypto.CommonAddress]uint64)
 
 	pool.allTxs = make(map[string]*types.Transaction)
-	pool.allPricedTxs = newTxPricedList()
+	pool.allPricedTxs = newTxPricedList(pool.allTxs)
+	pool.maxFutureTxs = maxTxsOfQueue
+	pool.globalSlots = DefaultGlobalSlots
+	pool.priceLimit = new(big.Int)
 
 	pool.journal = newTxJournal(journalPath)
+	pool.privateJournal = newTxJournal(journalPath + ".private")
 	pool.locals = make(map[crypto.CommonAddress]struct{})
+	pool.private = make(map[string]struct{})
+	pool.priority = NewTxPriority()
 
 	return pool
 }
 
+//Priority The priority rules consulted when packing
+func (pool *TransactionPool) Priority() *TxPriority {
+	return pool.priority
+}
+
 func (pool *TransactionPool) journalTx(from crypto.CommonAddress, tx *types.Transaction) {
 	// Only journal if it's enabled and the transaction is local
 	if _, ok := pool.locals[from]; !ok || pool.journal == nil {
 		return
 	}
 
-	if err := pool.journal.insert(tx); err != nil {
+	journal := pool.journal
+	if pool.isPrivate(tx.TxHash().String()) {
+		journal = pool.privateJournal
+	}
+	if err := journal.insert(tx); err != nil {
 		log.WithField("Reason", err).Warn("Failed to journal local transaction")
 	}
 }
 
-func (pool *TransactionPool) local() map[crypto.CommonAddress][]*types.Transaction {
-	isLocalAddr := func(addr crypto.CommonAddress) bool {
-		_, ok := pool.locals[addr]
-		return ok
+//isPrivate whether the transaction of id was sent privately and is still in pool
+func (pool *TransactionPool) isPrivate(id string) bool {
+	_, ok := pool.private[id]
+	return ok
+}
+
+//IsPrivate whether the transaction was sent privately, it is not synced to peers before it is
+//packed or dropped
+func (pool *TransactionPool) IsPrivate(hash *crypto.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.isPrivate(hash.String())
+}
+
+//rotateJournals regenerate the journals of the local public and private transactions
+func (pool *TransactionPool) rotateJournals() {
+	if err := pool.journal.rotate(pool.local(false)); err != nil {
+		log.WithField("Reason", err).Warn("Failed to rotate local transaction journal")
+	}
+	if err := pool.privateJournal.rotate(pool.local(true)); err != nil {
+		log.WithField("Reason", err).Warn("Failed to rotate private transaction journal")
 	}
+}
+
+//isLocal whether the transactions of addr are local, they are never evicted by price and packed first
+func (pool *TransactionPool) isLocal(addr crypto.CommonAddress) bool {
+	_, ok := pool.locals[addr]
+	return ok
+}
 
+//local return the local transactions, the private ones or the others
+func (pool *TransactionPool) local(private bool) map[crypto.CommonAddress][]*types.Transaction {
 	all := make(map[crypto.CommonAddress][]*types.Transaction)
 	for addr, list := range pool.queue {
-		if !list.Empty() && isLocalAddr(addr) {
-			txs := list.Flatten()
-			all[addr] = txs
+		if !list.Empty() && pool.isLocal(addr) {
+			if txs := pool.filterPrivate(list.Flatten(), private); len(txs) > 0 {
+				all[addr] = txs
+			}
 		}
 	}
 
 	for addr, list := range pool.pending {
-		if !list.Empty() && isLocalAddr(addr) {
-			txs := list.Flatten()
+		if !list.Empty() && pool.isLocal(addr) {
+			txs := pool.filterPrivate(list.Flatten(), private)
+			if len(txs) == 0 {
+				continue
+			}
 			if _, ok := all[addr]; ok {
 				txs = append(txs, all[addr]...)
-			} else {
-				all[addr] = txs
 			}
+			all[addr] = txs
 		}
 	}
 
 	return all
 }
 
-func (pool *TransactionPool) addTxs(txs []types.Transaction) []error {
+func (pool *TransactionPool) filterPrivate(txs []*types.Transaction, private bool) []*types.Transaction {
+	filtered := make([]*types.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if pool.isPrivate(tx.TxHash().String()) == private {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
+func (pool *TransactionPool) addTxs(txs []types.Transaction, private bool) []error {
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
 		tx := tx
@@ -139,7 +218,7 @@ func (pool *TransactionPool) addTxs(txs []types.Transaction) []error {
 		if tx.Nonce() < pool.getTransactionCount(from) {
 			continue
 		}
-		errs[i] = pool.addTx(&tx, true)
+		errs[i] = pool.addTx(&tx, true, private)
 		if errs[i] != nil {
 			log.WithField("Reason", errs[i]).Error("recover tx from journal err")
 		}
@@ -170,20 +249,43 @@ func (pool *TransactionPool) AddTransaction(tx *types.Transaction, isLocal bool)
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.addTx(tx, isLocal)
+	return pool.addTx(tx, isLocal, false)
+}
+
+//AddPrivateTransaction put tx in txpool and keep it out of the pool sync until it is packed or
+//dropped, local private transactions are journaled apart from the others
+func (pool *TransactionPool) AddPrivateTransaction(tx *types.Transaction, isLocal bool) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.addTx(tx, isLocal, true)
 }
 
 //func AddTransaction(id string, transaction *common.transaction) {
-func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
+func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool, private bool) (err error) {
 	id := tx.TxHash()
 	if _, ok := pool.allTxs[id.String()]; ok {
 		return errors.New("konwn tx")
 	}
+	if private {
+		//the mark goes with the transaction, it is removed with it or if it is not accepted
+		pool.private[id.String()] = struct{}{}
+		defer func() {
+			if _, ok := pool.allTxs[id.String()]; !ok {
+				delete(pool.private, id.String())
+			}
+		}()
+	}
 
 	addr, err := tx.From()
 	if err != nil {
 		return err
 	}
+	//Transactions of a local address are local whoever relays them
+	isLocal = isLocal || pool.isLocal(*addr)
+	if !isLocal && tx.GasPrice().Cmp(pool.priceLimit) < 0 {
+		return ErrUnderpriced
+	}
 
 	// pending Queue transaction substitution
 	if list, ok := pool.pending[*addr]; ok {
@@ -198,12 +300,10 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 
 			log.WithField("nonce", tx.Nonce()).WithField("old price", oldTx.GasPrice()).WithField("new pirce", tx.GasPrice()).Warn("replace")
 
-			delete(pool.allTxs, oldTx.TxHash().String())
-			pool.allPricedTxs.Remove(oldTx)
-
-			pool.allTxs[id.String()] = tx
-			pool.allPricedTxs.Put(tx)
+			pool.removeTx(oldTx.TxHash().String())
+			pool.putTx(id.String(), tx)
 			pool.journalTx(*addr, tx)
+			pool.notifyReplaced(oldTx, tx)
 			return nil
 		}
 	}
@@ -219,12 +319,10 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 
 			log.WithField("nonce", tx.Nonce()).WithField("old price", oldTx.GasPrice()).WithField("new pirce", tx.GasPrice()).Info("replace")
 
-			delete(pool.allTxs, oldTx.TxHash().String())
-			pool.allPricedTxs.Remove(oldTx)
-
-			pool.allTxs[id.String()] = tx
-			pool.allPricedTxs.Put(tx)
+			pool.removeTx(oldTx.TxHash().String())
+			pool.putTx(id.String(), tx)
 			pool.journalTx(*addr, tx)
+			pool.notifyReplaced(oldTx, tx)
 			return nil
 		}
 	}
@@ -235,48 +333,25 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 		return fmt.Errorf("SendTransaction local nonce:%d , comming tx nonce:%d too small", nonce, tx.Nonce())
 	}
 
-	//A new transaction is coming, let's see if the pool is full; When full, delete some of the cheaper tx's
-	miniPrice := new(big.Int)
-	if len(pool.allTxs) >= maxAllTxsCount {
-		//Cheaper deals will be discarded
-		txs := pool.allPricedTxs.Discard(1, pool.locals)
-		for _, t := range txs {
-			if t.GasPrice().Cmp(miniPrice) < 0 || miniPrice.Cmp(new(big.Int)) == 0 {
-				miniPrice = t.GasPrice()
-			}
-			delAddr, _ := tx.From()
-			delete(pool.allTxs, t.TxHash().String())
-
-			remove := func(list *txList, pending bool) bool {
-				//going to delete all the tx's in the queue /pending
-				removeSuccess, deleteTxs := list.Remove(&t)
-				if removeSuccess {
-					for _, delTx := range deleteTxs {
-						if pending {
-							pool.pendingNonce[*delAddr]--
-						}
-
-						delete(pool.allTxs, delTx.TxHash().String())
-						pool.allPricedTxs.Remove(delTx)
-					}
-				}
-				return removeSuccess
-			}
-
-			for i, maplist := range []map[crypto.CommonAddress]*txList{pool.pending, pool.queue} {
-				if list, ok := maplist[*delAddr]; ok {
-					b := remove(list, i == 0)
-					if b {
-						break
-					}
-				}
+	//A new transaction is coming, let's see if the pool is full; When full, the cheapest
+	//remote transactions are evicted for a better paying one
+	if pool.full() {
+		if !isLocal && pool.allPricedTxs.Underpriced(tx, pool.locals) {
+			return ErrUnderpriced
+		}
+		var dropped []*types.Transaction
+		for pool.full() {
+			txs := pool.allPricedTxs.Discard(1, pool.locals)
+			if len(txs) == 0 {
+				break
 			}
+			dropped = append(dropped, pool.evictTx(txs[0])...)
+		}
+		pool.notifyDropped(dropped, DropReasonUnderpriced)
+		if pool.full() && !isLocal {
+			//Only local transactions left, they are never evicted
+			return ErrTxPoolFull
 		}
-	}
-
-	//If the price of the new transaction is low and not local. So return an error (todo need to optimize)
-	if miniPrice.Cmp(new(big.Int)) != 0 && tx.GasPrice().Cmp(miniPrice) < 0 && !isLocal {
-		return fmt.Errorf("new tx gasprice is too low")
 	}
 
 	if isLocal {
@@ -287,16 +362,6 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 
 	//add to queue
 	if list, ok := pool.queue[*addr]; ok {
-		//Whether the queue space corresponding to the address is full,drop old tx
-		if list.Len() > maxTxsOfQueue {
-			//Blocking here may be nonce discontinuity, so discard the old transaction, add new transaction, and realize nonce continuity
-			txs := list.Cap(list.Len())
-			for _, delTx := range txs {
-				delete(pool.allTxs, delTx.TxHash().String())
-				pool.allPricedTxs.Remove(delTx)
-				log.WithField("oldtx", delTx.TxHash()).WithField("newTx", tx.TxHash()).Info("old tx been replaced")
-			}
-		}
 		list.Add(tx)
 	} else {
 		pool.queue[*addr] = newTxList(false)
@@ -304,13 +369,82 @@ func (pool *TransactionPool) addTx(tx *types.Transaction, isLocal bool) error {
 		pool.txFeed.Send(types.NewTxsEvent{Txs: []*types.Transaction{tx}})
 	}
 
-	pool.journalTx(*addr, tx)
-	pool.allTxs[id.String()] = tx
-	pool.allPricedTxs.Put(tx)
+	pool.putTx(id.String(), tx)
 	pool.syncToPending(addr)
+
+	//The transactions left in queue wait for a nonce gap, the highest ones are evicted when over the limit
+	evicted := pool.capFutureTxs(addr)
+	for i, delTx := range evicted {
+		if delTx == tx {
+			pool.notifyDropped(append(evicted[:i:i], evicted[i+1:]...), DropReasonFutureCap)
+			return ErrQueueFull
+		}
+	}
+	pool.notifyDropped(evicted, DropReasonFutureCap)
+	pool.journalTx(*addr, tx)
+	pool.eventFeed.Send(types.TxPoolEvent{Type: types.TxPoolEventAdded, Tx: tx})
 	return nil
 }
 
+//full whether the pool holds its max count or bytes of transactions
+func (pool *TransactionPool) full() bool {
+	return len(pool.allTxs) >= pool.globalSlots || (pool.maxTxsSize > 0 && pool.allTxsSize >= pool.maxTxsSize)
+}
+
+//evictTx remove tx from the pool, the pending transactions of its sender after it are
+//removed too, they can not be packed without it
+func (pool *TransactionPool) evictTx(tx *types.Transaction) []*types.Transaction {
+	from, _ := tx.From()
+	dropped := []*types.Transaction{tx}
+	pool.removeTx(tx.TxHash().String())
+	if list, ok := pool.pending[*from]; ok {
+		if removed, invalids := list.Remove(tx); removed {
+			pool.pendingNonce[*from] = tx.Nonce()
+			for _, delTx := range invalids {
+				pool.removeTx(delTx.TxHash().String())
+				dropped = append(dropped, delTx)
+			}
+			return dropped
+		}
+	}
+	if list, ok := pool.queue[*from]; ok {
+		list.Remove(tx)
+	}
+	return dropped
+}
+
+//capFutureTxs evict the highest nonce transactions of address queued over the future limit
+func (pool *TransactionPool) capFutureTxs(address *crypto.CommonAddress) []*types.Transaction {
+	list, ok := pool.queue[*address]
+	if !ok || pool.maxFutureTxs <= 0 {
+		return nil
+	}
+	evicted := list.Cap(pool.maxFutureTxs)
+	for _, delTx := range evicted {
+		pool.removeTx(delTx.TxHash().String())
+		log.WithField("addr", address.String()).WithField("nonce", delTx.Nonce()).WithField("limit", pool.maxFutureTxs).Info("evict future tx")
+	}
+	return evicted
+}
+
+//notifyDropped announce transactions removed from the pool before being packed
+func (pool *TransactionPool) notifyDropped(txs []*types.Transaction, reason string) {
+	if len(txs) == 0 {
+		return
+	}
+	txDropMeter.Mark(int64(len(txs)))
+	pool.dropFeed.Send(types.DroppedTxsEvent{Txs: txs, Reason: reason})
+	for _, tx := range txs {
+		pool.eventFeed.Send(types.TxPoolEvent{Type: types.TxPoolEventDropped, Tx: tx, Reason: reason})
+	}
+}
+
+//notifyReplaced announce oldTx replaced by tx paying a higher price for the same nonce
+func (pool *TransactionPool) notifyReplaced(oldTx, tx *types.Transaction) {
+	pool.eventFeed.Send(types.TxPoolEvent{Type: types.TxPoolEventReplaced, Tx: oldTx, Reason: DropReasonReplaced, ReplacedBy: tx.TxHash()})
+	pool.eventFeed.Send(types.TxPoolEvent{Type: types.TxPoolEventAdded, Tx: tx})
+}
+
 func (pool *TransactionPool) syncToPending(address *crypto.CommonAddress) {
 	if _, ok := pool.pending[*address]; !ok {
 		pool.pending[*address] = newTxList(true)
@@ -370,73 +504,102 @@ func (pool *TransactionPool) GetQueue() []*types.Transaction {
 	return retrunTxs
 }
 
-//GetPending The packaging process takes the transaction and packages it
+//GetPending The packaging process takes the transactions up to GasLimit, the local ones first
+//then the best paying across accounts while the transactions of an account stay in nonce order
 func (pool *TransactionPool) GetPending(GasLimit *big.Int) []*types.Transaction {
 	pool.mu.Lock()
-	gasCount := new(big.Int)
-
-	//change Data structure
-	hbn := make(map[crypto.CommonAddress]*nonceTxsHeap)
-
+	pending := make(map[crypto.CommonAddress][]*types.Transaction, len(pool.pending))
+	locals := make(map[crypto.CommonAddress][]*types.Transaction)
 	for addr, list := range pool.pending {
-		if !list.Empty() {
-			txs := list.Flatten()
-			newList := &nonceTxsHeap{}
-			for _, tx := range txs {
-				newList.Push(tx)
-			}
-			hbn[addr] = newList
+		if list.Empty() {
+			continue
+		}
+		if pool.isLocal(addr) {
+			locals[addr] = list.Flatten()
+		} else {
+			pending[addr] = list.Flatten()
 		}
 	}
-
 	pool.mu.Unlock()
 
 	var retrunTxs []*types.Transaction
-	for {
-		for addr, list := range hbn {
-			tx := heap.Pop(list).(*types.Transaction)
-			if GasLimit.Cmp(new(big.Int).Add(tx.GasLimit(), gasCount)) >= 0 {
-				retrunTxs = append(retrunTxs, tx)
-			} else {
-				goto END
-			}
-
-			if list.Len() == 0 {
-				delete(hbn, addr)
+	gasCount := new(big.Int)
+	pick := func(tx *types.Transaction) bool {
+		gas := new(big.Int).Add(tx.GasLimit(), gasCount)
+		if GasLimit.Cmp(gas) < 0 {
+			return false
+		}
+		gasCount = gas
+		retrunTxs = append(retrunTxs, tx)
+		return true
+	}
+
+	//Addresses with prioritized transactions go first, up to their last prioritized nonce
+	if !pool.priority.Empty() {
+		for _, group := range []map[crypto.CommonAddress][]*types.Transaction{locals, pending} {
+			for addr, txs := range group {
+				last := -1
+				for i, tx := range txs {
+					if pool.priority.Match(tx) {
+						last = i
+					}
+				}
+				for i := 0; i <= last; i++ {
+					if !pick(txs[i]) {
+						return retrunTxs
+					}
+				}
+				group[addr] = txs[last+1:]
 			}
 		}
+	}
 
-		if len(hbn) <= 0 {
-			goto END
+	//A transaction over the remaining gas is skipped with the rest of its account
+	for _, group := range []map[crypto.CommonAddress][]*types.Transaction{locals, pending} {
+		byPrice := newTxsByPrice(group)
+		for tx := byPrice.Peek(); tx != nil; tx = byPrice.Peek() {
+			if pick(tx) {
+				byPrice.Shift()
+			} else {
+				byPrice.Pop()
+			}
 		}
 	}
-
-END:
 	return retrunTxs
 }
 
 //Start start transaction pool
-func (pool *TransactionPool) Start(feed *event.Feed, tipRoot []byte) {
+func (pool *TransactionPool) Start(feed *event.Feed, reorgFeed *event.Feed, tipRoot []byte) {
 	b := pool.chainStore.RecoverTrie(tipRoot)
 	if !b {
 		log.WithField("recoverRet", b).Error("tx pool")
 	}
 
-	pool.journal.load(pool.addTxs)
-	pool.journal.rotate(pool.local())
+	pool.loadJournals()
 
 	go pool.checkUpdate()
 	pool.eventNewBlockSub = feed.Subscribe(pool.newBlockChan)
+	pool.eventReorgSub = reorgFeed.Subscribe(pool.reorgChan)
+}
+
+//loadJournals add the local transactions journaled before restart, the private ones stay private
+func (pool *TransactionPool) loadJournals() {
+	pool.journal.load(func(txs []types.Transaction) []error { return pool.addTxs(txs, false) })
+	pool.privateJournal.load(func(txs []types.Transaction) []error { return pool.addTxs(txs, true) })
+	pool.rotateJournals()
 }
 
 //Stop transaction pool work
 func (pool *TransactionPool) Stop() {
 	close(pool.quit)
 	pool.eventNewBlockSub.Unsubscribe()
+	pool.eventReorgSub.Unsubscribe()
 	pool.journal.close()
+	pool.privateJournal.close()
 }
 
 func (pool *TransactionPool) eliminateExpiredTxs() {
+	var expired []*types.Transaction
 	for _, list := range pool.queue {
 		if !list.Empty() {
 			txs := list.Flatten()
@@ -444,9 +607,9 @@ func (pool *TransactionPool) eliminateExpiredTxs() {
 				if tx.Time()+expireTimeTx <= time.Now().Unix() {
 					from, _ := tx.From()
 					log.WithField("tx time", tx.Time()).WithField("tx nonce", tx.Nonce()).WithField("from", from.String()).Info("tx expire")
-					delete(pool.allTxs, tx.TxHash().String())
-					pool.allPricedTxs.Remove(tx)
+					pool.removeTx(tx.TxHash().String())
 					list.Remove(tx)
+					expired = append(expired, tx)
 				}
 			}
 		}
@@ -459,13 +622,14 @@ func (pool *TransactionPool) eliminateExpiredTxs() {
 				if tx.Time()+expireTimeTx <= time.Now().Unix() {
 					from, _ := tx.From()
 					log.WithField("tx time", tx.Time()).WithField("tx nonce", tx.Nonce()).WithField("from", from.String()).Info("tx expire")
-					delete(pool.allTxs, tx.TxHash().String())
-					pool.allPricedTxs.Remove(tx)
+					pool.removeTx(tx.TxHash().String())
 					list.Remove(tx)
+					expired = append(expired, tx)
 				}
 			}
 		}
 	}
+	pool.notifyDropped(expired, DropReasonExpired)
 }
 
 func (pool *TransactionPool) checkUpdate() {
@@ -478,11 +642,12 @@ func (pool *TransactionPool) checkUpdate() {
 			pool.eliminateExpiredTxs()
 
 			//to journal
-			all := pool.local()
-			pool.journal.rotate(all)
+			pool.rotateJournals()
 			pool.mu.Unlock()
 		case block := <-pool.newBlockChan:
 			pool.adjust(block.Block)
+		case reorg := <-pool.reorgChan:
+			pool.reinject(reorg)
 		case <-pool.quit:
 			return
 		}
@@ -525,7 +690,7 @@ func (pool *TransactionPool) adjust(block *types.Block) {
 				txs := list.Forward(nonce)
 				for _, tx := range txs {
 					id := tx.TxHash()
-					delete(pool.allTxs, id.String())
+					pool.removeTx(id.String())
 				}
 			}
 
@@ -535,6 +700,44 @@ func (pool *TransactionPool) adjust(block *types.Block) {
 	}
 }
 
+//reinject put the transactions of detached blocks which not included by the new chain back to pool
+func (pool *TransactionPool) reinject(reorg *types.ReorgEvent) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	included := make(map[crypto.Hash]struct{})
+	for _, block := range reorg.NewChain {
+		for _, tx := range block.Data.TxList {
+			included[*tx.TxHash()] = struct{}{}
+		}
+	}
+
+	count := 0
+	for _, block := range reorg.OldChain {
+		for _, tx := range block.Data.TxList {
+			if _, ok := included[*tx.TxHash()]; ok {
+				continue
+			}
+			from, err := tx.From()
+			if err != nil {
+				continue
+			}
+			nonce := pool.chainStore.GetNonce(from)
+			if tx.Nonce() < nonce {
+				continue
+			}
+			//nonce of chain may go back after reorganize
+			if pool.pendingNonce[*from] > nonce {
+				pool.pendingNonce[*from] = nonce
+			}
+			if err := pool.addTx(tx, false, false); err == nil {
+				count++
+			}
+		}
+	}
+	log.WithField("forkHeight", reorg.ForkHeight).WithField("count", count).Info("reinject txs of detached blocks")
+}
+
 //GetTransactionCount Gets the total number of transactions, that is, the nonce corresponding to the address
 func (pool *TransactionPool) GetTransactionCount(address *crypto.CommonAddress) uint64 {
 	pool.mu.Lock()
@@ -605,7 +808,86 @@ func (pool *TransactionPool) GetTxInPool(hash string) (*types.Transaction, error
 	return nil, fmt.Errorf("hash:%s not in txpool", hash)
 }
 
+// SetMemoryLimit set the max bytes of transactions held by pool, cheaper transactions are discarded when exceed
+func (pool *TransactionPool) SetMemoryLimit(size int64) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.maxTxsSize = size
+}
+
+// MemoryUsage return the encoded bytes of all transactions in pool
+func (pool *TransactionPool) MemoryUsage() int64 {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.allTxsSize
+}
+
+// SetGlobalSlots set the max count of transactions held by pool, cheaper remote transactions are evicted when exceed
+func (pool *TransactionPool) SetGlobalSlots(slots int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.globalSlots = slots
+}
+
+// SetPriceLimit set the min gas price of the remote transactions accepted by pool
+func (pool *TransactionPool) SetPriceLimit(price *big.Int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.priceLimit = new(big.Int).Set(price)
+}
+
+// AddLocals treat the transactions of addrs as local, they are never evicted by price and
+// packed first, like the transactions submitted by the node itself
+func (pool *TransactionPool) AddLocals(addrs []crypto.CommonAddress) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for _, addr := range addrs {
+		pool.locals[addr] = struct{}{}
+	}
+}
+
+// SetFutureLimit set the max future nonce transactions queued per address, zero mean no limit
+func (pool *TransactionPool) SetFutureLimit(limit int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.maxFutureTxs = limit
+}
+
+// putTx add tx into the set of all transactions and account its size
+func (pool *TransactionPool) putTx(id string, tx *types.Transaction) {
+	if old, ok := pool.allTxs[id]; ok {
+		pool.allTxsSize -= int64(len(old.AsPersistentMessage()))
+	}
+	pool.allTxs[id] = tx
+	pool.allTxsSize += int64(len(tx.AsPersistentMessage()))
+	pool.allPricedTxs.Put(tx)
+	txCountGauge.Update(int64(len(pool.allTxs)))
+	txBytesGauge.Update(pool.allTxsSize)
+}
+
+// removeTx remove tx from the set of all transactions and account its size
+func (pool *TransactionPool) removeTx(id string) {
+	if tx, ok := pool.allTxs[id]; ok {
+		pool.allTxsSize -= int64(len(tx.AsPersistentMessage()))
+		delete(pool.allTxs, id)
+		delete(pool.private, id)
+		pool.allPricedTxs.Removed()
+		txCountGauge.Update(int64(len(pool.allTxs)))
+		txBytesGauge.Update(pool.allTxsSize)
+	}
+}
+
 // NewTxFeed new transaction feed in the trading pool
 func (pool *TransactionPool) NewTxFeed() *event.Feed {
 	return &pool.txFeed
 }
+
+// DroppedTxFeed feed of transactions dropped from the trading pool before being packed
+func (pool *TransactionPool) DroppedTxFeed() *event.Feed {
+	return &pool.dropFeed
+}
+
+// EventFeed feed of transactions accepted into, replaced in and dropped from the trading pool
+func (pool *TransactionPool) EventFeed() *event.Feed {
+	return &pool.eventFeed
+}
@@ -1,53 +1,54 @@
 package txpool
 
 import (
+	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
-	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
 	"github.com/drep-project/DREP-Chain/types"
 
 	rand2 "math/rand"
 
 	"crypto/rand"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
-	"time"
 )
 
-var txNum1 uint64 = 10000
+var txNum1 uint64 = maxTxsOfPending //An address holds no more pending txs
 var txNum2 int = 1000
 var txPool *TransactionPool
 var feed event.Feed
+var reorgFeed event.Feed
 
 func TestNewTransactions(t *testing.T) {
-	path := filepath.Join(os.TempDir(), fmt.Sprintf("./txpool/%d/data", rand2.Int63n(10000000)))
-	diskDb, err := database.NewDatabase(path)
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
 	if err != nil {
-		t.Error("db init err")
+		t.Fatal(err)
 	}
 
-	path = filepath.Join(os.TempDir(), fmt.Sprintf("./jounal/txs"))
-	txPool = NewTransactionPool(diskDb, path)
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("./jounal/%d/txs", rand2.Int63n(10000000)))
+	txPool = NewTransactionPool(trieStore, path)
 	if txPool == nil {
 		t.Error("init chainStore service err")
 	}
 
-	txPool.Start(&feed)
+	txPool.Start(&feed, &reorgFeed, trie.EmptyRoot[:])
 }
 
 func addTx(t *testing.T, num uint64) error {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
 
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 	fmt.Println(string(addr.Hex()))
-	txPool.chainStore.BeginTransaction()
 
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.chainStore.GetNonce(&addr)
 	for i := 0; uint64(i) < num; i++ {
@@ -63,10 +64,6 @@ func addTx(t *testing.T, num uint64) error {
 		if err != nil {
 			return err
 		}
-		if i%10000 == 0 {
-			time.Sleep(time.Second * 1)
-			fmt.Println("nonce id:", i)
-		}
 	}
 
 	return nil
@@ -82,7 +79,7 @@ func TestAddTX(t *testing.T) {
 
 func TestAddIntevalTX(t *testing.T) {
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 	for i := 0; i < txNum2; i++ {
 		if i != 0 && i%100 == 0 {
 			continue
@@ -101,53 +98,20 @@ func TestAddIntevalTX(t *testing.T) {
 //The pool is full of unprocessed transactions
 func TestGetPendingTxs(t *testing.T) {
 	TestNewTransactions(t)
-	ch := make(chan uint64)
-	go func() {
-		err := addTx(t, txNum1)
-		if err != nil {
-			return
-		}
+	if err := addTx(t, txNum1); err != nil {
+		t.Fatal(err)
+	}
 
-		time.Sleep(time.Second * 1)
-		ch <- txNum1
-	}()
-
-	func() {
-		var nonce uint64
-		for {
-			select {
-			case num := <-ch:
-				if nonce != num {
-					t.Fatalf("recv nonce:%d sendTxNum:%d", nonce, num)
-				}
-				return
-			default:
-				gasLimit := new(big.Int).SetInt64(10000000)
-				mapTxs := txPool.GetPending(gasLimit)
-				fmt.Println("pending tx len:", len(mapTxs))
-				if len(mapTxs) == 0 {
-					time.Sleep(time.Second * 1)
-					continue
-				}
-
-				var addrs []*crypto.CommonAddress
-				mapAddr := make(map[crypto.CommonAddress]struct{})
-				for _, tx := range mapTxs {
-					from := &crypto.CommonAddress{}
-					if _, ok := mapAddr[*from]; !ok {
-						mapAddr[*from] = struct{}{}
-						addrs = append(addrs, from)
-					}
-					nonce = tx.Nonce()
-				}
-				fmt.Println("recv nonce:", nonce)
-				txPool.chainStore.BeginTransaction()
-
-				feed.Send(addrs)
-				time.Sleep(time.Second * 1)
-			}
+	gasLimit := new(big.Int).SetInt64(10000000)
+	pending := txPool.GetPending(gasLimit)
+	if uint64(len(pending)) != txNum1 {
+		t.Fatalf("pending tx len:%d sendTxNum:%d", len(pending), txNum1)
+	}
+	for i, tx := range pending {
+		if tx.Nonce() != uint64(i) {
+			t.Fatalf("recv nonce:%d want:%d", tx.Nonce(), i)
 		}
-	}()
+	}
 }
 
 //The tx in the test queue is deleted
@@ -155,11 +119,9 @@ func TestReplace(t *testing.T) {
 	TestNewTransactions(t)
 
 	privKey, _ := crypto.GenerateKey(rand.Reader)
-	addr := crypto.PubKey2Address(privKey.PubKey())
-	txPool.chainStore.BeginTransaction()
-
+	addr := crypto.PubkeyToAddress(privKey.PubKey())
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.chainStore.GetNonce(&addr)
 	for i := 0; uint64(i) < maxTxsOfPending; i++ {
@@ -218,7 +180,7 @@ func TestDelTx(t *testing.T) {
 	//txPool.chainStore.BeginTransaction()
 
 	var amount uint64 = 0xefffffffffffffff
-	txPool.chainStore.PutBalance(&addr, new(big.Int).SetUint64(amount))
+	txPool.chainStore.PutBalance(&addr, 0, new(big.Int).SetUint64(amount))
 
 	nonce := txPool.getTransactionCount(&addr)
 	for i := 0; uint64(i) < maxTxsOfQueue+maxTxsOfPending; i++ {
@@ -232,14 +194,299 @@ func TestDelTx(t *testing.T) {
 	}
 
 	nonce += maxTxsOfQueue + maxTxsOfPending
-	//delete in pending
-	for i := 0; uint64(i) < 20; i++ {
+	//pending of the address is full, the higher nonces wait in queue until its limit
+	var err error
+	for i := 0; uint64(i) < 20 && err == nil; i++ {
 		tx := types.NewTransaction(addr, new(big.Int).SetInt64(100), new(big.Int).SetInt64(int64(100*5)), new(big.Int).SetInt64(100), nonce+uint64(i))
-		sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+		sig, signErr := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+		if signErr != nil {
+			t.Fatal(signErr)
+		}
 		tx.Sig = sig
 		err = txPool.AddTransaction(tx, false)
+	}
+	if err != ErrQueueFull {
+		t.Fatalf("got %v adding txs over the address capacity, want %v", err, ErrQueueFull)
+	}
+}
+
+func signedNonceTx(t *testing.T, privKey *secp256k1.PrivateKey, nonce uint64) *types.Transaction {
+	to := crypto.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx := types.NewTransaction(to, new(big.Int).SetInt64(100), new(big.Int).SetInt64(100), new(big.Int).SetInt64(100), nonce)
+	sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx
+}
+
+func TestFutureTxsCap(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTransactionPool(trieStore, "")
+	pool.SetFutureLimit(3)
+	dropped := make(chan types.DroppedTxsEvent, 10)
+	sub := pool.DroppedTxFeed().Subscribe(dropped)
+	defer sub.Unsubscribe()
+
+	privKey, _ := crypto.GenerateKey(rand.Reader)
+	// nonce 0 is missing, all of them wait in queue
+	for nonce := uint64(2); nonce <= 4; nonce++ {
+		if err := pool.AddTransaction(signedNonceTx(t, privKey, nonce), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a higher nonce over the cap is refused, not announced
+	if err := pool.AddTransaction(signedNonceTx(t, privKey, 5), false); err != ErrQueueFull {
+		t.Fatalf("got %v adding nonce over the cap, want %v", err, ErrQueueFull)
+	}
+	// a lower nonce evicts the highest one
+	if err := pool.AddTransaction(signedNonceTx(t, privKey, 1), false); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-dropped:
+		if len(ev.Txs) != 1 || ev.Txs[0].Nonce() != 4 || ev.Reason != DropReasonFutureCap {
+			t.Fatalf("unexpected dropped event %d txs, reason %s", len(ev.Txs), ev.Reason)
+		}
+	default:
+		t.Fatal("no dropped event for the evicted transaction")
+	}
+	if len(dropped) != 0 {
+		t.Fatal("refused transaction announced as dropped")
+	}
+
+	// the gap filled, the queued ones move to pending and are not capped
+	if err := pool.AddTransaction(signedNonceTx(t, privKey, 0), false); err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(privKey.PubKey())
+	if count := pool.GetTransactionCount(&from); count != 4 {
+		t.Fatalf("got transaction count %d, want 4", count)
+	}
+}
+
+func signedPriceTx(t *testing.T, privKey *secp256k1.PrivateKey, nonce uint64, price int64) *types.Transaction {
+	return signedAmountTx(t, privKey, nonce, 100, price)
+}
+
+// signedAmountTx sign a tx of amount, the hash of a tx does not cover its sender so txs of
+// different senders differ by their amount when their nonce and price are the same
+func signedAmountTx(t *testing.T, privKey *secp256k1.PrivateKey, nonce uint64, amount, price int64) *types.Transaction {
+	to := crypto.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx := types.NewTransaction(to, new(big.Int).SetInt64(amount), new(big.Int).SetInt64(price), new(big.Int).SetInt64(100), nonce)
+	sig, err := secp256k1.SignCompact(privKey, tx.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sig = sig
+	return tx
+}
+
+func TestEvictUnderpriced(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTransactionPool(trieStore, "")
+	pool.SetGlobalSlots(2)
+	pool.SetPriceLimit(big.NewInt(50))
+
+	keyA, _ := crypto.GenerateKey(rand.Reader)
+	keyB, _ := crypto.GenerateKey(rand.Reader)
+	keyC, _ := crypto.GenerateKey(rand.Reader)
+	if err := pool.AddTransaction(signedPriceTx(t, keyC, 0, 40), false); err != ErrUnderpriced {
+		t.Fatalf("got %v adding tx below the price limit, want %v", err, ErrUnderpriced)
+	}
+	cheap := signedPriceTx(t, keyA, 0, 100)
+	if err := pool.AddTransaction(cheap, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddTransaction(signedPriceTx(t, keyB, 0, 200), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pool is full, a tx paying no more than the cheapest one is refused
+	if err := pool.AddTransaction(signedAmountTx(t, keyC, 0, 200, 100), false); err != ErrUnderpriced {
+		t.Fatalf("got %v adding underpriced tx to full pool, want %v", err, ErrUnderpriced)
+	}
+	// a better paying one evicts the cheapest
+	if err := pool.AddTransaction(signedPriceTx(t, keyC, 0, 300), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.GetTxInPool(cheap.TxHash().String()); err == nil {
+		t.Fatal("cheapest tx not evicted")
+	}
+	if pending := pool.GetPending(big.NewInt(1000)); len(pending) != 2 {
+		t.Fatalf("got %d pending txs, want 2", len(pending))
+	}
+}
+
+func TestGetPendingByPrice(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTransactionPool(trieStore, "")
+	keyA, _ := crypto.GenerateKey(rand.Reader)
+	keyB, _ := crypto.GenerateKey(rand.Reader)
+	a0, a1 := signedPriceTx(t, keyA, 0, 100), signedPriceTx(t, keyA, 1, 500)
+	b0 := signedPriceTx(t, keyB, 0, 200)
+	for _, tx := range []*types.Transaction{a0, a1, b0} {
+		if err := pool.AddTransaction(tx, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the best paying head first, an account stays in nonce order
+	want := []*types.Transaction{b0, a0, a1}
+	pending := pool.GetPending(big.NewInt(1000))
+	if len(pending) != len(want) {
+		t.Fatalf("got %d pending txs, want %d", len(pending), len(want))
+	}
+	for i, tx := range pending {
+		if tx != want[i] {
+			t.Fatalf("pending tx %d has price %v nonce %d", i, tx.GasPrice(), tx.Nonce())
+		}
+	}
+
+	// each tx uses a gas limit of 100
+	if pending := pool.GetPending(big.NewInt(250)); len(pending) != 2 {
+		t.Fatalf("got %d pending txs within the gas limit, want 2", len(pending))
+	}
+}
+
+func TestLocalsFirstAndKept(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTransactionPool(trieStore, "")
+	pool.SetGlobalSlots(2)
+	pool.SetPriceLimit(big.NewInt(50))
+
+	keyA, _ := crypto.GenerateKey(rand.Reader)
+	keyB, _ := crypto.GenerateKey(rand.Reader)
+	keyC, _ := crypto.GenerateKey(rand.Reader)
+	pool.AddLocals([]crypto.CommonAddress{crypto.PubkeyToAddress(keyA.PubKey())})
+
+	// a configured local address is not held to the price limit, whoever relays its tx
+	local := signedPriceTx(t, keyA, 0, 10)
+	if err := pool.AddTransaction(local, false); err != nil {
+		t.Fatal(err)
+	}
+	remote := signedPriceTx(t, keyB, 0, 200)
+	if err := pool.AddTransaction(remote, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pool is full, a better paying tx evicts the remote one and keeps the local one
+	better := signedPriceTx(t, keyC, 0, 300)
+	if err := pool.AddTransaction(better, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.GetTxInPool(remote.TxHash().String()); err == nil {
+		t.Fatal("remote tx not evicted")
+	}
+	if _, err := pool.GetTxInPool(local.TxHash().String()); err != nil {
+		t.Fatal("local tx evicted")
+	}
+
+	// the local tx is packed first despite its price
+	pending := pool.GetPending(big.NewInt(1000))
+	if len(pending) != 2 || pending[0] != local || pending[1] != better {
+		t.Fatalf("got %d pending txs, want the local one first", len(pending))
+	}
+}
+
+func TestPoolEvents(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewTransactionPool(trieStore, "")
+	events := make(chan types.TxPoolEvent, 10)
+	sub := pool.EventFeed().Subscribe(events)
+	defer sub.Unsubscribe()
+
+	privKey, _ := crypto.GenerateKey(rand.Reader)
+	old := signedPriceTx(t, privKey, 0, 100)
+	if err := pool.AddTransaction(old, false); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Type != types.TxPoolEventAdded || *ev.Tx.TxHash() != *old.TxHash() {
+		t.Fatalf("got %s event, want added", ev.Type)
+	}
+
+	// a refused transaction is not announced
+	if err := pool.AddTransaction(signedPriceTx(t, privKey, 0, 50), false); err == nil {
+		t.Fatal("cheaper replacement accepted")
+	}
+	if len(events) != 0 {
+		t.Fatal("refused transaction announced")
+	}
+
+	tx := signedPriceTx(t, privKey, 0, 200)
+	if err := pool.AddTransaction(tx, false); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	if ev.Type != types.TxPoolEventReplaced || *ev.Tx.TxHash() != *old.TxHash() || ev.Reason != DropReasonReplaced || *ev.ReplacedBy != *tx.TxHash() {
+		t.Fatalf("unexpected replaced event %s, reason %s", ev.Type, ev.Reason)
+	}
+	if ev = <-events; ev.Type != types.TxPoolEventAdded || *ev.Tx.TxHash() != *tx.TxHash() {
+		t.Fatalf("got %s event, want added replacement", ev.Type)
+	}
+}
+
+func TestPrivateTxsAfterRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txpool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	newPool := func() *TransactionPool {
+		trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
 		if err != nil {
 			t.Fatal(err)
 		}
+		return NewTransactionPool(trieStore, filepath.Join(dir, "txs"))
+	}
+
+	key, _ := crypto.GenerateKey(rand.Reader)
+	public := signedPriceTx(t, key, 0, 100)
+	private := signedPriceTx(t, key, 1, 100)
+	pool := newPool()
+	if err := pool.AddTransaction(public, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.AddPrivateTransaction(private, true); err != nil {
+		t.Fatal(err)
+	}
+	pool.rotateJournals()
+	pool.journal.close()
+	pool.privateJournal.close()
+
+	// the private mark is journaled with the tx and kept until it leaves the pool
+	restarted := newPool()
+	restarted.loadJournals()
+	defer restarted.journal.close()
+	defer restarted.privateJournal.close()
+	if _, err := restarted.GetTxInPool(private.TxHash().String()); err != nil {
+		t.Fatal("private tx lost on restart")
+	}
+	if !restarted.IsPrivate(private.TxHash()) {
+		t.Fatal("private tx public after restart")
+	}
+	if restarted.IsPrivate(public.TxHash()) {
+		t.Fatal("public tx private after restart")
+	}
+	restarted.evictTx(private)
+	if restarted.IsPrivate(private.TxHash()) {
+		t.Fatal("private mark kept after the tx was dropped")
 	}
 }
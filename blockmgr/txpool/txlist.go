@@ -379,15 +379,19 @@ func (h *priceHeap) Pop() interface{} {
 }
 
 //txPricedList is a price-sorted heap to allow operating on transactions pool
-//contents in a price-incrementing way.
+//contents in a price-incrementing way. Transactions removed from the pool are left
+//in the heap as stale price points and skipped, the heap is rebuilt once they
+//exceed a quarter of it.
 type txPricedList struct {
-	items *priceHeap // Heap of prices of all the stored transactions
-	//stales int        // Number of stale price points to (re-heap trigger)
+	all    map[string]*types.Transaction // All transactions of the pool, the others are stale
+	items  *priceHeap                    // Heap of prices of all the stored transactions
+	stales int                           // Number of stale price points (re-heap trigger)
 }
 
-//newTxPricedList creates a new price-sorted transaction heap.
-func newTxPricedList() *txPricedList {
+//newTxPricedList creates a new price-sorted transaction heap of the transactions in all.
+func newTxPricedList(all map[string]*types.Transaction) *txPricedList {
 	return &txPricedList{
+		all:   all,
 		items: new(priceHeap),
 	}
 }
@@ -397,43 +401,139 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 	heap.Push(l.items, tx)
 }
 
-func (l *txPricedList) Remove(tx *types.Transaction) {
-	all := new(priceHeap)
-	for len(*l.items) > 0 {
-		// Discard stale transactions if found during cleanup
-		temp := heap.Pop(l.items).(*types.Transaction)
-		if tx.TxHash() != temp.TxHash() {
-			*all = append(*all, temp)
-		}
+//Removed notifies that a transaction was removed from the pool, its price point
+//turns stale.
+func (l *txPricedList) Removed() {
+	l.stales++
+	if l.stales <= len(*l.items)/4 {
+		return
+	}
+	reheap := make(priceHeap, 0, len(l.all))
+	for _, tx := range l.all {
+		reheap = append(reheap, tx)
 	}
+	heap.Init(&reheap)
+	*l.items = reheap
+	l.stales = 0
+}
+
+//stale reports whether the price point is no longer in the pool
+func (l *txPricedList) stale(tx *types.Transaction) bool {
+	return l.all[tx.TxHash().String()] != tx
+}
 
-	heap.Init(all)
+//Underpriced checks whether tx pays no more than the cheapest remote transaction
+//in the pool, false if there is none.
+func (l *txPricedList) Underpriced(tx *types.Transaction, local map[crypto.CommonAddress]struct{}) bool {
+	cheapest := l.Discard(1, local)
+	if len(cheapest) == 0 {
+		return false
+	}
+	heap.Push(l.items, cheapest[0])
+	return tx.GasPrice().Cmp(cheapest[0].GasPrice()) <= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
 // priced list and returns them for further removal from the entire pool.
-func (l *txPricedList) Discard(count int, local map[crypto.CommonAddress]struct{}) types.Transactions {
-	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
-	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
+func (l *txPricedList) Discard(count int, local map[crypto.CommonAddress]struct{}) []*types.Transaction {
+	drop := make([]*types.Transaction, 0, count) // Remote underpriced transactions to drop
+	save := make([]*types.Transaction, 0, 64)    // Local underpriced transactions to keep
 
 	for len(*l.items) > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
+		if l.stale(tx) {
+			if l.stales > 0 {
+				l.stales--
+			}
+			continue
+		}
 		from, _ := tx.From()
 		// Non stale transaction found, discard unless local
 		if _, ok := local[*from]; ok {
-			save = append(save, *tx)
+			save = append(save, tx)
 		} else {
-			drop = append(drop, *tx)
+			drop = append(drop, tx)
 			count--
 		}
 	}
 	for _, tx := range save {
-		heap.Push(l.items, &tx)
+		heap.Push(l.items, tx)
 	}
 	return drop
 }
 
+// priceHeadHeap is a heap.Interface implementation over the next transaction of each
+// account, the best paying one first.
+type priceHeadHeap []*types.Transaction
+
+func (h priceHeadHeap) Len() int           { return len(h) }
+func (h priceHeadHeap) Less(i, j int) bool { return h[i].GasPrice().Cmp(h[j].GasPrice()) > 0 }
+func (h priceHeadHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *priceHeadHeap) Push(x interface{}) {
+	*h = append(*h, x.(*types.Transaction))
+}
+
+func (h *priceHeadHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+// txsByPrice picks the pending transactions of all accounts by price, the best paying
+// first, while the transactions of one account stay in nonce order.
+type txsByPrice struct {
+	heads priceHeadHeap                                 // Next transaction of each account
+	txs   map[crypto.CommonAddress][]*types.Transaction // Transactions after the head, nonce sorted
+}
+
+// newTxsByPrice creates the picker over the nonce sorted transactions of each account.
+func newTxsByPrice(pending map[crypto.CommonAddress][]*types.Transaction) *txsByPrice {
+	byPrice := &txsByPrice{
+		heads: make(priceHeadHeap, 0, len(pending)),
+		txs:   make(map[crypto.CommonAddress][]*types.Transaction, len(pending)),
+	}
+	for addr, txs := range pending {
+		if len(txs) == 0 {
+			continue
+		}
+		byPrice.heads = append(byPrice.heads, txs[0])
+		byPrice.txs[addr] = txs[1:]
+	}
+	heap.Init(&byPrice.heads)
+	return byPrice
+}
+
+// Peek returns the best paying transaction, nil when none is left.
+func (byPrice *txsByPrice) Peek() *types.Transaction {
+	if len(byPrice.heads) == 0 {
+		return nil
+	}
+	return byPrice.heads[0]
+}
+
+// Shift replaces the best paying transaction by the next one of its account.
+func (byPrice *txsByPrice) Shift() {
+	from, _ := byPrice.heads[0].From()
+	if txs := byPrice.txs[*from]; len(txs) > 0 {
+		byPrice.heads[0], byPrice.txs[*from] = txs[0], txs[1:]
+		heap.Fix(&byPrice.heads, 0)
+		return
+	}
+	heap.Pop(&byPrice.heads)
+}
+
+// Pop removes the best paying transaction and the rest of its account, they can not
+// be packed without it.
+func (byPrice *txsByPrice) Pop() {
+	from, _ := byPrice.heads[0].From()
+	delete(byPrice.txs, *from)
+	heap.Pop(&byPrice.heads)
+}
+
 //type TxByNonce []*types.Transaction
 //
 //func (s TxByNonce) Len() int           { return len(s) }
@@ -0,0 +1,233 @@
+package chain
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/freezer"
+	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
+)
+
+// Ancient store
+//
+// Best chain blocks and their receipts lying AncientDepth blocks below the tip never change,
+// they are moved in the background out of the database into the append-only files of a
+// freezer. Item n of the freezer tables belongs to the best chain block at height n. The
+// database keeps the height of each frozen block under AncientPrefix and the count of frozen
+// blocks under ancientFrozenKey, block nodes, canonical hashes and the receipts of single
+// transactions stay in the database. Reads of a block or receipts missing from the database
+// fall back to the freezer.
+
+const (
+	ancientBlockTable    = "blocks"
+	ancientReceiptsTable = "receipts"
+
+	freezeInterval = time.Minute
+	freezeBatch    = 2048 // blocks moved in one write to the database
+)
+
+var (
+	AncientPrefix = []byte("ancient_")
+
+	ancientFrozenKey = append(ChainStatePrefix, []byte("frozen")...)
+	ancientTables    = []string{ancientBlockTable, ancientReceiptsTable}
+
+	// ancientStore hold the *freezer.Freezer read by every ChainStore, it is shared by the
+	// stores built over the database outside of the chain service
+	ancientStore atomic.Value
+)
+
+func ancient() *freezer.Freezer {
+	f, _ := ancientStore.Load().(*freezer.Freezer)
+	return f
+}
+
+func ancientKey(hash *crypto.Hash) []byte {
+	key := make([]byte, len(AncientPrefix)+crypto.HashLength)
+	copy(key, AncientPrefix)
+	copy(key[len(AncientPrefix):], hash[:])
+	return key
+}
+
+// getAncient read table kind of the frozen block hash
+func (chainStore *ChainStore) getAncient(kind string, hash *crypto.Hash) ([]byte, error) {
+	f := ancient()
+	if f == nil {
+		return nil, ErrBlockNotFound
+	}
+	value, err := chainStore.Get(ancientKey(hash))
+	if err != nil || len(value) != 8 {
+		return nil, ErrBlockNotFound
+	}
+	return f.Retrieve(kind, binary.BigEndian.Uint64(value))
+}
+
+// frozenBlocks return the count of blocks whose move to the freezer reached the database
+func (chainStore *ChainStore) frozenBlocks() uint64 {
+	value, err := chainStore.Get(ancientFrozenKey)
+	if err != nil || len(value) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+// checkAncientDepth check blocks are frozen below the deepest reorganize, a reorganize never
+// detaches a frozen block
+func (chainService *ChainService) checkAncientDepth() error {
+	depth := chainService.Config.AncientDepth
+	if maxReorg := chainService.maxReorgDepth(); depth > 0 && (maxReorg == 0 || depth <= maxReorg) {
+		return errors.Wrapf(ErrAncientDepth, "ancientDepth %d, maxReorgDepth %d", depth, maxReorg)
+	}
+	return nil
+}
+
+// openAncient open the freezer when blocks are moved to it or were moved before, frozen
+// blocks are read from it whatever the config
+func (chainService *ChainService) openAncient() error {
+	dir := chainService.DatabaseService.AncientDir()
+	if chainService.Config.AncientDepth == 0 {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	f, err := freezer.Open(dir, ancientTables)
+	if err != nil {
+		return err
+	}
+	// the blocks appended after the last write to the database are still in the database,
+	// they are moved again
+	frozen := chainService.chainStore.frozenBlocks()
+	if f.Items() < frozen {
+		f.Close()
+		return errors.Wrapf(ErrAncientMissing, "%d blocks in %s, %d frozen", f.Items(), dir, frozen)
+	}
+	if err := f.Truncate(frozen); err != nil {
+		f.Close()
+		return err
+	}
+	ancientStore.Store(f)
+	ancientBlocksGauge.Update(int64(frozen))
+	return nil
+}
+
+// closeAncient close the freezer once the freeze loop stopped
+func (chainService *ChainService) closeAncient() {
+	chainService.freezeWg.Wait()
+	if f := ancient(); f != nil {
+		ancientStore.Store((*freezer.Freezer)(nil))
+		if err := f.Close(); err != nil {
+			log.WithField("Reason", err).Error("close ancient store fail")
+		}
+	}
+}
+
+// freezeLoop move the deep blocks to the freezer until the service stop
+func (chainService *ChainService) freezeLoop() {
+	defer chainService.freezeWg.Done()
+	ticker := time.NewTicker(freezeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for {
+				moved, err := chainService.freeze()
+				if err != nil {
+					log.WithField("Reason", err).Error("freeze blocks fail")
+				}
+				if err != nil || moved < freezeBatch {
+					break
+				}
+				select {
+				case <-chainService.quit:
+					return
+				default:
+				}
+			}
+		case <-chainService.quit:
+			return
+		}
+	}
+}
+
+// freeze move up to freezeBatch best chain blocks AncientDepth below the tip to the freezer
+// and return the count moved. The freezer is synced before the blocks leave the database
+func (chainService *ChainService) freeze() (int, error) {
+	f := ancient()
+	tip := chainService.BestChain().Tip()
+	if f == nil || tip == nil || tip.Height <= chainService.Config.AncientDepth {
+		return 0, nil
+	}
+	first, limit := f.Items(), tip.Height-chainService.Config.AncientDepth
+	if first >= limit {
+		return 0, nil
+	}
+	if limit-first > freezeBatch {
+		limit = first + freezeBatch
+	}
+
+	chainStore := chainService.chainStore
+	hashes := make([]crypto.Hash, 0, limit-first)
+	var err error
+	for height := first; height < limit; height++ {
+		var hash *crypto.Hash
+		if hash, err = chainStore.GetCanonicalHash(height); err != nil {
+			err = errors.Wrapf(err, "canonical hash of height %d", height)
+			break
+		}
+		var block []byte
+		if block, err = chainStore.Get(append(BlockPrefix, hash[:]...)); err != nil {
+			err = errors.Wrapf(err, "block %s", hash.String())
+			break
+		}
+		// blocks without receipts are frozen with empty ones
+		receipts, _ := chainStore.Get(receiptsKey(*hash))
+		if err = f.Append(height, map[string][]byte{ancientBlockTable: block, ancientReceiptsTable: receipts}); err != nil {
+			break
+		}
+		hashes = append(hashes, *hash)
+	}
+	if len(hashes) == 0 {
+		return 0, err
+	}
+	if syncErr := f.Sync(); syncErr != nil {
+		f.Truncate(first)
+		return 0, syncErr
+	}
+
+	// the height of a block is written before it is deleted, reads always find it
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+	chainService.batchStore.Begin()
+	writeErr := func() error {
+		for i := range hashes {
+			height := make([]byte, 8)
+			binary.BigEndian.PutUint64(height, first+uint64(i))
+			if err := chainStore.Put(ancientKey(&hashes[i]), height); err != nil {
+				return err
+			}
+			if err := chainStore.Delete(append(BlockPrefix, hashes[i][:]...)); err != nil {
+				return err
+			}
+			if err := chainStore.Delete(receiptsKey(hashes[i])); err != nil {
+				return err
+			}
+		}
+		frozen := make([]byte, 8)
+		binary.BigEndian.PutUint64(frozen, first+uint64(len(hashes)))
+		return chainStore.Put(ancientFrozenKey, frozen)
+	}()
+	if commitErr := chainService.batchStore.Commit(); writeErr == nil {
+		writeErr = commitErr
+	}
+	if writeErr != nil {
+		// the blocks are still in the database, they are moved again
+		f.Truncate(first)
+		return 0, writeErr
+	}
+	ancientBlocksGauge.Update(int64(first) + int64(len(hashes)))
+	log.WithField("from", first).WithField("count", len(hashes)).Debug("freeze blocks")
+	return len(hashes), err
+}
@@ -12,10 +12,16 @@ import (
 // blocks, it is actually a tree-shaped structure where any node can have
 // multiple children.  However, there can only be one active branch which does
 // indeed form a chain from the tip all the way back to the genesis block.
+//
+// Only a window of nodes around the tip is kept in memory once the index is
+// compacted, older nodes are read from the database when they are looked up.
 type BlockIndex struct {
 	sync.RWMutex
 	Index map[crypto.Hash]*types.BlockNode
 	Dirty map[*types.BlockNode]struct{}
+
+	// loadNode read a node evicted from memory, nil if nodes are never evicted
+	loadNode func(hash *crypto.Hash) (*types.BlockNode, error)
 }
 
 // newBlockIndex returns a new empty instance of a block Index.  The Index will
@@ -28,24 +34,39 @@ func NewBlockIndex() *BlockIndex {
 	}
 }
 
+// SetLoader sets the function reading evicted nodes from the database, nodes
+// are only evicted by Compact once it is set.
+func (bi *BlockIndex) SetLoader(loadNode func(hash *crypto.Hash) (*types.BlockNode, error)) {
+	bi.Lock()
+	bi.loadNode = loadNode
+	bi.Unlock()
+}
+
 // HaveBlock returns whether or not the block Index contains the provided hash.
 //
 // This function is safe for concurrent access.
 func (bi *BlockIndex) HaveBlock(hash *crypto.Hash) bool {
-	bi.RLock()
-	_, hasBlock := bi.Index[*hash]
-	bi.RUnlock()
-	return hasBlock
+	return bi.LookupNode(hash) != nil
 }
 
 // LookupNode returns the block node identified by the provided hash.  It will
-// return nil if there is no entry for the hash.
+// return nil if there is no entry for the hash.  Nodes evicted from memory are
+// read from the database, the returned node is not linked to its parent then.
 //
 // This function is safe for concurrent access.
 func (bi *BlockIndex) LookupNode(hash *crypto.Hash) *types.BlockNode {
 	bi.RLock()
 	node := bi.Index[*hash]
+	loadNode := bi.loadNode
 	bi.RUnlock()
+	if node != nil || loadNode == nil {
+		return node
+	}
+
+	node, err := loadNode(hash)
+	if err != nil {
+		return nil
+	}
 	return node
 }
 
@@ -103,35 +124,90 @@ func (bi *BlockIndex) UnsetStatusFlags(node *types.BlockNode, flags types.BlockS
 
 // FlushToDB writes all dirty block nodes to the database. If all writes
 // succeed, this clears the dirty set.
+//
+// Only the read lock is held while writing, so lookups are not blocked by the
+// disk. Nodes are only modified with the chain block lock held, which the
+// caller must hold too.
 func (bi *BlockIndex) FlushToDB(storeBlockNodeFunc func(node *types.BlockNode) error) error {
-	bi.Lock()
+	bi.RLock()
 	if len(bi.Dirty) == 0 {
-		bi.Unlock()
+		bi.RUnlock()
 		return nil
 	}
+	flushed := make([]*types.BlockNode, 0, len(bi.Dirty))
 	var err error
 	for node := range bi.Dirty {
 		err = storeBlockNodeFunc(node)
 		if err != nil {
 			break
 		}
+		flushed = append(flushed, node)
 	}
+	bi.RUnlock()
 
 	// If write was successful, clear the dirty set.
 	if err == nil {
-		bi.Dirty = make(map[*types.BlockNode]struct{})
+		bi.Lock()
+		for _, node := range flushed {
+			delete(bi.Dirty, node)
+		}
+		bi.Unlock()
 	}
-
-	bi.Unlock()
 	return err
 }
 
+// ClearNode removes the node from the block Index and the Dirty set.
+//
+// This function is safe for concurrent access.
 func (bi *BlockIndex) ClearNode(node *types.BlockNode) {
-	if _, ok := bi.Dirty[node]; ok {
-		delete(bi.Dirty, node)
+	bi.Lock()
+	delete(bi.Dirty, node)
+	delete(bi.Index, *node.Hash)
+	bi.Unlock()
+}
+
+// Compact evicts the nodes below floor from memory, they are read from the
+// database on demand afterwards.  Dirty nodes are kept, and so are the fork
+// points of side chains which still have nodes above floor, so those side
+// chains can still be reorganized to.  Kept nodes are unlinked from evicted
+// parents so the evicted nodes can be collected.  mainChain tells whether a
+// node above floor is on the best chain.
+//
+// Nodes are only modified with the chain block lock held, which the caller
+// must hold too.
+func (bi *BlockIndex) Compact(floor uint64, mainChain func(node *types.BlockNode) bool) int {
+	bi.Lock()
+	defer bi.Unlock()
+	if bi.loadNode == nil {
+		return 0
 	}
 
-	if _, ok := bi.Index[*node.Hash]; ok {
-		delete(bi.Index, *node.Hash)
+	pinned := make(map[*types.BlockNode]struct{})
+	for _, node := range bi.Index {
+		if node.Height >= floor && node.Parent != nil && node.Parent.Height < floor && !mainChain(node) {
+			pinned[node.Parent] = struct{}{}
+		}
+	}
+
+	evicted := 0
+	for hash, node := range bi.Index {
+		if node.Height >= floor {
+			continue
+		}
+		if _, ok := pinned[node]; ok {
+			continue
+		}
+		if _, ok := bi.Dirty[node]; ok {
+			continue
+		}
+		delete(bi.Index, hash)
+		evicted++
+	}
+
+	for _, node := range bi.Index {
+		if node.Parent != nil && bi.Index[*node.Parent.Hash] != node.Parent {
+			node.Parent = nil
+		}
 	}
+	return evicted
 }
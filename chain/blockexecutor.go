@@ -118,9 +118,19 @@ func (chainBlockValidator *ChainBlockValidator) VerifyHeader(header, parent *typ
 func (chainBlockValidator *ChainBlockValidator) VerifyBody(block *types.Block) error {
 	// Header validity is known at this point, check the uncles and transactions
 	header := block.Header
-	if hash := chainBlockValidator.chain.DeriveMerkleRoot(block.Data.TxList); !bytes.Equal(hash, header.TxRoot) {
+	if hash := chainBlockValidator.chain.DeriveMerkleRoot(header.Height, block.Data.TxList); !bytes.Equal(hash, header.TxRoot) {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxRoot)
 	}
+	// chain id is part of signed message, transactions of other networks can not be replayed.
+	// Blocks below the fork were accepted without the check and must stay valid
+	if !chainBlockValidator.chain.genesisParams.IsChainIdFork(header.Height) {
+		return nil
+	}
+	for _, tx := range block.Data.TxList {
+		if tx.ChainId() != chainBlockValidator.chain.ChainID() {
+			return fmt.Errorf("%v, tx:%s chain id:%d", ErrTxChainId, tx.TxHash().String(), tx.ChainId())
+		}
+	}
 	return nil
 }
 
@@ -153,13 +163,16 @@ func (chainBlockValidator *ChainBlockValidator) ExecuteBlock(context *BlockExecu
 		return ErrReceiptRoot
 	}
 
-	for _, receipt := range context.Receipts {
+	for i, receipt := range context.Receipts {
 		receipt.BlockHash = *context.Block.Header.Hash()
 		receipt.PostState = newReceiptRoot[:]
 		err := context.DbStore.PutReceipt(receipt.TxHash, receipt)
 		if err != nil {
 			return err
 		}
+		if err := context.DbStore.PutTxLookup(&receipt.TxHash, &receipt.BlockHash, i); err != nil {
+			return err
+		}
 	}
 	err := context.DbStore.PutReceipts(*context.Block.Header.Hash(), context.Receipts)
 	if err != nil {
@@ -170,6 +183,9 @@ func (chainBlockValidator *ChainBlockValidator) ExecuteBlock(context *BlockExecu
 }
 
 func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *BlockExecuteContext, gasPool *GasPool, tx *types.Transaction) (*types.Receipt, uint64, error) {
+	if _, err := tx.Kind(); err != nil {
+		return nil, 0, err
+	}
 	//init transaction tx
 	from, err := tx.From()
 	if err != nil {
@@ -177,6 +193,7 @@ func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *BlockE
 	}
 
 	txContext := NewExecuteTransactionContext(context, context.TrieStore, gasPool, from, tx)
+	txContext.params = chainBlockValidator.chain.genesisParams
 	if err := txContext.PreCheck(); err != nil {
 		return nil, 0, err
 	}
@@ -197,7 +214,7 @@ func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *BlockE
 			exit = true
 			etr := txValidator.ExecuteTransaction(txContext)
 			if etr.Txerror != nil {
-				return nil, 0, err
+				return nil, 0, etr.Txerror
 			}
 			err = txContext.RefundCoin()
 			if err != nil {
@@ -214,7 +231,6 @@ func (chainBlockValidator *ChainBlockValidator) RouteTransaction(context *BlockE
 			// if the transaction created a contract, store the creation address in the receipt.
 			if (tx.To() == nil || tx.To().IsEmpty()) && tx.Type() == types.CreateContractType {
 				receipt.ContractAddress = crypto.CreateAddress(*from, tx.Nonce())
-				fmt.Println(receipt.ContractAddress)
 			}
 			// Set the receipt logs and create a bloom for filtering
 			receipt.Logs = etr.ContractTxLog
@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+// newChainIdBlock return a block at height carrying a tx of chainId, its tx root over the tx data
+func newChainIdBlock(t *testing.T, height uint64, chainId types.ChainIdType) *types.Block {
+	tx := types.NewTransaction(crypto.CommonAddress{1}, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 0)
+	tx.Data.ChainId = chainId
+	data, err := binary.Marshal(tx.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := common.NewMerkle([][]byte{sha3.Keccak256(data)}).Root.Hash
+	return newTxRootBlock(height, []*types.Transaction{tx}, root)
+}
+
+// TestChainIdFork checks transactions of another chain id are only rejected from the fork on,
+// blocks below it were accepted without the check
+func TestChainIdFork(t *testing.T) {
+	fork := uint64(100)
+	chainService := &ChainService{chainID: 1, genesisParams: &GenesisParams{ChainIdFork: &fork}}
+	validator := NewChainBlockValidator(chainService)
+
+	if err := validator.VerifyBody(newChainIdBlock(t, fork-1, 2)); err != nil {
+		t.Errorf("pre-fork block with a foreign tx: %v", err)
+	}
+	err := validator.VerifyBody(newChainIdBlock(t, fork, 2))
+	if err == nil || !strings.Contains(err.Error(), ErrTxChainId.Error()) {
+		t.Errorf("fork block with a foreign tx: got %v, want %v", err, ErrTxChainId)
+	}
+	if err := validator.VerifyBody(newChainIdBlock(t, fork, 1)); err != nil {
+		t.Errorf("fork block with a tx of the chain: %v", err)
+	}
+
+	// networks not scheduling the fork keep accepting the blocks
+	chainService.genesisParams = &GenesisParams{}
+	if err := validator.VerifyBody(newChainIdBlock(t, fork*10, 2)); err != nil {
+		t.Errorf("unscheduled fork: %v", err)
+	}
+}
+
+// TestSponsorFork checks sponsored transactions are only executed from the fork on, the gas
+// is bought from the fee payer and the sender keeps the balance it has
+func TestSponsorFork(t *testing.T) {
+	disk := memorydb.New()
+	if err := disk.Put([]byte(store.ChangeInterval), new(big.Int).SetUint64(100).FillBytes(make([]byte, 8))); err != nil {
+		t.Fatal(err)
+	}
+	trieStore, err := store.TrieStoreFromCache(disk, nil, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	senderKey, _ := crypto.GenerateKey(rand.Reader)
+	payerKey, _ := crypto.GenerateKey(rand.Reader)
+	payer := crypto.PubkeyToAddress(payerKey.PubKey())
+	if err := trieStore.PutBalance(&payer, 0, big.NewInt(1000000)); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := types.NewTransaction(crypto.CommonAddress{1}, big.NewInt(0), big.NewInt(1), big.NewInt(30000), 0)
+	tx.Sig, _ = secp256k1.SignCompact(senderKey, tx.SponsoredHash(&payer), true)
+	payerSig, _ := secp256k1.SignCompact(payerKey, tx.FeePayerHash(), true)
+	if err := tx.Sponsor(payerSig); err != nil {
+		t.Fatal(err)
+	}
+	from, err := tx.From()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fork := uint64(100)
+	params := &GenesisParams{SponsorFork: &fork}
+	preCheck := func(params *GenesisParams, height uint64) error {
+		block := &types.Block{Header: &types.BlockHeader{Height: height}, Data: &types.BlockData{}}
+		context := NewExecuteTransactionContext(NewBlockExecuteContext(trieStore, new(GasPool).AddGas(30000), nil, block), trieStore, new(GasPool).AddGas(30000), from, tx)
+		context.SetGenesisParams(params)
+		return context.PreCheck()
+	}
+	if err := preCheck(params, fork-1); err != ErrSponsorNotActive {
+		t.Errorf("pre-fork sponsored tx: got %v, want %v", err, ErrSponsorNotActive)
+	}
+	if err := preCheck(&GenesisParams{}, fork*10); err != ErrSponsorNotActive {
+		t.Errorf("unscheduled fork: got %v, want %v", err, ErrSponsorNotActive)
+	}
+	if err := preCheck(params, fork); err != nil {
+		t.Fatalf("fork block with a sponsored tx: %v", err)
+	}
+	if balance := trieStore.GetBalance(&payer, fork); balance.Cmp(big.NewInt(1000000-30000)) != 0 {
+		t.Errorf("fee payer balance %v after buying the gas", balance)
+	}
+	if balance := trieStore.GetBalance(from, fork); balance.Sign() != 0 {
+		t.Errorf("sender balance %v after buying the gas", balance)
+	}
+}
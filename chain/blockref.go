@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+// BlockRef refer a block of main chain by height or by hash. In json it is a height number,
+// a height string or a 0x prefixed block hash
+type BlockRef struct {
+	Height *uint64
+	Hash   *crypto.Hash
+}
+
+func (ref *BlockRef) UnmarshalJSON(input []byte) error {
+	var number uint64
+	if err := json.Unmarshal(input, &number); err == nil {
+		ref.Height, ref.Hash = &number, nil
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(input, &text); err != nil {
+		return ErrInvalidBlockRef
+	}
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		hash := &crypto.Hash{}
+		if err := hash.UnmarshalText([]byte(text)); err != nil {
+			return ErrInvalidBlockRef
+		}
+		ref.Height, ref.Hash = nil, hash
+		return nil
+	}
+	number, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return ErrInvalidBlockRef
+	}
+	ref.Height, ref.Hash = &number, nil
+	return nil
+}
+
+func (ref BlockRef) MarshalJSON() ([]byte, error) {
+	if ref.Hash != nil {
+		return json.Marshal(ref.Hash)
+	}
+	if ref.Height != nil {
+		return json.Marshal(*ref.Height)
+	}
+	return []byte("null"), nil
+}
+
+// node return the main chain node of ref, the tip if ref is nil
+func (chain *ChainApi) node(ref *BlockRef) (*types.BlockNode, error) {
+	if ref == nil || (ref.Height == nil && ref.Hash == nil) {
+		return chain.chainView.Tip(), nil
+	}
+	if ref.Height != nil {
+		node := chain.chainView.NodeByHeight(*ref.Height)
+		if node == nil {
+			return nil, ErrBlockNotFound
+		}
+		return node, nil
+	}
+	node := chain.chainService.Index().LookupNode(ref.Hash)
+	if node == nil || !chain.chainView.Contains(node) {
+		return nil, ErrBlockNotFound
+	}
+	return node, nil
+}
+
+// stateAt open the state trie at the state root of a main chain block, ErrStatePruned is
+// returned when the node mode does not keep it or it is not found
+func (chain *ChainApi) stateAt(ref *BlockRef) (store.StoreInterface, *types.BlockNode, error) {
+	node, err := chain.node(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !chain.chainService.stateRetained(node) {
+		return nil, nil, errors.Wrapf(ErrStatePruned, "%s node at height %d", chain.chainService.nodeMode(), node.Height)
+	}
+	trieStore, err := store.TrieStoreFromStore(chain.store, node.StateRoot)
+	if err == store.ErrRecoverRoot {
+		return nil, nil, errors.Wrapf(ErrStatePruned, "height %d", node.Height)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return trieStore, node, nil
+}
@@ -2,11 +2,39 @@ package chain
 
 import (
 	"encoding/json"
+
 	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
 )
 
 /**********************un register ********************/
 
+// encodeCancelDetail encode the cancel credit detail of a log in the block at height, json
+// below the cancel log fork
+func encodeCancelDetail(params *GenesisParams, height uint64, detail *types.CancelCreditDetail) ([]byte, error) {
+	if params.IsCancelLogFork(height) {
+		return binary.Marshal(detail)
+	}
+	return json.Marshal(detail)
+}
+
+// decodeCancelDetail decode the cancel credit detail of a log in the block at height, the
+// other encoding is tried if the one of the height fails
+func decodeCancelDetail(params *GenesisParams, height uint64, data []byte) (*types.CancelCreditDetail, error) {
+	decoders := []func([]byte, interface{}) error{json.Unmarshal, binary.Unmarshal}
+	if params.IsCancelLogFork(height) {
+		decoders[0], decoders[1] = decoders[1], decoders[0]
+	}
+	var err error
+	for _, decode := range decoders {
+		detail := &types.CancelCreditDetail{}
+		if err = decode(data, detail); err == nil {
+			return detail, nil
+		}
+	}
+	return nil, err
+}
+
 type CancelCandidateTxSelector struct {
 }
 
@@ -35,7 +63,7 @@ func (processor *CancelCandidateTransactionProcessor) ExecuteTransaction(context
 	}
 
 	logs := make([]*types.Log, 0, 1)
-	data, _ := json.Marshal(detail)
+	data, _ := encodeCancelDetail(context.GenesisParams(), context.header.Height, detail)
 
 	log := types.Log{TxType: tx.Type(), TxHash: *tx.TxHash(), Data: data, Height: context.header.Height, TxIndex: 0}
 	logs = append(logs, &log)
@@ -1,7 +1,6 @@
 package chain
 
 import (
-	"encoding/json"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -35,7 +34,7 @@ func (processor *CancelVoteTransactionProcessor) ExecuteTransaction(context *Exe
 	}
 
 	logs := make([]*types.Log, 0, 1)
-	data, _ := json.Marshal(detail)
+	data, _ := encodeCancelDetail(context.GenesisParams(), context.header.Height, detail)
 
 	log := types.Log{TxType: tx.Type(), TxHash: *tx.TxHash(), Data: data, Height: context.header.Height, TxIndex: 0}
 	logs = append(logs, &log)
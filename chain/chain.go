@@ -1,9 +1,12 @@
 package chain
 
 import (
+	"container/list"
 	"encoding/json"
 	"fmt"
+	"github.com/allegro/bigcache"
 	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/trie"
 	"math/big"
 	"sync"
 
@@ -25,9 +28,23 @@ import (
 var (
 	RootChain          types.ChainIdType
 	DefaultChainConfig = &ChainConfig{
-		RemotePort:  55556,
-		ChainId:     RootChain,
-		GenesisAddr: params.HoleAddress,
+		RemotePort:    55556,
+		ChainId:       RootChain,
+		GenesisAddr:   params.HoleAddress,
+		MaxReorgDepth: DefaultMaxReorgDepth,
+
+		MaxOrphanBlocks:   DefaultMaxOrphanBlocks,
+		MaxPeerOrphans:    DefaultMaxPeerOrphans,
+		OrphanExpiration:  DefaultOrphanExpiration,
+		MaxOrphanDistance: DefaultMaxOrphanDistance,
+
+		IndexWindow:      DefaultIndexWindow,
+		ResumeCheckDepth: DefaultResumeCheckDepth,
+
+		StateCommitInterval: DefaultStateCommitInterval,
+
+		NodeMode:       DefaultNodeMode,
+		StateRetention: DefaultStateRetention,
 	}
 	span = uint64(params.MaxGasLimit / 360)
 )
@@ -35,7 +52,7 @@ var (
 type ChainServiceInterface interface {
 	app.Service
 	ChainID() types.ChainIdType
-	DeriveMerkleRoot(txs []*types.Transaction) []byte
+	DeriveMerkleRoot(height uint64, txs []*types.Transaction) []byte
 	DeriveReceiptRoot(receipts []*types.Receipt) crypto.Hash
 	GetBlockByHash(hash *crypto.Hash) (*types.Block, error)
 	GetBlockByHeight(number uint64) (*types.Block, error)
@@ -51,6 +68,8 @@ type ChainServiceInterface interface {
 	BestChain() *ChainView
 	CalcGasLimit(parent *types.BlockHeader, gasFloor, gasCeil uint64) *big.Int
 	ProcessBlock(block *types.Block) (bool, bool, error)
+	ProcessPeerBlock(block *types.Block, peer string) (bool, bool, error)
+	IsKnownOrphan(hash *crypto.Hash) bool
 	NewBlockFeed() *event.Feed
 	GetLogsFeed() *event.Feed
 	GetRMLogsFeed() *event.Feed
@@ -61,8 +80,13 @@ type ChainServiceInterface interface {
 	TransactionValidators() map[ITransactionSelector]ITransactionValidator
 	AddTransactionValidator(selector ITransactionSelector, validator ITransactionValidator)
 	AddGenesisProcess(validator IGenesisProcess)
+	SetPendingTxLookup(lookup func(txHash crypto.Hash) *types.Transaction)
 	GetConfig() *ChainConfig
 	DetachBlockFeed() *event.Feed
+	ReorgFeed() *event.Feed
+	ReorgHistory() []*ReorgRecord
+	StaleStat(producer crypto.CommonAddress) *StaleStat
+	GenesisParams() *GenesisParams
 }
 
 var cs ChainServiceInterface = &ChainService{}
@@ -75,18 +99,30 @@ type ChainService struct {
 
 	chainID types.ChainIdType
 
-	lock         sync.RWMutex
+	lock sync.RWMutex
+	// addBlockSync serialize the blocks changing the chain state, i.e. the block index nodes,
+	// the best chain and the state trie. Reads never take it, they rely on the locks of the
+	// block index, the chain view and the orphan pool
 	addBlockSync sync.Mutex
 
+	// trustedImport skip header and body verification of blocks imported from snapshot, protected by addBlockSync
+	trustedImport bool
+
 	// These fields are related to handling of orphan blocks.  They are
 	// protected by a combination of the chain lock and the orphan lock.
-	orphanLock   sync.RWMutex
-	orphans      map[crypto.Hash]*types.OrphanBlock
-	prevOrphans  map[crypto.Hash][]*types.OrphanBlock
-	oldestOrphan *types.OrphanBlock
+	orphanLock  sync.RWMutex
+	orphans     map[crypto.Hash]*types.OrphanBlock
+	prevOrphans map[crypto.Hash][]*types.OrphanBlock
+	orphanLRU   *list.List // front is the most recently used
+	orphanElems map[crypto.Hash]*list.Element
+	orphanPeers map[string]int
+	orphanBytes int
+	quit        chan struct{}
+	freezeWg    sync.WaitGroup // freeze loop, see ancient.go
 
 	blockIndex *BlockIndex
 	bestChain  *ChainView
+	indexFloor uint64 // height below which the block index was last compacted
 
 	Config       *ChainConfig
 	genesisBlock *types.Block
@@ -96,12 +132,21 @@ type ChainService struct {
 	detachBlockFeed event.Feed
 	logsFeed        event.Feed
 	rmLogsFeed      event.Feed
+	reorgFeed       event.Feed
+	reorgHistory    reorgHistory
 
 	blockValidator       BlockValidators
 	transactionValidator map[ITransactionSelector]ITransactionValidator
 	genesisProcess       []IGenesisProcess
+	pendingTx            func(txHash crypto.Hash) *types.Transaction // transactions of the pool, see txlookup.go
 	chainStore           *ChainStore
+	batchStore           *database.BatchStore
+	trieCleans           *bigcache.BigCache
+	trieLayers           *store.TrieLayers // states of recent blocks not written to disk yet
+	reimportBlocks       []*types.Block    // blocks of an interrupted import, imported again on start
 	genesisConfig        json.RawMessage
+	genesisParams        *GenesisParams
+	initGenesisFile      string //Set when run init command, the genesis file is saved to it
 }
 
 type ChainState struct {
@@ -156,11 +201,41 @@ type ChainState struct {
 //}
 
 func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error {
+	if err := chainService.checkNodeMode(); err != nil {
+		return err
+	}
+	if err := chainService.checkAncientDepth(); err != nil {
+		return err
+	}
 	chainService.blockIndex = NewBlockIndex()
 	chainService.bestChain = NewChainView(nil)
-	chainService.chainStore = &ChainStore{chainService.DatabaseService.LevelDb()}
+	chainService.batchStore = database.NewBatchStore(chainService.DatabaseService.LevelDb())
+	chainService.chainStore = &ChainStore{chainService.batchStore}
+	chainService.blockIndex.SetLoader(chainService.chainStore.LoadBlockNode)
+	chainService.bestChain.SetLoader(chainService.loadCanonicalNode)
+	// frozen blocks, the genesis block first, are only found in the freezer
+	if err := chainService.openAncient(); err != nil {
+		return err
+	}
+	chainService.trieCleans = trie.NewCleanCache(int(app.CacheAllowance(app.CacheTrie) / 1024 / 1024))
+	store.SetSharedTrie(chainService.trieCleans, nil)
+	app.RegisterCacheUsage(app.CacheTrie, func() int64 {
+		if chainService.trieCleans == nil {
+			return 0
+		}
+		return int64(chainService.trieCleans.Capacity())
+	})
+	app.RegisterCacheUsage(app.CacheOrphans, chainService.orphanUsage)
+	// blocks are refused in maintenance mode once the import in flight finished, the
+	// state of the tip is then written to disk
+	app.RegisterMaintenance(MODULENAME, app.MaintenanceDrain, chainService.commitTip, nil)
+	app.RegisterShutdown(MODULENAME, app.ShutdownFlush, chainService.flushState)
 	chainService.orphans = make(map[crypto.Hash]*types.OrphanBlock)
 	chainService.prevOrphans = make(map[crypto.Hash][]*types.OrphanBlock)
+	chainService.orphanLRU = list.New()
+	chainService.orphanElems = make(map[crypto.Hash]*list.Element)
+	chainService.orphanPeers = make(map[string]int)
+	chainService.quit = make(chan struct{})
 
 	chainService.blockValidator = []IBlockValidator{NewChainBlockValidator(chainService)}
 	chainService.genesisProcess = []IGenesisProcess{NewPreminerGenesisProcessor()}
@@ -171,13 +246,12 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 		&CancelVoteTxSelector{}:      &CancelVoteTransactionProcessor{},
 		&CandidateTxSelector{}:       &CandidateTransactionProcessor{},
 		&CancelCandidateTxSelector{}: &CancelCandidateTransactionProcessor{},
+		&TokenTxSelector{}:           &TokenTransactionProcessor{},
 	}
 
-	var err error
-	//chainService.genesisConfig = path.Join(executeContext.CommonConfig.HomeDir, "genesis.json")
-	chainService.genesisConfig = executeContext.PhaseConfig["genesis"]
-	if chainService.genesisConfig == nil {
-		return fmt.Errorf("no genesis config,please check config.json")
+	err := chainService.loadGenesisConfig(executeContext)
+	if err != nil {
+		return err
 	}
 
 	chainService.genesisBlock, err = chainService.GetGenisiBlock(chainService.Config.GenesisAddr)
@@ -186,6 +260,9 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 	}
 	hash := chainService.genesisBlock.Header.Hash()
 	if !chainService.chainStore.HasBlock(hash) {
+		if chainService.initGenesisFile != "" && chainService.chainStore.BlockNodeCount() > 0 {
+			return ErrGenesisExist
+		}
 		chainService.genesisBlock, err = chainService.ProcessGenesisBlock(chainService.Config.GenesisAddr)
 		err = chainService.createChainState()
 		if err != nil {
@@ -202,25 +279,66 @@ func (chainService *ChainService) Init(executeContext *app.ExecuteContext) error
 		log.Error("InitStates err:", err)
 		return err
 	}
+	chainService.initStateLayers()
 	chainService.apis = []app.API{
 		{
 			Namespace: MODULENAME,
 			Version:   "1.0",
-			Service:   NewChainApi(chainService.DatabaseService.LevelDb(), chainService.BestChain(), chainService.chainStore),
+			Service:   NewChainApi(chainService, chainService.DatabaseService.LevelDb(), chainService.BestChain(), chainService.chainStore),
 			Public:    true,
 		},
 	}
+	app.RegisterStatus(MODULENAME, chainService.status)
 	return nil
 }
 
 func (chainService *ChainService) Start(executeContext *app.ExecuteContext) error {
+	go chainService.expireOrphansLoop()
+	if executeContext.Cli != nil && executeContext.Cli.Command.Name != "" {
+		switch executeContext.Cli.Command.Name {
+		case "export", "import":
+			return chainService.runSnapshotCommand(executeContext)
+		case InitCommand.Name:
+			return chainService.finishInit(executeContext)
+		case MigrateCommand.Name:
+			return chainService.runMigrateCommand(executeContext)
+		case DbCheckCommand.Name:
+			return chainService.runDbCheckCommand(executeContext)
+		}
+	}
+	chainService.reimport()
+	if chainService.Config.AncientDepth > 0 {
+		chainService.freezeWg.Add(1)
+		go chainService.freezeLoop()
+	}
 	return nil
 }
 
 func (chainService *ChainService) Stop(executeContext *app.ExecuteContext) error {
+	if chainService.quit != nil {
+		close(chainService.quit)
+	}
+	// blocks imported after the shutdown hooks ran are flushed here
+	if err := chainService.flushState(); err != nil {
+		log.WithField("Reason", err).Error("commit tip state fail")
+		return err
+	}
+	chainService.closeAncient()
 	return nil
 }
 
+// flushState write the dirty block index and the state of the tip to disk, once the import in
+// flight finished
+func (chainService *ChainService) flushState() error {
+	if chainService.blockIndex == nil {
+		return nil
+	}
+	chainService.addBlockSync.Lock()
+	chainService.flushIndexState()
+	chainService.addBlockSync.Unlock()
+	return chainService.commitTip()
+}
+
 func (chainService *ChainService) BlockExists(blockHash *crypto.Hash) bool {
 	return chainService.blockIndex.HaveBlock(blockHash)
 }
@@ -262,8 +380,8 @@ func (chainService *ChainService) GetBlockByHash(hash *crypto.Hash) (*types.Bloc
 }
 
 func (chainService *ChainService) GetBlockHeaderByHash(hash *crypto.Hash) (*types.BlockHeader, error) {
-	blockNode, ok := chainService.blockIndex.Index[*hash]
-	if !ok {
+	blockNode := chainService.blockIndex.LookupNode(hash)
+	if blockNode == nil {
 		return nil, ErrBlockNotFound
 	}
 	blockHeader := blockNode.Header()
@@ -289,9 +407,17 @@ func (chainService *ChainService) GetBlockHeaderByHeight(number uint64) (*types.
 	return &header, nil
 }
 
-func (chainService *ChainService) getTxHashes(ts []*types.Transaction) ([][]byte, error) {
+// getTxHashes returns the merkle leaves of the txs of the block at height. From
+// the tx root fork a leaf is the hash of the canonical encoding of the
+// transaction including its signature, below it the hash of the tx data.
+func (chainService *ChainService) getTxHashes(height uint64, ts []*types.Transaction) ([][]byte, error) {
 	txHashes := make([][]byte, len(ts))
+	fork := chainService.genesisParams.IsTxRootFork(height)
 	for i, tx := range ts {
+		if fork {
+			txHashes[i] = sha3.Keccak256(tx.AsPersistentMessage())
+			continue
+		}
 		b, err := binary.Marshal(tx.Data)
 		if err != nil {
 			return nil, err
@@ -301,15 +427,33 @@ func (chainService *ChainService) getTxHashes(ts []*types.Transaction) ([][]byte
 	return txHashes, nil
 }
 
-func (chainService *ChainService) DeriveMerkleRoot(txs []*types.Transaction) []byte {
+func (chainService *ChainService) DeriveMerkleRoot(height uint64, txs []*types.Transaction) []byte {
 	if len(txs) == 0 {
 		return []byte{}
 	}
-	ts, _ := chainService.getTxHashes(txs)
+	ts, _ := chainService.getTxHashes(height, txs)
 	merkle := common.NewMerkle(ts)
 	return merkle.Root.Hash
 }
 
+// DeriveMerkleProof returns the merkle leaf of the transaction at index of the
+// block at height and its inclusion proof against the root returned by
+// DeriveMerkleRoot.
+func (chainService *ChainService) DeriveMerkleProof(height uint64, txs []*types.Transaction, index int) ([]byte, [][]byte, error) {
+	if index < 0 || index >= len(txs) {
+		return nil, nil, ErrTxIndexOutOfRange
+	}
+	leaves, err := chainService.getTxHashes(height, txs)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := common.NewMerkle(leaves).Proof(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return leaves[index], proof, nil
+}
+
 func (chainService *ChainService) DeriveReceiptRoot(receipts []*types.Receipt) crypto.Hash {
 	if len(receipts) == 0 {
 		return crypto.Hash{}
@@ -339,6 +483,11 @@ func (chainService *ChainService) createChainState() error {
 		return err
 	}
 
+	err = chainService.putCanonicalChain(node)
+	if err != nil {
+		return err
+	}
+
 	err = chainService.chainStore.PutBlock(chainService.genesisBlock)
 	if err != nil {
 		return err
@@ -414,7 +563,7 @@ func (chainService *ChainService) Api() []app.API {
 }
 
 func (chainService *ChainService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{}
+	return append(snapshotCommands(), InitCommand, MigrateCommand, DbCommand), []cli.Flag{}
 }
 
 // DefaultConfig -> config
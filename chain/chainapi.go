@@ -14,6 +14,7 @@ import (
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
+	"github.com/pkg/errors"
 	"math/big"
 )
 
@@ -24,16 +25,18 @@ prefix:chain
 
 */
 type ChainApi struct {
-	store     dbinterface.KeyValueStore
-	chainView *ChainView
-	dbQuery   *ChainStore
+	chainService *ChainService
+	store        dbinterface.KeyValueStore
+	chainView    *ChainView
+	dbQuery      *ChainStore
 }
 
-func NewChainApi(store dbinterface.KeyValueStore, chainView *ChainView, dbQuery *ChainStore) *ChainApi {
+func NewChainApi(chainService *ChainService, store dbinterface.KeyValueStore, chainView *ChainView, dbQuery *ChainStore) *ChainApi {
 	return &ChainApi{
-		store:     store,
-		chainView: chainView,
-		dbQuery:   dbQuery,
+		chainService: chainService,
+		store:        store,
+		chainView:    chainView,
+		dbQuery:      dbQuery,
 	}
 }
 
@@ -85,6 +88,77 @@ func (chain *ChainApi) GetBlock(height uint64) (*types.Block, error) {
 	return block, nil
 }
 
+/*
+ name: getBlockByHeight
+ usage: Used to obtain a main chain block with the hashes of its transactions, or with the decoded transactions
+ params:
+	1. height
+	2. includeTxs (optional), return the decoded transactions instead of their hashes
+ return: block hash, header, proof and transactions
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBlockByHeight","params":[1,true], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"Hash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","Header":{"ChainId":0,"Version":1,"Height":1,...},"Proof":{"Type":0,"Evidence":"..."},"TxCount":1,"Transactions":[{"Hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","From":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","Nonce":15632,"Kind":"transfer",...,"BlockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","Height":1,"Index":0}]}}
+*/
+func (chain *ChainApi) GetBlockByHeight(height uint64, includeTxs *bool) (*RPCBlock, error) {
+	block, err := chain.GetBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	return newRPCBlock(block, includeTxs != nil && *includeTxs), nil
+}
+
+/*
+ name: getBlockByHash
+ usage: Used to obtain a block by hash with the hashes of its transactions, or with the decoded transactions
+ params:
+	1. block hash
+	2. includeTxs (optional), return the decoded transactions instead of their hashes
+ return: block hash, header, proof and transactions, as getBlockByHeight
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBlockByHash","params":["0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"Hash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","Header":{"ChainId":0,"Version":1,"Height":1,...},"Proof":{"Type":0,"Evidence":"..."},"TxCount":1,"Transactions":["0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040"]}}
+*/
+func (chain *ChainApi) GetBlockByHash(hash crypto.Hash, includeTxs *bool) (*RPCBlock, error) {
+	block, err := chain.dbQuery.GetBlock(&hash)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	return newRPCBlock(block, includeTxs != nil && *includeTxs), nil
+}
+
+/*
+ name: getBlocksByRange
+ usage: Used to obtain the main chain blocks from start to end included, at most 100 in one call
+ params:
+	1. start height
+	2. end height, the best height is used if it is higher
+	3. includeTxs (optional), return the decoded transactions instead of their hashes
+ return: blocks in order of height, as getBlockByHeight
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBlocksByRange","params":[1,2,false], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":[{"Hash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","Header":{"Height":1,...},"Proof":{...},"TxCount":0,"Transactions":[]},{"Hash":"0x8e4c2d0c4a2c6a3e7d5e4a1c1d3e2a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f","Header":{"Height":2,...},"Proof":{...},"TxCount":0,"Transactions":[]}]}
+*/
+func (chain *ChainApi) GetBlocksByRange(start, end uint64, includeTxs *bool) ([]*RPCBlock, error) {
+	if tip := chain.chainView.Tip(); tip != nil && end > tip.Height {
+		end = tip.Height
+	}
+	if end < start {
+		return nil, ErrBlockRange
+	}
+	if end-start >= maxBlockRange {
+		return nil, errors.Wrapf(ErrBlockRange, "at most %d blocks", maxBlockRange)
+	}
+	blocks := make([]*RPCBlock, 0, end-start+1)
+	for height := start; height <= end; height++ {
+		block, err := chain.GetBlock(height)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, newRPCBlock(block, includeTxs != nil && *includeTxs))
+	}
+	return blocks, nil
+}
+
 /*
  name: getMaxHeight
  usage: To get the current highest block
@@ -99,6 +173,20 @@ func (chain *ChainApi) GetMaxHeight() uint64 {
 	return chain.chainView.Tip().Height
 }
 
+/*
+ name: getChainId
+ usage: Get the chain id of this node
+ params:
+	1. 无
+ return: chain id
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getChainId","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":0}
+*/
+func (chain *ChainApi) GetChainId() types.ChainIdType {
+	return chain.chainService.ChainID()
+}
+
 /*
  name: getBlockGasInfo
  usage: Obtain gas related information
@@ -138,37 +226,104 @@ func (chain *ChainApi) GetBlockGasInfo() string {
 
 /*
  name: getBalance
- usage: Query address balance
+ usage: Query address balance, at the latest block or at a block of main chain
  params:
 	1. Query address
+	2. Block height or block hash, optional, the latest block if omitted
  return: The account balance in the address
- example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", 1000], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":9987999999999984000000}
 */
-func (chain *ChainApi) GetBalance(addr crypto.CommonAddress) string {
-	store, err := store.TrieStoreFromStore(chain.store, chain.chainView.Tip().StateRoot)
+func (chain *ChainApi) GetBalance(addr crypto.CommonAddress, block *BlockRef) (string, error) {
+	store, node, err := chain.stateAt(block)
 	if err != nil {
-		return ""
+		return "", err
 	}
-	big := store.GetBalance(&addr, chain.chainView.tip().Height)
+	return store.GetBalance(&addr, node.Height).String(), nil
+}
 
-	return big.String()
+/*
+ name: getBalanceAmount
+ usage: Query address balance with its canonical wei value, at the latest block or at a block of main chain
+ params:
+	1. Query address
+	2. Block height or block hash, optional, the latest block if omitted
+ return: The raw hex, decimal wei and drep forms of the balance
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getBalanceAmount","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"raw":"0x21e19e0c9bab2400000","wei":"10000000000000000000000","human":"10000drep"}}
+*/
+func (chain *ChainApi) GetBalanceAmount(addr crypto.CommonAddress, block *BlockRef) (*common.Amount, error) {
+	store, node, err := chain.stateAt(block)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewAmount(store.GetBalance(&addr, node.Height)), nil
 }
 
 /*
  name: getNonce
- usage: Query the nonce whose address is on the chain
+ usage: Query the nonce whose address is on the chain, at the latest block or at a block of main chain
  params:
 	1. Query address
+	2. Block height or block hash, optional, the latest block if omitted
  return: nonce
- example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getNonce","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getNonce","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", "0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9"], "id": 3}' -H "Content-Type:application/json"
  response:
    {"jsonrpc":"2.0","id":3,"result":0}
 */
-func (chain *ChainApi) GetNonce(addr crypto.CommonAddress) uint64 {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
-	return trieQuery.GetNonce(&addr)
+func (chain *ChainApi) GetNonce(addr crypto.CommonAddress, block *BlockRef) (uint64, error) {
+	store, _, err := chain.stateAt(block)
+	if err != nil {
+		return 0, err
+	}
+	return store.GetNonce(&addr), nil
+}
+
+/*
+ name: getProof
+ usage: Query the merkle proof of an account and of storage slots of a contract, at the latest block or at a block of main chain. The proofs are the trie nodes from the state root to the value, so light clients and bridges verify the state without trusting the node
+ params:
+	1. Query address
+	2. Storage slots of the contract, at most 64
+	3. Block height or block hash, optional, the latest block if omitted
+ return: the state root, balance, nonce and code hash of the account, the encoded account storage with its proof and the proof of each slot
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getProof","params":["0x8a8e541ddd1272d53729164c70197221a3c27486", ["0x0000000000000000000000000000000000000000000000000000000000000000"], 10], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"address":"0x8a8e541ddd1272d53729164c70197221a3c27486","height":10,"stateRoot":"0x2c8ea0d6ffb7d5b0a7d4fd2c2b0cd4a8d9b1d1c1f47a0a0cbe10a4e0b58c5a17","balance":"0x21e19e0c9bab2400000","nonce":1,"codeHash":"0x0000000000000000000000000000000000000000000000000000000000000000","key":"0x3f0d9e7f...","value":"0x...","accountProof":["0xf90211a0...","0xf871..."],"storageProof":[{"slot":"0x0000000000000000000000000000000000000000000000000000000000000000","key":"0x5a1c...","value":"0x","proof":["0xf90211a0..."]}]}}
+*/
+func (chain *ChainApi) GetProof(addr crypto.CommonAddress, slots []crypto.Hash, block *BlockRef) (*AccountProof, error) {
+	if len(slots) > maxProofSlots {
+		return nil, ErrTooManySlots
+	}
+	trieStore, node, err := chain.stateAt(block)
+	if err != nil {
+		return nil, err
+	}
+	proof := &AccountProof{
+		Address:      addr,
+		Height:       node.Height,
+		StateRoot:    node.StateRoot,
+		Balance:      (*hexutil.Big)(trieStore.GetBalance(&addr, node.Height)),
+		Nonce:        trieStore.GetNonce(&addr),
+		CodeHash:     trieStore.GetCodeHash(&addr),
+		Key:          store.StorageKey(&addr),
+		StorageProof: []*StorageProof{},
+	}
+	proof.Value, proof.AccountProof, err = prove(trieStore, proof.Key)
+	if err != nil {
+		return nil, err
+	}
+	for i := range slots {
+		slot := &StorageProof{Slot: slots[i], Key: slotKey(&addr, &slots[i])}
+		slot.Value, slot.Proof, err = prove(trieStore, slot.Key)
+		if err != nil {
+			return nil, err
+		}
+		proof.StorageProof = append(proof.StorageProof, slot)
+	}
+	return proof, nil
 }
 
 /*
@@ -226,6 +381,48 @@ func (chain *ChainApi) GetTransactionByBlockHeightAndIndex(height uint64, index
 	return block.Data.TxList[index], nil
 }
 
+/*
+ name: getTransactionByHash
+ usage: Query a transaction by hash in the main chain, then in the transaction pool
+ params:
+	1. transaction hash
+ return: the transaction with its block hash, height, index in the block and confirmations, or pending
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getTransactionByHash","params":["0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"Hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","From":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","Version":1,"Nonce":15632,"Type":0,...,"Kind":"transfer","BlockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","Height":10000,"Index":1,"Pending":false,"Confirmations":12}}
+*/
+func (chain *ChainApi) GetTransactionByHash(txHash crypto.Hash) (*RPCTxLookup, error) {
+	tx, node, index, err := chain.chainService.LookupTransaction(txHash)
+	if err == nil {
+		lookup := &RPCTxLookup{RPCTransaction: newRPCTransaction(tx, node.Hash, node.Height, index)}
+		if tip := chain.chainView.Tip(); tip != nil && tip.Height >= node.Height {
+			lookup.Confirmations = tip.Height - node.Height + 1
+		}
+		return lookup, nil
+	}
+	if err != ErrTxNotFound {
+		return nil, err
+	}
+	if tx := chain.chainService.PendingTransaction(txHash); tx != nil {
+		return &RPCTxLookup{RPCTransaction: newRPCTransaction(tx, &crypto.Hash{}, 0, 0), Pending: true}, nil
+	}
+	return nil, ErrTxNotFound
+}
+
+/*
+ name: getTransactionProof
+ usage: Get the merkle inclusion proof of a transaction on the main chain
+ params:
+	1. txhash
+ return: leaf hash of the transaction, its index in block, sibling hashes from leaf to TxRoot (an empty sibling means the node is hashed alone)
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getTransactionProof","params":["0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"txHash":"0x7d9dd32ca192e765ff2abd7c5f8931cc3f77f8f47d2d52170c7804c2ca2c5dd9","blockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","height":100,"txRoot":"0x...","index":2,"leaf":"0x...","proof":["0x...","0x"]}}
+*/
+func (chain *ChainApi) GetTransactionProof(txHash crypto.Hash) (*TxProof, error) {
+	return chain.chainService.GetTxProof(txHash)
+}
+
 /*
  name: getAliasByAddress
  usage: Gets the alias corresponding to the address according to the address
@@ -237,7 +434,10 @@ func (chain *ChainApi) GetTransactionByBlockHeightAndIndex(height uint64, index
 	{"jsonrpc":"2.0","id":3,"result":"tom"}
 */
 func (chain *ChainApi) GetAliasByAddress(addr *crypto.CommonAddress) string {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
+	trieQuery, err := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
+	if err != nil {
+		return ""
+	}
 	return trieQuery.GetStorageAlias(addr)
 }
 
@@ -252,7 +452,10 @@ func (chain *ChainApi) GetAliasByAddress(addr *crypto.CommonAddress) string {
    {"jsonrpc":"2.0","id":3,"result":"0x8a8e541ddd1272d53729164c70197221a3c27486"}
 */
 func (chain *ChainApi) GetAddressByAlias(alias string) (*crypto.CommonAddress, error) {
-	trieQuery, _ := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
+	trieQuery, err := NewTrieQuery(chain.store, chain.chainView.Tip().StateRoot)
+	if err != nil {
+		return nil, err
+	}
 	return trieQuery.AliasGet(alias)
 }
 
@@ -317,10 +520,9 @@ func (chain *ChainApi) GetCancelCreditDetailByTXHash(txHash crypto.Hash) []*type
 	if rt != nil {
 		for _, log := range rt.Logs {
 			if log.TxType == types.CancelVoteCreditType || log.TxType == types.CancelCandidateType {
-				id := types.CancelCreditDetail{}
-				err := json.Unmarshal(log.Data, &id)
+				id, err := decodeCancelDetail(chain.chainService.genesisParams, log.Height, log.Data)
 				if err == nil {
-					ids = append(ids, &id)
+					ids = append(ids, id)
 				}
 			}
 		}
@@ -485,6 +687,70 @@ func (chain *ChainApi) GetAvgPrice(height uint64) (*big.Int, error) {
 
 }
 
+/*
+ name: getReorgHistory
+ usage: Get recent chain reorganizes, newest first
+ params:
+	1. 无
+ return: reorganize records, include fork point and the hashes of detached and attached blocks
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getReorgHistory","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":[{"time":1592365562,"forkHeight":100,"forkHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","oldChain":["0x..."],"newChain":["0x...","0x..."]}]}
+*/
+func (chain *ChainApi) GetReorgHistory() []*ReorgRecord {
+	return chain.chainService.ReorgHistory()
+}
+
+/*
+ name: exportChain
+ usage: Export blocks of main chain to a snapshot file on the node, blocks are gzip compressed unless nocompress is set
+ params:
+	1. file path
+	2. from height
+	3. to height, the best height is used if it is higher
+	4. nocompress (optional)
+ return: true if succeed
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_exportChain","params":["/data/drep.snap",0,10000], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":true}
+*/
+func (chain *ChainApi) ExportChain(file string, from, to uint64, nocompress *bool) (bool, error) {
+	compress := nocompress == nil || !*nocompress
+	if err := chain.chainService.ExportChainFile(file, from, to, compress); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+/*
+ name: importChain
+ usage: Import blocks from a snapshot file on the node, trusted import skip header and signature verification but still execute blocks
+ params:
+	1. file path
+	2. trusted
+ return: the height range in snapshot and the number of new blocks
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_importChain","params":["/data/drep.snap",false], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"from":0,"to":10000,"imported":10000}}
+*/
+func (chain *ChainApi) ImportChain(file string, trusted bool) (*ImportResult, error) {
+	return chain.chainService.ImportChainFile(file, trusted)
+}
+
+/*
+ name: verifyDb
+ usage: Check the block index, the bodies and receipts of the best chain and the state trie of the tip are consistent, nothing is repaired
+ params:
+	1. 无
+ return: counts of the data checked and the problems found
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_verifyDb","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"height":10000,"blockNodes":10001,"canonicalSize":10001,"stateNodes":5321,"problemCount":1,"problems":[{"kind":"missing receipts","height":9998,"hash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","repaired":false}]}}
+*/
+func (chain *ChainApi) VerifyDb() (*DbCheckResult, error) {
+	return chain.chainService.VerifyDatabase(false)
+}
+
 type TrieQuery struct {
 	dbinterface.KeyValueStore
 	trie *trie.SecureTrie
@@ -572,6 +838,26 @@ func (trieQuery *TrieQuery) GetReputation(addr *crypto.CommonAddress) *big.Int {
 	return &storage.Reputation
 }
 
+func (trieQuery *TrieQuery) GetToken(symbol string) (*types.Token, error) {
+	value, err := trieQuery.Get(store.TokenKey(symbol))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, ErrTokenNotFound
+	}
+	token := &types.Token{}
+	if err := binary.Unmarshal(value, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (trieQuery *TrieQuery) GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	value, _ := trieQuery.Get(store.TokenBalanceKey(addr, symbol))
+	return new(big.Int).SetBytes(value)
+}
+
 func (trieQuery *TrieQuery) GetVoteCreditDetails(addr *crypto.CommonAddress) string {
 	key := sha3.Keccak256([]byte(store.StakeStorage + addr.Hex()))
 
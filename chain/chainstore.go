@@ -1,12 +1,13 @@
 package chain
 
 import (
-	"fmt"
+	"bytes"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/drep-project/binary"
+	"time"
 )
 
 var (
@@ -14,16 +15,26 @@ var (
 	ChainStatePrefix = []byte("chainState_")
 	BlockPrefix      = []byte("block_")
 	BlockNodePrefix  = []byte("blockNode_")
+	CanonicalPrefix  = []byte("canonical_")
+
+	blockJournalKey = append(ChainStatePrefix, []byte("journal")...)
 )
 
+// BlockJournal is the last best chain block whose state reached disk, it is
+// written in the same batch as the state
+type BlockJournal struct {
+	Height uint64
+	Hash   crypto.Hash
+	Time   int64
+}
+
 type ChainStore struct {
 	dbinterface.KeyValueStore
 }
 
 func (chainStore *ChainStore) PutReceipt(txHash crypto.Hash, receipt *types.Receipt) error {
-	key := sha3.Keccak256([]byte("receipt_" + txHash.String()))
-	value, err := binary.Marshal(receipt)
-	//fmt.Println("err11: ", err)
+	key := receiptKey(txHash)
+	value, err := types.EncodeReceipt(receipt)
 	if err != nil {
 		return err
 	}
@@ -31,16 +42,11 @@ func (chainStore *ChainStore) PutReceipt(txHash crypto.Hash, receipt *types.Rece
 }
 
 func (chainStore *ChainStore) GetReceipt(txHash crypto.Hash) *types.Receipt {
-	key := sha3.Keccak256([]byte("receipt_" + txHash.String()))
-	value, err := chainStore.Get(key)
-	//fmt.Println("err12: ", err)
-	//fmt.Println("val: ", value)
+	value, err := chainStore.Get(receiptKey(txHash))
 	if err != nil {
 		return nil
 	}
-	receipt := &types.Receipt{}
-	err = binary.Unmarshal(value, receipt)
-	fmt.Println("err13: ", err)
+	receipt, err := types.DecodeReceipt(value)
 	if err != nil {
 		return nil
 	}
@@ -48,22 +54,21 @@ func (chainStore *ChainStore) GetReceipt(txHash crypto.Hash) *types.Receipt {
 }
 
 func (chainStore *ChainStore) PutReceipts(blockHash crypto.Hash, receipts []*types.Receipt) error {
-	key := sha3.Keccak256([]byte("receipts_" + blockHash.String()))
-	value, err := binary.Marshal(receipts)
+	value, err := types.EncodeReceipts(receipts)
 	if err != nil {
 		return err
 	}
-	return chainStore.Put(key, value)
+	return chainStore.Put(receiptsKey(blockHash), value)
 }
 
 func (chainStore *ChainStore) GetReceipts(blockHash crypto.Hash) []*types.Receipt {
-	key := sha3.Keccak256([]byte("receipts_" + blockHash.String()))
-	value, err := chainStore.Get(key)
+	value, err := chainStore.Get(receiptsKey(blockHash))
 	if err != nil {
-		return make([]*types.Receipt, 0)
+		if value, err = chainStore.getAncient(ancientReceiptsTable, &blockHash); err != nil {
+			return make([]*types.Receipt, 0)
+		}
 	}
-	var receipts []*types.Receipt
-	err = binary.Unmarshal(value, &receipts)
+	receipts, err := types.DecodeReceipts(value)
 	if err != nil {
 		return make([]*types.Receipt, 0)
 	}
@@ -71,14 +76,21 @@ func (chainStore *ChainStore) GetReceipts(blockHash crypto.Hash) []*types.Receip
 }
 
 func (chainStore *ChainStore) DeleteReceipts(blockHash crypto.Hash) error {
-	key := sha3.Keccak256([]byte("receipts_" + blockHash.String()))
-	return chainStore.Delete(key)
+	return chainStore.Delete(receiptsKey(blockHash))
+}
+
+func receiptKey(txHash crypto.Hash) []byte {
+	return sha3.Keccak256([]byte("receipt_" + txHash.String()))
+}
+
+func receiptsKey(blockHash crypto.Hash) []byte {
+	return sha3.Keccak256([]byte("receipts_" + blockHash.String()))
 }
 
 func (chainStore *ChainStore) PutBlock(block *types.Block) error {
 	hash := block.Header.Hash()
 	key := append(BlockPrefix, hash[:]...)
-	value, err := binary.Marshal(block)
+	value, err := types.EncodeBlock(block)
 	if err != nil {
 		return err
 	}
@@ -86,14 +98,25 @@ func (chainStore *ChainStore) PutBlock(block *types.Block) error {
 	return chainStore.Put(key, value)
 }
 
-func (chainStore *ChainStore) GetBlockHeader(hash *crypto.Hash) (*types.BlockHeader, error) {
+// blockValue read the encoded block from the database, or from the freezer once frozen
+func (chainStore *ChainStore) blockValue(hash *crypto.Hash) ([]byte, error) {
 	key := append(BlockPrefix, hash[:]...)
 	value, err := chainStore.Get(key)
+	if err != nil {
+		if ancientValue, ancientErr := chainStore.getAncient(ancientBlockTable, hash); ancientErr == nil {
+			return ancientValue, nil
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+func (chainStore *ChainStore) GetBlockHeader(hash *crypto.Hash) (*types.BlockHeader, error) {
+	value, err := chainStore.blockValue(hash)
 	if err != nil {
 		return nil, err
 	}
-	block := &types.Block{}
-	err = binary.Unmarshal(value, block)
+	block, err := types.DecodeBlock(value)
 	if err != nil {
 		return nil, err
 	}
@@ -102,13 +125,11 @@ func (chainStore *ChainStore) GetBlockHeader(hash *crypto.Hash) (*types.BlockHea
 }
 
 func (chainStore *ChainStore) GetBlock(hash *crypto.Hash) (*types.Block, error) {
-	key := append(BlockPrefix, hash[:]...)
-	value, err := chainStore.Get(key)
+	value, err := chainStore.blockValue(hash)
 	if err != nil {
 		return nil, err
 	}
-	block := &types.Block{}
-	err = binary.Unmarshal(value, block)
+	block, err := types.DecodeBlock(value)
 	if err != nil {
 		return nil, err
 	}
@@ -118,13 +139,16 @@ func (chainStore *ChainStore) GetBlock(hash *crypto.Hash) (*types.Block, error)
 
 func (chainStore *ChainStore) HasBlock(hash *crypto.Hash) bool {
 	key := append(BlockPrefix, hash[:]...)
-	_, err := chainStore.Get(key)
+	if _, err := chainStore.Get(key); err == nil {
+		return true
+	}
+	_, err := chainStore.Get(ancientKey(hash))
 	return err == nil
 }
 
 func (chainStore *ChainStore) PutBlockNode(blockNode *types.BlockNode) error {
 	header := blockNode.Header()
-	value, err := binary.Marshal(header)
+	value, err := types.EncodeBlockHeader(&header)
 	if err != nil {
 		return err
 	}
@@ -147,8 +171,10 @@ func (chainStore *ChainStore) GetBlockNode(hash *crypto.Hash, height uint64) (*t
 	if err != nil {
 		return nil, 0, err
 	}
-	blockHeader := &types.BlockHeader{}
-	binary.Unmarshal(value[0:len(value)-1], blockHeader)
+	blockHeader, err := types.DecodeBlockHeader(value[0 : len(value)-1])
+	if err != nil {
+		return nil, 0, err
+	}
 	status := value[len(value)-1 : len(value)][0]
 	return blockHeader, types.BlockStatus(status), nil
 }
@@ -164,13 +190,24 @@ func (chainStore *ChainStore) BlockNodeCount() int64 {
 }
 
 func (chainStore *ChainStore) BlockNodeIterator(handle func(*types.BlockHeader, types.BlockStatus) error) error {
-	iter := chainStore.NewIteratorWithPrefix(BlockNodePrefix)
+	return chainStore.BlockNodeIteratorFrom(0, handle)
+}
+
+// BlockNodeIteratorFrom iterate the block nodes from height in order of height
+func (chainStore *ChainStore) BlockNodeIteratorFrom(height uint64, handle func(*types.BlockHeader, types.BlockStatus) error) error {
+	start := make([]byte, len(BlockNodePrefix)+8)
+	copy(start, BlockNodePrefix)
+	binary.BigEndian.PutUint64(start[len(BlockNodePrefix):], height)
+	iter := chainStore.NewIteratorWithStart(start)
 	defer iter.Release()
 	var err error
 	for iter.Next() {
+		if !bytes.HasPrefix(iter.Key(), BlockNodePrefix) {
+			break
+		}
 		val := iter.Value()
-		blockHeader := &types.BlockHeader{}
-		err = binary.Unmarshal(val[0:len(val)-1], blockHeader)
+		var blockHeader *types.BlockHeader
+		blockHeader, err = types.DecodeBlockHeader(val[0 : len(val)-1])
 		if err != nil {
 			break
 		}
@@ -185,6 +222,66 @@ func (chainStore *ChainStore) BlockNodeIterator(handle func(*types.BlockHeader,
 	return nil
 }
 
+// LoadBlockNode read a block node which is not in the memory index, its parent is left nil
+func (chainStore *ChainStore) LoadBlockNode(hash *crypto.Hash) (*types.BlockNode, error) {
+	header, err := chainStore.GetBlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+	header, status, err := chainStore.GetBlockNode(hash, header.Height)
+	if err != nil {
+		return nil, err
+	}
+	node := types.NewBlockNode(header, nil)
+	node.Status = status
+	return node, nil
+}
+
+// PutCanonicalHash record the hash of the best chain block at height
+func (chainStore *ChainStore) PutCanonicalHash(height uint64, hash *crypto.Hash) error {
+	return chainStore.Put(canonicalKey(height), hash[:])
+}
+
+// GetCanonicalHash return the hash of the best chain block at height
+func (chainStore *ChainStore) GetCanonicalHash(height uint64) (*crypto.Hash, error) {
+	value, err := chainStore.Get(canonicalKey(height))
+	if err != nil {
+		return nil, err
+	}
+	hash := crypto.Hash{}
+	hash.SetBytes(value)
+	return &hash, nil
+}
+
+func canonicalKey(height uint64) []byte {
+	key := make([]byte, len(CanonicalPrefix)+8)
+	copy(key, CanonicalPrefix)
+	binary.BigEndian.PutUint64(key[len(CanonicalPrefix):], height)
+	return key
+}
+
+// RecordBlockJournal record node as the last verified tip of the best chain
+func (chainStore *ChainStore) RecordBlockJournal(node *types.BlockNode) error {
+	value, err := binary.Marshal(&BlockJournal{Height: node.Height, Hash: *node.Hash, Time: time.Now().Unix()})
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(blockJournalKey, value)
+}
+
+// GetBlockJournal return the last recorded verified tip of the best chain
+func (chainStore *ChainStore) GetBlockJournal() (*BlockJournal, error) {
+	value, err := chainStore.Get(blockJournalKey)
+	if err != nil {
+		return nil, err
+	}
+	journal := &BlockJournal{}
+	if err := binary.Unmarshal(value, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
 func (chainStore *ChainStore) RollBack(height uint64, hash *crypto.Hash) (error, int64) {
 	var err error
 
@@ -38,9 +38,14 @@ func fastLog2Floor(n uint32) uint8 {
 //
 // The chain view for the branch ending in 6a consists of:
 //   genesis -> 1 -> 2 -> 3 -> 4a -> 5a -> 6a
+//
+// Only the nodes from base up to the tip are held, nodes below base or missing
+// from the held range are read through loadNode when it is set.
 type ChainView struct {
-	mtx   sync.Mutex
-	nodes []*types.BlockNode
+	mtx      sync.RWMutex
+	nodes    []*types.BlockNode // nodes[i] is the node at height base+i
+	base     uint64
+	loadNode func(height uint64) *types.BlockNode
 }
 
 // newChainView returns a new chain view for the given tip block node.  Passing
@@ -53,6 +58,14 @@ func NewChainView(tip *types.BlockNode) *ChainView {
 	return &c
 }
 
+// SetLoader sets the function reading the best chain node at a height which is
+// not held by the view.
+func (c *ChainView) SetLoader(loadNode func(height uint64) *types.BlockNode) {
+	c.mtx.Lock()
+	c.loadNode = loadNode
+	c.mtx.Unlock()
+}
+
 // genesis returns the genesis block for the chain view.  This only differs from
 // the exported version in that it is up to the caller to ensure the lock is
 // held.
@@ -63,16 +76,16 @@ func (c *ChainView) genesis() *types.BlockNode {
 		return nil
 	}
 
-	return c.nodes[0]
+	return c.nodeByHeight(0)
 }
 
 // Genesis returns the genesis block for the chain view.
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Genesis() *types.BlockNode {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	genesis := c.genesis()
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return genesis
 }
 
@@ -94,9 +107,9 @@ func (c *ChainView) tip() *types.BlockNode {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Tip() *types.BlockNode {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	tip := c.tip()
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return tip
 }
 
@@ -112,9 +125,20 @@ func (c *ChainView) setTip(node *types.BlockNode) {
 	if node == nil {
 		// Keep the backing array around for potential future use.
 		c.nodes = c.nodes[:0]
+		c.base = 0
 		return
 	}
 
+	// Start a new view at the oldest node linked to the tip, nodes evicted
+	// from the block index are loaded on demand.
+	if len(c.nodes) == 0 || node.Height < c.base {
+		c.nodes = c.nodes[:0]
+		c.base = node.Height
+		for n := node.Parent; n != nil; n = n.Parent {
+			c.base = n.Height
+		}
+	}
+
 	// Create or resize the slice that will hold the block nodes to the
 	// provided tip height.  When creating the slice, it is created with
 	// some additional capacity for the underlying array as append would do
@@ -123,7 +147,7 @@ func (c *ChainView) setTip(node *types.BlockNode) {
 	// contract the slice accordingly.  The additional capacity is chosen
 	// such that the array should only have to be extended about once a
 	// week.
-	needed := uint64(node.Height + 1)
+	needed := node.Height + 1 - c.base
 	if uint64(cap(c.nodes)) < needed {
 		nodes := make([]*types.BlockNode, needed, needed+approxNodesPerWeek)
 		copy(nodes, c.nodes)
@@ -136,8 +160,8 @@ func (c *ChainView) setTip(node *types.BlockNode) {
 		}
 	}
 
-	for node != nil && c.nodes[node.Height] != node {
-		c.nodes[node.Height] = node
+	for node != nil && node.Height >= c.base && c.nodes[node.Height-c.base] != node {
+		c.nodes[node.Height-c.base] = node
 		node = node.Parent
 	}
 }
@@ -155,6 +179,25 @@ func (c *ChainView) SetTip(node *types.BlockNode) {
 	c.mtx.Unlock()
 }
 
+// Compact drops the nodes below floor from the view, they are read through the
+// loader on demand afterwards.
+//
+// This function is safe for concurrent access.
+func (c *ChainView) Compact(floor uint64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.nodes) == 0 || floor <= c.base || floor > c.height() {
+		return
+	}
+
+	// Copy the held nodes so the dropped part of the array can be collected.
+	held := c.nodes[floor-c.base:]
+	nodes := make([]*types.BlockNode, len(held), len(held)+approxNodesPerWeek)
+	copy(nodes, held)
+	c.nodes = nodes
+	c.base = floor
+}
+
 // height returns the height of the tip of the chain view.  It will return -1 if
 // there is no tip (which only happens if the chain view has not been
 // initialized).  This only differs from the exported version in that it is up
@@ -162,7 +205,7 @@ func (c *ChainView) SetTip(node *types.BlockNode) {
 //
 // This function MUST be called with the view mutex locked (for reads).
 func (c *ChainView) height() uint64 {
-	return uint64(len(c.nodes) - 1)
+	return c.base + uint64(len(c.nodes)) - 1
 }
 
 // Height returns the height of the tip of the chain view.  It will return -1 if
@@ -171,9 +214,9 @@ func (c *ChainView) height() uint64 {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Height() uint64 {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	height := c.height()
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return height
 }
 
@@ -183,11 +226,17 @@ func (c *ChainView) Height() uint64 {
 //
 // This function MUST be called with the view mutex locked (for reads).
 func (c *ChainView) nodeByHeight(height uint64) *types.BlockNode {
-	if height < 0 || height >= uint64(len(c.nodes)) {
+	if len(c.nodes) == 0 || height > c.height() {
 		return nil
 	}
 
-	return c.nodes[height]
+	if height >= c.base && c.nodes[height-c.base] != nil {
+		return c.nodes[height-c.base]
+	}
+	if c.loadNode == nil {
+		return nil
+	}
+	return c.loadNode(height)
 }
 
 // NodeByHeight returns the block node at the specified height.  Nil will be
@@ -195,9 +244,9 @@ func (c *ChainView) nodeByHeight(height uint64) *types.BlockNode {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) NodeByHeight(height uint64) *types.BlockNode {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	node := c.nodeByHeight(height)
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return node
 }
 
@@ -206,11 +255,11 @@ func (c *ChainView) NodeByHeight(height uint64) *types.BlockNode {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Equals(other *ChainView) bool {
-	c.mtx.Lock()
-	other.mtx.Lock()
-	equals := len(c.nodes) == len(other.nodes) && c.tip() == other.tip()
-	other.mtx.Unlock()
-	c.mtx.Unlock()
+	c.mtx.RLock()
+	other.mtx.RLock()
+	equals := c.height() == other.height() && c.tip() == other.tip()
+	other.mtx.RUnlock()
+	c.mtx.RUnlock()
 	return equals
 }
 
@@ -220,7 +269,12 @@ func (c *ChainView) Equals(other *ChainView) bool {
 //
 // This function MUST be called with the view mutex locked (for reads).
 func (c *ChainView) contains(node *types.BlockNode) bool {
-	return c.nodeByHeight(node.Height) == node
+	viewNode := c.nodeByHeight(node.Height)
+	if viewNode == nil {
+		return false
+	}
+	// nodes loaded from the database are new copies
+	return viewNode == node || *viewNode.Hash == *node.Hash
 }
 
 // Contains returns whether or not the chain view contains the passed block
@@ -228,9 +282,9 @@ func (c *ChainView) contains(node *types.BlockNode) bool {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Contains(node *types.BlockNode) bool {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	contains := c.contains(node)
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return contains
 }
 
@@ -268,9 +322,9 @@ func (c *ChainView) next(node *types.BlockNode) *types.BlockNode {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) Next(node *types.BlockNode) *types.BlockNode {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	next := c.next(node)
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return next
 }
 
@@ -331,8 +385,8 @@ func (c *ChainView) findFork(node *types.BlockNode) *types.BlockNode {
 //
 // This function is safe for concurrent access.
 func (c *ChainView) FindFork(node *types.BlockNode) *types.BlockNode {
-	c.mtx.Lock()
+	c.mtx.RLock()
 	fork := c.findFork(node)
-	c.mtx.Unlock()
+	c.mtx.RUnlock()
 	return fork
 }
@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentChainReads read the best chain and the block index while blocks are imported,
+// the reads take no chain lock and must stay consistent. Run it with -race
+func TestConcurrentChainReads(t *testing.T) {
+	const blocks = 200
+	chainService, _ := newReorgService(t, 0, 1)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	reader := func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			tip := chainService.BestChain().Tip()
+			if node := chainService.BestChain().NodeByHeight(tip.Height); node == nil || node.Height != tip.Height {
+				t.Errorf("node at tip height %d not found", tip.Height)
+				return
+			}
+			if !chainService.BestChain().Contains(tip) || chainService.BestChain().FindFork(tip) != tip {
+				t.Errorf("tip %d not in best chain", tip.Height)
+				return
+			}
+			if chainService.blockIndex.LookupNode(tip.Hash) != tip {
+				t.Errorf("tip %d not in block index", tip.Height)
+				return
+			}
+			chainService.blockIndex.NodeStatus(tip)
+			if _, err := chainService.GetBlockHeaderByHash(tip.Hash); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go reader()
+	}
+
+	for i := 0; i < blocks; i++ {
+		if _, err := chainService.AcceptBlock(reorgBlock(chainService.BestChain().Tip(), 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if height := chainService.BestChain().Height(); height != blocks+1 {
+		t.Fatalf("best chain at %d, want %d", height, blocks+1)
+	}
+	if len(chainService.blockIndex.Dirty) != 0 {
+		t.Fatalf("%d block nodes not flushed", len(chainService.blockIndex.Dirty))
+	}
+}
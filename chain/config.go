@@ -6,8 +6,24 @@ import (
 )
 
 type ChainConfig struct {
-	RemotePort  int                  `json:"remoteport"`
-	RootChain   types.ChainIdType    `json:"rootChain,omitempty"`
-	ChainId     types.ChainIdType    `json:"chainID,omitempty"`
-	GenesisAddr crypto.CommonAddress `json:"genesisaddr"`
+	RemotePort    int                  `json:"remoteport"`
+	RootChain     types.ChainIdType    `json:"rootChain,omitempty"`
+	ChainId       types.ChainIdType    `json:"chainID,omitempty"`
+	GenesisAddr   crypto.CommonAddress `json:"genesisaddr"`
+	MaxReorgDepth uint64               `json:"maxReorgDepth"` // max blocks detached in one reorganize, 0 mean unlimited
+
+	MaxOrphanBlocks   int    `json:"maxOrphanBlocks"`   // capacity of orphan pool
+	MaxPeerOrphans    int    `json:"maxPeerOrphans"`    // orphans a single peer may hold in pool
+	OrphanExpiration  uint64 `json:"orphanExpiration"`  // orphan lifetime in seconds
+	MaxOrphanDistance uint64 `json:"maxOrphanDistance"` // max height of orphan above tip
+
+	IndexWindow      uint64 `json:"indexWindow"`      // blocks below tip kept in the memory block index, older ones are read from db
+	ResumeCheckDepth uint64 `json:"resumeCheckDepth"` // blocks below the block journal re-checked on restart
+
+	StateCommitInterval uint64 `json:"stateCommitInterval"` // blocks between two state writes to disk, 1 writes every block
+
+	NodeMode       string `json:"nodeMode"`       // archive (default), full or light, full and light prune historical state, see nodemode.go
+	StateRetention uint64 `json:"stateRetention"` // blocks below tip whose state a full node serves, 128 by default
+
+	AncientDepth uint64 `json:"ancientDepth"` // blocks below tip from which blocks and receipts move to flat files, 0 disables
 }
@@ -0,0 +1,263 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Database check
+//
+// VerifyDatabase walks the block index, the bodies and receipts of the best chain and the
+// state trie of the tip, and reports what is missing or does not match. The canonical height
+// index, the block nodes and the block receipt lists are derived from other data, a repair
+// rewrites them. Missing bodies, receipts and state nodes are not recoverable locally, the
+// node rolls back to the last block with complete data on restart and syncs it again.
+
+// Kinds of problems found by the database check
+const (
+	ProblemMissingBody      = "missing body"
+	ProblemCorruptBody      = "corrupt body"
+	ProblemMissingNode      = "missing block node"
+	ProblemDanglingParent   = "dangling parent"
+	ProblemStateRoot        = "mismatched state root"
+	ProblemTxRoot           = "mismatched tx root"
+	ProblemCanonical        = "wrong canonical hash"
+	ProblemMissingReceipts  = "missing receipts"
+	ProblemReceiptCount     = "mismatched receipt count"
+	ProblemMissingStateNode = "missing state node"
+	ProblemCorruptStateNode = "corrupt state node"
+)
+
+// maxDbProblems is the max count of problems listed in a check result, all are counted
+const maxDbProblems = 1000
+
+var (
+	RepairFlag = cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Rewrite the data which can be derived from other data when it is missing or wrong",
+	}
+
+	DbCheckCommand = cli.Command{
+		Name:  "check",
+		Usage: "Check the block index, block bodies, receipts and the state of the tip are consistent",
+		Flags: []cli.Flag{RepairFlag},
+	}
+
+	DbCommand = cli.Command{
+		Name:        "db",
+		Usage:       "Database maintenance commands",
+		Category:    "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{DbCheckCommand},
+	}
+)
+
+// DbProblem is an inconsistency found by the database check
+type DbProblem struct {
+	Kind     string      `json:"kind"`
+	Height   uint64      `json:"height"`
+	Hash     crypto.Hash `json:"hash"` // block hash, or node hash for state problems
+	Detail   string      `json:"detail,omitempty"`
+	Repaired bool        `json:"repaired"`
+}
+
+// DbCheckResult is the result of a database check
+type DbCheckResult struct {
+	Height        uint64       `json:"height"`        // height of the tip checked
+	BlockNodes    uint64       `json:"blockNodes"`    // block nodes in the block index
+	CanonicalSize uint64       `json:"canonicalSize"` // blocks of the best chain checked
+	StateNodes    uint64       `json:"stateNodes"`    // state trie nodes of the tip found
+	ProblemCount  int          `json:"problemCount"`
+	Problems      []*DbProblem `json:"problems"` // the first maxDbProblems problems
+}
+
+func (result *DbCheckResult) add(problem *DbProblem) {
+	result.ProblemCount++
+	if len(result.Problems) < maxDbProblems {
+		result.Problems = append(result.Problems, problem)
+	}
+}
+
+// VerifyDatabase check the chain data is consistent, the data derived from other data is
+// rewritten when repair is set
+func (chainService *ChainService) VerifyDatabase(repair bool) (*DbCheckResult, error) {
+	tip := chainService.BestChain().Tip()
+	result := &DbCheckResult{Height: tip.Height, Problems: []*DbProblem{}}
+	if err := chainService.checkBlockIndex(result); err != nil {
+		return nil, err
+	}
+	if err := chainService.checkBestChain(result, tip, repair); err != nil {
+		return nil, err
+	}
+
+	check := store.CheckTrie(chainService.DatabaseService.LevelDb(), tip.StateRoot)
+	result.StateNodes = check.Nodes
+	for _, hash := range check.Missing {
+		result.add(&DbProblem{Kind: ProblemMissingStateNode, Height: tip.Height, Hash: hash})
+	}
+	for _, hash := range check.Corrupt {
+		result.add(&DbProblem{Kind: ProblemCorruptStateNode, Height: tip.Height, Hash: hash})
+	}
+	return result, nil
+}
+
+// checkBlockIndex check every block node has its parent and, when its data is stored, its body
+func (chainService *ChainService) checkBlockIndex(result *DbCheckResult) error {
+	chainStore := chainService.chainStore
+	return chainStore.BlockNodeIterator(func(header *types.BlockHeader, status types.BlockStatus) error {
+		result.BlockNodes++
+		hash := header.Hash()
+		if status.HaveData() && !chainStore.HasBlock(hash) {
+			result.add(&DbProblem{Kind: ProblemMissingBody, Height: header.Height, Hash: *hash})
+		}
+		if header.Height > 0 {
+			if _, _, err := chainStore.GetBlockNode(&header.PreviousHash, header.Height-1); err != nil {
+				result.add(&DbProblem{Kind: ProblemDanglingParent, Height: header.Height, Hash: *hash, Detail: header.PreviousHash.String()})
+			}
+		}
+		return nil
+	})
+}
+
+// checkBestChain check the blocks of the best chain from tip down to the genesis block
+func (chainService *ChainService) checkBestChain(result *DbCheckResult, tip *types.BlockNode, repair bool) error {
+	chainStore := chainService.chainStore
+	hash := *tip.Hash
+	for height := tip.Height; ; height-- {
+		result.CanonicalSize++
+		nodeHeader, status, nodeErr := chainStore.GetBlockNode(&hash, height)
+		block, err := chainStore.GetBlock(&hash)
+		if err == nil && !block.Header.Hash().IsEqual(&hash) {
+			result.add(&DbProblem{Kind: ProblemCorruptBody, Height: height, Hash: hash})
+			block = nil
+		} else if err != nil {
+			result.add(&DbProblem{Kind: ProblemMissingBody, Height: height, Hash: hash})
+		}
+
+		if canonical, err := chainStore.GetCanonicalHash(height); err != nil || !canonical.IsEqual(&hash) {
+			problem := &DbProblem{Kind: ProblemCanonical, Height: height, Hash: hash}
+			if repair {
+				if err := chainStore.PutCanonicalHash(height, &hash); err != nil {
+					return err
+				}
+				problem.Repaired = true
+			}
+			result.add(problem)
+		}
+
+		if block != nil {
+			if nodeErr != nil || !bytes.Equal(nodeHeader.StateRoot, block.Header.StateRoot) {
+				kind := ProblemMissingNode
+				if nodeErr == nil {
+					kind = ProblemStateRoot
+				}
+				problem := &DbProblem{Kind: kind, Height: height, Hash: hash}
+				if repair {
+					// the body matches its hash, the node is rebuilt from it
+					node := types.NewBlockNode(block.Header, nil)
+					node.Status = status | types.StatusDataStored
+					if nodeErr != nil {
+						node.Status |= types.StatusValid
+					}
+					if err := chainStore.PutBlockNode(node); err != nil {
+						return err
+					}
+					problem.Repaired = true
+				}
+				result.add(problem)
+			}
+			if !bytes.Equal(chainService.DeriveMerkleRoot(block.Header.Height, blockTxs(block)), block.Header.TxRoot) {
+				result.add(&DbProblem{Kind: ProblemTxRoot, Height: height, Hash: hash})
+			}
+			if height > 0 {
+				if err := chainService.checkReceipts(result, block, repair); err != nil {
+					return err
+				}
+			}
+		} else if nodeErr != nil {
+			result.add(&DbProblem{Kind: ProblemMissingNode, Height: height, Hash: hash, Detail: "best chain walk stops here"})
+			return nil
+		}
+
+		if height == 0 {
+			return nil
+		}
+		if block != nil {
+			hash = block.Header.PreviousHash
+		} else {
+			hash = nodeHeader.PreviousHash
+		}
+	}
+}
+
+// checkReceipts check the receipt list of the block, it is rebuilt from the receipts of its
+// transactions when they are all stored
+func (chainService *ChainService) checkReceipts(result *DbCheckResult, block *types.Block, repair bool) error {
+	chainStore := chainService.chainStore
+	hash := *block.Header.Hash()
+	txs := blockTxs(block)
+	if exist, _ := chainStore.Has(receiptsKey(hash)); exist {
+		if receipts := chainStore.GetReceipts(hash); len(receipts) != len(txs) {
+			result.add(&DbProblem{
+				Kind:   ProblemReceiptCount,
+				Height: block.Header.Height,
+				Hash:   hash,
+				Detail: fmt.Sprintf("%d receipts for %d transactions", len(receipts), len(txs)),
+			})
+		}
+		return nil
+	}
+
+	problem := &DbProblem{Kind: ProblemMissingReceipts, Height: block.Header.Height, Hash: hash}
+	receipts := make([]*types.Receipt, 0, len(txs))
+	for _, tx := range txs {
+		receipt := chainStore.GetReceipt(*tx.TxHash())
+		if receipt == nil {
+			break
+		}
+		receipts = append(receipts, receipt)
+	}
+	if repair && len(receipts) == len(txs) {
+		if err := chainStore.PutReceipts(hash, receipts); err != nil {
+			return err
+		}
+		problem.Repaired = true
+	}
+	result.add(problem)
+	return nil
+}
+
+// blockTxs return the transactions of block, a block without data has none
+func blockTxs(block *types.Block) []*types.Transaction {
+	if block.Data == nil {
+		return nil
+	}
+	return block.Data.TxList
+}
+
+// runDbCheckCommand check the database after chain loaded, the node quits when it is done
+func (chainService *ChainService) runDbCheckCommand(executeContext *app.ExecuteContext) error {
+	result, err := chainService.VerifyDatabase(executeContext.Cli.Bool(RepairFlag.Name))
+	if err != nil {
+		return err
+	}
+	for _, problem := range result.Problems {
+		repaired := ""
+		if problem.Repaired {
+			repaired = " (repaired)"
+		}
+		fmt.Printf("%s at height %d: %s %s%s\n", problem.Kind, problem.Height, problem.Hash.String(), problem.Detail, repaired)
+	}
+	if result.ProblemCount > len(result.Problems) {
+		fmt.Printf("... %d more problems\n", result.ProblemCount-len(result.Problems))
+	}
+	fmt.Printf("checked %d block nodes, %d best chain blocks, %d state nodes at height %d: %d problems\n",
+		result.BlockNodes, result.CanonicalSize, result.StateNodes, result.Height, result.ProblemCount)
+	close(executeContext.Quit)
+	return nil
+}
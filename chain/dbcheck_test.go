@@ -0,0 +1,132 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// newDbCheckService return a chain service whose database holds a consistent best chain of
+// height blocks above genesis
+func newDbCheckService(t *testing.T, height int) (*ChainService, []*types.Block) {
+	databaseService := database.NewDatabaseService(memorydb.New())
+	batchStore := databaseService.LevelDb().(*database.BatchStore)
+	chainService := &ChainService{
+		Config:          &ChainConfig{},
+		DatabaseService: databaseService,
+		blockIndex:      NewBlockIndex(),
+		batchStore:      batchStore,
+		chainStore:      &ChainStore{batchStore},
+	}
+	genesis := &types.Block{Header: &types.BlockHeader{StateRoot: trie.EmptyRoot[:]}, Data: &types.BlockData{}}
+	node := types.NewBlockNode(genesis.Header, nil)
+	node.Status = types.StatusDataStored | types.StatusValid
+	if err := chainService.chainStore.PutBlock(genesis); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainService.chainStore.PutBlockNode(node); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainService.chainStore.PutCanonicalHash(0, node.Hash); err != nil {
+		t.Fatal(err)
+	}
+	chainService.blockIndex.AddNode(node)
+	chainService.bestChain = NewChainView(node)
+
+	blocks := []*types.Block{genesis}
+	for i := 0; i < height; i++ {
+		block := reorgBlock(chainService.BestChain().Tip(), 0)
+		if _, err := chainService.AcceptBlock(block); err != nil {
+			t.Fatal(err)
+		}
+		if err := chainService.chainStore.PutReceipts(*block.Header.Hash(), []*types.Receipt{}); err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, block)
+	}
+	return chainService, blocks
+}
+
+// problemKinds count the problems of each kind, the repaired ones apart
+func problemKinds(result *DbCheckResult) map[string]int {
+	kinds := make(map[string]int)
+	for _, problem := range result.Problems {
+		kind := problem.Kind
+		if problem.Repaired {
+			kind += " (repaired)"
+		}
+		kinds[kind]++
+	}
+	return kinds
+}
+
+func checkProblems(t *testing.T, chainService *ChainService, repair bool, want map[string]int) {
+	t.Helper()
+	result, err := chainService.VerifyDatabase(repair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := problemKinds(result)
+	if len(got) != len(want) || result.ProblemCount != len(result.Problems) {
+		t.Fatalf("repair %v: got problems %v, want %v", repair, got, want)
+	}
+	for kind, count := range want {
+		if got[kind] != count {
+			t.Fatalf("repair %v: got problems %v, want %v", repair, got, want)
+		}
+	}
+}
+
+// TestVerifyDatabase break the derived and the primary data of a chain and checks the check
+// reports both, repairs the derived data only and finds nothing else after the repair
+func TestVerifyDatabase(t *testing.T) {
+	chainService, blocks := newDbCheckService(t, 5)
+	result, err := chainService.VerifyDatabase(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Height != 5 || result.BlockNodes != 6 || result.CanonicalSize != 6 || result.ProblemCount != 0 {
+		t.Fatalf("unexpected result of a consistent chain %+v", result)
+	}
+
+	chainStore := chainService.chainStore
+	if err := chainStore.Delete(canonicalKey(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainStore.DeleteReceipts(*blocks[4].Header.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainStore.Delete(chainStore.blockIndexKey(blocks[4].Header.Hash(), 4)); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainStore.Delete(append(BlockPrefix, blocks[2].Header.Hash()[:]...)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the body of block 2 is missing from the block index and the best chain, the node of
+	// block 4 from the best chain and as the parent of block 5
+	broken := map[string]int{
+		ProblemCanonical:       1,
+		ProblemMissingReceipts: 1,
+		ProblemMissingNode:     1,
+		ProblemDanglingParent:  1,
+		ProblemMissingBody:     2,
+	}
+	checkProblems(t, chainService, false, broken)
+	// a check without repair writes nothing
+	checkProblems(t, chainService, false, broken)
+
+	checkProblems(t, chainService, true, map[string]int{
+		ProblemCanonical + " (repaired)":       1,
+		ProblemMissingReceipts + " (repaired)": 1,
+		ProblemMissingNode + " (repaired)":     1,
+		ProblemDanglingParent:                  1,
+		ProblemMissingBody:                     2,
+	})
+
+	// a missing body can not be rebuilt locally
+	checkProblems(t, chainService, false, map[string]int{ProblemMissingBody: 2})
+}
@@ -9,6 +9,7 @@ var (
 	ErrInvalidateBlockNumber     = errors.New("invalid block number")
 	ErrBlockNotFound             = errors.New("block not exist")
 	ErrTxIndexOutOfRange         = errors.New("tx index out of range")
+	ErrTxNotFound                = errors.New("transaction not found in main chain")
 	ErrReachGasLimit             = errors.New("gasRemained limit reached")
 	ErrInvalidateBlockMultisig   = errors.New("verify multisig error")
 	ErrUnsupportTxType           = errors.New("not support transaction type")
@@ -21,6 +22,7 @@ var (
 	ErrNotMathcedStateRoot       = errors.New("state root not matched")
 	ErrGasUsed                   = errors.New("gasRemained used not matched")
 	ErrChainId                   = errors.New("chainID not matched")
+	ErrTxChainId                 = errors.New("transaction chainID not matched")
 	ErrVersion                   = errors.New("version not matched")
 	ErrPreHash                   = errors.New("previous hash not matched")
 	ErrBlockExsist               = errors.New("already have block")
@@ -35,6 +37,29 @@ var (
 	ErrTooLongAlias              = errors.New("alias too long")
 	ErrUnsupportAliasChar        = errors.New("alias only support number and letter")
 	ErrReceiptRoot               = errors.New("receipt root not match")
+	ErrReorgTooDeep              = errors.New("reorganize depth exceed limit")
+	ErrOrphanTooFar              = errors.New("orphan block too far above tip")
+	ErrPeerOrphanQuota           = errors.New("peer orphan quota exceeded")
+	ErrSnapshotFormat            = errors.New("invalid snapshot file")
+	ErrSnapshotVersion           = errors.New("unsupported snapshot version")
+	ErrSnapshotGenesis           = errors.New("snapshot genesis not matched")
+	ErrSnapshotRange             = errors.New("invalid export range")
+	ErrSnapshotParent            = errors.New("parent of imported block not found")
+	ErrStatePruned               = errors.New("state pruned")
+	ErrUnknownNodeMode           = errors.New("unknown node mode")
+	ErrGenesisConfig             = errors.New("invalid genesis config")
+	ErrGenesisExist              = errors.New("data dir already initialized with another genesis")
+	ErrInvalidBlockRef           = errors.New("block must be a height or a block hash")
+	ErrTooManySlots              = errors.New("too many storage slots to prove")
+	ErrTokenExist                = errors.New("token already issued")
+	ErrTokenNotFound             = errors.New("token not found")
+	ErrNotTokenIssuer            = errors.New("only the issuer can mint token")
+	ErrTokenBalance              = errors.New("not enough token balance")
+	ErrTxTimeout                 = errors.New("transaction execution timeout")
+	ErrAncientDepth              = errors.New("ancient depth must exceed the max reorganize depth")
+	ErrAncientMissing            = errors.New("frozen blocks missing from the ancient store")
+	ErrBlockRange                = errors.New("invalid block range")
+	ErrSponsorNotActive          = errors.New("sponsored transaction before the sponsor fork")
 
 	ErrNoStorage   = errors.New("no account storage found")
 	ErrKeyNotFound = errors.New("key not found")
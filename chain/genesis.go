@@ -8,7 +8,6 @@ import (
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database/memorydb"
-	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -32,14 +31,15 @@ func (chainService *ChainService) GetGenisiBlock(biosAddress crypto.CommonAddres
 
 	root = db.GetStateRoot()
 
-	merkleRoot := chainService.DeriveMerkleRoot(nil)
+	merkleRoot := chainService.DeriveMerkleRoot(0, nil)
 	return &types.Block{
 		Header: &types.BlockHeader{
+			ChainId:      chainService.genesisParams.ChainId,
 			Version:      common.Version,
 			PreviousHash: crypto.Hash{},
-			GasLimit:     *new(big.Int).SetUint64(params.GenesisGasLimit),
+			GasLimit:     *new(big.Int).SetUint64(chainService.genesisParams.GasLimit),
 			GasUsed:      *new(big.Int),
-			Timestamp:    1545282765,
+			Timestamp:    chainService.genesisParams.Timestamp,
 			StateRoot:    root,
 			TxRoot:       merkleRoot,
 			Height:       0,
@@ -76,14 +76,15 @@ func (chainService *ChainService) ProcessGenesisBlock(biosAddr crypto.CommonAddr
 	if err != nil {
 		return nil, err
 	}
-	merkleRoot := chainService.DeriveMerkleRoot(nil)
+	merkleRoot := chainService.DeriveMerkleRoot(0, nil)
 	return &types.Block{
 		Header: &types.BlockHeader{
+			ChainId:      chainService.genesisParams.ChainId,
 			Version:      common.Version,
 			PreviousHash: crypto.Hash{},
-			GasLimit:     *new(big.Int).SetUint64(params.GenesisGasLimit),
+			GasLimit:     *new(big.Int).SetUint64(chainService.genesisParams.GasLimit),
 			GasUsed:      *new(big.Int),
-			Timestamp:    1545282765,
+			Timestamp:    chainService.genesisParams.Timestamp,
 			StateRoot:    root,
 			TxRoot:       merkleRoot,
 			Height:       0,
@@ -0,0 +1,205 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/common/fileutil"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	// GenesisFileName is the genesis file in data dir, it is used instead of the genesis section in config.json if exist
+	GenesisFileName = "genesis.json"
+
+	defaultGenesisTimestamp = 1545282765
+)
+
+var InitCommand = cli.Command{
+	Name:      "init",
+	Usage:     "Write genesis block of a custom network from genesis file and save the file to data dir",
+	ArgsUsage: "<genesis.json>",
+	Category:  "BLOCKCHAIN COMMANDS",
+}
+
+// GenesisParams is the chain parameters in genesis, the other fields such as Preminer and Miners
+// are handled by genesis processors. Fields omitted keep the values of main network.
+type GenesisParams struct {
+	ChainId   types.ChainIdType `json:"chainId,omitempty"`
+	GasLimit  uint64            `json:"gasLimit,omitempty"`
+	Timestamp uint64            `json:"timestamp,omitempty"`
+	Consensus string            `json:"consensus,omitempty"` //Consensus mode of the network, solo or bft
+
+	// Heights where consensus rules change, a fork omitted never activates. Existing networks
+	// schedule them above their head, new networks may start at 0
+	TxRootFork    *uint64 `json:"txRootFork,omitempty"`    //Tx root leaves hash the full tx encoding, signature included, from this height
+	CancelLogFork *uint64 `json:"cancelLogFork,omitempty"` //Cancel credit details in logs are binary encoded instead of json from this height
+	ChainIdFork   *uint64 `json:"chainIdFork,omitempty"`   //Blocks carrying transactions of another chain id are invalid from this height
+	SponsorFork   *uint64 `json:"sponsorFork,omitempty"`   //Transactions whose gas is paid by a co-signing fee payer are valid from this height
+
+	sections map[string]json.RawMessage //All sections of genesis, packages read their own with Section
+}
+
+// forkActive report whether the fork scheduled at fork is active at height
+func forkActive(fork *uint64, height uint64) bool {
+	return fork != nil && height >= *fork
+}
+
+// IsTxRootFork report whether tx root leaves hash the full tx encoding at height
+func (genesisParams *GenesisParams) IsTxRootFork(height uint64) bool {
+	return genesisParams != nil && forkActive(genesisParams.TxRootFork, height)
+}
+
+// IsCancelLogFork report whether cancel credit details in logs are binary encoded at height
+func (genesisParams *GenesisParams) IsCancelLogFork(height uint64) bool {
+	return genesisParams != nil && forkActive(genesisParams.CancelLogFork, height)
+}
+
+// IsChainIdFork report whether blocks at height may only carry transactions of the chain id
+func (genesisParams *GenesisParams) IsChainIdFork(height uint64) bool {
+	return genesisParams != nil && forkActive(genesisParams.ChainIdFork, height)
+}
+
+// IsSponsorFork report whether blocks at height may carry sponsored transactions
+func (genesisParams *GenesisParams) IsSponsorFork(height uint64) bool {
+	return genesisParams != nil && forkActive(genesisParams.SponsorFork, height)
+}
+
+// ForkHeights return the heights of the forks scheduled by their name in genesis
+func (genesisParams *GenesisParams) ForkHeights() map[string]uint64 {
+	heights := make(map[string]uint64)
+	if genesisParams == nil {
+		return heights
+	}
+	for name, fork := range map[string]*uint64{
+		"txRootFork":    genesisParams.TxRootFork,
+		"cancelLogFork": genesisParams.CancelLogFork,
+		"chainIdFork":   genesisParams.ChainIdFork,
+		"sponsorFork":   genesisParams.SponsorFork,
+	} {
+		if fork != nil {
+			heights[name] = *fork
+		}
+	}
+	return heights
+}
+
+// Forks return the heights of the forks scheduled, the p2p fork identifier is made of them
+func (genesisParams *GenesisParams) Forks() []uint64 {
+	var forks []uint64
+	for _, fork := range genesisParams.ForkHeights() {
+		forks = append(forks, fork)
+	}
+	return forks
+}
+
+// Section decode the section name of genesis into v, it report false if genesis has no such
+// section. Packages adding transaction kinds keep their chain rules there
+func (genesisParams *GenesisParams) Section(name string, v interface{}) (bool, error) {
+	if genesisParams == nil {
+		return false, nil
+	}
+	content, ok := genesisParams.sections[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return true, fmt.Errorf("%v, section %s: %v", ErrGenesisConfig, name, err)
+	}
+	return true, nil
+}
+
+func parseGenesisParams(content json.RawMessage) (*GenesisParams, error) {
+	genesisParams := &GenesisParams{}
+	if err := json.Unmarshal(content, genesisParams); err != nil {
+		return nil, fmt.Errorf("%v, %v", ErrGenesisConfig, err)
+	}
+	if err := json.Unmarshal(content, &genesisParams.sections); err != nil {
+		return nil, fmt.Errorf("%v, %v", ErrGenesisConfig, err)
+	}
+	if genesisParams.GasLimit == 0 {
+		genesisParams.GasLimit = params.GenesisGasLimit
+	}
+	if genesisParams.GasLimit < params.MinGasLimit || genesisParams.GasLimit > params.MaxGasLimit {
+		return nil, fmt.Errorf("%v, gas limit %d out of range [%d, %d]", ErrGenesisConfig, genesisParams.GasLimit, params.MinGasLimit, params.MaxGasLimit)
+	}
+	if genesisParams.Timestamp == 0 {
+		genesisParams.Timestamp = defaultGenesisTimestamp
+	}
+	switch genesisParams.Consensus {
+	case "", "solo", "bft":
+	default:
+		return nil, fmt.Errorf("%v, unknown consensus %s", ErrGenesisConfig, genesisParams.Consensus)
+	}
+	return genesisParams, nil
+}
+
+// loadGenesisConfig read genesis from the file given by init command, the genesis file in data dir
+// or the genesis section in config.json in turn
+func (chainService *ChainService) loadGenesisConfig(executeContext *app.ExecuteContext) error {
+	genesisFile := filepath.Join(executeContext.CommonConfig.HomeDir, GenesisFileName)
+	if executeContext.Cli != nil && executeContext.Cli.Command.Name == InitCommand.Name {
+		if len(executeContext.Cli.Args()) < 1 {
+			return fmt.Errorf("genesis file required")
+		}
+		content, err := readGenesisFile(executeContext.Cli.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		chainService.genesisConfig = content
+		chainService.initGenesisFile = genesisFile
+	} else if fileutil.IsFileExists(genesisFile) {
+		content, err := readGenesisFile(genesisFile)
+		if err != nil {
+			return err
+		}
+		chainService.genesisConfig = content
+	} else {
+		chainService.genesisConfig = executeContext.PhaseConfig["genesis"]
+	}
+	if chainService.genesisConfig == nil {
+		return fmt.Errorf("no genesis config, please run init with genesis file or check config.json")
+	}
+
+	genesisParams, err := parseGenesisParams(chainService.genesisConfig)
+	if err != nil {
+		return err
+	}
+	if genesisParams.ChainId != 0 && chainService.Config.ChainId != 0 && chainService.Config.ChainId != genesisParams.ChainId {
+		return fmt.Errorf("%v, config:%d genesis:%d", ErrGenesisConfig, chainService.Config.ChainId, genesisParams.ChainId)
+	}
+	chainService.genesisParams = genesisParams
+	chainService.chainID = genesisParams.ChainId
+	return nil
+}
+
+func readGenesisFile(file string) (json.RawMessage, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(content) {
+		return nil, fmt.Errorf("%v, invalid json in %s", ErrGenesisConfig, file)
+	}
+	return json.RawMessage(content), nil
+}
+
+// GenesisParams return chain parameters of genesis
+func (chainService *ChainService) GenesisParams() *GenesisParams {
+	return chainService.genesisParams
+}
+
+// finishInit save genesis file to data dir after genesis block written, so later start use it
+func (chainService *ChainService) finishInit(executeContext *app.ExecuteContext) error {
+	if err := ioutil.WriteFile(chainService.initGenesisFile, chainService.genesisConfig, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("write genesis block %s, chain id %d\n", chainService.genesisBlock.Header.Hash().String(), chainService.chainID)
+	close(executeContext.Quit)
+	return nil
+}
@@ -0,0 +1,137 @@
+package chain
+
+import (
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+// Import journal
+//
+// The writes of one block import go into one batch of the batch store, but the
+// batch is flushed to disk once the block is connected, before the tip is
+// advertised, and once more for every block attached by a reorg.  The writes
+// after the last flush, the block nodes of a reorg and the end of the import,
+// reach disk at the commit.  A node killed in between leaves a partly imported
+// block on disk.  Before an import starts an import record naming the block and
+// the block journal at that time is written on its own, it is deleted in the
+// last batch of the import, so a record found on startup marks an interrupted
+// import.  The block is then rolled back, the block journal is reset to the one
+// recorded and the block is imported again once all services started.
+//
+// A state commit falling inside the import, see statecommit.go, writes the state
+// of an ancestor and moves the block journal to it in the batch flushed with the
+// block.  The record is moved along in the same batch, so the journal it restores
+// names the last state written to disk whichever flush the node was killed after.
+
+var importJournalKey = append(ChainStatePrefix, []byte("importJournal")...)
+
+// ImportJournal is the record of a block import in flight
+type ImportJournal struct {
+	Height     uint64
+	Hash       crypto.Hash
+	PrevHeight uint64      // block journal when the import started
+	PrevHash   crypto.Hash // zero when there was no block journal
+	Time       int64
+}
+
+// PutImportJournal record the import of block starting while journal is the block journal
+func (chainStore *ChainStore) PutImportJournal(block *types.Block, journal *BlockJournal) error {
+	record := &ImportJournal{Height: block.Header.Height, Hash: *block.Header.Hash(), Time: time.Now().Unix()}
+	if journal != nil {
+		record.PrevHeight = journal.Height
+		record.PrevHash = journal.Hash
+	}
+	return chainStore.putImportJournal(record)
+}
+
+// advanceImportJournal move the block journal of the import in flight to node, whose state
+// is committed in the batch of the import. Without an import in flight it does nothing
+func (chainStore *ChainStore) advanceImportJournal(node *types.BlockNode) error {
+	record, err := chainStore.GetImportJournal()
+	if err != nil {
+		return nil
+	}
+	record.PrevHeight = node.Height
+	record.PrevHash = *node.Hash
+	return chainStore.putImportJournal(record)
+}
+
+func (chainStore *ChainStore) putImportJournal(record *ImportJournal) error {
+	value, err := binary.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(importJournalKey, value)
+}
+
+// GetImportJournal return the record of the import interrupted before it finished
+func (chainStore *ChainStore) GetImportJournal() (*ImportJournal, error) {
+	value, err := chainStore.Get(importJournalKey)
+	if err != nil {
+		return nil, err
+	}
+	record := &ImportJournal{}
+	if err := binary.Unmarshal(value, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// DeleteImportJournal delete the record of the import finished
+func (chainStore *ChainStore) DeleteImportJournal() error {
+	return chainStore.Delete(importJournalKey)
+}
+
+// beginImport record the import of block, it is written before the batch of the import begins
+func (chainService *ChainService) beginImport(block *types.Block) error {
+	journal, err := chainService.chainStore.GetBlockJournal()
+	if err != nil {
+		journal = nil
+	}
+	return chainService.chainStore.PutImportJournal(block, journal)
+}
+
+// recoverImport roll back the block of an interrupted import, it runs before the block index
+// is loaded. The body is kept in memory when it is intact and imported again on start
+func (chainService *ChainService) recoverImport() error {
+	record, err := chainService.chainStore.GetImportJournal()
+	if err != nil {
+		return nil
+	}
+	log.WithField("height", record.Height).WithField("hash", record.Hash).Warn("block import interrupted, roll back")
+
+	if block, err := chainService.chainStore.GetBlock(&record.Hash); err == nil && block.Header.Hash().IsEqual(&record.Hash) {
+		chainService.reimportBlocks = append(chainService.reimportBlocks, block)
+	}
+	if err := chainService.chainStore.DeleteReceipts(record.Hash); err != nil {
+		return err
+	}
+	if err, _ := chainService.chainStore.RollBack(record.Height, &record.Hash); err != nil {
+		return err
+	}
+
+	if record.PrevHash == (crypto.Hash{}) {
+		err = chainService.chainStore.Delete(blockJournalKey)
+	} else {
+		err = chainService.chainStore.RecordBlockJournal(&types.BlockNode{Height: record.PrevHeight, Hash: &record.PrevHash})
+	}
+	if err != nil {
+		return err
+	}
+	return chainService.chainStore.DeleteImportJournal()
+}
+
+// reimport import again the blocks rolled back by recoverImport, a block failing now is left
+// to the peers to send again
+func (chainService *ChainService) reimport() {
+	blocks := chainService.reimportBlocks
+	chainService.reimportBlocks = nil
+	for _, block := range blocks {
+		if _, _, err := chainService.ProcessBlock(block); err != nil {
+			log.WithField("height", block.Header.Height).WithField("Reason", err).Warn("import interrupted block again fail")
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// newJournalService return a chain service keeping states in layers over disk, its block
+// journal naming base
+func newJournalService(t *testing.T, disk *memorydb.Database, base *types.BlockNode) *ChainService {
+	batchStore := database.NewBatchStore(disk)
+	chainService := &ChainService{
+		batchStore: batchStore,
+		chainStore: &ChainStore{batchStore},
+		trieLayers: store.NewTrieLayers(*base.Hash),
+	}
+	if err := chainService.chainStore.RecordBlockJournal(base); err != nil {
+		t.Fatal(err)
+	}
+	return chainService
+}
+
+// restart return the chain service of a node killed with the writes not flushed lost
+func restart(disk *memorydb.Database) *ChainService {
+	batchStore := database.NewBatchStore(disk)
+	return &ChainService{batchStore: batchStore, chainStore: &ChainStore{batchStore}}
+}
+
+func journalBlock(height uint64) *types.Block {
+	return &types.Block{
+		Header: &types.BlockHeader{Height: height, GasLimit: *big.NewInt(0), GasUsed: *big.NewInt(0)},
+		Data:   &types.BlockData{},
+	}
+}
+
+// interruptImport start the import of block, write its body and node, commit the state of
+// committed if any and flush when flush is set, the node is then killed
+func interruptImport(t *testing.T, chainService *ChainService, block *types.Block, committed *types.BlockNode, flush bool) {
+	if err := chainService.beginImport(block); err != nil {
+		t.Fatal(err)
+	}
+	chainService.batchStore.Begin()
+	if err := chainService.chainStore.PutBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	if err := chainService.chainStore.PutBlockNode(types.NewBlockNode(block.Header, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if committed != nil {
+		if err := chainService.commitState(committed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if flush {
+		if err := chainService.batchStore.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func checkRecovered(t *testing.T, chainService *ChainService, block *types.Block, journal *types.BlockNode, reimport int) {
+	if err := chainService.recoverImport(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chainService.chainStore.GetImportJournal(); err == nil {
+		t.Error("import record left after recovery")
+	}
+	if chainService.chainStore.HasBlock(block.Header.Hash()) {
+		t.Error("interrupted block left on disk")
+	}
+	if _, _, err := chainService.chainStore.GetBlockNode(block.Header.Hash(), block.Header.Height); err == nil {
+		t.Error("node of interrupted block left on disk")
+	}
+	got, err := chainService.chainStore.GetBlockJournal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Height != journal.Height || got.Hash != *journal.Hash {
+		t.Errorf("block journal at %d %s, want %d %s", got.Height, got.Hash, journal.Height, journal.Hash)
+	}
+	if len(chainService.reimportBlocks) != reimport {
+		t.Errorf("%d blocks to import again, want %d", len(chainService.reimportBlocks), reimport)
+	}
+}
+
+// TestRecoverImport kill the node in the middle of an import, before and after the batch of
+// the import is flushed, and checks the block is rolled back on the journal of the state on disk
+func TestRecoverImport(t *testing.T) {
+	base := &types.BlockNode{Height: 0, Hash: &crypto.Hash{1}}
+	committed := &types.BlockNode{Height: 1, Hash: &crypto.Hash{2}}
+	block := journalBlock(DefaultStateCommitInterval + 1)
+
+	// killed before the flush, nothing of the import but the record reached disk
+	disk := memorydb.New()
+	interruptImport(t, newJournalService(t, disk, base), block, committed, false)
+	checkRecovered(t, restart(disk), block, base, 0)
+
+	// killed after the flush of a block without a state commit
+	disk = memorydb.New()
+	interruptImport(t, newJournalService(t, disk, base), block, nil, true)
+	checkRecovered(t, restart(disk), block, base, 1)
+
+	// killed after the flush of a block committing the state of an ancestor, the journal
+	// stays on the committed state
+	disk = memorydb.New()
+	interruptImport(t, newJournalService(t, disk, base), block, committed, true)
+	checkRecovered(t, restart(disk), block, committed, 1)
+}
@@ -0,0 +1,101 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	// DefaultIndexWindow is the default count of blocks below the tip kept in the memory block index
+	DefaultIndexWindow = 10000
+	// minIndexWindow keep enough nodes for median time and reorganize checks near the tip
+	minIndexWindow = 1024
+)
+
+// indexWindow return the count of blocks below the tip kept in memory, it is never
+// smaller than the reorganize limit so reorganizes are resolved in memory
+func (chainService *ChainService) indexWindow() uint64 {
+	window := chainService.Config.IndexWindow
+	if window == 0 {
+		window = DefaultIndexWindow
+	}
+	if window < minIndexWindow {
+		window = minIndexWindow
+	}
+	if maxDepth := chainService.maxReorgDepth(); window < maxDepth {
+		window = maxDepth
+	}
+	return window
+}
+
+// indexStartHeight return the height InitStates start loading block nodes from, the
+// whole index is loaded when no block journal was recorded yet
+func (chainService *ChainService) indexStartHeight(journal *BlockJournal) uint64 {
+	window := chainService.indexWindow()
+	if journal == nil || journal.Height <= window {
+		return 0
+	}
+	return journal.Height - window
+}
+
+// putCanonicalChain record node in the block journal and index the heights of its
+// ancestors, stopping at the first one already indexed
+func (chainService *ChainService) putCanonicalChain(node *types.BlockNode) error {
+	if err := chainService.indexCanonicalChain(node); err != nil {
+		return err
+	}
+	return chainService.chainStore.RecordBlockJournal(node)
+}
+
+// indexCanonicalChain index the heights of node and its ancestors, stopping at the first
+// one already indexed
+func (chainService *ChainService) indexCanonicalChain(node *types.BlockNode) error {
+	for n := node; n != nil; n = n.Parent {
+		hash, err := chainService.chainStore.GetCanonicalHash(n.Height)
+		if err == nil && hash.IsEqual(n.Hash) {
+			break
+		}
+		if err := chainService.chainStore.PutCanonicalHash(n.Height, n.Hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCanonicalNode read the best chain node at height from db
+func (chainService *ChainService) loadCanonicalNode(height uint64) *types.BlockNode {
+	hash, err := chainService.chainStore.GetCanonicalHash(height)
+	if err != nil {
+		return nil
+	}
+	return chainService.blockIndex.LookupNode(hash)
+}
+
+// compactIndex evict the block nodes far below the tip from memory once the tip moved
+// a tenth of the window, keeping startup time and memory bounded by the window
+func (chainService *ChainService) compactIndex() {
+	window := chainService.indexWindow()
+	tip := chainService.bestChain.Tip()
+	if tip == nil || tip.Height <= window {
+		return
+	}
+	floor := tip.Height - window
+	if floor < chainService.indexFloor+window/10 {
+		return
+	}
+
+	// the best chain nodes are collected first, the index lock is held while compacting
+	mainChain := make(map[*types.BlockNode]struct{}, window+1)
+	for height := floor; height <= tip.Height; height++ {
+		if node := chainService.bestChain.NodeByHeight(height); node != nil {
+			mainChain[node] = struct{}{}
+		}
+	}
+	chainService.flushIndexState()
+	evicted := chainService.blockIndex.Compact(floor, func(node *types.BlockNode) bool {
+		_, ok := mainChain[node]
+		return ok
+	})
+	chainService.bestChain.Compact(floor)
+	chainService.indexFloor = floor
+	log.WithField("floor", floor).WithField("evicted", evicted).Debug("compact block index")
+}
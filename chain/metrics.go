@@ -0,0 +1,12 @@
+package chain
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	blockProcessTimer  = metrics.NewRegisteredTimer("chain/block/process", nil)
+	headHeightGauge    = metrics.NewRegisteredGauge("chain/head/height", nil)
+	reorgMeter         = metrics.NewRegisteredMeter("chain/reorg/executes", nil)
+	reorgDepthHist     = metrics.NewRegisteredHistogram("chain/reorg/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
+	staleBlockMeter    = metrics.NewRegisteredMeter("chain/block/stale", nil)
+	ancientBlocksGauge = metrics.NewRegisteredGauge("chain/ancient/blocks", nil)
+)
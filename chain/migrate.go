@@ -0,0 +1,159 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	encodingKey = append(ChainStatePrefix, []byte("encoding")...)
+
+	MigrateCommand = cli.Command{
+		Name:     "migratedb",
+		Usage:    "Rewrite blocks and receipts stored in the legacy binary or an older canonical encoding in the current one",
+		Category: "BLOCKCHAIN COMMANDS",
+	}
+)
+
+// EncodingVersion return the canonical encoding version all blocks and receipts were migrated
+// to, 0 if the store may still hold data in the binary encoding. Blocks and receipts written
+// before a migration to types.CurrentEncoding may be in an older one
+func (chainStore *ChainStore) EncodingVersion() byte {
+	value, err := chainStore.Get(encodingKey)
+	if err != nil || len(value) != 1 {
+		return 0
+	}
+	return value[0]
+}
+
+// MigrateEncoding rewrite the blocks and receipts not in types.CurrentEncoding, which compresses
+// them, and the block nodes still in the binary encoding. Reads accept every encoding, so a node
+// can run during a partial migration and an interrupted migration continues where it stopped.
+// Return the number of blocks rewritten
+func (chainStore *ChainStore) MigrateEncoding() (uint64, error) {
+	if chainStore.EncodingVersion() == types.CurrentEncoding {
+		return 0, nil
+	}
+	batch := chainStore.NewBatch()
+	flush := func(force bool) error {
+		if !force && batch.ValueSize() < dbinterface.IdealBatchSize {
+			return nil
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+
+	migrated := uint64(0)
+	iter := chainStore.NewIteratorWithPrefix(BlockPrefix)
+	for iter.Next() {
+		if types.EncodingOf(iter.Value()) == types.CurrentEncoding {
+			continue
+		}
+		block, err := types.DecodeBlock(iter.Value())
+		if err != nil {
+			iter.Release()
+			return migrated, fmt.Errorf("block %x: %v", iter.Key()[len(BlockPrefix):], err)
+		}
+		if err := chainStore.migrateBlock(batch, iter.Key(), block); err != nil {
+			iter.Release()
+			return migrated, err
+		}
+		if err := flush(false); err != nil {
+			iter.Release()
+			return migrated, err
+		}
+		migrated++
+		if migrated%10000 == 0 {
+			log.WithField("blocks", migrated).Info("Migrating block encoding")
+		}
+	}
+	iter.Release()
+
+	iter = chainStore.NewIteratorWithPrefix(BlockNodePrefix)
+	for iter.Next() {
+		value := iter.Value()
+		if len(value) == 0 || types.IsCanonicalEncoding(value) {
+			continue
+		}
+		header, err := types.DecodeBlockHeader(value[:len(value)-1])
+		if err != nil {
+			iter.Release()
+			return migrated, fmt.Errorf("block node %x: %v", iter.Key()[len(BlockNodePrefix):], err)
+		}
+		encoded, err := types.EncodeBlockHeader(header)
+		if err != nil {
+			iter.Release()
+			return migrated, err
+		}
+		batch.Put(iter.Key(), append(encoded, value[len(value)-1]))
+		if err := flush(false); err != nil {
+			iter.Release()
+			return migrated, err
+		}
+	}
+	iter.Release()
+
+	batch.Put(encodingKey, []byte{types.CurrentEncoding})
+	return migrated, flush(true)
+}
+
+// migrateBlock add the canonical encoding of the block and its receipts to batch
+func (chainStore *ChainStore) migrateBlock(batch dbinterface.Batch, key []byte, block *types.Block) error {
+	encoded, err := types.EncodeBlock(block)
+	if err != nil {
+		return err
+	}
+	batch.Put(common.CopyBytes(key), encoded)
+
+	hash := crypto.Hash{}
+	hash.SetBytes(key[len(BlockPrefix):])
+	if value, err := chainStore.Get(receiptsKey(hash)); err == nil && types.EncodingOf(value) != types.CurrentEncoding {
+		receipts, err := types.DecodeReceipts(value)
+		if err != nil {
+			return fmt.Errorf("receipts of block %s: %v", hash.String(), err)
+		}
+		if encoded, err = types.EncodeReceipts(receipts); err != nil {
+			return err
+		}
+		batch.Put(receiptsKey(hash), encoded)
+	}
+	if block.Data == nil {
+		return nil
+	}
+	for _, tx := range block.Data.TxList {
+		txHash := *tx.TxHash()
+		value, err := chainStore.Get(receiptKey(txHash))
+		if err != nil || types.EncodingOf(value) == types.CurrentEncoding {
+			continue
+		}
+		receipt, err := types.DecodeReceipt(value)
+		if err != nil {
+			return fmt.Errorf("receipt of tx %s: %v", txHash.String(), err)
+		}
+		if encoded, err = types.EncodeReceipt(receipt); err != nil {
+			return err
+		}
+		batch.Put(receiptKey(txHash), encoded)
+	}
+	return nil
+}
+
+// runMigrateCommand migrate the chain store after chain loaded, the node quits when it is done
+func (chainService *ChainService) runMigrateCommand(executeContext *app.ExecuteContext) error {
+	migrated, err := chainService.chainStore.MigrateEncoding()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("migrate %d blocks to encoding version %d\n", migrated, types.CurrentEncoding)
+	close(executeContext.Quit)
+	return nil
+}
@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+// Node modes, they decide which historical states the node keeps and serves
+//
+//	archive  the state of every block is written to disk and served for any height. A node
+//	         switched to archive keeps only the states written before as a full node
+//	full     the states of recent blocks are kept in memory and one every StateCommitInterval
+//	         blocks reaches disk, see statecommit.go. The states of the last StateRetention
+//	         blocks are served when they are still found
+//	light    only the state of the tip is served
+//
+// A state not served is reported with ErrStatePruned. Archive is the default so historical
+// state queries keep working, pruning only happens when full or light is configured.
+const (
+	NodeModeArchive = "archive"
+	NodeModeFull    = "full"
+	NodeModeLight   = "light"
+)
+
+// DefaultNodeMode is the node mode used when none is configured
+const DefaultNodeMode = NodeModeArchive
+
+// DefaultStateRetention is the default count of blocks below the tip whose state a full node serves
+const DefaultStateRetention = 128
+
+func (chainService *ChainService) nodeMode() string {
+	if chainService.Config.NodeMode != "" {
+		return chainService.Config.NodeMode
+	}
+	return DefaultNodeMode
+}
+
+func (chainService *ChainService) stateRetention() uint64 {
+	if chainService.Config.StateRetention > 0 {
+		return chainService.Config.StateRetention
+	}
+	return DefaultStateRetention
+}
+
+// checkNodeMode check the configured node mode is known
+func (chainService *ChainService) checkNodeMode() error {
+	switch chainService.nodeMode() {
+	case NodeModeArchive, NodeModeFull, NodeModeLight:
+		return nil
+	}
+	return errors.Wrapf(ErrUnknownNodeMode, "%s", chainService.Config.NodeMode)
+}
+
+// stateRetained report whether the node mode serves the state of the main chain block node
+func (chainService *ChainService) stateRetained(node *types.BlockNode) bool {
+	tip := chainService.BestChain().Tip()
+	switch chainService.nodeMode() {
+	case NodeModeArchive:
+		return true
+	case NodeModeLight:
+		return node.Height >= tip.Height
+	default:
+		return node.Height+chainService.stateRetention() > tip.Height
+	}
+}
@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+// newModeApi return the api of a node in mode whose best chain is height blocks high, every
+// block having the empty state
+func newModeApi(mode string, retention uint64, height uint64) *ChainApi {
+	var tip *types.BlockNode
+	for i := uint64(0); i <= height; i++ {
+		tip = types.NewBlockNode(&types.BlockHeader{Height: i, StateRoot: trie.EmptyRoot[:]}, tip)
+	}
+	chainService := &ChainService{
+		Config:    &ChainConfig{NodeMode: mode, StateRetention: retention},
+		bestChain: NewChainView(tip),
+	}
+	return NewChainApi(chainService, memorydb.New(), chainService.bestChain, nil)
+}
+
+// checkStateAt checks the state at height is served when served is set and reported pruned otherwise
+func checkStateAt(t *testing.T, api *ChainApi, height uint64, served bool) {
+	_, _, err := api.stateAt(&BlockRef{Height: &height})
+	if served && err != nil {
+		t.Errorf("%s node: state at %d: %v", api.chainService.nodeMode(), height, err)
+	}
+	if !served && errors.Cause(err) != ErrStatePruned {
+		t.Errorf("%s node: state at %d: got %v, want %v", api.chainService.nodeMode(), height, err, ErrStatePruned)
+	}
+}
+
+func TestNodeModeFull(t *testing.T) {
+	api := newModeApi(NodeModeFull, 10, 100)
+	checkStateAt(t, api, 100, true)
+	checkStateAt(t, api, 91, true)
+	checkStateAt(t, api, 90, false)
+	checkStateAt(t, api, 0, false)
+
+	// the default retention applies when none is configured
+	api = newModeApi(NodeModeFull, 0, DefaultStateRetention*2)
+	checkStateAt(t, api, DefaultStateRetention+1, true)
+	checkStateAt(t, api, DefaultStateRetention, false)
+}
+
+func TestNodeModeLight(t *testing.T) {
+	api := newModeApi(NodeModeLight, 10, 100)
+	checkStateAt(t, api, 100, true)
+	checkStateAt(t, api, 99, false)
+	checkStateAt(t, api, 0, false)
+}
+
+func TestNodeModeArchive(t *testing.T) {
+	for _, mode := range []string{NodeModeArchive, ""} {
+		api := newModeApi(mode, 10, 100)
+		for height := uint64(0); height <= 100; height++ {
+			checkStateAt(t, api, height, true)
+		}
+	}
+}
+
+func TestCheckNodeMode(t *testing.T) {
+	for _, mode := range []string{"", NodeModeArchive, NodeModeFull, NodeModeLight} {
+		chainService := &ChainService{Config: &ChainConfig{NodeMode: mode}}
+		if err := chainService.checkNodeMode(); err != nil {
+			t.Errorf("mode %q rejected: %v", mode, err)
+		}
+	}
+	chainService := &ChainService{Config: &ChainConfig{NodeMode: "pruned"}}
+	if err := chainService.checkNodeMode(); errors.Cause(err) != ErrUnknownNodeMode {
+		t.Errorf("unknown mode: got %v, want %v", err, ErrUnknownNodeMode)
+	}
+}
@@ -1,13 +1,26 @@
 package chain
 
 import (
+	"time"
+
+	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/crypto"
 	types "github.com/drep-project/DREP-Chain/types"
-	"time"
+	"github.com/drep-project/binary"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 const (
-	maxOrphanBlocks = 40960
+	// DefaultMaxOrphanBlocks is the default capacity of the orphan pool
+	DefaultMaxOrphanBlocks = 4096
+	// DefaultMaxPeerOrphans is the default count of orphans a single peer may hold in the pool
+	DefaultMaxPeerOrphans = 256
+	// DefaultOrphanExpiration is the default lifetime of an orphan in seconds
+	DefaultOrphanExpiration = 3600
+	// DefaultMaxOrphanDistance is the default max height of an orphan above the current tip
+	DefaultMaxOrphanDistance = 1024
+
+	orphanExpireInterval = time.Minute
 )
 
 // IsKnownOrphan returns whether the passed hash is currently a known orphan.
@@ -63,9 +76,46 @@ func (b *ChainService) removeOrphanBlock(orphan *types.OrphanBlock) {
 	b.orphanLock.Lock()
 	defer b.orphanLock.Unlock()
 
+	b.removeOrphanBlockLocked(orphan)
+}
+
+// takeOrphans removes the orphans whose parent is the passed hash from the
+// orphan pool and returns them.
+//
+// This function is safe for concurrent access.
+func (b *ChainService) takeOrphans(prevHash *crypto.Hash) []*types.OrphanBlock {
+	b.orphanLock.Lock()
+	defer b.orphanLock.Unlock()
+
+	orphans := append([]*types.OrphanBlock(nil), b.prevOrphans[*prevHash]...)
+	for _, orphan := range orphans {
+		if orphan != nil {
+			b.removeOrphanBlockLocked(orphan)
+		}
+	}
+	return orphans
+}
+
+// removeOrphanBlockLocked is removeOrphanBlock without locking, the caller must
+// hold the orphan lock.
+func (b *ChainService) removeOrphanBlockLocked(orphan *types.OrphanBlock) {
 	// Remove the orphan block from the orphan pool.
 	orphanHash := orphan.Block.Header.Hash()
+	if _, exists := b.orphans[*orphanHash]; !exists {
+		return
+	}
 	delete(b.orphans, *orphanHash)
+	b.orphanBytes -= orphan.Size
+	if elem, ok := b.orphanElems[*orphanHash]; ok {
+		b.orphanLRU.Remove(elem)
+		delete(b.orphanElems, *orphanHash)
+	}
+	if orphan.Peer != "" {
+		b.orphanPeers[orphan.Peer]--
+		if b.orphanPeers[orphan.Peer] <= 0 {
+			delete(b.orphanPeers, orphan.Peer)
+		}
+	}
 
 	// Remove the reference from the previous orphan index too.  An indexing
 	// for loop is intentionally used over a range here as range does not
@@ -89,52 +139,150 @@ func (b *ChainService) removeOrphanBlock(orphan *types.OrphanBlock) {
 	if len(b.prevOrphans[prevHash]) == 0 {
 		delete(b.prevOrphans, prevHash)
 	}
+	metrics.GetOrRegisterGauge("chain/orphans/count", nil).Update(int64(len(b.orphans)))
 }
 
-// addOrphanBlock adds the passed block (which is already determined to be
-// an orphan prior calling this function) to the orphan pool.  It lazily cleans
-// up any expired blocks so a separate cleanup poller doesn't need to be run.
-// It also imposes a maximum limit on the number of outstanding orphan
-// blocks and will remove the oldest received orphan block if the limit is
-// exceeded.
-func (b *ChainService) addOrphanBlock(block *types.Block) {
-	// Remove expired orphan blocks.
+// touchOrphan mark the orphan as recently used so it is evicted later.
+func (b *ChainService) touchOrphan(hash *crypto.Hash) {
+	b.orphanLock.Lock()
+	defer b.orphanLock.Unlock()
+
+	if elem, ok := b.orphanElems[*hash]; ok {
+		b.orphanLRU.MoveToFront(elem)
+	}
+}
+
+// checkOrphan reject orphans too far above the current tip or from a peer
+// which already exhaust its quota.
+func (b *ChainService) checkOrphan(block *types.Block, peer string) error {
+	tipHeight := b.BestChain().Height()
+	if block.Header.Height > tipHeight+b.maxOrphanDistance() {
+		metrics.GetOrRegisterMeter("chain/orphans/rejected", nil).Mark(1)
+		return ErrOrphanTooFar
+	}
+
+	b.orphanLock.RLock()
+	count := b.orphanPeers[peer]
+	b.orphanLock.RUnlock()
+	if peer != "" && count >= b.maxPeerOrphans() {
+		metrics.GetOrRegisterMeter("chain/orphans/rejected", nil).Mark(1)
+		return ErrPeerOrphanQuota
+	}
+	return nil
+}
+
+// expireOrphans removes all orphans whose expiration has passed.
+func (b *ChainService) expireOrphans() {
+	b.orphanLock.Lock()
+	defer b.orphanLock.Unlock()
+
+	now := time.Now()
+	expired := 0
 	for _, oBlock := range b.orphans {
-		if time.Now().After(oBlock.Expiration) {
-			b.removeOrphanBlock(oBlock)
-			continue
+		if now.After(oBlock.Expiration) {
+			b.removeOrphanBlockLocked(oBlock)
+			expired++
 		}
+	}
+	if expired > 0 {
+		log.WithField("count", expired).Debug("remove expired orphan blocks")
+		metrics.GetOrRegisterMeter("chain/orphans/expired", nil).Mark(int64(expired))
+	}
+}
 
-		// Update the oldest orphan block pointer so it can be discarded
-		// in case the orphan pool fills up.
-		if b.oldestOrphan == nil || oBlock.Expiration.Before(b.oldestOrphan.Expiration) {
-			b.oldestOrphan = oBlock
+// expireOrphansLoop periodically drop expired orphans until the service stop.
+func (b *ChainService) expireOrphansLoop() {
+	ticker := time.NewTicker(orphanExpireInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.expireOrphans()
+		case <-b.quit:
+			return
 		}
 	}
+}
 
-	// Limit orphan blocks to prevent memory exhaustion.
-	if len(b.orphans)+1 > maxOrphanBlocks {
-		// Remove the oldest orphan to make room for the new one.
-		b.removeOrphanBlock(b.oldestOrphan)
-		b.oldestOrphan = nil
-	}
+// addOrphanBlock adds the passed block (which is already determined to be
+// an orphan prior calling this function) to the orphan pool.  It lazily cleans
+// up any expired blocks besides the periodical cleanup. It also imposes a
+// maximum limit on the number of outstanding orphan blocks and will remove
+// the least recently used orphan block if the limit is exceeded.
+func (b *ChainService) addOrphanBlock(block *types.Block, peer string) {
+	// Remove expired orphan blocks.
+	b.expireOrphans()
 
-	// Protect concurrent access.  This is intentionally done here instead
-	// of near the top since removeOrphanBlock does its own locking and
-	// the range iterator is not invalidated by removing map entries.
+	// Protect concurrent access.
 	b.orphanLock.Lock()
 	defer b.orphanLock.Unlock()
 
-	// Insert the block into the orphan map with an expiration time
-	// 1 hour from now.
-	expiration := time.Now().Add(time.Hour)
+	size := 0
+	if content, err := binary.Marshal(block); err == nil {
+		size = len(content)
+	}
+
+	// Limit orphan blocks to prevent memory exhaustion, both count and bytes are bounded.
+	budget := int(app.CacheAllowance(app.CacheOrphans))
+	for (len(b.orphans)+1 > b.maxOrphanBlocks() || b.orphanBytes+size > budget) && b.orphanLRU.Len() > 0 {
+		// Remove the least recently used orphan to make room for the new one.
+		b.removeOrphanBlockLocked(b.orphanLRU.Back().Value.(*types.OrphanBlock))
+		metrics.GetOrRegisterMeter("chain/orphans/evicted", nil).Mark(1)
+	}
+
+	// Insert the block into the orphan map with an expiration time.
+	expiration := time.Now().Add(b.orphanExpiration())
 	oBlock := &types.OrphanBlock{
 		Block:      block,
 		Expiration: expiration,
+		Peer:       peer,
+		Size:       size,
+	}
+	hash := *block.Header.Hash()
+	b.orphans[hash] = oBlock
+	b.orphanBytes += size
+	b.orphanElems[hash] = b.orphanLRU.PushFront(oBlock)
+	if peer != "" {
+		b.orphanPeers[peer]++
 	}
-	b.orphans[*block.Header.Hash()] = oBlock
 
 	// Add to previous hash lookup index for faster dependency lookups.
 	prevHash := block.Header.PreviousHash
 	b.prevOrphans[prevHash] = append(b.prevOrphans[prevHash], oBlock)
+	metrics.GetOrRegisterGauge("chain/orphans/count", nil).Update(int64(len(b.orphans)))
+}
+
+// orphanUsage report the bytes of blocks held by orphan pool
+func (b *ChainService) orphanUsage() int64 {
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+	return int64(b.orphanBytes)
+}
+
+func (b *ChainService) maxOrphanBlocks() int {
+	if b.Config.MaxOrphanBlocks > 0 {
+		return b.Config.MaxOrphanBlocks
+	}
+	return DefaultMaxOrphanBlocks
+}
+
+func (b *ChainService) maxPeerOrphans() int {
+	if b.Config.MaxPeerOrphans > 0 {
+		return b.Config.MaxPeerOrphans
+	}
+	return DefaultMaxPeerOrphans
+}
+
+func (b *ChainService) orphanExpiration() time.Duration {
+	if b.Config.OrphanExpiration > 0 {
+		return time.Duration(b.Config.OrphanExpiration) * time.Second
+	}
+	return DefaultOrphanExpiration * time.Second
+}
+
+func (b *ChainService) maxOrphanDistance() uint64 {
+	if b.Config.MaxOrphanDistance > 0 {
+		return b.Config.MaxOrphanDistance
+	}
+	return DefaultMaxOrphanDistance
 }
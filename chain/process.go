@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"math/big"
+	"time"
 
+	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/types"
@@ -15,17 +17,25 @@ import (
 )
 
 func (chainService *ChainService) ProcessBlock(block *types.Block) (bool, bool, error) {
-	chainService.addBlockSync.Lock()
-	defer chainService.addBlockSync.Unlock()
+	return chainService.ProcessPeerBlock(block, "")
+}
+
+// ProcessPeerBlock process a block received from peer, the orphans of each peer are limited by quota
+func (chainService *ChainService) ProcessPeerBlock(block *types.Block, peer string) (bool, bool, error) {
+	// Reject known blocks without waiting for the block being processed, the
+	// checks are repeated once the lock is held.
 	blockHash := block.Header.Hash()
-	exist := chainService.BlockExists(blockHash)
-	if exist {
-		return false, false, ErrBlockExsist
+	if err := chainService.checkKnownBlock(blockHash); err != nil {
+		return false, false, err
 	}
 
-	// The block must not already exist as an orphan.
-	if _, exists := chainService.orphans[*blockHash]; exists {
-		return false, false, ErrOrphanBlockExsist
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+	if app.InMaintenance() {
+		return false, false, app.ErrMaintenance
+	}
+	if err := chainService.checkKnownBlock(blockHash); err != nil {
+		return false, false, err
 	}
 
 	// Handle orphan blocks.
@@ -33,7 +43,10 @@ func (chainService *ChainService) ProcessBlock(block *types.Block) (bool, bool,
 	prevHash := block.Header.PreviousHash
 	prevHashExists := chainService.BlockExists(&prevHash)
 	if !prevHashExists && prevHash != zeroHash {
-		chainService.addOrphanBlock(block)
+		if err := chainService.checkOrphan(block, peer); err != nil {
+			return false, false, err
+		}
+		chainService.addOrphanBlock(block, peer)
 		return false, true, nil
 	}
 	isMainChain, err := chainService.acceptBlock(block)
@@ -51,6 +64,20 @@ func (chainService *ChainService) ProcessBlock(block *types.Block) (bool, bool,
 	return isMainChain, false, nil
 }
 
+// checkKnownBlock fails if the block is already in the chain or in the orphan pool
+func (chainService *ChainService) checkKnownBlock(blockHash *crypto.Hash) error {
+	if chainService.BlockExists(blockHash) {
+		return ErrBlockExsist
+	}
+
+	// The block must not already exist as an orphan.
+	if chainService.IsKnownOrphan(blockHash) {
+		chainService.touchOrphan(blockHash)
+		return ErrOrphanBlockExsist
+	}
+	return nil
+}
+
 func (chainService *ChainService) processOrphans(hash *crypto.Hash) error {
 	// Start with processing at least the passed hash.  Leave a little room
 	// for additional orphan blocks that need to be processed without
@@ -63,17 +90,13 @@ func (chainService *ChainService) processOrphans(hash *crypto.Hash) error {
 		processHashes[0] = nil // Prevent GC leak.
 		processHashes = processHashes[1:]
 
-		for i := 0; i < len(chainService.prevOrphans[*processHash]); i++ {
-			orphan := chainService.prevOrphans[*processHash][i]
+		// Remove the orphans from the orphan pool.
+		for i, orphan := range chainService.takeOrphans(processHash) {
 			if orphan == nil {
 				log.Warn(fmt.Sprintf("Found a nil entry at index %d in the orphan dependency list for block %v", i, processHash))
 				continue
 			}
-
-			// Remove the orphan from the orphan pool.
 			orphanHash := orphan.Block.Header.Hash()
-			chainService.removeOrphanBlock(orphan)
-			i--
 
 			// Potentially accept the block into the block chain.
 			_, err := chainService.acceptBlock(orphan.Block)
@@ -93,14 +116,48 @@ func (chainService *ChainService) processOrphans(hash *crypto.Hash) error {
 func (chainService *ChainService) AcceptBlock(block *types.Block) (inMainChain bool, err error) {
 	chainService.addBlockSync.Lock()
 	defer chainService.addBlockSync.Unlock()
+	if app.InMaintenance() {
+		return false, app.ErrMaintenance
+	}
 	return chainService.acceptBlock(block)
 
 }
 
+// acceptBlock accumulate all writes of the block into one batch and write them to disk together
 func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain bool, err error) {
+	start := time.Now()
+	// the import record is written before the batch, see importjournal.go
+	if err := chainService.beginImport(block); err != nil {
+		return false, err
+	}
+	chainService.batchStore.Begin()
+	inMainChain, err = chainService.importBlock(block)
+	if deleteErr := chainService.chainStore.DeleteImportJournal(); deleteErr != nil && err == nil {
+		err = deleteErr
+	}
+	if commitErr := chainService.batchStore.Commit(); commitErr != nil {
+		log.WithField("Reason", commitErr).Error("write block batch fail")
+		if err == nil {
+			err = commitErr
+		}
+	}
+	if err == nil {
+		blockProcessTimer.UpdateSince(start)
+		if inMainChain {
+			headHeightGauge.Update(int64(block.Header.Height))
+			chainService.compactIndex()
+		}
+	}
+	return inMainChain, err
+}
+
+func (chainService *ChainService) importBlock(block *types.Block) (inMainChain bool, err error) {
 	prevNode := chainService.blockIndex.LookupNode(&block.Header.PreviousHash)
 	preBlock := prevNode.Header()
 	for _, blockValidator := range chainService.BlockValidator() {
+		if chainService.trustedImport {
+			break
+		}
 		err = blockValidator.VerifyHeader(block.Header, &preBlock)
 		if err != nil {
 			return false, err
@@ -125,7 +182,7 @@ func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain b
 	if err != nil {
 		return false, err
 	}
-	trieStore, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), prevNode.StateRoot)
+	trieStore, err := store.TrieStoreFromCache(chainService.stateStore(), chainService.trieCleans, prevNode.StateRoot)
 	if err != nil {
 		return false, err
 	}
@@ -145,10 +202,17 @@ func (chainService *ChainService) acceptBlock(block *types.Block) (inMainChain b
 	if block.Header.Height <= chainService.BestChain().Tip().Height {
 		// store but but not reorg
 		log.Debug("block store and validate true but not reorgnize")
+		chainService.markStale(newNode)
+		chainService.flushIndexState()
 		return false, nil
 	}
 
 	detachNodes, attachNodes := chainService.getReorganizeNodes(newNode)
+	if maxDepth := chainService.maxReorgDepth(); maxDepth > 0 && uint64(detachNodes.Len()) > maxDepth {
+		log.WithField("hash", newNode.Hash).WithField("depth", detachNodes.Len()).WithField("limit", maxDepth).Warn("REORGANIZE: reject too deep reorganize")
+		chainService.flushIndexState()
+		return false, errors.Wrapf(ErrReorgTooDeep, "depth %d limit %d", detachNodes.Len(), maxDepth)
+	}
 
 	// Reorganize the chain.
 	log.WithField("hash", newNode.Hash).Info("REORGANIZE: Block is causing a reorganize.")
@@ -239,6 +303,13 @@ func (chainService *ChainService) getReorganizeNodes(node *types.BlockNode) (*li
 	// so they are attached in the appropriate order when iterating the list
 	// later.
 	forkNode := chainService.BestChain().FindFork(node)
+	if forkNode == nil {
+		// The fork point was evicted from the in-memory index, which only
+		// happens to side chains forking further below the tip than the
+		// index window.
+		log.WithField("hash", node.Hash).Warn("REORGANIZE: fork point out of block index window")
+		return detachNodes, attachNodes
+	}
 	invalidChain := false
 	for n := node; n != nil && n != forkNode; n = n.Parent {
 		if chainService.blockIndex.NodeStatus(n).KnownInvalid() {
@@ -274,6 +345,9 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 	if detachNodes.Len() == 0 && attachNodes.Len() == 0 {
 		return nil
 	}
+	forkNode := forkNodeOf(detachNodes, attachNodes)
+	oldChain := make([]*types.Block, 0, detachNodes.Len())
+	newChain := make([]*types.Block, 0, attachNodes.Len())
 	if detachNodes.Len() != 0 {
 		elem := detachNodes.Back()
 		lastBlock := elem.Value.(*types.BlockNode)
@@ -281,6 +355,7 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 		//Consider rollback
 		//	db.Rollback2Block(height, lastBlock.Hash)
 		log.WithField("Height", height).Info("REORGANIZE:RollBack state root")
+		chainService.dropState(lastBlock, lastBlock.Parent)
 		chainService.markState(db, lastBlock.Parent)
 		elem = detachNodes.Front()
 		for elem != nil {
@@ -290,6 +365,8 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 				return err
 			}
 			chainService.notifyDetachBlock(block)
+			chainService.markStale(blockNode)
+			oldChain = append([]*types.Block{block}, oldChain...)
 			elem = elem.Next()
 		}
 	}
@@ -307,11 +384,17 @@ func (chainService *ChainService) reorganizeChain(db store.StoreInterface, detac
 				return err
 			}
 			chainService.markState(db, blockNode)
+			chainService.unmarkStale(blockNode)
 			chainService.notifyBlock(block, context.Logs)
+			newChain = append(newChain, block)
 			log.WithField("Height", blockNode.Height).WithField("Hash", blockNode.Hash).Info("REORGANIZE:Append New Block")
 			elem = elem.Next()
 		}
 	}
+	logReorgLosses(detachNodes, attachNodes)
+	if forkNode != nil {
+		chainService.notifyReorg(forkNode, oldChain, newChain)
+	}
 	return nil
 }
 
@@ -346,23 +429,49 @@ func (chainService *ChainService) notifyDetachBlock(block *types.Block) {
 func (chainService *ChainService) markState(db store.StoreInterface, blockNode *types.BlockNode) {
 	db.Commit()
 	db.TrieDB().Commit(crypto.Bytes2Hash(blockNode.StateRoot), true)
+	committed := chainService.sealState(blockNode)
+	if err := chainService.indexCanonicalChain(blockNode); err != nil {
+		log.WithField("Reason", err).Error("index best chain fail")
+	}
+	if chainService.trieLayers == nil {
+		if err := chainService.chainStore.RecordBlockJournal(blockNode); err != nil {
+			log.WithField("Reason", err).Error("record block journal fail")
+		}
+	}
+	// state must reach the database store before the tip is advertised, other services
+	// read it from there
+	if err := chainService.batchStore.Flush(); err != nil {
+		log.WithField("Reason", err).Error("flush block batch fail")
+	} else if committed != nil {
+		chainService.trieLayers.Rebase(*committed.Hash, committed.Height)
+	}
 	chainService.BestChain().SetTip(blockNode)
 }
 
-//TODO improves the performan
+// InitStates load the block index from db.  Only the nodes within the index window
+// below the recorded tip are loaded, older ones are read on demand, so startup
+// does not grow with the chain.
 func (chainService *ChainService) InitStates() error {
-	blockCount := chainService.chainStore.BlockNodeCount()
-	blockNodes := make([]types.BlockNode, blockCount)
-	var i int32
+	// Roll back a block whose import was interrupted, before its node is
+	// loaded.  See importjournal.go.
+	if err := chainService.recoverImport(); err != nil {
+		return err
+	}
+	journal, err := chainService.chainStore.GetBlockJournal()
+	if err != nil {
+		journal = nil
+	}
+	start := chainService.indexStartHeight(journal)
 	var lastNode *types.BlockNode
-	err := chainService.chainStore.BlockNodeIterator(func(header *types.BlockHeader, status types.BlockStatus) error {
+	err = chainService.chainStore.BlockNodeIteratorFrom(start, func(header *types.BlockHeader, status types.BlockStatus) error {
 		// Determine the parent block node. Since we iterate block headers
 		// in order of height, if the blocks are mostly linear there is a
 		// very good chance the previous header processed is the parent.
+		// Parents of the nodes at the start height are left on disk.
 		var parent *types.BlockNode
 		if lastNode == nil {
 			blockHash := header.Hash()
-			if !blockHash.IsEqual(chainService.genesisBlock.Header.Hash()) {
+			if start == 0 && !blockHash.IsEqual(chainService.genesisBlock.Header.Hash()) {
 				return errors.Wrapf(ErrInitStateFail, "Expected  first entry in block index to be genesis block, found %s", blockHash)
 			}
 		} else if header.PreviousHash == *lastNode.Hash {
@@ -370,7 +479,7 @@ func (chainService *ChainService) InitStates() error {
 			// blocks are mostly linear there is a very good chance the
 			// previous header processed is the parent.
 			parent = lastNode
-		} else {
+		} else if header.Height > start {
 			parent = chainService.blockIndex.LookupNode(&header.PreviousHash)
 			if parent == nil {
 				return errors.Wrapf(ErrInitStateFail, "Could not find parent for block %s", header.Hash())
@@ -379,13 +488,11 @@ func (chainService *ChainService) InitStates() error {
 
 		// Initialize the block node for the block, connect it,
 		// and add it to the block index.
-		node := &blockNodes[i]
-		types.InitBlockNode(node, header, parent)
+		node := types.NewBlockNode(header, parent)
 		node.Status = status
-		chainService.blockIndex.AddNode(node)
+		chainService.blockIndex.addNode(node)
 
 		lastNode = node
-		i++
 		return nil
 	})
 
@@ -393,40 +500,26 @@ func (chainService *ChainService) InitStates() error {
 		return err
 	}
 
+	// Resume on the block journal, or on the highest block when there is
+	// no journal yet.  See resume.go for the checks done before resuming.
 	tip := lastNode
-	for {
-		if tip.Height != 0 {
-			_, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), lastNode.StateRoot)
-			if err == nil {
-
-				break
-			}
-
-			// commit fail and repaire here
-			//delete dirty data , and rollback state to journalHeight
-			//Remove nodeblock, block information from disk; Back block number and associated operation log sequence number
-			err, _ = chainService.chainStore.RollBack(tip.Height, tip.Hash)
-			if err != nil {
-				log.WithField("height", tip.Height).Error("rollback2block err")
-				return err
-			}
-
-			//Removes node information from memory
-			chainService.blockIndex.ClearNode(tip)
-		} else {
-			_, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), lastNode.StateRoot)
-			if err == nil {
-				break
-			}
-
-			return fmt.Errorf("recover tire from old data err")
+	if journal != nil {
+		if node, ok := chainService.blockIndex.Index[journal.Hash]; ok {
+			tip = node
 		}
-
-		tip = tip.Ancestor(tip.Height - 1)
+	}
+	tip, err = chainService.resumeTip(tip, journal)
+	if err != nil {
+		return err
 	}
 
 	// Set the best chain view to the stored best state.
 	chainService.BestChain().SetTip(tip)
+	// Databases written before the best chain was indexed by height are
+	// indexed once here, from the fully loaded index.
+	if err := chainService.putCanonicalChain(tip); err != nil {
+		return err
+	}
 
 	// Load the raw block bytes for the best block.
 	if !chainService.chainStore.HasBlock(tip.Hash) {
@@ -451,7 +544,12 @@ func (chainService *ChainService) InitStates() error {
 	// As we might have updated the index after it was loaded, we'll
 	// attempt to flush the index to the DB. This will only result in a
 	// write if the elements are dirty, so it'll usually be a noop.
-	return chainService.blockIndex.FlushToDB(chainService.chainStore.PutBlockNode)
+	err = chainService.blockIndex.FlushToDB(chainService.chainStore.PutBlockNode)
+	if err != nil {
+		return err
+	}
+	chainService.compactIndex()
+	return nil
 }
 
 //180000000/360
@@ -0,0 +1,70 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+)
+
+// maxProofSlots is the count of storage slots proven in a call
+const maxProofSlots = 64
+
+// AccountProof proves the storage of an account and some slots of a contract against the state root
+// of a block. Key is the key of the state trie before it is hashed by the secure trie, and Value is
+// the encoded storage of the account found at the end of the proof, empty if the account not exists
+type AccountProof struct {
+	Address      crypto.CommonAddress `json:"address"`
+	Height       uint64               `json:"height"`
+	StateRoot    common.Bytes         `json:"stateRoot"`
+	Balance      *hexutil.Big         `json:"balance"`
+	Nonce        uint64               `json:"nonce"`
+	CodeHash     crypto.Hash          `json:"codeHash"`
+	Key          common.Bytes         `json:"key"`
+	Value        common.Bytes         `json:"value"`
+	AccountProof []common.Bytes       `json:"accountProof"`
+	StorageProof []*StorageProof      `json:"storageProof"`
+}
+
+// StorageProof proves the value of a storage slot of a contract, Key is the key of the slot in
+// the state trie
+type StorageProof struct {
+	Slot  crypto.Hash    `json:"slot"`
+	Key   common.Bytes   `json:"key"`
+	Value common.Bytes   `json:"value"`
+	Proof []common.Bytes `json:"proof"`
+}
+
+// proofList collects the nodes of a proof in order from the root
+type proofList []common.Bytes
+
+func (list *proofList) Put(key []byte, value []byte) error {
+	*list = append(*list, value)
+	return nil
+}
+
+func (list *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// slotKey is the key of a storage slot of a contract in the state trie, as the slot is loaded by the evm
+func slotKey(contract *crypto.CommonAddress, slot *crypto.Hash) []byte {
+	loc := new(big.Int).SetBytes(slot[:])
+	return new(big.Int).SetBytes(sha3.HashS256(contract.Bytes(), loc.Bytes())).Bytes()
+}
+
+// prove read the value of key and the proof of it from a state trie
+func prove(trieStore store.StoreInterface, key []byte) (common.Bytes, []common.Bytes, error) {
+	value, err := trieStore.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof := proofList{}
+	if err := trieStore.Prove(key, &proof); err != nil {
+		return nil, nil, err
+	}
+	return value, proof, nil
+}
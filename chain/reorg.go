@@ -0,0 +1,106 @@
+package chain
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	// DefaultMaxReorgDepth is the default count of blocks allowed to be detached from best chain in one reorganize
+	DefaultMaxReorgDepth = 100
+	// maxReorgHistory is the count of recent reorganizes kept in memory for operators
+	maxReorgHistory = 128
+)
+
+// ReorgRecord is a summary of one chain reorganize
+type ReorgRecord struct {
+	Time       int64         `json:"time"`
+	ForkHeight uint64        `json:"forkHeight"`
+	ForkHash   crypto.Hash   `json:"forkHash"`
+	OldChain   []crypto.Hash `json:"oldChain"`
+	NewChain   []crypto.Hash `json:"newChain"`
+}
+
+// reorgHistory keep recent reorganize records, the oldest one is dropped when full
+type reorgHistory struct {
+	lock    sync.RWMutex
+	records []*ReorgRecord
+}
+
+func (history *reorgHistory) add(record *ReorgRecord) {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	history.records = append(history.records, record)
+	if len(history.records) > maxReorgHistory {
+		history.records = history.records[len(history.records)-maxReorgHistory:]
+	}
+}
+
+// list return records sorted from newest to oldest
+func (history *reorgHistory) list() []*ReorgRecord {
+	history.lock.RLock()
+	defer history.lock.RUnlock()
+	records := make([]*ReorgRecord, 0, len(history.records))
+	for i := len(history.records) - 1; i >= 0; i-- {
+		records = append(records, history.records[i])
+	}
+	return records
+}
+
+// maxReorgDepth return the configured limit, zero mean no limit
+func (chainService *ChainService) maxReorgDepth() uint64 {
+	return chainService.Config.MaxReorgDepth
+}
+
+// notifyReorg record the reorganize and post it to subscribers
+func (chainService *ChainService) notifyReorg(forkNode *types.BlockNode, oldChain, newChain []*types.Block) {
+	record := &ReorgRecord{
+		Time:       time.Now().Unix(),
+		ForkHeight: forkNode.Height,
+		ForkHash:   *forkNode.Hash,
+		OldChain:   make([]crypto.Hash, len(oldChain)),
+		NewChain:   make([]crypto.Hash, len(newChain)),
+	}
+	for i, block := range oldChain {
+		record.OldChain[i] = *block.Header.Hash()
+	}
+	for i, block := range newChain {
+		record.NewChain[i] = *block.Header.Hash()
+	}
+	chainService.reorgHistory.add(record)
+	reorgMeter.Mark(1)
+	reorgDepthHist.Update(int64(len(oldChain)))
+
+	chainService.reorgFeed.Send(&types.ReorgEvent{
+		ForkHeight: forkNode.Height,
+		ForkHash:   *forkNode.Hash,
+		OldChain:   oldChain,
+		NewChain:   newChain,
+	})
+}
+
+// ReorgFeed provide subscription of chain reorganize, the event type is *types.ReorgEvent
+func (chainService *ChainService) ReorgFeed() *event.Feed {
+	return &chainService.reorgFeed
+}
+
+// ReorgHistory return recent reorganizes from newest to oldest
+func (chainService *ChainService) ReorgHistory() []*ReorgRecord {
+	return chainService.reorgHistory.list()
+}
+
+// forkNodeOf return the common ancestor of detached and attached segment
+func forkNodeOf(detachNodes, attachNodes *list.List) *types.BlockNode {
+	if detachNodes.Len() != 0 {
+		return detachNodes.Back().Value.(*types.BlockNode).Parent
+	}
+	if attachNodes.Len() != 0 {
+		return attachNodes.Front().Value.(*types.BlockNode).Parent
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+// newReorgService return a chain service without validators whose best chain is height blocks
+// of the empty state above genesis
+func newReorgService(t *testing.T, maxReorgDepth uint64, height int) (*ChainService, []*types.BlockNode) {
+	batchStore := database.NewBatchStore(memorydb.New())
+	chainService := &ChainService{
+		Config:     &ChainConfig{MaxReorgDepth: maxReorgDepth},
+		blockIndex: NewBlockIndex(),
+		batchStore: batchStore,
+		chainStore: &ChainStore{batchStore},
+	}
+	genesis := types.NewBlockNode(&types.BlockHeader{StateRoot: trie.EmptyRoot[:]}, nil)
+	chainService.blockIndex.AddNode(genesis)
+	nodes := append([]*types.BlockNode{genesis}, extendChain(t, chainService, genesis, height, 0)...)
+	chainService.bestChain = NewChainView(nodes[len(nodes)-1])
+	return chainService, nodes
+}
+
+func reorgBlock(parent *types.BlockNode, miner byte) *types.Block {
+	return &types.Block{
+		Header: &types.BlockHeader{
+			PreviousHash: *parent.Hash,
+			Height:       parent.Height + 1,
+			StateRoot:    trie.EmptyRoot[:],
+			MinerAddr:    crypto.CommonAddress{miner},
+		},
+		Data: &types.BlockData{},
+	}
+}
+
+// extendChain store count blocks of miner on top of parent, as a chain received but not connected
+func extendChain(t *testing.T, chainService *ChainService, parent *types.BlockNode, count int, miner byte) []*types.BlockNode {
+	nodes := make([]*types.BlockNode, 0, count)
+	for i := 0; i < count; i++ {
+		block := reorgBlock(parent, miner)
+		if err := chainService.chainStore.PutBlock(block); err != nil {
+			t.Fatal(err)
+		}
+		parent = types.NewBlockNode(block.Header, parent)
+		chainService.blockIndex.AddNode(parent)
+		nodes = append(nodes, parent)
+	}
+	return nodes
+}
+
+// TestReorgDepthLimit checks a side chain detaching more blocks than the limit is refused and
+// one detaching up to the limit is reorganized to and recorded
+func TestReorgDepthLimit(t *testing.T) {
+	chainService, mainChain := newReorgService(t, 3, 5)
+	tip := chainService.BestChain().Tip()
+
+	// forking at height 1 detaches the 4 blocks above it
+	side := extendChain(t, chainService, mainChain[1], 4, 1)
+	_, err := chainService.acceptBlock(reorgBlock(side[len(side)-1], 1))
+	if errors.Cause(err) != ErrReorgTooDeep {
+		t.Fatalf("reorganize of depth 4: got %v, want %v", err, ErrReorgTooDeep)
+	}
+	if chainService.BestChain().Tip() != tip {
+		t.Fatal("best chain moved by a refused reorganize")
+	}
+	if history := chainService.ReorgHistory(); len(history) != 0 {
+		t.Fatalf("refused reorganize recorded: %v", history)
+	}
+
+	// forking at height 2 detaches 3 blocks
+	events := make(chan *types.ReorgEvent, 1)
+	sub := chainService.ReorgFeed().Subscribe(events)
+	defer sub.Unsubscribe()
+	side = extendChain(t, chainService, mainChain[2], 3, 2)
+	block := reorgBlock(side[len(side)-1], 2)
+	inMainChain, err := chainService.acceptBlock(block)
+	if err != nil || !inMainChain {
+		t.Fatalf("reorganize of depth 3: in main chain %v, err %v", inMainChain, err)
+	}
+	if !chainService.BestChain().Tip().Hash.IsEqual(block.Header.Hash()) {
+		t.Fatal("best chain not moved to the side chain")
+	}
+	history := chainService.ReorgHistory()
+	if len(history) != 1 || history[0].ForkHeight != 2 || len(history[0].OldChain) != 3 || len(history[0].NewChain) != 4 {
+		t.Fatalf("unexpected reorganize history %v", history)
+	}
+	select {
+	case ev := <-events:
+		if ev.ForkHeight != 2 || len(ev.OldChain) != 3 || len(ev.NewChain) != 4 {
+			t.Fatalf("unexpected reorganize event %v", ev)
+		}
+	default:
+		t.Fatal("reorganize not posted")
+	}
+}
+
+// TestReorgDepthUnlimited checks a zero limit lets any reorganize through
+func TestReorgDepthUnlimited(t *testing.T) {
+	chainService, mainChain := newReorgService(t, 0, 5)
+	side := extendChain(t, chainService, mainChain[0], 5, 1)
+	if _, err := chainService.acceptBlock(reorgBlock(side[len(side)-1], 1)); err != nil {
+		t.Fatal(err)
+	}
+	if tip := chainService.BestChain().Tip(); tip.Height != 6 {
+		t.Fatalf("tip at %d, want 6", tip.Height)
+	}
+}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"math"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultResumeCheckDepth is the default count of blocks re-checked below the block journal on restart
+const DefaultResumeCheckDepth = 16
+
+// Fast resume
+//
+// The block journal is recorded in the same batch as the state committed to disk,
+// see statecommit.go, so the journal always names a block whose body and state
+// reached disk.  On restart the blocks at or below the journal
+// were verified before shutdown and are not checked again, except the last
+// ResumeCheckDepth of them which are re-checked against a partly flushed disk.
+// Without a journal, e.g. a database written by an older version, every block
+// loaded in the index window is re-checked.  The node resumes on the highest
+// checked block whose body and state exist, the blocks above it are rolled back.
+
+func (chainService *ChainService) resumeCheckDepth() uint64 {
+	if chainService.Config.ResumeCheckDepth > 0 {
+		return chainService.Config.ResumeCheckDepth
+	}
+	return DefaultResumeCheckDepth
+}
+
+// checkResumeNode re-check a block before resuming on it, its body and state must be on disk
+func (chainService *ChainService) checkResumeNode(node *types.BlockNode) error {
+	if node.Status.KnownInvalid() {
+		return errors.Wrapf(ErrInitStateFail, "block %s is invalid", node.Hash)
+	}
+	if !chainService.chainStore.HasBlock(node.Hash) {
+		return errors.Wrapf(ErrBlockNotFound, "block %s", node.Hash)
+	}
+	_, err := store.TrieStoreFromStore(chainService.DatabaseService.LevelDb(), node.StateRoot)
+	return err
+}
+
+// resumeTip re-check the blocks below tip the journal does not cover and return the
+// highest one the node can resume on, the failed blocks above it are rolled back
+func (chainService *ChainService) resumeTip(tip *types.BlockNode, journal *BlockJournal) (*types.BlockNode, error) {
+	depth := uint64(math.MaxUint64)
+	if journal != nil && journal.Height <= tip.Height {
+		depth = tip.Height - journal.Height + chainService.resumeCheckDepth()
+	}
+
+	// Check from the oldest block up, a block is only resumed on when all
+	// checked blocks below it pass too.
+	checked := []*types.BlockNode{}
+	for node := tip; node != nil && uint64(len(checked)) < depth; node = node.Parent {
+		checked = append(checked, node)
+	}
+	var resumed *types.BlockNode
+	failed := len(checked)
+	for i := len(checked) - 1; i >= 0; i-- {
+		if err := chainService.checkResumeNode(checked[i]); err != nil {
+			log.WithField("height", checked[i].Height).WithField("err", err).Warn("resume check fail")
+			break
+		}
+		resumed = checked[i]
+		failed = i
+	}
+
+	rollback := checked[:failed]
+	if resumed == nil {
+		// Even the oldest checked block failed, walk further down until a
+		// block passes.
+		for node := checked[len(checked)-1]; ; {
+			if node.Height == 0 {
+				return nil, errors.Wrapf(ErrInitStateFail, "recover trie from old data")
+			}
+			parent := node.Parent
+			if parent == nil {
+				if parent = chainService.blockIndex.LookupNode(node.PreviousHash); parent == nil {
+					return nil, errors.Wrapf(ErrBlockNotFound, "cannot find parent of block %s", node.Hash)
+				}
+				chainService.blockIndex.addNode(parent)
+			}
+			if chainService.checkResumeNode(parent) == nil {
+				resumed = parent
+				break
+			}
+			rollback = append(rollback, parent)
+			node = parent
+		}
+	}
+
+	log.WithField("checked", len(checked)).WithField("rollback", len(rollback)).WithField("height", resumed.Height).Info("re-checked blocks before resume")
+
+	// Remove the failed blocks from disk and memory, newest first.
+	for _, node := range rollback {
+		if err, _ := chainService.chainStore.RollBack(node.Height, node.Hash); err != nil {
+			log.WithField("height", node.Height).Error("rollback2block err")
+			return nil, err
+		}
+		chainService.blockIndex.ClearNode(node)
+	}
+	return resumed, nil
+}
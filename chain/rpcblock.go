@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// maxBlockRange is the most blocks returned by one chain_getBlocksByRange call
+const maxBlockRange = 100
+
+// RPCTransaction is a transaction of a block decoded for rpc clients, with its hash,
+// sender and position in the chain
+type RPCTransaction struct {
+	Hash crypto.Hash
+	From crypto.CommonAddress
+	types.TransactionData
+	Sig     common.Bytes
+	Kind    string      `json:",omitempty"` // name of the transaction type
+	Payload interface{} `json:",omitempty"` // Data decoded by the kind, omitted if it has no structured payload
+
+	BlockHash crypto.Hash
+	Height    uint64
+	Index     int // index in the block
+}
+
+// RPCBlock is a block with the hashes of its transactions, or the decoded transactions
+// when they are asked for
+type RPCBlock struct {
+	Hash         crypto.Hash
+	Header       *types.BlockHeader
+	Proof        types.Proof
+	TxCount      int
+	Transactions []interface{} // crypto.Hash or *RPCTransaction
+}
+
+// RPCTxLookup is a transaction found by hash. The block fields of a transaction of the pool
+// are empty
+type RPCTxLookup struct {
+	*RPCTransaction
+	Pending       bool
+	Confirmations uint64 // blocks from the block of the transaction to the tip, 1 in the tip
+}
+
+func newRPCTransaction(tx *types.Transaction, blockHash *crypto.Hash, height uint64, index int) *RPCTransaction {
+	rpcTx := &RPCTransaction{
+		Hash:            *tx.TxHash(),
+		TransactionData: tx.Data,
+		Sig:             common.Bytes(tx.Sig),
+		BlockHash:       *blockHash,
+		Height:          height,
+		Index:           index,
+	}
+	if from, err := tx.From(); err == nil {
+		rpcTx.From = *from
+	}
+	if kind, err := tx.Kind(); err == nil {
+		rpcTx.Kind = kind.Name
+	}
+	if payload, err := tx.DecodePayload(); err == nil {
+		rpcTx.Payload = payload
+	}
+	return rpcTx
+}
+
+func newRPCBlock(block *types.Block, includeTxs bool) *RPCBlock {
+	hash := block.Header.Hash()
+	rpcBlock := &RPCBlock{
+		Hash:         *hash,
+		Header:       block.Header,
+		Proof:        block.Proof,
+		TxCount:      len(block.Data.TxList),
+		Transactions: make([]interface{}, len(block.Data.TxList)),
+	}
+	for i, tx := range block.Data.TxList {
+		if includeTxs {
+			rpcBlock.Transactions[i] = newRPCTransaction(tx, hash, block.Header.Height, i)
+		} else {
+			rpcBlock.Transactions[i] = tx.TxHash()
+		}
+	}
+	return rpcBlock
+}
@@ -0,0 +1,275 @@
+package chain
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	dbinary "github.com/drep-project/binary"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	// SnapshotVersion is the current version of chain snapshot format
+	SnapshotVersion uint16 = 1
+
+	snapshotNoCompression   uint8 = 0
+	snapshotGzipCompression uint8 = 1
+
+	maxSnapshotBlockSize = 64 * 1024 * 1024
+)
+
+var (
+	snapshotMagic = [8]byte{'D', 'R', 'E', 'P', 'S', 'N', 'A', 'P'}
+
+	NoCompressFlag = cli.BoolFlag{
+		Name:  "nocompress",
+		Usage: "Export blocks without gzip compression",
+	}
+	TrustedImportFlag = cli.BoolFlag{
+		Name:  "trusted",
+		Usage: "Skip header and signature verification of imported blocks, the blocks are still executed",
+	}
+)
+
+// snapshotHeader is written at the beginning of snapshot file, the blocks follow it
+// each prefixed with length, compressed as a whole if compression is set
+type snapshotHeader struct {
+	Magic       [8]byte
+	Version     uint16
+	Compression uint8
+	Genesis     crypto.Hash
+	From        uint64
+	To          uint64
+}
+
+// ImportResult is the result of importing a snapshot
+type ImportResult struct {
+	From     uint64 `json:"from"`
+	To       uint64 `json:"to"`
+	Imported uint64 `json:"imported"` //Blocks not exist before import
+}
+
+// ExportChain write blocks of main chain in [from, to] to w
+func (chainService *ChainService) ExportChain(w io.Writer, from, to uint64, compress bool) error {
+	if to > chainService.BestChain().Height() {
+		to = chainService.BestChain().Height()
+	}
+	if from > to {
+		return fmt.Errorf("%v, from:%d to:%d", ErrSnapshotRange, from, to)
+	}
+
+	header := snapshotHeader{
+		Magic:   snapshotMagic,
+		Version: SnapshotVersion,
+		Genesis: *chainService.genesisBlock.Header.Hash(),
+		From:    from,
+		To:      to,
+	}
+	if compress {
+		header.Compression = snapshotGzipCompression
+	}
+	if err := binary.Write(w, binary.BigEndian, &header); err != nil {
+		return err
+	}
+
+	body := w
+	if compress {
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		body = gz
+	}
+	for height := from; height <= to; height++ {
+		block, err := chainService.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		buf, err := dbinary.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(body, binary.BigEndian, uint32(len(buf))); err != nil {
+			return err
+		}
+		if _, err := body.Write(buf); err != nil {
+			return err
+		}
+		if height%10000 == 0 {
+			log.WithField("height", height).WithField("to", to).Info("exporting blocks")
+		}
+	}
+	return nil
+}
+
+// ImportChain read blocks from snapshot and add them to chain one by one, trusted blocks skip
+// header and signature verification but still are executed to rebuild the states
+func (chainService *ChainService) ImportChain(r io.Reader, trusted bool) (*ImportResult, error) {
+	header := snapshotHeader{}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != snapshotMagic {
+		return nil, ErrSnapshotFormat
+	}
+	if header.Version != SnapshotVersion {
+		return nil, fmt.Errorf("%v, version:%d", ErrSnapshotVersion, header.Version)
+	}
+	if header.Genesis != *chainService.genesisBlock.Header.Hash() {
+		return nil, fmt.Errorf("%v, snapshot genesis:%s", ErrSnapshotGenesis, header.Genesis.String())
+	}
+
+	body := r
+	switch header.Compression {
+	case snapshotNoCompression:
+	case snapshotGzipCompression:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		return nil, fmt.Errorf("%v, compression:%d", ErrSnapshotFormat, header.Compression)
+	}
+
+	result := &ImportResult{From: header.From, To: header.From}
+	for height := header.From; height <= header.To; height++ {
+		var size uint32
+		if err := binary.Read(body, binary.BigEndian, &size); err != nil {
+			return result, err
+		}
+		if size > maxSnapshotBlockSize {
+			return result, fmt.Errorf("%v, block size:%d", ErrSnapshotFormat, size)
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(body, buf); err != nil {
+			return result, err
+		}
+		block := &types.Block{}
+		if err := dbinary.Unmarshal(buf, block); err != nil {
+			return result, err
+		}
+		if block.Header.Height != height {
+			return result, fmt.Errorf("%v, expect height:%d got:%d", ErrSnapshotFormat, height, block.Header.Height)
+		}
+
+		imported, err := chainService.importChainBlock(block, trusted)
+		if err != nil {
+			return result, err
+		}
+		if imported {
+			result.Imported++
+		}
+		result.To = height
+		if height%10000 == 0 {
+			log.WithField("height", height).WithField("to", header.To).Info("importing blocks")
+		}
+	}
+	return result, nil
+}
+
+// importChainBlock add a block whose parent must exist, existing blocks are skipped
+func (chainService *ChainService) importChainBlock(block *types.Block, trusted bool) (bool, error) {
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+
+	if chainService.BlockExists(block.Header.Hash()) {
+		return false, nil
+	}
+	if !chainService.BlockExists(&block.Header.PreviousHash) {
+		return false, fmt.Errorf("%v, height:%d", ErrSnapshotParent, block.Header.Height)
+	}
+
+	chainService.trustedImport = trusted
+	defer func() { chainService.trustedImport = false }()
+	_, err := chainService.acceptBlock(block)
+	return err == nil, err
+}
+
+// ExportChainFile export blocks in [from, to] to file
+func (chainService *ChainService) ExportChainFile(file string, from, to uint64, compress bool) error {
+	fh, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	w := bufio.NewWriter(fh)
+	if err := chainService.ExportChain(w, from, to, compress); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// ImportChainFile import blocks from file
+func (chainService *ChainService) ImportChainFile(file string, trusted bool) (*ImportResult, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return chainService.ImportChain(bufio.NewReader(fh), trusted)
+}
+
+func snapshotCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "export",
+			Usage:     "Export blocks of main chain to a snapshot file",
+			ArgsUsage: "<file> [from] [to]",
+			Category:  "BLOCKCHAIN COMMANDS",
+			Flags:     []cli.Flag{NoCompressFlag},
+		},
+		{
+			Name:      "import",
+			Usage:     "Import blocks from a snapshot file",
+			ArgsUsage: "<file>",
+			Category:  "BLOCKCHAIN COMMANDS",
+			Flags:     []cli.Flag{TrustedImportFlag},
+		},
+	}
+}
+
+// runSnapshotCommand run export or import command after chain loaded, the node quits when it is done
+func (chainService *ChainService) runSnapshotCommand(executeContext *app.ExecuteContext) error {
+	ctx := executeContext.Cli
+	if len(ctx.Args()) < 1 {
+		return fmt.Errorf("snapshot file required")
+	}
+	file := ctx.Args().Get(0)
+
+	switch ctx.Command.Name {
+	case "export":
+		var from, to uint64 = 0, chainService.BestChain().Height()
+		var err error
+		if len(ctx.Args()) > 1 {
+			if from, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+				return err
+			}
+		}
+		if len(ctx.Args()) > 2 {
+			if to, err = strconv.ParseUint(ctx.Args().Get(2), 10, 64); err != nil {
+				return err
+			}
+		}
+		if err := chainService.ExportChainFile(file, from, to, !ctx.Bool(NoCompressFlag.Name)); err != nil {
+			return err
+		}
+		fmt.Println("export blocks to", file)
+	case "import":
+		result, err := chainService.ImportChainFile(file, ctx.Bool(TrustedImportFlag.Name))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("import blocks %d-%d, %d new blocks\n", result.From, result.To, result.Imported)
+	}
+	close(executeContext.Quit)
+	return nil
+}
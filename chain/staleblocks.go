@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"container/list"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+// Stale blocks
+//
+// A valid block stored on a side chain, or detached from the best chain by a
+// reorganize, is flagged stale in the block index and counted for its
+// producer.  The flag is cleared and the count taken back when a reorganize
+// attaches the block again.  Producers losing many blocks usually have a
+// skewed clock or a slow link to the other producers.
+
+var staleStatPrefix = append(ChainStatePrefix, []byte("staleStat")...)
+
+// StaleStat is the count of blocks of a producer which are not in the best chain
+type StaleStat struct {
+	Count      uint64      `json:"count"`
+	LastHeight uint64      `json:"lastHeight"` // height of the last block gone stale
+	LastHash   crypto.Hash `json:"lastHash"`
+}
+
+func staleStatKey(producer *crypto.CommonAddress) []byte {
+	return append(append([]byte{}, staleStatPrefix...), producer.Bytes()...)
+}
+
+// GetStaleStat return the stale blocks of producer, an empty stat if it has none
+func (chainStore *ChainStore) GetStaleStat(producer *crypto.CommonAddress) *StaleStat {
+	stat := &StaleStat{}
+	value, err := chainStore.Get(staleStatKey(producer))
+	if err != nil || len(value) == 0 {
+		return stat
+	}
+	if err := binary.Unmarshal(value, stat); err != nil {
+		log.WithField("producer", producer.String()).WithField("Reason", err).Error("unmarshal stale stat")
+		return &StaleStat{}
+	}
+	return stat
+}
+
+// PutStaleStat write the stale blocks of producer
+func (chainStore *ChainStore) PutStaleStat(producer *crypto.CommonAddress, stat *StaleStat) error {
+	value, err := binary.Marshal(stat)
+	if err != nil {
+		return err
+	}
+	return chainStore.Put(staleStatKey(producer), value)
+}
+
+// StaleStat return the blocks of producer which are not in the best chain
+func (chainService *ChainService) StaleStat(producer crypto.CommonAddress) *StaleStat {
+	return chainService.chainStore.GetStaleStat(&producer)
+}
+
+// markStale flag node stale and count it for its producer, the block index is flushed by the caller
+func (chainService *ChainService) markStale(node *types.BlockNode) {
+	if chainService.blockIndex.NodeStatus(node).Stale() {
+		return
+	}
+	chainService.blockIndex.SetStatusFlags(node, types.StatusStale)
+	stat := chainService.chainStore.GetStaleStat(&node.MinerAddr)
+	stat.Count++
+	stat.LastHeight = node.Height
+	stat.LastHash = *node.Hash
+	if err := chainService.chainStore.PutStaleStat(&node.MinerAddr, stat); err != nil {
+		log.WithField("Reason", err).Warn("write stale stat fail")
+	}
+	staleBlockMeter.Mark(1)
+}
+
+// unmarkStale clear the stale flag of node attached to the best chain again
+func (chainService *ChainService) unmarkStale(node *types.BlockNode) {
+	if !chainService.blockIndex.NodeStatus(node).Stale() {
+		return
+	}
+	chainService.blockIndex.UnsetStatusFlags(node, types.StatusStale)
+	stat := chainService.chainStore.GetStaleStat(&node.MinerAddr)
+	if stat.Count > 0 {
+		stat.Count--
+	}
+	if err := chainService.chainStore.PutStaleStat(&node.MinerAddr, stat); err != nil {
+		log.WithField("Reason", err).Warn("write stale stat fail")
+	}
+}
+
+// logReorgLosses report every block detached by a reorganize with the block replacing it, the
+// timestamp gap between them hints at the clock skew or latency of the producers
+func logReorgLosses(detachNodes, attachNodes *list.List) {
+	winners := make(map[uint64]*types.BlockNode, attachNodes.Len())
+	for elem := attachNodes.Front(); elem != nil; elem = elem.Next() {
+		node := elem.Value.(*types.BlockNode)
+		winners[node.Height] = node
+	}
+	for elem := detachNodes.Front(); elem != nil; elem = elem.Next() {
+		node := elem.Value.(*types.BlockNode)
+		entry := log.WithField("Height", node.Height).WithField("Hash", node.Hash).WithField("producer", node.MinerAddr.String()).WithField("time", node.TimeStamp)
+		if winner, ok := winners[node.Height]; ok {
+			entry = entry.WithField("winner", winner.MinerAddr.String()).WithField("timeGap", int64(winner.TimeStamp)-int64(node.TimeStamp))
+		}
+		entry.Warn("REORGANIZE: block lost")
+	}
+}
@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// DefaultStateCommitInterval is the default count of blocks between two state commits to disk
+const DefaultStateCommitInterval = 32
+
+// Periodic state commit
+//
+// The trie nodes written by a block are kept in memory in a layer of the block, see
+// store.TrieLayers, and every StateCommitInterval blocks the state of the best chain block
+// one interval below the tip is written to disk and recorded in the block journal. The
+// states of the blocks in between never reach disk, a reorganize drops the layers of the
+// detached blocks and the tip is committed on shutdown. After a crash the node resumes on
+// the journal and fetches the blocks above it again. An interval of 1, always used by an
+// archive node, writes the state of every block to disk directly.
+
+func (chainService *ChainService) stateCommitInterval() uint64 {
+	if chainService.nodeMode() == NodeModeArchive {
+		// an archive node keeps the state of every block
+		return 1
+	}
+	if chainService.Config.StateCommitInterval > 0 {
+		return chainService.Config.StateCommitInterval
+	}
+	return DefaultStateCommitInterval
+}
+
+// stateStore return the store the state of new blocks is written to
+func (chainService *ChainService) stateStore() dbinterface.KeyValueStore {
+	if chainService.trieLayers == nil {
+		return chainService.batchStore
+	}
+	return chainService.trieLayers.Staging(chainService.batchStore)
+}
+
+// sealState keep the state written for blockNode in its layer. When blockNode is on a commit
+// height the state of its ancestor one interval below is written into the block batch, the
+// ancestor is returned to rebase the layers on once the batch reached disk
+func (chainService *ChainService) sealState(blockNode *types.BlockNode) *types.BlockNode {
+	if chainService.trieLayers == nil {
+		return nil
+	}
+	chainService.trieLayers.Seal(*blockNode.Hash, *blockNode.PreviousHash, blockNode.Height)
+	interval := chainService.stateCommitInterval()
+	if blockNode.Height%interval != 0 || blockNode.Height < interval {
+		return nil
+	}
+	node := blockNode.Ancestor(blockNode.Height - interval)
+	if node == nil {
+		return nil
+	}
+	if err := chainService.commitState(node); err != nil {
+		log.WithField("Reason", err).WithField("Height", node.Height).Error("commit state fail")
+		return nil
+	}
+	return node
+}
+
+// commitState write the state of blockNode held in memory and the block journal naming
+// blockNode into the batch store, the record of the import in flight follows the journal
+func (chainService *ChainService) commitState(blockNode *types.BlockNode) error {
+	if err := chainService.trieLayers.Commit(crypto.Bytes2Hash(blockNode.StateRoot), chainService.batchStore); err != nil {
+		return err
+	}
+	if err := chainService.chainStore.RecordBlockJournal(blockNode); err != nil {
+		return err
+	}
+	return chainService.chainStore.advanceImportJournal(blockNode)
+}
+
+// dropState drop the states of the detached blocks from memory, from the lowest detached
+// block up. The fork block becomes the base when its state is on disk
+func (chainService *ChainService) dropState(lowest *types.BlockNode, fork *types.BlockNode) {
+	if chainService.trieLayers == nil {
+		return
+	}
+	chainService.trieLayers.Drop(*lowest.Hash)
+	if fork != nil && !chainService.trieLayers.Has(*fork.Hash) {
+		chainService.trieLayers.Rebase(*fork.Hash, fork.Height)
+	}
+}
+
+// commitTip write the state of the tip to disk, so no state is lost on shutdown
+func (chainService *ChainService) commitTip() error {
+	if chainService.trieLayers == nil {
+		return nil
+	}
+	chainService.addBlockSync.Lock()
+	defer chainService.addBlockSync.Unlock()
+	tip := chainService.BestChain().Tip()
+	if tip == nil || *tip.Hash == chainService.trieLayers.Base() {
+		return nil
+	}
+	chainService.batchStore.Begin()
+	err := chainService.commitState(tip)
+	if commitErr := chainService.batchStore.Commit(); err == nil {
+		err = commitErr
+	}
+	if err != nil {
+		return err
+	}
+	chainService.trieLayers.Rebase(*tip.Hash, tip.Height)
+	return nil
+}
+
+// initStateLayers keep the states of new blocks in memory on top of the tip, its state was
+// checked to be on disk
+func (chainService *ChainService) initStateLayers() {
+	if chainService.stateCommitInterval() <= 1 {
+		return
+	}
+	chainService.trieLayers = store.NewTrieLayers(*chainService.BestChain().Tip().Hash)
+	store.SetSharedTrie(chainService.trieCleans, chainService.trieLayers)
+}
@@ -0,0 +1,29 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// ChainStatus is the chain section of the node status
+type ChainStatus struct {
+	ChainId     types.ChainIdType `json:"chainId"`
+	RootChain   types.ChainIdType `json:"rootChain"`
+	GenesisHash crypto.Hash       `json:"genesisHash"`
+	GenesisAddr string            `json:"genesisAddr"`
+	Height      uint64            `json:"height"`
+	Encoding    byte              `json:"encoding"` //Storage encoding version blocks were migrated to, 0 if some may still be in the binary encoding
+	Forks       map[string]uint64 `json:"forks"`    //Heights of the forks scheduled in genesis by their name
+}
+
+func (chainService *ChainService) status() interface{} {
+	return &ChainStatus{
+		ChainId:     chainService.Config.ChainId,
+		RootChain:   chainService.Config.RootChain,
+		GenesisHash: *chainService.genesisBlock.Header.Hash(),
+		GenesisAddr: chainService.Config.GenesisAddr.String(),
+		Height:      chainService.BestChain().Height(),
+		Encoding:    chainService.chainStore.EncodingVersion(),
+		Forks:       chainService.genesisParams.ForkHeights(),
+	}
+}
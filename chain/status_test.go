@@ -0,0 +1,25 @@
+package chain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestChainStatusForks(t *testing.T) {
+	chainService, blocks := newDbCheckService(t, 0)
+	chainService.genesisBlock = blocks[0]
+	if status := chainService.status().(*ChainStatus); len(status.Forks) != 0 {
+		t.Fatalf("no genesis params: got forks %v", status.Forks)
+	}
+
+	params, err := parseGenesisParams(json.RawMessage(`{"chainId":1,"txRootFork":10,"sponsorFork":0}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainService.genesisParams = params
+	status := chainService.status().(*ChainStatus)
+	if want := map[string]uint64{"txRootFork": 10, "sponsorFork": 0}; !reflect.DeepEqual(status.Forks, want) {
+		t.Fatalf("got forks %v, want %v", status.Forks, want)
+	}
+}
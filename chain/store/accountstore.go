@@ -25,6 +25,8 @@ var (
 	ErrUsedAlias = errors.New("the alias has been used")
 	//ErrInvalidateAlias set null string as alias
 	ErrInvalidateAlias = errors.New("set null string as alias")
+	//ErrNonCanonicalStorage the storage has no canonical encoding
+	ErrNonCanonicalStorage = errors.New("storage balance map is encoded in map order")
 )
 
 type trieAccountStore struct {
@@ -57,12 +59,17 @@ func (trieStore *trieAccountStore) initState() error {
 	return nil
 }
 
+// StorageKey is the key of the storage of an account in the state trie
+func StorageKey(addr *crypto.CommonAddress) []byte {
+	return sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+}
+
 func (trieStore *trieAccountStore) GetStorage(addr *crypto.CommonAddress) (*types.Storage, error) {
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
 	storage := &types.Storage{}
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+	key := StorageKey(addr)
 	value, err := trieStore.storeDB.Get(key)
 	if err != nil {
 		return nil, err
@@ -83,7 +90,7 @@ func (trieStore *trieAccountStore) DeleteStorage(addr *crypto.CommonAddress) err
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+	key := StorageKey(addr)
 
 	return trieStore.storeDB.Delete(key)
 }
@@ -92,7 +99,14 @@ func (trieStore *trieAccountStore) PutStorage(addr *crypto.CommonAddress, storag
 	trieStore.lock.Lock()
 	defer trieStore.lock.Unlock()
 
-	key := sha3.Keccak256([]byte(AddressStorage + addr.Hex()))
+	// The storage is hashed into the state root, a map would be encoded in a
+	// different order by each node. BalanceMap stays empty until it is replaced
+	// by a sorted encoding at a fork height.
+	if len(storage.BalanceMap) > 0 {
+		return ErrNonCanonicalStorage
+	}
+
+	key := StorageKey(addr)
 	value, err := binary.Marshal(storage)
 	if err != nil {
 		return err
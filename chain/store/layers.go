@@ -0,0 +1,296 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+// TrieLayers keep the trie nodes written by recent blocks in memory, one layer per block, so
+// the state of a recent block is read without being on disk. Commit writes the nodes reachable
+// from the state of one block to disk and drops the layers up to it, the nodes of the states in
+// between that were overwritten never reach disk.
+//
+// A layer is only valid on top of its parent, dropping a layer drops the layers above it too.
+type TrieLayers struct {
+	lock    sync.RWMutex
+	nodes   map[crypto.Hash]*layerNode
+	layers  map[crypto.Hash]*trieLayer // block hash -> layer
+	staging map[crypto.Hash][]byte     // nodes written for the block being sealed
+	base    crypto.Hash                // block whose state was written to disk last
+	size    int                        // bytes of the nodes held
+}
+
+type layerNode struct {
+	blob []byte
+	refs int // count of layers holding the node
+}
+
+type trieLayer struct {
+	height uint64
+	parent crypto.Hash
+	nodes  []crypto.Hash
+}
+
+// NewTrieLayers create empty layers on top of the block whose state is on disk
+func NewTrieLayers(base crypto.Hash) *TrieLayers {
+	return &TrieLayers{
+		nodes:   make(map[crypto.Hash]*layerNode),
+		layers:  make(map[crypto.Hash]*trieLayer),
+		staging: make(map[crypto.Hash][]byte),
+		base:    base,
+	}
+}
+
+func (layers *TrieLayers) node(key []byte) ([]byte, bool) {
+	if len(key) != crypto.HashLength {
+		return nil, false
+	}
+	hash := crypto.Bytes2Hash(key)
+	layers.lock.RLock()
+	defer layers.lock.RUnlock()
+	if blob, ok := layers.staging[hash]; ok {
+		return blob, true
+	}
+	if node, ok := layers.nodes[hash]; ok {
+		return node.blob, true
+	}
+	return nil, false
+}
+
+func (layers *TrieLayers) stage(key []byte, value []byte) bool {
+	if len(key) != crypto.HashLength {
+		return false
+	}
+	layers.lock.Lock()
+	defer layers.lock.Unlock()
+	layers.staging[crypto.Bytes2Hash(key)] = append([]byte{}, value...)
+	return true
+}
+
+// Seal move the nodes written since the last seal into the layer of block
+func (layers *TrieLayers) Seal(block, parent crypto.Hash, height uint64) {
+	layers.lock.Lock()
+	defer layers.lock.Unlock()
+	layer, ok := layers.layers[block]
+	if !ok {
+		if block == layers.base {
+			// the state of the base block is already on disk
+			layers.staging = make(map[crypto.Hash][]byte)
+			return
+		}
+		layer = &trieLayer{height: height, parent: parent}
+		layers.layers[block] = layer
+	}
+	for hash, blob := range layers.staging {
+		node, ok := layers.nodes[hash]
+		if !ok {
+			node = &layerNode{blob: blob}
+			layers.nodes[hash] = node
+			layers.size += crypto.HashLength + len(blob)
+		}
+		node.refs++
+		layer.nodes = append(layer.nodes, hash)
+	}
+	layers.staging = make(map[crypto.Hash][]byte)
+}
+
+// Drop remove the layer of block and the layers on top of it
+func (layers *TrieLayers) Drop(block crypto.Hash) {
+	layers.lock.Lock()
+	defer layers.lock.Unlock()
+	if layer, ok := layers.layers[block]; ok {
+		layers.drop(block, layer)
+		layers.dropOrphans()
+	}
+}
+
+func (layers *TrieLayers) drop(block crypto.Hash, layer *trieLayer) {
+	for _, hash := range layer.nodes {
+		node := layers.nodes[hash]
+		if node.refs--; node.refs == 0 {
+			delete(layers.nodes, hash)
+			layers.size -= crypto.HashLength + len(node.blob)
+		}
+	}
+	delete(layers.layers, block)
+}
+
+// dropOrphans remove the layers whose parent is neither a layer nor the base
+func (layers *TrieLayers) dropOrphans() {
+	for dropped := true; dropped; {
+		dropped = false
+		for block, layer := range layers.layers {
+			if _, ok := layers.layers[layer.parent]; !ok && layer.parent != layers.base {
+				layers.drop(block, layer)
+				dropped = true
+			}
+		}
+	}
+}
+
+// Has report whether the state of block is held by the layers
+func (layers *TrieLayers) Has(block crypto.Hash) bool {
+	layers.lock.RLock()
+	defer layers.lock.RUnlock()
+	_, ok := layers.layers[block]
+	return ok
+}
+
+// Commit write the nodes reachable from root, the state root of block, which are only held in
+// memory into batch. Once the batch is written Rebase must be called
+func (layers *TrieLayers) Commit(root crypto.Hash, batch dbinterface.KeyValueWriter) error {
+	layers.lock.RLock()
+	defer layers.lock.RUnlock()
+
+	visited := make(map[crypto.Hash]struct{})
+	queue := []crypto.Hash{root}
+	for len(queue) > 0 {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if _, ok := visited[hash]; ok {
+			continue
+		}
+		visited[hash] = struct{}{}
+
+		// a node not held in memory is on disk together with its children
+		node, ok := layers.nodes[hash]
+		if !ok {
+			continue
+		}
+		if err := batch.Put(hash[:], node.blob); err != nil {
+			return err
+		}
+		children, err := trie.NodeChildren(hash, node.blob)
+		if err != nil {
+			return err
+		}
+		queue = append(queue, children...)
+	}
+	return nil
+}
+
+// Rebase make block, whose state was committed, the base and drop the layers at or below its
+// height and the layers not on top of it
+func (layers *TrieLayers) Rebase(block crypto.Hash, height uint64) {
+	layers.lock.Lock()
+	defer layers.lock.Unlock()
+	layers.base = block
+	for hash, layer := range layers.layers {
+		if layer.height <= height {
+			layers.drop(hash, layer)
+		}
+	}
+	layers.dropOrphans()
+}
+
+// Base return the block whose state was written to disk last
+func (layers *TrieLayers) Base() crypto.Hash {
+	layers.lock.RLock()
+	defer layers.lock.RUnlock()
+	return layers.base
+}
+
+// Size return the count of layers and the bytes of nodes they hold
+func (layers *TrieLayers) Size() (int, int) {
+	layers.lock.RLock()
+	defer layers.lock.RUnlock()
+	return len(layers.layers), layers.size
+}
+
+// Overlay return a view of disk with the nodes held in the layers, writes go to disk
+func (layers *TrieLayers) Overlay(disk dbinterface.KeyValueStore) dbinterface.KeyValueStore {
+	return &layerStore{KeyValueStore: disk, layers: layers}
+}
+
+// Staging return a view of disk with the nodes held in the layers, trie nodes written to it are
+// staged for the next Seal instead of reaching disk
+func (layers *TrieLayers) Staging(disk dbinterface.KeyValueStore) dbinterface.KeyValueStore {
+	return &layerStore{KeyValueStore: disk, layers: layers, staging: true}
+}
+
+// layerStore read the trie nodes from the layers before disk
+type layerStore struct {
+	dbinterface.KeyValueStore
+	layers  *TrieLayers
+	staging bool
+}
+
+func (store *layerStore) Has(key []byte) (bool, error) {
+	if _, ok := store.layers.node(key); ok {
+		return true, nil
+	}
+	return store.KeyValueStore.Has(key)
+}
+
+func (store *layerStore) Get(key []byte) ([]byte, error) {
+	if blob, ok := store.layers.node(key); ok {
+		return blob, nil
+	}
+	return store.KeyValueStore.Get(key)
+}
+
+func (store *layerStore) Put(key []byte, value []byte) error {
+	if store.staging && store.layers.stage(key, value) {
+		return nil
+	}
+	return store.KeyValueStore.Put(key, value)
+}
+
+func (store *layerStore) NewBatch() dbinterface.Batch {
+	if !store.staging {
+		return store.KeyValueStore.NewBatch()
+	}
+	return &stagingBatch{store: store, disk: store.KeyValueStore.NewBatch()}
+}
+
+// stagingBatch stage the trie nodes on write and write the other keys to disk
+type stagingBatch struct {
+	store *layerStore
+	disk  dbinterface.Batch
+	ops   [][2][]byte
+	size  int
+}
+
+func (b *stagingBatch) Put(key, value []byte) error {
+	if len(key) != crypto.HashLength {
+		b.size += len(value)
+		return b.disk.Put(key, value)
+	}
+	b.ops = append(b.ops, [2][]byte{append([]byte{}, key...), append([]byte{}, value...)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *stagingBatch) Delete(key []byte) error {
+	b.size++
+	return b.disk.Delete(key)
+}
+
+func (b *stagingBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *stagingBatch) Write() error {
+	for _, op := range b.ops {
+		b.store.layers.stage(op[0], op[1])
+	}
+	return b.disk.Write()
+}
+
+func (b *stagingBatch) Reset() {
+	b.disk.Reset()
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+func (b *stagingBatch) Replay(w dbinterface.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if err := w.Put(op[0], op[1]); err != nil {
+			return err
+		}
+	}
+	return b.disk.Replay(w)
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+// layerBlock write balance into the state on top of root and seal it as block
+func layerBlock(t *testing.T, layers *TrieLayers, disk *memorydb.Database, root []byte, block, parent crypto.Hash, height uint64, balance int64) []byte {
+	db, err := TrieStoreFromCache(layers.Staging(disk), nil, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.CommonAddress{1}
+	if err := db.PutBalance(&addr, height, big.NewInt(balance)); err != nil {
+		t.Fatal(err)
+	}
+	db.Commit()
+	newRoot := db.GetStateRoot()
+	if err := db.TrieDB().Commit(crypto.Bytes2Hash(newRoot), false); err != nil {
+		t.Fatal(err)
+	}
+	layers.Seal(block, parent, height)
+	return newRoot
+}
+
+func TestTrieLayers(t *testing.T) {
+	disk := memorydb.New()
+	putChangeInterval(t, disk)
+	genesis := crypto.Hash{0}
+	layers := NewTrieLayers(genesis)
+
+	root1 := layerBlock(t, layers, disk, trie.EmptyRoot[:], crypto.Hash{1}, genesis, 1, 100)
+	root2 := layerBlock(t, layers, disk, root1, crypto.Hash{2}, crypto.Hash{1}, 2, 200)
+	side := layerBlock(t, layers, disk, root1, crypto.Hash{3}, crypto.Hash{1}, 2, 300)
+
+	if _, err := TrieStoreFromCache(disk, nil, root2); err == nil {
+		t.Fatal("state of a layer reached disk")
+	}
+	if _, err := TrieStoreFromCache(layers.Overlay(disk), nil, side); err != nil {
+		t.Fatal(err)
+	}
+
+	layers.Drop(crypto.Hash{3})
+	if _, err := TrieStoreFromCache(layers.Overlay(disk), nil, side); err == nil {
+		t.Fatal("dropped state still readable")
+	}
+
+	batch := disk.NewBatch()
+	if err := layers.Commit(crypto.Bytes2Hash(root2), batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	layers.Rebase(crypto.Hash{2}, 2)
+	if count, size := layers.Size(); count != 0 || size != 0 {
+		t.Fatalf("%d layers of %d bytes left", count, size)
+	}
+
+	db, err := TrieStoreFromCache(disk, nil, root2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.CommonAddress{1}
+	if balance := db.GetBalance(&addr, 2); balance.Int64() != 200 {
+		t.Fatalf("got balance %v", balance)
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"bytes"
 	"encoding/binary"
+	"github.com/allegro/bigcache"
 	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database"
@@ -10,6 +11,7 @@ import (
 	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
 	"github.com/drep-project/DREP-Chain/types"
 	"math/big"
+	"sync"
 )
 
 const (
@@ -19,6 +21,10 @@ const (
 
 var (
 	log = dlog.EnsureLogger(MODULENAME)
+
+	sharedTrieLock sync.RWMutex
+	sharedCleans   *bigcache.BigCache // clean trie nodes shared by every store opened with TrieStoreFromStore
+	sharedLayers   *TrieLayers        // states of recent blocks not written to disk yet
 )
 
 type StoreInterface interface {
@@ -42,12 +48,19 @@ type StoreInterface interface {
 	PutByteCode(addr *crypto.CommonAddress, byteCode []byte) error
 
 	GetReputation(addr *crypto.CommonAddress) *big.Int
+
+	GetToken(symbol string) (*types.Token, error)
+	PutToken(token *types.Token) error
+	GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int
+	PutTokenBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error
+
 	GetStateRoot() []byte
 	RecoverTrie(root []byte) bool
 
 	TrieDB() *trie.Database
 	Get(key []byte) ([]byte, error)
 	Put(key []byte, value []byte) error
+	Prove(key []byte, proofDb dbinterface.KeyValueWriter) error
 	Commit()
 
 	CopyState() *database.SnapShot
@@ -71,6 +84,7 @@ type StoreInterface interface {
 type Store struct {
 	stake   *trieStakeStore
 	account *trieAccountStore
+	token   *trieTokenStore
 	db      *StoreDB
 }
 
@@ -247,6 +261,22 @@ func (s Store) GetReputation(addr *crypto.CommonAddress) *big.Int {
 	return s.account.GetReputation(addr)
 }
 
+func (s Store) GetToken(symbol string) (*types.Token, error) {
+	return s.token.GetToken(symbol)
+}
+
+func (s Store) PutToken(token *types.Token) error {
+	return s.token.PutToken(token)
+}
+
+func (s Store) GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	return s.token.GetTokenBalance(addr, symbol)
+}
+
+func (s Store) PutTokenBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	return s.token.PutTokenBalance(addr, symbol, balance)
+}
+
 func (s Store) AliasSet(addr *crypto.CommonAddress, alias string) (err error) {
 	return s.account.AliasSet(addr, alias)
 }
@@ -278,12 +308,38 @@ func (s Store) Put(key []byte, value []byte) error {
 	return s.db.Put(key, value)
 }
 
+func (s Store) Prove(key []byte, proofDb dbinterface.KeyValueWriter) error {
+	return s.db.Prove(key, proofDb)
+}
+
+// SetSharedTrie set the clean node cache and the in memory states of recent blocks of the
+// stores opened by TrieStoreFromStore, so state read by rpc and consensus hits the nodes
+// cached by block import instead of the database, and the states not on disk yet are found
+func SetSharedTrie(cleans *bigcache.BigCache, layers *TrieLayers) {
+	sharedTrieLock.Lock()
+	defer sharedTrieLock.Unlock()
+	sharedCleans = cleans
+	sharedLayers = layers
+}
+
 func TrieStoreFromStore(diskDB dbinterface.KeyValueStore, stateRoot []byte) (StoreInterface, error) {
-	db := NewStoreDB(diskDB, nil, nil, trie.NewDatabaseWithCache(diskDB, 0))
+	sharedTrieLock.RLock()
+	cleans, layers := sharedCleans, sharedLayers
+	sharedTrieLock.RUnlock()
+	if layers != nil {
+		diskDB = layers.Overlay(diskDB)
+	}
+	return TrieStoreFromCache(diskDB, cleans, stateRoot)
+}
+
+// TrieStoreFromCache is TrieStoreFromStore with a clean node cache shared between stores
+func TrieStoreFromCache(diskDB dbinterface.KeyValueStore, cleans *bigcache.BigCache, stateRoot []byte) (StoreInterface, error) {
+	db := NewStoreDB(diskDB, nil, nil, trie.NewDatabaseWithCleans(diskDB, cleans))
 
 	store := &Store{
 		stake:   newStakeStorage(db),
 		account: newTrieAccoutStore(db),
+		token:   newTokenStore(db),
 		db:      db,
 	}
 
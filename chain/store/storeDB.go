@@ -68,6 +68,11 @@ func (s *StoreDB) Delete(key []byte) error {
 	return err
 }
 
+// Prove write the trie nodes on the path to key into proofDb, the state is read at the recovered root
+func (s *StoreDB) Prove(key []byte, proofDb dbinterface.KeyValueWriter) error {
+	return s.trie.Prove(key, 0, proofDb)
+}
+
 func (s *StoreDB) Flush() {
 	if s.cache != nil {
 		s.cache.Flush()
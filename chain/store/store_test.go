@@ -11,12 +11,23 @@ import (
 
 	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/database/leveldb"
 	"github.com/drep-project/binary"
 )
 
 var ChangeCycle uint64 = 100
 
+// putChangeInterval write the change interval the consensus service stores at startup,
+// balances of a height other than 0 can't be read or written without it. It is stored in
+// units of 100 blocks, cancelled credit returns to balance ChangeCycle blocks later
+func putChangeInterval(t *testing.T, db dbinterface.KeyValueWriter) {
+	value := new(big.Int).SetUint64(ChangeCycle / 100).FillBytes(make([]byte, 8))
+	if err := db.Put([]byte(ChangeInterval), value); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestGetVoteCredit(t *testing.T) {
 	defer os.RemoveAll("./test")
 	diskDB, _ := leveldb.New("./test", 16, 512, "")
@@ -35,8 +46,8 @@ func TestGetVoteCredit(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		pri, _ := crypto.GenerateKey(rand.Reader)
 		addr := crypto.PubkeyToAddress(pri.PubKey())
-		store.stake.VoteCredit(&addr, &backbone, new(big.Int).SetUint64(uint64(222+i)*drepUnit), 0)
-		total.Add(total, new(big.Int).SetUint64(uint64(222+i)*drepUnit))
+		store.stake.VoteCredit(&addr, &backbone, new(big.Int).SetUint64(uint64(222+i)*params.Coin), 0)
+		total.Add(total, new(big.Int).SetUint64(uint64(222+i)*params.Coin))
 	}
 
 	if total.Cmp(store.GetVoteCreditCount(&backbone)) != 0 {
@@ -66,7 +77,7 @@ func TestCandidateCredit(t *testing.T) {
 		Node:   "127.0.0.1:55555",
 	}
 	data, _ := cd.Marshal()
-	store.stake.CandidateCredit(&backbone, new(big.Int).SetUint64(registerPledgeLimit*drepUnit), data, 0)
+	store.stake.CandidateCredit(&backbone, new(big.Int).Mul(new(big.Int).SetUint64(RegisterPledgeLimit), new(big.Int).SetUint64(params.Coin)), data, 0)
 
 	m, err := store.GetCandidateAddrs()
 	if err != nil {
@@ -103,6 +114,7 @@ func TestBigInt(t *testing.T) {
 func TestPutBalance(t *testing.T) {
 	defer os.RemoveAll("./test")
 	diskDB, _ := leveldb.New("./test", 16, 512, "")
+	putChangeInterval(t, diskDB)
 	storeInterface, _ := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
 
 	store := storeInterface.(*Store)
@@ -144,6 +156,24 @@ func TestPutBalance(t *testing.T) {
 	}
 }
 
+func TestPutStorageCanonical(t *testing.T) {
+	defer os.RemoveAll("./test")
+	diskDB, _ := leveldb.New("./test", 16, 512, "")
+	storeInterface, _ := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
+	store := storeInterface.(*Store)
+
+	pri, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(pri.PubKey())
+	storage := &types.Storage{Nonce: 1}
+	if err := store.account.PutStorage(&addr, storage); err != nil {
+		t.Fatal(err)
+	}
+	storage.BalanceMap = map[string]big.Int{"a": *big.NewInt(1), "b": *big.NewInt(2)}
+	if err := store.account.PutStorage(&addr, storage); err != ErrNonCanonicalStorage {
+		t.Fatalf("got %v putting a balance map, want %v", err, ErrNonCanonicalStorage)
+	}
+}
+
 func TestDatabase_UpdateCandidateAddr(t *testing.T) {
 	defer os.RemoveAll("./test")
 
@@ -242,6 +272,7 @@ func TestCancelVoteCredit(t *testing.T) {
 	defer os.RemoveAll("./test")
 
 	diskDB, _ := leveldb.New("./test", 16, 512, "")
+	putChangeInterval(t, diskDB)
 	storeInterface, err := TrieStoreFromStore(diskDB, trie.EmptyRoot[:])
 
 	store := storeInterface.(*Store)
@@ -273,8 +304,8 @@ func TestCancelVoteCredit(t *testing.T) {
 			t.Fatal("cancel vote ok")
 		}
 
-		if voteValue.Cmp(store.GetBalance(&addr, ChangeCycle)) != 0 {
-			t.Fatal(voteValue, "!=", store.GetBalance(&addr, ChangeCycle))
+		if voteValue.Cmp(store.GetBalance(&addr, 10+ChangeCycle)) != 0 {
+			t.Fatal(voteValue, "!=", store.GetBalance(&addr, 10+ChangeCycle))
 		}
 	}
 
@@ -290,7 +321,7 @@ func TestCancelVoteCredit(t *testing.T) {
 	}
 
 	for _, addr := range addrs {
-		b := store.GetBalance(&addr, ChangeCycle)
+		b := store.GetBalance(&addr, 10+ChangeCycle)
 		if b.Cmp(new(big.Int).SetInt64(50000)) != 0 {
 			t.Fatalf("cancel vote err,%v", b)
 		}
@@ -305,34 +336,14 @@ func TestCancelVoteCredit(t *testing.T) {
 }
 
 func Test_getInterset(t *testing.T) {
-	balance := new(big.Int).SetUint64(params.Coin)
-	balance.Mul(balance, new(big.Int).SetUint64(100))
-	//Within two months
-	interest2 := getInterst(0, 1036800, balance)
-
-	fmt.Println("0-3  month:", interest2)
-	fmt.Println(interest2.Mul(interest2, new(big.Int).SetUint64(6*100)).Div(interest2, balance), "%")
-
-	//Three months interest
-	interest3 := getInterst(0, 1555200, balance)
-
-	fmt.Println("3-6  month:", interest3)
-	fmt.Println(interest3.Mul(interest3, new(big.Int).SetUint64(4*100)).Div(interest3, balance), "%")
-
-	//six months interest
-	interest6 := getInterst(0, 3110400, balance)
-	fmt.Println("6-12  month:", getInterst(0, 3110400, balance))
-	fmt.Println(interest6.Mul(interest6, new(big.Int).SetUint64(2*100)).Div(interest6, balance), "%")
-	//12 months interest
-
-	interest12 := getInterst(0, 6220800, balance)
-	fmt.Println("12- month:")
-	fmt.Println(interest12)
-	fmt.Println(balance)
-
-	interest12.Mul(interest12, new(big.Int).SetUint64(100))
-
-	fmt.Println(interest12.Div(interest12, balance).String(), "%")
-
-	//fmt.Println(getInterst(0, 1555200, balance))
+	threeMonth, sixMonth, oneYear, moreOneYear := GetInterestRate()
+	fmt.Println("0-3  month:", threeMonth, "%")
+	fmt.Println("3-6  month:", sixMonth, "%")
+	fmt.Println("6-12  month:", oneYear, "%")
+	fmt.Println("12- month:", moreOneYear, "%")
+
+	//The longer the credit is kept, the higher the annualized interest rate
+	if threeMonth == 0 || threeMonth > sixMonth || sixMonth > oneYear || oneYear > moreOneYear {
+		t.Fatalf("interest rates not growing with time, %d %d %d %d", threeMonth, sixMonth, oneYear, moreOneYear)
+	}
 }
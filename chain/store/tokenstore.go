@@ -0,0 +1,73 @@
+package store
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+const (
+	//TokenStorage Native token stored with its symbol as the KEY
+	TokenStorage = "TokenStorage"
+	//TokenBalance Balance of a native token stored with the address and the symbol as the KEY
+	TokenBalance = "TokenBalance"
+)
+
+type trieTokenStore struct {
+	store *StoreDB
+}
+
+func newTokenStore(store *StoreDB) *trieTokenStore {
+	return &trieTokenStore{
+		store: store,
+	}
+}
+
+// TokenKey is the key of a native token in the state trie
+func TokenKey(symbol string) []byte {
+	return sha3.Keccak256([]byte(TokenStorage + symbol))
+}
+
+// TokenBalanceKey is the key of the balance of a native token of an account in the state trie,
+// it is in the key space of the account storage. Balances are kept under their own keys instead of
+// in types.Storage: adding a field to Storage changes the encoding and so the state root of every
+// existing account, and a map like BalanceMap has no canonical encoding to be hashed by all nodes.
+// A transfer also only touches the two balances instead of decoding and rewriting whole accounts
+func TokenBalanceKey(addr *crypto.CommonAddress, symbol string) []byte {
+	return sha3.Keccak256([]byte(AddressStorage + addr.Hex() + TokenBalance + symbol))
+}
+
+func (trieStore *trieTokenStore) GetToken(symbol string) (*types.Token, error) {
+	value, err := trieStore.store.Get(TokenKey(symbol))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	token := &types.Token{}
+	if err := binary.Unmarshal(value, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (trieStore *trieTokenStore) PutToken(token *types.Token) error {
+	value, err := binary.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return trieStore.store.Put(TokenKey(token.Symbol), value)
+}
+
+func (trieStore *trieTokenStore) GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	value, _ := trieStore.store.Get(TokenBalanceKey(addr, symbol))
+	return new(big.Int).SetBytes(value)
+}
+
+func (trieStore *trieTokenStore) PutTokenBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	return trieStore.store.Put(TokenBalanceKey(addr, symbol), balance.Bytes())
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"bytes"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+// TrieCheck is the result of walking every node of a state trie
+type TrieCheck struct {
+	Nodes   uint64        // nodes found
+	Missing []crypto.Hash // nodes referenced but not found
+	Corrupt []crypto.Hash // nodes whose content does not match their hash
+}
+
+// CheckTrie walk the state trie at root in diskDB, the states of recent blocks not on disk yet
+// included. Every missing or corrupt node is reported, the walk goes on with the other nodes
+func CheckTrie(diskDB dbinterface.KeyValueStore, root []byte) *TrieCheck {
+	sharedTrieLock.RLock()
+	layers := sharedLayers
+	sharedTrieLock.RUnlock()
+	if layers != nil {
+		diskDB = layers.Overlay(diskDB)
+	}
+
+	check := &TrieCheck{}
+	rootHash := crypto.Bytes2Hash(root)
+	if rootHash == trie.EmptyRoot || rootHash == (crypto.Hash{}) {
+		return check
+	}
+	queue := []crypto.Hash{rootHash}
+	for len(queue) > 0 {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+
+		blob, err := diskDB.Get(hash[:])
+		if err != nil || len(blob) == 0 {
+			check.Missing = append(check.Missing, hash)
+			continue
+		}
+		check.Nodes++
+		if !bytes.Equal(sha3.Keccak256(blob), hash[:]) {
+			check.Corrupt = append(check.Corrupt, hash)
+			continue
+		}
+		children, err := trie.NodeChildren(hash, blob)
+		if err != nil {
+			check.Corrupt = append(check.Corrupt, hash)
+			continue
+		}
+		queue = append(queue, children...)
+	}
+	return check
+}
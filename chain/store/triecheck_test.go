@@ -0,0 +1,79 @@
+package store
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+// trieNodes return the keys of the trie nodes in disk, the keys other data is stored under
+// are not the hash of their value
+func trieNodes(disk *memorydb.Database) [][]byte {
+	var keys [][]byte
+	it := disk.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		if bytes.Equal(it.Key(), sha3.Keccak256(it.Value())) {
+			keys = append(keys, append([]byte{}, it.Key()...))
+		}
+	}
+	return keys
+}
+
+func TestCheckTrie(t *testing.T) {
+	disk := memorydb.New()
+	putChangeInterval(t, disk)
+	db, err := TrieStoreFromCache(disk, nil, trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(1); i <= 50; i++ {
+		if err := db.PutBalance(&crypto.CommonAddress{i}, 1, big.NewInt(int64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Commit()
+	root := db.GetStateRoot()
+	if err := db.TrieDB().Commit(crypto.Bytes2Hash(root), false); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := trieNodes(disk)
+	check := CheckTrie(disk, root)
+	if check.Nodes != uint64(len(nodes)) || len(check.Missing) != 0 || len(check.Corrupt) != 0 {
+		t.Fatalf("consistent trie of %d nodes: got %d nodes, %d missing, %d corrupt", len(nodes), check.Nodes, len(check.Missing), len(check.Corrupt))
+	}
+	if check := CheckTrie(disk, trie.EmptyRoot[:]); check.Nodes != 0 || len(check.Missing) != 0 {
+		t.Fatalf("empty trie: got %d nodes, %d missing", check.Nodes, len(check.Missing))
+	}
+
+	// a missing node is reported and the walk goes on with its siblings
+	var missing []byte
+	for _, key := range nodes {
+		if !bytes.Equal(key, root) {
+			missing = key
+			break
+		}
+	}
+	if err := disk.Delete(missing); err != nil {
+		t.Fatal(err)
+	}
+	check = CheckTrie(disk, root)
+	if len(check.Missing) != 1 || !bytes.Equal(check.Missing[0][:], missing) || len(check.Corrupt) != 0 || check.Nodes == 0 {
+		t.Fatalf("missing node %x: got %d nodes, missing %v, %d corrupt", missing, check.Nodes, check.Missing, len(check.Corrupt))
+	}
+
+	// a node not matching its hash is reported and its children are not walked
+	if err := disk.Put(root, []byte{0xc0}); err != nil {
+		t.Fatal(err)
+	}
+	check = CheckTrie(disk, root)
+	if len(check.Corrupt) != 1 || !bytes.Equal(check.Corrupt[0][:], root) || check.Nodes != 1 || len(check.Missing) != 0 {
+		t.Fatalf("corrupt root: got %d nodes, %d missing, corrupt %v", check.Nodes, len(check.Missing), check.Corrupt)
+	}
+}
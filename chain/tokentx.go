@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+/**********************token********************/
+
+type TokenTxSelector struct {
+}
+
+func (tokenTxSelector *TokenTxSelector) Select(tx *types.Transaction) bool {
+	switch tx.Type() {
+	case types.IssueTokenType, types.MintTokenType, types.BurnTokenType, types.TransferTokenType:
+		return true
+	}
+	return false
+}
+
+var (
+	_ = (ITransactionSelector)((*TokenTxSelector)(nil))
+	_ = (ITransactionValidator)((*TokenTransactionProcessor)(nil))
+)
+
+// TokenTransactionProcessor issue native tokens and move them between the storage of accounts,
+// all checks are done before any state is written
+type TokenTransactionProcessor struct {
+}
+
+func (processor *TokenTransactionProcessor) ExecuteTransaction(context *ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	from := context.From()
+	ts := context.TrieStore()
+	tx := context.Tx()
+
+	var err error
+	switch tx.Type() {
+	case types.IssueTokenType:
+		err = processor.issue(context, ts, from)
+	case types.MintTokenType:
+		err = processor.mint(context, ts, from)
+	case types.BurnTokenType:
+		err = processor.burn(context, ts, from)
+	case types.TransferTokenType:
+		err = processor.transfer(context, ts, from)
+	default:
+		err = ErrUnsupportTxType
+	}
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+
+	err = ts.PutNonce(from, tx.Nonce()+1)
+	if err != nil {
+		etr.Txerror = err
+		return etr
+	}
+	return etr
+}
+
+func (processor *TokenTransactionProcessor) issue(context *ExecuteTransactionContext, ts store.StoreInterface, from *crypto.CommonAddress) error {
+	data := &types.IssueTokenData{}
+	if err := data.Unmarshal(context.Data()); err != nil {
+		return err
+	}
+	if err := context.UseGas(params.TokenIssueGas); err != nil {
+		return err
+	}
+	token, err := ts.GetToken(data.Symbol)
+	if err != nil {
+		return err
+	}
+	if token != nil {
+		return ErrTokenExist
+	}
+
+	token = &types.Token{
+		Symbol:    data.Symbol,
+		Name:      data.Name,
+		Decimals:  data.Decimals,
+		Issuer:    *from,
+		Supply:    data.Supply,
+		MaxSupply: data.MaxSupply,
+	}
+	if err := ts.PutToken(token); err != nil {
+		return err
+	}
+	return ts.PutTokenBalance(from, token.Symbol, data.Supply.ToInt())
+}
+
+func (processor *TokenTransactionProcessor) mint(context *ExecuteTransactionContext, ts store.StoreInterface, from *crypto.CommonAddress) error {
+	token, amount, err := processor.load(context, ts)
+	if err != nil {
+		return err
+	}
+	if token.Issuer != *from {
+		return ErrNotTokenIssuer
+	}
+	supply := new(big.Int).Add(token.Supply.ToInt(), amount)
+	if token.MaxSupply.ToInt().Sign() > 0 && supply.Cmp(token.MaxSupply.ToInt()) > 0 {
+		return types.ErrTokenSupply
+	}
+
+	// mint to the issuer if no receiver given
+	to := context.Tx().To()
+	if *to == (crypto.CommonAddress{}) {
+		to = from
+	}
+	token.Supply = common.Big(*supply)
+	if err := ts.PutToken(token); err != nil {
+		return err
+	}
+	balance := ts.GetTokenBalance(to, token.Symbol)
+	return ts.PutTokenBalance(to, token.Symbol, balance.Add(balance, amount))
+}
+
+func (processor *TokenTransactionProcessor) burn(context *ExecuteTransactionContext, ts store.StoreInterface, from *crypto.CommonAddress) error {
+	token, amount, err := processor.load(context, ts)
+	if err != nil {
+		return err
+	}
+	balance := ts.GetTokenBalance(from, token.Symbol)
+	if balance.Cmp(amount) < 0 {
+		return ErrTokenBalance
+	}
+
+	token.Supply = common.Big(*new(big.Int).Sub(token.Supply.ToInt(), amount))
+	if err := ts.PutToken(token); err != nil {
+		return err
+	}
+	return ts.PutTokenBalance(from, token.Symbol, balance.Sub(balance, amount))
+}
+
+func (processor *TokenTransactionProcessor) transfer(context *ExecuteTransactionContext, ts store.StoreInterface, from *crypto.CommonAddress) error {
+	token, amount, err := processor.load(context, ts)
+	if err != nil {
+		return err
+	}
+	balance := ts.GetTokenBalance(from, token.Symbol)
+	if balance.Cmp(amount) < 0 {
+		return ErrTokenBalance
+	}
+
+	to := context.Tx().To()
+	if *to == *from {
+		return nil
+	}
+	if err := ts.PutTokenBalance(from, token.Symbol, balance.Sub(balance, amount)); err != nil {
+		return err
+	}
+	toBalance := ts.GetTokenBalance(to, token.Symbol)
+	return ts.PutTokenBalance(to, token.Symbol, toBalance.Add(toBalance, amount))
+}
+
+// load decode the amount of the transaction, charge the gas and read the token moved
+func (processor *TokenTransactionProcessor) load(context *ExecuteTransactionContext, ts store.StoreInterface) (*types.Token, *big.Int, error) {
+	data := &types.TokenAmountData{}
+	if err := data.Unmarshal(context.Data()); err != nil {
+		return nil, nil, err
+	}
+	if err := context.UseGas(params.TokenGas); err != nil {
+		return nil, nil, err
+	}
+	token, err := ts.GetToken(data.Symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == nil {
+		return nil, nil, ErrTokenNotFound
+	}
+	return token, data.Amount.ToInt(), nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
 	"math/big"
+	"time"
 )
 
 type ITransactionValidator interface {
@@ -22,24 +23,51 @@ type ExecuteTransactionContext struct {
 	gp          *GasPool
 	tx          *types.Transaction
 	from        *crypto.CommonAddress
+	payer       *crypto.CommonAddress
 	gasPrice    *big.Int
 	value       *big.Int
 	data        []byte
 	header      *types.BlockHeader
+	params      *GenesisParams // chain parameters, nil outside of block execution
 	gasRemained uint64
 	initialGas  uint64
+	deadline    time.Time
 }
 
 func NewExecuteTransactionContext(blockContext *BlockExecuteContext, chainstore store.StoreInterface, gasPool *GasPool, from *crypto.CommonAddress, tx *types.Transaction) *ExecuteTransactionContext {
 	context := &ExecuteTransactionContext{trieStore: chainstore, gp: gasPool, tx: tx, from: from}
 	context.blockContext = blockContext
 	context.from = from
+	context.payer = from
 	context.gasPrice = tx.GasPrice()
 	context.value = tx.Amount()
 	context.data = tx.GetData()
 	context.header = blockContext.Block.Header
 	return context
 }
+
+// SetDeadline set the time the execution of the transaction is aborted at, only block producers
+// set it, a block is always executed to the end when it is verified
+func (context *ExecuteTransactionContext) SetDeadline(deadline time.Time) {
+	context.deadline = deadline
+}
+
+// Deadline return the time the execution is aborted at, zero for no limit
+func (context *ExecuteTransactionContext) Deadline() time.Time {
+	return context.deadline
+}
+
+// SetGenesisParams set the chain parameters the transaction is executed with
+func (context *ExecuteTransactionContext) SetGenesisParams(params *GenesisParams) {
+	context.params = params
+}
+
+// GenesisParams return the chain parameters the transaction is executed with, the forks
+// scheduled in them select the rules at the height of the block
+func (context *ExecuteTransactionContext) GenesisParams() *GenesisParams {
+	return context.params
+}
+
 func (context *ExecuteTransactionContext) Header() *types.BlockHeader {
 	return context.header
 }
@@ -72,6 +100,11 @@ func (context *ExecuteTransactionContext) From() *crypto.CommonAddress {
 	return context.from
 }
 
+// FeePayer return the account paying the gas, the sender unless the transaction is sponsored
+func (context *ExecuteTransactionContext) FeePayer() *crypto.CommonAddress {
+	return context.payer
+}
+
 func (context *ExecuteTransactionContext) Tx() *types.Transaction {
 	return context.tx
 }
@@ -87,7 +120,7 @@ func (context *ExecuteTransactionContext) TrieStore() store.StoreInterface {
 func (context *ExecuteTransactionContext) RefundCoin() error {
 	// Return DREP for remaining gasRemained, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(context.gasRemained), context.gasPrice)
-	err := context.trieStore.AddBalance(context.from, context.header.Height, remaining)
+	err := context.trieStore.AddBalance(context.payer, context.header.Height, remaining)
 	if err != nil {
 		return nil
 	}
@@ -120,7 +153,7 @@ func (context *ExecuteTransactionContext) UseGas(amount uint64) error {
 
 func (context *ExecuteTransactionContext) buyGas() error {
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(context.tx.Gas()), context.gasPrice)
-	if context.trieStore.GetBalance(context.from, context.header.Height).Cmp(mgval) < 0 {
+	if context.trieStore.GetBalance(context.payer, context.header.Height).Cmp(mgval) < 0 {
 		return ErrInsufficientBalanceForGas
 	}
 	if err := context.gp.SubGas(context.tx.Gas()); err != nil {
@@ -129,7 +162,7 @@ func (context *ExecuteTransactionContext) buyGas() error {
 	context.gasRemained += context.tx.Gas()
 
 	context.initialGas = context.tx.Gas()
-	return context.trieStore.SubBalance(context.from, context.header.Height, mgval)
+	return context.trieStore.SubBalance(context.payer, context.header.Height, mgval)
 }
 
 func (context *ExecuteTransactionContext) PreCheck() error {
@@ -142,5 +175,14 @@ func (context *ExecuteTransactionContext) PreCheck() error {
 		log.WithField("db nonce", nonce).WithField("tx nonce", context.tx.Nonce()).WithField("from", context.from.String()).Info("state precheck too low")
 		return ErrNonceTooLow
 	}
+	// Blocks below the fork were invalid with the signature of a fee payer appended
+	if context.tx.IsSponsored() && !context.params.IsSponsorFork(context.header.Height) {
+		return ErrSponsorNotActive
+	}
+	payer, err := context.tx.FeePayer()
+	if err != nil {
+		return err
+	}
+	context.payer = payer
 	return context.buyGas()
 }
@@ -0,0 +1,92 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+// Transaction lookup
+//
+// The block hash and index of every executed transaction is written with its receipt. A
+// transaction of a block reorganized out of the main chain keeps the entry of that block, it
+// is only reported while the block is in the main chain. Transactions executed before the
+// lookup existed are located from their receipt.
+
+var TxLookupPrefix = []byte("txLookup_")
+
+func txLookupKey(txHash *crypto.Hash) []byte {
+	key := make([]byte, len(TxLookupPrefix)+crypto.HashLength)
+	copy(key, TxLookupPrefix)
+	copy(key[len(TxLookupPrefix):], txHash[:])
+	return key
+}
+
+// PutTxLookup record the transaction txHash at index in the block blockHash
+func (chainStore *ChainStore) PutTxLookup(txHash, blockHash *crypto.Hash, index int) error {
+	value := make([]byte, crypto.HashLength+8)
+	copy(value, blockHash[:])
+	binary.BigEndian.PutUint64(value[crypto.HashLength:], uint64(index))
+	return chainStore.Put(txLookupKey(txHash), value)
+}
+
+// GetTxLookup return the block hash and index last recorded for the transaction txHash
+func (chainStore *ChainStore) GetTxLookup(txHash *crypto.Hash) (*crypto.Hash, int, error) {
+	value, err := chainStore.Get(txLookupKey(txHash))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(value) != crypto.HashLength+8 {
+		return nil, 0, ErrTxNotFound
+	}
+	blockHash := &crypto.Hash{}
+	blockHash.SetBytes(value[:crypto.HashLength])
+	return blockHash, int(binary.BigEndian.Uint64(value[crypto.HashLength:])), nil
+}
+
+// SetPendingTxLookup set the function reading transactions of the pool, transactions not in
+// the main chain are looked up with it
+func (chainService *ChainService) SetPendingTxLookup(lookup func(txHash crypto.Hash) *types.Transaction) {
+	chainService.pendingTx = lookup
+}
+
+// LookupTransaction return the main chain transaction txHash with its block node and index
+// in the block
+func (chainService *ChainService) LookupTransaction(txHash crypto.Hash) (*types.Transaction, *types.BlockNode, int, error) {
+	blockHash, index, err := chainService.chainStore.GetTxLookup(&txHash)
+	if err != nil {
+		receipt := chainService.chainStore.GetReceipt(txHash)
+		if receipt == nil {
+			return nil, nil, 0, ErrTxNotFound
+		}
+		blockHash, index = &receipt.BlockHash, -1
+	}
+	node := chainService.blockIndex.LookupNode(blockHash)
+	if node == nil || !chainService.BestChain().Contains(node) {
+		return nil, nil, 0, ErrTxNotFound
+	}
+	block, err := chainService.GetBlockByHash(node.Hash)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if index < 0 {
+		for i, tx := range block.Data.TxList {
+			if *tx.TxHash() == txHash {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 || index >= len(block.Data.TxList) || *block.Data.TxList[index].TxHash() != txHash {
+		return nil, nil, 0, ErrTxNotFound
+	}
+	return block.Data.TxList[index], node, index, nil
+}
+
+// PendingTransaction return the transaction txHash of the pool, nil if it is not there
+func (chainService *ChainService) PendingTransaction(txHash crypto.Hash) *types.Transaction {
+	if chainService.pendingTx == nil {
+		return nil
+	}
+	return chainService.pendingTx(txHash)
+}
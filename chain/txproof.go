@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// TxProof proves that a transaction is included in a block of the main chain,
+// Leaf hashed up with Proof must give the TxRoot of the block header.
+type TxProof struct {
+	TxHash    crypto.Hash    `json:"txHash"`
+	BlockHash crypto.Hash    `json:"blockHash"`
+	Height    uint64         `json:"height"`
+	TxRoot    common.Bytes   `json:"txRoot"`
+	Index     int            `json:"index"`
+	Leaf      common.Bytes   `json:"leaf"`
+	Proof     []common.Bytes `json:"proof"`
+}
+
+// Verify checks the proof against its TxRoot.
+func (proof *TxProof) Verify() bool {
+	siblings := make([][]byte, len(proof.Proof))
+	for i, p := range proof.Proof {
+		siblings[i] = p
+	}
+	return common.VerifyMerkleProof(proof.TxRoot, proof.Leaf, proof.Index, siblings)
+}
+
+// GetTxProof builds the merkle inclusion proof of a transaction on the main chain.
+func (chainService *ChainService) GetTxProof(txHash crypto.Hash) (*TxProof, error) {
+	receipt := chainService.chainStore.GetReceipt(txHash)
+	if receipt == nil {
+		return nil, ErrTxNotFound
+	}
+	node := chainService.BestChain().NodeByHeight(receipt.BlockNumber)
+	if node == nil || *node.Hash != receipt.BlockHash {
+		// the block was reorganized out of the main chain
+		return nil, ErrTxNotFound
+	}
+	block, err := chainService.GetBlockByHash(node.Hash)
+	if err != nil {
+		return nil, err
+	}
+	for i, tx := range block.Data.TxList {
+		if *tx.TxHash() != txHash {
+			continue
+		}
+		leaf, siblings, err := chainService.DeriveMerkleProof(block.Header.Height, block.Data.TxList, i)
+		if err != nil {
+			return nil, err
+		}
+		proof := &TxProof{
+			TxHash:    txHash,
+			BlockHash: *node.Hash,
+			Height:    block.Header.Height,
+			TxRoot:    block.Header.TxRoot,
+			Index:     i,
+			Leaf:      leaf,
+			Proof:     make([]common.Bytes, len(siblings)),
+		}
+		for j, sibling := range siblings {
+			proof.Proof[j] = sibling
+		}
+		return proof, nil
+	}
+	return nil, ErrTxNotFound
+}
@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+func newTxRootBlock(height uint64, txs []*types.Transaction, root []byte) *types.Block {
+	return &types.Block{
+		Header: &types.BlockHeader{Height: height, TxRoot: root},
+		Data:   &types.BlockData{TxCount: uint64(len(txs)), TxList: txs},
+	}
+}
+
+// TestTxRootFork checks blocks below the fork keep the tx root over the tx data
+// and blocks from the fork on use the full tx encoding
+func TestTxRootFork(t *testing.T) {
+	fork := uint64(100)
+	chainService := &ChainService{chainID: 1, genesisParams: &GenesisParams{TxRootFork: &fork}}
+	validator := NewChainBlockValidator(chainService)
+
+	txs := make([]*types.Transaction, 3)
+	for i := range txs {
+		txs[i] = types.NewTransaction(crypto.CommonAddress{byte(i)}, big.NewInt(int64(i)), big.NewInt(1), big.NewInt(21000), uint64(i))
+		txs[i].Data.ChainId = 1
+		txs[i].Sig = []byte{byte(i), 1, 2, 3}
+	}
+	dataLeaves := make([][]byte, len(txs))
+	fullLeaves := make([][]byte, len(txs))
+	for i, tx := range txs {
+		data, err := binary.Marshal(tx.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataLeaves[i] = sha3.Keccak256(data)
+		fullLeaves[i] = sha3.Keccak256(tx.AsPersistentMessage())
+	}
+	preForkRoot := common.NewMerkle(dataLeaves).Root.Hash
+	forkRoot := common.NewMerkle(fullLeaves).Root.Hash
+	if bytes.Equal(preForkRoot, forkRoot) {
+		t.Fatal("tx roots before and after the fork are the same")
+	}
+
+	if err := validator.VerifyBody(newTxRootBlock(fork-1, txs, preForkRoot)); err != nil {
+		t.Errorf("pre-fork block: %v", err)
+	}
+	if err := validator.VerifyBody(newTxRootBlock(fork-1, txs, forkRoot)); err == nil {
+		t.Error("pre-fork block with the fork tx root passed")
+	}
+	if err := validator.VerifyBody(newTxRootBlock(fork, txs, forkRoot)); err != nil {
+		t.Errorf("fork block: %v", err)
+	}
+	if err := validator.VerifyBody(newTxRootBlock(fork, txs, preForkRoot)); err == nil {
+		t.Error("fork block with the pre-fork tx root passed")
+	}
+
+	// networks not scheduling the fork keep the tx data leaves
+	chainService.genesisParams = &GenesisParams{}
+	if err := validator.VerifyBody(newTxRootBlock(fork*10, txs, preForkRoot)); err != nil {
+		t.Errorf("unscheduled fork: %v", err)
+	}
+}
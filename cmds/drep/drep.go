@@ -10,12 +10,15 @@ import (
 	"github.com/drep-project/DREP-Chain/database"
 	p2pService "github.com/drep-project/DREP-Chain/network/service"
 	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
+	bridgeService "github.com/drep-project/DREP-Chain/pkgs/bridge"
 	chainIndexerService "github.com/drep-project/DREP-Chain/pkgs/chain_indexer"
 	consensusService "github.com/drep-project/DREP-Chain/pkgs/consensus/service"
 	cliService "github.com/drep-project/DREP-Chain/pkgs/drepclient/service"
 	evmService "github.com/drep-project/DREP-Chain/pkgs/evm"
 	filterService "github.com/drep-project/DREP-Chain/pkgs/filter"
+	journalService "github.com/drep-project/DREP-Chain/pkgs/journal"
 	logServer "github.com/drep-project/DREP-Chain/pkgs/log"
+	reputationService "github.com/drep-project/DREP-Chain/pkgs/reputation"
 	"github.com/drep-project/DREP-Chain/pkgs/rpc"
 	"github.com/drep-project/DREP-Chain/pkgs/trace"
 	"github.com/drep-project/binary"
@@ -37,7 +40,10 @@ func main() {
 		evmService.EvmService{},
 		chainIndexerService.ChainIndexerService{},
 		filterService.FilterService{},
+		journalService.JournalService{},
 		accountService.AccountService{},
+		bridgeService.BridgeService{},
+		reputationService.ReputationService{},
 		consensusService.ConsensusService{},
 		trace.TraceService{},
 		cliService.CliService{},
@@ -139,6 +139,7 @@ func init() {
 	methods["account_cancelVoteCredit"] = cancelVoteCredit
 	methods["account_candidateCredit"] = candidateCredit
 	methods["account_cancelCandidateCredit"] = cancelCandidateCredit
+	methods["stake_registerCandidateAuto"] = registerCandidateAuto
 
 	methods["account_readContract"] = readContract
 	methods["account_estimateGas"] = estimateGas
@@ -148,6 +149,8 @@ func init() {
 	methods["account_dumpPubkey"] = dumpPubkey
 	methods["account_sign"] = sign
 	methods["account_generateAddresses"] = generateAddresses
+	methods["account_generateAddress"] = generateAddress
+	methods["account_getSupportedChains"] = getSupportedChains
 	methods["account_importKeyStore"] = importKeyStore
 	methods["account_importPrivkey"] = importPrivkey
 	methods["account_getKeyStores"] = getKeyStores
@@ -267,11 +270,11 @@ func getBlockGasInfo(args cli.Args, client *rpc.Client, ctx context.Context) {
 
 func getNonce(args cli.Args, client *rpc.Client, ctx context.Context) {
 	var resp uint64
-	if err := argsJudge(args, 2); err != nil {
-		fmt.Println(err.Error())
+	if len(args) != 2 && len(args) != 3 {
+		fmt.Println(argsJudge(args, 2).Error())
 		return
 	}
-	if err := client.CallContext(ctx, &resp, args[0], args[1]); err != nil {
+	if err := client.CallContext(ctx, &resp, args[0], blockRefArgs(args)...); err != nil {
 		fmt.Println("return err :", err)
 		return
 	}
@@ -705,6 +708,18 @@ func cancelCandidateCredit(args cli.Args, client *rpc.Client, ctx context.Contex
 	}
 	fmt.Println(resp)
 }
+func registerCandidateAuto(args cli.Args, client *rpc.Client, ctx context.Context) {
+	var resp string
+	if err := argsJudge(args, 4); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if err := client.CallContext(ctx, &resp, args[0], args[1], args[2], args[3]); err != nil {
+		fmt.Println("return err :", err)
+		return
+	}
+	fmt.Println(resp)
+}
 func readContract(args cli.Args, client *rpc.Client, ctx context.Context) {
 	var resp common.Bytes //common.Bytes, error
 		if err := argsJudge(args, 4); err != nil {
@@ -780,6 +795,26 @@ func generateAddresses(args cli.Args, client *rpc.Client, ctx context.Context) {
 	}
 	fmt.Println(resp)
 }
+func generateAddress(args cli.Args, client *rpc.Client, ctx context.Context) {
+	var resp string
+	if err := argsJudge(args, 3); err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if err := client.CallContext(ctx, &resp, args[0], args[1], args[2]); err != nil {
+		fmt.Println("return err :", err)
+		return
+	}
+	fmt.Println(resp)
+}
+func getSupportedChains(args cli.Args, client *rpc.Client, ctx context.Context) {
+	resp := make([]string, 0)
+	if err := client.CallContext(ctx, &resp, args[0]); err != nil {
+		fmt.Println("return err :", err)
+		return
+	}
+	fmt.Println(resp)
+}
 func importKeyStore(args cli.Args, client *rpc.Client, ctx context.Context) {
 	resp := make([]string, 0) //([]string, error)
 		if err := argsJudge(args, 3); err != nil {
@@ -834,13 +869,26 @@ func getMiners(args cli.Args, client *rpc.Client, ctx context.Context) {
 
 func getBalance(args cli.Args, client *rpc.Client, ctx context.Context) {
 	var resp string
-	if err := client.CallContext(ctx, &resp, args[0], args[1]); err != nil {
+	if len(args) != 2 && len(args) != 3 {
+		fmt.Println(argsJudge(args, 2).Error())
+		return
+	}
+	if err := client.CallContext(ctx, &resp, args[0], blockRefArgs(args)...); err != nil {
 		fmt.Println("return err :", err)
 	}
 
 	fmt.Println(resp)
 }
 
+// blockRefArgs is the address and the optional block height or hash of a state query
+func blockRefArgs(args cli.Args) []interface{} {
+	params := []interface{}{args[1]}
+	if len(args) == 3 {
+		params = append(params, args[2])
+	}
+	return params
+}
+
 func createCode(args cli.Args, client *rpc.Client, ctx context.Context) {
 	var resp string
 	if err := argsJudge(args, 5); err != nil {
@@ -163,6 +163,17 @@ func gen(ctx *cli.Context) error {
 	filterConfig.Enable = true
 
 	Preminers := []*chain.Preminer{{Addr: getPreminer(), Value: cfg.Preminer[0].Value}}
+	genesis := struct {
+		ChainId   types.ChainIdType `json:"chainId"`
+		Consensus string            `json:"consensus"`
+		Preminer  []*chain.Preminer
+		Miners    []types.CandidateData
+	}{
+		ChainId:   chainConfig.ChainId,
+		Consensus: consensusConfig.ConsensusMode,
+		Preminer:  Preminers,
+		Miners:    produces,
+	}
 
 	if len(nodeItems) == 1 {
 		consensusConfig.Solo.MyPk = (*secp256k1.PublicKey)(&standbyKey[0].PublicKey)
@@ -200,16 +211,10 @@ func gen(ctx *cli.Context) error {
 		offset = writePhase(fs, "chain_indexer", chainIndexerConfig, offset)
 		offset = writePhase(fs, "filter", filterConfig, offset)
 
-		offset = writePhase(fs, "genesis",
-			struct {
-				Preminer []*chain.Preminer
-				Miners   []types.CandidateData
-			}{
-				Preminer: Preminers,
-				Miners:   produces,
-			}, offset)
+		offset = writePhase(fs, "genesis", genesis, offset)
 		fs.Truncate(offset - 2)
 		fs.WriteAt([]byte("\n}"), offset-2)
+		writeGenesisFile(userDir, genesis)
 	} else {
 		for i := 0; i < len(nodeItems); i++ {
 			consensusConfig.Bft.MyPk = (*secp256k1.PublicKey)(&standbyKey[i].PublicKey)
@@ -245,16 +250,10 @@ func gen(ctx *cli.Context) error {
 			offset = writePhase(fs, "chain_indexer", chainIndexerConfig, offset)
 			offset = writePhase(fs, "filter", filterConfig, offset)
 
-			offset = writePhase(fs, "genesis",
-				struct {
-					Preminer []*chain.Preminer
-					Miners   []types.CandidateData
-				}{
-					Preminer: Preminers,
-					Miners:   produces,
-				}, offset)
+			offset = writePhase(fs, "genesis", genesis, offset)
 			fs.Truncate(offset - 2)
 			fs.WriteAt([]byte("\n}"), offset-2)
+			writeGenesisFile(userDir, genesis)
 		}
 	}
 	return nil
@@ -282,6 +281,12 @@ func writePhase(fs *os.File, name string, config interface{}, offset int64) int6
 	return offset
 }
 
+// writeGenesisFile write genesis to data dir, nodes use it instead of the genesis section in config.json
+func writeGenesisFile(userDir string, genesis interface{}) {
+	bytes, _ := json.MarshalIndent(genesis, "", "	")
+	ioutil.WriteFile(path2.Join(userDir, chain.GenesisFileName), bytes, 0644)
+}
+
 func getAccount(name string) *types.Node {
 	node := RandomNode([]byte(name))
 	return node
@@ -132,7 +132,8 @@ func UnmarshalFixedUnprefixedText(typname string, input, out []byte) error {
 //
 // Negative integers are not supported at this time. Attempting to marshal them will
 // return an error. Values larger than 256bits are rejected by Unmarshal but will be
-// marshaled without error.
+// marshaled without error. With EnableUnitAmounts, Unmarshal also accepts amounts
+// with units such as "1.5drep".
 type Big mathBig.Int
 
 func (m *Big) SetMathBig(v mathBig.Int) {
@@ -173,6 +174,14 @@ func (b *Big) UnmarshalJSON(input []byte) error {
 
 // UnmarshalText implements encoding.TextUnmarshaler
 func (b *Big) UnmarshalText(input []byte) error {
+	if UnitAmountsEnabled() && len(input) > 0 && !bytesHave0xPrefix(input) {
+		wei, err := ParseAmount(string(input))
+		if err != nil {
+			return err
+		}
+		*b = (Big)(*wei)
+		return nil
+	}
 	raw, err := checkNumberText(input)
 	if err != nil {
 		return err
@@ -2,6 +2,7 @@ package common
 
 import (
 	"bytes"
+	"errors"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
 	"math"
 )
@@ -132,3 +133,41 @@ func (m *Merkle) IsOnTrie(hash []byte) bool {
 	}
 	return false
 }
+
+// Proof returns the authorization path of the leaf at index, from the leaf
+// level up to the root. An empty entry marks a level where the node had no
+// neighbour and was hashed on its own.
+func (m *Merkle) Proof(index int) ([][]byte, error) {
+	if index < 0 || index >= len(m.Leaves) {
+		return nil, errors.New("merkle leaf index out of range")
+	}
+	proof := [][]byte{}
+	for node := m.Leaves[index]; node.Parent != nil; node = node.Parent {
+		if node.Neighbour != nil {
+			proof = append(proof, node.Neighbour.Hash)
+		} else {
+			proof = append(proof, []byte{})
+		}
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof checks that leaf is committed to by root at position index.
+func VerifyMerkleProof(root, leaf []byte, index int, proof [][]byte) bool {
+	if index < 0 {
+		return false
+	}
+	h := leaf
+	for _, sibling := range proof {
+		switch {
+		case len(sibling) == 0:
+			h = sha3.HashS256(h)
+		case index%2 == 0:
+			h = sha3.HashS256(h, sibling)
+		default:
+			h = sha3.HashS256(sibling, h)
+		}
+		index /= 2
+	}
+	return bytes.Equal(h, root)
+}
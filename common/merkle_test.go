@@ -0,0 +1,33 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+)
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = sha3.Keccak256([]byte{byte(n), byte(i)})
+		}
+		merkle := NewMerkle(leaves)
+		for i := range leaves {
+			proof, err := merkle.Proof(i)
+			if err != nil {
+				t.Fatalf("n=%d i=%d: %v", n, i, err)
+			}
+			if !VerifyMerkleProof(merkle.Root.Hash, leaves[i], i, proof) {
+				t.Errorf("n=%d i=%d: valid proof rejected", n, i)
+			}
+			if VerifyMerkleProof(merkle.Root.Hash, leaves[(i+1)%n], i, proof) && !bytes.Equal(leaves[i], leaves[(i+1)%n]) {
+				t.Errorf("n=%d i=%d: proof accepted for another leaf", n, i)
+			}
+		}
+		if _, err := merkle.Proof(n); err == nil {
+			t.Errorf("n=%d: expected out of range error", n)
+		}
+	}
+}
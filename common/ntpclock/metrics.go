@@ -0,0 +1,8 @@
+package ntpclock
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	// driftGauge is the last measured drift of the local clock in milliseconds
+	driftGauge = metrics.NewRegisteredGauge("ntp/drift", nil)
+)
@@ -0,0 +1,249 @@
+// Package ntpclock measures the drift of the local clock against NTP servers.
+//
+// Block timestamps must increase and are checked against the parent block, a
+// producer with a skewed clock stamps blocks the others reject or that push the
+// chain time ahead. The monitor samples the configured servers periodically,
+// warns when the drift exceeds the warning threshold and reports the clock as
+// dangerous over the max drift, block producers skip their turn then.
+package ntpclock
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
+)
+
+const (
+	MODULENAME = "ntp"
+
+	// DefaultInterval is the default seconds between two measurements
+	DefaultInterval = 600
+	// DefaultWarnDrift is the default milliseconds of drift logged as a warning
+	DefaultWarnDrift = 1000
+	// DefaultMaxDrift is the default milliseconds of drift block production is refused over
+	DefaultMaxDrift = 5000
+
+	ntpChecks  = 3               // measurements per server, the median is taken
+	ntpTimeout = 5 * time.Second // time waiting for the reply of a server
+)
+
+var (
+	log = dlog.EnsureLogger(MODULENAME)
+
+	// DefaultServers are the NTP servers sampled when none is configured
+	DefaultServers = []string{"pool.ntp.org", "time.google.com", "time.cloudflare.com"}
+
+	ErrNoServer = errors.New("no ntp server answered")
+
+	defaultMonitor atomic.Value
+)
+
+// Config of the clock drift monitor, zero values use the defaults
+type Config struct {
+	Disable   bool     `json:"disable,omitempty"`
+	Servers   []string `json:"servers,omitempty"`
+	Interval  int      `json:"interval,omitempty"`  //Seconds between two measurements
+	WarnDrift int      `json:"warnDrift,omitempty"` //Milliseconds of drift logged as a warning
+	MaxDrift  int      `json:"maxDrift,omitempty"`  //Milliseconds of drift block production is refused over
+}
+
+// Status is the last measured drift of the local clock
+type Status struct {
+	Drift     int64  `json:"drift"`           //Milliseconds the local clock is ahead, negative when behind
+	Measured  int64  `json:"measured"`        //Unix time of the measurement, 0 before the first one
+	Servers   int    `json:"servers"`         //Servers answered in the measurement
+	Dangerous bool   `json:"dangerous"`       //Drift over the max drift, block production is refused
+	Error     string `json:"error,omitempty"` //Error of the last measurement, the drift is kept from the one before
+}
+
+// Monitor measure the clock drift periodically
+type Monitor struct {
+	config Config
+	query  func(server string) (time.Duration, error)
+
+	lock    sync.RWMutex
+	status  Status
+	quit    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// NewMonitor create a monitor of config, it measures once started
+func NewMonitor(config Config) *Monitor {
+	if len(config.Servers) == 0 {
+		config.Servers = DefaultServers
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.WarnDrift <= 0 {
+		config.WarnDrift = DefaultWarnDrift
+	}
+	if config.MaxDrift <= 0 {
+		config.MaxDrift = DefaultMaxDrift
+	}
+	return &Monitor{config: config, query: sntpDrift}
+}
+
+// Start measure the drift now and every interval until stopped
+func (monitor *Monitor) Start() {
+	monitor.quit = make(chan struct{})
+	monitor.stopped.Add(1)
+	go func() {
+		defer monitor.stopped.Done()
+		timer := time.NewTicker(time.Duration(monitor.config.Interval) * time.Second)
+		defer timer.Stop()
+		for {
+			monitor.Check()
+			select {
+			case <-timer.C:
+			case <-monitor.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stop the periodic measurement
+func (monitor *Monitor) Stop() {
+	if monitor.quit == nil {
+		return
+	}
+	close(monitor.quit)
+	monitor.stopped.Wait()
+	monitor.quit = nil
+}
+
+// Check measure the drift against the servers, the median of the servers answering is taken
+func (monitor *Monitor) Check() (time.Duration, error) {
+	drifts := []time.Duration{}
+	for _, server := range monitor.config.Servers {
+		drift, err := monitor.query(server)
+		if err != nil {
+			log.WithField("server", server).WithField("Reason", err).Debug("query ntp server fail")
+			continue
+		}
+		drifts = append(drifts, drift)
+	}
+
+	monitor.lock.Lock()
+	defer monitor.lock.Unlock()
+	if len(drifts) == 0 {
+		monitor.status.Error = ErrNoServer.Error()
+		return time.Duration(monitor.status.Drift) * time.Millisecond, ErrNoServer
+	}
+	drift := median(drifts)
+	monitor.status = Status{
+		Drift:     int64(drift / time.Millisecond),
+		Measured:  time.Now().Unix(),
+		Servers:   len(drifts),
+		Dangerous: abs(drift) > time.Duration(monitor.config.MaxDrift)*time.Millisecond,
+	}
+	driftGauge.Update(monitor.status.Drift)
+	switch {
+	case monitor.status.Dangerous:
+		log.WithField("drift", drift).WithField("max", time.Duration(monitor.config.MaxDrift)*time.Millisecond).Error("System clock is off too far, block production is refused, please enable network time synchronisation")
+	case abs(drift) > time.Duration(monitor.config.WarnDrift)*time.Millisecond:
+		log.WithField("drift", drift).Warn("System clock seems off, blocks produced may be rejected, please enable network time synchronisation")
+	default:
+		log.WithField("drift", drift).Debug("NTP sanity check done")
+	}
+	return drift, nil
+}
+
+// Status return the last measurement
+func (monitor *Monitor) Status() *Status {
+	monitor.lock.RLock()
+	defer monitor.lock.RUnlock()
+	status := monitor.status
+	return &status
+}
+
+// Dangerous whether the last measured drift is over the max drift
+func (monitor *Monitor) Dangerous() bool {
+	monitor.lock.RLock()
+	defer monitor.lock.RUnlock()
+	return monitor.status.Dangerous
+}
+
+// SetDefault set the monitor of the process read by Current and Dangerous
+func SetDefault(monitor *Monitor) {
+	defaultMonitor.Store(monitor)
+}
+
+// Current return the last measurement of the monitor of the process, nil if there is none
+func Current() *Status {
+	monitor, _ := defaultMonitor.Load().(*Monitor)
+	if monitor == nil {
+		return nil
+	}
+	return monitor.Status()
+}
+
+// Dangerous whether the clock of the process is off too far to produce blocks, false when it
+// is not monitored
+func Dangerous() bool {
+	monitor, _ := defaultMonitor.Load().(*Monitor)
+	return monitor != nil && monitor.Dangerous()
+}
+
+// sntpDrift measure the drift against server with the simple version of NTP, it is not precise
+// but fine for these purposes. The median of a few measurements is taken
+func sntpDrift(server string) (time.Duration, error) {
+	addr, err := net.ResolveUDPAddr("udp", net.JoinHostPort(server, "123"))
+	if err != nil {
+		return 0, err
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// Construct the time request (empty package with only 2 fields set):
+	//   Bits 3-5: Protocol version, 3
+	//   Bits 6-8: Mode of operation, client, 3
+	request := make([]byte, 48)
+	request[0] = 3<<3 | 3
+
+	drifts := []time.Duration{}
+	for i := 0; i < ntpChecks; i++ {
+		sent := time.Now()
+		if _, err = conn.Write(request); err != nil {
+			return 0, err
+		}
+		conn.SetDeadline(time.Now().Add(ntpTimeout))
+		reply := make([]byte, 48)
+		if _, err = conn.Read(reply); err != nil {
+			return 0, err
+		}
+		elapsed := time.Since(sent)
+		// Calculate the drift based on an assumed answer time of RRT/2
+		drifts = append(drifts, sent.Sub(replyTime(reply))+elapsed/2)
+	}
+	return median(drifts), nil
+}
+
+// replyTime return the transmit time of an NTP reply
+func replyTime(reply []byte) time.Time {
+	sec := uint64(reply[43]) | uint64(reply[42])<<8 | uint64(reply[41])<<16 | uint64(reply[40])<<24
+	frac := uint64(reply[47]) | uint64(reply[46])<<8 | uint64(reply[45])<<16 | uint64(reply[44])<<24
+	nanosec := sec*1e9 + (frac*1e9)>>32
+	return time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(nanosec))
+}
+
+func median(drifts []time.Duration) time.Duration {
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i] < drifts[j] })
+	return drifts[len(drifts)/2]
+}
+
+func abs(drift time.Duration) time.Duration {
+	if drift < 0 {
+		return -drift
+	}
+	return drift
+}
@@ -0,0 +1,63 @@
+package ntpclock
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckMedianDrift(t *testing.T) {
+	drifts := map[string]time.Duration{
+		"a": 200 * time.Millisecond,
+		"b": 6 * time.Second,
+		"c": 300 * time.Millisecond,
+	}
+	monitor := NewMonitor(Config{Servers: []string{"a", "b", "c", "d"}})
+	monitor.query = func(server string) (time.Duration, error) {
+		if drift, ok := drifts[server]; ok {
+			return drift, nil
+		}
+		return 0, errors.New("timeout")
+	}
+
+	// the server far off is outvoted, the one not answering is skipped
+	drift, err := monitor.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift != 300*time.Millisecond {
+		t.Fatalf("got drift %v, want 300ms", drift)
+	}
+	if status := monitor.Status(); status.Servers != 3 || status.Dangerous {
+		t.Fatalf("got %d servers dangerous %v, want 3 servers not dangerous", status.Servers, status.Dangerous)
+	}
+
+	drifts["a"], drifts["c"] = -7*time.Second, -8*time.Second
+	if _, err := monitor.Check(); err != nil {
+		t.Fatal(err)
+	}
+	if !monitor.Dangerous() {
+		t.Fatal("clock 7s behind not dangerous")
+	}
+
+	// no server answering keeps the last drift
+	monitor.config.Servers = []string{"d"}
+	if _, err := monitor.Check(); err != ErrNoServer {
+		t.Fatalf("got %v, want %v", err, ErrNoServer)
+	}
+	if status := monitor.Status(); status.Drift != -7000 || !status.Dangerous || status.Error == "" {
+		t.Fatalf("got drift %dms dangerous %v error %q after failed check", status.Drift, status.Dangerous, status.Error)
+	}
+}
+
+func TestReplyTime(t *testing.T) {
+	// 2019-01-01 00:00:00.5 UTC in NTP era 0
+	reply := make([]byte, 48)
+	sec := uint32(3755289600)
+	reply[40], reply[41], reply[42], reply[43] = byte(sec>>24), byte(sec>>16), byte(sec>>8), byte(sec)
+	reply[44] = 0x80
+	want := time.Date(2019, 1, 1, 0, 0, 0, 5e8, time.UTC)
+	if got := replyTime(reply); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -30,15 +30,18 @@ import (
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+var (
+	memcacheCleanHitMeter   = metrics.NewRegisteredMeter("trie/memcache/clean/hit", nil)
+	memcacheCleanMissMeter  = metrics.NewRegisteredMeter("trie/memcache/clean/miss", nil)
+	memcacheCleanReadMeter  = metrics.NewRegisteredMeter("trie/memcache/clean/read", nil)
+	memcacheCleanWriteMeter = metrics.NewRegisteredMeter("trie/memcache/clean/write", nil)
+)
+
 //var (
-//	memcacheCleanHitMeter   = metrics.NewRegisteredMeter("trie/memcache/clean/hit", nil)
-//	memcacheCleanMissMeter  = metrics.NewRegisteredMeter("trie/memcache/clean/miss", nil)
-//	memcacheCleanReadMeter  = metrics.NewRegisteredMeter("trie/memcache/clean/read", nil)
-//	memcacheCleanWriteMeter = metrics.NewRegisteredMeter("trie/memcache/clean/write", nil)
-//
 //	memcacheFlushTimeTimer  = metrics.NewRegisteredResettingTimer("trie/memcache/flush/time", nil)
 //	memcacheFlushNodesMeter = metrics.NewRegisteredMeter("trie/memcache/flush/nodes", nil)
 //	memcacheFlushSizeMeter  = metrics.NewRegisteredMeter("trie/memcache/flush/size", nil)
@@ -304,17 +307,42 @@ func NewDatabase(diskdb dbinterface.KeyValueStore) *Database {
 // before its written out to disk or garbage collected. It also acts as a read cache
 // for nodes loaded from disk.
 func NewDatabaseWithCache(diskdb dbinterface.KeyValueStore, cache int) *Database {
-	var cleans *bigcache.BigCache
-	if cache > 0 {
-		cleans, _ = bigcache.NewBigCache(bigcache.Config{
-			Shards:             1024,
-			LifeWindow:         time.Hour,
-			MaxEntriesInWindow: cache * 1024,
-			MaxEntrySize:       512,
-			HardMaxCacheSize:   cache,
-			Hasher:             trienodeHasher{},
-		})
+	return NewDatabaseWithCleans(diskdb, NewCleanCache(cache))
+}
+
+// NewCleanCache creates a read cache of the given megabytes for clean nodes, as
+// nodes are keyed by hash, one cache can be shared by many trie databases over
+// the same disk database. Nil is returned if cache is not positive.
+func NewCleanCache(cache int) *bigcache.BigCache {
+	if cache <= 0 {
+		return nil
 	}
+	cleans, _ := bigcache.NewBigCache(bigcache.Config{
+		Shards:             1024,
+		LifeWindow:         time.Hour,
+		MaxEntriesInWindow: cache * 1024,
+		MaxEntrySize:       512,
+		HardMaxCacheSize:   cache,
+		Hasher:             trienodeHasher{},
+	})
+	return cleans
+}
+
+// NodeChildren returns the hashes of the nodes referenced by the encoded node,
+// used to walk a trie whose nodes are kept outside of a Database.
+func NodeChildren(hash crypto.Hash, blob []byte) ([]crypto.Hash, error) {
+	n, err := decodeNode(hash[:], blob)
+	if err != nil {
+		return nil, err
+	}
+	var children []crypto.Hash
+	gatherChildren(simplifyNode(n), &children)
+	return children, nil
+}
+
+// NewDatabaseWithCleans creates a new trie database using the given read cache
+// for nodes loaded from disk.
+func NewDatabaseWithCleans(diskdb dbinterface.KeyValueStore, cleans *bigcache.BigCache) *Database {
 	return &Database{
 		diskdb: diskdb,
 		cleans: cleans,
@@ -390,8 +418,8 @@ func (db *Database) node(hash crypto.Hash) node {
 	// Retrieve the node from the clean cache if available
 	if db.cleans != nil {
 		if enc, err := db.cleans.Get(string(hash[:])); err == nil && enc != nil {
-			//memcacheCleanHitMeter.Mark(1)
-			//memcacheCleanReadMeter.Mark(int64(len(enc)))
+			memcacheCleanHitMeter.Mark(1)
+			memcacheCleanReadMeter.Mark(int64(len(enc)))
 			return mustDecodeNode(hash[:], enc)
 		}
 	}
@@ -411,8 +439,8 @@ func (db *Database) node(hash crypto.Hash) node {
 	}
 	if db.cleans != nil {
 		db.cleans.Set(string(hash[:]), enc)
-		//memcacheCleanMissMeter.Mark(1)
-		//memcacheCleanWriteMeter.Mark(int64(len(enc)))
+		memcacheCleanMissMeter.Mark(1)
+		memcacheCleanWriteMeter.Mark(int64(len(enc)))
 	}
 	return mustDecodeNode(hash[:], enc)
 }
@@ -427,8 +455,8 @@ func (db *Database) Node(hash crypto.Hash) ([]byte, error) {
 	// Retrieve the node from the clean cache if available
 	if db.cleans != nil {
 		if enc, err := db.cleans.Get(string(hash[:])); err == nil && enc != nil {
-			//memcacheCleanHitMeter.Mark(1)
-			//memcacheCleanReadMeter.Mark(int64(len(enc)))
+			memcacheCleanHitMeter.Mark(1)
+			memcacheCleanReadMeter.Mark(int64(len(enc)))
 			return enc, nil
 		}
 	}
@@ -445,8 +473,8 @@ func (db *Database) Node(hash crypto.Hash) ([]byte, error) {
 	if err == nil && enc != nil {
 		if db.cleans != nil {
 			db.cleans.Set(string(hash[:]), enc)
-			//memcacheCleanMissMeter.Mark(1)
-			//memcacheCleanWriteMeter.Mark(int64(len(enc)))
+			memcacheCleanMissMeter.Mark(1)
+			memcacheCleanWriteMeter.Mark(int64(len(enc)))
 		}
 	}
 	return enc, err
@@ -19,15 +19,15 @@ package trie
 import (
 	"testing"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
 )
 
 // Tests that the trie database returns a missing trie node error if attempting
 // to retrieve the meta root.
 func TestDatabaseMetarootFetch(t *testing.T) {
 	db := NewDatabase(memorydb.New())
-	if _, err := db.Node(common.Hash{}); err == nil {
+	if _, err := db.Node(crypto.Hash{}); err == nil {
 		t.Fatalf("metaroot retrieval succeeded")
 	}
 }
@@ -18,6 +18,7 @@ package trie
 
 import (
 	"hash"
+	"runtime"
 	"sync"
 
 	"github.com/drep-project/DREP-Chain/common"
@@ -27,10 +28,18 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+// parallelHashThreshold is the number of updates since the last hash from which the
+// subtries are hashed concurrently, below it goroutines cost more than they save
+const parallelHashThreshold = 100
+
+// maxHashWorkers bounds the goroutines hashing the subtries of one trie
+var maxHashWorkers = runtime.NumCPU()
+
 type hasher struct {
-	tmp    sliceBuffer
-	sha    keccakState
-	onleaf LeafCallback
+	tmp      sliceBuffer
+	sha      keccakState
+	onleaf   LeafCallback
+	parallel bool // whether the children of the topmost full node are hashed concurrently
 }
 
 // keccakState wraps sha3.state. In addition to the usual hash methods, it also supports
@@ -65,6 +74,24 @@ var hasherPool = sync.Pool{
 func newHasher(onleaf LeafCallback) *hasher {
 	h := hasherPool.Get().(*hasher)
 	h.onleaf = onleaf
+	h.parallel = false
+	return h
+}
+
+// newParallelHasher returns a hasher which splits the work of the topmost full node
+// into its 16 subtries and hashes them on a pool of at most maxHashWorkers goroutines.
+func newParallelHasher(onleaf LeafCallback) *hasher {
+	h := newHasher(onleaf)
+	if onleaf != nil {
+		// leaf callback will be invoked from several goroutines
+		var lock sync.Mutex
+		h.onleaf = func(leaf []byte, parent crypto.Hash) error {
+			lock.Lock()
+			defer lock.Unlock()
+			return onleaf(leaf, parent)
+		}
+	}
+	h.parallel = true
 	return h
 }
 
@@ -142,6 +169,14 @@ func (h *hasher) hashChildren(original node, db *Database) (node, node, error) {
 		// Hash the full node's children, caching the newly hashed subtrees
 		collapsed, cached := n.copy(), n.copy()
 
+		if h.parallel && maxHashWorkers > 1 && dirtyChildren(n, db != nil) > 1 {
+			err = h.hashChildrenParallel(n, collapsed, cached, db)
+			if err != nil {
+				return original, original, err
+			}
+			cached.Children[16] = n.Children[16]
+			return collapsed, cached, nil
+		}
 		for i := 0; i < 16; i++ {
 			if n.Children[i] != nil {
 				collapsed.Children[i], cached.Children[i], err = h.hash(n.Children[i], db, false)
@@ -159,6 +194,61 @@ func (h *hasher) hashChildren(original node, db *Database) (node, node, error) {
 	}
 }
 
+// hashChildrenParallel hashes the 16 subtries of a full node concurrently, the
+// workers take the subtries one by one and walk them with their own hasher.
+// The database insert is guarded by the db lock.
+func (h *hasher) hashChildrenParallel(n, collapsed, cached *fullNode, db *Database) error {
+	var (
+		wg    sync.WaitGroup
+		errs  [16]error
+		tasks = make(chan int, 16)
+	)
+	for i := 0; i < 16; i++ {
+		if n.Children[i] != nil {
+			tasks <- i
+		}
+	}
+	close(tasks)
+
+	workers := maxHashWorkers
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			subHasher := newHasher(h.onleaf)
+			defer returnHasherToPool(subHasher)
+			for index := range tasks {
+				collapsed.Children[index], cached.Children[index], errs[index] = subHasher.hash(n.Children[index], db, false)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirtyChildren counts the children of a full node which have not been hashed yet
+// or, when committing, need to be stored. Only those are worth to hash concurrently.
+func dirtyChildren(n *fullNode, commit bool) int {
+	count := 0
+	for i := 0; i < 16; i++ {
+		switch child := n.Children[i].(type) {
+		case *fullNode, *shortNode:
+			if hash, dirty := child.cache(); hash == nil || (commit && dirty) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
 // store hashes the node n and if we have a storage layer specified, it writes
 // the key/value pair to it and tracks any node->child references as well as any
 // node->external trie references.
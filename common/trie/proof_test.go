@@ -0,0 +1,70 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func TestSecureProof(t *testing.T) {
+	trie, _ := NewSecure(crypto.Hash{}, NewDatabase(memorydb.New()))
+	content := map[string][]byte{}
+	for i := byte(0); i < 200; i++ {
+		key, val := []byte{1, i}, bytes.Repeat([]byte{i}, 40)
+		content[string(key)] = val
+		trie.Update(key, val)
+	}
+	root, err := trie.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, val := range content {
+		proof := memorydb.New()
+		if err := trie.Prove([]byte(key), 0, proof); err != nil {
+			t.Fatalf("prove key %x: %v", key, err)
+		}
+		value, _, err := VerifyProof(root, trie.hashKey([]byte(key)), proof)
+		if err != nil {
+			t.Fatalf("verify key %x: %v", key, err)
+		}
+		if !bytes.Equal(value, val) {
+			t.Fatalf("verified value mismatch for key %x: have %x, want %x", key, value, val)
+		}
+	}
+
+	// a missing key is proven absent
+	proof := memorydb.New()
+	if err := trie.Prove([]byte{3, 0}, 0, proof); err != nil {
+		t.Fatal(err)
+	}
+	value, _, err := VerifyProof(root, trie.hashKey([]byte{3, 0}), proof)
+	if err != nil || value != nil {
+		t.Fatalf("missing key verified to %x, %v", value, err)
+	}
+
+	// a proof does not verify against another root
+	proof = memorydb.New()
+	trie.Prove([]byte{1, 0}, 0, proof)
+	if _, _, err := VerifyProof(crypto.Hash{1}, trie.hashKey([]byte{1, 0}), proof); err == nil {
+		t.Fatal("proof verified against a wrong root")
+	}
+}
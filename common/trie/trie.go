@@ -55,6 +55,10 @@ type Trie struct {
 	db      *Database
 	dbWrite *Database
 	root    node
+
+	// unhashed counts the updates since the last hash, the trie is hashed
+	// concurrently once it reaches parallelHashThreshold
+	unhashed int
 }
 
 // newFlag returns the cache flag value for a newly created node.
@@ -192,6 +196,7 @@ func (t *Trie) Update(key, value []byte) {
 //
 // If a node was not found in the database, a MissingNodeError is returned.
 func (t *Trie) TryUpdate(key, value []byte) error {
+	t.unhashed++
 	k := keybytesToHex(key)
 	if len(value) != 0 {
 		_, n, err := t.insert(t.root, nil, k, valueNode(value))
@@ -288,6 +293,7 @@ func (t *Trie) Delete(key []byte) {
 // TryDelete removes any existing value for key from the trie.
 // If a node was not found in the database, a MissingNodeError is returned.
 func (t *Trie) TryDelete(key []byte) error {
+	t.unhashed++
 	k := keybytesToHex(key)
 	_, n, err := t.delete(t.root, nil, k)
 	if err != nil {
@@ -473,7 +479,16 @@ func (t *Trie) hashRoot(db *Database, onleaf LeafCallback) (node, node, error) {
 	if t.root == nil {
 		return hashNode(EmptyRoot.Bytes()), nil, nil
 	}
-	h := newHasher(onleaf)
+	var h *hasher
+	if t.unhashed >= parallelHashThreshold {
+		h = newParallelHasher(onleaf)
+	} else {
+		h = newHasher(onleaf)
+	}
 	defer returnHasherToPool(h)
+	if db != nil {
+		// the updates are only done with once committed, a hash alone leaves them to store
+		t.unhashed = 0
+	}
 	return h.hash(t.root, db, true)
 }
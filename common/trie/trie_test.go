@@ -25,15 +25,17 @@ import (
 	"math/rand"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"testing/quick"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/leveldb"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethdb"
-	"github.com/ethereum/go-ethereum/ethdb/leveldb"
-	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -44,15 +46,15 @@ func init() {
 
 // Used for testing
 func newEmpty() *Trie {
-	trie, _ := New(common.Hash{}, NewDatabase(memorydb.New()))
+	trie, _ := New(crypto.Hash{}, NewDatabase(memorydb.New()))
 	return trie
 }
 
 func TestEmptyTrie(t *testing.T) {
 	var trie Trie
 	res := trie.Hash()
-	exp := emptyRoot
-	if res != common.Hash(exp) {
+	exp := EmptyRoot
+	if res != crypto.Hash(exp) {
 		t.Errorf("expected %x got %x", exp, res)
 	}
 }
@@ -68,7 +70,7 @@ func TestNull(t *testing.T) {
 }
 
 func TestMissingRoot(t *testing.T) {
-	trie, err := New(common.HexToHash("0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"), NewDatabase(memorydb.New()))
+	trie, err := New(crypto.HexToHash("0beec7b5ea3f0fdbc95d0dd47f3c5bc275da8a33"), NewDatabase(memorydb.New()))
 	if trie != nil {
 		t.Error("New returned non-nil trie for invalid root")
 	}
@@ -84,7 +86,7 @@ func testMissingNode(t *testing.T, memonly bool) {
 	diskdb := memorydb.New()
 	triedb := NewDatabase(diskdb)
 
-	trie, _ := New(common.Hash{}, triedb)
+	trie, _ := New(crypto.Hash{}, triedb)
 	updateString(trie, "120000", "qwerqwerqwerqwerqwerqwerqwerqwer")
 	updateString(trie, "123456", "asdfasdfasdfasdfasdfasdfasdfasdf")
 	root, _ := trie.Commit(nil)
@@ -118,7 +120,7 @@ func testMissingNode(t *testing.T, memonly bool) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	hash := common.HexToHash("0xe1d943cc8f061a0c0b98162830b970395ac9315654824bf21b73b891365262f9")
+	hash := crypto.HexToHash("0xe1d943cc8f061a0c0b98162830b970395ac9315654824bf21b73b891365262f9")
 	if memonly {
 		delete(triedb.dirties, hash)
 	} else {
@@ -159,7 +161,7 @@ func TestInsert(t *testing.T) {
 	updateString(trie, "dog", "puppy")
 	updateString(trie, "dogglesworth", "cat")
 
-	exp := common.HexToHash("8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3")
+	exp := crypto.HexToHash("8aad789dff2f538bca5d8ea56e8abe10f4c7ba3a5dea95fea4cd6e7c3a1168d3")
 	root := trie.Hash()
 	if root != exp {
 		t.Errorf("exp %x got %x", exp, root)
@@ -168,7 +170,7 @@ func TestInsert(t *testing.T) {
 	trie = newEmpty()
 	updateString(trie, "A", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
 
-	exp = common.HexToHash("d23786fb4a010da3ce639d66d5e904a11dbc02746d1ce25029e53290cabf28ab")
+	exp = crypto.HexToHash("d23786fb4a010da3ce639d66d5e904a11dbc02746d1ce25029e53290cabf28ab")
 	root, err := trie.Commit(nil)
 	if err != nil {
 		t.Fatalf("commit error: %v", err)
@@ -223,7 +225,7 @@ func TestDelete(t *testing.T) {
 	}
 
 	hash := trie.Hash()
-	exp := common.HexToHash("5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84")
+	exp := crypto.HexToHash("5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84")
 	if hash != exp {
 		t.Errorf("expected %x got %x", exp, hash)
 	}
@@ -247,7 +249,7 @@ func TestEmptyValues(t *testing.T) {
 	}
 
 	hash := trie.Hash()
-	exp := common.HexToHash("5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84")
+	exp := crypto.HexToHash("5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84")
 	if hash != exp {
 		t.Errorf("expected %x got %x", exp, hash)
 	}
@@ -318,7 +320,7 @@ func TestLargeValue(t *testing.T) {
 }
 
 type countingDB struct {
-	ethdb.KeyValueStore
+	dbinterface.KeyValueStore
 	gets map[string]int
 }
 
@@ -382,7 +384,7 @@ func (randTest) Generate(r *rand.Rand, size int) reflect.Value {
 func runRandTest(rt randTest) bool {
 	triedb := NewDatabase(memorydb.New())
 
-	tr, _ := New(common.Hash{}, triedb)
+	tr, _ := New(crypto.Hash{}, triedb)
 	values := make(map[string]string) // tracks content of the trie
 
 	for i, step := range rt {
@@ -416,7 +418,7 @@ func runRandTest(rt randTest) bool {
 			}
 			tr = newtr
 		case opItercheckhash:
-			checktr, _ := New(common.Hash{}, triedb)
+			checktr, _ := New(crypto.Hash{}, triedb)
 			it := NewIterator(tr.NodeIterator(nil))
 			for it.Next() {
 				checktr.Update(it.Key, it.Value)
@@ -453,7 +455,7 @@ func benchGet(b *testing.B, commit bool) {
 	trie := new(Trie)
 	if commit {
 		_, tmpdb := tempDB()
-		trie, _ = New(common.Hash{}, tmpdb)
+		trie, _ = New(crypto.Hash{}, tmpdb)
 	}
 	k := make([]byte, 32)
 	for i := 0; i < benchElemCount; i++ {
@@ -493,34 +495,146 @@ func benchUpdate(b *testing.B, e binary.ByteOrder) *Trie {
 // the first one will be NOOP. As such, we'll use b.N as the number of account to
 // insert into the trie before measuring the hashing.
 func BenchmarkHash(b *testing.B) {
+	// Create a realistic account trie to hash
+	addresses, accounts := makeAccounts(b.N)
+
+	// Insert the accounts into the trie and hash it
+	trie := newEmpty()
+	for i := 0; i < len(addresses); i++ {
+		trie.Update(sha3.Keccak256(addresses[i][:]), accounts[i])
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+	trie.Hash()
+}
+
+// Benchmarks the commit of a fresh account trie by the serial and the parallel hasher.
+func BenchmarkCommitSerial(b *testing.B)   { benchCommit(b, false) }
+func BenchmarkCommitParallel(b *testing.B) { benchCommit(b, true) }
+
+func benchCommit(b *testing.B, parallel bool) {
+	addresses, accounts := makeAccounts(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		trie := newEmpty()
+		for j := 0; j < len(addresses); j++ {
+			trie.Update(sha3.Keccak256(addresses[j][:]), accounts[j])
+		}
+		h := newHasher(nil)
+		if parallel {
+			h = newParallelHasher(nil)
+		}
+		b.StartTimer()
+
+		if _, _, err := h.hash(trie.root, trie.db, true); err != nil {
+			b.Fatal(err)
+		}
+		returnHasherToPool(h)
+	}
+}
+
+// makeAccounts generates a deterministic set of random addresses and rlp encoded accounts.
+func makeAccounts(size int) (addresses [][20]byte, accounts [][]byte) {
 	// Make the random benchmark deterministic
 	random := rand.New(rand.NewSource(0))
 
-	// Create a realistic account trie to hash
-	addresses := make([][20]byte, b.N)
+	addresses = make([][20]byte, size)
 	for i := 0; i < len(addresses); i++ {
 		for j := 0; j < len(addresses[i]); j++ {
 			addresses[i][j] = byte(random.Intn(256))
 		}
 	}
-	accounts := make([][]byte, len(addresses))
+	accounts = make([][]byte, len(addresses))
 	for i := 0; i < len(accounts); i++ {
 		var (
 			nonce   = uint64(random.Int63())
 			balance = new(big.Int).Rand(random, new(big.Int).Exp(common.Big2, common.Big256, nil))
-			root    = emptyRoot
-			code    = crypto.Keccak256(nil)
+			root    = EmptyRoot
+			code    = sha3.Keccak256(nil)
 		)
 		accounts[i], _ = rlp.EncodeToBytes([]interface{}{nonce, balance, root, code})
 	}
-	// Insert the accounts into the trie and hash it
+	return addresses, accounts
+}
+
+// Tests that the parallel hasher produces the same root, nodes and leaf callbacks as the serial one.
+func TestParallelHashEqualsSerial(t *testing.T) {
+	workers := maxHashWorkers
+	maxHashWorkers = 4
+	defer func() { maxHashWorkers = workers }()
+
+	addresses, accounts := makeAccounts(2000)
+	commit := func(parallel bool) (crypto.Hash, *Database, int) {
+		trie := newEmpty()
+		for i := 0; i < len(addresses); i++ {
+			trie.Update(sha3.Keccak256(addresses[i][:]), accounts[i])
+		}
+		// delete some accounts so that the trie also collapses nodes
+		for i := 0; i < len(addresses); i += 7 {
+			trie.Delete(sha3.Keccak256(addresses[i][:]))
+		}
+		var leaves int32
+		onleaf := func(leaf []byte, parent crypto.Hash) error {
+			atomic.AddInt32(&leaves, 1)
+			return nil
+		}
+		h := newHasher(onleaf)
+		if parallel {
+			h = newParallelHasher(onleaf)
+		}
+		defer returnHasherToPool(h)
+		hash, _, err := h.hash(trie.root, trie.db, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return crypto.BytesToHash(hash.(hashNode)), trie.db, int(leaves)
+	}
+	serialRoot, serialDB, serialLeaves := commit(false)
+	parallelRoot, parallelDB, parallelLeaves := commit(true)
+
+	if serialRoot != parallelRoot {
+		t.Fatalf("root mismatch: serial %x, parallel %x", serialRoot, parallelRoot)
+	}
+	if serialLeaves != parallelLeaves {
+		t.Fatalf("leaf callbacks mismatch: serial %d, parallel %d", serialLeaves, parallelLeaves)
+	}
+	serialNodes, parallelNodes := serialDB.Nodes(), parallelDB.Nodes()
+	if len(serialNodes) != len(parallelNodes) {
+		t.Fatalf("node count mismatch: serial %d, parallel %d", len(serialNodes), len(parallelNodes))
+	}
+	for _, hash := range serialNodes {
+		want, _ := serialDB.Node(hash)
+		if got, err := parallelDB.Node(hash); err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("node %x mismatch: %v", hash, err)
+		}
+	}
+}
+
+// Tests that only a trie with enough updates since its last commit is hashed concurrently.
+func TestParallelHashThreshold(t *testing.T) {
 	trie := newEmpty()
-	for i := 0; i < len(addresses); i++ {
-		trie.Update(crypto.Keccak256(addresses[i][:]), accounts[i])
+	for i := 0; i < parallelHashThreshold-1; i++ {
+		updateString(trie, fmt.Sprintf("key%d", i), "value")
+	}
+	if trie.unhashed != parallelHashThreshold-1 {
+		t.Fatalf("unhashed %d, want %d", trie.unhashed, parallelHashThreshold-1)
 	}
-	b.ResetTimer()
-	b.ReportAllocs()
 	trie.Hash()
+	if trie.unhashed != parallelHashThreshold-1 {
+		t.Fatal("hash without commit reset the update count")
+	}
+	deleteString(trie, "key0")
+	if trie.unhashed != parallelHashThreshold {
+		t.Fatalf("unhashed %d, want %d", trie.unhashed, parallelHashThreshold)
+	}
+	if _, err := trie.Commit(nil); err != nil {
+		t.Fatal(err)
+	}
+	if trie.unhashed != 0 {
+		t.Fatalf("unhashed %d after commit, want 0", trie.unhashed)
+	}
 }
 
 func tempDB() (string, *Database) {
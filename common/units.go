@@ -0,0 +1,121 @@
+package common
+
+import (
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+// Amount units and their number of decimals, same scale as params.GWei and params.Coin
+var amountUnits = map[string]int{
+	"wei":  0,
+	"gwei": 9,
+	"drep": 18,
+}
+
+var (
+	ErrAmountSyntax    = &decError{"invalid amount, want <number><unit> e.g. 1.5drep"}
+	ErrAmountUnit      = &decError{"unknown amount unit, want wei, gwei or drep"}
+	ErrAmountPrecision = &decError{"amount has more decimals than its unit allows"}
+	ErrAmountRange     = &decError{"amount > 256 bits"}
+)
+
+// unitAmounts is set when Big accepts amounts with units besides hex
+var unitAmounts int32
+
+// EnableUnitAmounts let Big decode amounts with units such as "1.5drep" or "10gwei".
+// Hex quantities are accepted either way
+func EnableUnitAmounts(enable bool) {
+	if enable {
+		atomic.StoreInt32(&unitAmounts, 1)
+	} else {
+		atomic.StoreInt32(&unitAmounts, 0)
+	}
+}
+
+// UnitAmountsEnabled reports whether Big accepts amounts with units
+func UnitAmountsEnabled() bool {
+	return atomic.LoadInt32(&unitAmounts) == 1
+}
+
+// ParseAmount convert an amount with unit to wei. The parsing is strict: no sign,
+// spaces, exponent or leading zeros, a fraction needs digits on both sides of the
+// dot and may not be finer than one wei, and the result must fit in 256 bits
+func ParseAmount(input string) (*big.Int, error) {
+	end := 0
+	for end < len(input) && (isDigit(input[end]) || input[end] == '.') {
+		end++
+	}
+	number, unit := input[:end], strings.ToLower(input[end:])
+	if number == "" || unit == "" {
+		return nil, ErrAmountSyntax
+	}
+	decimals, ok := amountUnits[unit]
+	if !ok {
+		return nil, ErrAmountUnit
+	}
+
+	integer, fraction := number, ""
+	if dot := strings.IndexByte(number, '.'); dot >= 0 {
+		integer, fraction = number[:dot], number[dot+1:]
+		if fraction == "" || strings.IndexByte(fraction, '.') >= 0 {
+			return nil, ErrAmountSyntax
+		}
+	}
+	if integer == "" || (len(integer) > 1 && integer[0] == '0') {
+		return nil, ErrAmountSyntax
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return nil, ErrAmountPrecision
+	}
+
+	digits := integer + fraction + strings.Repeat("0", decimals-len(fraction))
+	wei, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, ErrAmountSyntax
+	}
+	if wei.BitLen() > 256 {
+		return nil, ErrAmountRange
+	}
+	return wei, nil
+}
+
+// FormatAmount write wei in drep without trailing zeros, e.g. "1.5drep"
+func FormatAmount(wei *big.Int) string {
+	decimals := amountUnits["drep"]
+	digits := new(big.Int).Abs(wei).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	integer, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+	if fraction == "" {
+		return sign + integer + "drep"
+	}
+	return sign + integer + "." + fraction + "drep"
+}
+
+// Amount is a quantity as returned by the rpc, the raw hex value with its canonical
+// decimal wei value and a human readable form
+type Amount struct {
+	Raw   *Big   `json:"raw"`
+	Wei   string `json:"wei"`
+	Human string `json:"human"`
+}
+
+// NewAmount describe wei in every form
+func NewAmount(wei *big.Int) *Amount {
+	return &Amount{
+		Raw:   (*Big)(new(big.Int).Set(wei)),
+		Wei:   wei.String(),
+		Human: FormatAmount(wei),
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		input string
+		wei   string
+		err   error
+	}{
+		{"1.5drep", "1500000000000000000", nil},
+		{"10gwei", "10000000000", nil},
+		{"10GWei", "10000000000", nil},
+		{"0.000000001gwei", "1", nil},
+		{"0.0000000001gwei", "", ErrAmountPrecision},
+		{"0.5gwei", "500000000", nil},
+		{"1.50wei", "", ErrAmountPrecision},
+		{"1.0wei", "1", nil},
+		{"0drep", "0", nil},
+		{"", "", ErrAmountSyntax},
+		{"drep", "", ErrAmountSyntax},
+		{"1", "", ErrAmountSyntax},
+		{"-1drep", "", ErrAmountSyntax},
+		{"+1drep", "", ErrAmountSyntax},
+		{" 1drep", "", ErrAmountSyntax},
+		{"1 drep", "", ErrAmountUnit},
+		{"1e3drep", "", ErrAmountUnit},
+		{".5drep", "", ErrAmountSyntax},
+		{"1.drep", "", ErrAmountSyntax},
+		{"1.2.3drep", "", ErrAmountSyntax},
+		{"01drep", "", ErrAmountSyntax},
+		{"1eth", "", ErrAmountUnit},
+		{strings.Repeat("9", 80) + "wei", "", ErrAmountRange},
+	}
+	for _, c := range cases {
+		wei, err := ParseAmount(c.input)
+		if err != c.err {
+			t.Errorf("%q: got error %v, want %v", c.input, err, c.err)
+			continue
+		}
+		if err == nil && wei.String() != c.wei {
+			t.Errorf("%q: got %s, want %s", c.input, wei.String(), c.wei)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		wei  string
+		want string
+	}{
+		{"0", "0drep"},
+		{"1", "0.000000000000000001drep"},
+		{"1500000000000000000", "1.5drep"},
+		{"10000000000000000000000", "10000drep"},
+	}
+	for _, c := range cases {
+		wei, _ := new(big.Int).SetString(c.wei, 10)
+		if got := FormatAmount(wei); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.wei, got, c.want)
+		}
+		back, err := ParseAmount(c.want)
+		if err != nil || back.Cmp(wei) != 0 {
+			t.Errorf("%s: round trip got %v, %v", c.want, back, err)
+		}
+	}
+}
+
+func TestBigUnitAmounts(t *testing.T) {
+	defer EnableUnitAmounts(false)
+
+	var b Big
+	if err := json.Unmarshal([]byte(`"1.5drep"`), &b); err == nil {
+		t.Fatal("unit amount accepted while disabled")
+	}
+
+	EnableUnitAmounts(true)
+	if err := json.Unmarshal([]byte(`"1.5drep"`), &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.ToInt().String() != "1500000000000000000" {
+		t.Fatalf("got %s", b.ToInt().String())
+	}
+	if err := json.Unmarshal([]byte(`"0x10"`), &b); err != nil || b.ToInt().Int64() != 16 {
+		t.Fatalf("hex quantity: got %v, %v", b.ToInt(), err)
+	}
+	if err := json.Unmarshal([]byte(`"1.5"`), &b); err == nil {
+		t.Fatal("amount without unit accepted")
+	}
+}
@@ -0,0 +1,344 @@
+// Package badgerdb implements the key-value database layer based on BadgerDB.
+package badgerdb
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+const (
+	// minCache is the minimum amount of memory in megabytes to allocate to the
+	// block cache.
+	minCache = 16
+
+	// gcDiscardRatio is the ratio of stale data in a value log file above which
+	// Compact rewrites it.
+	gcDiscardRatio = 0.5
+)
+
+// Database is a persistent key-value store. Badger keeps keys in an LSM tree and
+// values in a separate log, which favours write heavy workloads with large values.
+type Database struct {
+	fn string     // filename for reporting
+	db *badger.DB // BadgerDB instance
+
+	closeOnce sync.Once
+}
+
+// New returns a wrapped BadgerDB object, cache is the block cache in megabytes and
+// syncWrite decide whether writes are flushed to disk by fsync.
+func New(file string, cache int, syncWrite bool) (*Database, error) {
+	if cache < minCache {
+		cache = minCache
+	}
+	opts := badger.DefaultOptions(file).
+		WithLogger(nil).
+		WithSyncWrites(syncWrite).
+		WithBlockCacheSize(int64(cache) * 1024 * 1024)
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Database{fn: file, db: db}, nil
+}
+
+// Close flushes any pending data to disk and closes all io accesses to the
+// underlying key-value store.
+func (db *Database) Close() error {
+	var err error
+	db.closeOnce.Do(func() { err = db.db.Close() })
+	return err
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	return db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+// Sync flushes the writes not synced yet to disk.
+func (db *Database) Sync() error {
+	return db.db.Sync()
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() dbinterface.Batch {
+	return &batch{db: db.db}
+}
+
+// NewIterator creates a binary-alphabetical iterator over the entire keyspace
+// contained within the badger database.
+func (db *Database) NewIterator() dbinterface.Iterator {
+	return db.NewRangeIterator(nil, nil, false)
+}
+
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// database content starting at a particular initial key (or after, if it does
+// not exist).
+func (db *Database) NewIteratorWithStart(start []byte) dbinterface.Iterator {
+	return db.NewRangeIterator(start, nil, false)
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix.
+func (db *Database) NewIteratorWithPrefix(prefix []byte) dbinterface.Iterator {
+	return db.NewRangeIterator(prefix, prefixLimit(prefix), false)
+}
+
+// NewRangeIterator creates an iterator over the keys in [start, limit), in
+// descending order if reverse is set.
+func (db *Database) NewRangeIterator(start []byte, limit []byte, reverse bool) dbinterface.Iterator {
+	txn := db.db.NewTransaction(false)
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = reverse
+	return &iterator{
+		txn:     txn,
+		it:      txn.NewIterator(opts),
+		start:   start,
+		limit:   limit,
+		reverse: reverse,
+	}
+}
+
+// Stat returns a particular internal stat of the database, "badger.size" is the
+// bytes of the LSM tree and the value log.
+func (db *Database) Stat(property string) (string, error) {
+	if property != "badger.size" {
+		return "", errors.New("unknown property")
+	}
+	lsm, vlog := db.db.Size()
+	return strconv.FormatInt(lsm+vlog, 10), nil
+}
+
+// Compact rewrites the value log files with much stale data. Badger compacts its
+// LSM tree by itself, so the key range is ignored.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	for {
+		if err := db.db.RunValueLogGC(gcDiscardRatio); err != nil {
+			if err == badger.ErrNoRewrite {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// Path returns the path to the database directory.
+func (db *Database) Path() string {
+	return db.fn
+}
+
+// prefixLimit returns the smallest key greater than all keys with the prefix, nil
+// if there is none.
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte{}, prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// batch is a write-only badger batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	db   *badger.DB
+	ops  []op
+	size int
+}
+
+type op struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, op{key: copyBytes(key), value: copyBytes(value)})
+	b.size += len(value)
+	return nil
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, op{key: copyBytes(key), delete: true})
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = wb.Delete(op.key)
+		} else {
+			err = wb.Set(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w dbinterface.KeyValueWriter) error {
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = w.Delete(op.key)
+		} else {
+			err = w.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyBytes returns a copy of b, the caller may reuse its slices before the batch
+// is written.
+func copyBytes(b []byte) []byte {
+	return append([]byte{}, b...)
+}
+
+// iterator walks a read only badger transaction, the keys outside [start, limit)
+// end the iteration.
+type iterator struct {
+	txn          *badger.Txn
+	it           *badger.Iterator
+	start, limit []byte
+	reverse      bool
+	started      bool
+	key, value   []byte
+	err          error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.seek()
+	} else if it.it.Valid() {
+		it.it.Next()
+	}
+	if !it.it.Valid() {
+		it.key, it.value = nil, nil
+		return false
+	}
+	item := it.it.Item()
+	key := item.Key()
+	if it.limit != nil && bytes.Compare(key, it.limit) >= 0 || bytes.Compare(key, it.start) < 0 {
+		it.key, it.value = nil, nil
+		return false
+	}
+	it.key = item.KeyCopy(nil)
+	it.value, it.err = item.ValueCopy(nil)
+	return it.err == nil
+}
+
+// seek positions the iterator on the first key of the range in its direction.
+func (it *iterator) seek() {
+	if !it.reverse {
+		it.it.Seek(it.start)
+		return
+	}
+	if it.limit == nil {
+		it.it.Rewind()
+		return
+	}
+	// a reverse seek lands on the largest key not above limit, limit is excluded
+	it.it.Seek(it.limit)
+	if it.it.Valid() && bytes.Equal(it.it.Item().Key(), it.limit) {
+		it.it.Next()
+	}
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	if it.it != nil {
+		it.it.Close()
+		it.txn.Discard()
+		it.it = nil
+	}
+}
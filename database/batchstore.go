@@ -0,0 +1,378 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+var errBatchNotFound = errors.New("not found")
+
+// BatchStore wraps a key value store, all writes issued between Begin and Commit are
+// accumulated into one batch and written to disk together. Reads and iterators see the
+// pending writes.
+type BatchStore struct {
+	dbinterface.KeyValueStore
+
+	lock      sync.RWMutex
+	batching  bool
+	batch     dbinterface.Batch
+	pending   map[string][]byte // nil value is a pending delete
+	flushSize int               // bytes of pending data written to disk at the next commit boundary, 0 for no limit
+}
+
+// NewBatchStore create a batch store over the disk store
+func NewBatchStore(store dbinterface.KeyValueStore) *BatchStore {
+	return &BatchStore{
+		KeyValueStore: store,
+		pending:       make(map[string][]byte),
+	}
+}
+
+// Begin start to accumulate writes
+func (store *BatchStore) Begin() {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if store.batching {
+		return
+	}
+	store.batching = true
+	store.batch = store.KeyValueStore.NewBatch()
+}
+
+// SetFlushSize set the bytes of pending data above which the batch is written to disk at the
+// next commit boundary without waiting for Flush, see FlushIfFull
+func (store *BatchStore) SetFlushSize(size int) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.flushSize = size
+}
+
+// PendingSize return the bytes of data waiting to be written
+func (store *BatchStore) PendingSize() int {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+	if !store.batching {
+		return 0
+	}
+	return store.batch.ValueSize()
+}
+
+// Flush write the accumulated data to disk and keep batching
+func (store *BatchStore) Flush() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return store.flush()
+}
+
+// Commit write the accumulated data to disk and stop batching. The end of a batch is a commit
+// boundary of the store below, a full dirty cache is written to disk there
+func (store *BatchStore) Commit() error {
+	store.lock.Lock()
+	err := store.flush()
+	store.batching = false
+	store.batch = nil
+	store.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	if below, ok := store.KeyValueStore.(interface{ FlushIfFull() error }); ok {
+		return below.FlushIfFull()
+	}
+	return nil
+}
+
+func (store *BatchStore) flush() error {
+	if !store.batching || store.batch.ValueSize() == 0 && len(store.pending) == 0 {
+		return nil
+	}
+	if err := store.batch.Write(); err != nil {
+		return err
+	}
+	store.batch.Reset()
+	store.pending = make(map[string][]byte)
+	return nil
+}
+
+// FlushIfFull write the batch to disk once it outgrows the flush size. Writes never flush by
+// themselves, a block is only half on disk if the flush falls inside it, so this is called at
+// commit boundaries only
+func (store *BatchStore) FlushIfFull() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if store.batching && store.flushSize > 0 && store.batch.ValueSize() >= store.flushSize {
+		return store.flush()
+	}
+	return nil
+}
+
+// Has retrieves if a key is present, pending writes included
+func (store *BatchStore) Has(key []byte) (bool, error) {
+	store.lock.RLock()
+	if val, ok := store.pending[string(key)]; ok {
+		store.lock.RUnlock()
+		return val != nil, nil
+	}
+	store.lock.RUnlock()
+	return store.KeyValueStore.Has(key)
+}
+
+// Get retrieves the given key, pending writes included
+func (store *BatchStore) Get(key []byte) ([]byte, error) {
+	store.lock.RLock()
+	if val, ok := store.pending[string(key)]; ok {
+		store.lock.RUnlock()
+		if val == nil {
+			return nil, errBatchNotFound
+		}
+		return val, nil
+	}
+	store.lock.RUnlock()
+	return store.KeyValueStore.Get(key)
+}
+
+// Put inserts the key into batch while batching, otherwise write to disk directly
+func (store *BatchStore) Put(key []byte, value []byte) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if !store.batching {
+		return store.KeyValueStore.Put(key, value)
+	}
+	return store.put(key, value)
+}
+
+func (store *BatchStore) put(key []byte, value []byte) error {
+	val := make([]byte, len(value))
+	copy(val, value)
+	store.pending[string(key)] = val
+	return store.batch.Put(key, val)
+}
+
+// Delete removes the key in batch while batching, otherwise delete from disk directly
+func (store *BatchStore) Delete(key []byte) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if !store.batching {
+		return store.KeyValueStore.Delete(key)
+	}
+	return store.delete(key)
+}
+
+func (store *BatchStore) delete(key []byte) error {
+	store.pending[string(key)] = nil
+	return store.batch.Delete(key)
+}
+
+// write apply the ops together, they are never split across two writes to disk
+func (store *BatchStore) write(ops []batchOp) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if !store.batching {
+		diskBatch := store.KeyValueStore.NewBatch()
+		for _, op := range ops {
+			if op.delete {
+				diskBatch.Delete(op.key)
+			} else {
+				diskBatch.Put(op.key, op.value)
+			}
+		}
+		return diskBatch.Write()
+	}
+	for _, op := range ops {
+		var err error
+		if op.delete {
+			err = store.delete(op.key)
+		} else {
+			err = store.put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewIterator creates an iterator over the whole store, pending writes included
+func (store *BatchStore) NewIterator() dbinterface.Iterator {
+	return store.NewRangeIterator(nil, nil, false)
+}
+
+// NewIteratorWithStart creates an iterator from start, pending writes included
+func (store *BatchStore) NewIteratorWithStart(start []byte) dbinterface.Iterator {
+	return store.NewRangeIterator(start, nil, false)
+}
+
+// NewIteratorWithPrefix creates an iterator over the keys with prefix, pending writes included
+func (store *BatchStore) NewIteratorWithPrefix(prefix []byte) dbinterface.Iterator {
+	return store.NewRangeIterator(prefix, prefixLimit(prefix), false)
+}
+
+// NewRangeIterator creates an iterator over the keys in [start, limit), pending writes included
+func (store *BatchStore) NewRangeIterator(start []byte, limit []byte, reverse bool) dbinterface.Iterator {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	// the disk iterator is created under the lock, so no flush moves pending keys in between
+	it := &mergedIterator{
+		disk:    store.KeyValueStore.NewRangeIterator(start, limit, reverse),
+		reverse: reverse,
+	}
+	for key := range store.pending {
+		if key >= string(start) && (limit == nil || key < string(limit)) {
+			it.keys = append(it.keys, key)
+		}
+	}
+	sort.Strings(it.keys)
+	if reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(it.keys)))
+	}
+	for _, key := range it.keys {
+		it.values = append(it.values, store.pending[key])
+	}
+	return it
+}
+
+// prefixLimit return the smallest key after all keys with prefix, nil if there is none
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte{}, prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// mergedIterator walk the pending writes and the disk in key order, a pending write hides
+// the disk value of its key
+type mergedIterator struct {
+	disk      dbinterface.Iterator
+	diskValid bool
+	started   bool
+	reverse   bool
+
+	keys   []string
+	values [][]byte // nil value is a pending delete
+	pos    int
+
+	key, value []byte
+}
+
+func (it *mergedIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		it.diskValid = it.disk.Next()
+	}
+	for {
+		hasPending := it.pos < len(it.keys)
+		if !hasPending && !it.diskValid {
+			it.key, it.value = nil, nil
+			return false
+		}
+		cmp := 0
+		switch {
+		case !hasPending:
+			cmp = 1
+		case !it.diskValid:
+			cmp = -1
+		default:
+			cmp = bytes.Compare([]byte(it.keys[it.pos]), it.disk.Key())
+			if it.reverse {
+				cmp = -cmp
+			}
+		}
+		if cmp > 0 {
+			it.key = append([]byte{}, it.disk.Key()...)
+			it.value = append([]byte{}, it.disk.Value()...)
+			it.diskValid = it.disk.Next()
+			return true
+		}
+		if cmp == 0 {
+			it.diskValid = it.disk.Next()
+		}
+		key, value := it.keys[it.pos], it.values[it.pos]
+		it.pos++
+		if value != nil {
+			it.key, it.value = []byte(key), value
+			return true
+		}
+	}
+}
+
+func (it *mergedIterator) Error() error {
+	return it.disk.Error()
+}
+
+func (it *mergedIterator) Key() []byte {
+	return it.key
+}
+
+func (it *mergedIterator) Value() []byte {
+	return it.value
+}
+
+func (it *mergedIterator) Release() {
+	it.disk.Release()
+}
+
+// NewBatch creates a batch whose writes are merged into the store batch while batching
+func (store *BatchStore) NewBatch() dbinterface.Batch {
+	return &storeBatch{store: store}
+}
+
+type batchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// storeBatch buffer ops in memory and apply them to the BatchStore on write
+type storeBatch struct {
+	store *BatchStore
+	ops   []batchOp
+	size  int
+}
+
+func (b *storeBatch) Put(key, value []byte) error {
+	b.ops = append(b.ops, batchOp{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *storeBatch) Delete(key []byte) error {
+	b.ops = append(b.ops, batchOp{key: append([]byte{}, key...), delete: true})
+	b.size++
+	return nil
+}
+
+func (b *storeBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *storeBatch) Write() error {
+	return b.store.write(b.ops)
+}
+
+func (b *storeBatch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
+}
+
+func (b *storeBatch) Replay(w dbinterface.KeyValueWriter) error {
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = w.Delete(op.key)
+		} else {
+			err = w.Put(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
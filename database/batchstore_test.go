@@ -0,0 +1,111 @@
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func TestBatchStore(t *testing.T) {
+	disk := memorydb.New()
+	store := NewBatchStore(disk)
+
+	store.Begin()
+	store.Put([]byte("a"), []byte("1"))
+	batch := store.NewBatch()
+	batch.Put([]byte("b"), []byte("2"))
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	store.Delete([]byte("a"))
+
+	if ok, _ := disk.Has([]byte("b")); ok {
+		t.Fatal("write reach disk before commit")
+	}
+	if val, err := store.Get([]byte("b")); err != nil || !bytes.Equal(val, []byte("2")) {
+		t.Fatalf("pending write not visible, got %v %v", val, err)
+	}
+	if ok, _ := store.Has([]byte("a")); ok {
+		t.Fatal("pending delete not visible")
+	}
+
+	if err := store.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := disk.Get([]byte("b")); err != nil || !bytes.Equal(val, []byte("2")) {
+		t.Fatalf("batch not written, got %v %v", val, err)
+	}
+	if ok, _ := disk.Has([]byte("a")); ok {
+		t.Fatal("delete not written")
+	}
+
+	store.Put([]byte("c"), []byte("3"))
+	if ok, _ := disk.Has([]byte("c")); !ok {
+		t.Fatal("write should go to disk directly when not batching")
+	}
+}
+
+func TestBatchStoreIterator(t *testing.T) {
+	disk := memorydb.New()
+	for _, key := range []string{"a", "b", "d"} {
+		disk.Put([]byte(key), []byte("disk"))
+	}
+	store := NewBatchStore(disk)
+	store.Begin()
+	store.Put([]byte("c"), []byte("pending"))
+	store.Put([]byte("d"), []byte("pending"))
+	store.Delete([]byte("b"))
+
+	iterated := func(it dbinterface.Iterator) string {
+		defer it.Release()
+		keys := ""
+		for it.Next() {
+			keys += string(it.Key()) + "=" + string(it.Value()) + " "
+		}
+		return keys
+	}
+	if got := iterated(store.NewIterator()); got != "a=disk c=pending d=pending " {
+		t.Fatalf("got %q", got)
+	}
+	if got := iterated(store.NewRangeIterator([]byte("b"), nil, true)); got != "d=pending c=pending " {
+		t.Fatalf("got %q", got)
+	}
+	if got := iterated(store.NewIteratorWithPrefix([]byte("a"))); got != "a=disk " {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestBatchStoreFlushSize(t *testing.T) {
+	disk := memorydb.New()
+	store := NewBatchStore(disk)
+	store.SetFlushSize(4)
+	store.Begin()
+
+	// a full cache is only written at the commit of the block batch above it
+	block := NewBatchStore(store)
+	block.Begin()
+	block.Put([]byte("a"), []byte("123"))
+	block.Put([]byte("b"), []byte("456"))
+	if err := block.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	store.Put([]byte("c"), []byte("1"))
+	for _, key := range []string{"a", "b", "c"} {
+		if ok, _ := disk.Has([]byte(key)); ok {
+			t.Fatalf("%s flushed inside the block", key)
+		}
+	}
+	if err := block.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if ok, _ := disk.Has([]byte(key)); !ok {
+			t.Fatalf("%s not flushed", key)
+		}
+	}
+	if size := store.PendingSize(); size != 0 {
+		t.Fatalf("pending size %d after flush", size)
+	}
+}
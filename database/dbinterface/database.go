@@ -62,6 +62,7 @@ type KeyValueStore interface {
 	KeyValueWriter
 	Batcher
 	Iteratee
+	RangeIteratee
 	Stater
 	Compacter
 	io.Closer
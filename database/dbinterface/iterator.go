@@ -64,3 +64,11 @@ type Iteratee interface {
 	// of database content with a particular key prefix.
 	NewIteratorWithPrefix(prefix []byte) Iterator
 }
+
+// RangeIteratee wraps the NewRangeIterator method of a backing data store.
+type RangeIteratee interface {
+	// NewRangeIterator creates an iterator over the keys in [start, limit), in
+	// descending order if reverse is set. A nil start is treated as a key before
+	// all keys, a nil limit as a key after all keys.
+	NewRangeIterator(start []byte, limit []byte, reverse bool) Iterator
+}
@@ -4,12 +4,14 @@ import (
 	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/database/dbinterface"
-	"github.com/drep-project/DREP-Chain/database/leveldb"
 	"github.com/drep-project/DREP-Chain/database/memorydb"
 	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
 
 	"gopkg.in/urfave/cli.v1"
 	path2 "path"
+	"strconv"
+	"sync"
+	"time"
 )
 
 var (
@@ -21,15 +23,36 @@ var (
 		Name:  "datadir",
 		Usage: "Directory for the database dir (default = inside the homedir)",
 	}
+	DBWriteBufferFlag = cli.IntFlag{
+		Name:  "dbwritebuffer",
+		Usage: "Megabytes of memory allocated to database write buffer",
+	}
+	DBSyncFlag = cli.BoolFlag{
+		Name:  "dbsync",
+		Usage: "Fsync database on every batch write, with a dirty cache on every write of the cache",
+	}
+	DBFlushIntervalFlag = cli.Uint64Flag{
+		Name:  "db.flushinterval",
+		Usage: "Seconds between writes of the dirty cache to disk, 0 writes every block through",
+	}
+	DBDirtyCacheFlag = cli.IntFlag{
+		Name:  "db.dirtycache",
+		Usage: "Megabytes of dirty data written to disk without waiting for the flush interval",
+	}
 )
 
 type DatabaseService struct {
 	Config *DatabaseConfig
 	db     dbinterface.KeyValueStore
+	store  *BatchStore // dirty cache in front of db
+	path   string
+
+	quit chan struct{}
+	wg   sync.WaitGroup
 }
 
 func NewDatabaseService(db dbinterface.KeyValueStore) *DatabaseService {
-	ds := &DatabaseService{db: db}
+	ds := &DatabaseService{db: db, store: NewBatchStore(db)}
 	return ds
 }
 
@@ -42,7 +65,7 @@ func (database *DatabaseService) Api() []app.API {
 }
 
 func (database *DatabaseService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{DataDirFlag}
+	return nil, []cli.Flag{DataDirFlag, DBEngineFlag, DBWriteBufferFlag, DBSyncFlag, DBFlushIntervalFlag, DBDirtyCacheFlag}
 }
 
 func (database *DatabaseService) Init(executeContext *app.ExecuteContext) error {
@@ -50,24 +73,128 @@ func (database *DatabaseService) Init(executeContext *app.ExecuteContext) error
 	if executeContext.Cli != nil && executeContext.Cli.GlobalIsSet(DataDirFlag.Name) {
 		path = executeContext.Cli.GlobalString(DataDirFlag.Name)
 	}
+	if database.Config == nil {
+		database.Config = DefaultDatabaseConfig()
+	}
+	if executeContext.Cli != nil {
+		if executeContext.Cli.GlobalIsSet(app.CacheFlag.Name) || executeContext.Cli.GlobalIsSet(app.CacheTrieFlag.Name) {
+			database.Config.Cache = int(app.CacheAllowance(app.CacheDatabase) / 1024 / 1024)
+		}
+		if executeContext.Cli.GlobalIsSet(DBEngineFlag.Name) {
+			database.Config.Engine = executeContext.Cli.GlobalString(DBEngineFlag.Name)
+		}
+		if executeContext.Cli.GlobalIsSet(DBWriteBufferFlag.Name) {
+			database.Config.WriteBuffer = executeContext.Cli.GlobalInt(DBWriteBufferFlag.Name)
+		}
+		if executeContext.Cli.GlobalIsSet(DBSyncFlag.Name) {
+			database.Config.SyncWrite = executeContext.Cli.GlobalBool(DBSyncFlag.Name)
+		}
+		if executeContext.Cli.GlobalIsSet(DBFlushIntervalFlag.Name) {
+			database.Config.FlushInterval = executeContext.Cli.GlobalUint64(DBFlushIntervalFlag.Name)
+		}
+		if executeContext.Cli.GlobalIsSet(DBDirtyCacheFlag.Name) {
+			database.Config.DirtyCache = executeContext.Cli.GlobalInt(DBDirtyCacheFlag.Name)
+		}
+	}
+	database.path = path
 	var err error
-	database.db, err = leveldb.New(path, 16, 512, "")
+	database.db, err = openEngine(path, database.Config)
 	if err != nil {
 		return err
 	}
+	if err := MigrateSchema(database.db); err != nil {
+		database.db.Close()
+		return err
+	}
+
+	// block import writes are small and random, buffering them between flushes turns
+	// them into a few large batches
+	database.store = NewBatchStore(database.db)
+	if database.Config.FlushInterval > 0 {
+		database.store.SetFlushSize(database.Config.DirtyCache * 1024 * 1024)
+		database.store.Begin()
+	}
+	database.quit = make(chan struct{})
+	app.RegisterCacheUsage(app.CacheDatabase, database.cacheUsage)
+	app.RegisterMaintenance(MODULENAME, app.MaintenanceFlush, database.Sync, nil)
 	return nil
 }
 
+// Sync writes the dirty cache and flushes the writes of the database not synced yet to disk
+func (database *DatabaseService) Sync() error {
+	if err := database.store.Flush(); err != nil {
+		return err
+	}
+	if syncer, ok := database.db.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// cacheUsage report the bytes of blocks cached by leveldb
+func (database *DatabaseService) cacheUsage() int64 {
+	stat, err := database.db.Stat("leveldb.cachedblock")
+	if err != nil {
+		return 0
+	}
+	size, _ := strconv.ParseInt(stat, 10, 64)
+	return size
+}
+
+// DefaultConfig -> config
+func (database *DatabaseService) DefaultConfig() *DatabaseConfig {
+	return DefaultDatabaseConfig()
+}
+
 func (database *DatabaseService) Start(executeContext *app.ExecuteContext) error {
+	// a service wrapping an open store with NewDatabaseService was never initialized
+	if database.quit == nil {
+		return nil
+	}
+	if database.Config.FlushInterval > 0 {
+		database.wg.Add(1)
+		go database.flushLoop(time.Duration(database.Config.FlushInterval) * time.Second)
+	}
 	return nil
 }
 
+// flushLoop write the dirty cache to disk on every interval
+func (database *DatabaseService) flushLoop(interval time.Duration) {
+	defer database.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := database.store.Flush(); err != nil {
+				log.WithField("Reason", err).Error("flush dirty cache fail")
+			}
+		case <-database.quit:
+			return
+		}
+	}
+}
+
+// Stop write the dirty cache to disk, services using the database are stopped before
 func (database *DatabaseService) Stop(executeContext *app.ExecuteContext) error {
-	return nil
+	if database.quit == nil {
+		return nil
+	}
+	close(database.quit)
+	database.wg.Wait()
+	database.quit = nil
+	return database.Sync()
 }
 
+// LevelDb return the store of the chain data, reads see the writes still in the dirty cache
 func (database *DatabaseService) LevelDb() dbinterface.KeyValueStore {
-	return database.db
+	return database.store
+}
+
+// AncientDir return the directory of the flat files holding the chain history moved out of
+// the database, see the freezer package
+func (database *DatabaseService) AncientDir() string {
+	return path2.Join(database.path, "ancient")
 }
 
 func (database *DatabaseService) MemoryDb() dbinterface.KeyValueStore {
@@ -75,4 +202,23 @@ func (database *DatabaseService) MemoryDb() dbinterface.KeyValueStore {
 }
 
 type DatabaseConfig struct {
+	Engine        string `json:"engine"`        // storage engine, leveldb if empty
+	Cache         int    `json:"cache"`         // megabytes of read cache
+	Handles       int    `json:"handles"`       // count of open files
+	WriteBuffer   int    `json:"writeBuffer"`   // megabytes of write buffer, leveldb only
+	SyncWrite     bool   `json:"syncWrite"`     // fsync on every batch write
+	FlushInterval uint64 `json:"flushInterval"` // seconds between writes of the dirty cache, 0 disables the cache
+	DirtyCache    int    `json:"dirtyCache"`    // megabytes of dirty data that trigger a write at the next block commit before the interval
+}
+
+func DefaultDatabaseConfig() *DatabaseConfig {
+	return &DatabaseConfig{
+		Engine:        EngineLevelDB,
+		Cache:         16,
+		Handles:       512,
+		WriteBuffer:   4,
+		SyncWrite:     false,
+		FlushInterval: 5,
+		DirtyCache:    64,
+	}
 }
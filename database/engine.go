@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	path2 "path"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/database/badgerdb"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/leveldb"
+	"github.com/drep-project/DREP-Chain/database/pebbledb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Storage engines the chain data can be kept in
+const (
+	EngineLevelDB = "leveldb"
+	EngineBadger  = "badger"
+	EnginePebble  = "pebble"
+)
+
+// engineFile records in the data dir which engine wrote it, data written before the engine was
+// selectable has no such file and is leveldb
+const engineFile = "ENGINE"
+
+var DBEngineFlag = cli.StringFlag{
+	Name:  "db.engine",
+	Usage: "Storage engine of the chain data (leveldb, badger, pebble), an existing data dir keeps its engine",
+}
+
+// openEngine open the data dir with the configured engine
+func openEngine(path string, config *DatabaseConfig) (dbinterface.KeyValueStore, error) {
+	engine := config.Engine
+	if engine == "" {
+		engine = EngineLevelDB
+	}
+	existing, err := dataEngine(path)
+	if err != nil {
+		return nil, err
+	}
+	if existing != "" && existing != engine {
+		return nil, fmt.Errorf("data dir %s is stored by %s, not %s: export the chain and import it into a new data dir to change the engine", path, existing, engine)
+	}
+
+	var db dbinterface.KeyValueStore
+	switch engine {
+	case EngineLevelDB:
+		db, err = leveldb.NewWithOptions(path, config.Cache, config.Handles, config.WriteBuffer, config.SyncWrite, "")
+	case EngineBadger:
+		db, err = badgerdb.New(path, config.Cache, config.SyncWrite)
+	case EnginePebble:
+		db, err = pebbledb.New(path, config.Cache, config.Handles, config.SyncWrite)
+	default:
+		return nil, fmt.Errorf("unknown database engine %s", engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if existing == "" {
+		if err := ioutil.WriteFile(path2.Join(path, engineFile), []byte(engine+"\n"), 0644); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// dataEngine return the engine of the data dir, empty if the dir holds no data yet
+func dataEngine(path string) (string, error) {
+	content, err := ioutil.ReadFile(path2.Join(path, engineFile))
+	if err == nil {
+		return strings.TrimSpace(string(content)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	files, err := ioutil.ReadDir(path)
+	if os.IsNotExist(err) || len(files) == 0 {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return EngineLevelDB, nil
+}
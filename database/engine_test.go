@@ -0,0 +1,197 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+// engines open an empty store of every engine in dir
+var engines = []struct {
+	name string
+	open func(dir string) (dbinterface.KeyValueStore, error)
+}{
+	{"memorydb", func(string) (dbinterface.KeyValueStore, error) { return memorydb.New(), nil }},
+	{EngineLevelDB, func(dir string) (dbinterface.KeyValueStore, error) {
+		return openEngine(dir, &DatabaseConfig{Engine: EngineLevelDB, Cache: 16, Handles: 16})
+	}},
+	{EngineBadger, func(dir string) (dbinterface.KeyValueStore, error) {
+		return openEngine(dir, &DatabaseConfig{Engine: EngineBadger, Cache: 16})
+	}},
+	{EnginePebble, func(dir string) (dbinterface.KeyValueStore, error) {
+		return openEngine(dir, &DatabaseConfig{Engine: EnginePebble, Cache: 16, Handles: 16})
+	}},
+}
+
+func withEngines(t testing.TB, run func(name string, db dbinterface.KeyValueStore)) {
+	for _, engine := range engines {
+		dir, err := ioutil.TempDir("", "engine-"+engine.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := engine.open(dir)
+		if err != nil {
+			os.RemoveAll(dir)
+			t.Fatalf("%s: %v", engine.name, err)
+		}
+		run(engine.name, db)
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func iterated(it dbinterface.Iterator) string {
+	defer it.Release()
+	keys := ""
+	for it.Next() {
+		keys += string(it.Key())
+	}
+	return keys
+}
+
+func TestEngineConformance(t *testing.T) {
+	withEngines(t, func(name string, db dbinterface.KeyValueStore) {
+		for _, key := range []string{"a", "b", "ba", "bb", "c"} {
+			if err := db.Put([]byte(key), []byte("v"+key)); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if value, err := db.Get([]byte("ba")); err != nil || string(value) != "vba" {
+			t.Fatalf("%s: got %q %v", name, value, err)
+		}
+		if _, err := db.Get([]byte("x")); err == nil {
+			t.Fatalf("%s: got no error for a missing key", name)
+		}
+		if exist, err := db.Has([]byte("x")); err != nil || exist {
+			t.Fatalf("%s: missing key reported %v %v", name, exist, err)
+		}
+
+		checks := []struct {
+			it   dbinterface.Iterator
+			want string
+		}{
+			{db.NewIterator(), "abbabbc"},
+			{db.NewIteratorWithStart([]byte("b")), "bbabbc"},
+			{db.NewIteratorWithPrefix([]byte("b")), "bbabb"},
+			{db.NewRangeIterator([]byte("b"), []byte("c"), false), "bbabb"},
+			{db.NewRangeIterator([]byte("b"), []byte("c"), true), "bbbab"},
+			{db.NewRangeIterator(nil, []byte("bb"), true), "baba"},
+			{db.NewRangeIterator(nil, nil, true), "cbbbaba"},
+		}
+		for i, check := range checks {
+			if got := iterated(check.it); got != check.want {
+				t.Errorf("%s: iterator %d got %q, want %q", name, i, got, check.want)
+			}
+		}
+
+		batch := db.NewBatch()
+		batch.Delete([]byte("a"))
+		batch.Put([]byte("d"), []byte("vd"))
+		replayed := memorydb.New()
+		if err := batch.Replay(replayed); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := iterated(replayed.NewIterator()); got != "d" {
+			t.Errorf("%s: replayed %q", name, got)
+		}
+		if err := batch.Write(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := iterated(db.NewIterator()); got != "bbabbcd" {
+			t.Errorf("%s: after batch got %q", name, got)
+		}
+	})
+}
+
+func TestEngineMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "engine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := openEngine(dir, &DatabaseConfig{Engine: EnginePebble, Cache: 16, Handles: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if _, err := openEngine(dir, &DatabaseConfig{Engine: EngineLevelDB, Cache: 16, Handles: 16}); err == nil {
+		t.Fatal("opened pebble data with leveldb")
+	}
+	if engine, _ := dataEngine(dir); engine != EnginePebble {
+		t.Fatalf("got engine %s", engine)
+	}
+}
+
+func benchKey(i int) []byte {
+	key := make([]byte, 32)
+	binary.BigEndian.PutUint64(key[24:], uint64(i)*2654435761)
+	return key
+}
+
+var benchValue = bytes.Repeat([]byte{0xaa}, 128)
+
+func BenchmarkEngines(b *testing.B) {
+	for _, engine := range engines {
+		engine := engine
+		bench := func(name string, prepare int, run func(b *testing.B, db dbinterface.KeyValueStore)) {
+			b.Run(fmt.Sprintf("%s/%s", engine.name, name), func(b *testing.B) {
+				dir, err := ioutil.TempDir("", "engine-bench")
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer os.RemoveAll(dir)
+				db, err := engine.open(dir)
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer db.Close()
+				batch := db.NewBatch()
+				for i := 0; i < prepare; i++ {
+					batch.Put(benchKey(i), benchValue)
+				}
+				if err := batch.Write(); err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+				run(b, db)
+			})
+		}
+
+		bench("Put", 0, func(b *testing.B, db dbinterface.KeyValueStore) {
+			for i := 0; i < b.N; i++ {
+				db.Put(benchKey(i), benchValue)
+			}
+		})
+		bench("Get", 10000, func(b *testing.B, db dbinterface.KeyValueStore) {
+			for i := 0; i < b.N; i++ {
+				db.Get(benchKey(i % 10000))
+			}
+		})
+		bench("Batch", 0, func(b *testing.B, db dbinterface.KeyValueStore) {
+			batch := db.NewBatch()
+			for i := 0; i < b.N; i++ {
+				batch.Put(benchKey(i), benchValue)
+				if batch.ValueSize() >= 1024*1024 {
+					batch.Write()
+					batch.Reset()
+				}
+			}
+			batch.Write()
+		})
+		bench("Iterate", 10000, func(b *testing.B, db dbinterface.KeyValueStore) {
+			for i := 0; i < b.N; i++ {
+				it := db.NewRangeIterator(nil, nil, i%2 == 1)
+				for it.Next() {
+				}
+				it.Release()
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package freezer stores immutable chain history in append-only flat files, out of the key
+// value store. Items are numbered by block height from 0, every table holds one item per
+// height, so a frozen block is read with two file reads and the files are copied as they are
+// for a backup.
+package freezer
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrOutOfBounds  = errors.New("item not in the freezer")
+	ErrOutOfOrder   = errors.New("item not appended in order")
+	ErrUnknownTable = errors.New("unknown freezer table")
+	ErrMissingItem  = errors.New("item missing a table")
+)
+
+// Freezer is a set of tables growing together, item n of each table belongs to height n
+type Freezer struct {
+	lock   sync.Mutex // serializes appends and truncates
+	items  uint64     // count of items in every table, accessed atomically
+	tables map[string]*table
+}
+
+// Open open or create the tables in dir, tables left uneven by a crash are truncated to
+// the shortest one
+func Open(dir string, tables []string) (*Freezer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	freezer := &Freezer{tables: make(map[string]*table, len(tables))}
+	items := ^uint64(0)
+	for _, name := range tables {
+		t, err := openTable(dir, name)
+		if err != nil {
+			freezer.Close()
+			return nil, err
+		}
+		freezer.tables[name] = t
+		if t.items < items {
+			items = t.items
+		}
+	}
+	if len(tables) == 0 {
+		items = 0
+	}
+	for _, t := range freezer.tables {
+		if err := t.truncate(items); err != nil {
+			freezer.Close()
+			return nil, err
+		}
+	}
+	freezer.items = items
+	return freezer, nil
+}
+
+// Items return the count of items, the next item appended is numbered with it
+func (freezer *Freezer) Items() uint64 {
+	return atomic.LoadUint64(&freezer.items)
+}
+
+// Retrieve read item of table kind
+func (freezer *Freezer) Retrieve(kind string, item uint64) ([]byte, error) {
+	t, ok := freezer.tables[kind]
+	if !ok {
+		return nil, ErrUnknownTable
+	}
+	if item >= freezer.Items() {
+		return nil, ErrOutOfBounds
+	}
+	return t.retrieve(item)
+}
+
+// Append add item to every table, blobs hold the data of each table. The item is not durable
+// before Sync
+func (freezer *Freezer) Append(item uint64, blobs map[string][]byte) error {
+	freezer.lock.Lock()
+	defer freezer.lock.Unlock()
+	if item != freezer.Items() {
+		return ErrOutOfOrder
+	}
+	for name := range freezer.tables {
+		if _, ok := blobs[name]; !ok {
+			return ErrMissingItem
+		}
+	}
+	for name, t := range freezer.tables {
+		if err := t.append(blobs[name]); err != nil {
+			// keep the tables even, the item is appended again later
+			for _, t := range freezer.tables {
+				t.truncate(item)
+			}
+			return err
+		}
+	}
+	atomic.StoreUint64(&freezer.items, item+1)
+	return nil
+}
+
+// Truncate drop the items from item on
+func (freezer *Freezer) Truncate(items uint64) error {
+	freezer.lock.Lock()
+	defer freezer.lock.Unlock()
+	if items >= freezer.Items() {
+		return nil
+	}
+	atomic.StoreUint64(&freezer.items, items)
+	for _, t := range freezer.tables {
+		if err := t.truncate(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync flush the tables to disk
+func (freezer *Freezer) Sync() error {
+	for _, t := range freezer.tables {
+		if err := t.sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (freezer *Freezer) Close() error {
+	var err error
+	for _, t := range freezer.tables {
+		if closeErr := t.close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
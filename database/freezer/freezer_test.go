@@ -0,0 +1,116 @@
+package freezer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testTables = []string{"hashes", "blocks"}
+
+func testBlobs(item uint64) map[string][]byte {
+	return map[string][]byte{
+		"hashes": []byte(fmt.Sprintf("hash%d", item)),
+		"blocks": bytes.Repeat([]byte{byte(item)}, int(item)),
+	}
+}
+
+func TestFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	freezer, err := Open(dir, testTables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item := uint64(0); item < 10; item++ {
+		if err := freezer.Append(item, testBlobs(item)); err != nil {
+			t.Fatalf("append %d: %v", item, err)
+		}
+	}
+	if err := freezer.Append(11, testBlobs(11)); err != ErrOutOfOrder {
+		t.Errorf("append out of order: got %v, want %v", err, ErrOutOfOrder)
+	}
+	if err := freezer.Append(10, map[string][]byte{"hashes": nil}); err != ErrMissingItem {
+		t.Errorf("append missing table: got %v, want %v", err, ErrMissingItem)
+	}
+	if err := freezer.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	freezer.Close()
+
+	// reopened, the items are still there
+	if freezer, err = Open(dir, testTables); err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+	if freezer.Items() != 10 {
+		t.Fatalf("items %d, want 10", freezer.Items())
+	}
+	for item := uint64(0); item < 10; item++ {
+		for name, want := range testBlobs(item) {
+			blob, err := freezer.Retrieve(name, item)
+			if err != nil || !bytes.Equal(blob, want) {
+				t.Errorf("%s %d: got %x, %v, want %x", name, item, blob, err, want)
+			}
+		}
+	}
+	if _, err := freezer.Retrieve("blocks", 10); err != ErrOutOfBounds {
+		t.Errorf("retrieve past end: got %v, want %v", err, ErrOutOfBounds)
+	}
+	if _, err := freezer.Retrieve("receipts", 0); err != ErrUnknownTable {
+		t.Errorf("retrieve unknown table: got %v, want %v", err, ErrUnknownTable)
+	}
+
+	if err := freezer.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := freezer.Append(5, testBlobs(5)); err != nil {
+		t.Fatalf("append after truncate: %v", err)
+	}
+}
+
+func TestFreezerRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	freezer, err := Open(dir, testTables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for item := uint64(0); item < 5; item++ {
+		if err := freezer.Append(item, testBlobs(item)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	freezer.Close()
+
+	// a crash cut the last block item in half and left the hashes table ahead
+	data := filepath.Join(dir, "blocks.dat")
+	info, _ := os.Stat(data)
+	if err := os.Truncate(data, info.Size()-2); err != nil {
+		t.Fatal(err)
+	}
+	if freezer, err = Open(dir, testTables); err != nil {
+		t.Fatal(err)
+	}
+	defer freezer.Close()
+	if freezer.Items() != 4 {
+		t.Fatalf("items %d after repair, want 4", freezer.Items())
+	}
+	if err := freezer.Append(4, testBlobs(4)); err != nil {
+		t.Fatalf("append after repair: %v", err)
+	}
+	if blob, err := freezer.Retrieve("blocks", 4); err != nil || !bytes.Equal(blob, testBlobs(4)["blocks"]) {
+		t.Errorf("block 4 after repair: %x, %v", blob, err)
+	}
+}
@@ -0,0 +1,159 @@
+package freezer
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// indexEntrySize is the size of an index entry, the end offset of an item in the data file
+const indexEntrySize = 8
+
+// table is an append-only flat file of items numbered from 0. The data file holds the items
+// back to back, entry i of the index file holds the offset where item i ends
+type table struct {
+	lock  sync.RWMutex
+	data  *os.File
+	index *os.File
+	items uint64 // count of items
+	size  uint64 // bytes of data
+}
+
+// openTable open the table name in dir, a partial append of a crash is cut off
+func openTable(dir, name string) (*table, error) {
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	t := &table{data: data, index: index}
+	if err := t.repair(); err != nil {
+		t.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// repair drop the index entries pointing past the data and the data of no index entry
+func (t *table) repair() error {
+	indexInfo, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	dataInfo, err := t.data.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(indexInfo.Size()) / indexEntrySize
+	end := uint64(0)
+	for items > 0 {
+		if end, err = t.end(items - 1); err != nil {
+			return err
+		}
+		if end <= uint64(dataInfo.Size()) {
+			break
+		}
+		items--
+		end = 0
+	}
+	return t.truncateTo(items, end)
+}
+
+// end return the offset where item ends in the data file
+func (t *table) end(item uint64) (uint64, error) {
+	var buf [indexEntrySize]byte
+	if _, err := t.index.ReadAt(buf[:], int64(item*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func (t *table) truncateTo(items, size uint64) error {
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(size)); err != nil {
+		return err
+	}
+	t.items, t.size = items, size
+	return nil
+}
+
+// append write blob as the next item
+func (t *table) append(blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	var buf [indexEntrySize]byte
+	binary.BigEndian.PutUint64(buf[:], t.size+uint64(len(blob)))
+	if _, err := t.index.WriteAt(buf[:], int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.items++
+	t.size += uint64(len(blob))
+	return nil
+}
+
+// retrieve read item
+func (t *table) retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if item >= t.items {
+		return nil, ErrOutOfBounds
+	}
+	start := uint64(0)
+	if item > 0 {
+		var err error
+		if start, err = t.end(item - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, err := t.end(item)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// truncate drop the items from item on
+func (t *table) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if items >= t.items {
+		return nil
+	}
+	size := uint64(0)
+	if items > 0 {
+		var err error
+		if size, err = t.end(items - 1); err != nil {
+			return err
+		}
+	}
+	return t.truncateTo(items, size)
+}
+
+func (t *table) sync() error {
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+func (t *table) close() error {
+	err := t.data.Close()
+	if indexErr := t.index.Close(); err == nil {
+		err = indexErr
+	}
+	return err
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
@@ -50,17 +51,24 @@ const (
 	// database files.
 	minHandles = 16
 
+	// minWriteBuffer is the minimum size in megabytes of leveldb memtable.
+	minWriteBuffer = 4
+
 	// metricsGatheringInterval specifies the interval to retrieve leveldb database
 	// compaction, io and pause stats to report to the user.
 	metricsGatheringInterval = 3 * time.Second
 )
 
+// syncMarkerKey is the key put by Sync to flush the journal
+var syncMarkerKey = []byte("leveldbSyncMarker")
+
 // Database is a persistent key-value store. Apart from basic data storage
 // functionality it also supports batch writes and iterating over the keyspace in
 // binary-alphabetical order.
 type Database struct {
-	fn string      // filename for reporting
-	db *leveldb.DB // LevelDB instance
+	fn        string            // filename for reporting
+	db        *leveldb.DB       // LevelDB instance
+	writeOpts *opt.WriteOptions // options of batch write, sync or not
 
 	quitLock sync.Mutex      // Mutex protecting the quit channel access
 	quitChan chan chan error // Quit channel to stop the metrics collection before closing the database
@@ -71,6 +79,15 @@ type Database struct {
 // New returns a wrapped LevelDB object. The namespace is the prefix that the
 // metrics reporting should use for surfacing internal stats.
 func New(file string, cache int, handles int, namespace string) (*Database, error) {
+	if cache < minCache {
+		cache = minCache
+	}
+	return NewWithOptions(file, cache, handles, cache/4, false, namespace)
+}
+
+// NewWithOptions returns a wrapped LevelDB object with explicit write buffer size in
+// megabytes, syncWrite decide whether batch writes are flushed to disk by fsync.
+func NewWithOptions(file string, cache int, handles int, writeBuffer int, syncWrite bool, namespace string) (*Database, error) {
 	// Ensure we have some minimal caching and file guarantees
 	if cache < minCache {
 		cache = minCache
@@ -78,14 +95,17 @@ func New(file string, cache int, handles int, namespace string) (*Database, erro
 	if handles < minHandles {
 		handles = minHandles
 	}
+	if writeBuffer < minWriteBuffer {
+		writeBuffer = minWriteBuffer
+	}
 	logger := log.New("database", file)
-	logger.Info("Allocated cache and file handles", "cache", common.StorageSize(cache*1024*1024), "handles", handles)
+	logger.Info("Allocated cache and file handles", "cache", common.StorageSize(cache*1024*1024), "handles", handles, "writeBuffer", common.StorageSize(writeBuffer*1024*1024), "sync", syncWrite)
 
 	// OpenWallet the db and recover any potential corruptions
 	db, err := leveldb.OpenFile(file, &opt.Options{
 		OpenFilesCacheCapacity: handles,
 		BlockCacheCapacity:     cache / 2 * opt.MiB,
-		WriteBuffer:            cache / 4 * opt.MiB, // Two of these are used internally
+		WriteBuffer:            writeBuffer * opt.MiB, // Two of these are used internally
 		Filter:                 filter.NewBloomFilter(10),
 	})
 	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
@@ -96,10 +116,11 @@ func New(file string, cache int, handles int, namespace string) (*Database, erro
 	}
 	// Assemble the wrapper with all the registered metrics
 	ldb := &Database{
-		fn:       file,
-		db:       db,
-		log:      logger,
-		quitChan: make(chan chan error),
+		fn:        file,
+		db:        db,
+		writeOpts: &opt.WriteOptions{Sync: syncWrite},
+		log:       logger,
+		quitChan:  make(chan chan error),
 	}
 
 	// Start up the metrics gathering and return
@@ -139,12 +160,19 @@ func (db *Database) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
+// Sync flushes the writes not synced yet to disk. An empty batch is not written
+// by leveldb, so a marker key is put with sync to fsync the journal before it.
+func (db *Database) Sync() error {
+	return db.db.Put(syncMarkerKey, nil, &opt.WriteOptions{Sync: true})
+}
+
 // NewBatch creates a write-only key-value store that buffers changes to its host
 // database until a final write is called.
 func (db *Database) NewBatch() dbinterface.Batch {
 	return &batch{
-		db: db.db,
-		b:  new(leveldb.Batch),
+		db:        db.db,
+		b:         new(leveldb.Batch),
+		writeOpts: db.writeOpts,
 	}
 }
 
@@ -167,6 +195,30 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) dbinterface.Iterator {
 	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
 }
 
+// NewRangeIterator creates an iterator over the keys in [start, limit), in
+// descending order if reverse is set.
+func (db *Database) NewRangeIterator(start []byte, limit []byte, reverse bool) dbinterface.Iterator {
+	it := db.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+	if reverse {
+		return &reverseIterator{Iterator: it}
+	}
+	return it
+}
+
+// reverseIterator walks a leveldb iterator from its last key backwards.
+type reverseIterator struct {
+	iterator.Iterator
+	started bool
+}
+
+func (it *reverseIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		return it.Iterator.Last()
+	}
+	return it.Iterator.Prev()
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
 	return db.db.GetProperty(property)
@@ -345,9 +397,10 @@ func (db *Database) meter1(refresh time.Duration) {
 // batch is a write-only leveldb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type batch struct {
-	db   *leveldb.DB
-	b    *leveldb.Batch
-	size int
+	db        *leveldb.DB
+	b         *leveldb.Batch
+	writeOpts *opt.WriteOptions
+	size      int
 }
 
 // Put inserts the given value into the batch for later committing.
@@ -371,7 +424,7 @@ func (b *batch) ValueSize() int {
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
-	return b.db.Write(b.b, nil)
+	return b.db.Write(b.b, b.writeOpts)
 }
 
 // Reset resets the batch for reuse.
@@ -193,6 +193,39 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) dbinterface.Iterator {
 	}
 }
 
+// NewRangeIterator creates an iterator over the keys in [start, limit), in
+// descending order if reverse is set.
+func (db *Database) NewRangeIterator(start []byte, limit []byte, reverse bool) dbinterface.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		st     = string(start)
+		lm     = string(limit)
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	// Collect the keys from the memory database inside the range
+	for key := range db.db {
+		if key >= st && (limit == nil || key < lm) {
+			keys = append(keys, key)
+		}
+	}
+	// Sort the items and retrieve the associated values
+	if reverse {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	} else {
+		sort.Strings(keys)
+	}
+	for _, key := range keys {
+		values = append(values, db.db[key])
+	}
+	return &iterator{
+		keys:   keys,
+		values: values,
+	}
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *Database) Stat(property string) (string, error) {
 	return "", errors.New("unknown property")
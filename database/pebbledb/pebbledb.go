@@ -0,0 +1,304 @@
+// Package pebbledb implements the key-value database layer based on Pebble.
+package pebbledb
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+const (
+	// minCache is the minimum amount of memory in megabytes to allocate to pebble
+	// read and write caching, split half and half.
+	minCache = 16
+
+	// minHandles is the minimum number of files handles to allocate to the open
+	// database files.
+	minHandles = 16
+)
+
+// Database is a persistent key-value store. Apart from basic data storage
+// functionality it also supports batch writes and iterating over the keyspace in
+// binary-alphabetical order.
+type Database struct {
+	fn string     // filename for reporting
+	db *pebble.DB // Underlying pebble storage engine
+
+	writeOptions *pebble.WriteOptions
+	closeOnce    sync.Once
+}
+
+// New returns a wrapped pebble DB object, cache is the memory in megabytes shared
+// by the block cache and the memtable.
+func New(file string, cache int, handles int, syncWrite bool) (*Database, error) {
+	if cache < minCache {
+		cache = minCache
+	}
+	if handles < minHandles {
+		handles = minHandles
+	}
+	db, err := pebble.Open(file, &pebble.Options{
+		Cache:        pebble.NewCache(int64(cache/2) * 1024 * 1024),
+		MaxOpenFiles: handles,
+		MemTableSize: uint64(cache/2) * 1024 * 1024 / 2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeOptions := pebble.NoSync
+	if syncWrite {
+		writeOptions = pebble.Sync
+	}
+	return &Database{fn: file, db: db, writeOptions: writeOptions}, nil
+}
+
+// Close flushes any pending data to disk and closes all io accesses to the
+// underlying key-value store.
+func (db *Database) Close() error {
+	var err error
+	db.closeOnce.Do(func() { err = db.db.Close() })
+	return err
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	dat, closer, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, len(dat))
+	copy(ret, dat)
+	closer.Close()
+	return ret, nil
+}
+
+// Put inserts the given value into the key-value store.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.db.Set(key, value, db.writeOptions)
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	return db.db.Delete(key, db.writeOptions)
+}
+
+// Sync flushes the memtable to disk.
+func (db *Database) Sync() error {
+	return db.db.Flush()
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() dbinterface.Batch {
+	return &batch{
+		b:  db.db.NewBatch(),
+		db: db,
+	}
+}
+
+// NewIterator creates a binary-alphabetical iterator over the entire keyspace
+// contained within the pebble database.
+func (db *Database) NewIterator() dbinterface.Iterator {
+	return db.NewRangeIterator(nil, nil, false)
+}
+
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// database content starting at a particular initial key (or after, if it does
+// not exist).
+func (db *Database) NewIteratorWithStart(start []byte) dbinterface.Iterator {
+	return db.NewRangeIterator(start, nil, false)
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix.
+func (db *Database) NewIteratorWithPrefix(prefix []byte) dbinterface.Iterator {
+	return db.NewRangeIterator(prefix, prefixLimit(prefix), false)
+}
+
+// NewRangeIterator creates an iterator over the keys in [start, limit), in
+// descending order if reverse is set.
+func (db *Database) NewRangeIterator(start []byte, limit []byte, reverse bool) dbinterface.Iterator {
+	it, err := db.db.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: limit})
+	return &iterator{iter: it, reverse: reverse, err: err}
+}
+
+// Stat returns a particular internal stat of the database, "pebble.metrics" is
+// the formatted metrics of the LSM tree.
+func (db *Database) Stat(property string) (string, error) {
+	switch property {
+	case "pebble.metrics":
+		return db.db.Metrics().String(), nil
+	case "pebble.size":
+		return strconv.FormatUint(db.db.Metrics().DiskSpaceUsage(), 10), nil
+	}
+	return "", errors.New("unknown property")
+}
+
+// Compact flattens the underlying data store for the given key range. In essence,
+// deleted and overwritten versions are discarded, and the data is rearranged to
+// reduce the cost of operations needed to access them.
+//
+// A nil start is treated as a key before all keys in the data store; a nil limit
+// is treated as a key after all keys in the data store. If both is nil then it
+// will compact entire data store.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	// pebble requires an upper bound, all keys written by the chain are shorter
+	// than 32 bytes of 0xff
+	if limit == nil {
+		limit = bytes.Repeat([]byte{0xff}, 32)
+	}
+	return db.db.Compact(start, limit, true)
+}
+
+// Path returns the path to the database directory.
+func (db *Database) Path() string {
+	return db.fn
+}
+
+// prefixLimit returns the smallest key greater than all keys with the prefix, nil
+// if there is none.
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte{}, prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// batch is a write-only pebble batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	b    *pebble.Batch
+	db   *Database
+	size int
+}
+
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.size += len(value)
+	return b.b.Set(key, value, nil)
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.size++
+	return b.b.Delete(key, nil)
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	return b.b.Commit(b.db.writeOptions)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// Replay replays the batch contents.
+func (b *batch) Replay(w dbinterface.KeyValueWriter) error {
+	reader := b.b.Reader()
+	for {
+		kind, k, v, ok, err := reader.Next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		switch kind {
+		case pebble.InternalKeyKindSet:
+			err = w.Put(k, v)
+		case pebble.InternalKeyKindDelete:
+			err = w.Delete(k)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// iterator wraps a pebble iterator, which has to be positioned before the first
+// call to Next.
+type iterator struct {
+	iter    *pebble.Iterator
+	reverse bool
+	started bool
+	err     error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.iter == nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		if it.reverse {
+			return it.iter.Last()
+		}
+		return it.iter.First()
+	}
+	if it.reverse {
+		return it.iter.Prev()
+	}
+	return it.iter.Next()
+}
+
+// Error returns any accumulated error.
+func (it *iterator) Error() error {
+	if it.err != nil || it.iter == nil {
+		return it.err
+	}
+	return it.iter.Error()
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (it *iterator) Key() []byte {
+	if it.iter == nil || !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (it *iterator) Value() []byte {
+	if it.iter == nil || !it.iter.Valid() {
+		return nil
+	}
+	return it.iter.Value()
+}
+
+// Release releases associated resources.
+func (it *iterator) Release() {
+	if it.iter != nil {
+		it.iter.Close()
+		it.iter = nil
+	}
+}
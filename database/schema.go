@@ -0,0 +1,144 @@
+package database
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+const (
+	// BaseSchemaVersion is the layout written by nodes that predate schema versioning,
+	// a non-empty database without version key is assumed to be at this version
+	BaseSchemaVersion = uint64(1)
+)
+
+var (
+	// SchemaVersionKey holds the big endian schema version of the node database
+	SchemaVersionKey = []byte("schemaVersion")
+
+	ErrSchemaTooNew          = errors.New("database schema is newer than supported by this node")
+	ErrDuplicateMigration    = errors.New("duplicate schema migration version")
+	ErrInvalidMigrationOrder = errors.New("schema migration version must be above the base version")
+
+	migrationLock sync.Mutex
+	migrations    []Migration
+)
+
+// Migration upgrades the database from Version-1 to Version. Migrate must be safe to
+// run again on a partially migrated database, the version is only stamped after it returns
+type Migration struct {
+	Version uint64
+	Name    string
+	Migrate func(db dbinterface.KeyValueStore) error
+}
+
+// RegisterMigration add a migration, stores changing their format register one in init
+func RegisterMigration(migration Migration) error {
+	migrationLock.Lock()
+	defer migrationLock.Unlock()
+	if migration.Version <= BaseSchemaVersion {
+		return ErrInvalidMigrationOrder
+	}
+	for _, m := range migrations {
+		if m.Version == migration.Version {
+			return ErrDuplicateMigration
+		}
+	}
+	migrations = append(migrations, migration)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return nil
+}
+
+// LatestSchemaVersion is the version a fully migrated database is at
+func LatestSchemaVersion() uint64 {
+	migrationLock.Lock()
+	defer migrationLock.Unlock()
+	return latestSchemaVersion(migrations)
+}
+
+func latestSchemaVersion(migrations []Migration) uint64 {
+	if len(migrations) == 0 {
+		return BaseSchemaVersion
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// ReadSchemaVersion return the stamped version, ok is false if the key is missing
+func ReadSchemaVersion(db dbinterface.KeyValueReader) (version uint64, ok bool, err error) {
+	has, err := db.Has(SchemaVersionKey)
+	if err != nil || !has {
+		return 0, false, err
+	}
+	val, err := db.Get(SchemaVersionKey)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(val) != 8 {
+		return 0, false, fmt.Errorf("invalid schema version %x", val)
+	}
+	return binary.BigEndian.Uint64(val), true, nil
+}
+
+// WriteSchemaVersion stamp the database with version
+func WriteSchemaVersion(db dbinterface.KeyValueWriter, version uint64) error {
+	val := make([]byte, 8)
+	binary.BigEndian.PutUint64(val, version)
+	return db.Put(SchemaVersionKey, val)
+}
+
+// MigrateSchema bring the database to the latest schema. A fresh database is stamped
+// directly, an older one runs the pending migrations in order, and a database written
+// by a newer node is refused instead of being read with the wrong layout
+func MigrateSchema(db dbinterface.KeyValueStore) error {
+	migrationLock.Lock()
+	pending := make([]Migration, len(migrations))
+	copy(pending, migrations)
+	migrationLock.Unlock()
+	return migrateSchema(db, pending)
+}
+
+func migrateSchema(db dbinterface.KeyValueStore, migrations []Migration) error {
+	latest := latestSchemaVersion(migrations)
+	version, ok, err := ReadSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if isEmpty(db) {
+			log.WithField("version", latest).Info("Initialize database schema")
+			return WriteSchemaVersion(db, latest)
+		}
+		version = BaseSchemaVersion
+	}
+	if version > latest {
+		return fmt.Errorf("%v: database %d, supported %d", ErrSchemaTooNew, version, latest)
+	}
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+		log.WithField("from", version).WithField("to", migration.Version).WithField("name", migration.Name).Info("Migrate database schema")
+		if err := migration.Migrate(db); err != nil {
+			return fmt.Errorf("migration %d (%s): %v", migration.Version, migration.Name, err)
+		}
+		if err := WriteSchemaVersion(db, migration.Version); err != nil {
+			return err
+		}
+		version = migration.Version
+	}
+	if !ok {
+		// legacy database already at the latest layout, stamp it
+		return WriteSchemaVersion(db, version)
+	}
+	return nil
+}
+
+func isEmpty(db dbinterface.Iteratee) bool {
+	it := db.NewIterator()
+	defer it.Release()
+	return !it.Next()
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func TestMigrateSchemaFresh(t *testing.T) {
+	db := memorydb.New()
+	migrations := []Migration{{Version: 2, Name: "never", Migrate: func(dbinterface.KeyValueStore) error {
+		t.Fatal("migration run on fresh database")
+		return nil
+	}}}
+	if err := migrateSchema(db, migrations); err != nil {
+		t.Fatal(err)
+	}
+	if version, ok, _ := ReadSchemaVersion(db); !ok || version != 2 {
+		t.Fatalf("got version %d %v, want 2", version, ok)
+	}
+}
+
+func TestMigrateSchemaLegacy(t *testing.T) {
+	db := memorydb.New()
+	db.Put([]byte("block_1"), []byte{1})
+
+	var ran []uint64
+	step := func(version uint64) Migration {
+		return Migration{Version: version, Migrate: func(dbinterface.KeyValueStore) error {
+			ran = append(ran, version)
+			return nil
+		}}
+	}
+	if err := migrateSchema(db, []Migration{step(2), step(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 2 || ran[0] != 2 || ran[1] != 3 {
+		t.Fatalf("unexpected migrations %v", ran)
+	}
+	if version, _, _ := ReadSchemaVersion(db); version != 3 {
+		t.Fatalf("got version %d, want 3", version)
+	}
+
+	// already migrated
+	ran = nil
+	if err := migrateSchema(db, []Migration{step(2), step(3)}); err != nil || len(ran) != 0 {
+		t.Fatalf("rerun migrations %v, err %v", ran, err)
+	}
+}
+
+func TestMigrateSchemaFailure(t *testing.T) {
+	db := memorydb.New()
+	WriteSchemaVersion(db, 1)
+	migrations := []Migration{
+		{Version: 2, Migrate: func(dbinterface.KeyValueStore) error { return nil }},
+		{Version: 3, Migrate: func(dbinterface.KeyValueStore) error { return errors.New("boom") }},
+	}
+	if err := migrateSchema(db, migrations); err == nil {
+		t.Fatal("expect migration error")
+	}
+	// the completed step is kept, the failed one is retried on next start
+	if version, _, _ := ReadSchemaVersion(db); version != 2 {
+		t.Fatalf("got version %d, want 2", version)
+	}
+}
+
+func TestMigrateSchemaTooNew(t *testing.T) {
+	db := memorydb.New()
+	WriteSchemaVersion(db, BaseSchemaVersion+1)
+	if err := migrateSchema(db, nil); err == nil {
+		t.Fatal("expect newer schema to be refused")
+	}
+}
+
+func TestRegisterMigration(t *testing.T) {
+	if err := RegisterMigration(Migration{Version: BaseSchemaVersion}); err != ErrInvalidMigrationOrder {
+		t.Fatalf("got %v, want %v", err, ErrInvalidMigrationOrder)
+	}
+}
@@ -8,70 +8,163 @@ replace (
 
 	cloud.google.com/go/pubsub => github.com/googleapis/google-cloud-go/pubsub v1.0.1
 	cloud.google.com/go/storage => github.com/googleapis/google-cloud-go/storage v1.3.0
-	golang.org/x/crypto => github.com/golang/crypto v0.0.0-20191111213947-16651526fdb4
-	golang.org/x/exp => github.com/golang/exp v0.0.0-20190731235908-ec7cb31e5a56
 	golang.org/x/image => github.com/golang/image v0.0.0-20191009234506-e7c1f5e7dbb8
 	golang.org/x/lint => github.com/golang/lint v0.0.0-20190930215403-16217165b5de
 	golang.org/x/mobile => github.com/golang/mobile v0.0.0-20191031020345-0945064e013a
 	golang.org/x/mod => github.com/golang/mod v0.1.0
-	golang.org/x/net => github.com/golang/net v0.0.0-20191109021931-daa7c04131f5
 	golang.org/x/oauth2 => github.com/golang/oauth2 v0.0.0-20190604053449-0f29369cfe45
-	golang.org/x/sync => github.com/golang/sync v0.0.0-20190911185100-cd5d95a43a6e
-	golang.org/x/sys => github.com/golang/sys v0.0.0-20191110163157-d32e6e3b99c4
-	golang.org/x/text => github.com/golang/text v0.3.2
+	golang.org/x/sync => github.com/golang/sync v0.7.0
+	golang.org/x/text => github.com/golang/text v0.14.0
 	golang.org/x/time => github.com/golang/time v0.0.0-20191024005414-555d28b269f0
 	golang.org/x/tools => github.com/golang/tools v0.0.0-20191112005509-a3f652f18032
 	golang.org/x/xerrors => github.com/golang/xerrors v0.0.0-20191011141410-1b5146add898
 	google.golang.org/api => github.com/googleapis/google-api-go-client v0.8.0
 	google.golang.org/appengine => github.com/golang/appengine v1.6.5
-	google.golang.org/genproto => github.com/google/go-genproto v0.0.0-20191108220845-16a3f7862a1a
-	google.golang.org/grpc => github.com/grpc/grpc-go v1.25.1
 	gopkg.in/urfave/cli.v1 => github.com/urfave/cli v1.21.0
 )
 
-go 1.13
+go 1.20
 
 require (
 	github.com/AsynkronIT/protoactor-go v0.0.0-20200317173033-c483abfa40e2
 	github.com/allegro/bigcache v1.2.1
-	github.com/aristanetworks/goarista v0.0.0-20200513152637-638451432ae4
+	github.com/aristanetworks/goarista v0.0.0-20200602234848-db8a79a18e4a
 	github.com/asaskevich/EventBus v0.0.0-20200428142821-4fc0642a29f3
 	github.com/btcsuite/btcd v0.20.1-beta
 	github.com/btcsuite/btcutil v1.0.2
-	github.com/cosmos/cosmos-sdk v0.38.3
+	github.com/cockroachdb/pebble v1.1.2
+	github.com/cosmos/cosmos-sdk v0.38.4
 	github.com/davecgh/go-spew v1.1.1
+	github.com/dgraph-io/badger/v2 v2.2007.4
 	github.com/docker/docker v1.13.1
 	github.com/drep-project/binary v0.0.0-20190919035907-78d88687b9c1
 	github.com/drep-project/dlog v0.0.0-20200514080736-e9b04787eae9
-	github.com/drep-project/drep-chain v0.0.0-20200513090939-9faecf3157e0
-	github.com/drep-project/rpc v0.0.0-20200514081031-c317a9a1ce9d
-	github.com/ethereum/go-ethereum v1.9.14
+	github.com/drep-project/rpc v0.0.0-20200619075325-34a34786ab54
+	github.com/ethereum/go-ethereum v1.9.15
 	github.com/fatih/color v1.9.0
-	github.com/golang/snappy v0.0.1
+	github.com/golang/snappy v0.0.4
 	github.com/huin/goupnp v1.0.0
 	github.com/jackpal/go-nat-pmp v1.0.2
 	github.com/julienschmidt/httprouter v1.3.0
-	github.com/mattn/go-colorable v0.1.6
-	github.com/meling/urs v0.0.0-20140826003057-dfe7ae28e94c
-	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
+	github.com/lib/pq v1.1.1
+	github.com/mattn/go-colorable v0.1.13
 	github.com/peterh/liner v1.2.0
 	github.com/pingcap/errors v0.11.4
 	github.com/pkg/errors v0.9.1
 	github.com/robertkrimen/otto v0.0.0-20191219234010-c382bd3c16ff
-	github.com/rubblelabs/ripple v0.0.0-20200504074039-c8844ec07a94
+	github.com/rubblelabs/ripple v0.0.0-20200519102443-e15c7b29cbd7
 	github.com/sasaxie/go-client-api v0.0.0-20190820063117-f0587df4b72e
-	github.com/shengdoushi/base58 v1.0.0 // indirect
 	github.com/shiena/ansicolor v0.0.0-20151119151921-a422bbe96644
-	github.com/sirupsen/logrus v1.6.0
-	github.com/stretchr/testify v1.5.1
+	github.com/sirupsen/logrus v1.9.0
+	github.com/stretchr/testify v1.9.0
 	github.com/syndtr/goleveldb v1.0.1-0.20190923125748-758128399b1d
 	github.com/vishalkuo/bimap v0.0.0-20180703190407-09cff2814645
 	github.com/x-cray/logrus-prefixed-formatter v0.5.2
-	go.mongodb.org/mongo-driver v1.3.3
-	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
-	golang.org/x/net v0.0.0-20200513185701-a91f0712d120
-	golang.org/x/sys v0.0.0-20200513112337-417ce2331b5c
+	go.mongodb.org/mongo-driver v1.3.4
+	golang.org/x/crypto v0.21.0
+	golang.org/x/net v0.23.0
+	golang.org/x/sys v0.18.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
-	gopkg.in/sourcemap.v1 v1.0.5 // indirect
 	gopkg.in/urfave/cli.v1 v1.20.0
 )
+
+require (
+	github.com/ChainSafe/go-schnorrkel v0.0.0-20200102211924-4bcbc698314f // indirect
+	github.com/DataDog/zstd v1.4.5 // indirect
+	github.com/StackExchange/wmi v0.0.0-20190523213315-cbe66965904d // indirect
+	github.com/VictoriaMetrics/fastcache v1.5.7 // indirect
+	github.com/Workiva/go-datastructures v1.0.52 // indirect
+	github.com/astaxie/beego v1.12.1 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash v1.1.0 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/cockroachdb/errors v1.11.3 // indirect
+	github.com/cockroachdb/fifo v0.0.0-20240606204812-0bbfbd93a7ce // indirect
+	github.com/cockroachdb/logtags v0.0.0-20230118201751-21c54148d20b // indirect
+	github.com/cockroachdb/redact v1.1.5 // indirect
+	github.com/cockroachdb/tokenbucket v0.0.0-20230807174530-cc333fc44b06 // indirect
+	github.com/cosmos/go-bip39 v0.0.0-20180819234021-555e2067c45d // indirect
+	github.com/deckarep/golang-set v1.7.1 // indirect
+	github.com/dgraph-io/ristretto v0.0.3-0.20200630154024-f66de99634de // indirect
+	github.com/dgryski/go-farm v0.0.0-20190423205320-6a90982ecee2 // indirect
+	github.com/dustin/go-humanize v1.0.0 // indirect
+	github.com/edsrzf/mmap-go v1.0.0 // indirect
+	github.com/emirpasic/gods v1.12.0 // indirect
+	github.com/etcd-io/bbolt v1.3.3 // indirect
+	github.com/fjl/memsize v0.0.0-20180418122429-ca190fb6ffbc // indirect
+	github.com/fsnotify/fsnotify v1.4.7 // indirect
+	github.com/gballet/go-libpcsclite v0.0.0-20190607065134-2772fd86a8ff // indirect
+	github.com/getsentry/sentry-go v0.27.0 // indirect
+	github.com/go-kit/kit v0.10.0 // indirect
+	github.com/go-logfmt/logfmt v0.5.0 // indirect
+	github.com/go-ole/go-ole v1.2.4 // indirect
+	github.com/go-stack/stack v1.8.0 // indirect
+	github.com/gogo/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
+	github.com/google/btree v1.0.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/gorilla/websocket v1.4.1 // indirect
+	github.com/gtank/merlin v0.1.1-0.20191105220539-8318aed1a79f // indirect
+	github.com/gtank/ristretto255 v0.1.2 // indirect
+	github.com/hashicorp/golang-lru v0.5.4 // indirect
+	github.com/hashicorp/hcl v1.0.0 // indirect
+	github.com/inconshreveable/mousetrap v1.0.0 // indirect
+	github.com/jmhodges/levigo v1.0.0 // indirect
+	github.com/karalabe/usb v0.0.0-20190919080040-51dc0efba356 // indirect
+	github.com/klauspost/compress v1.16.0 // indirect
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/libp2p/go-buffer-pool v0.0.2 // indirect
+	github.com/magiconair/properties v1.8.1 // indirect
+	github.com/mattn/go-isatty v0.0.17 // indirect
+	github.com/mattn/go-runewidth v0.0.4 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.2-0.20181231171920-c182affec369 // indirect
+	github.com/mgutz/ansi v0.0.0-20170206155736-9520e82c474b // indirect
+	github.com/mimoo/StrobeGo v0.0.0-20181016162300-f8f6d4d2b643 // indirect
+	github.com/mitchellh/mapstructure v1.1.2 // indirect
+	github.com/olekukonko/tablewriter v0.0.2-0.20190409134802-7e037d187b0c // indirect
+	github.com/orcaman/concurrent-map v0.0.0-20190107190726-7ed82d9cb717 // indirect
+	github.com/pborman/uuid v1.2.0 // indirect
+	github.com/pelletier/go-toml v1.6.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/prometheus/client_golang v1.12.0 // indirect
+	github.com/prometheus/client_model v0.2.1-0.20210607210712-147c58e9608a // indirect
+	github.com/prometheus/common v0.32.1 // indirect
+	github.com/prometheus/procfs v0.7.3 // indirect
+	github.com/prometheus/tsdb v0.7.1 // indirect
+	github.com/rjeczalik/notify v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.9.0 // indirect
+	github.com/rs/cors v1.7.0 // indirect
+	github.com/shengdoushi/base58 v1.0.0 // indirect
+	github.com/shirou/gopsutil v2.20.5-0.20200531151128-663af789c085+incompatible // indirect
+	github.com/spf13/afero v1.2.2 // indirect
+	github.com/spf13/cast v1.3.0 // indirect
+	github.com/spf13/cobra v1.0.0 // indirect
+	github.com/spf13/jwalterweatherman v1.1.0 // indirect
+	github.com/spf13/pflag v1.0.5 // indirect
+	github.com/spf13/viper v1.6.2 // indirect
+	github.com/status-im/keycard-go v0.0.0-20190316090335-8537d3370df4 // indirect
+	github.com/steakknife/bloomfilter v0.0.0-20180922174646-6819c0d2a570 // indirect
+	github.com/steakknife/hamming v0.0.0-20180906055917-c99c65617cd3 // indirect
+	github.com/subosito/gotenv v1.2.0 // indirect
+	github.com/tecbot/gorocksdb v0.0.0-20191217155057-f0fad39f321c // indirect
+	github.com/tendermint/go-amino v0.15.1 // indirect
+	github.com/tendermint/tendermint v0.33.3 // indirect
+	github.com/tendermint/tm-db v0.5.0 // indirect
+	github.com/tyler-smith/go-bip39 v1.0.1-0.20181017060643-dbb3b84ba2ef // indirect
+	github.com/wsddn/go-ecdh v0.0.0-20161211032359-48726bab9208 // indirect
+	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c // indirect
+	github.com/xdg/stringprep v1.0.0 // indirect
+	golang.org/x/exp v0.0.0-20230626212559-97b1e661b5df // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/term v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
+	google.golang.org/grpc v1.56.3 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
+	gopkg.in/natefinch/npipe.v2 v2.0.0-20160621034901-c1b8fa8bdcce // indirect
+	gopkg.in/sourcemap.v1 v1.0.5 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,122 @@
+package p2p
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+// ErrNotPermissioned is returned when the allowlist is used on a server
+// that is not running in permissioned mode.
+var ErrNotPermissioned = errors.New("p2p server is not in permissioned mode")
+
+// NodeAllowlist is the set of nodes that may connect in permissioned mode.
+// It is merged from named sources, e.g. a file and an on-chain registry,
+// and every source can be replaced while the server is running.
+type NodeAllowlist struct {
+	lock    sync.RWMutex
+	sources map[string]map[enode.ID]struct{}
+}
+
+// NewNodeAllowlist creates an empty allowlist.
+func NewNodeAllowlist() *NodeAllowlist {
+	return &NodeAllowlist{sources: make(map[string]map[enode.ID]struct{})}
+}
+
+// SetSource replaces the nodes contributed by source. An empty list removes
+// the source. It reports whether the set of the source changed.
+func (al *NodeAllowlist) SetSource(source string, ids []enode.ID) bool {
+	set := make(map[enode.ID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+
+	al.lock.Lock()
+	defer al.lock.Unlock()
+	old := al.sources[source]
+	changed := len(old) != len(set)
+	if !changed {
+		for id := range set {
+			if _, ok := old[id]; !ok {
+				changed = true
+				break
+			}
+		}
+	}
+	if len(set) == 0 {
+		delete(al.sources, source)
+	} else {
+		al.sources[source] = set
+	}
+	return changed
+}
+
+// Contains reports whether any source allows id.
+func (al *NodeAllowlist) Contains(id enode.ID) bool {
+	al.lock.RLock()
+	defer al.lock.RUnlock()
+	for _, set := range al.sources {
+		if _, ok := set[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Sources returns the sorted node ids of every source.
+func (al *NodeAllowlist) Sources() map[string][]enode.ID {
+	al.lock.RLock()
+	defer al.lock.RUnlock()
+	sources := make(map[string][]enode.ID, len(al.sources))
+	for source, set := range al.sources {
+		ids := make([]enode.ID, 0, len(set))
+		for id := range set {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+		sources[source] = ids
+	}
+	return sources
+}
+
+// Allowlist returns the allowlist of a permissioned server, nil otherwise.
+func (srv *Server) Allowlist() *NodeAllowlist {
+	if !srv.Permissioned {
+		return nil
+	}
+	return srv.NodeAllowlist
+}
+
+// SetAllowedNodes replaces the nodes allowed by source and disconnects the
+// peers that are no longer allowed by any source.
+func (srv *Server) SetAllowedNodes(source string, ids []enode.ID) error {
+	allowlist := srv.Allowlist()
+	if allowlist == nil {
+		return ErrNotPermissioned
+	}
+	if !allowlist.SetSource(source, ids) {
+		return nil
+	}
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return nil
+	}
+
+	select {
+	case srv.peerOp <- func(peers map[enode.ID]*Peer) {
+		for id, p := range peers {
+			if !allowlist.Contains(id) {
+				p.log.WithField("source", source).Debug("Dropping peer removed from allowlist")
+				go p.Disconnect(DiscNotAllowed)
+			}
+		}
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package p2p
+
+import (
+	"crypto/rand"
+	mrand "math/rand"
+	"net"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+func TestNodeAllowlistSources(t *testing.T) {
+	a, b, c := randomID(), randomID(), randomID()
+	al := NewNodeAllowlist()
+
+	if !al.SetSource("file", []enode.ID{a, b}) {
+		t.Fatal("new source not reported as changed")
+	}
+	if al.SetSource("file", []enode.ID{b, a}) {
+		t.Error("same set reported as changed")
+	}
+	al.SetSource("chain", []enode.ID{b, c})
+	for _, id := range []enode.ID{a, b, c} {
+		if !al.Contains(id) {
+			t.Errorf("%v not allowed", id)
+		}
+	}
+
+	// b stays allowed through the chain source
+	al.SetSource("file", nil)
+	if al.Contains(a) {
+		t.Error("node of removed source still allowed")
+	}
+	if !al.Contains(b) {
+		t.Error("node of remaining source not allowed")
+	}
+	if sources := al.Sources(); len(sources) != 1 || len(sources["chain"]) != 2 {
+		t.Errorf("unexpected sources %v", sources)
+	}
+}
+
+func TestAllowlistHandshakeChecks(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	node := enode.NewV4(key.PubKey(), net.IP{10, 0, 0, 1}, 30303, 30303)
+	srv := &Server{Config: Config{Permissioned: true, NodeAllowlist: NewNodeAllowlist(), MaxPeers: 10}}
+
+	for _, flags := range []connFlag{inboundConn, dynDialedConn, staticDialedConn | trustedConn} {
+		c := &conn{peerNode: node, flags: flags}
+		if err := srv.encHandshakeChecks(map[enode.ID]*Peer{}, 0, c); err != DiscNotAllowed {
+			t.Errorf("%v: got %v, want %v", flags, err, DiscNotAllowed)
+		}
+	}
+
+	if err := srv.SetAllowedNodes("file", []enode.ID{node.ID()}); err != nil {
+		t.Fatal(err)
+	}
+	// the node passes the allowlist and hits the next check
+	fd, _ := net.Pipe()
+	defer fd.Close()
+	peers := map[enode.ID]*Peer{node.ID(): {rw: &conn{fd: fd, peerNode: node}}}
+	if err := srv.encHandshakeChecks(peers, 0, &conn{peerNode: node, flags: inboundConn}); err != DiscAlreadyConnected {
+		t.Errorf("allowed node: got %v, want %v", err, DiscAlreadyConnected)
+	}
+
+	if err := (&Server{}).SetAllowedNodes("file", nil); err != ErrNotPermissioned {
+		t.Errorf("got %v, want %v", err, ErrNotPermissioned)
+	}
+}
+
+func TestDialStateSkipsNotAllowed(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	node := enode.NewV4(key.PubKey(), net.IP{10, 0, 0, 1}, 30303, 30303)
+	s := newDialState(enode.ID{}, nil, nil, nil, 10, nil)
+	s.allowlist = NewNodeAllowlist()
+
+	if err := s.checkDial(node, map[enode.ID]*Peer{}); err != errNotAllowed {
+		t.Errorf("got %v, want %v", err, errNotAllowed)
+	}
+	s.allowlist.SetSource("file", []enode.ID{node.ID()})
+	if err := s.checkDial(node, map[enode.ID]*Peer{}); err != nil {
+		t.Errorf("allowed node: got %v", err)
+	}
+}
+
+func randomID() (id enode.ID) {
+	for i := range id {
+		id[i] = byte(mrand.Intn(255))
+	}
+	return id
+}
@@ -0,0 +1,79 @@
+package p2p
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+)
+
+// headRangeStep is the number of blocks the head has to advance before the
+// head height in the node record is updated. Every update bumps the record
+// sequence number, so it is kept coarse.
+const headRangeStep = 1024
+
+// chainEntry returns the chain entry of the local node record.
+// srv.lock must be held.
+func (srv *Server) chainEntry() enr.Chain {
+	role := srv.NodeRole
+	if role == "" {
+		role = enr.RoleFull
+	}
+	return enr.Chain{
+		ChainId:     srv.ChainId,
+		GenesisHash: srv.GenesisHash,
+		Lowest:      srv.headLowest,
+		Highest:     srv.headHighest,
+		Role:        role,
+	}
+}
+
+// ChainEntry returns the chain entry advertised in the local node record.
+func (srv *Server) ChainEntry() enr.Chain {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	return srv.chainEntry()
+}
+
+// updateChainEntry publishes the chain and fork entries if the local node is
+// set up.
+// srv.lock must be held.
+func (srv *Server) updateChainEntry() {
+	if srv.localnode != nil {
+		srv.localnode.Set(srv.chainEntry())
+		srv.setForkEntry()
+	}
+}
+
+// SetGenesis sets the genesis hash advertised in the node record. Discovery
+// drops nodes advertising another chain id or genesis.
+func (srv *Server) SetGenesis(hash crypto.Hash) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	srv.GenesisHash = hash
+	srv.updateChainEntry()
+}
+
+// SetNodeRole sets the role advertised in the node record, one of
+// enr.RoleProducer, enr.RoleFull and enr.RoleLight.
+func (srv *Server) SetNodeRole(role string) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	if srv.NodeRole == role {
+		return
+	}
+	srv.NodeRole = role
+	srv.updateChainEntry()
+}
+
+// SetChainHead sets the range of block heights the node can serve. The
+// record follows the head in steps of headRangeStep blocks.
+func (srv *Server) SetChainHead(lowest, highest uint64) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	srv.head = highest
+	srv.setForkEntry()
+	if lowest == srv.headLowest && highest >= srv.headHighest && highest < srv.headHighest+headRangeStep {
+		return
+	}
+	srv.headLowest, srv.headHighest = lowest, highest
+	srv.updateChainEntry()
+}
@@ -0,0 +1,48 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+)
+
+func TestServerChainEntry(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+	srv := &Server{Config: Config{ChainId: 7}}
+	srv.localnode = enode.NewLocalNode(db, key)
+
+	load := func() enr.Chain {
+		var chain enr.Chain
+		if err := srv.localnode.Node().Load(&chain); err != nil {
+			t.Fatal(err)
+		}
+		return chain
+	}
+
+	srv.SetGenesis(crypto.Hash{1})
+	if chain := load(); chain.ChainId != 7 || chain.GenesisHash != [32]byte{1} || chain.Role != enr.RoleFull {
+		t.Fatalf("unexpected entry %+v", chain)
+	}
+
+	srv.SetNodeRole(enr.RoleProducer)
+	if chain := load(); chain.Role != enr.RoleProducer {
+		t.Errorf("role %q, want %q", chain.Role, enr.RoleProducer)
+	}
+
+	// the head is only published in steps
+	srv.SetChainHead(0, headRangeStep)
+	seq := srv.localnode.Node().Seq()
+	srv.SetChainHead(0, headRangeStep+10)
+	if srv.localnode.Node().Seq() != seq || load().Highest != headRangeStep {
+		t.Error("record updated within head range step")
+	}
+	srv.SetChainHead(0, 2*headRangeStep)
+	if load().Highest != 2*headRangeStep {
+		t.Errorf("highest %d, want %d", load().Highest, 2*headRangeStep)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/drep-project/DREP-Chain/common/mclock"
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
 	"github.com/drep-project/DREP-Chain/network/p2p/netutil"
 )
@@ -69,9 +70,16 @@ func (t TCPDialer) Dial(dest *enode.Node) (net.Conn, error) {
 type dialstate struct {
 	maxDynDials int
 	ntab        discoverTable
+	dns         nodeSource // optional, consulted when the table is sparse
+	pex         nodeSource // optional, nodes learned from peer exchange
 	netrestrict *netutil.Netlist
+	allowlist   *NodeAllowlist // set in permissioned mode
 	self        enode.ID
 
+	// optional, rejects dynamic candidates whose record shows another
+	// network or incompatible forks
+	checkNetwork func(*enode.Node) error
+
 	lookupRunning bool
 	dialing       map[enode.ID]connFlag
 	lookupBuf     []*enode.Node // current discovery lookup results
@@ -79,10 +87,22 @@ type dialstate struct {
 	static        map[enode.ID]*dialTask
 	hist          *dialHistory
 
+	stats    *dialStats                 // dial outcomes and churn, adapts the dial ratio and lookup cadence
+	sources  map[enode.ID]dialSource    // source of running dynamic dials
+	finished map[enode.ID]*finishedDial // dials whose outcome is checked against the next peer set
+
 	start     time.Time     // time when the dialer was first used
 	bootnodes []*enode.Node // default dials when there are no peers
 }
 
+// finishedDial is a completed dial waiting for its outcome. A dial task
+// completes after the peer was added or discarded, so the outcome is known
+// from the peer set passed to the next newTasks call.
+type finishedDial struct {
+	node *enode.Node
+	src  dialSource
+}
+
 type discoverTable interface {
 	Close()
 	Resolve(*enode.Node) *enode.Node
@@ -90,6 +110,12 @@ type discoverTable interface {
 	ReadRandomNodes([]*enode.Node) int
 }
 
+// nodeSource supplies dial candidates found outside the discovery table,
+// such as nodes listed in DNS trees.
+type nodeSource interface {
+	ReadRandomNodes([]*enode.Node) int
+}
+
 // the dial history remembers recent dials.
 type dialHistory []pastDial
 
@@ -136,6 +162,9 @@ func newDialState(self enode.ID, static []*enode.Node, bootnodes []*enode.Node,
 		bootnodes:   make([]*enode.Node, len(bootnodes)),
 		randomNodes: make([]*enode.Node, maxdyn/2),
 		hist:        new(dialHistory),
+		stats:       newDialStats(maxdyn),
+		sources:     make(map[enode.ID]dialSource),
+		finished:    make(map[enode.ID]*finishedDial),
 	}
 	copy(s.bootnodes, bootnodes)
 	for _, n := range static {
@@ -163,9 +192,22 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 		s.start = now
 	}
 
+	// Learn from the dials finished since the last call.
+	for id, d := range s.finished {
+		s.stats.dialed(d.node, d.src, peers[id] != nil, now)
+		delete(s.finished, id)
+	}
+
 	var newtasks []task
-	addDial := func(flag connFlag, n *enode.Node) bool {
-		if err := s.checkDial(n, peers); err != nil {
+	addDial := func(flag connFlag, n *enode.Node, src dialSource) bool {
+		err := s.checkDial(n, peers)
+		if err == nil && s.checkNetwork != nil {
+			err = s.checkNetwork(n)
+		}
+		if err == nil && s.stats.backedOff(n, now) {
+			err = errBackedOff
+		}
+		if err != nil {
 			log.WithField("id", n.ID()).
 				WithField("addr", &net.TCPAddr{IP: n.IP(), Port: n.TCP()}).
 				WithField("err", err).
@@ -173,6 +215,9 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 			return false
 		}
 		s.dialing[n.ID()] = flag
+		if src != 0 {
+			s.sources[n.ID()] = src
+		}
 		newtasks = append(newtasks, &dialTask{flags: flag, dest: n})
 		return true
 	}
@@ -184,6 +229,8 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 			needDynDials--
 		}
 	}
+	s.stats.setPeers(s.maxDynDials - needDynDials)
+	defer s.stats.update()
 	for _, flag := range s.dialing {
 		if flag&dynDialedConn != 0 {
 			needDynDials--
@@ -216,17 +263,44 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 		s.bootnodes = append(s.bootnodes[:0], s.bootnodes[1:]...)
 		s.bootnodes = append(s.bootnodes, bootnode)
 
-		if addDial(dynDialedConn, bootnode) {
+		if addDial(dynDialedConn, bootnode, 0) {
 			needDynDials--
 		}
 	}
-	// Use random nodes from the table for half of the necessary
-	// dynamic dials.
-	randomCandidates := needDynDials / 2
-	if randomCandidates > 0 {
+	// Use random nodes from the table for part of the necessary dynamic
+	// dials. The share starts at half and follows how well table nodes
+	// connect compared to fresh lookup results.
+	randomCandidates := int(float64(needDynDials) * s.stats.randomRatio())
+	if randomCandidates > 0 && s.ntab != nil {
 		n := s.ntab.ReadRandomNodes(s.randomNodes)
+		dialed := 0
 		for i := 0; i < randomCandidates && i < n; i++ {
-			if addDial(dynDialedConn, s.randomNodes[i]) {
+			if addDial(dynDialedConn, s.randomNodes[i], dialFromTable) {
+				needDynDials--
+				dialed++
+			}
+		}
+		randomCandidates -= dialed
+	}
+	// Top up from DNS node lists when the table could not provide enough
+	// candidates, e.g. right after start or behind a restrictive NAT.
+	if randomCandidates > 0 && s.dns != nil {
+		n := s.dns.ReadRandomNodes(s.randomNodes)
+		dialed := 0
+		for i := 0; i < randomCandidates && i < n; i++ {
+			if addDial(dynDialedConn, s.randomNodes[i], 0) {
+				needDynDials--
+				dialed++
+			}
+		}
+		randomCandidates -= dialed
+	}
+	// Then nodes shared by peers, the only source left when discovery UDP
+	// is blocked.
+	if randomCandidates > 0 && s.pex != nil {
+		n := s.pex.ReadRandomNodes(s.randomNodes)
+		for i := 0; i < randomCandidates && i < n; i++ {
+			if addDial(dynDialedConn, s.randomNodes[i], 0) {
 				needDynDials--
 			}
 		}
@@ -235,13 +309,13 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 	// items from the result buffer.
 	i := 0
 	for ; i < len(s.lookupBuf) && needDynDials > 0; i++ {
-		if addDial(dynDialedConn, s.lookupBuf[i]) {
+		if addDial(dynDialedConn, s.lookupBuf[i], dialFromLookup) {
 			needDynDials--
 		}
 	}
 	s.lookupBuf = s.lookupBuf[:copy(s.lookupBuf, s.lookupBuf[i:])]
 	// Launch a discovery lookup if more candidates are needed.
-	if len(s.lookupBuf) < needDynDials && !s.lookupRunning {
+	if len(s.lookupBuf) < needDynDials && !s.lookupRunning && s.ntab != nil {
 		s.lookupRunning = true
 		newtasks = append(newtasks, &discoverTask{})
 	}
@@ -263,6 +337,8 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
+	errBackedOff        = errors.New("subnet backed off after failed dials")
+	errNotAllowed       = errors.New("not contained in node allowlist")
 )
 
 func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
@@ -277,6 +353,8 @@ func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
 		return errSelf
 	case s.netrestrict != nil && !s.netrestrict.Contains(n.IP()):
 		return errNotWhitelisted
+	case s.allowlist != nil && !s.allowlist.Contains(n.ID()):
+		return errNotAllowed
 	case s.hist.contains(n.ID()):
 		return errRecentlyDialed
 	}
@@ -286,14 +364,26 @@ func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
 func (s *dialstate) taskDone(t task, now time.Time) {
 	switch t := t.(type) {
 	case *dialTask:
-		s.hist.add(t.dest.ID(), now.Add(dialHistoryExpiration))
-		delete(s.dialing, t.dest.ID())
+		id := t.dest.ID()
+		s.hist.add(id, now.Add(dialHistoryExpiration))
+		delete(s.dialing, id)
+		if t.flags&dynDialedConn != 0 {
+			s.finished[id] = &finishedDial{node: t.dest, src: s.sources[id]}
+			delete(s.sources, id)
+		}
 	case *discoverTask:
 		s.lookupRunning = false
 		s.lookupBuf = append(s.lookupBuf, t.results...)
 	}
 }
 
+// peerRemoved records the lifetime of dropped dynamic peers as churn.
+func (s *dialstate) peerRemoved(p *Peer) {
+	if p.rw.is(dynDialedConn) {
+		s.stats.peerDropped(time.Duration(mclock.Now() - p.created))
+	}
+}
+
 func (t *dialTask) Do(srv *Server) {
 	if t.dest.Incomplete() {
 		if !t.resolve(srv) {
@@ -307,9 +397,8 @@ func (t *dialTask) Do(srv *Server) {
 		// Try resolving the ID of static nodes if dialing failed.
 		if _, ok := err.(*dialError); ok && t.flags&staticDialedConn != 0 {
 			if t.resolve(srv) {
-				err := t.dial(srv, t.dest)
-				if err != nil {
-					fmt.Println("dial::::", err)
+				if err := t.dial(srv, t.dest); err != nil {
+					log.WithField("task", t).WithField("err", err).Debug("Dial error after resolve")
 				}
 			}
 		}
@@ -373,7 +462,11 @@ func (t *discoverTask) Do(srv *Server) {
 	// newTasks generates a lookup task whenever dynamic dials are
 	// necessary. Lookups need to take some time, otherwise the
 	// event loop spins too fast.
-	next := srv.lastLookup.Add(lookupInterval)
+	interval := lookupInterval
+	if srv.dialStats != nil {
+		interval = srv.dialStats.lookupInterval()
+	}
+	next := srv.lastLookup.Add(interval)
 	if now := time.Now(); now.Before(next) {
 		time.Sleep(next.Sub(now))
 	}
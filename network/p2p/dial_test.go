@@ -94,18 +94,18 @@ func TestDialStateDynDial(t *testing.T) {
 			// A discovery query is launched.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				new: []task{&discoverTask{}},
 			},
 			// Dynamic dials are launched when it completes.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				done: []task{
 					&discoverTask{results: []*enode.Node{
@@ -127,11 +127,11 @@ func TestDialStateDynDial(t *testing.T) {
 			// the sum of active dial count and dynamic peer count is == maxDynDials.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(4), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(3), nil)},
@@ -142,12 +142,12 @@ func TestDialStateDynDial(t *testing.T) {
 			// maxDynDials has been reached.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(4), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(5), nil)},
@@ -160,11 +160,11 @@ func TestDialStateDynDial(t *testing.T) {
 			// results from last discovery lookup are reused.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(4), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 				new: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(6), nil)},
@@ -175,9 +175,9 @@ func TestDialStateDynDial(t *testing.T) {
 			// and a new one is spawned because more candidates are needed.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(6), nil)},
@@ -192,10 +192,10 @@ func TestDialStateDynDial(t *testing.T) {
 			// no new is started.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(5), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(7), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(7), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(7), nil)},
@@ -205,10 +205,10 @@ func TestDialStateDynDial(t *testing.T) {
 			// should be immediately requested.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(0), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(5), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(7), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(0), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(7), nil)}},
 				},
 				done: []task{
 					&discoverTask{},
@@ -255,12 +255,13 @@ func TestDialStateDynDialBootnode(t *testing.T) {
 			},
 			// No dials succeed, bootnodes still pending fallback interval
 			{},
-			// No dials succeed, 2 dynamic dials attempted and 1 bootnode too as fallback interval was reached
+			// No dials succeed, 1 bootnode is attempted as fallback interval was reached. The
+			// random table nodes failed, so their share of the dynamic dials drops below half
+			// and only 1 of them is redialed
 			{
 				new: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(1), nil)},
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(4), nil)},
-					&dialTask{flags: dynDialedConn, dest: newNode(uintID(5), nil)},
 				},
 			},
 			// No dials succeed, 2nd bootnode is attempted
@@ -268,7 +269,6 @@ func TestDialStateDynDialBootnode(t *testing.T) {
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(1), nil)},
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(4), nil)},
-					&dialTask{flags: dynDialedConn, dest: newNode(uintID(5), nil)},
 				},
 				new: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(2), nil)},
@@ -283,7 +283,7 @@ func TestDialStateDynDialBootnode(t *testing.T) {
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(3), nil)},
 				},
 			},
-			// No dials succeed, 1st bootnode is attempted again, expired random nodes retried
+			// No dials succeed, 1st bootnode is attempted again, expired random node retried
 			{
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(3), nil)},
@@ -291,18 +291,16 @@ func TestDialStateDynDialBootnode(t *testing.T) {
 				new: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(1), nil)},
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(4), nil)},
-					&dialTask{flags: dynDialedConn, dest: newNode(uintID(5), nil)},
 				},
 			},
 			// Random dial succeeds, no more bootnodes are attempted
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(4), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(1), nil)},
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(4), nil)},
-					&dialTask{flags: dynDialedConn, dest: newNode(uintID(5), nil)},
 				},
 			},
 		},
@@ -340,8 +338,8 @@ func TestDialStateDynDialFromTable(t *testing.T) {
 			// Dialing nodes 1,2 succeeds. Dials from the lookup are launched.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(1), nil)},
@@ -362,11 +360,11 @@ func TestDialStateDynDialFromTable(t *testing.T) {
 			// Dialing nodes 3,4,5 fails. The dials from the lookup succeed.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(10), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(11), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(12), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(10), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(11), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(12), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: dynDialedConn, dest: newNode(uintID(3), nil)},
@@ -381,11 +379,11 @@ func TestDialStateDynDialFromTable(t *testing.T) {
 			// discovery query is still running.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(10), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(11), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(12), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(10), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(11), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(12), nil)}},
 				},
 			},
 			// Nodes 3,4 are not tried again because only the first two
@@ -393,11 +391,11 @@ func TestDialStateDynDialFromTable(t *testing.T) {
 			// already connected.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(10), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(11), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(12), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(10), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(11), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(12), nil)}},
 				},
 			},
 		},
@@ -459,8 +457,8 @@ func TestDialStateStaticDial(t *testing.T) {
 			// aren't yet connected.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				new: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(3), nil)},
@@ -472,9 +470,9 @@ func TestDialStateStaticDial(t *testing.T) {
 			// nodes are either connected or still being dialed.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(3), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(3), nil)},
@@ -484,11 +482,11 @@ func TestDialStateStaticDial(t *testing.T) {
 			// nodes are now connected.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(4), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(4), nil)},
@@ -501,20 +499,20 @@ func TestDialStateStaticDial(t *testing.T) {
 			// Wait a round for dial history to expire, no new tasks should spawn.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(4), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(4), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 			},
 			// If a static node is dropped, it should be immediately redialed,
 			// irrespective whether it was originally static or dynamic.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(3), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(5), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(3), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(5), nil)}},
 				},
 				new: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(2), nil)},
@@ -544,8 +542,8 @@ func TestDialStaticAfterReset(t *testing.T) {
 		// No new dial tasks, all peers are connected.
 		{
 			peers: []*Peer{
-				{rw: &conn{flags: staticDialedConn, node: newNode(uintID(1), nil)}},
-				{rw: &conn{flags: staticDialedConn, node: newNode(uintID(2), nil)}},
+				{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(1), nil)}},
+				{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(2), nil)}},
 			},
 			done: []task{
 				&dialTask{flags: staticDialedConn, dest: newNode(uintID(1), nil)},
@@ -594,8 +592,8 @@ func TestDialStateCache(t *testing.T) {
 			// nodes are either connected or still being dialed.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: staticDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: staticDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(1), nil)},
@@ -606,8 +604,8 @@ func TestDialStateCache(t *testing.T) {
 			// entry to expire.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				done: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(3), nil)},
@@ -619,15 +617,15 @@ func TestDialStateCache(t *testing.T) {
 			// Still waiting for node 3's entry to expire in the cache.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 			},
 			// The cache entry for node 3 has expired and is retried.
 			{
 				peers: []*Peer{
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(1), nil)}},
-					{rw: &conn{flags: dynDialedConn, node: newNode(uintID(2), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(1), nil)}},
+					{rw: &conn{flags: dynDialedConn, peerNode: newNode(uintID(2), nil)}},
 				},
 				new: []task{
 					&dialTask{flags: staticDialedConn, dest: newNode(uintID(3), nil)},
@@ -0,0 +1,211 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	// weight of the latest sample in the moving averages
+	dialStatsAlpha = 0.1
+
+	// a peer dropped within this time after connecting counts as churn
+	shortLivedPeer = 2 * time.Minute
+
+	// subnets failing this many dials in a row are not dialed for a while,
+	// the pause doubles with every further failure
+	subnetFailThreshold = 3
+	subnetMinBackoff    = time.Minute
+	subnetMaxBackoff    = 30 * time.Minute
+	maxTrackedSubnets   = 1024
+
+	// bounds of the share of dynamic dials taken from random table nodes
+	minRandomRatio = 0.25
+	maxRandomRatio = 0.75
+
+	// lookups run this often while the peer count is below half of the target
+	boostedLookupInterval = lookupInterval / 4
+)
+
+// dialSource is where a dial candidate came from.
+type dialSource int
+
+const (
+	dialFromTable dialSource = iota + 1
+	dialFromLookup
+)
+
+// dialStats tracks dial outcomes and peer churn so the dialer can adapt to
+// poor networks. Outcomes are updated by the dialer in Server.run, the lookup
+// interval is read by discovery tasks, hence the lock.
+type dialStats struct {
+	mu sync.Mutex
+
+	tableSuccess  float64 // moving average of successful dials to random table nodes
+	lookupSuccess float64 // moving average of successful dials to lookup results
+	churn         float64 // moving average of peers dropping shortly after connecting
+
+	peers  int // current dynamic peers
+	target int // wanted dynamic peers
+
+	subnets map[string]*subnetStats
+}
+
+type subnetStats struct {
+	fails int       // consecutive failed dials
+	until time.Time // subnet is not dialed before this time
+}
+
+func newDialStats(target int) *dialStats {
+	return &dialStats{
+		tableSuccess:  1,
+		lookupSuccess: 1,
+		target:        target,
+		subnets:       make(map[string]*subnetStats),
+	}
+}
+
+// subnetKey groups addresses by /24 for IPv4 and /48 for IPv6.
+func subnetKey(ip net.IP) string {
+	if ip == nil || ip.IsUnspecified() {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+func ewma(avg float64, ok bool) float64 {
+	sample := 0.0
+	if ok {
+		sample = 1
+	}
+	return avg*(1-dialStatsAlpha) + sample*dialStatsAlpha
+}
+
+// dialed records the outcome of a dial.
+func (st *dialStats) dialed(n *enode.Node, src dialSource, ok bool, now time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch src {
+	case dialFromTable:
+		st.tableSuccess = ewma(st.tableSuccess, ok)
+	case dialFromLookup:
+		st.lookupSuccess = ewma(st.lookupSuccess, ok)
+	}
+	key := subnetKey(n.IP())
+	if key == "" {
+		return
+	}
+	if ok {
+		delete(st.subnets, key)
+		return
+	}
+	sub := st.subnets[key]
+	if sub == nil {
+		st.expireSubnets(now)
+		if len(st.subnets) >= maxTrackedSubnets {
+			return
+		}
+		sub = new(subnetStats)
+		st.subnets[key] = sub
+	}
+	sub.fails++
+	if sub.fails >= subnetFailThreshold {
+		backoff := subnetMinBackoff
+		for i := subnetFailThreshold; i < sub.fails && backoff < subnetMaxBackoff; i++ {
+			backoff *= 2
+		}
+		if backoff > subnetMaxBackoff {
+			backoff = subnetMaxBackoff
+		}
+		sub.until = now.Add(backoff)
+	}
+}
+
+// expireSubnets forgets subnets whose back-off has passed and which are not
+// about to be backed off.
+func (st *dialStats) expireSubnets(now time.Time) {
+	for key, sub := range st.subnets {
+		if sub.fails >= subnetFailThreshold && now.After(sub.until) {
+			delete(st.subnets, key)
+		}
+	}
+}
+
+// backedOff reports whether the subnet of n is currently not dialed.
+func (st *dialStats) backedOff(n *enode.Node, now time.Time) bool {
+	key := subnetKey(n.IP())
+	if key == "" {
+		return false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sub := st.subnets[key]
+	return sub != nil && now.Before(sub.until)
+}
+
+// peerDropped records how long a dynamic peer stayed connected.
+func (st *dialStats) peerDropped(lifetime time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.churn = ewma(st.churn, lifetime < shortLivedPeer)
+}
+
+// setPeers updates the number of connected dynamic peers.
+func (st *dialStats) setPeers(n int) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.peers = n
+}
+
+// randomRatio returns the share of dynamic dials taken from random table
+// nodes. It follows the relative success of table nodes and lookup results,
+// starting at one half.
+func (st *dialStats) randomRatio() float64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	total := st.tableSuccess + st.lookupSuccess
+	if total == 0 {
+		return 0.5
+	}
+	ratio := st.tableSuccess / total
+	if ratio < minRandomRatio {
+		ratio = minRandomRatio
+	} else if ratio > maxRandomRatio {
+		ratio = maxRandomRatio
+	}
+	return ratio
+}
+
+// lookupInterval returns the minimum time between discovery lookups. Lookups
+// are boosted while the peer count is low or peers keep dropping.
+func (st *dialStats) lookupInterval() time.Duration {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.target > 0 && (st.peers < st.target/2 || st.churn > 0.5) {
+		return boostedLookupInterval
+	}
+	return lookupInterval
+}
+
+// update publishes the statistics to the metrics registry.
+func (st *dialStats) update() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	dialTableSuccessGauge.Update(st.tableSuccess)
+	dialLookupSuccessGauge.Update(st.lookupSuccess)
+	peerChurnGauge.Update(st.churn)
+	backedOff := 0
+	for _, sub := range st.subnets {
+		if sub.fails >= subnetFailThreshold {
+			backedOff++
+		}
+	}
+	dialBackoffGauge.Update(int64(backedOff))
+}
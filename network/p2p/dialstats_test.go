@@ -0,0 +1,87 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+// newSignedNode return a node of a fresh key, its record signed the way remote records are
+func newSignedNode(t *testing.T, ip net.IP) *enode.Node {
+	key, err := crypto.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enode.NewV4(key.PubKey(), ip, 30303, 30303)
+}
+
+func TestDialStatsSubnetBackoff(t *testing.T) {
+	st := newDialStats(10)
+	now := time.Now()
+	n1 := newSignedNode(t, net.IP{10, 0, 1, 1})
+	n2 := newSignedNode(t, net.IP{10, 0, 1, 2})
+	other := newSignedNode(t, net.IP{10, 0, 2, 1})
+
+	for i := 0; i < subnetFailThreshold-1; i++ {
+		st.dialed(n1, dialFromTable, false, now)
+	}
+	if st.backedOff(n2, now) {
+		t.Fatal("subnet backed off before reaching the failure threshold")
+	}
+	st.dialed(n1, dialFromTable, false, now)
+	if !st.backedOff(n2, now) {
+		t.Fatal("subnet not backed off after repeated failures")
+	}
+	if st.backedOff(other, now) {
+		t.Fatal("unrelated subnet backed off")
+	}
+	if !st.backedOff(n2, now.Add(subnetMinBackoff-time.Second)) || st.backedOff(n2, now.Add(subnetMinBackoff+time.Second)) {
+		t.Fatal("wrong back-off duration")
+	}
+
+	// another failure doubles the pause, a success clears it
+	st.dialed(n2, dialFromTable, false, now)
+	if !st.backedOff(n1, now.Add(2*subnetMinBackoff-time.Second)) {
+		t.Fatal("back-off not doubled")
+	}
+	st.dialed(n2, dialFromTable, true, now)
+	if st.backedOff(n1, now) {
+		t.Fatal("back-off not cleared by successful dial")
+	}
+}
+
+func TestDialStatsRatio(t *testing.T) {
+	st := newDialStats(10)
+	if r := st.randomRatio(); r != 0.5 {
+		t.Fatalf("initial ratio %v, want 0.5", r)
+	}
+	n := newSignedNode(t, nil)
+	for i := 0; i < 50; i++ {
+		st.dialed(n, dialFromTable, false, time.Now())
+		st.dialed(n, dialFromLookup, true, time.Now())
+	}
+	if r := st.randomRatio(); r != minRandomRatio {
+		t.Fatalf("ratio %v after failing table dials, want %v", r, minRandomRatio)
+	}
+}
+
+func TestDialStatsLookupInterval(t *testing.T) {
+	st := newDialStats(10)
+	if st.lookupInterval() != boostedLookupInterval {
+		t.Fatal("lookups not boosted without peers")
+	}
+	st.setPeers(8)
+	if st.lookupInterval() != lookupInterval {
+		t.Fatal("lookups boosted with enough peers")
+	}
+	for i := 0; i < 20; i++ {
+		st.peerDropped(time.Second)
+	}
+	if st.lookupInterval() != boostedLookupInterval {
+		t.Fatal("lookups not boosted under high churn")
+	}
+}
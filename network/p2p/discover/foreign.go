@@ -0,0 +1,147 @@
+package discover
+
+import (
+	"errors"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+	"github.com/drep-project/binary"
+)
+
+const (
+	foreignExpiration = 24 * time.Hour // how long a node of another network is kept out
+	maxForeignNodes   = 1000
+)
+
+var errForeignChain = errors.New("node belongs to another network")
+
+// chainRest returns the chain and fork entries of the local node record. They
+// are sent in the tail of ping and pong packets, so both sides learn about a
+// network mismatch before any TCP connection is made. Older nodes ignore the
+// tail, or the fork entry after the chain entry.
+func (t *udp) chainRest() []enr.RawValue {
+	var chain enr.Chain
+	if err := t.self().Load(&chain); err != nil {
+		return nil
+	}
+	blob, err := binary.Marshal(chain)
+	if err != nil {
+		return nil
+	}
+	rest := []enr.RawValue{blob}
+	var fork enr.Fork
+	if err := t.self().Load(&fork); err != nil {
+		return rest
+	}
+	if blob, err = binary.Marshal(fork); err != nil {
+		return rest
+	}
+	return append(rest, blob)
+}
+
+// checkChain compares the chain entry sent by a node with the local one, and
+// its fork entry with the fork filter, and keeps the node out of the table if
+// it belongs to another network or follows other forks. Nodes that don't send
+// these entries are accepted.
+func (t *udp) checkChain(fromID enode.ID, rest []enr.RawValue) error {
+	if len(rest) == 0 {
+		return nil
+	}
+	var local, remote enr.Chain
+	if err := t.self().Load(&local); err != nil {
+		return nil
+	}
+	if err := binary.Unmarshal(rest[0], &remote); err != nil {
+		return nil
+	}
+	if !local.SameNetwork(remote) {
+		log.WithField("id", fromID).WithField("chainId", remote.ChainId).Trace("Dropping node of other network")
+		t.tab.markForeign(fromID)
+		return errForeignChain
+	}
+	var fork enr.Fork
+	if len(rest) > 1 && t.forkFilter != nil && binary.Unmarshal(rest[1], &fork) == nil {
+		if err := t.forkFilter(fork); err != nil {
+			log.WithField("id", fromID).WithField("err", err).Trace("Dropping node of other fork")
+			t.tab.markForeign(fromID)
+			return errForeignChain
+		}
+	}
+	t.tab.clearForeign(fromID)
+	return nil
+}
+
+// markForeign removes the node from the table and keeps it out until the
+// mark expires.
+func (tab *Table) markForeign(id enode.ID) {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+
+	if len(tab.foreign) >= maxForeignNodes {
+		now := time.Now()
+		for fid, exp := range tab.foreign {
+			if now.After(exp) {
+				delete(tab.foreign, fid)
+			}
+		}
+		// still full, evict an arbitrary entry
+		for fid := range tab.foreign {
+			if len(tab.foreign) < maxForeignNodes {
+				break
+			}
+			delete(tab.foreign, fid)
+		}
+	}
+	tab.foreign[id] = time.Now().Add(foreignExpiration)
+
+	b := tab.bucket(id)
+	for _, n := range b.entries {
+		if n.ID() == id {
+			tab.deleteInBucket(b, n)
+			break
+		}
+	}
+	for _, n := range b.replacements {
+		if n.ID() == id {
+			b.replacements = deleteNode(b.replacements, n)
+			tab.removeIP(b, n.IP())
+			break
+		}
+	}
+}
+
+func (tab *Table) clearForeign(id enode.ID) {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+	delete(tab.foreign, id)
+}
+
+func (tab *Table) isForeign(id enode.ID) bool {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+	return tab.isForeignLocked(id)
+}
+
+// isForeignLocked must be called with tab.mutex held.
+func (tab *Table) isForeignLocked(id enode.ID) bool {
+	exp, ok := tab.foreign[id]
+	if ok && time.Now().After(exp) {
+		delete(tab.foreign, id)
+		return false
+	}
+	return ok
+}
+
+// dropForeign filters nodes of other networks out of lookup results.
+func (tab *Table) dropForeign(nodes []*node) []*node {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+	kept := nodes[:0]
+	for _, n := range nodes {
+		if !tab.isForeignLocked(n.ID()) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
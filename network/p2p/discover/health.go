@@ -0,0 +1,97 @@
+package discover
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+var errNoEndpoint = errors.New("node has no UDP endpoint")
+
+// tableStats counts the discovery traffic, the fields are accessed atomically.
+type tableStats struct {
+	pingsSent     uint64
+	pingsReceived uint64
+	pongsReceived uint64
+	pingTimeouts  uint64
+}
+
+// BucketHealth is the content of a non-empty bucket of the table.
+type BucketHealth struct {
+	Distance     int      `json:"distance"` // log distance of the nodes to the local node
+	Entries      []string `json:"entries"`  // enode urls, most recently active first
+	Replacements int      `json:"replacements"`
+}
+
+// Health is a snapshot of the table, used to find out why a node has no peers.
+type Health struct {
+	Nodes         int            `json:"nodes"`
+	Seeds         int            `json:"seeds"`
+	Foreign       int            `json:"foreign"`
+	Initialized   bool           `json:"initialized"`
+	LastRefresh   int64          `json:"lastRefresh"` // unix time of the last completed refresh, 0 before the first
+	PingsSent     uint64         `json:"pingsSent"`
+	PingsReceived uint64         `json:"pingsReceived"`
+	PongsReceived uint64         `json:"pongsReceived"`
+	PingTimeouts  uint64         `json:"pingTimeouts"`
+	Buckets       []BucketHealth `json:"buckets"`
+}
+
+// Health returns the bucket contents, the refresh time and the ping/pong
+// counters of the table.
+func (tab *Table) Health() *Health {
+	health := &Health{
+		Initialized:   tab.isInitDone(),
+		PingsSent:     atomic.LoadUint64(&tab.stats.pingsSent),
+		PingsReceived: atomic.LoadUint64(&tab.stats.pingsReceived),
+		PongsReceived: atomic.LoadUint64(&tab.stats.pongsReceived),
+		PingTimeouts:  atomic.LoadUint64(&tab.stats.pingTimeouts),
+		Buckets:       []BucketHealth{},
+	}
+
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+	health.Seeds = len(tab.nursery)
+	health.Foreign = len(tab.foreign)
+	if !tab.lastRefresh.IsZero() {
+		health.LastRefresh = tab.lastRefresh.Unix()
+	}
+	for i, b := range tab.buckets {
+		if len(b.entries) == 0 && len(b.replacements) == 0 {
+			continue
+		}
+		bucket := BucketHealth{
+			Distance:     bucketMinDistance + 1 + i,
+			Entries:      make([]string, 0, len(b.entries)),
+			Replacements: len(b.replacements),
+		}
+		for _, n := range b.entries {
+			bucket.Entries = append(bucket.Entries, n.String())
+		}
+		health.Nodes += len(b.entries)
+		health.Buckets = append(health.Buckets, bucket)
+	}
+	return health
+}
+
+// Bond pings the node and adds it to the table when it answers.
+func (tab *Table) Bond(n *enode.Node) error {
+	if n.IP() == nil || n.UDP() == 0 {
+		return errNoEndpoint
+	}
+	if err := tab.net.ping(n.ID(), &net.UDPAddr{IP: n.IP(), Port: n.UDP()}); err != nil {
+		return err
+	}
+	tab.addVerifiedNode(wrapNode(n))
+	return nil
+}
+
+// refreshed records the time of a completed refresh.
+func (tab *Table) refreshed() {
+	tab.mutex.Lock()
+	defer tab.mutex.Unlock()
+	tab.lastRefresh = time.Now()
+}
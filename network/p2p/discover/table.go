@@ -68,6 +68,10 @@ type Table struct {
 	nursery []*node           // bootstrap nodes
 	rand    *mrand.Rand       // source of randomness, periodically reseeded
 	ips     netutil.DistinctNetSet
+	foreign map[enode.ID]time.Time // nodes of other networks and when they may return
+
+	lastRefresh time.Time  // when the last refresh completed
+	stats       tableStats // discovery traffic counters
 
 	db         *enode.DB // database of known nodes
 	net        transport
@@ -109,6 +113,7 @@ func newTable(t transport, db *enode.DB, bootnodes []*enode.Node) (*Table, error
 		closed:     make(chan struct{}),
 		rand:       mrand.New(mrand.NewSource(0)),
 		ips:        netutil.DistinctNetSet{Subnet: tableSubnet, Limit: tableIPLimit},
+		foreign:    make(map[enode.ID]time.Time),
 	}
 	if err := tab.setFallbackNodes(bootnodes); err != nil {
 		return nil, err
@@ -301,7 +306,7 @@ func (tab *Table) lookup(targetKey encPubkey, refreshIfEmpty bool) []*node {
 		}
 		pendingQueries--
 	}
-	return result.entries
+	return tab.dropForeign(result.entries)
 }
 
 func (tab *Table) findnode(n *node, targetKey encPubkey, reply chan<- []*node) {
@@ -408,6 +413,7 @@ loop:
 // bootstrap or discarded faulty peers).
 func (tab *Table) doRefresh(done chan struct{}) {
 	defer close(done)
+	defer tab.refreshed()
 
 	// Load nodes from the database and insert
 	// them. This should yield a few previously seen nodes that are
@@ -571,6 +577,9 @@ func (tab *Table) addSeenNode(n *node) {
 
 	tab.mutex.Lock()
 	defer tab.mutex.Unlock()
+	if tab.isForeignLocked(n.ID()) {
+		return
+	}
 	b := tab.bucket(n.ID())
 	if contains(b.entries, n.ID()) {
 		// Already in bucket, don't add.
@@ -613,6 +622,9 @@ func (tab *Table) addVerifiedNode(n *node) {
 
 	tab.mutex.Lock()
 	defer tab.mutex.Unlock()
+	if tab.isForeignLocked(n.ID()) {
+		return
+	}
 	b := tab.bucket(n.ID())
 	if tab.bumpInBucket(b, n) {
 		// Already in bucket, moved to front.
@@ -688,3 +688,75 @@ func newkey() *secp256k1.PrivateKey {
 	}
 	return key
 }
+
+func TestTable_foreignNodes(t *testing.T) {
+	transport := newPingRecorder()
+	tab, db := newTestTable(transport)
+	defer db.Close()
+	defer tab.Close()
+	<-tab.initDone
+
+	n := nodeAtDistance(tab.self().ID(), 250, intIP(1))
+	tab.addSeenNode(n)
+	if !contains(tab.bucket(n.ID()).entries, n.ID()) {
+		t.Fatal("node not added")
+	}
+
+	tab.markForeign(n.ID())
+	if contains(tab.bucket(n.ID()).entries, n.ID()) {
+		t.Error("foreign node still in table")
+	}
+	tab.addSeenNode(n)
+	tab.addVerifiedNode(n)
+	if contains(tab.bucket(n.ID()).entries, n.ID()) {
+		t.Error("foreign node added again")
+	}
+	if res := tab.dropForeign([]*node{n}); len(res) != 0 {
+		t.Error("foreign node not dropped from lookup result")
+	}
+
+	tab.clearForeign(n.ID())
+	tab.addSeenNode(n)
+	if !contains(tab.bucket(n.ID()).entries, n.ID()) {
+		t.Error("node not added after clearing the mark")
+	}
+}
+
+func TestTable_HealthAndBond(t *testing.T) {
+	transport := newPingRecorder()
+	tab, db := newTestTable(transport)
+	defer db.Close()
+	defer tab.Close()
+	<-tab.initDone
+
+	alive := nodeAtDistance(tab.self().ID(), 250, intIP(1))
+	if err := tab.Bond(unwrapNode(alive)); err != errNoEndpoint {
+		t.Fatalf("bond without udp endpoint: got %v, want %v", err, errNoEndpoint)
+	}
+
+	var r enr.Record
+	r.Set(enr.IP(intIP(1)))
+	r.Set(enr.UDP(30303))
+	alive = wrapNode(enode.SignNull(&r, alive.ID()))
+	dead := wrapNode(enode.SignNull(&r, idAtDistance(tab.self().ID(), 251)))
+	transport.dead[dead.ID()] = true
+
+	if err := tab.Bond(unwrapNode(alive)); err != nil {
+		t.Fatalf("bond with live node: %v", err)
+	}
+	if err := tab.Bond(unwrapNode(dead)); err != errTimeout {
+		t.Fatalf("bond with dead node: got %v, want %v", err, errTimeout)
+	}
+
+	health := tab.Health()
+	if !health.Initialized || health.LastRefresh == 0 {
+		t.Errorf("table not reported refreshed: %+v", health)
+	}
+	if health.Nodes != 1 || len(health.Buckets) != 1 {
+		t.Fatalf("wrong table content: %+v", health)
+	}
+	bucket := health.Buckets[0]
+	if bucket.Distance != 250 || len(bucket.Entries) != 1 || bucket.Entries[0] != alive.String() {
+		t.Errorf("wrong bucket content: %+v", bucket)
+	}
+}
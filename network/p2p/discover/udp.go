@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
@@ -184,6 +185,7 @@ type udp struct {
 	localNode   *enode.LocalNode
 	db          *enode.DB
 	tab         *Table
+	forkFilter  func(enr.Fork) error
 	wg          sync.WaitGroup
 
 	addReplyMatcher chan *replyMatcher
@@ -245,9 +247,10 @@ type Config struct {
 	PrivateKey *secp256k1.PrivateKey
 
 	// These settings are optional:
-	NetRestrict *netutil.Netlist  // network whitelist
-	Bootnodes   []*enode.Node     // list of bootstrap nodes
-	Unhandled   chan<- ReadPacket // unhandled packets are sent on this channel
+	NetRestrict *netutil.Netlist     // network whitelist
+	Bootnodes   []*enode.Node        // list of bootstrap nodes
+	Unhandled   chan<- ReadPacket    // unhandled packets are sent on this channel
+	ForkFilter  func(enr.Fork) error // rejects nodes advertising incompatible forks
 }
 
 // ListenUDP returns a new table that listens for UDP packets on laddr.
@@ -264,6 +267,7 @@ func newUDP(c conn, ln *enode.LocalNode, cfg Config) (*Table, *udp, error) {
 		conn:            c,
 		priv:            cfg.PrivateKey,
 		netrestrict:     cfg.NetRestrict,
+		forkFilter:      cfg.ForkFilter,
 		localNode:       ln,
 		db:              ln.Database(),
 		closing:         make(chan struct{}),
@@ -300,7 +304,16 @@ func (t *udp) ourEndpoint() rpcEndpoint {
 
 // ping sends a ping message to the given node and waits for a reply.
 func (t *udp) ping(toid enode.ID, toaddr *net.UDPAddr) error {
-	return <-t.sendPing(toid, toaddr, nil)
+	if err := <-t.sendPing(toid, toaddr, nil); err != nil {
+		if err == errTimeout {
+			atomic.AddUint64(&t.tab.stats.pingTimeouts, 1)
+		}
+		return err
+	}
+	if t.tab.isForeign(toid) {
+		return errForeignChain
+	}
+	return nil
 }
 
 // sendPing sends a ping message to the given node and invokes the callback
@@ -311,6 +324,7 @@ func (t *udp) sendPing(toid enode.ID, toaddr *net.UDPAddr, callback func()) <-ch
 		From:       t.ourEndpoint(),
 		To:         makeEndpoint(toaddr, 0), // TODO: maybe use known TCP port from DB
 		Expiration: uint64(time.Now().Add(expiration).Unix()),
+		Rest:       t.chainRest(),
 	}
 	packet, hash, err := encodePacket(t.priv, pingPacket, req)
 	if err != nil {
@@ -322,7 +336,7 @@ func (t *udp) sendPing(toid enode.ID, toaddr *net.UDPAddr, callback func()) <-ch
 	// reference the ping we're about to send.
 	errc := t.pending(toid, toaddr.IP, pongPacket, func(p interface{}) (matched bool, requestDone bool) {
 		matched = bytes.Equal(p.(*pong).ReplyTok, hash)
-		if matched && callback != nil {
+		if matched && t.checkChain(toid, p.(*pong).Rest) == nil && callback != nil {
 			callback()
 		}
 		return matched, matched
@@ -330,6 +344,7 @@ func (t *udp) sendPing(toid enode.ID, toaddr *net.UDPAddr, callback func()) <-ch
 	// Send the packet.
 	t.localNode.UDPContact(toaddr)
 	t.write(toaddr, toid, req.name(), packet)
+	atomic.AddUint64(&t.tab.stats.pingsSent, 1)
 	return errc
 }
 
@@ -343,6 +358,9 @@ func (t *udp) findnode(toid enode.ID, toaddr *net.UDPAddr, target encPubkey) ([]
 		// Wait for them to ping back and process our pong.
 		time.Sleep(respTimeout)
 	}
+	if t.tab.isForeign(toid) {
+		return nil, errForeignChain
+	}
 
 	// Add a matcher for 'neighbours' replies to the pending reply queue. The matcher is
 	// active until enough nodes have been received.
@@ -657,12 +675,18 @@ func (req *ping) preverify(t *udp, from *net.UDPAddr, fromID enode.ID, fromKey e
 }
 
 func (req *ping) handle(t *udp, from *net.UDPAddr, fromID enode.ID, mac []byte) {
+	atomic.AddUint64(&t.tab.stats.pingsReceived, 1)
 	// Reply.
 	t.send(from, fromID, pongPacket, &pong{
 		To:         makeEndpoint(from, req.From.TCP),
 		ReplyTok:   mac,
 		Expiration: uint64(time.Now().Add(expiration).Unix()),
+		Rest:       t.chainRest(),
 	})
+	if t.checkChain(fromID, req.Rest) != nil {
+		// The pong tells the sender about the mismatch, don't bond with it.
+		return
+	}
 
 	// Ping back if our last pong on file is too far in the past.
 	n := wrapNode(enode.NewV4(req.senderKey, from.IP, int(req.From.TCP), from.Port))
@@ -692,6 +716,7 @@ func (req *pong) preverify(t *udp, from *net.UDPAddr, fromID enode.ID, fromKey e
 }
 
 func (req *pong) handle(t *udp, from *net.UDPAddr, fromID enode.ID, mac []byte) {
+	atomic.AddUint64(&t.tab.stats.pongsReceived, 1)
 	t.localNode.UDPEndpointStatement(from, &net.UDPAddr{IP: req.To.IP, Port: int(req.To.UDP)})
 	t.db.UpdateLastPongReceived(fromID, from.IP, time.Now())
 }
@@ -702,6 +727,9 @@ func (req *findnode) preverify(t *udp, from *net.UDPAddr, fromID enode.ID, fromK
 	if expired(req.Expiration) {
 		return errExpired
 	}
+	if t.tab.isForeign(fromID) {
+		return errForeignChain
+	}
 	if time.Since(t.db.LastPongReceived(fromID, from.IP)) > bondExpiration {
 		// No endpoint proof pong exists, we don't process the packet. This prevents an
 		// attack vector where the discovery protocol could be used to amplify traffic in a
@@ -0,0 +1,303 @@
+package dnsdisc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	defaultTimeout         = 5 * time.Second
+	defaultRecheckInterval = 30 * time.Minute
+
+	// maxLinkDepth bounds how far links between trees are followed.
+	maxLinkDepth = 4
+)
+
+// Resolver is a DNS resolver that can query TXT records.
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+}
+
+// Config holds Client options.
+type Config struct {
+	Timeout         time.Duration // timeout of a single DNS query, default 5s
+	RecheckInterval time.Duration // interval between tree root checks, default 30min
+	Resolver        Resolver      // the DNS resolver, defaults to net.DefaultResolver
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RecheckInterval == 0 {
+		cfg.RecheckInterval = defaultRecheckInterval
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = net.DefaultResolver
+	}
+	return cfg
+}
+
+// Client fetches and verifies node trees.
+type Client struct {
+	cfg Config
+
+	// entries are content addressed, so they are cached for the lifetime
+	// of the client and only roots are re-resolved on recheck.
+	lock  sync.Mutex
+	cache map[string]entry
+}
+
+// NewClient creates a client.
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg.withDefaults(), cache: make(map[string]entry)}
+}
+
+// SyncTree downloads the tree at url and all trees it links to.
+func (c *Client) SyncTree(url string) (*Tree, error) {
+	link, err := parseLink(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tree url %q: %v", url, err)
+	}
+	t := &Tree{entries: make(map[string]entry)}
+	if err := c.syncLink(t, link, make(map[string]bool), 0); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (c *Client) syncLink(t *Tree, link *linkEntry, visited map[string]bool, depth int) error {
+	if visited[link.domain] || depth > maxLinkDepth {
+		return nil
+	}
+	visited[link.domain] = true
+
+	root, err := c.resolveRoot(link)
+	if err != nil {
+		return err
+	}
+	if t.root == nil {
+		t.root = root
+	}
+	if err := c.syncSubtree(t, link.domain, root.eroot, false); err != nil {
+		return err
+	}
+	var links []*linkEntry
+	if err := c.collectLinks(t, link.domain, root.lroot, &links); err != nil {
+		return err
+	}
+	for _, l := range links {
+		if err := c.syncLink(t, l, visited, depth+1); err != nil {
+			log.WithField("tree", l.String()).WithField("err", err).Debug("Skipping linked DNS tree")
+		}
+	}
+	return nil
+}
+
+func (c *Client) syncSubtree(t *Tree, domain, hash string, linksAllowed bool) error {
+	e, err := c.resolveEntry(domain, hash, linksAllowed)
+	if err != nil {
+		return err
+	}
+	t.entries[hash] = e
+	if b, ok := e.(*branchEntry); ok {
+		for _, child := range b.children {
+			if err := c.syncSubtree(t, domain, child, linksAllowed); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) collectLinks(t *Tree, domain, hash string, links *[]*linkEntry) error {
+	e, err := c.resolveEntry(domain, hash, true)
+	if err != nil {
+		return err
+	}
+	switch e := e.(type) {
+	case *branchEntry:
+		for _, child := range e.children {
+			if err := c.collectLinks(t, domain, child, links); err != nil {
+				return err
+			}
+		}
+	case *linkEntry:
+		t.entries[hash] = e
+		*links = append(*links, e)
+	}
+	return nil
+}
+
+// resolveRoot retrieves a root entry and verifies its signature.
+func (c *Client) resolveRoot(link *linkEntry) (*rootEntry, error) {
+	txts, err := c.lookup(link.domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, txt := range txts {
+		if !strings.HasPrefix(txt, rootPrefix) {
+			continue
+		}
+		root, err := parseRoot(txt)
+		if err != nil {
+			return nil, err
+		}
+		if !root.verifySignature(link.pubkey) {
+			return nil, errInvalidSig
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("no root found at %s", link.domain)
+}
+
+// resolveEntry retrieves an entry from the cache or fetches it from the network.
+func (c *Client) resolveEntry(domain, hash string, linksAllowed bool) (entry, error) {
+	key := hash + "." + domain
+	c.lock.Lock()
+	e, ok := c.cache[key]
+	c.lock.Unlock()
+	if ok {
+		return e, nil
+	}
+
+	txts, err := c.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, txt := range txts {
+		h := sha3.Keccak256([]byte(txt))
+		if b32format.EncodeToString(h[:hashLen]) != hash {
+			return nil, errHashMismatch
+		}
+		e, err := parseEntry(txt, linksAllowed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry at %s: %v", key, err)
+		}
+		c.lock.Lock()
+		c.cache[key] = e
+		c.lock.Unlock()
+		return e, nil
+	}
+	return nil, fmt.Errorf("no entry found at %s", key)
+}
+
+func (c *Client) lookup(name string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+	return c.cfg.Resolver.LookupTXT(ctx, name)
+}
+
+// Source keeps the nodes of a set of trees up to date and hands them out
+// at random. It is used by the dialer when the discovery table is sparse.
+type Source struct {
+	client *Client
+	urls   []string
+
+	lock  sync.RWMutex
+	nodes []*enode.Node
+	trees map[string]*Tree
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSource creates a node source for the given tree URLs. Trees are
+// synced in the background until Close is called.
+func (c *Client) NewSource(urls []string) *Source {
+	s := &Source{
+		client: c,
+		urls:   urls,
+		trees:  make(map[string]*Tree),
+		quit:   make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.loop()
+	return s
+}
+
+func (s *Source) loop() {
+	defer s.wg.Done()
+
+	recheck := time.NewTicker(s.client.cfg.RecheckInterval)
+	defer recheck.Stop()
+	for {
+		s.refresh()
+		select {
+		case <-recheck.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// refresh syncs all trees and rebuilds the node set. A tree that fails to
+// sync keeps contributing the nodes of its last successful sync.
+func (s *Source) refresh() {
+	for _, url := range s.urls {
+		t, err := s.client.SyncTree(url)
+		if err != nil {
+			log.WithField("tree", url).WithField("err", err).Warn("DNS discovery sync failed")
+			continue
+		}
+		s.lock.Lock()
+		if prev := s.trees[url]; prev == nil || prev.Seq() != t.Seq() {
+			log.WithField("tree", url).WithField("seq", t.Seq()).WithField("nodes", len(t.Nodes())).Info("DNS discovery tree updated")
+		}
+		s.trees[url] = t
+		s.lock.Unlock()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	seen := make(map[enode.ID]bool)
+	s.nodes = s.nodes[:0]
+	for _, t := range s.trees {
+		for _, n := range t.Nodes() {
+			if !seen[n.ID()] {
+				seen[n.ID()] = true
+				s.nodes = append(s.nodes, n)
+			}
+		}
+	}
+}
+
+// ReadRandomNodes fills buf with random nodes from the synced trees and
+// returns the number of nodes written.
+func (s *Source) ReadRandomNodes(buf []*enode.Node) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	n := copy(buf, s.nodes)
+	if n < len(s.nodes) {
+		// reservoir sample the rest so every node has the same chance
+		for i := n; i < len(s.nodes); i++ {
+			if j := rand.Intn(i + 1); j < n {
+				buf[j] = s.nodes[i]
+			}
+		}
+	}
+	rand.Shuffle(n, func(i, j int) { buf[i], buf[j] = buf[j], buf[i] })
+	return n
+}
+
+// Len returns the number of known nodes.
+func (s *Source) Len() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.nodes)
+}
+
+// Close stops syncing.
+func (s *Source) Close() {
+	close(s.quit)
+	s.wg.Wait()
+}
@@ -0,0 +1,153 @@
+package dnsdisc
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+type mapResolver map[string]string
+
+func (mr mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if record, ok := mr[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, fmt.Errorf("no such host %s", name)
+}
+
+func (mr mapResolver) add(records map[string]string) {
+	for k, v := range records {
+		mr[k] = v
+	}
+}
+
+func testNodes(t *testing.T, n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		key, err := crypto.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = enode.NewV4(key.PubKey(), net.IP{10, 0, byte(i >> 8), byte(i)}, 55555, 55555)
+	}
+	return nodes
+}
+
+func signedTree(t *testing.T, key *secp256k1.PrivateKey, domain string, nodes []*enode.Node, links []string) (*Tree, string) {
+	tree, err := MakeTree(1, nodes, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, url
+}
+
+func TestClientSyncTree(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	nodes := testNodes(t, 40)
+	tree, url := signedTree(t, key, "nodes.example.org", nodes, nil)
+
+	resolver := make(mapResolver)
+	resolver.add(tree.ToTXT("nodes.example.org"))
+
+	c := NewClient(Config{Resolver: resolver})
+	synced, err := c.SyncTree(url)
+	if err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if synced.Seq() != 1 {
+		t.Errorf("wrong seq %d", synced.Seq())
+	}
+	want := make(map[enode.ID]bool)
+	for _, n := range nodes {
+		want[n.ID()] = true
+	}
+	got := synced.Nodes()
+	if len(got) != len(nodes) {
+		t.Fatalf("wrong node count %d, want %d", len(got), len(nodes))
+	}
+	for _, n := range got {
+		if !want[n.ID()] {
+			t.Errorf("unexpected node %v", n.ID())
+		}
+	}
+}
+
+func TestClientSyncLinkedTree(t *testing.T) {
+	key1, _ := crypto.GenerateKey(rand.Reader)
+	key2, _ := crypto.GenerateKey(rand.Reader)
+	tree2, url2 := signedTree(t, key2, "b.example.org", testNodes(t, 3), nil)
+	tree1, url1 := signedTree(t, key1, "a.example.org", testNodes(t, 2), []string{url2})
+
+	resolver := make(mapResolver)
+	resolver.add(tree1.ToTXT("a.example.org"))
+	resolver.add(tree2.ToTXT("b.example.org"))
+
+	synced, err := NewClient(Config{Resolver: resolver}).SyncTree(url1)
+	if err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if n := len(synced.Nodes()); n != 5 {
+		t.Errorf("wrong node count %d, want 5", n)
+	}
+}
+
+func TestClientRejectsBadTree(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	otherKey, _ := crypto.GenerateKey(rand.Reader)
+	tree, url := signedTree(t, key, "nodes.example.org", testNodes(t, 5), nil)
+
+	// root signed by a different key
+	resolver := make(mapResolver)
+	forged, _ := signedTree(t, otherKey, "nodes.example.org", testNodes(t, 5), nil)
+	resolver.add(forged.ToTXT("nodes.example.org"))
+	if _, err := NewClient(Config{Resolver: resolver}).SyncTree(url); err != errInvalidSig {
+		t.Errorf("forged root: got err %v, want %v", err, errInvalidSig)
+	}
+
+	// leaf content that does not match its hash
+	resolver = make(mapResolver)
+	resolver.add(tree.ToTXT("nodes.example.org"))
+	for name, txt := range resolver {
+		if strings.HasPrefix(txt, nodePrefix) {
+			resolver[name] = testNodes(t, 1)[0].String()
+			break
+		}
+	}
+	if _, err := NewClient(Config{Resolver: resolver}).SyncTree(url); err != errHashMismatch {
+		t.Errorf("tampered leaf: got err %v, want %v", err, errHashMismatch)
+	}
+}
+
+func TestSourceReadRandomNodes(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	tree, url := signedTree(t, key, "nodes.example.org", testNodes(t, 20), nil)
+	resolver := make(mapResolver)
+	resolver.add(tree.ToTXT("nodes.example.org"))
+
+	src := NewClient(Config{Resolver: resolver}).NewSource([]string{url})
+	defer src.Close()
+	src.refresh()
+
+	buf := make([]*enode.Node, 8)
+	if n := src.ReadRandomNodes(buf); n != len(buf) {
+		t.Fatalf("got %d nodes, want %d", n, len(buf))
+	}
+	seen := make(map[enode.ID]bool)
+	for _, n := range buf {
+		if seen[n.ID()] {
+			t.Errorf("duplicate node %v", n.ID())
+		}
+		seen[n.ID()] = true
+	}
+}
@@ -0,0 +1,13 @@
+package dnsdisc
+
+import (
+	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
+)
+
+const (
+	MODULENAME = "p2p"
+)
+
+var (
+	log = dlog.EnsureLogger(MODULENAME)
+)
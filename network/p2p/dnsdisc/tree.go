@@ -0,0 +1,328 @@
+// Package dnsdisc implements node discovery via signed node trees published
+// as DNS TXT records, in the style of EIP-1459.
+//
+// A tree is rooted at a TXT record on the tree's domain:
+//
+//	enrtree-root:v1 e=<node-root> l=<link-root> seq=<n> sig=<signature>
+//
+// Every other entry lives at <hash>.<domain>, where hash is the base32
+// encoded first 16 bytes of the keccak256 of the entry text. Entries are
+// branches ("enrtree-branch:<h1>,<h2>,..."), links to other trees
+// ("enrtree://<key>@<domain>") or node leaves. Node leaves carry enode URLs,
+// the same format accepted for bootnodes.
+package dnsdisc
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	rootPrefix   = "enrtree-root:v1"
+	branchPrefix = "enrtree-branch:"
+	linkPrefix   = "enrtree://"
+	nodePrefix   = "enode://"
+
+	// maxChildren keeps branch records below the 370 byte TXT payload
+	// that fits into a single UDP DNS response.
+	maxChildren = 13
+	hashLen     = 16
+)
+
+var (
+	b32format = base32.StdEncoding.WithPadding(base32.NoPadding)
+	b64format = base64.RawURLEncoding
+
+	errUnknownEntry = errors.New("unknown entry type")
+	errNoPubkey     = errors.New("missing public key")
+	errBadPubkey    = errors.New("invalid public key")
+	errInvalidHash  = errors.New("invalid child hash")
+	errInvalidSig   = errors.New("invalid root signature")
+	errSyntax       = errors.New("invalid syntax")
+	errHashMismatch = errors.New("hash mismatch")
+)
+
+type entry interface {
+	fmt.Stringer
+}
+
+type (
+	rootEntry struct {
+		eroot string
+		lroot string
+		seq   uint
+		sig   []byte
+	}
+	branchEntry struct {
+		children []string
+	}
+	linkEntry struct {
+		str    string
+		domain string
+		pubkey *secp256k1.PublicKey
+	}
+	nodeEntry struct {
+		node *enode.Node
+	}
+)
+
+func (e *rootEntry) sigHash() []byte {
+	return sha3.Keccak256([]byte(fmt.Sprintf("%s e=%s l=%s seq=%d", rootPrefix, e.eroot, e.lroot, e.seq)))
+}
+
+func (e *rootEntry) verifySignature(pubkey *secp256k1.PublicKey) bool {
+	if len(e.sig) != 65 {
+		return false
+	}
+	return crypto.VerifySignature(crypto.CompressPubkey(pubkey), e.sigHash(), e.sig[:64])
+}
+
+func (e *rootEntry) String() string {
+	return fmt.Sprintf("%s e=%s l=%s seq=%d sig=%s", rootPrefix, e.eroot, e.lroot, e.seq, b64format.EncodeToString(e.sig))
+}
+
+func (e *branchEntry) String() string {
+	return branchPrefix + strings.Join(e.children, ",")
+}
+
+func (e *linkEntry) String() string {
+	return linkPrefix + e.str
+}
+
+func (e *nodeEntry) String() string {
+	return e.node.String()
+}
+
+// subdomain returns the name under which e is published.
+func subdomain(e entry) string {
+	h := sha3.Keccak256([]byte(e.String()))
+	return b32format.EncodeToString(h[:hashLen])
+}
+
+func parseRoot(text string) (*rootEntry, error) {
+	var (
+		e      rootEntry
+		sigstr string
+	)
+	if _, err := fmt.Sscanf(text, rootPrefix+" e=%s l=%s seq=%d sig=%s", &e.eroot, &e.lroot, &e.seq, &sigstr); err != nil {
+		return nil, errSyntax
+	}
+	if !isValidHash(e.eroot) || !isValidHash(e.lroot) {
+		return nil, errInvalidHash
+	}
+	sig, err := b64format.DecodeString(sigstr)
+	if err != nil || len(sig) != 65 {
+		return nil, errInvalidSig
+	}
+	e.sig = sig
+	return &e, nil
+}
+
+// parseEntry parses a non-root entry. Links are only accepted when
+// linksAllowed is set, node leaves only when it is not, so the node and
+// link subtrees cannot be mixed.
+func parseEntry(text string, linksAllowed bool) (entry, error) {
+	switch {
+	case strings.HasPrefix(text, branchPrefix):
+		return parseBranch(text[len(branchPrefix):])
+	case linksAllowed && strings.HasPrefix(text, linkPrefix):
+		return parseLink(text)
+	case !linksAllowed && strings.HasPrefix(text, nodePrefix):
+		n, err := enode.ParseV4(text)
+		if err != nil {
+			return nil, err
+		}
+		if n.Incomplete() {
+			return nil, fmt.Errorf("incomplete node %v", n.ID())
+		}
+		return &nodeEntry{node: n}, nil
+	default:
+		return nil, errUnknownEntry
+	}
+}
+
+func parseBranch(text string) (entry, error) {
+	var children []string
+	if text != "" {
+		children = strings.Split(text, ",")
+	}
+	for _, c := range children {
+		if !isValidHash(c) {
+			return nil, errInvalidHash
+		}
+	}
+	return &branchEntry{children}, nil
+}
+
+// parseLink parses a tree URL of the form enrtree://<base32 key>@<domain>.
+func parseLink(text string) (*linkEntry, error) {
+	if !strings.HasPrefix(text, linkPrefix) {
+		return nil, errors.New("not a valid tree url")
+	}
+	str := text[len(linkPrefix):]
+	pos := strings.IndexByte(str, '@')
+	if pos == -1 {
+		return nil, errNoPubkey
+	}
+	keystring, domain := str[:pos], str[pos+1:]
+	if domain == "" {
+		return nil, errSyntax
+	}
+	keybytes, err := b32format.DecodeString(keystring)
+	if err != nil {
+		return nil, errBadPubkey
+	}
+	key, err := crypto.DecompressPubkey(keybytes)
+	if err != nil {
+		return nil, errBadPubkey
+	}
+	return &linkEntry{str: str, domain: domain, pubkey: key}, nil
+}
+
+// ParseURL checks a tree URL and returns the domain it points to.
+func ParseURL(url string) (string, error) {
+	link, err := parseLink(url)
+	if err != nil {
+		return "", err
+	}
+	return link.domain, nil
+}
+
+func isValidHash(s string) bool {
+	dlen := b32format.DecodedLen(len(s))
+	if dlen < 12 || dlen > 32 || strings.ContainsAny(s, "\n\r") {
+		return false
+	}
+	buf := make([]byte, 32)
+	_, err := b32format.Decode(buf, []byte(s))
+	return err == nil
+}
+
+// Tree is a signed node tree, as published or as fetched by a Client.
+type Tree struct {
+	root    *rootEntry
+	entries map[string]entry
+}
+
+// MakeTree creates an unsigned tree containing the given nodes and links.
+func MakeTree(seq uint, nodes []*enode.Node, links []string) (*Tree, error) {
+	// Sort for a stable tree layout.
+	records := make([]*enode.Node, len(nodes))
+	copy(records, nodes)
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].String() < records[j].String()
+	})
+	sortedLinks := make([]string, len(links))
+	copy(sortedLinks, links)
+	sort.Strings(sortedLinks)
+
+	t := &Tree{entries: make(map[string]entry)}
+	nodeEntries := make([]entry, 0, len(records))
+	for _, n := range records {
+		if n.Incomplete() {
+			return nil, fmt.Errorf("node %v has no endpoint", n.ID())
+		}
+		nodeEntries = append(nodeEntries, &nodeEntry{node: n})
+	}
+	linkEntries := make([]entry, 0, len(sortedLinks))
+	for _, l := range sortedLinks {
+		le, err := parseLink(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid link %q: %v", l, err)
+		}
+		linkEntries = append(linkEntries, le)
+	}
+
+	eroot := t.build(nodeEntries)
+	lroot := t.build(linkEntries)
+	t.root = &rootEntry{eroot: subdomain(eroot), lroot: subdomain(lroot), seq: seq}
+	return t, nil
+}
+
+// build adds the subtree over the given leaves and returns its root.
+func (t *Tree) build(leaves []entry) entry {
+	if len(leaves) == 1 {
+		t.entries[subdomain(leaves[0])] = leaves[0]
+		return leaves[0]
+	}
+	if len(leaves) <= maxChildren {
+		b := &branchEntry{children: make([]string, len(leaves))}
+		for i, e := range leaves {
+			b.children[i] = subdomain(e)
+			t.entries[b.children[i]] = e
+		}
+		t.entries[subdomain(b)] = b
+		return b
+	}
+	var children []entry
+	for len(leaves) > 0 {
+		n := maxChildren
+		if len(leaves) < n {
+			n = len(leaves)
+		}
+		children = append(children, t.build(leaves[:n]))
+		leaves = leaves[n:]
+	}
+	return t.build(children)
+}
+
+// Sign signs the tree root with key and returns the tree URL on domain.
+func (t *Tree) Sign(key *secp256k1.PrivateKey, domain string) (string, error) {
+	sig, err := crypto.Sign(t.root.sigHash(), key)
+	if err != nil {
+		return "", err
+	}
+	t.root.sig = sig
+	return linkPrefix + b32format.EncodeToString(crypto.CompressPubkey(key.PubKey())) + "@" + domain, nil
+}
+
+// Seq returns the sequence number of the tree.
+func (t *Tree) Seq() uint {
+	return t.root.seq
+}
+
+// Nodes returns all nodes contained in the tree.
+func (t *Tree) Nodes() []*enode.Node {
+	var nodes []*enode.Node
+	for _, e := range t.entries {
+		if ne, ok := e.(*nodeEntry); ok {
+			nodes = append(nodes, ne.node)
+		}
+	}
+	return nodes
+}
+
+// Links returns the URLs of all trees linked from this tree.
+func (t *Tree) Links() []string {
+	var links []string
+	for _, e := range t.entries {
+		if le, ok := e.(*linkEntry); ok {
+			links = append(links, le.String())
+		}
+	}
+	return links
+}
+
+// ToTXT returns the TXT records of a signed tree, keyed by DNS name.
+func (t *Tree) ToTXT(domain string) map[string]string {
+	records := map[string]string{domain: t.root.String()}
+	for sub, e := range t.entries {
+		records[sub+"."+domain] = e.String()
+	}
+	return records
+}
+
+// String is a debug representation of the tree root.
+func (t *Tree) String() string {
+	return "seq=" + strconv.FormatUint(uint64(t.root.seq), 10) + " nodes=" + strconv.Itoa(len(t.Nodes()))
+}
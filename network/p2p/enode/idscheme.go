@@ -193,7 +193,7 @@ func (NullID) NodeAddr(r *enr.Record) []byte {
 
 func SignNull(r *enr.Record, id ID) *Node {
 	r.Set(enr.ID("null"))
-	r.Set(enr.WithEntry("nulladdr", id))
+	r.Set(enr.WithEntry("nulladdr", &id))
 	if err := r.SetSig(NullID{}, []byte{}); err != nil {
 		panic(err)
 	}
@@ -26,13 +26,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/leveldb"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
 	drepbinary "github.com/drep-project/binary"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/errors"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
-	"github.com/syndtr/goleveldb/leveldb/opt"
-	"github.com/syndtr/goleveldb/leveldb/storage"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // Keys in the node database.
@@ -65,9 +62,9 @@ var zeroIP = make(net.IP, 16)
 // DB is the node database, storing previously seen nodes and any collected metadata about
 // them for QoS purposes.
 type DB struct {
-	lvl    *leveldb.DB   // Interface to the database itself
-	runner sync.Once     // Ensures we can start at most one expirer
-	quit   chan struct{} // Channel to signal the expiring thread to stop
+	kv     dbinterface.KeyValueStore // Interface to the database itself
+	runner sync.Once                 // Ensures we can start at most one expirer
+	quit   chan struct{}             // Channel to signal the expiring thread to stop
 }
 
 // OpenDB opens a node database for storing and retrieving infos about known peers in the
@@ -81,21 +78,13 @@ func OpenDB(path string) (*DB, error) {
 
 // newMemoryNodeDB creates a new in-memory node database without a persistent backend.
 func newMemoryDB() (*DB, error) {
-	db, err := leveldb.Open(storage.NewMemStorage(), nil)
-	if err != nil {
-		return nil, err
-	}
-	return &DB{lvl: db, quit: make(chan struct{})}, nil
+	return &DB{kv: memorydb.New(), quit: make(chan struct{})}, nil
 }
 
 // newPersistentNodeDB creates/opens a leveldb backed persistent node database,
 // also flushing its contents in case of a version mismatch.
 func newPersistentDB(path string) (*DB, error) {
-	opts := &opt.Options{OpenFilesCacheCapacity: 5}
-	db, err := leveldb.OpenFile(path, opts)
-	if _, iscorrupted := err.(*errors.ErrCorrupted); iscorrupted {
-		db, err = leveldb.RecoverFile(path, nil)
-	}
+	db, err := leveldb.New(path, 0, 5, "")
 	if err != nil {
 		return nil, err
 	}
@@ -105,16 +94,18 @@ func newPersistentDB(path string) (*DB, error) {
 	currentVer := make([]byte, binary.MaxVarintLen64)
 	currentVer = currentVer[:binary.PutVarint(currentVer, int64(dbVersion))]
 
-	blob, err := db.Get([]byte(dbVersionKey), nil)
-	switch err {
-	case leveldb.ErrNotFound:
+	exist, err := db.Has([]byte(dbVersionKey))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if !exist {
 		// Version not found (i.e. empty cache), insert it
-		if err := db.Put([]byte(dbVersionKey), currentVer, nil); err != nil {
+		if err := db.Put([]byte(dbVersionKey), currentVer); err != nil {
 			db.Close()
 			return nil, err
 		}
-
-	case nil:
+	} else if blob, err := db.Get([]byte(dbVersionKey)); err == nil {
 		// Version present, flush if different
 		if !bytes.Equal(blob, currentVer) {
 			db.Close()
@@ -124,7 +115,7 @@ func newPersistentDB(path string) (*DB, error) {
 			return newPersistentDB(path)
 		}
 	}
-	return &DB{lvl: db, quit: make(chan struct{})}, nil
+	return &DB{kv: db, quit: make(chan struct{})}, nil
 }
 
 // nodeKey returns the database key for a node record.
@@ -183,7 +174,7 @@ func localItemKey(id ID, field string) []byte {
 
 // fetchInt64 retrieves an integer associated with a particular key.
 func (db *DB) fetchInt64(key []byte) int64 {
-	blob, err := db.lvl.Get(key, nil)
+	blob, err := db.kv.Get(key)
 	if err != nil {
 		return 0
 	}
@@ -198,12 +189,12 @@ func (db *DB) fetchInt64(key []byte) int64 {
 func (db *DB) storeInt64(key []byte, n int64) error {
 	blob := make([]byte, binary.MaxVarintLen64)
 	blob = blob[:binary.PutVarint(blob, n)]
-	return db.lvl.Put(key, blob, nil)
+	return db.kv.Put(key, blob)
 }
 
 // fetchUint64 retrieves an integer associated with a particular key.
 func (db *DB) fetchUint64(key []byte) uint64 {
-	blob, err := db.lvl.Get(key, nil)
+	blob, err := db.kv.Get(key)
 	if err != nil {
 		return 0
 	}
@@ -215,12 +206,12 @@ func (db *DB) fetchUint64(key []byte) uint64 {
 func (db *DB) storeUint64(key []byte, n uint64) error {
 	blob := make([]byte, binary.MaxVarintLen64)
 	blob = blob[:binary.PutUvarint(blob, n)]
-	return db.lvl.Put(key, blob, nil)
+	return db.kv.Put(key, blob)
 }
 
 // Node retrieves a node with a given id from the database.
 func (db *DB) Node(id ID) *Node {
-	blob, err := db.lvl.Get(nodeKey(id), nil)
+	blob, err := db.kv.Get(nodeKey(id))
 	if err != nil {
 		return nil
 	}
@@ -246,7 +237,7 @@ func (db *DB) UpdateNode(node *Node) error {
 	if err != nil {
 		return err
 	}
-	if err := db.lvl.Put(nodeKey(node.ID()), blob, nil); err != nil {
+	if err := db.kv.Put(nodeKey(node.ID()), blob); err != nil {
 		return err
 	}
 	return db.storeUint64(nodeItemKey(node.ID(), zeroIP, dbNodeSeq), node.Seq())
@@ -268,14 +259,14 @@ func (db *DB) Resolve(n *Node) *Node {
 
 // DeleteNode deletes all information associated with a node.
 func (db *DB) DeleteNode(id ID) {
-	deleteRange(db.lvl, nodeKey(id))
+	deleteRange(db.kv, nodeKey(id))
 }
 
-func deleteRange(db *leveldb.DB, prefix []byte) {
-	it := db.NewIterator(util.BytesPrefix(prefix), nil)
+func deleteRange(db dbinterface.KeyValueStore, prefix []byte) {
+	it := db.NewIteratorWithPrefix(prefix)
 	defer it.Release()
 	for it.Next() {
-		db.Delete(it.Key(), nil)
+		db.Delete(it.Key())
 	}
 }
 
@@ -310,7 +301,7 @@ func (db *DB) expirer() {
 // expireNodes iterates over the database and deletes all nodes that have not
 // been seen (i.e. received a pong from) for some time.
 func (db *DB) expireNodes() {
-	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbNodePrefix)), nil)
+	it := db.kv.NewIteratorWithPrefix([]byte(dbNodePrefix))
 	defer it.Release()
 	if !it.Next() {
 		return
@@ -330,7 +321,7 @@ func (db *DB) expireNodes() {
 			}
 			if time < threshold {
 				// Last pong from this Node older than threshold, remove fields belonging to it.
-				deleteRange(db.lvl, nodeItemKey(id, ip, ""))
+				deleteRange(db.kv, nodeItemKey(id, ip, ""))
 			}
 		}
 		atEnd = !it.Next()
@@ -339,7 +330,7 @@ func (db *DB) expireNodes() {
 			// We've moved beyond the last entry of the current ID.
 			// Remove everything if there was no recent enough pong.
 			if youngestPong > 0 && youngestPong < threshold {
-				deleteRange(db.lvl, nodeKey(id))
+				deleteRange(db.kv, nodeKey(id))
 			}
 			youngestPong = 0
 		}
@@ -395,10 +386,8 @@ func (db *DB) QuerySeeds(n int, maxAge time.Duration) []*Node {
 	var (
 		now   = time.Now()
 		nodes = make([]*Node, 0, n)
-		it    = db.lvl.NewIterator(nil, nil)
 		id    ID
 	)
-	defer it.Release()
 
 seek:
 	for seeks := 0; len(nodes) < n && seeks < n*5; seeks++ {
@@ -408,9 +397,9 @@ seek:
 		ctr := id[0]
 		rand.Read(id[:])
 		id[0] = ctr + id[0]%16
-		it.Seek(nodeKey(id))
-
+		it := db.kv.NewIteratorWithStart(nodeKey(id))
 		n := nextNode(it)
+		it.Release()
 		if n == nil {
 			id[0] = 0
 			continue seek // iterator exhausted
@@ -430,8 +419,8 @@ seek:
 
 // reads the next node record from the iterator, skipping over other
 // database entries.
-func nextNode(it iterator.Iterator) *Node {
-	for end := false; !end; end = !it.Next() {
+func nextNode(it dbinterface.Iterator) *Node {
+	for it.Next() {
 		id, rest := splitNodeKey(it.Key())
 		if string(rest) != dbDiscoverRoot {
 			continue
@@ -444,5 +433,5 @@ func nextNode(it iterator.Iterator) *Node {
 // close flushes and closes the database files.
 func (db *DB) Close() {
 	close(db.quit)
-	db.lvl.Close()
+	db.kv.Close()
 }
@@ -293,3 +293,33 @@ func (testSig) NodeAddr(r *Record) []byte {
 	}
 	return id
 }
+
+func TestChainSameNetwork(t *testing.T) {
+	genesis := [32]byte{1}
+	local := Chain{ChainId: 1, GenesisHash: genesis, Role: RoleFull}
+
+	tests := []struct {
+		remote Chain
+		want   bool
+	}{
+		{Chain{ChainId: 1, GenesisHash: genesis, Role: RoleProducer, Highest: 2048}, true},
+		{Chain{ChainId: 1}, true}, // genesis unknown
+		{Chain{ChainId: 2, GenesisHash: genesis}, false},
+		{Chain{ChainId: 1, GenesisHash: [32]byte{2}}, false},
+	}
+	for i, test := range tests {
+		if got := local.SameNetwork(test.remote); got != test.want {
+			t.Errorf("test %d: got %v, want %v", i, got, test.want)
+		}
+	}
+
+	var r Record
+	r.Set(local)
+	var loaded Chain
+	if err := r.Load(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded != local {
+		t.Errorf("loaded %+v, want %+v", loaded, local)
+	}
+}
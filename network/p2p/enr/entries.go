@@ -66,6 +66,45 @@ type IP net.IP
 
 func (v IP) ENRKey() string { return "ip" }
 
+// Node roles carried in the Chain entry.
+const (
+	RoleProducer = "producer"
+	RoleFull     = "full"
+	RoleLight    = "light"
+)
+
+// Chain is the "drep" key, which holds the network the node belongs to, the
+// block heights it can serve and its role.
+type Chain struct {
+	ChainId     uint64
+	GenesisHash [32]byte
+	Lowest      uint64 // lowest block height kept by the node
+	Highest     uint64 // head height, only updated in coarse steps
+	Role        string
+}
+
+func (v Chain) ENRKey() string { return "drep" }
+
+// SameNetwork reports whether o describes the same DREP network. A zero
+// genesis hash is unknown and matches any genesis.
+func (v Chain) SameNetwork(o Chain) bool {
+	if v.ChainId != o.ChainId {
+		return false
+	}
+	var zero [32]byte
+	return v.GenesisHash == zero || o.GenesisHash == zero || v.GenesisHash == o.GenesisHash
+}
+
+// Fork is the "fork" key, which identifies the block rules the node follows:
+// a checksum of the genesis hash and of the forks it has passed, and the
+// height of its next fork, 0 if none is scheduled.
+type Fork struct {
+	Hash [4]byte
+	Next uint64
+}
+
+func (v Fork) ENRKey() string { return "fork" }
+
 // KeyError is an error related to a key.
 type KeyError struct {
 	Key string
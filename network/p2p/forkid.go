@@ -0,0 +1,167 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"sort"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+)
+
+// Fork identifier
+//
+// The fork identifier is a checksum of the genesis hash and of the heights of
+// the forks a node has passed, with the height of the next fork it knows of.
+// Nodes follow compatible rules when they have passed the same forks, or when
+// one is behind the other on the same schedule and knows the fork it misses.
+// It is advertised in the node record and in discovery pings, nodes following
+// other rules are neither added to the discovery table nor dialed.
+
+var (
+	errOtherNetwork     = errors.New("node record of another network")
+	errForkRemoteStale  = errors.New("remote node misses a fork already passed")
+	errForkIncompatible = errors.New("remote node follows other forks")
+)
+
+// forkSchedule returns the distinct fork heights sorted, fork 0 is the genesis.
+func forkSchedule(forks []uint64) []uint64 {
+	schedule := make([]uint64, 0, len(forks))
+	for _, fork := range forks {
+		if fork != 0 {
+			schedule = append(schedule, fork)
+		}
+	}
+	sort.Slice(schedule, func(i, j int) bool { return schedule[i] < schedule[j] })
+	distinct := schedule[:0]
+	for i, fork := range schedule {
+		if i == 0 || fork != schedule[i-1] {
+			distinct = append(distinct, fork)
+		}
+	}
+	return distinct
+}
+
+// forkChecksums returns the checksums of the genesis hash followed by the
+// first i forks of schedule, for i from 0 to len(schedule).
+func forkChecksums(genesis [32]byte, schedule []uint64) [][4]byte {
+	sums := make([][4]byte, len(schedule)+1)
+	sum := crc32.ChecksumIEEE(genesis[:])
+	binary.BigEndian.PutUint32(sums[0][:], sum)
+	for i, fork := range schedule {
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], fork)
+		sum = crc32.Update(sum, crc32.IEEETable, buf[:])
+		binary.BigEndian.PutUint32(sums[i+1][:], sum)
+	}
+	return sums
+}
+
+// passedForks returns the number of forks of schedule passed at height head.
+func passedForks(schedule []uint64, head uint64) int {
+	return sort.Search(len(schedule), func(i int) bool { return schedule[i] > head })
+}
+
+// newForkID returns the fork identifier of a node at height head.
+func newForkID(genesis [32]byte, forks []uint64, head uint64) enr.Fork {
+	schedule := forkSchedule(forks)
+	passed := passedForks(schedule, head)
+	id := enr.Fork{Hash: forkChecksums(genesis, schedule)[passed]}
+	if passed < len(schedule) {
+		id.Next = schedule[passed]
+	}
+	return id
+}
+
+// checkForkID reports whether a node advertising remote follows rules
+// compatible with a node at height head.
+func checkForkID(genesis [32]byte, forks []uint64, head uint64, remote enr.Fork) error {
+	schedule := forkSchedule(forks)
+	sums := forkChecksums(genesis, schedule)
+	passed := passedForks(schedule, head)
+
+	// Same forks passed, the remote node must not announce a fork passed
+	// locally without being in the local schedule.
+	if sums[passed] == remote.Hash {
+		if remote.Next != 0 && head >= remote.Next {
+			return errForkIncompatible
+		}
+		return nil
+	}
+	// The remote node is behind, it must know the next fork it misses.
+	for i := 0; i < passed; i++ {
+		if sums[i] == remote.Hash {
+			if remote.Next != schedule[i] {
+				return errForkRemoteStale
+			}
+			return nil
+		}
+	}
+	// The remote node is ahead on the local schedule, we are syncing.
+	for i := passed + 1; i < len(sums); i++ {
+		if sums[i] == remote.Hash {
+			return nil
+		}
+	}
+	return errForkIncompatible
+}
+
+// forkEntry returns the fork identifier of the local node record.
+// srv.lock must be held.
+func (srv *Server) forkEntry() enr.Fork {
+	return newForkID(srv.GenesisHash, srv.Forks, srv.head)
+}
+
+// setForkEntry publishes the fork identifier once the genesis is known, it
+// changes at fork heights only. srv.lock must be held.
+func (srv *Server) setForkEntry() {
+	if srv.localnode != nil && srv.GenesisHash != (crypto.Hash{}) {
+		srv.localnode.Set(srv.forkEntry())
+	}
+}
+
+// SetForks sets the heights of the forks of the network, the fork identifier
+// advertised is derived from them.
+func (srv *Server) SetForks(forks []uint64) {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	srv.Forks = forks
+	srv.setForkEntry()
+}
+
+// ForkEntry returns the fork identifier of the local node.
+func (srv *Server) ForkEntry() enr.Fork {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	return srv.forkEntry()
+}
+
+// CheckFork reports whether a node advertising fork follows rules compatible
+// with the local node. Any fork passes while the genesis is unknown.
+func (srv *Server) CheckFork(fork enr.Fork) error {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	if srv.GenesisHash == (crypto.Hash{}) {
+		return nil
+	}
+	return checkForkID(srv.GenesisHash, srv.Forks, srv.head, fork)
+}
+
+// checkNetwork reports whether the record of n shows another network or
+// incompatible forks. Records without these entries pass, the handshake
+// checks the chain id.
+func (srv *Server) checkNetwork(n *enode.Node) error {
+	var chain enr.Chain
+	if err := n.Load(&chain); err == nil {
+		if local := srv.ChainEntry(); !local.SameNetwork(chain) {
+			return errOtherNetwork
+		}
+	}
+	var fork enr.Fork
+	if err := n.Load(&fork); err == nil {
+		return srv.CheckFork(fork)
+	}
+	return nil
+}
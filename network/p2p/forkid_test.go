@@ -0,0 +1,45 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
+)
+
+func TestForkID(t *testing.T) {
+	genesis := [32]byte{1}
+	forks := []uint64{200, 100, 100, 0}
+	sums := forkChecksums(genesis, forkSchedule(forks))
+	if len(sums) != 3 {
+		t.Fatalf("got %d checksums, want 3", len(sums))
+	}
+
+	if id := newForkID(genesis, forks, 99); id != (enr.Fork{Hash: sums[0], Next: 100}) {
+		t.Errorf("fork id before first fork: %+v", id)
+	}
+	if id := newForkID(genesis, forks, 150); id != (enr.Fork{Hash: sums[1], Next: 200}) {
+		t.Errorf("fork id between forks: %+v", id)
+	}
+	if id := newForkID(genesis, forks, 200); id != (enr.Fork{Hash: sums[2]}) {
+		t.Errorf("fork id after last fork: %+v", id)
+	}
+
+	other := forkChecksums([32]byte{2}, nil)[0]
+	tests := []struct {
+		remote enr.Fork
+		err    error
+	}{
+		{enr.Fork{Hash: sums[1], Next: 200}, nil},
+		{enr.Fork{Hash: sums[1]}, nil},                            // next fork not scheduled remotely yet
+		{enr.Fork{Hash: sums[1], Next: 120}, errForkIncompatible}, // remote fork passed locally
+		{enr.Fork{Hash: sums[0], Next: 100}, nil},                 // remote syncing
+		{enr.Fork{Hash: sums[0]}, errForkRemoteStale},             // remote misses fork 100
+		{enr.Fork{Hash: sums[2]}, nil},                            // local syncing
+		{enr.Fork{Hash: other}, errForkIncompatible},
+	}
+	for i, test := range tests {
+		if err := checkForkID(genesis, forks, 150, test.remote); err != test.err {
+			t.Errorf("test %d: got %v, want %v", i, err, test.err)
+		}
+	}
+}
@@ -54,9 +54,20 @@ func (msg Msg) Decode(val interface{}) error {
 	//	return newPeerError(errInvalidMsg, "(code %x) (size %d) %v", msg.Code, msg.Size, err)
 	//}
 
-	buf, err := ioutil.ReadAll(msg.Payload)
-	if err != nil {
-		return err
+	// The decoded value may alias buf, so it is allocated per message
+	// rather than taken from a pool. Sizing it up front avoids the
+	// repeated growth of ioutil.ReadAll.
+	var buf []byte
+	if msg.Size > 0 {
+		buf = make([]byte, msg.Size)
+		if _, err := io.ReadFull(msg.Payload, buf); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if buf, err = ioutil.ReadAll(msg.Payload); err != nil {
+			return err
+		}
 	}
 
 	return binary.Unmarshal(buf, val)
@@ -93,12 +104,31 @@ type MsgReadWriter interface {
 	MsgWriter
 }
 
+// Payload is an already encoded message body. Send writes a Payload
+// as-is, so a message gossiped to many peers only has to be encoded once.
+type Payload []byte
+
+// EncodePayload encodes data into a Payload that can be passed to Send
+// any number of times.
+func EncodePayload(data interface{}) (Payload, error) {
+	buf, err := binary.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return Payload(buf), nil
+}
+
 // Send writes an RLP-encoded message with the given code.
 // data should encode as an RLP list.
 func Send(w MsgWriter, msgcode uint64, data interface{}) error {
-	buf, err := binary.Marshal(data)
-	if err != nil {
-		return err
+	var buf []byte
+	if payload, ok := data.(Payload); ok {
+		buf = payload
+	} else {
+		var err error
+		if buf, err = binary.Marshal(data); err != nil {
+			return err
+		}
 	}
 
 	size := len(buf)
@@ -0,0 +1,50 @@
+package p2p
+
+import (
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	ingressMeterName = "p2p/ingress"
+	egressMeterName  = "p2p/egress"
+)
+
+var (
+	peersGauge        = metrics.NewRegisteredGauge("p2p/peers", nil)
+	inboundPeersGauge = metrics.NewRegisteredGauge("p2p/peers/inbound", nil)
+	peerAddMeter      = metrics.NewRegisteredMeter("p2p/peers/add", nil)
+	peerDropMeter     = metrics.NewRegisteredMeter("p2p/peers/drop", nil)
+
+	// handshake failures by stage, rejections by the server checks are counted apart
+	encHandshakeFailMeter   = metrics.NewRegisteredMeter("p2p/handshake/enc/failures", nil)
+	protoHandshakeFailMeter = metrics.NewRegisteredMeter("p2p/handshake/proto/failures", nil)
+	handshakeRejectMeter    = metrics.NewRegisteredMeter("p2p/handshake/rejected", nil)
+
+	// adaptive dialing, see dialStats
+	dialTableSuccessGauge  = metrics.NewRegisteredGaugeFloat64("p2p/dial/success/table", nil)
+	dialLookupSuccessGauge = metrics.NewRegisteredGaugeFloat64("p2p/dial/success/lookup", nil)
+	peerChurnGauge         = metrics.NewRegisteredGaugeFloat64("p2p/peers/churn", nil)
+	dialBackoffGauge       = metrics.NewRegisteredGauge("p2p/dial/backoff/subnets", nil)
+
+	// messages dropped by the rate limits and peers disconnected for exceeding them
+	throttledMeter     = metrics.NewRegisteredMeter("p2p/ratelimit/throttled", nil)
+	rateLimitDropMeter = metrics.NewRegisteredMeter("p2p/ratelimit/drops", nil)
+
+	// payload bytes of snappy frames before and after compression, their ratio is the
+	// bandwidth saved
+	snappyEgressPlainMeter  = metrics.NewRegisteredMeter("p2p/snappy/egress/plain", nil)
+	snappyEgressFrameMeter  = metrics.NewRegisteredMeter("p2p/snappy/egress/compressed", nil)
+	snappyIngressPlainMeter = metrics.NewRegisteredMeter("p2p/snappy/ingress/plain", nil)
+	snappyIngressFrameMeter = metrics.NewRegisteredMeter("p2p/snappy/ingress/compressed", nil)
+)
+
+// markProtoTraffic counts message and payload bytes of a sub protocol, direction is
+// ingressMeterName or egressMeterName. Meters are created on first use so that the
+// registry only holds protocols that actually run.
+func markProtoTraffic(direction, proto string, size uint32) {
+	if !metrics.Enabled {
+		return
+	}
+	metrics.GetOrRegisterMeter(direction+"/"+proto+"/packets", nil).Mark(1)
+	metrics.GetOrRegisterMeter(direction+"/"+proto+"/bytes", nil).Mark(int64(size))
+}
@@ -0,0 +1,28 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func TestMarkProtoTraffic(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	markProtoTraffic(egressMeterName, "metricstest", 100)
+	markProtoTraffic(egressMeterName, "metricstest", 28)
+
+	packets := metrics.GetOrRegisterMeter(egressMeterName+"/metricstest/packets", nil)
+	if packets.Count() != 2 {
+		t.Errorf("packets count %d, want 2", packets.Count())
+	}
+	bytes := metrics.GetOrRegisterMeter(egressMeterName+"/metricstest/bytes", nil)
+	if bytes.Count() != 128 {
+		t.Errorf("bytes count %d, want 128", bytes.Count())
+	}
+	if metrics.DefaultRegistry.Get(ingressMeterName+"/metricstest/bytes") != nil {
+		t.Error("ingress meter registered without traffic")
+	}
+}
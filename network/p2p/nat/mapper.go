@@ -0,0 +1,208 @@
+package nat
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// retry delays used when the gateway refuses or drops a mapping
+	minRetryInterval = 30 * time.Second
+	maxRetryInterval = mapUpdateInterval
+)
+
+// MappingStatus is the state of one port mapping.
+type MappingStatus struct {
+	Protocol  string    `json:"protocol"`
+	ExtPort   int       `json:"extPort"`
+	IntPort   int       `json:"intPort"`
+	Name      string    `json:"name"`
+	Mapped    bool      `json:"mapped"`
+	Expiry    time.Time `json:"expiry"`    // lease expiry of the last successful mapping
+	NextRenew time.Time `json:"nextRenew"` // time of the next refresh or retry
+	Failures  int       `json:"failures"`  // consecutive failed attempts
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Status is a snapshot of the port mapper.
+type Status struct {
+	Interface  string          `json:"interface"`
+	ExternalIP string          `json:"externalIP"`
+	Mappings   []MappingStatus `json:"mappings"`
+}
+
+// Mapper keeps port mappings on a NAT device alive, retries with back-off
+// when the gateway drops a lease and records the state for inspection.
+type Mapper struct {
+	nat Interface
+
+	lock     sync.Mutex
+	extIP    net.IP
+	mappings []*mapping
+}
+
+type mapping struct {
+	status MappingStatus
+	remap  chan struct{}
+}
+
+// NewMapper creates a mapper on m.
+func NewMapper(m Interface) *Mapper {
+	return &Mapper{nat: m}
+}
+
+// Map adds a port mapping on m and keeps it alive until c is closed.
+// This function is typically invoked in its own goroutine.
+func (mapper *Mapper) Map(c chan struct{}, protocol string, extport, intport int, name string) {
+	log := NewLog().WithField("proto", protocol).WithField("extport", extport).WithField("intport", intport).WithField("interface", mapper.nat)
+	mp := &mapping{
+		status: MappingStatus{Protocol: protocol, ExtPort: extport, IntPort: intport, Name: name},
+		remap:  make(chan struct{}, 1),
+	}
+	mapper.lock.Lock()
+	mapper.mappings = append(mapper.mappings, mp)
+	mapper.lock.Unlock()
+
+	refresh := time.NewTimer(mapper.add(mp))
+	defer func() {
+		refresh.Stop()
+		log.Debug("Deleting port mapping")
+		mapper.nat.DeleteMapping(protocol, extport, intport)
+		mapper.lock.Lock()
+		for i, m := range mapper.mappings {
+			if m == mp {
+				mapper.mappings = append(mapper.mappings[:i], mapper.mappings[i+1:]...)
+				break
+			}
+		}
+		mapper.lock.Unlock()
+	}()
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		case <-mp.remap:
+			if !refresh.Stop() {
+				select {
+				case <-refresh.C:
+				default:
+				}
+			}
+			refresh.Reset(mapper.nextDelay(mp))
+		case <-refresh.C:
+			log.Trace("Refreshing port mapping")
+			refresh.Reset(mapper.add(mp))
+		}
+	}
+}
+
+// add (re)creates the mapping and returns the delay until the next attempt.
+func (mapper *Mapper) add(mp *mapping) time.Duration {
+	log := NewLog().WithField("proto", mp.status.Protocol).WithField("extport", mp.status.ExtPort).WithField("intport", mp.status.IntPort).WithField("interface", mapper.nat)
+	err := mapper.nat.AddMapping(mp.status.Protocol, mp.status.ExtPort, mp.status.IntPort, mp.status.Name, mapTimeout)
+	var ip net.IP
+	if err == nil {
+		ip, _ = mapper.nat.ExternalIP()
+	}
+
+	mapper.lock.Lock()
+	defer mapper.lock.Unlock()
+	now := time.Now()
+	if err != nil {
+		if mp.status.Failures == 0 {
+			log.WithField("err", err).Debug("Couldn't add port mapping")
+		}
+		mp.status.Failures++
+		mp.status.LastError = err.Error()
+		if now.After(mp.status.Expiry) {
+			mp.status.Mapped = false
+		}
+	} else {
+		if !mp.status.Mapped {
+			log.Info("Mapped network port")
+		}
+		mp.status.Mapped = true
+		mp.status.Failures = 0
+		mp.status.LastError = ""
+		mp.status.Expiry = now.Add(mapTimeout)
+		if ip != nil {
+			mapper.extIP = ip
+		}
+	}
+	delay := mapper.delayLocked(mp)
+	mp.status.NextRenew = now.Add(delay)
+	return delay
+}
+
+func (mapper *Mapper) nextDelay(mp *mapping) time.Duration {
+	mapper.lock.Lock()
+	defer mapper.lock.Unlock()
+	return time.Until(mp.status.NextRenew)
+}
+
+// delayLocked doubles the retry interval with every failure until it
+// reaches the regular refresh interval.
+func (mapper *Mapper) delayLocked(mp *mapping) time.Duration {
+	if mp.status.Failures == 0 {
+		return mapUpdateInterval
+	}
+	delay := minRetryInterval
+	for i := 1; i < mp.status.Failures && delay < maxRetryInterval; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryInterval {
+		delay = maxRetryInterval
+	}
+	return delay
+}
+
+// Remap refreshes all mappings and the external IP right away and returns
+// the resulting status.
+func (mapper *Mapper) Remap() *Status {
+	mapper.lock.Lock()
+	mappings := make([]*mapping, len(mapper.mappings))
+	copy(mappings, mapper.mappings)
+	mapper.lock.Unlock()
+
+	if ip, err := mapper.nat.ExternalIP(); err == nil {
+		mapper.lock.Lock()
+		mapper.extIP = ip
+		mapper.lock.Unlock()
+	}
+	for _, mp := range mappings {
+		mapper.add(mp)
+		// let the mapping loop pick up the new schedule
+		select {
+		case mp.remap <- struct{}{}:
+		default:
+		}
+	}
+	return mapper.Status()
+}
+
+// Status returns a snapshot of the mapper state.
+func (mapper *Mapper) Status() *Status {
+	mapper.lock.Lock()
+	defer mapper.lock.Unlock()
+	status := &Status{
+		Interface: mapper.nat.String(),
+		Mappings:  make([]MappingStatus, len(mapper.mappings)),
+	}
+	if mapper.extIP != nil {
+		status.ExternalIP = mapper.extIP.String()
+	}
+	for i, mp := range mapper.mappings {
+		status.Mappings[i] = mp.status
+	}
+	return status
+}
+
+// ExternalIP returns the last external address reported by the gateway.
+func (mapper *Mapper) ExternalIP() net.IP {
+	mapper.lock.Lock()
+	defer mapper.lock.Unlock()
+	return mapper.extIP
+}
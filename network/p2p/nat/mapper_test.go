@@ -0,0 +1,64 @@
+package nat
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// flakyNAT fails the first failures AddMapping calls.
+type flakyNAT struct {
+	failures int
+	calls    int
+}
+
+func (n *flakyNAT) AddMapping(string, int, int, string, time.Duration) error {
+	n.calls++
+	if n.calls <= n.failures {
+		return errors.New("lease refused")
+	}
+	return nil
+}
+func (n *flakyNAT) DeleteMapping(string, int, int) error { return nil }
+func (n *flakyNAT) ExternalIP() (net.IP, error)          { return net.IP{1, 2, 3, 4}, nil }
+func (n *flakyNAT) String() string                       { return "flaky" }
+
+func TestMapperBackoff(t *testing.T) {
+	mapper := NewMapper(&flakyNAT{failures: 3})
+	mp := &mapping{status: MappingStatus{Protocol: "tcp", ExtPort: 1, IntPort: 1}, remap: make(chan struct{}, 1)}
+	mapper.mappings = append(mapper.mappings, mp)
+
+	for i, want := range []time.Duration{minRetryInterval, 2 * minRetryInterval, 4 * minRetryInterval, mapUpdateInterval} {
+		if got := mapper.add(mp); got != want {
+			t.Errorf("attempt %d: delay %v, want %v", i, got, want)
+		}
+	}
+	status := mapper.Status()
+	if len(status.Mappings) != 1 || !status.Mappings[0].Mapped || status.Mappings[0].Failures != 0 {
+		t.Fatalf("unexpected mapping status %+v", status.Mappings)
+	}
+	if status.ExternalIP != "1.2.3.4" {
+		t.Errorf("external ip %q, want 1.2.3.4", status.ExternalIP)
+	}
+}
+
+func TestMapperRemap(t *testing.T) {
+	quit := make(chan struct{})
+	mapper := NewMapper(&flakyNAT{failures: 1})
+	go mapper.Map(quit, "udp", 2, 2, "test")
+	defer close(quit)
+
+	deadline := time.Now().Add(time.Second)
+	for ms := mapper.Status().Mappings; len(ms) == 0 || ms[0].Failures == 0; ms = mapper.Status().Mappings {
+		if time.Now().After(deadline) {
+			t.Fatal("first mapping attempt not done")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// the first attempt failed, remap must not wait for the retry
+	status := mapper.Remap()
+	if m := status.Mappings[0]; !m.Mapped || m.Failures != 0 {
+		t.Fatalf("mapping not refreshed: %+v", m)
+	}
+}
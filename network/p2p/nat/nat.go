@@ -95,34 +95,10 @@ const (
 )
 
 // Map adds a port mapping on m and keeps it alive until c is closed.
-// This function is typically invoked in its own goroutine.
+// This function is typically invoked in its own goroutine. Use a Mapper
+// to inspect or refresh the mapping.
 func Map(m Interface, c chan struct{}, protocol string, extport, intport int, name string) {
-	log := NewLog().WithField("proto", protocol).WithField("extport", extport).WithField("intport", intport).WithField("interface", m)
-	refresh := time.NewTimer(mapUpdateInterval)
-	defer func() {
-		refresh.Stop()
-		log.Debug("Deleting port mapping")
-		m.DeleteMapping(protocol, extport, intport)
-	}()
-	if err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
-		log.WithField("err", err).Debug("Couldn't add port mapping")
-	} else {
-		log.Info("Mapped network port")
-	}
-	for {
-		select {
-		case _, ok := <-c:
-			if !ok {
-				return
-			}
-		case <-refresh.C:
-			log.Trace("Refreshing port mapping")
-			if err := m.AddMapping(protocol, extport, intport, name, mapTimeout); err != nil {
-				log.WithField("err", err).Debug("Couldn't add port mapping")
-			}
-			refresh.Reset(mapUpdateInterval)
-		}
-	}
+	NewMapper(m).Map(c, protocol, extport, intport, name)
 }
 
 // ExtIP assumes that the local machine is reachable on the given
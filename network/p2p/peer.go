@@ -64,6 +64,7 @@ type protoHandshake struct {
 	Caps       []Cap
 	ListenPort uint64
 	ID         []byte // secp256k1 public key
+	ChainId    uint64
 
 	// Ignore additional fields (for forward compatibility).
 	Rest []enr.RawValue `rlp:"tail"`
@@ -115,6 +116,8 @@ type Peer struct {
 
 	// events receives message send / receive events if set
 	events *event.Feed
+
+	traffic *peerTraffic
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -194,6 +197,7 @@ func newPeer(conn *conn, protocols []Protocol) *Peer {
 		protoErr:     make(chan error, len(protomap)+1), // protocols + pingLoop
 		closed:       make(chan struct{}),
 		log:          xx.Logger,
+		traffic:      newPeerTraffic(),
 	}
 	return p
 }
@@ -319,6 +323,14 @@ func (p *Peer) handle(msg Msg) error {
 		if err != nil {
 			return fmt.Errorf("msg code out of range: %v", msg.Code)
 		}
+		markProtoTraffic(ingressMeterName, proto.Name, msg.Size)
+		p.traffic.ingress(msg.Size)
+		if allowed, err := p.limit(proto, msg); err != nil {
+			msg.Discard()
+			return err
+		} else if !allowed {
+			return msg.Discard()
+		}
 		select {
 		case proto.receiveMsgChan <- msg:
 			return nil
@@ -356,7 +368,7 @@ outer:
 					offset -= uint64(old.Length)
 				}
 				// Assign the new match
-				result[cap.Name] = &protoRW{Protocol: proto, offset: offset, receiveMsgChan: make(chan Msg), w: rw}
+				result[cap.Name] = &protoRW{Protocol: proto, offset: offset, receiveMsgChan: make(chan Msg), w: rw, limiters: newLimiters(proto)}
 				offset += uint64(proto.Length)
 
 				continue outer
@@ -371,6 +383,7 @@ func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error)
 	for _, proto := range p.runningProto {
 		proto := proto
 		proto.closedFromPeer = p.closed
+		proto.traffic = p.traffic
 		proto.wstart = writeStart
 		proto.werr = writeErr
 		var rw MsgReadWriter = proto
@@ -416,6 +429,8 @@ type protoRW struct {
 	werr           chan<- error    // for write results
 	offset         uint64
 	w              MsgWriter
+	limiters       map[uint64]*rateLimiter // rate limits of the message codes, see ratelimit.go
+	traffic        *peerTraffic
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
@@ -426,6 +441,12 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	select {
 	case <-rw.wstart:
 		err = rw.w.WriteMsg(msg)
+		if err == nil {
+			markProtoTraffic(egressMeterName, rw.Name, msg.Size)
+			if rw.traffic != nil {
+				rw.traffic.egress(msg.Size)
+			}
+		}
 		// Report write status back to Peer.run. It will initiate
 		// shutdown if the error is non-nil and unblock the next write
 		// otherwise. The calling protocol code should exit for errors
@@ -464,6 +485,7 @@ type PeerInfo struct {
 		Static        bool   `json:"static"`
 	} `json:"network"`
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
+	Traffic   *TrafficInfo           `json:"traffic"`   // Messages and bytes exchanged, messages throttled
 }
 
 // Info gathers and returns a collection of metadata known about a peer.
@@ -486,6 +508,7 @@ func (p *Peer) Info() *PeerInfo {
 	info.Network.Inbound = p.rw.is(inboundConn)
 	info.Network.Trusted = p.rw.is(trustedConn)
 	info.Network.Static = p.rw.is(staticDialedConn)
+	info.Traffic = p.traffic.info()
 
 	// Gather all the running protocol infos
 	for _, proto := range p.runningProto {
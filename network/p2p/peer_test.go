@@ -45,8 +45,8 @@ var discard = Protocol{
 
 func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
 	fd1, fd2 := net.Pipe()
-	c1 := &conn{fd: fd1, node: newNode(randomID(), nil), transport: newTestTransport(&newkey().PublicKey, fd1)}
-	c2 := &conn{fd: fd2, node: newNode(randomID(), nil), transport: newTestTransport(&newkey().PublicKey, fd2)}
+	c1 := &conn{fd: fd1, peerNode: newNode(randomID(), nil), transport: newTestTransport(newkey().PubKey(), fd1)}
+	c2 := &conn{fd: fd2, peerNode: newNode(randomID(), nil), transport: newTestTransport(newkey().PubKey(), fd2)}
 	for _, p := range protos {
 		c1.caps = append(c1.caps, p.cap())
 		c2.caps = append(c2.caps, p.cap())
@@ -55,7 +55,7 @@ func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
 	peer := newPeer(c1, protos)
 	errc := make(chan error, 1)
 	go func() {
-		_, err := peer.run()
+		_, err := peer.runProtocols()
 		errc <- err
 	}()
 
@@ -115,7 +115,8 @@ func TestPeerProtoEncodeMsg(t *testing.T) {
 	closer, rw, _, _ := testPeer([]Protocol{proto})
 	defer closer()
 
-	if err := ExpectMsg(rw, 17, []string{"foo", "bar"}); err != nil {
+	// SendItems encodes the items as a list of interfaces
+	if err := ExpectMsg(rw, 17, []interface{}{"foo", "bar"}); err != nil {
 		t.Error(err)
 	}
 }
@@ -134,7 +135,7 @@ func TestPeerPing(t *testing.T) {
 func TestPeerDisconnect(t *testing.T) {
 	closer, rw, _, disc := testPeer(nil)
 	defer closer()
-	if err := SendItems(rw, discMsg, DiscQuitting); err != nil {
+	if err := Send(rw, discMsg, [1]DiscReason{DiscQuitting}); err != nil {
 		t.Fatal(err)
 	}
 	select {
@@ -183,7 +184,7 @@ func TestPeerDisconnectRace(t *testing.T) {
 		}
 		// In some cases, simulate remote requesting a disconnect.
 		if maybe() {
-			go SendItems(rw, discMsg, DiscQuitting)
+			go Send(rw, discMsg, [1]DiscReason{DiscQuitting})
 		}
 
 		select {
@@ -0,0 +1,330 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+// The peer exchange protocol lets connected peers share the endpoints of the
+// nodes they are connected to, so a mesh forms without discovery, e.g. when
+// UDP is blocked. Every endpoint is signed by the key of the node it belongs
+// to, a peer can relay an endpoint but never forge one.
+const (
+	pexProtocolName    = "pex"
+	pexProtocolVersion = 1
+	pexMsg             = 0
+
+	pexInterval   = 30 * time.Second // interval between two samples sent to a peer
+	pexSampleSize = 16               // max entries in a sample, besides the own entry of the sender
+	pexEntryTTL   = time.Hour        // entries signed longer ago are dropped
+	pexClockSlack = time.Minute      // entries signed in the future by up to this are accepted
+	pexMaxGood    = 256              // max verified entries kept for relaying
+	pexMaxLearned = 512              // max entries kept as dial candidates
+	pexMaxMsgSize = 16 * 1024
+)
+
+var (
+	errPexChainId = errors.New("pex entry of another chain")
+	errPexExpired = errors.New("pex entry expired")
+	errPexAddr    = errors.New("pex entry has no usable endpoint")
+	errPexSig     = errors.New("pex entry signature malformed")
+)
+
+// pexEntry is the endpoint of a node signed by the node key. The node id is
+// recovered from the signature.
+type pexEntry struct {
+	IP      []byte
+	TCP     uint16
+	UDP     uint16
+	ChainId uint64
+	Time    uint64 // unix seconds when the entry was signed
+	Sig     []byte
+}
+
+type pexPacket struct {
+	Entries []*pexEntry
+}
+
+func (entry *pexEntry) sigHash() []byte {
+	buf := make([]byte, 20)
+	binary.BigEndian.PutUint16(buf[0:], entry.TCP)
+	binary.BigEndian.PutUint16(buf[2:], entry.UDP)
+	binary.BigEndian.PutUint64(buf[4:], entry.ChainId)
+	binary.BigEndian.PutUint64(buf[12:], entry.Time)
+	return sha3.Keccak256([]byte(pexProtocolName), entry.IP, buf)
+}
+
+// signPexEntry signs the endpoint of the local node
+func signPexEntry(prv *secp256k1.PrivateKey, n *enode.Node, chainId uint64, now time.Time) (*pexEntry, error) {
+	entry := &pexEntry{
+		IP:      n.IP(),
+		TCP:     uint16(n.TCP()),
+		UDP:     uint16(n.UDP()),
+		ChainId: chainId,
+		Time:    uint64(now.Unix()),
+	}
+	if _, err := entry.endpoint(); err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(entry.sigHash(), prv)
+	if err != nil {
+		return nil, err
+	}
+	entry.Sig = sig
+	return entry, nil
+}
+
+// endpoint checks the address in the entry can be dialed
+func (entry *pexEntry) endpoint() (net.IP, error) {
+	ip := net.IP(entry.IP)
+	if (len(ip) != net.IPv4len && len(ip) != net.IPv6len) || entry.TCP == 0 {
+		return nil, errPexAddr
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsMulticast() {
+		return nil, errPexAddr
+	}
+	return ip, nil
+}
+
+// node verifies the entry and returns the node it was signed by
+func (entry *pexEntry) node(chainId uint64, now time.Time) (*enode.Node, error) {
+	if entry.ChainId != chainId {
+		return nil, errPexChainId
+	}
+	signed := time.Unix(int64(entry.Time), 0)
+	if now.Sub(signed) > pexEntryTTL || signed.Sub(now) > pexClockSlack {
+		return nil, errPexExpired
+	}
+	ip, err := entry.endpoint()
+	if err != nil {
+		return nil, err
+	}
+	if len(entry.Sig) != 65 {
+		return nil, errPexSig
+	}
+	pubkey, err := crypto.SigToPub(entry.sigHash(), entry.Sig)
+	if err != nil {
+		return nil, err
+	}
+	return enode.NewV4(pubkey, ip, int(entry.TCP), int(entry.UDP)), nil
+}
+
+// peerExchange keeps the entries sent by connected peers about themselves,
+// which are relayed to other peers, and the entries learned from samples,
+// which are read by the dialer as candidates.
+type peerExchange struct {
+	srv *Server
+
+	mu      sync.Mutex
+	own     *pexEntry
+	good    map[enode.ID]*pexEntry
+	learned map[enode.ID]*pexLearned
+}
+
+type pexLearned struct {
+	node  *enode.Node
+	until time.Time
+}
+
+func newPeerExchange(srv *Server) *peerExchange {
+	return &peerExchange{
+		srv:     srv,
+		good:    make(map[enode.ID]*pexEntry),
+		learned: make(map[enode.ID]*pexLearned),
+	}
+}
+
+func (pex *peerExchange) protocol() Protocol {
+	return Protocol{
+		Name:     pexProtocolName,
+		Version:  pexProtocolVersion,
+		Length:   1,
+		Run:      pex.run,
+		NodeInfo: pex.nodeInfo,
+	}
+}
+
+func (pex *peerExchange) nodeInfo() interface{} {
+	pex.mu.Lock()
+	defer pex.mu.Unlock()
+	return map[string]int{"relayed": len(pex.good), "learned": len(pex.learned)}
+}
+
+func (pex *peerExchange) run(p *Peer, rw MsgReadWriter) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				if err := Send(rw, pexMsg, &pexPacket{Entries: pex.sample(p.ID())}); err != nil {
+					return
+				}
+				// spread the samples of peers connected at once
+				timer.Reset(pexInterval/2 + time.Duration(rand.Int63n(int64(pexInterval))))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var last time.Time
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Code != pexMsg || msg.Size > pexMaxMsgSize {
+			msg.Discard()
+			return newPeerError(errInvalidMsg, "pex message code %d size %d", msg.Code, msg.Size)
+		}
+		// samples arriving faster than they are sent are ignored
+		now := time.Now()
+		if now.Sub(last) < pexInterval/4 {
+			msg.Discard()
+			continue
+		}
+		last = now
+
+		packet := &pexPacket{}
+		if err := msg.Decode(packet); err != nil {
+			return err
+		}
+		if len(packet.Entries) > pexSampleSize+1 {
+			return newPeerError(errInvalidMsg, "pex sample of %d entries", len(packet.Entries))
+		}
+		pex.receive(p, packet.Entries, now)
+	}
+}
+
+// receive verifies the entries of a sample. The entry of the sending peer is
+// kept for relaying if it matches the address the peer is connected from.
+func (pex *peerExchange) receive(p *Peer, entries []*pexEntry, now time.Time) {
+	self := pex.srv.localnode.ID()
+	pex.mu.Lock()
+	defer pex.mu.Unlock()
+
+	for _, entry := range entries {
+		n, err := entry.node(pex.srv.ChainId, now)
+		if err != nil {
+			log.WithField("peer", p.ID()).WithField("err", err).Trace("Dropping pex entry")
+			continue
+		}
+		switch n.ID() {
+		case self:
+			// our own entry relayed back
+		case p.ID():
+			// the endpoint of a producer behind this sentry is never relayed
+			if pex.srv.IsPrivateNode(n.ID()) {
+				break
+			}
+			if addr, ok := p.RemoteAddr().(*net.TCPAddr); ok && addr.IP.Equal(n.IP()) {
+				pex.addGood(n.ID(), entry)
+			}
+		default:
+			pex.addLearned(n, time.Unix(int64(entry.Time), 0).Add(pexEntryTTL))
+		}
+	}
+}
+
+func (pex *peerExchange) addGood(id enode.ID, entry *pexEntry) {
+	if _, ok := pex.good[id]; !ok && len(pex.good) >= pexMaxGood {
+		for old := range pex.good {
+			delete(pex.good, old)
+			break
+		}
+	}
+	pex.good[id] = entry
+}
+
+func (pex *peerExchange) addLearned(n *enode.Node, until time.Time) {
+	if old, ok := pex.learned[n.ID()]; ok {
+		if old.until.After(until) {
+			return
+		}
+	} else if len(pex.learned) >= pexMaxLearned {
+		for old := range pex.learned {
+			delete(pex.learned, old)
+			break
+		}
+	}
+	pex.learned[n.ID()] = &pexLearned{node: n, until: until}
+}
+
+// sample returns the own entry and a random sample of the relayed entries,
+// leaving out the entry of the receiving peer
+func (pex *peerExchange) sample(to enode.ID) []*pexEntry {
+	now := time.Now()
+	pex.mu.Lock()
+	defer pex.mu.Unlock()
+
+	entries := make([]*pexEntry, 0, pexSampleSize+1)
+	if own := pex.ownEntry(now); own != nil {
+		entries = append(entries, own)
+	}
+	for id, entry := range pex.good {
+		if now.Sub(time.Unix(int64(entry.Time), 0)) > pexEntryTTL {
+			delete(pex.good, id)
+			continue
+		}
+		if id == to {
+			continue
+		}
+		if len(entries) < cap(entries) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ownEntry signs the endpoint of the local node again once half of the entry
+// lifetime passed or the endpoint changed. Nodes without a known public
+// endpoint send no entry.
+func (pex *peerExchange) ownEntry(now time.Time) *pexEntry {
+	n := pex.srv.localnode.Node()
+	own := pex.own
+	if own != nil && now.Sub(time.Unix(int64(own.Time), 0)) < pexEntryTTL/2 &&
+		net.IP(own.IP).Equal(n.IP()) && int(own.TCP) == n.TCP() && int(own.UDP) == n.UDP() {
+		return own
+	}
+	own, err := signPexEntry(pex.srv.PrivateKey, n, pex.srv.ChainId, now)
+	if err != nil {
+		pex.own = nil
+		return nil
+	}
+	pex.own = own
+	return own
+}
+
+// ReadRandomNodes fills buf with learned nodes, it is the node source of the
+// dialer.
+func (pex *peerExchange) ReadRandomNodes(buf []*enode.Node) int {
+	now := time.Now()
+	pex.mu.Lock()
+	defer pex.mu.Unlock()
+
+	n := 0
+	for id, learned := range pex.learned {
+		if now.After(learned.until) {
+			delete(pex.learned, id)
+			continue
+		}
+		if n < len(buf) {
+			buf[n] = learned.node
+			n++
+		}
+	}
+	rand.Shuffle(n, func(i, j int) { buf[i], buf[j] = buf[j], buf[i] })
+	return n
+}
@@ -0,0 +1,76 @@
+package p2p
+
+import (
+	crand "crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+func TestPexEntry(t *testing.T) {
+	key, _ := crypto.GenerateKey(crand.Reader)
+	now := time.Now()
+	local := enode.NewV4(key.PubKey(), net.IP{10, 0, 1, 7}, 55555, 55555)
+
+	entry, err := signPexEntry(key, local, 1, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := entry.node(1, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.ID() != local.ID() || !n.IP().Equal(local.IP()) || n.TCP() != 55555 {
+		t.Fatalf("entry verified to %v, expected %v", n, local)
+	}
+
+	if _, err := entry.node(2, now); err != errPexChainId {
+		t.Fatalf("entry of chain 1 accepted on chain 2, %v", err)
+	}
+	if _, err := entry.node(1, now.Add(pexEntryTTL+time.Second)); err != errPexExpired {
+		t.Fatalf("expired entry accepted, %v", err)
+	}
+
+	// a relayed entry with another address no longer belongs to the node
+	forged := *entry
+	forged.IP = net.IP{10, 0, 1, 8}
+	if n, err := forged.node(1, now); err == nil && n.ID() == local.ID() {
+		t.Fatal("forged entry verified to the signing node")
+	}
+
+	// nodes without a public endpoint send no entry
+	loopback := enode.NewV4(key.PubKey(), net.IP{127, 0, 0, 1}, 55555, 55555)
+	if _, err := signPexEntry(key, loopback, 1, now); err != errPexAddr {
+		t.Fatalf("loopback entry signed, %v", err)
+	}
+}
+
+func TestPexLearned(t *testing.T) {
+	pex := newPeerExchange(&Server{})
+	now := time.Now()
+	for i := 0; i < pexMaxLearned+10; i++ {
+		key, _ := crypto.GenerateKey(crand.Reader)
+		until := now.Add(time.Hour)
+		if i%2 == 0 {
+			until = now.Add(-time.Second)
+		}
+		pex.addLearned(enode.NewV4(key.PubKey(), net.IP{10, 0, byte(i >> 8), byte(i)}, 55555, 0), until)
+	}
+	if len(pex.learned) != pexMaxLearned {
+		t.Fatalf("%d nodes learned, expected %d", len(pex.learned), pexMaxLearned)
+	}
+
+	buf := make([]*enode.Node, pexMaxLearned)
+	n := pex.ReadRandomNodes(buf)
+	if n == 0 || n != len(pex.learned) {
+		t.Fatalf("%d nodes read, %d kept", n, len(pex.learned))
+	}
+	for _, learned := range pex.learned {
+		if now.After(learned.until) {
+			t.Fatal("expired node kept")
+		}
+	}
+}
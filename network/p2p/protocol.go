@@ -56,6 +56,10 @@ type Protocol struct {
 
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
+
+	// RateLimits limits the rate of message codes a peer may send, codes
+	// without a limit are not limited. See RateLimit.
+	RateLimits map[uint64]RateLimit
 }
 
 func (p Protocol) cap() Cap {
@@ -0,0 +1,138 @@
+package p2p
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit is the rate a peer may send one message code of a sub protocol at. A message
+// beyond it is dropped, a peer dropping Burst messages in a row is disconnected
+type RateLimit struct {
+	Rate  float64 // messages per second
+	Burst int     // messages accepted at once after an idle period
+}
+
+// rateLimiter is a token bucket of one message code of one peer, it is only used by the
+// read loop of the peer
+type rateLimiter struct {
+	limit   RateLimit
+	tokens  float64
+	last    time.Time
+	refused int // messages dropped in a row
+}
+
+func newRateLimiter(limit RateLimit, now time.Time) *rateLimiter {
+	return &rateLimiter{limit: limit, tokens: float64(limit.Burst), last: now}
+}
+
+// allow take a token for a message received at now, exceeded is set once the messages
+// dropped in a row reach the burst
+func (limiter *rateLimiter) allow(now time.Time) (allowed bool, exceeded bool) {
+	if elapsed := now.Sub(limiter.last).Seconds(); elapsed > 0 {
+		limiter.tokens += elapsed * limiter.limit.Rate
+		if limiter.tokens > float64(limiter.limit.Burst) {
+			limiter.tokens = float64(limiter.limit.Burst)
+		}
+	}
+	limiter.last = now
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		limiter.refused = 0
+		return true, false
+	}
+	limiter.refused++
+	return false, limiter.refused >= limiter.limit.Burst
+}
+
+// TrafficInfo is the traffic of a peer since it connected
+type TrafficInfo struct {
+	IngressPackets uint64            `json:"ingressPackets"`
+	IngressBytes   uint64            `json:"ingressBytes"`
+	EgressPackets  uint64            `json:"egressPackets"`
+	EgressBytes    uint64            `json:"egressBytes"`
+	Throttled      map[string]uint64 `json:"throttled"` // messages dropped by rate limit, by protocol/code
+}
+
+// peerTraffic count the traffic of a peer, it is updated by the read loop and the protocol
+// writers and read by Info
+type peerTraffic struct {
+	ingressPackets uint64
+	ingressBytes   uint64
+	egressPackets  uint64
+	egressBytes    uint64
+
+	lock      sync.Mutex
+	throttled map[string]uint64
+}
+
+func newPeerTraffic() *peerTraffic {
+	return &peerTraffic{throttled: make(map[string]uint64)}
+}
+
+func (traffic *peerTraffic) ingress(size uint32) {
+	atomic.AddUint64(&traffic.ingressPackets, 1)
+	atomic.AddUint64(&traffic.ingressBytes, uint64(size))
+}
+
+func (traffic *peerTraffic) egress(size uint32) {
+	atomic.AddUint64(&traffic.egressPackets, 1)
+	atomic.AddUint64(&traffic.egressBytes, uint64(size))
+}
+
+func (traffic *peerTraffic) throttle(proto string, code uint64) {
+	traffic.lock.Lock()
+	traffic.throttled[proto+"/"+strconv.FormatUint(code, 10)]++
+	traffic.lock.Unlock()
+	throttledMeter.Mark(1)
+}
+
+func (traffic *peerTraffic) info() *TrafficInfo {
+	info := &TrafficInfo{
+		IngressPackets: atomic.LoadUint64(&traffic.ingressPackets),
+		IngressBytes:   atomic.LoadUint64(&traffic.ingressBytes),
+		EgressPackets:  atomic.LoadUint64(&traffic.egressPackets),
+		EgressBytes:    atomic.LoadUint64(&traffic.egressBytes),
+		Throttled:      make(map[string]uint64),
+	}
+	traffic.lock.Lock()
+	for key, count := range traffic.throttled {
+		info.Throttled[key] = count
+	}
+	traffic.lock.Unlock()
+	return info
+}
+
+// limit check the rate limit of a message of the sub protocol, the message is dropped when
+// it is not allowed and DiscRateLimited returned when the peer keeps exceeding the limit.
+// Trusted peers are not limited
+func (p *Peer) limit(proto *protoRW, msg Msg) (bool, error) {
+	if p.rw.is(trustedConn) {
+		return true, nil
+	}
+	code := msg.Code - proto.offset
+	limiter, ok := proto.limiters[code]
+	if !ok {
+		return true, nil
+	}
+	allowed, exceeded := limiter.allow(msg.ReceivedAt)
+	if allowed {
+		return true, nil
+	}
+	p.traffic.throttle(proto.Name, code)
+	if exceeded {
+		rateLimitDropMeter.Mark(1)
+		return false, DiscRateLimited
+	}
+	return false, nil
+}
+
+func newLimiters(proto Protocol) map[uint64]*rateLimiter {
+	limiters := make(map[uint64]*rateLimiter, len(proto.RateLimits))
+	now := time.Now()
+	for code, limit := range proto.RateLimits {
+		limiters[code] = newRateLimiter(limit, now)
+	}
+	return limiters
+}
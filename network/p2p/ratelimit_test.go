@@ -0,0 +1,44 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(RateLimit{Rate: 2, Burst: 3}, now)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.allow(now); !allowed {
+			t.Fatalf("message %d of the burst refused", i)
+		}
+	}
+	if allowed, exceeded := limiter.allow(now); allowed || exceeded {
+		t.Fatal("message beyond the burst not refused, or refused as exceeded too early")
+	}
+
+	// half a second refills one token at 2 messages per second
+	now = now.Add(500 * time.Millisecond)
+	if allowed, _ := limiter.allow(now); !allowed {
+		t.Fatal("refilled token refused")
+	}
+	if allowed, _ := limiter.allow(now); allowed {
+		t.Fatal("message allowed without token")
+	}
+
+	// a peer dropping burst messages in a row exceeds the limit
+	limiter.allow(now)
+	if _, exceeded := limiter.allow(now); !exceeded {
+		t.Fatal("limit not exceeded after burst messages refused in a row")
+	}
+
+	// the bucket never holds more than the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		limiter.allow(now)
+	}
+	if allowed, _ := limiter.allow(now); allowed {
+		t.Fatal("bucket refilled beyond the burst")
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"hash"
 	"io"
 	"io/ioutil"
+	mrand "math/rand"
 	"net"
 	"sync"
 	"time"
@@ -114,8 +115,9 @@ func (t *rlpx) close(err error) {
 			// it hangs forever, since net.Pipe does not implement
 			// a write deadline. Because of this only try to send
 			// the disconnect reason message if there is no error.
+			// the reason is sent as the one element array the readers decode
 			if err := t.fd.SetWriteDeadline(time.Now().Add(discWriteTimeout)); err == nil {
-				SendItems(t.rw, discMsg, r)
+				Send(t.rw, discMsg, [1]DiscReason{r})
 			}
 		}
 	}
@@ -227,7 +229,7 @@ type secrets struct {
 
 // RLPx v4 handshake auth (defined in EIP-8).
 type authMsgV4 struct {
-	gotPlain bool // whether read packet had plain format.
+	gotPlain bool `binary:"ignore"` // whether read packet had plain format.
 
 	Signature       [sigLen]byte
 	InitiatorPubkey [pubLen]byte
@@ -492,20 +494,19 @@ func (msg *authRespV4) decodePlain(input []byte) {
 var padSpace = make([]byte, 300)
 
 func sealEIP8(msg interface{}, h *encHandshake) ([]byte, error) {
-	//buf := new(bytes.Buffer)
-	//if err := .Encode(buf, msg); err != nil {
-	//	return nil, err
-	//}
-	//// pad with random amount of data. the amount needs to be at least 100 bytes to make
-	//// the message distinguishable from pre-EIP-8 handshakes.
-	//pad := padSpace[:mrand.Intn(len(padSpace)-100)+100]
-	//buf.Write(pad)
-	//prefix := make([]byte, 2)
-	//binary.BigEndian.PutUint16(prefix, uint16(buf.Len()+eciesOverhead))
-	//
-	//enc, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(h.remote), buf.Bytes(), nil, prefix)
-	//return append(prefix, enc...), err
-	return nil, nil
+	buf, err := binary.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	// pad with random amount of data. the amount needs to be at least 100 bytes to make
+	// the message distinguishable from pre-EIP-8 handshakes.
+	pad := padSpace[:mrand.Intn(len(padSpace)-100)+100]
+	buf = append(buf, pad...)
+	prefix := make([]byte, 2)
+	oldBinary.BigEndian.PutUint16(prefix, uint16(len(buf)+eciesOverhead))
+
+	enc, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(h.remote), buf, nil, prefix)
+	return append(prefix, enc...), err
 }
 
 type plainDecoder interface {
@@ -525,11 +526,9 @@ func readHandshakeMsg(msg plainDecoder, plainSize int, prv *secp256k1.PrivateKey
 		return buf, nil
 	}
 
-	_, err := key.Decrypt(buf, nil, nil)
-	fmt.Println("readHandshakeMsg err:",err)
 	// Could be EIP-8 format, try that.
 	prefix := buf[:2]
-	size := binary.BigEndian.Uint16(prefix)
+	size := oldBinary.BigEndian.Uint16(prefix)
 	if size < uint16(plainSize) {
 		return buf, fmt.Errorf("size underflow, need at least %d bytes", plainSize)
 	}
@@ -537,7 +536,12 @@ func readHandshakeMsg(msg plainDecoder, plainSize int, prv *secp256k1.PrivateKey
 	if _, err := io.ReadFull(r, buf[plainSize:]); err != nil {
 		return buf, err
 	}
-	return buf, nil
+	dec, err := key.Decrypt(buf[2:], nil, prefix)
+	if err != nil {
+		return buf, err
+	}
+	// the decoder stops at the end of the message, the padding is ignored
+	return buf, binary.Unmarshal(dec, msg)
 }
 
 // importPublicKey unmarshals 512 bit public keys.
@@ -583,6 +587,42 @@ var (
 	zero16 = make([]byte, 16)
 )
 
+// frameBufferPool holds scratch buffers used to assemble outgoing frames and
+// to stage compressed payloads. Pooling them instead of keeping one per
+// connection means idle peers do not pin large block-sized buffers.
+var frameBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 4096)
+		return &buf
+	},
+}
+
+func getFrameBuffer(size int) *[]byte {
+	bufp := frameBufferPool.Get().(*[]byte)
+	*bufp = growslice(*bufp, size)
+	return bufp
+}
+
+func putFrameBuffer(bufp *[]byte) {
+	frameBufferPool.Put(bufp)
+}
+
+// growslice returns b with length n, reusing its backing array if it is
+// large enough.
+func growslice(b []byte, n int) []byte {
+	if cap(b) >= n {
+		return b[:n]
+	}
+	return make([]byte, n)
+}
+
+// macScratch is the per-direction working space of updateMACInto.
+type macScratch struct {
+	aesbuf [aes.BlockSize]byte
+	sum    [32]byte
+	seed   [32]byte // frame MAC seed, kept apart from sum which it seeds
+}
+
 // rlpxFrameRW implements a simplified version of RLPx framing.
 // chunked messages are not supported and all headers are equal to
 // zeroHeader.
@@ -598,6 +638,11 @@ type rlpxFrameRW struct {
 	ingressMAC hash.Hash
 
 	snappy bool
+
+	// WriteMsg and ReadMsg run concurrently under separate locks, so each
+	// direction has its own fixed-size scratch space.
+	wmac, rmac macScratch
+	rhead      [32]byte
 }
 
 func newRLPXFrameRW(conn io.ReadWriter, s secrets) *rlpxFrameRW {
@@ -623,67 +668,83 @@ func newRLPXFrameRW(conn io.ReadWriter, s secrets) *rlpxFrameRW {
 }
 
 func (rw *rlpxFrameRW) WriteMsg(msg Msg) error {
-	ptype, _ := binary.Marshal(msg.Code)
+	// the message code is a uvarint, matching binary.Marshal(uint64)
+	var ptype [oldBinary.MaxVarintLen64]byte
+	ptypeLen := oldBinary.PutUvarint(ptype[:], msg.Code)
 
 	// if snappy is enabled, compress message now
+	var payload []byte
 	if rw.snappy {
 		if msg.Size > maxUint24 {
 			return errPlainMessageTooLarge
 		}
-		payload, _ := ioutil.ReadAll(msg.Payload)
-		payload = snappy.Encode(nil, payload)
-
-		msg.Payload = bytes.NewReader(payload)
+		plainp := getFrameBuffer(int(msg.Size))
+		defer putFrameBuffer(plainp)
+		if _, err := io.ReadFull(msg.Payload, *plainp); err != nil {
+			return err
+		}
+		compp := getFrameBuffer(snappy.MaxEncodedLen(len(*plainp)))
+		defer putFrameBuffer(compp)
+		payload = snappy.Encode(*compp, *plainp)
 		msg.Size = uint32(len(payload))
+		snappyEgressPlainMeter.Mark(int64(len(*plainp)))
+		snappyEgressFrameMeter.Mark(int64(len(payload)))
 	}
-	// write header
-	headbuf := make([]byte, 32)
-	fsize := uint32(len(ptype)) + msg.Size
+	fsize := uint32(ptypeLen) + msg.Size
 	if fsize > maxUint24 {
 		return errors.New("message size overflows uint24")
 	}
+	rsize := fsize // frame size rounded up to 16 byte boundary
+	if padding := fsize % 16; padding > 0 {
+		rsize += 16 - padding
+	}
+
+	// the whole frame is assembled in one buffer laid out as
+	// header (32) | frame content (rsize) | frame MAC (16)
+	// and written to conn in a single call.
+	bufp := getFrameBuffer(32 + int(rsize) + 16)
+	defer putFrameBuffer(bufp)
+	buf := *bufp
+	headbuf, framebuf, fmac := buf[:32], buf[32:32+rsize], buf[32+rsize:]
+
+	// write header, the pooled buffer holds the bytes of an earlier frame
+	copy(headbuf, zero16)
 	putInt24(fsize, headbuf) // TODO: check overflow
 	copy(headbuf[3:], zeroHeader)
 	rw.enc.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now encrypted
 
 	// write header MAC
-	copy(headbuf[16:], updateMAC(rw.egressMAC, rw.macCipher, headbuf[:16]))
-	if _, err := rw.conn.Write(headbuf); err != nil {
-		return err
-	}
+	copy(headbuf[16:], updateMACInto(&rw.wmac, rw.egressMAC, rw.macCipher, headbuf[:16]))
 
 	// write encrypted frame, updating the egress MAC hash with
-	// the data written to conn.
-	tee := cipher.StreamWriter{S: rw.enc, W: io.MultiWriter(rw.conn, rw.egressMAC)}
-	if _, err := tee.Write(ptype); err != nil {
-		return err
-	}
-	if _, err := io.Copy(tee, msg.Payload); err != nil {
+	// the encrypted frame content.
+	n := copy(framebuf, ptype[:ptypeLen])
+	if rw.snappy {
+		n += copy(framebuf[n:], payload)
+	} else if _, err := io.ReadFull(msg.Payload, framebuf[n:fsize]); err != nil {
 		return err
 	}
-	if padding := fsize % 16; padding > 0 {
-		if _, err := tee.Write(zero16[:16-padding]); err != nil {
-			return err
-		}
-	}
+	copy(framebuf[fsize:], zero16[:rsize-fsize])
+	rw.enc.XORKeyStream(framebuf, framebuf)
+	rw.egressMAC.Write(framebuf)
 
 	// write frame MAC. egress MAC hash is up to date because
 	// frame content was written to it as well.
-	fmacseed := rw.egressMAC.Sum(nil)
-	mac := updateMAC(rw.egressMAC, rw.macCipher, fmacseed)
-	_, err := rw.conn.Write(mac)
+	fmacseed := rw.egressMAC.Sum(rw.wmac.seed[:0])
+	copy(fmac, updateMACInto(&rw.wmac, rw.egressMAC, rw.macCipher, fmacseed))
+	_, err := rw.conn.Write(buf)
 	return err
 }
 
 func (rw *rlpxFrameRW) ReadMsg() (msg Msg, err error) {
 	// read the header
-	headbuf := make([]byte, 32)
+	headbuf := rw.rhead[:]
 	if _, err := io.ReadFull(rw.conn, headbuf); err != nil {
 		return msg, err
 	}
 	// verify header mac
 
-	shouldMAC := updateMAC(rw.ingressMAC, rw.macCipher, headbuf[:16])
+	shouldMAC := updateMACInto(&rw.rmac, rw.ingressMAC, rw.macCipher, headbuf[:16])
 	if !hmac.Equal(shouldMAC, headbuf[16:]) {
 		return msg, errors.New("bad header MAC")
 	}
@@ -696,69 +757,82 @@ func (rw *rlpxFrameRW) ReadMsg() (msg Msg, err error) {
 	if padding := fsize % 16; padding > 0 {
 		rsize += 16 - padding
 	}
-	framebuf := make([]byte, rsize)
+	// Payloads are handed to protocol handlers asynchronously, so an
+	// uncompressed frame must live in its own buffer. A compressed frame
+	// is decoded into a fresh buffer below and can use pooled memory.
+	var framebuf []byte
+	if rw.snappy {
+		bufp := getFrameBuffer(int(rsize))
+		defer putFrameBuffer(bufp)
+		framebuf = *bufp
+	} else {
+		framebuf = make([]byte, rsize)
+	}
 	if _, err := io.ReadFull(rw.conn, framebuf); err != nil {
 		return msg, err
 	}
 
 	// read and validate frame MAC. we can re-use headbuf for that.
 	rw.ingressMAC.Write(framebuf)
-	fmacseed := rw.ingressMAC.Sum(nil)
+	fmacseed := rw.ingressMAC.Sum(rw.rmac.seed[:0])
 	if _, err := io.ReadFull(rw.conn, headbuf[:16]); err != nil {
 		return msg, err
 	}
-	shouldMAC = updateMAC(rw.ingressMAC, rw.macCipher, fmacseed)
+	shouldMAC = updateMACInto(&rw.rmac, rw.ingressMAC, rw.macCipher, fmacseed)
 	if !hmac.Equal(shouldMAC, headbuf[:16]) {
 		return msg, errors.New("bad frame MAC")
 	}
 
 	// decrypt frame content
 	rw.dec.XORKeyStream(framebuf, framebuf)
-	content := bytes.NewReader(framebuf[:fsize])
+	content := framebuf[:fsize]
 	//if err := rlp.Decode(content, &msg.Code); err != nil {
 	//	return msg, err
 	//}
 
-	msg.Code, err = oldBinary.ReadUvarint(content)
-	if err != nil {
-		return msg, err
+	code, n := oldBinary.Uvarint(content)
+	if n <= 0 {
+		return msg, errors.New("invalid message code")
 	}
-
-	msg.Size = uint32(content.Len())
-	msg.Payload = content
+	msg.Code, content = code, content[n:]
 
 	// if snappy is enabled, verify and decompress message
 	if rw.snappy {
-		payload, err := ioutil.ReadAll(msg.Payload)
-		if err != nil {
-			return msg, err
-		}
-		size, err := snappy.DecodedLen(payload)
+		size, err := snappy.DecodedLen(content)
 		if err != nil {
 			return msg, err
 		}
 		if size > int(maxUint24) {
 			return msg, errPlainMessageTooLarge
 		}
-		payload, err = snappy.Decode(nil, payload)
+		compressed := len(content)
+		content, err = snappy.Decode(make([]byte, size), content)
 		if err != nil {
 			return msg, err
 		}
-		msg.Size, msg.Payload = uint32(size), bytes.NewReader(payload)
+		snappyIngressPlainMeter.Mark(int64(len(content)))
+		snappyIngressFrameMeter.Mark(int64(compressed))
 	}
+	msg.Size, msg.Payload = uint32(len(content)), bytes.NewReader(content)
 	return msg, nil
 }
 
 // updateMAC reseeds the given hash with encrypted seed.
 // it returns the first 16 bytes of the hash sum after seeding.
 func updateMAC(mac hash.Hash, block cipher.Block, seed []byte) []byte {
-	aesbuf := make([]byte, aes.BlockSize)
-	block.Encrypt(aesbuf, mac.Sum(nil))
+	return updateMACInto(new(macScratch), mac, block, seed)
+}
+
+// updateMACInto is updateMAC using the caller's scratch space. The
+// returned slice aliases scratch and is valid until its next use.
+func updateMACInto(scratch *macScratch, mac hash.Hash, block cipher.Block, seed []byte) []byte {
+	aesbuf := scratch.aesbuf[:]
+	block.Encrypt(aesbuf, mac.Sum(scratch.sum[:0]))
 	for i := range aesbuf {
 		aesbuf[i] ^= seed[i]
 	}
 	mac.Write(aesbuf)
-	return mac.Sum(nil)[:16]
+	return mac.Sum(scratch.sum[:0])[:16]
 }
 
 func readInt24(b []byte) uint32 {
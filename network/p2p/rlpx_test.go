@@ -18,8 +18,8 @@ package p2p
 
 import (
 	"bytes"
-	"crypto/ecdsa"
 	"crypto/rand"
+	oldBinary "encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -33,16 +33,19 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/ecies"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	"github.com/drep-project/DREP-Chain/network/p2p/simulations/pipes"
-	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/drep-project/binary"
 	"golang.org/x/crypto/sha3"
 )
 
 func TestSharedSecret(t *testing.T) {
-	prv0, _ := crypto.GenerateKey() // = ecdsa.GenerateKey(crypto.S256(), rand.Reader)
-	pub0 := &prv0.PublicKey
-	prv1, _ := crypto.GenerateKey()
-	pub1 := &prv1.PublicKey
+	prv0, _ := crypto.GenerateKey(rand.Reader)
+	pub0 := prv0.PubKey()
+	prv1, _ := crypto.GenerateKey(rand.Reader)
+	pub1 := prv1.PubKey()
 
 	ss0, err := ecies.ImportECDSA(prv0).GenerateShared(ecies.ImportECDSAPublic(pub1), sskLen, sskLen)
 	if err != nil {
@@ -80,12 +83,12 @@ func TestEncHandshake(t *testing.T) {
 func testEncHandshake(token []byte) error {
 	type result struct {
 		side   string
-		pubkey *ecdsa.PublicKey
+		pubkey *secp256k1.PublicKey
 		err    error
 	}
 	var (
-		prv0, _  = crypto.GenerateKey()
-		prv1, _  = crypto.GenerateKey()
+		prv0, _  = crypto.GenerateKey(rand.Reader)
+		prv1, _  = crypto.GenerateKey(rand.Reader)
 		fd0, fd1 = net.Pipe()
 		c0, c1   = newRLPX(fd0).(*rlpx), newRLPX(fd1).(*rlpx)
 		output   = make(chan result)
@@ -96,12 +99,12 @@ func testEncHandshake(token []byte) error {
 		defer func() { output <- r }()
 		defer fd0.Close()
 
-		r.pubkey, r.err = c0.doEncHandshake(prv0, &prv1.PublicKey)
+		r.pubkey, r.err = c0.doEncHandshake(prv0, prv1.PubKey())
 		if r.err != nil {
 			return
 		}
-		if !reflect.DeepEqual(r.pubkey, &prv1.PublicKey) {
-			r.err = fmt.Errorf("remote pubkey mismatch: got %v, want: %v", r.pubkey, &prv1.PublicKey)
+		if !r.pubkey.IsEqual(prv1.PubKey()) {
+			r.err = fmt.Errorf("remote pubkey mismatch: got %v, want: %v", r.pubkey, prv1.PubKey())
 		}
 	}()
 	go func() {
@@ -113,8 +116,9 @@ func testEncHandshake(token []byte) error {
 		if r.err != nil {
 			return
 		}
-		if !reflect.DeepEqual(r.pubkey, &prv0.PublicKey) {
-			r.err = fmt.Errorf("remote ID mismatch: got %v, want: %v", r.pubkey, &prv0.PublicKey)
+		// the initiator key travels as its x coordinate, that is the identity the receiver learns
+		if r.pubkey.X.Cmp(prv0.PubKey().X) != 0 {
+			r.err = fmt.Errorf("remote ID mismatch: got %v, want: %v", r.pubkey, prv0.PubKey())
 		}
 	}()
 
@@ -145,12 +149,12 @@ func testEncHandshake(token []byte) error {
 
 func TestProtocolHandshake(t *testing.T) {
 	var (
-		prv0, _ = crypto.GenerateKey()
-		pub0    = crypto.FromECDSAPub(&prv0.PublicKey)[1:]
+		prv0, _ = crypto.GenerateKey(rand.Reader)
+		pub0    = crypto.CompressPubkey(prv0.PubKey())[1:]
 		hs0     = &protoHandshake{Version: 3, ID: pub0, Caps: []Cap{{"a", 0}, {"b", 2}}}
 
-		prv1, _ = crypto.GenerateKey()
-		pub1    = crypto.FromECDSAPub(&prv1.PublicKey)[1:]
+		prv1, _ = crypto.GenerateKey(rand.Reader)
+		pub1    = crypto.CompressPubkey(prv1.PubKey())[1:]
 		hs1     = &protoHandshake{Version: 3, ID: pub1, Caps: []Cap{{"c", 1}, {"d", 3}}}
 
 		wg sync.WaitGroup
@@ -166,13 +170,13 @@ func TestProtocolHandshake(t *testing.T) {
 		defer wg.Done()
 		defer fd0.Close()
 		rlpx := newRLPX(fd0)
-		rpubkey, err := rlpx.doEncHandshake(prv0, &prv1.PublicKey)
+		rpubkey, err := rlpx.doEncHandshake(prv0, prv1.PubKey())
 		if err != nil {
 			t.Errorf("dial side enc handshake failed: %v", err)
 			return
 		}
-		if !reflect.DeepEqual(rpubkey, &prv1.PublicKey) {
-			t.Errorf("dial side remote pubkey mismatch: got %v, want %v", rpubkey, &prv1.PublicKey)
+		if !rpubkey.IsEqual(prv1.PubKey()) {
+			t.Errorf("dial side remote pubkey mismatch: got %v, want %v", rpubkey, prv1.PubKey())
 			return
 		}
 
@@ -197,8 +201,8 @@ func TestProtocolHandshake(t *testing.T) {
 			t.Errorf("listen side enc handshake failed: %v", err)
 			return
 		}
-		if !reflect.DeepEqual(rpubkey, &prv0.PublicKey) {
-			t.Errorf("listen side remote pubkey mismatch: got %v, want %v", rpubkey, &prv0.PublicKey)
+		if rpubkey.X.Cmp(prv0.PubKey().X) != 0 {
+			t.Errorf("listen side remote pubkey mismatch: got %v, want %v", rpubkey, prv0.PubKey())
 			return
 		}
 
@@ -213,7 +217,7 @@ func TestProtocolHandshake(t *testing.T) {
 			return
 		}
 
-		if err := ExpectMsg(rlpx, discMsg, []DiscReason{DiscQuitting}); err != nil {
+		if err := ExpectMsg(rlpx, discMsg, [1]DiscReason{DiscQuitting}); err != nil {
 			t.Errorf("error receiving disconnect: %v", err)
 		}
 	}()
@@ -229,7 +233,7 @@ func TestProtocolHandshakeErrors(t *testing.T) {
 	}{
 		{
 			code: discMsg,
-			msg:  []DiscReason{DiscQuitting},
+			msg:  [1]DiscReason{DiscQuitting},
 			err:  DiscQuitting,
 		},
 		{
@@ -245,7 +249,7 @@ func TestProtocolHandshakeErrors(t *testing.T) {
 		{
 			code: handshakeMsg,
 			msg:  []byte{1, 2, 3},
-			err:  newPeerError(errInvalidMsg, "(code 0) (size 4) rlp: expected input list for p2p.protoHandshake"),
+			err:  io.EOF,
 		},
 		{
 			code: handshakeMsg,
@@ -268,16 +272,17 @@ func TestRLPXFrameFake(t *testing.T) {
 	buf := new(bytes.Buffer)
 	hash := fakeHash([]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1})
 	rw := newRLPXFrameRW(buf, secrets{
-		AES:        crypto.Keccak256(),
-		MAC:        crypto.Keccak256(),
+		AES:        crypto.Keccak256Hash().Bytes(),
+		MAC:        crypto.Keccak256Hash().Bytes(),
 		IngressMAC: hash,
 		EgressMAC:  hash,
 	})
 
+	// payload is binary encoded, its length prefix is 04 where rlp has C4
 	golden := unhex(`
 00828ddae471818bb0bfa6b551d1cb42
 01010101010101010101010101010101
-ba628a4ba590cb43f7848f41c4382885
+baa28a4ba590cb43f7848f41c4382885
 01010101010101010101010101010101
 `)
 
@@ -303,7 +308,7 @@ ba628a4ba590cb43f7848f41c4382885
 		t.Errorf("msg code mismatch: got %d, want %d", msg.Code, 8)
 	}
 	payload, _ := ioutil.ReadAll(msg.Payload)
-	wantPayload := unhex("C401020304")
+	wantPayload := unhex("0401020304")
 	if !bytes.Equal(payload, wantPayload) {
 		t.Errorf("msg payload mismatch:\ngot  %x\nwant %x", payload, wantPayload)
 	}
@@ -368,32 +373,98 @@ func TestRLPXFrameRW(t *testing.T) {
 			t.Fatalf("msg code mismatch: got %d, want %d", msg.Code, i)
 		}
 		payload, _ := ioutil.ReadAll(msg.Payload)
-		wantPayload, _ := rlp.EncodeToBytes(wmsg)
+		wantPayload, _ := binary.Marshal(wmsg)
 		if !bytes.Equal(payload, wantPayload) {
 			t.Fatalf("msg payload mismatch:\ngot  %x\nwant %x", payload, wantPayload)
 		}
 	}
 }
 
+func TestRLPXFrameRWSnappy(t *testing.T) {
+	var (
+		aesSecret = make([]byte, 16)
+		macSecret = make([]byte, 16)
+		macInit   = make([]byte, 32)
+	)
+	for _, s := range [][]byte{aesSecret, macSecret, macInit} {
+		rand.Read(s)
+	}
+	conn := new(bytes.Buffer)
+	newRW := func() *rlpxFrameRW {
+		s := secrets{
+			AES:        aesSecret,
+			MAC:        macSecret,
+			EgressMAC:  sha3.NewLegacyKeccak256(),
+			IngressMAC: sha3.NewLegacyKeccak256(),
+		}
+		s.EgressMAC.Write(macInit)
+		s.IngressMAC.Write(macInit)
+		rw := newRLPXFrameRW(conn, s)
+		rw.snappy = true
+		return rw
+	}
+	rw1, rw2 := newRW(), newRW()
+
+	// payloads are read after later frames reuse pooled buffers,
+	// so each must still own its memory.
+	var msgs []Msg
+	sizes := []int{0, 1, 15, 16, 300, 70000}
+	for i, size := range sizes {
+		if err := rw1.WriteMsg(Msg{Code: uint64(i * 100), Size: uint32(size), Payload: bytes.NewReader(bytes.Repeat([]byte{byte(i)}, size))}); err != nil {
+			t.Fatalf("WriteMsg error (size=%d): %v", size, err)
+		}
+		msg, err := rw2.ReadMsg()
+		if err != nil {
+			t.Fatalf("ReadMsg error (size=%d): %v", size, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	for i, msg := range msgs {
+		if msg.Code != uint64(i*100) {
+			t.Errorf("msg code mismatch: got %d, want %d", msg.Code, i*100)
+		}
+		payload, _ := ioutil.ReadAll(msg.Payload)
+		if want := bytes.Repeat([]byte{byte(i)}, sizes[i]); !bytes.Equal(payload, want) {
+			t.Errorf("msg %d payload mismatch: got %d bytes, want %d", i, len(payload), len(want))
+		}
+	}
+}
+
+func TestRLPXMsgCodeEncoding(t *testing.T) {
+	for _, code := range []uint64{0, 1, 16, 127, 128, 300, 1 << 32} {
+		want, err := binary.Marshal(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, oldBinary.MaxVarintLen64)
+		got = got[:oldBinary.PutUvarint(got, code)]
+		if !bytes.Equal(got, want) {
+			t.Errorf("code %d: got %x, want %x", code, got, want)
+		}
+	}
+}
+
 type handshakeAuthTest struct {
 	input       string
 	isPlain     bool
 	wantVersion uint
-	wantRest    []rlp.RawValue
+	wantRest    []enr.RawValue
 }
 
+// The handshake vectors are sealed for keyB (auth) and keyA (ack) of
+// TestHandshakeForwardCompatibility, in the wire format of this network: the
+// public keys are 32 byte x coordinates and the EIP-8 bodies are binary encoded.
 var eip8HandshakeAuthTests = []handshakeAuthTest{
 	// (Auth₁) RLPx v4 plain encoding
 	{
 		input: `
-			048ca79ad18e4b0659fab4853fe5bc58eb83992980f4c9cc147d2aa31532efd29a3d3dc6a3d89eaf
-			913150cfc777ce0ce4af2758bf4810235f6e6ceccfee1acc6b22c005e9e3a49d6448610a58e98744
-			ba3ac0399e82692d67c1f58849050b3024e21a52c9d3b01d871ff5f210817912773e610443a9ef14
-			2e91cdba0bd77b5fdf0769b05671fc35f83d83e4d3b0b000c6b2a1b1bba89e0fc51bf4e460df3105
-			c444f14be226458940d6061c296350937ffd5e3acaceeaaefd3c6f74be8e23e0f45163cc7ebd7622
-			0f0128410fd05250273156d548a414444ae2f7dea4dfca2d43c057adb701a715bf59f6fb66b2d1d2
-			0f2c703f851cbf5ac47396d9ca65b6260bd141ac4d53e2de585a73d1750780db4c9ee4cd4d225173
-			a4592ee77e2bd94d0be3691f3b406f9bba9b591fc63facc016bfa8
+			04d0ef89588702af399f034f7e1b93d69a03f01a8df51d628f2c23096159ef6947182f34f8ed5362
+			4dae301b8e8e1c5c4aa60878aab26a1b5ee860c45e47b8d550cafe8791d2ddfc69daa2cab8e6c6eb
+			47a520e7c0daa442b8335a660b70526e5da2f95b21ce2f552f40fd8c3f62e9f5c9600415802dbe48
+			a0dbf024fcc22e8125796fc237570546cca49c8b3133e16673db697be0c23f47a6cb3d381131565c
+			fde5bae6e4f0af63fe9428541fbdd3e58303c91f2c93d3c264a25d40787eb1b1df1d97c0949be727
+			968da36d4d317786b86bb4ce0a52d8c621e2032f85559529f194993fb01b96c8879249276168bb85
+			535a0b37aff0d5a1504135916a780bfa4c27a6a527541898d4d74016b048130ed6c15d
 		`,
 		isPlain:     true,
 		wantVersion: 4,
@@ -401,118 +472,105 @@ var eip8HandshakeAuthTests = []handshakeAuthTest{
 	// (Auth₂) EIP-8 encoding
 	{
 		input: `
-			01b304ab7578555167be8154d5cc456f567d5ba302662433674222360f08d5f1534499d3678b513b
-			0fca474f3a514b18e75683032eb63fccb16c156dc6eb2c0b1593f0d84ac74f6e475f1b8d56116b84
-			9634a8c458705bf83a626ea0384d4d7341aae591fae42ce6bd5c850bfe0b999a694a49bbbaf3ef6c
-			da61110601d3b4c02ab6c30437257a6e0117792631a4b47c1d52fc0f8f89caadeb7d02770bf999cc
-			147d2df3b62e1ffb2c9d8c125a3984865356266bca11ce7d3a688663a51d82defaa8aad69da39ab6
-			d5470e81ec5f2a7a47fb865ff7cca21516f9299a07b1bc63ba56c7a1a892112841ca44b6e0034dee
-			70c9adabc15d76a54f443593fafdc3b27af8059703f88928e199cb122362a4b35f62386da7caad09
-			c001edaeb5f8a06d2b26fb6cb93c52a9fca51853b68193916982358fe1e5369e249875bb8d0d0ec3
-			6f917bc5e1eafd5896d46bd61ff23f1a863a8a8dcd54c7b109b771c8e61ec9c8908c733c0263440e
-			2aa067241aaa433f0bb053c7b31a838504b148f570c0ad62837129e547678c5190341e4f1693956c
-			3bf7678318e2d5b5340c9e488eefea198576344afbdf66db5f51204a6961a63ce072c8926c
+			01ed046c0a2d476090100a8d1d4a72d0f30d129866cbe6530e4c3b2b4fa55829b0a280223fe3798a
+			aa31e1c3187b011cd47dcd26804187f65419016b3a5550268936c49c1f903b800ad853cbc13bb380
+			dddca9db54e450a86bc032751f9bc13ae92ecd07ad62374b0734d7bc506a08ef16571105055bb3fd
+			49cffff88fc7d7fb155917d480406d26182be2e97883bc8edb54207b6e82e5be791e142fa0b9291e
+			b311d4756cc274231babda12969b64e250e959325d1fdb92a45c63ca9f7382dd0b47413cac48cdc4
+			3884ac0cd8876ab917c92bdfd15956e2e4e59c42c9c9a7a1fdef0c398f677715e5940c054e644ba1
+			3e9c84c3d96d178adb09c4c7be739fd8b7012008e3cc9d2fae21c5cd2d6840a63bc94ad38daab976
+			3d823297ab45cbc060a2001b29f5cf729af69356c0e31fb045ac368ce944cf904b3adae7fc0163f3
+			0f1c7e6d0a4e598716677e5eb43a2828e7a820281ee520093a88b50798c91a86125061bca5e8854d
+			93d269196eefa22307225bb97a9741f65aff469c9de644c21c3c6ae0a3078df6f3db0d3d6bee060c
+			9744f85b563af0cde30fe7e893a5fa937377b77bcc39dcbf04fb40f4338398e453e8d620f908868d
+			d2febde0eaab6f92db5bb1ad45f75dafb83a1f491dcb66d7898b32dd765d8f5cb394019c55cf19fe
+			e74947fef6350f25825f164c15245c
 		`,
 		wantVersion: 4,
-		wantRest:    []rlp.RawValue{},
 	},
 	// (Auth₃) RLPx v4 EIP-8 encoding with version 56, additional list elements
 	{
 		input: `
-			01b8044c6c312173685d1edd268aa95e1d495474c6959bcdd10067ba4c9013df9e40ff45f5bfd6f7
-			2471f93a91b493f8e00abc4b80f682973de715d77ba3a005a242eb859f9a211d93a347fa64b597bf
-			280a6b88e26299cf263b01b8dfdb712278464fd1c25840b995e84d367d743f66c0e54a586725b7bb
-			f12acca27170ae3283c1073adda4b6d79f27656993aefccf16e0d0409fe07db2dc398a1b7e8ee93b
-			cd181485fd332f381d6a050fba4c7641a5112ac1b0b61168d20f01b479e19adf7fdbfa0905f63352
-			bfc7e23cf3357657455119d879c78d3cf8c8c06375f3f7d4861aa02a122467e069acaf513025ff19
-			6641f6d2810ce493f51bee9c966b15c5043505350392b57645385a18c78f14669cc4d960446c1757
-			1b7c5d725021babbcd786957f3d17089c084907bda22c2b2675b4378b114c601d858802a55345a15
-			116bc61da4193996187ed70d16730e9ae6b3bb8787ebcaea1871d850997ddc08b4f4ea668fbf3740
-			7ac044b55be0908ecb94d4ed172ece66fd31bfdadf2b97a8bc690163ee11f5b575a4b44e36e2bfb2
-			f0fce91676fd64c7773bac6a003f481fddd0bae0a1f31aa27504e2a533af4cef3b623f4791b2cca6
-			d490
+			01f904d9fd926e85b2758012461f8a24fad5f65c2ed2e21728c9ddcce6592715cd89c0c965383251
+			e075f14c79bdc19d644182a796c9b8299441861df6cfe52119732bdff7408a40dfb57737ff83ff68
+			731460355129f3f9503357911d9538fba653c52669894c39efb212a8a6c1ee6dee8845d2e599ab83
+			5e48e4ed4504e53174815d227779f843d3ec3fa751f2b259a764bf9fd26d94edd242f8f56f7912e6
+			ae4eb120ce5b73aa74befab790465e502d4ae555066fbc7e3c716e4796a51a7c47dfad468c49f69a
+			81cb8dfafb2e6b9c5e88861cb062628966b02c41bdb4b235badd676307891ac6d21985bc4062d242
+			8b44e6d4945650484e3e001da08cfdbf25768c57ac91f55ff0630b76a886834dcab935e71826d57c
+			b8365fd5540110a94c53a8d9180162ac9cc552fc7c94389cdd00f0998b0f2d45c22bc53987f9395d
+			fbbd7b7ccfd3ff7f530064c945bf01e18628b7e10ba2335f1acea8d2be930330a9d1841c363e9373
+			bd24d751b93d7a68987649d6053897b1f8456f9655c55ced1296f569a96694baf065efcf0f7eac83
+			7832ce79e2a299c327e2915a7fa052fb5ad2ad5ce94f9e67fdee39e494f520c7fa0f7a992b8370c9
+			458e683c61d14088af26fa8a4bf9fbb4a9e9f45fcd53474e88653e8f4a3a89f4b62e61836bf34120
+			d1415ae2f42fe3e280deb3af18f9c4fcb16a56c98a06f7ad8ca192
 		`,
 		wantVersion: 56,
-		wantRest:    []rlp.RawValue{{0x01}, {0x02}, {0xC2, 0x04, 0x05}},
+		wantRest:    []enr.RawValue{{0x01}, {0x02}, {0xC2, 0x04, 0x05}},
 	},
 }
 
 type handshakeAckTest struct {
 	input       string
 	wantVersion uint
-	wantRest    []rlp.RawValue
+	wantRest    []enr.RawValue
 }
 
 var eip8HandshakeRespTests = []handshakeAckTest{
 	// (Ack₁) RLPx v4 plain encoding
 	{
 		input: `
-			049f8abcfa9c0dc65b982e98af921bc0ba6e4243169348a236abe9df5f93aa69d99cadddaa387662
-			b0ff2c08e9006d5a11a278b1b3331e5aaabf0a32f01281b6f4ede0e09a2d5f585b26513cb794d963
-			5a57563921c04a9090b4f14ee42be1a5461049af4ea7a7f49bf4c97a352d39c8d02ee4acc416388c
-			1c66cec761d2bc1c72da6ba143477f049c9d2dde846c252c111b904f630ac98e51609b3b1f58168d
-			dca6505b7196532e5f85b259a20c45e1979491683fee108e9660edbf38f3add489ae73e3dda2c71b
-			d1497113d5c755e942d1
+			0493000914ab628e5ed01aa9ce94d2b473038ba0a877e7632ed380749aa8ab1e5e85199b49e70d2b
+			9db336a0b8a05ed7dd020c9c1803ce2369eda56a01c41ceecffcdb1713a6af90106dc78b3d6c814c
+			a9582dae119596774be6171ed6654f74466dfa85abb7d0d1d8ac3db472e8b9ae15386bd8a08347c3
+			c07e64e8bc2690795abf2e8f396457945286142cab61124c2a67f0ccc909b2f1b12334725c375865
+			c3b34eecb27883aa868b42ac6ebbc039eb90
 		`,
 		wantVersion: 4,
 	},
 	// (Ack₂) EIP-8 encoding
 	{
 		input: `
-			01ea0451958701280a56482929d3b0757da8f7fbe5286784beead59d95089c217c9b917788989470
-			b0e330cc6e4fb383c0340ed85fab836ec9fb8a49672712aeabbdfd1e837c1ff4cace34311cd7f4de
-			05d59279e3524ab26ef753a0095637ac88f2b499b9914b5f64e143eae548a1066e14cd2f4bd7f814
-			c4652f11b254f8a2d0191e2f5546fae6055694aed14d906df79ad3b407d94692694e259191cde171
-			ad542fc588fa2b7333313d82a9f887332f1dfc36cea03f831cb9a23fea05b33deb999e85489e645f
-			6aab1872475d488d7bd6c7c120caf28dbfc5d6833888155ed69d34dbdc39c1f299be1057810f34fb
-			e754d021bfca14dc989753d61c413d261934e1a9c67ee060a25eefb54e81a4d14baff922180c395d
-			3f998d70f46f6b58306f969627ae364497e73fc27f6d17ae45a413d322cb8814276be6ddd13b885b
-			201b943213656cde498fa0e9ddc8e0b8f8a53824fbd82254f3e2c17e8eaea009c38b4aa0a3f306e8
-			797db43c25d68e86f262e564086f59a2fc60511c42abfb3057c247a8a8fe4fb3ccbadde17514b7ac
-			8000cdb6a912778426260c47f38919a91f25f4b5ffb455d6aaaf150f7e5529c100ce62d6d92826a7
-			1778d809bdf60232ae21ce8a437eca8223f45ac37f6487452ce626f549b3b5fdee26afd2072e4bc7
-			5833c2464c805246155289f4
+			012e04356b7abfb91c274f544cb1ddbfdc5b2b41d3e12de62af947bc43badb30aef5cfb9268bb2db
+			574342223baf12b1c0f38e0ef65e157099950282eb5f2c1dfdb25e43d788bee485f05d9c6e79173e
+			80f5cfb9cd0f164cdbff3cdb51710b60ed8252095f7dee5d79c5cd6285c039295c92be2cc7859bf1
+			8a58132b248af36f6ff6344bf6b07748bff50bf63097e28160befad57bd3a108f36a91fff9798304
+			02f2407fad08e8bacad73c15794e02427948b3102f852291d7f37de317c204a6406a50ad43b75578
+			6c56e6f90df343736ef97dd4334a24e4befa4b51bb7fa9e10f06dc6e83782f9f6cbad9d35ecc073b
+			adbeed478c396bc790a866139eb32ac99a239b801d206611a31a35b633d963bbf5250337c3159311
+			1257c57679a46e6a532aed4f8e66bcb3f6c08145a7deb10e
 		`,
 		wantVersion: 4,
-		wantRest:    []rlp.RawValue{},
 	},
 	// (Ack₃) EIP-8 encoding with version 57, additional list elements
 	{
 		input: `
-			01f004076e58aae772bb101ab1a8e64e01ee96e64857ce82b1113817c6cdd52c09d26f7b90981cd7
-			ae835aeac72e1573b8a0225dd56d157a010846d888dac7464baf53f2ad4e3d584531fa203658fab0
-			3a06c9fd5e35737e417bc28c1cbf5e5dfc666de7090f69c3b29754725f84f75382891c561040ea1d
-			dc0d8f381ed1b9d0d4ad2a0ec021421d847820d6fa0ba66eaf58175f1b235e851c7e2124069fbc20
-			2888ddb3ac4d56bcbd1b9b7eab59e78f2e2d400905050f4a92dec1c4bdf797b3fc9b2f8e84a482f3
-			d800386186712dae00d5c386ec9387a5e9c9a1aca5a573ca91082c7d68421f388e79127a5177d4f8
-			590237364fd348c9611fa39f78dcdceee3f390f07991b7b47e1daa3ebcb6ccc9607811cb17ce51f1
-			c8c2c5098dbdd28fca547b3f58c01a424ac05f869f49c6a34672ea2cbbc558428aa1fe48bbfd6115
-			8b1b735a65d99f21e70dbc020bfdface9f724a0d1fb5895db971cc81aa7608baa0920abb0a565c9c
-			436e2fd13323428296c86385f2384e408a31e104670df0791d93e743a3a5194ee6b076fb6323ca59
-			3011b7348c16cf58f66b9633906ba54a2ee803187344b394f75dd2e663a57b956cb830dd7a908d4f
-			39a2336a61ef9fda549180d4ccde21514d117b6c6fd07a9102b5efe710a32af4eeacae2cb3b1dec0
-			35b9593b48b9d3ca4c13d245d5f04169b0b1
+			0187041b371044e9bf15aae2406e3a0f88e2b7b2b241b61475c2c51a705abb02a07ca05045a9de13
+			14dd4cb5bf2428dcb0e36e97969184bcefc1c30338d9aa8976d20b63692036064456940f301957db
+			6295daae0fb7b9ef2ec409c98f76877e3b4ceea7d3cba42d27b72ce6c006562a4207b88be8e76ef3
+			a7c25a29f425c005ea9fddd360f01ad0118bf03724259f7837518f971e0042f805bbb572419fb490
+			b6df40fce50071a860f740bfd32854abfab5dfd363018bb12073e1f7c42155f01de5885a747324e7
+			be667876fa3637b7a9b640583e5d5638e1dce2d582939dd243def2bf1ecbc172c0d4e13adb3676d9
+			8017bb440388704220593b8ebaaf402f8c14a83a093bd6d441b8d9aa7829d17079966bf35d29547e
+			aab12df8d99a5bc229a96a22d2f2d25e1e539787a6252ddc7b3f232270c42fc196798ee58407052a
+			831629986b4c7ded4dbd6c2623ee8da07917ba5d7db01ac22e3f28f2de6a6ec93f956992e1acfb98
+			853b6bb36766a2e8f4a58af0fcc0eca4870bc6efbe3a5039db5f48050a51428af0
 		`,
 		wantVersion: 57,
-		wantRest:    []rlp.RawValue{{0x06}, {0xC2, 0x07, 0x08}, {0x81, 0xFA}},
+		wantRest:    []enr.RawValue{{0x06}, {0xC2, 0x07, 0x08}, {0x81, 0xFA}},
 	},
 }
 
 func TestHandshakeForwardCompatibility(t *testing.T) {
 	var (
-		keyA, _       = crypto.HexToECDSA("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee")
-		keyB, _       = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
-		pubA          = crypto.FromECDSAPub(&keyA.PublicKey)[1:]
-		pubB          = crypto.FromECDSAPub(&keyB.PublicKey)[1:]
-		ephA, _       = crypto.HexToECDSA("869d6ecf5211f1cc60418a13b9d870b22959d0c16f02bec714c960dd2298a32d")
-		ephB, _       = crypto.HexToECDSA("e238eb8e04fee6511ab04c6dd3c89ce097b11f25d584863ac2b6d5b35b1847e4")
-		ephPubA       = crypto.FromECDSAPub(&ephA.PublicKey)[1:]
-		ephPubB       = crypto.FromECDSAPub(&ephB.PublicKey)[1:]
+		keyA, _       = crypto.ToPrivateKey(unhex("49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee"))
+		keyB, _       = crypto.ToPrivateKey(unhex("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"))
+		pubA          = crypto.CompressPubkey(keyA.PubKey())[1:]
+		ephB, _       = crypto.ToPrivateKey(unhex("e238eb8e04fee6511ab04c6dd3c89ce097b11f25d584863ac2b6d5b35b1847e4"))
+		ephPubB       = exportPubkey(ephB.PubKey())
 		nonceA        = unhex("7e968bba13b6c50e2c4cd7f241cc0d64d1ac25c7f5952df231ac6a2bda8ee5d6")
 		nonceB        = unhex("559aead08264d5795d3909718cdd05abd49572e84fe55590eef31a88a08fdffd")
-		_, _, _, _    = pubA, pubB, ephPubA, ephPubB
-		authSignature = unhex("299ca6acfd35e3d72d8ba3d1e2b60b5561d5af5218eb5bc182045769eb4226910a301acae3b369fffc4a4899d6b02531e89fd4fe36a2cf0d93607ba470b50f7800")
-		_             = authSignature
+		authSignature = unhex("5ccc53a99558f46162122995719d4f3cfd840926800f8e2dce54b729327c1cbe548ed0a385998a7bc6e2c91d1ba38dd9e8013f57bc0d6efd3c566f90cfeaadcf00")
 	)
 	makeAuth := func(test handshakeAuthTest) *authMsgV4 {
 		msg := &authMsgV4{Version: test.wantVersion, Rest: test.wantRest, gotPlain: test.isPlain}
@@ -570,14 +628,14 @@ func TestHandshakeForwardCompatibility(t *testing.T) {
 		hs = &encHandshake{
 			initiator:     false,
 			respNonce:     nonceB,
-			randomPrivKey: ecies.ImportECDSA(ephB),
+			randomPrivKey: ephB,
 		}
 		authCiphertext     = unhex(eip8HandshakeAuthTests[1].input)
 		authRespCiphertext = unhex(eip8HandshakeRespTests[1].input)
 		authMsg            = makeAuth(eip8HandshakeAuthTests[1])
 		wantAES            = unhex("80e8632c05fed6fc2a13b0f8d31a3cf645366239170ea067065aba8e28bac487")
 		wantMAC            = unhex("2ea74ec5dae199227dff1af715362700e989d889d7a493cb0639691efb8e5f98")
-		wantFooIngressHash = unhex("0c7ec6340062cc46f5e9f1e3cf86f8c8c403c5a0964f5df0ebd34a75ddc86db5")
+		wantFooIngressHash = unhex("4edd1545c4ac07394624e983bc9afb757c9c7710e69654d3c8027cca1cb1964f")
 	)
 	if err := hs.handleAuthMsg(authMsg, keyB); err != nil {
 		t.Fatalf("handleAuthMsg: %v", err)
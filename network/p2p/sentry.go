@@ -0,0 +1,58 @@
+package p2p
+
+import (
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+// Sentry architecture
+//
+// A producer node is hidden behind sentry nodes it trusts. Set SentryNodes on
+// the producer: it only dials the sentries, it does not listen, does not run
+// discovery or peer exchange, so its address is never advertised, and any
+// other node is refused. Set PrivateNodes on the sentries to the producers they
+// protect: the producers are trusted, they are kept above the peer limits and
+// their endpoints are never shared by peer exchange. The consensus protocol
+// relays the messages of the producer through its sentries.
+
+// setupSentry restrict the config of a producer behind sentries, it runs before the
+// listener, the discovery and the peer exchange are set up
+func (srv *Server) setupSentry() {
+	if !srv.SentryMode() {
+		return
+	}
+	srv.NoDiscovery = true
+	srv.DiscoveryV5 = false
+	srv.DiscoveryDNS = nil
+	srv.PeerExchange = false
+	srv.ListenAddr = ""
+	srv.NAT = nil
+	srv.BootstrapNodes = nil
+	srv.BootstrapNodesV5 = nil
+	srv.StaticNodes = append([]*enode.Node{}, srv.SentryNodes...)
+	srv.log.WithField("sentries", len(srv.SentryNodes)).Info("P2P sentry mode, only connecting to sentry nodes")
+}
+
+// SentryMode reports whether the node is a producer hidden behind sentry nodes
+func (srv *Server) SentryMode() bool {
+	return len(srv.SentryNodes) > 0
+}
+
+// IsSentry reports whether id is one of the sentry nodes of a producer in sentry mode
+func (srv *Server) IsSentry(id enode.ID) bool {
+	for _, n := range srv.SentryNodes {
+		if n.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPrivateNode reports whether id is a producer protected by this sentry node
+func (srv *Server) IsPrivateNode(id enode.ID) bool {
+	for _, n := range srv.PrivateNodes {
+		if n.ID() == id {
+			return true
+		}
+	}
+	return false
+}
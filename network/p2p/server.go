@@ -31,10 +31,12 @@ import (
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/common/mclock"
+	"github.com/drep-project/DREP-Chain/common/ntpclock"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/network/p2p/discover"
 	"github.com/drep-project/DREP-Chain/network/p2p/discv5"
+	"github.com/drep-project/DREP-Chain/network/p2p/dnsdisc"
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
 	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	"github.com/drep-project/DREP-Chain/network/p2p/nat"
@@ -96,6 +98,15 @@ type Config struct {
 	// with the rest of the network.
 	BootstrapNodes []*enode.Node
 
+	// DiscoveryDNS lists signed DNS node trees (enrtree://<key>@<domain>)
+	// used as dial candidates when the discovery table is sparse.
+	DiscoveryDNS []string `json:",omitempty"`
+
+	// PeerExchange enables the pex protocol, connected peers share signed
+	// endpoints of their peers, which are dialed when the discovery table
+	// is sparse, e.g. when discovery UDP is blocked.
+	PeerExchange bool `json:",omitempty"`
+
 	// BootstrapNodesV5 are used to establish connectivity
 	// with the rest of the network using the V5 discovery
 	// protocol.
@@ -114,6 +125,25 @@ type Config struct {
 	// Node networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `json:",omitempty"`
 
+	// Permissioned restricts connections in both directions to the nodes
+	// contained in NodeAllowlist. The check is done right after the
+	// encryption handshake.
+	Permissioned bool `json:",omitempty"`
+
+	// SentryNodes hides a producer behind the sentry nodes listed, it only
+	// dials them, does not listen nor advertise its address, and refuses
+	// any other node.
+	SentryNodes []*enode.Node `json:",omitempty"`
+
+	// PrivateNodes are the producers a sentry node protects, they are
+	// trusted and their endpoints are never shared with other peers.
+	PrivateNodes []*enode.Node `json:",omitempty"`
+
+	// NodeAllowlist holds the nodes allowed in permissioned mode. It is
+	// created empty on start if not set, and can be updated at runtime
+	// through SetAllowedNodes.
+	NodeAllowlist *NodeAllowlist `json:"-"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `json:",omitempty"`
@@ -124,6 +154,21 @@ type Config struct {
 	//Blockchain layer protocol
 	ProtocolsBlockChan []Protocol `json:"-"`
 
+	// ChainId is exchanged in protocol handshake, peers from other networks are rejected
+	ChainId uint64 `json:"-"`
+
+	// GenesisHash and ChainId are advertised in the node record, discovery
+	// skips nodes of other networks before dialing them.
+	GenesisHash crypto.Hash `json:"-"`
+
+	// NodeRole is the role advertised in the node record, "full" if empty.
+	NodeRole string `json:",omitempty"`
+
+	// Forks are the block heights of the hard forks scheduled in genesis. They
+	// make the fork identifier advertised in the node record, nodes following
+	// other rules are not dialed.
+	Forks []uint64 `json:"-"`
+
 	// If ListenAddr is set to a non-nil address, the server
 	// will listen for incoming connections.
 	//
@@ -162,15 +207,22 @@ type Server struct {
 	newTransport func(net.Conn) transport
 	newPeerHook  func(*Peer)
 
-	lock    sync.Mutex // protects running
-	running bool
+	lock        sync.Mutex // protects running and the chain entry fields
+	running     bool
+	headLowest  uint64 // block heights advertised in the node record
+	headHighest uint64
+	head        uint64 // exact head height, the fork identifier follows it
 
 	nodedb       *enode.DB
 	localnode    *enode.LocalNode
 	ntab         discoverTable
+	dnsSource    *dnsdisc.Source
+	pex          *peerExchange
+	natMapper    *nat.Mapper
 	listener     net.Listener
 	ourHandshake *protoHandshake
 	lastLookup   time.Time
+	dialStats    *dialStats
 	DiscV5       *discv5.Network
 
 	// These are for Peers, PeerCount (and nothing else).
@@ -188,6 +240,8 @@ type Server struct {
 	loopWG        sync.WaitGroup // loop, listenLoop
 	peerFeed      event.Feed
 	log           *logrus.Entry
+
+	refuseInbound int32 // set atomically, inbound connections are refused while it is 1
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -295,7 +349,6 @@ func (srv *Server) Peers() []*Peer {
 	// environments.
 	case srv.peerOp <- func(peers map[enode.ID]*Peer) {
 		for _, p := range peers {
-			fmt.Println("peer ip :", p.IP())
 			ps = append(ps, p)
 		}
 	}:
@@ -320,6 +373,9 @@ func (srv *Server) PeerCount() int {
 // server is shut down. If the connection fails for any reason, the server will
 // attempt to reconnect the peer.
 func (srv *Server) AddPeer(node *enode.Node) {
+	if srv.SentryMode() && !srv.IsSentry(node.ID()) {
+		return
+	}
 	select {
 	case srv.addstatic <- node:
 	case <-srv.quit:
@@ -372,6 +428,73 @@ func (srv *Server) Self() *enode.Node {
 	return ln.Node()
 }
 
+// errNoNAT is returned by the NAT methods when no port mapper is configured.
+var errNoNAT = errors.New("nat port mapping is not enabled")
+
+// NATStatus returns the state of the NAT port mappings.
+func (srv *Server) NATStatus() (*nat.Status, error) {
+	if srv.natMapper == nil {
+		return nil, errNoNAT
+	}
+	return srv.natMapper.Status(), nil
+}
+
+// NATRemap refreshes the NAT port mappings immediately. The local node
+// record follows a changed external address.
+func (srv *Server) NATRemap() (*nat.Status, error) {
+	if srv.natMapper == nil {
+		return nil, errNoNAT
+	}
+	status := srv.natMapper.Remap()
+	if _, isExtIP := srv.NAT.(nat.ExtIP); !isExtIP {
+		if ip := srv.natMapper.ExternalIP(); ip != nil && srv.localnode != nil {
+			srv.localnode.SetStaticIP(ip)
+		}
+	}
+	return status, nil
+}
+
+// errNoDiscovery is returned by the discovery methods when the v4 discovery
+// table is not running.
+var errNoDiscovery = errors.New("node discovery is not running")
+
+func (srv *Server) discoveryTable() (*discover.Table, error) {
+	tab, ok := srv.ntab.(*discover.Table)
+	if !ok || tab == nil {
+		return nil, errNoDiscovery
+	}
+	return tab, nil
+}
+
+// DiscoveryHealth returns the bucket contents, last refresh time and ping/pong
+// counters of the discovery table.
+func (srv *Server) DiscoveryHealth() (*discover.Health, error) {
+	tab, err := srv.discoveryTable()
+	if err != nil {
+		return nil, err
+	}
+	return tab.Health(), nil
+}
+
+// DiscoveryLookup runs a lookup for a random target immediately and returns
+// the nodes found.
+func (srv *Server) DiscoveryLookup() ([]*enode.Node, error) {
+	tab, err := srv.discoveryTable()
+	if err != nil {
+		return nil, err
+	}
+	return tab.LookupRandom(), nil
+}
+
+// DiscoveryBond pings node and adds it to the discovery table when it answers.
+func (srv *Server) DiscoveryBond(node *enode.Node) error {
+	tab, err := srv.discoveryTable()
+	if err != nil {
+		return err
+	}
+	return tab.Bond(node)
+}
+
 // Stop terminates the server and all active peer connections.
 // It blocks until all active connections have been closed.
 func (srv *Server) Stop() {
@@ -455,6 +578,16 @@ func (srv *Server) Start() (err error) {
 	srv.removetrusted = make(chan *enode.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.setupSentry()
+	if srv.NAT != nil {
+		srv.natMapper = nat.NewMapper(srv.NAT)
+	}
+	if srv.Permissioned && srv.NodeAllowlist == nil {
+		srv.NodeAllowlist = NewNodeAllowlist()
+	}
+	if srv.PeerExchange {
+		srv.pex = newPeerExchange(srv)
+	}
 
 	if err := srv.setupLocalNode(); err != nil {
 		return err
@@ -470,16 +603,50 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.localnode.ID(), srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
+	if srv.dnsSource != nil {
+		dialer.dns = srv.dnsSource
+	}
+	if srv.pex != nil {
+		dialer.pex = srv.pex
+	}
+	if srv.Permissioned {
+		dialer.allowlist = srv.NodeAllowlist
+	}
+	dialer.checkNetwork = srv.checkNetwork
+	srv.dialStats = dialer.stats
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
 	return nil
 }
 
+// protocols returns the protocols run on every peer, the pex protocol is
+// added if enabled. It is left out when checking a peer is useful.
+func (srv *Server) protocols() []Protocol {
+	if srv.pex == nil {
+		return srv.ProtocolsBlockChan
+	}
+	protocols := make([]Protocol, 0, len(srv.ProtocolsBlockChan)+1)
+	protocols = append(protocols, srv.ProtocolsBlockChan...)
+	return append(protocols, srv.pex.protocol())
+}
+
+// Caps returns the capabilities offered to peers in the protocol handshake,
+// sorted by name and version.
+func (srv *Server) Caps() []Cap {
+	protocols := srv.protocols()
+	caps := make([]Cap, 0, len(protocols))
+	for _, p := range protocols {
+		caps = append(caps, p.cap())
+	}
+	sort.Sort(capsByNameAndVersion(caps))
+	return caps
+}
+
 func (srv *Server) setupLocalNode() error {
 	// Create the devp2p handshake.
 	pubkey := crypto.CompressPubkey(srv.PrivateKey.PubKey())
-	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: pubkey[1:]}
-	for _, p := range srv.ProtocolsBlockChan {
+	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: pubkey[1:], ChainId: srv.ChainId}
+	for _, p := range srv.protocols() {
 		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, p.cap())
 	}
 	sort.Sort(capsByNameAndVersion(srv.ourHandshake.Caps))
@@ -493,6 +660,8 @@ func (srv *Server) setupLocalNode() error {
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	srv.localnode.Set(capsByNameAndVersion(srv.ourHandshake.Caps))
+	srv.localnode.Set(srv.chainEntry())
+	srv.setForkEntry()
 	// TODO: check conflicts
 	for _, p := range srv.ProtocolsBlockChan {
 		for _, e := range p.Attributes {
@@ -522,6 +691,16 @@ func (srv *Server) setupLocalNode() error {
 }
 
 func (srv *Server) setupDiscovery() error {
+	// DNS discovery
+	if len(srv.DiscoveryDNS) > 0 && !srv.NoDial {
+		for _, url := range srv.DiscoveryDNS {
+			if _, err := dnsdisc.ParseURL(url); err != nil {
+				return fmt.Errorf("invalid DNS discovery url %q: %v", url, err)
+			}
+		}
+		srv.dnsSource = dnsdisc.NewClient(dnsdisc.Config{}).NewSource(srv.DiscoveryDNS)
+	}
+
 	if srv.NoDiscovery && !srv.DiscoveryV5 {
 		return nil
 	}
@@ -538,7 +717,7 @@ func (srv *Server) setupDiscovery() error {
 	srv.log.WithField("addr", realaddr).Debug("UDP listener up")
 	if srv.NAT != nil {
 		if !realaddr.IP.IsLoopback() {
-			go nat.Map(srv.NAT, srv.quit, "udp", realaddr.Port, realaddr.Port, "ethereum discovery")
+			go srv.natMapper.Map(srv.quit, "udp", realaddr.Port, realaddr.Port, "ethereum discovery")
 		}
 	}
 	srv.localnode.SetFallbackUDP(realaddr.Port)
@@ -556,6 +735,7 @@ func (srv *Server) setupDiscovery() error {
 			NetRestrict: srv.NetRestrict,
 			Bootnodes:   srv.BootstrapNodes,
 			Unhandled:   unhandled,
+			ForkFilter:  srv.CheckFork,
 		}
 		ntab, err := discover.ListenUDP(conn, srv.localnode, cfg)
 		if err != nil {
@@ -601,7 +781,7 @@ func (srv *Server) setupListening() error {
 	if !laddr.IP.IsLoopback() && srv.NAT != nil {
 		srv.loopWG.Add(1)
 		go func() {
-			nat.Map(srv.NAT, srv.quit, "tcp", laddr.Port, laddr.Port, "ethereum p2p")
+			srv.natMapper.Map(srv.quit, "tcp", laddr.Port, laddr.Port, "ethereum p2p")
 			srv.loopWG.Done()
 		}()
 	}
@@ -611,6 +791,7 @@ func (srv *Server) setupListening() error {
 type dialer interface {
 	newTasks(running int, peers map[enode.ID]*Peer, now time.Time) []task
 	taskDone(task, time.Time)
+	peerRemoved(*Peer)
 	addStatic(*enode.Node)
 	removeStatic(*enode.Node)
 }
@@ -633,6 +814,12 @@ func (srv *Server) run(dialstate dialer) {
 	for _, n := range srv.ProduceNodes {
 		trusted[n.ID()] = true
 	}
+	for _, n := range srv.SentryNodes {
+		trusted[n.ID()] = true
+	}
+	for _, n := range srv.PrivateNodes {
+		trusted[n.ID()] = true
+	}
 
 	// removes t from runningTasks
 	delTask := func(t task) {
@@ -738,7 +925,7 @@ running:
 			err := srv.protoHandshakeChecks(peers, inboundCount, c)
 			if err == nil {
 				// The handshakes are done and it passed all checks.
-				p := newPeer(c, srv.ProtocolsBlockChan)
+				p := newPeer(c, srv.protocols())
 				// If message events are enabled, pass the peerFeed
 				// to the peer
 				if srv.EnableMsgEvents {
@@ -751,6 +938,9 @@ running:
 				if p.Inbound() {
 					inboundCount++
 				}
+				peerAddMeter.Mark(1)
+				peersGauge.Update(int64(len(peers)))
+				inboundPeersGauge.Update(int64(inboundCount))
 			}
 			// The dialer logic relies on the assumption that
 			// dial tasks complete after the peer has been added or
@@ -772,10 +962,14 @@ running:
 				Info("Removing p2p peer")
 
 			delete(peers, pd.ID())
+			dialstate.peerRemoved(pd.Peer)
 
 			if pd.Inbound() {
 				inboundCount--
 			}
+			peerDropMeter.Mark(1)
+			peersGauge.Update(int64(len(peers)))
+			inboundPeersGauge.Update(int64(inboundCount))
 		}
 	}
 
@@ -788,6 +982,9 @@ running:
 	if srv.DiscV5 != nil {
 		srv.DiscV5.Close()
 	}
+	if srv.dnsSource != nil {
+		srv.dnsSource.Close()
+	}
 	// Disconnect all peers.
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
@@ -814,10 +1011,16 @@ func (srv *Server) protoHandshakeChecks(peers map[enode.ID]*Peer, inboundCount i
 
 func (srv *Server) encHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int, c *conn) error {
 	switch {
+	case srv.Permissioned && !srv.NodeAllowlist.Contains(c.peerNode.ID()):
+		return DiscNotAllowed
+	case srv.SentryMode() && !srv.IsSentry(c.peerNode.ID()):
+		return DiscNotAllowed
 	case !c.is(trustedConn|staticDialedConn) && len(peers) >= srv.MaxPeers:
 		return DiscTooManyPeers
 	case !c.is(trustedConn) && c.is(inboundConn) && inboundCount >= srv.maxInboundConns():
 		return DiscTooManyPeers
+	case !c.is(trustedConn) && c.is(inboundConn) && atomic.LoadInt32(&srv.refuseInbound) == 1:
+		return DiscQuitting
 	case peers[c.peerNode.ID()] != nil:
 		p := peers[c.peerNode.ID()]
 		log.WithField("old peer ip", p.IP()).WithField("node", c.peerNode.ID()).Info("encHandshakeChecks err")
@@ -829,11 +1032,21 @@ func (srv *Server) encHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int
 	}
 }
 
+// SetRefuseInbound refuse or accept again inbound connections of nodes not trusted,
+// the connected peers are kept
+func (srv *Server) SetRefuseInbound(refuse bool) {
+	var flag int32
+	if refuse {
+		flag = 1
+	}
+	atomic.StoreInt32(&srv.refuseInbound, flag)
+}
+
 func (srv *Server) maxInboundConns() int {
 	return srv.MaxPeers - srv.maxDialedConns()
 }
 func (srv *Server) maxDialedConns() int {
-	if srv.NoDiscovery || srv.NoDial {
+	if (srv.NoDiscovery && len(srv.DiscoveryDNS) == 0) || srv.NoDial {
 		return 0
 	}
 	r := srv.DialRatio
@@ -930,6 +1143,7 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 	remotePubkey, err := c.doEncHandshake(srv.PrivateKey, dialPubkey)
 	if err != nil {
 		srv.log.WithField("addr", c.fd.RemoteAddr()).WithField("conn", c.flags).WithField("err", err).Trace("Failed RLPx handshake")
+		encHandshakeFailMeter.Mark(1)
 		return err
 	}
 	if dialDest != nil {
@@ -946,22 +1160,31 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 	err = srv.checkpoint(c, srv.posthandshake)
 	if err != nil {
 		clog.WithField("err", err).Trace("Rejected peer before protocol handshake")
+		handshakeRejectMeter.Mark(1)
 		return err
 	}
 	// Run the protocol handshake
 	phs, err := c.doProtoHandshake(srv.ourHandshake)
 	if err != nil {
 		clog.WithField("err", err).Trace("Failed proto handshake")
+		protoHandshakeFailMeter.Mark(1)
 		return err
 	}
 	if id := c.peerNode.ID(); !bytes.Equal(phs.ID, id[:]) {
 		clog.WithField("phsid", hex.EncodeToString(phs.ID)).Trace("Wrong devp2p handshake identity")
+		protoHandshakeFailMeter.Mark(1)
 		return DiscUnexpectedIdentity
 	}
+	if phs.ChainId != srv.ChainId {
+		clog.WithField("chainId", phs.ChainId).Trace("Peer from other network")
+		protoHandshakeFailMeter.Mark(1)
+		return DiscChainIdMismatch
+	}
 	c.caps, c.name = phs.Caps, phs.Name
 	err = srv.checkpoint(c, srv.addpeer)
 	if err != nil {
 		clog.WithField("err", err).Trace("Rejected peer")
+		handshakeRejectMeter.Mark(1)
 		return err
 	}
 	// If the checks completed successfully, runPeer has now been
@@ -1027,8 +1250,6 @@ func (srv *Server) runPeer(p *Peer) {
 		Error: err.Error(),
 	})
 
-	fmt.Println("remove peer ip:", p.String(), p.Name())
-
 	// Note: run waits for existing peers to be sent on srv.delpeer
 	// before returning, so this send should not select on srv.quit.
 	srv.delpeer <- peerDrop{p, err, remoteRequested}
@@ -1047,6 +1268,7 @@ type NodeInfo struct {
 	} `json:"ports"`
 	ListenAddr string                 `json:"listenAddr"`
 	Protocols  map[string]interface{} `json:"protocols"`
+	ClockDrift *ntpclock.Status       `json:"clockDrift,omitempty"` // Last measured drift of the system clock
 }
 
 // NodeInfo gathers and returns a collection of metadata known about the host.
@@ -1060,6 +1282,7 @@ func (srv *Server) NodeInfo() *NodeInfo {
 		IP:         node.IP().String(),
 		ListenAddr: srv.ListenAddr,
 		Protocols:  make(map[string]interface{}),
+		ClockDrift: ntpclock.Current(),
 	}
 	info.Ports.Discovery = node.UDP()
 	info.Ports.Listener = node.TCP()
@@ -1071,7 +1294,7 @@ func (srv *Server) NodeInfo() *NodeInfo {
 	}
 
 	// Gather all the running protocol infos (only once per protocol type)
-	for _, proto := range srv.ProtocolsBlockChan {
+	for _, proto := range srv.protocols() {
 		if _, ok := info.Protocols[proto.Name]; !ok {
 			nodeInfo := interface{}("unknown")
 			if query := proto.NodeInfo; query != nil {
@@ -17,16 +17,15 @@
 package p2p
 
 import (
-	"crypto/ecdsa"
+	crand "crypto/rand"
 	"errors"
-	"math/rand"
 	"net"
 	"reflect"
 	"testing"
 	"time"
 
-	log "github.com/drep-project/dlog"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
 	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	"golang.org/x/crypto/sha3"
@@ -37,13 +36,13 @@ import (
 // }
 
 type testTransport struct {
-	rpub *ecdsa.PublicKey
+	rpub *secp256k1.PublicKey
 	*rlpx
 
 	closeErr error
 }
 
-func newTestTransport(rpub *ecdsa.PublicKey, fd net.Conn) transport {
+func newTestTransport(rpub *secp256k1.PublicKey, fd net.Conn) transport {
 	wrapped := newRLPX(fd).(*rlpx)
 	wrapped.rw = newRLPXFrameRW(fd, secrets{
 		MAC:        zero16,
@@ -54,12 +53,12 @@ func newTestTransport(rpub *ecdsa.PublicKey, fd net.Conn) transport {
 	return &testTransport{rpub: rpub, rlpx: wrapped}
 }
 
-func (c *testTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *ecdsa.PublicKey) (*ecdsa.PublicKey, error) {
+func (c *testTransport) doEncHandshake(prv *secp256k1.PrivateKey, dialDest *secp256k1.PublicKey) (*secp256k1.PublicKey, error) {
 	return c.rpub, nil
 }
 
 func (c *testTransport) doProtoHandshake(our *protoHandshake) (*protoHandshake, error) {
-	pubkey := crypto.FromECDSAPub(c.rpub)[1:]
+	pubkey := idBytes(c.rpub)
 	return &protoHandshake{ID: pubkey, Name: "test"}, nil
 }
 
@@ -68,7 +67,7 @@ func (c *testTransport) close(err error) {
 	c.closeErr = err
 }
 
-func startTestServer(t *testing.T, remoteKey *ecdsa.PublicKey, pf func(*Peer)) *Server {
+func startTestServer(t *testing.T, remoteKey *secp256k1.PublicKey, pf func(*Peer)) *Server {
 	config := Config{
 		Name:       "test",
 		MaxPeers:   10,
@@ -89,9 +88,9 @@ func startTestServer(t *testing.T, remoteKey *ecdsa.PublicKey, pf func(*Peer)) *
 func TestServerListen(t *testing.T) {
 	// start the test server
 	connected := make(chan *Peer)
-	remid := &newkey().PublicKey
+	remid := newkey().PubKey()
 	srv := startTestServer(t, remid, func(p *Peer) {
-		if p.ID() != enode.PubkeyToIDV4(remid) {
+		if p.ID() != pubkeyID(remid) {
 			t.Error("peer func called with wrong node id")
 		}
 		connected <- p
@@ -140,7 +139,7 @@ func TestServerDial(t *testing.T) {
 
 	// start the server
 	connected := make(chan *Peer)
-	remid := &newkey().PublicKey
+	remid := newkey().PubKey()
 	srv := startTestServer(t, remid, func(p *Peer) { connected <- p })
 	defer close(connected)
 	defer srv.Stop()
@@ -156,7 +155,7 @@ func TestServerDial(t *testing.T) {
 
 		select {
 		case peer := <-connected:
-			if peer.ID() != enode.PubkeyToIDV4(remid) {
+			if peer.ID() != pubkeyID(remid) {
 				t.Errorf("peer has wrong id")
 			}
 			if peer.Name() != "test" {
@@ -233,7 +232,7 @@ func TestServerTaskScheduling(t *testing.T) {
 		quit:      make(chan struct{}),
 		ntab:      fakeTable{},
 		running:   true,
-		log:       log.New(),
+		log:       NewLog(),
 	}
 	srv.loopWG.Add(1)
 	go func() {
@@ -281,7 +280,7 @@ func TestServerManyTasks(t *testing.T) {
 			nodedb:    db,
 			ntab:      fakeTable{},
 			running:   true,
-			log:       log.New(),
+			log:       NewLog(),
 		}
 		done       = make(chan *testTask)
 		start, end = 0, 0
@@ -334,6 +333,8 @@ func (tg taskgen) newTasks(running int, peers map[enode.ID]*Peer, now time.Time)
 func (tg taskgen) taskDone(t task, now time.Time) {
 	tg.doneFunc(t)
 }
+func (tg taskgen) peerRemoved(*Peer) {
+}
 func (tg taskgen) addStatic(*enode.Node) {
 }
 func (tg taskgen) removeStatic(*enode.Node) {
@@ -353,13 +354,13 @@ func (t *testTask) Do(srv *Server) {
 // at capacity. Trusted connections should still be accepted.
 func TestServerAtCap(t *testing.T) {
 	trustedNode := newkey()
-	trustedID := enode.PubkeyToIDV4(&trustedNode.PublicKey)
+	trustedID := pubkeyID(trustedNode.PubKey())
 	srv := &Server{
 		Config: Config{
 			PrivateKey:   newkey(),
 			MaxPeers:     10,
 			NoDial:       true,
-			TrustedNodes: []*enode.Node{newNode(trustedID, nil)},
+			ProduceNodes: []*enode.Node{newNode(trustedID, nil)},
 		},
 	}
 	if err := srv.Start(); err != nil {
@@ -369,9 +370,9 @@ func TestServerAtCap(t *testing.T) {
 
 	newconn := func(id enode.ID) *conn {
 		fd, _ := net.Pipe()
-		tx := newTestTransport(&trustedNode.PublicKey, fd)
+		tx := newTestTransport(trustedNode.PubKey(), fd)
 		node := enode.SignNull(new(enr.Record), id)
-		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+		return &conn{fd: fd, transport: tx, flags: inboundConn, peerNode: node, cont: make(chan error)}
 	}
 
 	// Inject a few connections to fill up the peer set.
@@ -417,12 +418,12 @@ func TestServerAtCap(t *testing.T) {
 func TestServerPeerLimits(t *testing.T) {
 	srvkey := newkey()
 	clientkey := newkey()
-	clientnode := enode.NewV4(&clientkey.PublicKey, nil, 0, 0)
+	clientnode := enode.NewV4(clientkey.PubKey(), nil, 0, 0)
 
 	var tp = &setupTransport{
-		pubkey: &clientkey.PublicKey,
+		pubkey: clientkey.PubKey(),
 		phs: protoHandshake{
-			ID: crypto.FromECDSAPub(&clientkey.PublicKey)[1:],
+			ID: idBytes(clientkey.PubKey()),
 			// Force "DiscUselessPeer" due to unmatching caps
 			// Caps: []Cap{discard.cap()},
 		},
@@ -430,13 +431,13 @@ func TestServerPeerLimits(t *testing.T) {
 
 	srv := &Server{
 		Config: Config{
-			PrivateKey: srvkey,
-			MaxPeers:   0,
-			NoDial:     true,
-			Protocols:  []Protocol{discard},
+			PrivateKey:         srvkey,
+			MaxPeers:           0,
+			NoDial:             true,
+			ProtocolsBlockChan: []Protocol{discard},
 		},
 		newTransport: func(fd net.Conn) transport { return tp },
-		log:          log.New(),
+		log:          NewLog(),
 	}
 	if err := srv.Start(); err != nil {
 		t.Fatalf("couldn't start server: %v", err)
@@ -481,8 +482,8 @@ func TestServerPeerLimits(t *testing.T) {
 func TestServerSetupConn(t *testing.T) {
 	var (
 		clientkey, srvkey = newkey(), newkey()
-		clientpub         = &clientkey.PublicKey
-		srvpub            = &srvkey.PublicKey
+		clientpub         = clientkey.PubKey()
+		srvpub            = srvkey.PubKey()
 	)
 	tests := []struct {
 		dontstart bool
@@ -507,7 +508,7 @@ func TestServerSetupConn(t *testing.T) {
 		},
 		{
 			tt:           &setupTransport{pubkey: clientpub},
-			dialDest:     enode.NewV4(&newkey().PublicKey, nil, 0, 0),
+			dialDest:     enode.NewV4(newkey().PubKey(), nil, 0, 0),
 			flags:        dynDialedConn,
 			wantCalls:    "doEncHandshake,close,",
 			wantCloseErr: DiscUnexpectedIdentity,
@@ -527,13 +528,13 @@ func TestServerSetupConn(t *testing.T) {
 			wantCloseErr: errors.New("foo"),
 		},
 		{
-			tt:           &setupTransport{pubkey: srvpub, phs: protoHandshake{ID: crypto.FromECDSAPub(srvpub)[1:]}},
+			tt:           &setupTransport{pubkey: srvpub, phs: protoHandshake{ID: idBytes(srvpub)}},
 			flags:        inboundConn,
 			wantCalls:    "doEncHandshake,close,",
 			wantCloseErr: DiscSelf,
 		},
 		{
-			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: crypto.FromECDSAPub(clientpub)[1:]}},
+			tt:           &setupTransport{pubkey: clientpub, phs: protoHandshake{ID: idBytes(clientpub)}},
 			flags:        inboundConn,
 			wantCalls:    "doEncHandshake,doProtoHandshake,close,",
 			wantCloseErr: DiscUselessPeer,
@@ -543,13 +544,13 @@ func TestServerSetupConn(t *testing.T) {
 	for i, test := range tests {
 		srv := &Server{
 			Config: Config{
-				PrivateKey: srvkey,
-				MaxPeers:   10,
-				NoDial:     true,
-				Protocols:  []Protocol{discard},
+				PrivateKey:         srvkey,
+				MaxPeers:           10,
+				NoDial:             true,
+				ProtocolsBlockChan: []Protocol{discard},
 			},
 			newTransport: func(fd net.Conn) transport { return test.tt },
-			log:          log.New(),
+			log:          NewLog(),
 		}
 		if !test.dontstart {
 			if err := srv.Start(); err != nil {
@@ -568,7 +569,7 @@ func TestServerSetupConn(t *testing.T) {
 }
 
 type setupTransport struct {
-	pubkey            *ecdsa.PublicKey
+	pubkey            *secp256k1.PublicKey
 	encHandshakeErr   error
 	phs               protoHandshake
 	protoHandshakeErr error
@@ -577,7 +578,7 @@ type setupTransport struct {
 	closeErr error
 }
 
-func (c *setupTransport) doEncHandshake(prv *ecdsa.PrivateKey, dialDest *ecdsa.PublicKey) (*ecdsa.PublicKey, error) {
+func (c *setupTransport) doEncHandshake(prv *secp256k1.PrivateKey, dialDest *secp256k1.PublicKey) (*secp256k1.PublicKey, error) {
 	c.calls += "doEncHandshake,"
 	return c.pubkey, c.encHandshakeErr
 }
@@ -603,16 +604,20 @@ func (c *setupTransport) ReadMsg() (Msg, error) {
 }
 
 func newkey() *secp256k1.PrivateKey {
-	key, err := crypto.GenerateKey()
+	key, err := crypto.GenerateKey(crand.Reader)
 	if err != nil {
 		panic("couldn't generate key: " + err.Error())
 	}
 	return key
 }
 
-func randomID() (id enode.ID) {
-	for i := range id {
-		id[i] = byte(rand.Intn(255))
-	}
-	return id
+// pubkeyID return the id of the node of pub
+func pubkeyID(pub *secp256k1.PublicKey) enode.ID {
+	return enode.NewV4(pub, nil, 0, 0).ID()
+}
+
+// idBytes return the id of pub the way the protocol handshake carries it
+func idBytes(pub *secp256k1.PublicKey) []byte {
+	id := pubkeyID(pub)
+	return id[:]
 }
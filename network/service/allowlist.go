@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	// Allowlist sources of permissioned mode
+	AllowlistSourceFile  = "file"
+	AllowlistSourceChain = "chain"
+
+	defaultAllowlistFile    = "allowed-nodes.json"
+	allowlistReloadInterval = 10 * time.Second
+)
+
+// parseAllowlist parses a json list of enode urls or hex node ids.
+func parseAllowlist(data []byte) ([]enode.ID, error) {
+	var entries []string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	ids := make([]enode.ID, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.HasPrefix(entry, "enode://") {
+			n, err := enode.ParseV4(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid enode url %q: %v", entry, err)
+			}
+			ids = append(ids, n.ID())
+			continue
+		}
+		var id enode.ID
+		if err := id.UnmarshalText([]byte(entry)); err != nil {
+			return nil, fmt.Errorf("invalid node id %q: %v", entry, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// allowlistPath returns the path of the allowlist file.
+func (p2pService *P2pService) allowlistPath() string {
+	file := p2pService.Config.AllowlistFile
+	if file == "" {
+		file = defaultAllowlistFile
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(p2pService.Config.DataDir, file)
+	}
+	return file
+}
+
+// ReloadAllowlist reads the allowlist file and replaces the file source of
+// the allowlist. A missing file allows no node through this source.
+func (p2pService *P2pService) ReloadAllowlist() error {
+	file := p2pService.allowlistPath()
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		log.WithField("file", file).Warn("Allowlist file not found")
+		return p2pService.server.SetAllowedNodes(AllowlistSourceFile, nil)
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := parseAllowlist(data)
+	if err != nil {
+		return fmt.Errorf("allowlist file %s: %v", file, err)
+	}
+	log.WithField("file", file).WithField("nodes", len(ids)).Info("Loaded node allowlist")
+	return p2pService.server.SetAllowedNodes(AllowlistSourceFile, ids)
+}
+
+// watchAllowlist reloads the allowlist file when its modification time or
+// size changes.
+func (p2pService *P2pService) watchAllowlist() {
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(p2pService.allowlistPath()); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+
+	ticker := time.NewTicker(allowlistReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(p2pService.allowlistPath())
+			if err != nil {
+				if !modTime.IsZero() {
+					modTime, size = time.Time{}, 0
+					p2pService.ReloadAllowlist()
+				}
+				continue
+			}
+			if info.ModTime().Equal(modTime) && info.Size() == size {
+				continue
+			}
+			modTime, size = info.ModTime(), info.Size()
+			if err := p2pService.ReloadAllowlist(); err != nil {
+				log.WithField("err", err).Error("Reload node allowlist")
+			}
+		case <-p2pService.quit:
+			return
+		}
+	}
+}
+
+// SetAllowedNodes replaces the nodes allowed by source, peers no longer
+// allowed are disconnected.
+func (p2pService *P2pService) SetAllowedNodes(source string, ids []enode.ID) error {
+	return p2pService.server.SetAllowedNodes(source, ids)
+}
+
+// Permissioned reports whether only allowlisted nodes may connect.
+func (p2pService *P2pService) Permissioned() bool {
+	return p2pService.server.Allowlist() != nil
+}
+
+// Allowlist returns the allowed node ids of every source.
+func (p2pService *P2pService) Allowlist() (map[string][]enode.ID, error) {
+	allowlist := p2pService.server.Allowlist()
+	if allowlist == nil {
+		return nil, p2p.ErrNotPermissioned
+	}
+	return allowlist.Sources(), nil
+}
@@ -1,6 +1,12 @@
 package service
 
-import "github.com/drep-project/DREP-Chain/network/p2p/enode"
+import (
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/discover"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/nat"
+)
 
 /*
 name: p2p network interface
@@ -67,3 +73,236 @@ func (p2pApis *P2PApi) RemovePeer(addr string) {
 func (p2pApis *P2PApi) LocalNode() *enode.Node {
 	return p2pApis.p2pService.LocalNode()
 }
+
+/*
+name: Admin RPC Api
+usage: Node administration
+prefix:admin
+*/
+type AdminApi struct {
+	p2pService P2P
+}
+
+/*
+ name: nodeAddress
+ usage: Get the enode url, node id and the candidate registration data of local node
+ params:
+	1. pubkey of the candidate account, optional, candidate data is omitted if absent
+ return: enode url, node id and the data used by account_candidateCredit
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_nodeAddress","params":["0x020e233ebaed5ade5e48d7ee7a999e173df054321f4ddaebecdb61756f8a43e91c"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"enode":"enode://3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0@149.129.172.91:55555","id":"3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0","candidate":"{\"Pubkey\":\"0x020e233ebaed5ade5e48d7ee7a999e173df054321f4ddaebecdb61756f8a43e91c\",\"Node\":\"enode://3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0@149.129.172.91:55555\"}"}}
+*/
+func (adminApi *AdminApi) NodeAddress(pubkey *secp256k1.PublicKey) (*NodeAddress, error) {
+	return newNodeAddress(adminApi.p2pService.LocalNode(), pubkey)
+}
+
+/*
+ name: natStatus
+ usage: Get the NAT port mapping state, include the external ip reported by the gateway, mapped ports and lease expiry
+ params:
+ return: nat mapper status
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_natStatus","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"interface":"UPnP(IGDv1-IP1)","externalIP":"149.129.172.91","mappings":[{"protocol":"tcp","extPort":55555,"intPort":55555,"name":"ethereum p2p","mapped":true,"expiry":"2020-06-20T10:20:00+08:00","nextRenew":"2020-06-20T10:15:00+08:00","failures":0}]}}
+*/
+func (adminApi *AdminApi) NatStatus() (*nat.Status, error) {
+	return adminApi.p2pService.NATStatus()
+}
+
+/*
+ name: natRemap
+ usage: Refresh the NAT port mappings and the external ip immediately
+ params:
+ return: nat mapper status after refresh
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_natRemap","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"interface":"UPnP(IGDv1-IP1)","externalIP":"149.129.172.91","mappings":[...]}}
+*/
+func (adminApi *AdminApi) NatRemap() (*nat.Status, error) {
+	return adminApi.p2pService.NATRemap()
+}
+
+/*
+ name: allowlist
+ usage: Get the node ids allowed to connect in permissioned mode, grouped by source (file, chain)
+ params:
+ return: allowed node ids of every source
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_allowlist","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"chain":["3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0"],"file":["9064107749f41ffffd9177f27af7bb854d702d930462c4be2d91d1772b3f03f3"]}}
+*/
+func (adminApi *AdminApi) Allowlist() (map[string][]enode.ID, error) {
+	return adminApi.p2pService.Allowlist()
+}
+
+/*
+ name: reloadAllowlist
+ usage: Reload the allowlist file now, connected peers that are no longer allowed are dropped
+ params:
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_reloadAllowlist","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *AdminApi) ReloadAllowlist() error {
+	if !adminApi.p2pService.Permissioned() {
+		return p2p.ErrNotPermissioned
+	}
+	return adminApi.p2pService.ReloadAllowlist()
+}
+
+/*
+name: Admin peer management Api
+usage: Manage the peers of local node, not public: served over ipc, or over http and websocket only when the admin module is enabled explicitly
+prefix:admin
+*/
+type PeerAdminApi struct {
+	p2pService *P2pService
+}
+
+/*
+ name: addPeer
+ usage: Connect a peer and keep the connection, it is dialed again when dropped
+ params:
+	1. enode://publickey@ip:p2p-port
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_addPeer","params":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *PeerAdminApi) AddPeer(url string) error {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return err
+	}
+	adminApi.p2pService.server.AddPeer(node)
+	return nil
+}
+
+/*
+ name: removePeer
+ usage: Disconnect a peer added by addPeer and stop dialing it
+ params:
+	1. enode://publickey@ip:p2p-port
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_removePeer","params":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *PeerAdminApi) RemovePeer(url string) error {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return err
+	}
+	adminApi.p2pService.server.RemovePeer(node)
+	return nil
+}
+
+/*
+ name: addTrustedPeer
+ usage: Trust a peer, it is always accepted even when the peer slots are full
+ params:
+	1. enode://publickey@ip:p2p-port
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_addTrustedPeer","params":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *PeerAdminApi) AddTrustedPeer(url string) error {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return err
+	}
+	adminApi.p2pService.server.AddTrustedPeer(node)
+	return nil
+}
+
+/*
+ name: removeTrustedPeer
+ usage: Stop trusting a peer, it is not disconnected
+ params:
+	1. enode://publickey@ip:p2p-port
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_removeTrustedPeer","params":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *PeerAdminApi) RemoveTrustedPeer(url string) error {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return err
+	}
+	adminApi.p2pService.server.RemoveTrustedPeer(node)
+	return nil
+}
+
+/*
+ name: peers
+ usage: Get the connected peers with their protocols, addresses and traffic
+ params:
+ return: connected peers
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_peers","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":[{"enode":"enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555","id":"9d3c7e1ac1e8a1fc2b7a0b13b7c4c8c3e0c8a1e5f6b7a8d9c0e1f2a3b4c5d6e7","name":"drep","caps":["blockMgr/2"],"network":{"localAddress":"192.168.74.2:50712","remoteAddress":"192.168.74.1:55555","inbound":false,"trusted":false,"static":true},"protocols":{}}]}
+*/
+func (adminApi *PeerAdminApi) Peers() []*p2p.PeerInfo {
+	return adminApi.p2pService.server.PeersInfo()
+}
+
+/*
+ name: nodeInfo
+ usage: Get the information of local node: enode url, listening addresses, protocols and clock drift
+ params:
+ return: local node information
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_nodeInfo","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"id":"3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0","name":"drep","enode":"enode://3f05da2475bf09ce20b790d76b42450996bc1d3c113a1848be1960171f9851c0@149.129.172.91:55555","ip":"149.129.172.91","ports":{"discovery":55555,"listener":55555},"listenAddr":"[::]:55555","protocols":{}}}
+*/
+func (adminApi *PeerAdminApi) NodeInfo() *p2p.NodeInfo {
+	return adminApi.p2pService.server.NodeInfo()
+}
+
+/*
+ name: discoveryHealth
+ usage: Get the state of the discovery table: nodes of each bucket, last refresh time and ping/pong counters
+ params:
+ return: discovery table state
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_discoveryHealth","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"nodes":1,"seeds":2,"foreign":0,"initialized":true,"lastRefresh":1592365562,"pingsSent":36,"pingsReceived":12,"pongsReceived":30,"pingTimeouts":6,"buckets":[{"distance":255,"entries":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"],"replacements":0}]}}
+*/
+func (adminApi *PeerAdminApi) DiscoveryHealth() (*discover.Health, error) {
+	return adminApi.p2pService.server.DiscoveryHealth()
+}
+
+/*
+ name: discoveryLookup
+ usage: Run a lookup for a random target immediately, instead of waiting for the next refresh
+ params:
+ return: nodes found
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_discoveryLookup","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"]}
+*/
+func (adminApi *PeerAdminApi) DiscoveryLookup() ([]*enode.Node, error) {
+	return adminApi.p2pService.server.DiscoveryLookup()
+}
+
+/*
+ name: discoveryBond
+ usage: Ping a node over the discovery protocol, it is added to the discovery table when it answers
+ params:
+	1. enode://publickey@ip:p2p-port
+ return: nil
+ example:  curl http://127.0.0.1:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_discoveryBond","params":["enode://e1b2f83b7b0f5845cc74ca12bb40152e520842bbd0597b7770cb459bd40f109178811ebddd6d640100cdb9b661a3a43a9811d9fdc63770032a3f2524257fb62d@192.168.74.1:55555"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *PeerAdminApi) DiscoveryBond(url string) error {
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return err
+	}
+	return adminApi.p2pService.server.DiscoveryBond(node)
+}
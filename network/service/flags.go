@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/drep-project/DREP-Chain/common/ntpclock"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	DiscoveryDNSFlag = cli.StringFlag{
+		Name:  "discovery.dns",
+		Usage: "comma separated DNS node tree urls (enrtree://<key>@<domain>) used for peer discovery",
+	}
+	PermissionedFlag = cli.BoolFlag{
+		Name:  "p2p.permissioned",
+		Usage: "only accept and dial nodes contained in the allowlist file or the on-chain producer registry",
+	}
+	PeerExchangeFlag = cli.BoolFlag{
+		Name:  "p2p.pex",
+		Usage: "share signed endpoints of connected peers with peers, for networks where discovery UDP is blocked",
+	}
+	AllowlistFileFlag = cli.StringFlag{
+		Name:  "p2p.allowlist",
+		Usage: "json file listing the enode urls or node ids allowed in permissioned mode, reloaded on change",
+	}
+	SentryNodesFlag = cli.StringFlag{
+		Name:  "p2p.sentries",
+		Usage: "comma separated enode urls of the sentry nodes hiding this producer, only they are connected and consensus messages are relayed through them",
+	}
+	PrivateNodesFlag = cli.StringFlag{
+		Name:  "p2p.private",
+		Usage: "comma separated enode urls of the producers this sentry node protects, they are trusted and never advertised",
+	}
+	NTPServersFlag = cli.StringFlag{
+		Name:  "ntp.servers",
+		Usage: "comma separated NTP servers sampled to measure the drift of the system clock",
+	}
+	NTPMaxDriftFlag = cli.IntFlag{
+		Name:  "ntp.maxdrift",
+		Usage: "milliseconds of system clock drift block production is refused over",
+		Value: ntpclock.DefaultMaxDrift,
+	}
+	NTPDisableFlag = cli.BoolFlag{
+		Name:  "ntp.disable",
+		Usage: "do not measure the system clock drift, blocks are produced whatever the clock",
+	}
+)
@@ -2,8 +2,10 @@ package service
 
 import (
 	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/network/p2p"
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/nat"
 )
 
 type P2P interface {
@@ -14,6 +16,19 @@ type P2P interface {
 	AddPeer(nodeUrl string) error
 	RemovePeer(url string)
 	AddProtocols(protocols []p2p.Protocol)
+	SetChainId(chainId uint64)
+	SetGenesis(hash crypto.Hash)
+	SetForks(forks []uint64)
+	SetNodeRole(role string)
+	SetChainHead(lowest, highest uint64)
 	LocalNode() *enode.Node
+	NATStatus() (*nat.Status, error)
+	NATRemap() (*nat.Status, error)
+	Permissioned() bool
+	SetAllowedNodes(source string, ids []enode.ID) error
+	Allowlist() (map[string][]enode.ID, error)
+	ReloadAllowlist() error
+	SentryMode() bool
+	Sentry() bool
 	//SubscribeEvents(ch chan *p2p.PeerEvent) event.Subscription
 }
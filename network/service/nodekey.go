@@ -0,0 +1,164 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	p2pTypes "github.com/drep-project/DREP-Chain/network/types"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	NodeIPFlag = cli.StringFlag{
+		Name:  "nodeip",
+		Usage: "public ip of the node used in enode url",
+		Value: "127.0.0.1",
+	}
+	NodePortFlag = cli.IntFlag{
+		Name:  "nodeport",
+		Usage: "p2p port of the node used in enode url",
+		Value: 55555,
+	}
+	CandidatePubkeyFlag = cli.StringFlag{
+		Name:  "pubkey",
+		Usage: "pubkey of the candidate account, used to build the candidate registration data",
+	}
+
+	ErrNodeKeyNotFound = errors.New("node key not found, run `nodekey generate` first")
+	ErrNodeKeyExist    = errors.New("node key already exist")
+)
+
+// NodeAddress is the identity of local node and the data needed to register it as candidate
+type NodeAddress struct {
+	Enode     string `json:"enode"`
+	ID        string `json:"id"`
+	Candidate string `json:"candidate,omitempty"` //json of types.CandidateData, used by account_candidateCredit
+}
+
+// newNodeAddress build node address, the candidate data is only filled if pubkey is given
+func newNodeAddress(node *enode.Node, pubkey *secp256k1.PublicKey) (*NodeAddress, error) {
+	addr := &NodeAddress{
+		Enode: node.String(),
+		ID:    node.ID().String(),
+	}
+	if pubkey != nil {
+		cd := &types.CandidateData{Pubkey: pubkey, Node: addr.Enode}
+		data, err := cd.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		addr.Candidate = string(data)
+	}
+	return addr, nil
+}
+
+func nodekeyCommand() cli.Command {
+	flags := []cli.Flag{NodeIPFlag, NodePortFlag, CandidatePubkeyFlag}
+	return cli.Command{
+		Name:     "nodekey",
+		Usage:    "Manage the p2p node key",
+		Category: "P2P COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "show",
+				Usage:  "Print the enode url, node id and candidate registration data of the node key",
+				Flags:  flags,
+				Action: showNodeKey,
+			},
+			{
+				Name:   "generate",
+				Usage:  "Generate a node key if absent and print its enode url, node id and candidate registration data",
+				Flags:  flags,
+				Action: generateNodeKey,
+			},
+		},
+	}
+}
+
+// nodekeyConfig return the p2p config whose data dir is the home dir of app
+func nodekeyConfig(ctx *cli.Context) *p2pTypes.P2pConfig {
+	homeDir := common.AppDataDir(ctx.App.Name, false)
+	if ctx.GlobalIsSet(app.HomeDirFlag.Name) {
+		homeDir = ctx.GlobalString(app.HomeDirFlag.Name)
+	}
+	config := *p2pTypes.DefaultP2pConfig
+	config.DataDir = homeDir
+	return &config
+}
+
+func showNodeKey(ctx *cli.Context) error {
+	config := nodekeyConfig(ctx)
+	if _, err := os.Stat(config.NodeKeyPath()); os.IsNotExist(err) {
+		return ErrNodeKeyNotFound
+	}
+	key, err := config.LoadPrivateKey()
+	if err != nil {
+		return err
+	}
+	return printNodeKey(ctx, key)
+}
+
+func generateNodeKey(ctx *cli.Context) error {
+	config := nodekeyConfig(ctx)
+	keyfile := config.NodeKeyPath()
+	if _, err := os.Stat(keyfile); err == nil {
+		return ErrNodeKeyExist
+	}
+	key, err := crypto.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyfile), 0700); err != nil {
+		return err
+	}
+	if err := crypto.SaveECDSA(keyfile, key); err != nil {
+		return err
+	}
+	fmt.Println("node key saved to", keyfile)
+	return printNodeKey(ctx, key)
+}
+
+func printNodeKey(ctx *cli.Context, key *secp256k1.PrivateKey) error {
+	ip := net.ParseIP(ctx.String(NodeIPFlag.Name))
+	if ip == nil {
+		return fmt.Errorf("invalid node ip %s", ctx.String(NodeIPFlag.Name))
+	}
+	port := ctx.Int(NodePortFlag.Name)
+	node := enode.NewV4(key.PubKey(), ip, port, port)
+
+	var pubkey *secp256k1.PublicKey
+	if ctx.IsSet(CandidatePubkeyFlag.Name) {
+		pkBytes, err := hex.DecodeString(strings.TrimPrefix(ctx.String(CandidatePubkeyFlag.Name), "0x"))
+		if err != nil {
+			return err
+		}
+		pubkey, err = secp256k1.ParsePubKey(pkBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	addr, err := newNodeAddress(node, pubkey)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(addr, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
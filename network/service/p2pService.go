@@ -2,11 +2,15 @@ package service
 
 import (
 	"path"
+	"strings"
 
 	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/common/ntpclock"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/network/p2p"
 	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/nat"
 	p2pTypes "github.com/drep-project/DREP-Chain/network/types"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -22,6 +26,7 @@ type P2pService struct {
 	outQuene chan *outMessage //Before the message is sent, it enters this cache
 	quit     chan struct{}
 	server   *p2p.Server //The underlying p2p manager
+	clock    *ntpclock.Monitor
 }
 
 type outMessage struct {
@@ -40,7 +45,7 @@ func (p2pService *P2pService) Api() []app.API {
 }
 
 func (p2pService *P2pService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{}
+	return []cli.Command{nodekeyCommand()}, []cli.Flag{DiscoveryDNSFlag, PeerExchangeFlag, PermissionedFlag, AllowlistFileFlag, SentryNodesFlag, PrivateNodesFlag, NTPServersFlag, NTPMaxDriftFlag, NTPDisableFlag}
 }
 
 func NewP2pService(config *p2pTypes.P2pConfig, homeDir string) *P2pService {
@@ -70,6 +75,23 @@ func NewP2pService(config *p2pTypes.P2pConfig, homeDir string) *P2pService {
 			},
 			Public: true,
 		},
+	app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &AdminApi{
+				p2pService: p2pService,
+			},
+			Public: true,
+		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &PeerAdminApi{
+				p2pService: p2pService,
+			},
+			//served over ipc, or over http and websocket only when admin is in the modules
+			Public: false,
+		},
 	}
 	return p2pService
 }
@@ -92,10 +114,59 @@ func (p2pService *P2pService) Init(executeContext *app.ExecuteContext) error {
 	//if p2pService.Config.NodeDatabase == "" {
 	p2pService.Config.NodeDatabase = path.Join(executeContext.CommonConfig.HomeDir, "drepnode", "peersnode")
 	//}
+	if executeContext.Cli.GlobalIsSet(DiscoveryDNSFlag.Name) {
+		p2pService.Config.DiscoveryDNS = nil
+		for _, url := range strings.Split(executeContext.Cli.GlobalString(DiscoveryDNSFlag.Name), ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				p2pService.Config.DiscoveryDNS = append(p2pService.Config.DiscoveryDNS, url)
+			}
+		}
+	}
+
+	if executeContext.Cli.GlobalIsSet(PeerExchangeFlag.Name) {
+		p2pService.Config.PeerExchange = executeContext.Cli.GlobalBool(PeerExchangeFlag.Name)
+	}
+	if executeContext.Cli.GlobalIsSet(PermissionedFlag.Name) {
+		p2pService.Config.Permissioned = executeContext.Cli.GlobalBool(PermissionedFlag.Name)
+	}
+	if executeContext.Cli.GlobalIsSet(AllowlistFileFlag.Name) {
+		p2pService.Config.AllowlistFile = executeContext.Cli.GlobalString(AllowlistFileFlag.Name)
+	}
+	if executeContext.Cli.GlobalIsSet(SentryNodesFlag.Name) {
+		nodes, err := parseNodes(executeContext.Cli.GlobalString(SentryNodesFlag.Name))
+		if err != nil {
+			return err
+		}
+		p2pService.Config.SentryNodes = nodes
+	}
+	if executeContext.Cli.GlobalIsSet(PrivateNodesFlag.Name) {
+		nodes, err := parseNodes(executeContext.Cli.GlobalString(PrivateNodesFlag.Name))
+		if err != nil {
+			return err
+		}
+		p2pService.Config.PrivateNodes = nodes
+	}
+	if p2pService.Config.Permissioned {
+		p2pService.Config.NodeAllowlist = p2p.NewNodeAllowlist()
+	}
+	p2pService.setupClock(executeContext.Cli)
+	p2pService.quit = make(chan struct{})
 
 	p2pService.server = &p2p.Server{
 		Config: p2pService.Config.Config,
 	}
+	if p2pService.Config.Permissioned {
+		if err := p2pService.ReloadAllowlist(); err != nil {
+			return err
+		}
+	}
+	app.RegisterMaintenance(MODULENAME, app.MaintenanceRefuse, func() error {
+		p2pService.server.SetRefuseInbound(true)
+		return nil
+	}, func() {
+		p2pService.server.SetRefuseInbound(false)
+	})
+	app.RegisterStatus(MODULENAME, p2pService.status)
 
 	p2pService.apis = []app.API{
 		app.API{
@@ -106,17 +177,116 @@ func (p2pService *P2pService) Init(executeContext *app.ExecuteContext) error {
 			},
 			Public: true,
 		},
+	app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &AdminApi{
+				p2pService: p2pService,
+			},
+			Public: true,
+		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service: &PeerAdminApi{
+				p2pService: p2pService,
+			},
+			//served over ipc, or over http and websocket only when admin is in the modules
+			Public: false,
+		},
 	}
 	return nil
 }
 
+// parseNodes parse comma separated enode urls
+func parseNodes(urls string) ([]*enode.Node, error) {
+	nodes := []*enode.Node{}
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url == "" {
+			continue
+		}
+		n := &enode.Node{}
+		if err := n.UnmarshalText([]byte(url)); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// SentryMode reports whether this producer is hidden behind sentry nodes, it only talks to them
+func (p2pService *P2pService) SentryMode() bool {
+	return p2pService.server.SentryMode()
+}
+
+// Sentry reports whether this node protects producers, it relays their consensus messages
+func (p2pService *P2pService) Sentry() bool {
+	return len(p2pService.server.PrivateNodes) > 0
+}
+
+// setupClock create the monitor of the clock drift of config, overridden by the command line flags
+func (p2pService *P2pService) setupClock(ctx *cli.Context) {
+	config := &p2pService.Config.NTP
+	if ctx.GlobalIsSet(NTPServersFlag.Name) {
+		config.Servers = nil
+		for _, server := range strings.Split(ctx.GlobalString(NTPServersFlag.Name), ",") {
+			if server = strings.TrimSpace(server); server != "" {
+				config.Servers = append(config.Servers, server)
+			}
+		}
+	}
+	if ctx.GlobalIsSet(NTPMaxDriftFlag.Name) {
+		config.MaxDrift = ctx.GlobalInt(NTPMaxDriftFlag.Name)
+	}
+	if ctx.GlobalIsSet(NTPDisableFlag.Name) {
+		config.Disable = ctx.GlobalBool(NTPDisableFlag.Name)
+	}
+	if config.Disable {
+		return
+	}
+	p2pService.clock = ntpclock.NewMonitor(*config)
+	ntpclock.SetDefault(p2pService.clock)
+}
+
 func (p2pService *P2pService) AddProtocols(protocols []p2p.Protocol) {
 	p2pService.server.ProtocolsBlockChan = append(p2pService.server.ProtocolsBlockChan, protocols[:len(protocols)]...)
 }
 
+// SetChainId set chain id exchanged in handshake, it must be called before start
+func (p2pService *P2pService) SetChainId(chainId uint64) {
+	p2pService.server.ChainId = chainId
+}
+
+// SetGenesis sets the genesis hash advertised in the node record
+func (p2pService *P2pService) SetGenesis(hash crypto.Hash) {
+	p2pService.server.SetGenesis(hash)
+}
+
+// SetForks sets the fork heights the fork identifier of the node record is derived from
+func (p2pService *P2pService) SetForks(forks []uint64) {
+	p2pService.server.SetForks(forks)
+}
+
+// SetNodeRole sets the role (producer, full, light) advertised in the node record
+func (p2pService *P2pService) SetNodeRole(role string) {
+	p2pService.server.SetNodeRole(role)
+}
+
+// SetChainHead sets the block heights advertised in the node record
+func (p2pService *P2pService) SetChainHead(lowest, highest uint64) {
+	p2pService.server.SetChainHead(lowest, highest)
+}
+
 func (p2pService *P2pService) Start(executeContext *app.ExecuteContext) error {
 	p2pService.server.Start()
+	if p2pService.clock != nil {
+		p2pService.clock.Start()
+	}
 	go p2pService.sendMessageRoutine()
+	go p2pService.watchPeerFiles()
+	if p2pService.Permissioned() {
+		go p2pService.watchAllowlist()
+	}
 	return nil
 }
 
@@ -125,6 +295,9 @@ func (p2pService *P2pService) Stop(executeContext *app.ExecuteContext) error {
 		return nil
 	}
 	p2pService.server.Stop()
+	if p2pService.clock != nil {
+		p2pService.clock.Stop()
+	}
 	if p2pService.quit != nil {
 		close(p2pService.quit)
 	}
@@ -205,3 +378,11 @@ func (p2pService *P2pService) RemovePeer(nodeUrl string) {
 func (p2pService *P2pService) LocalNode() *enode.Node {
 	return p2pService.server.LocalNode()
 }
+
+func (p2pService *P2pService) NATStatus() (*nat.Status, error) {
+	return p2pService.server.NATStatus()
+}
+
+func (p2pService *P2pService) NATRemap() (*nat.Status, error) {
+	return p2pService.server.NATRemap()
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+)
+
+const (
+	staticNodesFile         = "static-nodes.json"
+	trustedNodesFile        = "trusted-nodes.json"
+	peerFilesReloadInterval = 10 * time.Second
+)
+
+// peerFile is a json list of enode urls in the datadir, the nodes listed are
+// added to the server and the ones removed from the file are removed again.
+type peerFile struct {
+	name    string
+	add     func(node *enode.Node)
+	remove  func(node *enode.Node)
+	applied map[enode.ID]*enode.Node
+
+	modTime time.Time
+	size    int64
+}
+
+// parsePeerFile parses a json list of enode urls.
+func parsePeerFile(data []byte) ([]*enode.Node, error) {
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, err
+	}
+	nodes := make([]*enode.Node, 0, len(urls))
+	for _, url := range urls {
+		n, err := enode.ParseV4(strings.TrimSpace(url))
+		if err != nil {
+			return nil, fmt.Errorf("invalid enode url %q: %v", url, err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
+
+// path returns the path of the file in the datadir.
+func (file *peerFile) path(dataDir string) string {
+	return filepath.Join(dataDir, file.name)
+}
+
+// reload reads the file and applies the difference with the nodes applied
+// before. A missing file lists no node, an invalid one is ignored.
+func (file *peerFile) reload(dataDir string) error {
+	path := file.path(dataDir)
+	var nodes []*enode.Node
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if nodes, err = parsePeerFile(data); err != nil {
+			return fmt.Errorf("peer file %s: %v", path, err)
+		}
+	}
+
+	listed := make(map[enode.ID]*enode.Node, len(nodes))
+	for _, n := range nodes {
+		listed[n.ID()] = n
+	}
+	added, removed := 0, 0
+	for id, n := range file.applied {
+		if _, ok := listed[id]; !ok {
+			file.remove(n)
+			removed++
+		}
+	}
+	for id, n := range listed {
+		if _, ok := file.applied[id]; !ok {
+			file.add(n)
+			added++
+		}
+	}
+	file.applied = listed
+	if added > 0 || removed > 0 {
+		log.WithField("file", path).WithField("added", added).WithField("removed", removed).Info("Applied peer file")
+	}
+	return nil
+}
+
+// changed reports whether the modification time or size of the file changed
+// since the last call.
+func (file *peerFile) changed(dataDir string) bool {
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(file.path(dataDir)); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	if modTime.Equal(file.modTime) && size == file.size {
+		return false
+	}
+	file.modTime, file.size = modTime, size
+	return true
+}
+
+// watchPeerFiles applies the static and trusted node files of the datadir and
+// applies them again whenever they change, so peers are managed without
+// restarting the node.
+func (p2pService *P2pService) watchPeerFiles() {
+	files := []*peerFile{
+		{name: staticNodesFile, add: p2pService.server.AddPeer, remove: p2pService.server.RemovePeer},
+		{name: trustedNodesFile, add: p2pService.server.AddTrustedPeer, remove: p2pService.server.RemoveTrustedPeer},
+	}
+	dataDir := p2pService.Config.DataDir
+	apply := func() {
+		for _, file := range files {
+			if !file.changed(dataDir) {
+				continue
+			}
+			if err := file.reload(dataDir); err != nil {
+				log.WithField("err", err).Error("Reload peer file")
+			}
+		}
+	}
+
+	apply()
+	ticker := time.NewTicker(peerFilesReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			apply()
+		case <-p2pService.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+// P2pStatus is the p2p section of the node status
+type P2pStatus struct {
+	Enode        string   `json:"enode"`
+	ListenAddr   string   `json:"listenAddr"`
+	ChainId      uint64   `json:"chainId"`
+	Role         string   `json:"role"`
+	MaxPeers     int      `json:"maxPeers"`
+	NoDiscovery  bool     `json:"noDiscovery"`
+	PeerExchange bool     `json:"peerExchange"`
+	Permissioned bool     `json:"permissioned"`
+	Caps         []string `json:"caps"` //Protocols offered in the handshake as name/version
+}
+
+func (p2pService *P2pService) status() interface{} {
+	server := p2pService.server
+	entry := server.ChainEntry()
+	status := &P2pStatus{
+		Enode:        server.Self().String(),
+		ListenAddr:   server.ListenAddr,
+		ChainId:      entry.ChainId,
+		Role:         entry.Role,
+		MaxPeers:     server.MaxPeers,
+		NoDiscovery:  server.NoDiscovery,
+		PeerExchange: server.PeerExchange,
+		Permissioned: p2pService.Permissioned(),
+		Caps:         []string{},
+	}
+	for _, cap := range server.Caps() {
+		status.Caps = append(status.Caps, cap.String())
+	}
+	return status
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/drep-project/DREP-Chain/common/ntpclock"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/network/p2p"
@@ -119,6 +120,15 @@ type Config struct {
 type P2pConfig struct {
 	p2p.Config
 	DataDir string `json:",omitempty"`
+
+	// AllowlistFile is a json list of enode urls or node ids allowed to
+	// connect in permissioned mode. Relative paths are resolved against
+	// the home directory. The file is reloaded when it changes.
+	AllowlistFile string `json:",omitempty"`
+
+	// NTP configures the measurement of the local clock drift, block
+	// production is refused while it is over the max drift.
+	NTP ntpclock.Config `json:",omitempty"`
 }
 
 var (
@@ -190,6 +200,19 @@ func (c *P2pConfig) instanceDir() string {
 	return filepath.Join(c.DataDir, c.name())
 }
 
+// NodeKeyPath returns the path of the persisted node key
+func (c *P2pConfig) NodeKeyPath() string {
+	return c.ResolvePath(datadirPrivateKey)
+}
+
+// LoadPrivateKey loads the persisted node key, unlike GeneratePrivateKey no key is created if absent
+func (c *P2pConfig) LoadPrivateKey() (*secp256k1.PrivateKey, error) {
+	if c.PrivateKey != nil {
+		return c.PrivateKey, nil
+	}
+	return crypto.LoadECDSA(c.NodeKeyPath())
+}
+
 func (c *P2pConfig) GeneratePrivateKey() *secp256k1.PrivateKey {
 	// Use any specifically configured key.
 	if c.PrivateKey != nil {
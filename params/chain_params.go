@@ -9,6 +9,9 @@ const (
 
 	AliasGas uint64 = 68 // gas Use when alias a address
 
+	TokenIssueGas uint64 = 50000 // gas Use when issue a native token
+	TokenGas      uint64 = 21000 // gas Use when mint, burn or transfer a native token
+
 	//GasLimitBoundDivisor uint64 = 64       // The bound divisor of the gas limit, used in update calculations.
 	MinGasLimit     uint64 = 18000000 // Minimum the gas limit may ever be.
 	GenesisGasLimit uint64 = 18000000 // Gas limit of the Genesis block.
@@ -21,3 +21,30 @@ func Test_Generate(t *testing.T) {
 	fmt.Println("Atom：", generator.ToAtom())
 	fmt.Println("Tron：", generator.ToTron())
 }
+
+func Test_Registry(t *testing.T) {
+	pri, _ := secp256k1.GeneratePrivateKey(nil)
+	if len(Chains()) != 9 {
+		t.Fatalf("%d chains registered by default, expected 9", len(Chains()))
+	}
+	if err := Register(NewCodec(Bitcoin, ethAddress)); err != ErrCodecExist {
+		t.Fatalf("codec of bitcoin replaced, %v", err)
+	}
+	if _, err := Encode("polkadot", pri.PubKey()); err != ErrUnknownChain {
+		t.Fatalf("unknown chain encoded, %v", err)
+	}
+
+	codec := NewCodec("polkadot", func(pubKey *secp256k1.PublicKey) (string, error) {
+		return "dot", nil
+	})
+	if err := Register(codec); err != nil {
+		t.Fatal(err)
+	}
+	generator := &AddrGenerate{PrivateKey: pri}
+	if addr, err := generator.Generate("polkadot"); err != nil || addr != "dot" {
+		t.Fatalf("address of registered chain %s, %v", addr, err)
+	}
+	if generator.ToEth() == "" || generator.ToBtc() == "" {
+		t.Fatal("empty address of default chains")
+	}
+}
@@ -0,0 +1,123 @@
+package addrgenerator
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+)
+
+// names of the chains with a codec registered by default
+const (
+	Bitcoin  = "bitcoin"
+	Ethereum = "ethereum"
+	Neo      = "neo"
+	Ripple   = "ripple"
+	Dash     = "dash"
+	Dogecoin = "dogecoin"
+	Litecoin = "litecoin"
+	Cosmos   = "cosmos"
+	Tron     = "tron"
+)
+
+var (
+	ErrUnknownChain   = errors.New("no address codec for chain")
+	ErrCodecExist     = errors.New("address codec of chain already registered")
+	ErrEmptyChainName = errors.New("chain name of codec is empty")
+)
+
+// Codec encodes the public key of a drep account as an address of another chain. A chain is
+// supported by registering its codec
+type Codec interface {
+	// Chain is the name the codec is registered and looked up by
+	Chain() string
+	Encode(pubKey *secp256k1.PublicKey) (string, error)
+}
+
+type codecFunc struct {
+	chain  string
+	encode func(pubKey *secp256k1.PublicKey) (string, error)
+}
+
+// NewCodec make a codec of chain from its encode function
+func NewCodec(chain string, encode func(pubKey *secp256k1.PublicKey) (string, error)) Codec {
+	return &codecFunc{chain: chain, encode: encode}
+}
+
+func (codec *codecFunc) Chain() string {
+	return codec.chain
+}
+
+func (codec *codecFunc) Encode(pubKey *secp256k1.PublicKey) (string, error) {
+	return codec.encode(pubKey)
+}
+
+var (
+	codecs    = map[string]Codec{}
+	codecLock sync.RWMutex
+)
+
+func init() {
+	for _, codec := range []Codec{
+		NewCodec(Bitcoin, altcoinEncoder("Bitcoin")),
+		NewCodec(Ethereum, ethAddress),
+		NewCodec(Neo, neoAddress),
+		NewCodec(Ripple, rippleAddress),
+		NewCodec(Dash, altcoinEncoder("Dash")),
+		NewCodec(Dogecoin, altcoinEncoder("Dogecoin")),
+		NewCodec(Litecoin, altcoinEncoder("Litecoin")),
+		NewCodec(Cosmos, cosmosAddress),
+		NewCodec(Tron, tronAddress),
+	} {
+		if err := Register(codec); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register add the codec of a chain, a chain has only one codec
+func Register(codec Codec) error {
+	if codec.Chain() == "" {
+		return ErrEmptyChainName
+	}
+	codecLock.Lock()
+	defer codecLock.Unlock()
+	if _, ok := codecs[codec.Chain()]; ok {
+		return ErrCodecExist
+	}
+	codecs[codec.Chain()] = codec
+	return nil
+}
+
+// Lookup return the codec of chain
+func Lookup(chain string) (Codec, error) {
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+	codec, ok := codecs[chain]
+	if !ok {
+		return nil, ErrUnknownChain
+	}
+	return codec, nil
+}
+
+// Chains list the chains with a codec, sorted by name
+func Chains() []string {
+	codecLock.RLock()
+	defer codecLock.RUnlock()
+	chains := make([]string, 0, len(codecs))
+	for chain := range codecs {
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// Encode the public key as an address of chain
+func Encode(chain string, pubKey *secp256k1.PublicKey) (string, error) {
+	codec, err := Lookup(chain)
+	if err != nil {
+		return "", err
+	}
+	return codec.Encode(pubKey)
+}
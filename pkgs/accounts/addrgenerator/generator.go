@@ -3,7 +3,6 @@ package addrgenerator
 import (
 	"crypto/ecdsa"
 	"crypto/sha256"
-	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -16,22 +15,70 @@ import (
 	"math/big"
 )
 
+// AddrGenerate encodes the public key of a private key as the addresses of the registered chains
 type AddrGenerate struct {
 	PrivateKey *secp256k1.PrivateKey
 }
 
+// Generate return the address of chain
+func (addrGenerate *AddrGenerate) Generate(chain string) (string, error) {
+	return Encode(chain, addrGenerate.PrivateKey.PubKey())
+}
+
+func (addrGenerate *AddrGenerate) generate(chain string) string {
+	addr, _ := addrGenerate.Generate(chain)
+	return addr
+}
+
 func (addrGenerate *AddrGenerate) ToEth() string {
-	pk := addrGenerate.PrivateKey.PubKey()
-	ecdsaPk := (*ecdsa.PublicKey)(pk)
-	return ethcrypto.PubkeyToAddress(*ecdsaPk).String()
+	return addrGenerate.generate(Ethereum)
 }
 func (addrGenerate *AddrGenerate) ToRipple() string {
-	bytes := rippleCrypto.Sha256RipeMD160(addrGenerate.PrivateKey.PubKey().SerializeCompressed())
-	hash, _ := rippleCrypto.NewAccountId(bytes)
-	return hash.String()
+	return addrGenerate.generate(Ripple)
 }
 func (addrGenerate *AddrGenerate) ToNeo() string {
-	pub_bytes := addrGenerate.PrivateKey.PubKey().Serialize()
+	return addrGenerate.generate(Neo)
+}
+
+func (addrGenerate *AddrGenerate) ToLiteCoin() string {
+	return addrGenerate.generate(Litecoin)
+}
+
+func (addrGenerate *AddrGenerate) ToDogecoin() string {
+	return addrGenerate.generate(Dogecoin)
+}
+
+func (addrGenerate *AddrGenerate) ToDash() string {
+	return addrGenerate.generate(Dash)
+}
+
+func (addrGenerate *AddrGenerate) ToAtom() string {
+	return addrGenerate.generate(Cosmos)
+}
+
+func (addrGenerate *AddrGenerate) ToTron() string {
+	return addrGenerate.generate(Tron)
+}
+
+func (addrGenerate *AddrGenerate) ToBtc() string {
+	return addrGenerate.generate(Bitcoin)
+}
+
+func ethAddress(pubKey *secp256k1.PublicKey) (string, error) {
+	return ethcrypto.PubkeyToAddress(*(*ecdsa.PublicKey)(pubKey)).String(), nil
+}
+
+func rippleAddress(pubKey *secp256k1.PublicKey) (string, error) {
+	bytes := rippleCrypto.Sha256RipeMD160(pubKey.SerializeCompressed())
+	hash, err := rippleCrypto.NewAccountId(bytes)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func neoAddress(pubKey *secp256k1.PublicKey) (string, error) {
+	pub_bytes := pubKey.Serialize()
 
 	pub_bytes = append([]byte{0x21}, pub_bytes...)
 	pub_bytes = append(pub_bytes, 0xAC)
@@ -49,40 +96,22 @@ func (addrGenerate *AddrGenerate) ToNeo() string {
 	pub_hash_2 := ripemd160_h.Sum(nil)
 
 	program_hash := pub_hash_2
-	return addrGenerate.b58checkencodeNEO(0x17, program_hash)
-}
-
-func (addrGenerate *AddrGenerate) ToLiteCoin() string {
-	coin := getCoin("Litecoin")
-	return genCoin(addrGenerate.PrivateKey, coin.PubKeyHashAddrID, coin.PrivateKeyID, coin.Name)
-}
-
-func (addrGenerate *AddrGenerate) ToDogecoin() string {
-	coin := getCoin("Dogecoin")
-	return genCoin(addrGenerate.PrivateKey, coin.PubKeyHashAddrID, coin.PrivateKeyID, coin.Name)
-}
-
-func (addrGenerate *AddrGenerate) ToDash() string {
-	coin := getCoin("Dash")
-	return genCoin(addrGenerate.PrivateKey, coin.PubKeyHashAddrID, coin.PrivateKeyID, coin.Name)
+	return b58checkencodeNEO(0x17, program_hash), nil
 }
 
-func (addrGenerate *AddrGenerate) ToAtom() string {
-	pubKey := addrGenerate.PrivateKey.PubKey()
-	addr := sdk.AccAddress(pubKey.Serialize())
-	return addr.String()
+func cosmosAddress(pubKey *secp256k1.PublicKey) (string, error) {
+	return sdk.AccAddress(pubKey.Serialize()).String(), nil
 }
 
-func (addrGenerate *AddrGenerate) ToTron() string {
-	return addressFromKey(addrGenerate.PrivateKey)
-}
-
-func (addrGenerate *AddrGenerate) ToBtc() string {
-	coin := getCoin("Bitcoin")
-	return genCoin(addrGenerate.PrivateKey, coin.PubKeyHashAddrID, coin.PrivateKeyID, coin.Name)
+// altcoinEncoder encode the addresses of a bitcoin like coin in altcoins
+func altcoinEncoder(name string) func(pubKey *secp256k1.PublicKey) (string, error) {
+	return func(pubKey *secp256k1.PublicKey) (string, error) {
+		coin := getCoin(name)
+		return genCoin(pubKey, coin.PubKeyHashAddrID)
+	}
 }
 
-func (addrGenerate *AddrGenerate) b58checkencodeNEO(ver uint8, b []byte) (s string) {
+func b58checkencodeNEO(ver uint8, b []byte) (s string) {
 	/* Prepend version */
 	bcpy := append([]byte{ver}, b...)
 
@@ -142,22 +171,20 @@ func b58encode(b []byte) (s string) {
 	return s
 }
 
-func genCoin(pk *secp256k1.PrivateKey, PubKeyHashAddrID, PrivateKeyID byte, name string) string {
-	net := &chaincfg.MainNetParams
+func genCoin(pubKey *secp256k1.PublicKey, PubKeyHashAddrID byte) (string, error) {
+	// copy the params, the main net of bitcoin is shared
+	net := chaincfg.MainNetParams
 	net.PubKeyHashAddrID = PubKeyHashAddrID
-	net.PrivateKeyID = PrivateKeyID
-	edsaPriv := (*ecdsa.PrivateKey)(pk)
-	btcPriv := (*btcec.PrivateKey)(edsaPriv)
-	wif, _ := btcutil.NewWIF(btcPriv, net, true)
-	addr, _ := btcutil.NewAddressPubKey(wif.PrivKey.PubKey().SerializeCompressed(), net)
-	return addr.EncodeAddress()
+	addr, err := btcutil.NewAddressPubKey(pubKey.SerializeCompressed(), &net)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeAddress(), nil
 }
 
-func addressFromKey(secpKey *secp256k1.PrivateKey) string {
-	// Build the Private Key and extract the Public Key
-	key := (*ecdsa.PrivateKey)(secpKey)
+func tronAddress(pubKey *secp256k1.PublicKey) (string, error) {
 	// #1
-	pub := append(key.X.Bytes(), key.Y.Bytes()...)
+	pub := pubKey.SerializeUncompressed()[1:]
 
 	// #2
 	hash := sha3.NewLegacyKeccak256()
@@ -177,5 +204,5 @@ func addressFromKey(secpKey *secp256k1.PrivateKey) string {
 	rawAddr := append(addr41, checksum...)
 	tronAddr := base58.Encode(rawAddr)
 
-	return tronAddr
+	return tronAddr, nil
 }
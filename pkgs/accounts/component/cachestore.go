@@ -42,6 +42,18 @@ func (cacheStore *CacheStore) GetKey(addr *crypto.CommonAddress) (*types.Node, e
 	return nil, ErrKeyNotExistOrUnlock
 }
 
+// Addresses return the addresses of the keys in cache, which are the unlocked accounts
+func (cacheStore *CacheStore) Addresses() []crypto.CommonAddress {
+	cacheStore.rlock.RLock()
+	defer cacheStore.rlock.RUnlock()
+
+	addrs := make([]crypto.CommonAddress, 0, len(cacheStore.nodes))
+	for _, node := range cacheStore.nodes {
+		addrs = append(addrs, *node.Address)
+	}
+	return addrs
+}
+
 func (cacheStore *CacheStore) ListAddr(auth string) ([]string, error) {
 	return cacheStore.store.ExportAddrs(auth)
 }
@@ -114,13 +114,16 @@ func aesCTRXOR(key, inText, iv []byte) ([]byte, error) {
 	return outText, err
 }
 
-// BytesToCryptoNode cocnvert given bytes and password to a node
+// BytesToCryptoNode cocnvert given bytes and password to a node, keystore v3 json is accepted too
 func BytesToCryptoNode(data []byte, auth string) (node *types.Node, errRef error) {
 	defer func() {
 		if err := recover(); err != nil {
 			errRef = ErrDecryptFail
 		}
 	}()
+	if IsKeyStoreV3(data) {
+		return DecryptKeyV3(data, auth)
+	}
 
 	cryptoNode := new(CryptedNode)
 
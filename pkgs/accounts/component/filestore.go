@@ -6,7 +6,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/drep-project/DREP-Chain/common/fileutil"
 	"github.com/drep-project/DREP-Chain/crypto"
@@ -124,15 +123,12 @@ func (fs FileStore) ExportKey(auth string) ([]*types.Node, error) {
 			return false, err
 		}
 
-		fmt.Println("e 0:", time.Now().Unix(), time.Now().Nanosecond())
-
 		node, err := BytesToCryptoNode(contents, auth)
 		if err != nil {
 			log.WithField("Msg", err).Error("read key store error ", "Msg", err.Error())
 			return false, err
 		}
 		persistedNodes = append(persistedNodes, node)
-		fmt.Println("e 1:", time.Now().Unix(), time.Now().Nanosecond())
 		return true, nil
 
 	})
@@ -0,0 +1,194 @@
+package component
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	crypto2 "github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/types"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeyStoreV3Version is the version of the Web3 secret storage format
+	KeyStoreV3Version = 3
+
+	keyHeaderKDFPbkdf2 = "pbkdf2"
+	pbkdf2PRF          = "hmac-sha256"
+)
+
+// KeyStoreV3 is the Web3 secret storage (keystore v3) json, the format Ethereum
+// wallets and tools read and write
+type KeyStoreV3 struct {
+	Address string       `json:"address"`
+	Crypto  CryptoJSONV3 `json:"crypto"`
+	Id      string       `json:"id"`
+	Version int          `json:"version"`
+}
+
+type CryptoJSONV3 struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams CipherParamsV3         `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type CipherParamsV3 struct {
+	IV string `json:"iv"`
+}
+
+// IsKeyStoreV3 reports whether data looks like a keystore v3 json
+func IsKeyStoreV3(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	probe := struct {
+		Version int `json:"version"`
+	}{}
+	return json.Unmarshal(data, &probe) == nil && probe.Version == KeyStoreV3Version
+}
+
+// EncryptKeyV3 encrypt the private key of node into a keystore v3 json with scrypt
+func EncryptKeyV3(node *types.Node, auth string, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(auth), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], node.PrivateKey.Serialize(), iv)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return nil, err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40 // uuid version 4
+	id[8] = (id[8] & 0x3f) | 0x80 // uuid variant 10
+
+	addr := crypto2.PubkeyToAddress(node.PrivateKey.PubKey())
+	return json.Marshal(&KeyStoreV3{
+		Address: hex.EncodeToString(addr.Bytes()),
+		Crypto: CryptoJSONV3{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: CipherParamsV3{IV: hex.EncodeToString(iv)},
+			KDF:          keyHeaderKDF,
+			KDFParams: map[string]interface{}{
+				"n":     scryptN,
+				"r":     scryptR,
+				"p":     scryptP,
+				"dklen": scryptDKLen,
+				"salt":  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(sha3.Keccak256(derivedKey[16:32], cipherText)),
+		},
+		Id:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: KeyStoreV3Version,
+	})
+}
+
+// DecryptKeyV3 decrypt a keystore v3 json with scrypt or pbkdf2 key derivation. The
+// node carries no chain id or chain code, the v3 format has neither
+func DecryptKeyV3(data []byte, auth string) (*types.Node, error) {
+	keyStore := &KeyStoreV3{}
+	if err := json.Unmarshal(data, keyStore); err != nil {
+		return nil, err
+	}
+	if keyStore.Version != KeyStoreV3Version {
+		return nil, fmt.Errorf("keystore version not supported: %v", keyStore.Version)
+	}
+	privD, err := decryptKeyV3(&keyStore.Crypto, auth)
+	if err != nil {
+		return nil, err
+	}
+	priv, pub := secp256k1.PrivKeyFromScalar(privD)
+	addr := crypto2.PubkeyToAddress(pub)
+	if keyStore.Address != "" && !strings.EqualFold(strings.TrimPrefix(keyStore.Address, "0x"), hex.EncodeToString(addr.Bytes())) {
+		return nil, fmt.Errorf("key content mismatch: have address %x, want %s", addr.Bytes(), keyStore.Address)
+	}
+	return &types.Node{
+		Address:    &addr,
+		PrivateKey: priv,
+	}, nil
+}
+
+func decryptKeyV3(cryptoJSON *CryptoJSONV3, auth string) ([]byte, error) {
+	if cryptoJSON.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("Cipher not supported: %v", cryptoJSON.Cipher)
+	}
+	cipherText, err := hex.DecodeString(cryptoJSON.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(cryptoJSON.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(cryptoJSON.MAC)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := getKDFKeyV3(cryptoJSON, auth)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sha3.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	return aesCTRXOR(derivedKey[:16], cipherText, iv)
+}
+
+func getKDFKeyV3(cryptoJSON *CryptoJSONV3, auth string) ([]byte, error) {
+	params := cryptoJSON.KDFParams
+	salt, err := hex.DecodeString(stringParam(params, "salt"))
+	if err != nil {
+		return nil, err
+	}
+	dkLen := intParam(params, "dklen")
+	if dkLen < 32 {
+		return nil, fmt.Errorf("KDF dklen too short: %d", dkLen)
+	}
+
+	switch cryptoJSON.KDF {
+	case keyHeaderKDF:
+		return scrypt.Key([]byte(auth), salt, intParam(params, "n"), intParam(params, "r"), intParam(params, "p"), dkLen)
+	case keyHeaderKDFPbkdf2:
+		if prf := stringParam(params, "prf"); prf != pbkdf2PRF {
+			return nil, fmt.Errorf("PRF not supported: %v", prf)
+		}
+		return pbkdf2.Key([]byte(auth), salt, intParam(params, "c"), dkLen, sha256.New), nil
+	}
+	return nil, fmt.Errorf("KDF not supported: %v", cryptoJSON.KDF)
+}
+
+func intParam(params map[string]interface{}, name string) int {
+	val, _ := params[name].(float64)
+	return int(val)
+}
+
+func stringParam(params map[string]interface{}, name string) string {
+	val, _ := params[name].(string)
+	return val
+}
@@ -0,0 +1,64 @@
+package component
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// pbkdf2 test vector of the Web3 secret storage definition
+const v3Pbkdf2Vector = `{
+	"crypto": {
+		"cipher": "aes-128-ctr",
+		"cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+		"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+		"kdf": "pbkdf2",
+		"kdfparams": {"c": 262144, "dklen": 32, "prf": "hmac-sha256", "salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},
+		"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+	},
+	"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+	"version": 3
+}`
+
+func TestDecryptKeyV3Vector(t *testing.T) {
+	if !IsKeyStoreV3([]byte(v3Pbkdf2Vector)) {
+		t.Fatal("vector not detected as keystore v3")
+	}
+	if _, err := DecryptKeyV3([]byte(v3Pbkdf2Vector), "wrongpassword"); err != ErrDecrypt {
+		t.Fatalf("got %v, want %v", err, ErrDecrypt)
+	}
+	node, err := BytesToCryptoNode([]byte(v3Pbkdf2Vector), "testpassword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := hex.EncodeToString(node.PrivateKey.Serialize()); key != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Fatalf("got private key %s", key)
+	}
+}
+
+func TestKeyStoreV3RoundTrip(t *testing.T) {
+	priv, _ := secp256k1.GeneratePrivateKey(nil)
+	addr := crypto.PubkeyToAddress(priv.PubKey())
+	node := &types.Node{Address: &addr, PrivateKey: priv}
+
+	data, err := EncryptKeyV3(node, "123", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsKeyStoreV3(data) {
+		t.Fatal("export not detected as keystore v3")
+	}
+	decrypted, err := DecryptKeyV3(data, "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decrypted.Address != addr || !decrypted.PrivateKey.PubKey().IsEqual(priv.PubKey()) {
+		t.Fatal("round trip key mismatch")
+	}
+	if _, err := DecryptKeyV3(data, "456"); err != ErrDecrypt {
+		t.Fatalf("got %v, want %v", err, ErrDecrypt)
+	}
+}
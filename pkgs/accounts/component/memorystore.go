@@ -14,7 +14,7 @@ type MemoryStore struct {
 func (mstore *MemoryStore) ExportAddrs(auth string) ([]string, error) {
 	addrs := make([]string, 0)
 	mstore.keys.Range(func(key, value interface{}) bool {
-		addrs = append(addrs, value.(types.Node).Address.String())
+		addrs = append(addrs, value.(*types.Node).Address.String())
 		return true
 	})
 	return addrs, nil
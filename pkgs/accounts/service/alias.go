@@ -0,0 +1,52 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/pkg/errors"
+)
+
+// AddressOrAlias is an address parameter of the account rpc, either a hex address or
+// an alias registered on chain with account_setAlias
+type AddressOrAlias string
+
+// ResolveAddress turn an address or alias into the address. Aliases are looked up in the
+// state of the best chain tip, or asked to the upstream in wallet-only mode
+func (accountService *AccountService) ResolveAddress(param AddressOrAlias) (*crypto.CommonAddress, error) {
+	input := strings.TrimSpace(string(param))
+	if crypto.IsHexAddress(input) {
+		addr := crypto.HexToAddress(input)
+		return &addr, nil
+	}
+	if err := chain.CheckAlias([]byte(input)); err != nil {
+		return nil, errors.Wrapf(ErrInvalidAddress, "%s", input)
+	}
+
+	if accountService.upstream != nil {
+		addr := &crypto.CommonAddress{}
+		if err := accountService.upstream.Call(addr, "chain_getAddressByAlias", input); err != nil {
+			return nil, err
+		}
+		return addr, nil
+	}
+	trieQuery, err := chain.NewTrieQuery(accountService.DatabaseService.LevelDb(), accountService.Chain.BestChain().Tip().StateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return trieQuery.AliasGet(input)
+}
+
+// resolveAddresses resolve several address parameters, stopping at the first failure
+func (accountService *AccountService) resolveAddresses(params ...AddressOrAlias) ([]*crypto.CommonAddress, error) {
+	addrs := make([]*crypto.CommonAddress, len(params))
+	for i, param := range params {
+		addr, err := accountService.ResolveAddress(param)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
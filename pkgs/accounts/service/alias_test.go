@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestResolveHexAddress(t *testing.T) {
+	accountService := &AccountService{}
+	want := crypto.HexToAddress("0x3ebcbe7cb440dd8c52940a2963472380afbb56c5")
+	for _, input := range []AddressOrAlias{
+		"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",
+		"3ebcbe7cb440dd8c52940a2963472380afbb56c5",
+		" 0x3EBCBE7CB440DD8C52940A2963472380AFBB56C5 ",
+	} {
+		addr, err := accountService.ResolveAddress(input)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if *addr != want {
+			t.Fatalf("%s: got %s, want %s", input, addr.String(), want.String())
+		}
+	}
+}
+
+func TestResolveInvalidAlias(t *testing.T) {
+	accountService := &AccountService{}
+	for _, input := range []AddressOrAlias{"", "tom", "has space", "0x3ebcbe7c!", "waytoolongaliasforthechain"} {
+		if _, err := accountService.ResolveAddress(input); errors.Cause(err) != ErrInvalidAddress {
+			t.Fatalf("%q: got %v, want %v", input, err, ErrInvalidAddress)
+		}
+	}
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"bytes"
-	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/params"
 	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
 	"math/big"
+	"time"
 
 	"github.com/drep-project/DREP-Chain/blockmgr"
 	"github.com/drep-project/DREP-Chain/common"
@@ -17,6 +18,7 @@ import (
 	"github.com/drep-project/DREP-Chain/pkgs/accounts/addrgenerator"
 	"github.com/drep-project/DREP-Chain/pkgs/evm"
 	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
 )
 
 /*
@@ -30,6 +32,7 @@ type AccountApi struct {
 	accountService     *AccountService
 	poolQuery          blockmgr.IBlockMgrPool
 	messageBroadCastor blockmgr.ISendMessage
+	nonces             *blockmgr.NonceManager
 	databaseService    *database.DatabaseService
 }
 
@@ -91,38 +94,52 @@ func (accountapi *AccountApi) CreateWallet(password string) error {
  name: lockAccount
  usage: Lock the account
  params:
+	1. The account address, or its alias
  return:  Failure returns the reason for the error, and success returns no information
  example:   curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_lockAccount","params":["0x518b3fefa3fb9a72753c6ad10a2b68cc034ec391"], "id": 3}' -H "Content-Type:application/json"
  response:
 	 {"jsonrpc":"2.0","id":3,"result":null}
 */
-func (accountapi *AccountApi) LockAccount(addr crypto.CommonAddress) error {
+func (accountapi *AccountApi) LockAccount(address AddressOrAlias) error {
 	if !accountapi.Wallet.IsOpen() {
 		return ErrClosedWallet
 	}
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return err
+	}
 
-	return accountapi.Wallet.Lock(&addr)
+	return accountapi.Wallet.Lock(addr)
 
 	return ErrLockedWallet
 }
 
 /*
  name: account_unlockAccount
- usage: Unlock the account
+ usage: Unlock the account, optionally for a number of seconds
  params:
-	1. The account address
+	1. The account address, or its alias
 	2. password
+	3. seconds to keep the account unlocked (optional, omitted or 0 until locked or idle)
  return: Failure returns the reason for the error, and success returns no information
- example:   curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_unlockAccount","params":["0x518b3fefa3fb9a72753c6ad10a2b68cc034ec391", "123456"], "id": 3}' -H "Content-Type:application/json"
+ example:   curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_unlockAccount","params":["0x518b3fefa3fb9a72753c6ad10a2b68cc034ec391", "123456", 300], "id": 3}' -H "Content-Type:application/json"
  response:
 	 {"jsonrpc":"2.0","id":3,"result":null}
 */
-func (accountapi *AccountApi) UnlockAccount(addr crypto.CommonAddress, password string) error {
+func (accountapi *AccountApi) UnlockAccount(address AddressOrAlias, password string, seconds *uint64) error {
 	if !accountapi.Wallet.IsOpen() {
 		return ErrClosedWallet
 	}
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return err
+	}
 
-	return accountapi.Wallet.UnLock(&addr, password)
+	duration := time.Duration(0)
+	if seconds != nil {
+		duration = time.Duration(*seconds) * time.Second
+	}
+	return accountapi.Wallet.UnLockFor(addr, password, duration)
 }
 
 /*
@@ -156,8 +173,8 @@ func (accountapi *AccountApi) CloseWallet() {
  name: transfer
  usage: transfer
  params:
-	1. The address at which the transfer was initiated
-	2. Recipient's address
+	1. The address at which the transfer was initiated, or its alias
+	2. Recipient's address, or its alias
 	3. Mount
 	4. gas price
 	5. gas limit
@@ -167,18 +184,23 @@ func (accountapi *AccountApi) CloseWallet() {
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) Transfer(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data common.Bytes) (string, error) {
+func (accountapi *AccountApi) Transfer(from, to AddressOrAlias, amount, gasprice, gaslimit *common.Big, data common.Bytes) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
 	if gasprice.ToInt().Uint64() < blockmgr.DefaultGasPrice {
 		gasprice.SetMathBig(*new(big.Int).SetUint64(blockmgr.DefaultGasPrice))
 	}
 
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
-	tx := types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	tx := types.NewTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -190,8 +212,8 @@ func (accountapi *AccountApi) Transfer(from crypto.CommonAddress, to crypto.Comm
  name: transferWithNonce
  usage: transfer with nonce
  params:
-	1. The address at which the transfer was initiated
-	2. Recipient's address
+	1. The address at which the transfer was initiated, or its alias
+	2. Recipient's address, or its alias
 	3. Mount
 	4. gas price
 	5. gas limit
@@ -202,14 +224,18 @@ func (accountapi *AccountApi) Transfer(from crypto.CommonAddress, to crypto.Comm
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) TransferWithNonce(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data common.Bytes, nonce uint64) (string, error) {
-	//nonce := accountapi.poolQuery.GetTransactionCount(&from)
-	tx := types.NewTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+func (accountapi *AccountApi) TransferWithNonce(from, to AddressOrAlias, amount, gasprice, gaslimit *common.Big, data common.Bytes, nonce uint64) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	//nonce := accountapi.poolQuery.GetTransactionCount(fromAddr)
+	tx := types.NewTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -217,11 +243,337 @@ func (accountapi *AccountApi) TransferWithNonce(from crypto.CommonAddress, to cr
 	return tx.TxHash().String(), nil
 }
 
+/*
+ name: transferSponsored
+ usage: Transfer from an account whose gas is paid by another local account, the sender may have no balance besides the amount
+ params:
+	1. The address at which the transfer was initiated, or its alias
+	2. Recipient's address, or its alias
+	3. The address paying the gas, or its alias
+	4. Mount
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_transferSponsored","params":["0x8a8e541ddd1272d53729164c70197221a3c27486","0x3296d3336895b5baaa0eca3df911741bd0681c3f","0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x0","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) TransferSponsored(from, to, feePayer AddressOrAlias, amount, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to, feePayer)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr, payerAddr := addrs[0], addrs[1], addrs[2]
+	if gasprice.ToInt().Uint64() < blockmgr.DefaultGasPrice {
+		gasprice.SetMathBig(*new(big.Int).SetUint64(blockmgr.DefaultGasPrice))
+	}
+
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	tx := types.NewTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	if err := accountapi.Wallet.SignSponsoredTransaction(fromAddr, payerAddr, tx); err != nil {
+		return "", err
+	}
+	if err := accountapi.Wallet.SponsorTransaction(payerAddr, tx); err != nil {
+		return "", err
+	}
+	if err := accountapi.messageBroadCastor.SendTransaction(tx, true); err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: sponsorTransaction
+ usage: Sign a transaction signed by its sender elsewhere for this fee payer and send it, the fee payer pays the gas. Sponsored transactions are valid from sponsorFork of genesis on
+ params:
+	1. The address paying the gas, or its alias
+	2. A transaction whose sender signed its sponsored hash, keccak256("sponsored", tx hash, fee payer address)
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_sponsorTransaction","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (accountapi *AccountApi) SponsorTransaction(feePayer AddressOrAlias, txbytes common.Bytes) (string, error) {
+	payerAddr, err := accountapi.accountService.ResolveAddress(feePayer)
+	if err != nil {
+		return "", err
+	}
+	tx := &types.Transaction{}
+	if err := binary.Unmarshal(txbytes, tx); err != nil {
+		return "", err
+	}
+	if _, err := tx.From(); err != nil {
+		return "", types.ErrNotSigned
+	}
+	if err := accountapi.Wallet.SponsorTransaction(payerAddr, tx); err != nil {
+		return "", err
+	}
+	if err := accountapi.messageBroadCastor.SendTransaction(tx, true); err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: pendingNonce
+ usage: The nonce the next transaction of a local account is assigned, skipping nonces used by transactions being sent and filling gaps in the pool first
+ params:
+	1. address, or its alias
+ return: nonce
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_pendingNonce","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":3}
+*/
+func (accountapi *AccountApi) PendingNonce(addr AddressOrAlias) (uint64, error) {
+	address, err := accountapi.accountService.ResolveAddress(addr)
+	if err != nil {
+		return 0, err
+	}
+	return accountapi.nonces.Pending(address), nil
+}
+
+/*
+ name: resetNonce
+ usage: Drop the nonces reserved by transactions being sent from a local account, the next nonce is derived from the pool again
+ params:
+	1. address, or its alias
+ return: the nonce the next transaction is assigned
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_resetNonce","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":3}
+*/
+func (accountapi *AccountApi) ResetNonce(addr AddressOrAlias) (uint64, error) {
+	address, err := accountapi.accountService.ResolveAddress(addr)
+	if err != nil {
+		return 0, err
+	}
+	if gaps := accountapi.nonces.Gaps(address); len(gaps) > 0 {
+		log.WithField("addr", address.String()).WithField("gaps", gaps).Info("nonce gaps in pool")
+	}
+	return accountapi.nonces.Reset(address), nil
+}
+
+/*
+ name: scheduleTx
+ usage: Broadcast a signed transaction once the time and the block height reach its activation condition, schedules are kept across restarts
+ params:
+	1. A signed transaction
+	2. Not before this unix time in seconds, 0 for no time condition
+	3. Not before this block height, 0 for no height condition
+ return: the scheduled transaction
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_scheduleTx","params":["0x40a287b6d30b05313131317a4120dd8c23c40910d038fa43b2f8932d3681cbe5ee3079b6e9de0bea6e8e6b2a867a561aa26e1cd6b62aa0422a043186b593b784bf80845c3fd5a7fbfe62e61d8564",0,12000],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"hash":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","from":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","nonce":3,"height":12000,"created":1559322808,"raw":"0x40a287b6d30b..."}}
+*/
+func (accountapi *AccountApi) ScheduleTx(txbytes common.Bytes, notBefore, height uint64) (*ScheduledTx, error) {
+	tx := &types.Transaction{}
+	if err := binary.Unmarshal(txbytes, tx); err != nil {
+		return nil, err
+	}
+	return accountapi.accountService.scheduler.add(tx, notBefore, height)
+}
+
+/*
+ name: listScheduledTx
+ usage: List the transactions waiting for their activation condition, with the error of the last failed send if any
+ params:
+ return: scheduled transactions, oldest first
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_listScheduledTx","params":[],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":[{"hash":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","from":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","nonce":3,"height":12000,"created":1559322808,"raw":"0x40a287b6d30b..."}]}
+*/
+func (accountapi *AccountApi) ListScheduledTx() []*ScheduledTx {
+	return accountapi.accountService.scheduler.list()
+}
+
+/*
+ name: cancelScheduledTx
+ usage: Cancel a scheduled transaction which is not broadcasted yet
+ params:
+	1. transaction hash
+ return: error if not scheduled
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_cancelScheduledTx","params":["0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":null}
+*/
+func (accountapi *AccountApi) CancelScheduledTx(hash crypto.Hash) error {
+	return accountapi.accountService.scheduler.cancel(hash)
+}
+
+/*
+ name: exportPairing
+ usage: Run on the offline signer, export its accounts for an online watcher node, each signed by its key
+ params:
+	1. addresses of the accounts, or their aliases
+	2. optional file the pairing is written to
+ return: pairing blob
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_exportPairing","params":[["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"],"/media/usb/pairing.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x0100000001..."}
+*/
+func (accountapi *AccountApi) ExportPairing(addrs []AddressOrAlias, path *string) (string, error) {
+	resolved, err := accountapi.accountService.resolveAddresses(addrs...)
+	if err != nil {
+		return "", err
+	}
+	blob, err := accountapi.accountService.exportPairing(resolved)
+	if err != nil {
+		return "", err
+	}
+	return blob, writeBlob(path, blob)
+}
+
+/*
+ name: importPairing
+ usage: Run on the online watcher, watch the accounts exported by an offline signer, signing requests can be created for them
+ params:
+	1. pairing blob, or the file it was written to
+ return: addresses of the paired accounts
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_importPairing","params":["/media/usb/pairing.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"]}
+*/
+func (accountapi *AccountApi) ImportPairing(pairing string) ([]crypto.CommonAddress, error) {
+	return accountapi.accountService.importPairing(pairing)
+}
+
+/*
+ name: listPaired
+ usage: List the accounts paired with an offline signer
+ return: addresses of the paired accounts
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_listPaired","params":[],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"]}
+*/
+func (accountapi *AccountApi) ListPaired() []crypto.CommonAddress {
+	return accountapi.accountService.signing.pairedAddresses()
+}
+
+/*
+ name: createSigningRequest
+ usage: Run on the online watcher, build a transfer of a paired account for the offline signer, its nonce stays reserved until the signature is submitted or the request canceled
+ params:
+	1. paired address, or its alias
+	2. Recipient's address, or its alias
+	3. amount
+	4. gas price
+	5. gas limit
+	6. data
+	7. optional file the request is written to
+ return: the signing request, encoded is the blob for the signer
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_createSigningRequest","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","0x111","0x110","0x30000","","/media/usb/request.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"hash":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","from":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","to":"0x8a8e541ddd1272d53729164c70197221a3c27486","type":0,"amount":"0x111","gasPrice":"0x110","gasLimit":"0x30000","nonce":3,"created":1559322808,"raw":"0x0100...","encoded":"0x0200..."}}
+*/
+func (accountapi *AccountApi) CreateSigningRequest(from, to AddressOrAlias, amount, gasprice, gaslimit *common.Big, data common.Bytes, path *string) (*SigningRequest, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return nil, err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	request, err := accountapi.accountService.createSigningRequest(fromAddr, func(nonce uint64) *types.Transaction {
+		tx := types.NewTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+		tx.Data.Data = data
+		return tx
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, writeBlob(path, request.Encoded)
+}
+
+/*
+ name: listSigningRequests
+ usage: List the requests waiting for the signature of the offline signer, oldest first
+ return: signing requests
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_listSigningRequests","params":[],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":[{"hash":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","from":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","nonce":3,"encoded":"0x0200..."}]}
+*/
+func (accountapi *AccountApi) ListSigningRequests() ([]*SigningRequest, error) {
+	requests := accountapi.accountService.signing.list()
+	for i, request := range requests {
+		encoded, err := request.encoded()
+		if err != nil {
+			return nil, err
+		}
+		requests[i] = encoded
+	}
+	return requests, nil
+}
+
+/*
+ name: cancelSigningRequest
+ usage: Drop a request waiting for the offline signer and release its nonce
+ params:
+	1. hash of the request
+ return: null
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_cancelSigningRequest","params":["0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":null}
+*/
+func (accountapi *AccountApi) CancelSigningRequest(hash crypto.Hash) error {
+	return accountapi.accountService.cancelSigningRequest(hash)
+}
+
+/*
+ name: inspectSigningRequest
+ usage: Run on the offline signer, decode a signing request so the transaction is reviewed before it is signed
+ params:
+	1. request blob, or the file it was written to
+ return: the signing request
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_inspectSigningRequest","params":["/media/usb/request.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"hash":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538","from":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","to":"0x8a8e541ddd1272d53729164c70197221a3c27486","type":0,"amount":"0x111","gasPrice":"0x110","gasLimit":"0x30000","nonce":3,"created":1559322808,"raw":"0x0100..."}}
+*/
+func (accountapi *AccountApi) InspectSigningRequest(request string) (*SigningRequest, error) {
+	inspected, _, err := accountapi.accountService.inspectSigningRequest(request)
+	return inspected, err
+}
+
+/*
+ name: signRequest
+ usage: Run on the offline signer, sign a signing request with the key of its account
+ params:
+	1. request blob, or the file it was written to
+	2. optional file the signature is written to
+ return: signature blob
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_signRequest","params":["/media/usb/request.txt","/media/usb/signature.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x03f30e8586..."}
+*/
+func (accountapi *AccountApi) SignRequest(request string, path *string) (string, error) {
+	blob, err := accountapi.accountService.signRequest(request)
+	if err != nil {
+		return "", err
+	}
+	return blob, writeBlob(path, blob)
+}
+
+/*
+ name: submitSignature
+ usage: Run on the online watcher, assemble the transaction of a request with the signature of the offline signer and broadcast it
+ params:
+	1. signature blob, or the file it was written to
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_submitSignature","params":["/media/usb/signature.txt"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0xf30e858667fa63bc57ae395c3f57ede9bb3ad4969d12f4bce51d900fb5931538"}
+*/
+func (accountapi *AccountApi) SubmitSignature(signature string) (string, error) {
+	hash, err := accountapi.accountService.submitSignature(signature)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
 /*
  name: setAlias
  usage: Set an alias
  params:
-	1. address
+	1. address, or its alias
 	2. alias
 	3. gas price
 	4. gas lowLimit
@@ -231,16 +583,19 @@ func (accountapi *AccountApi) TransferWithNonce(from crypto.CommonAddress, to cr
 response:
 	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
 */
-func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias string, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&srcAddr)
+func (accountapi *AccountApi) SetAlias(src AddressOrAlias, alias string, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(src)
+	if err != nil {
+		return "", err
+	}
+	srcAddr := addrs[0]
+	nonce := accountapi.nonces.Reserve(srcAddr)
+	defer accountapi.nonces.Release(srcAddr, nonce)
 	t := types.NewAliasTransaction(alias, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&srcAddr, t.TxHash().Bytes())
+	err = accountapi.Wallet.SignTransaction(srcAddr, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
-	fmt.Println(hex.EncodeToString(t.AsPersistentMessage()))
-	fmt.Println(t.TxHash().String())
 	err = accountapi.messageBroadCastor.SendTransaction(t, true)
 	if err != nil {
 		return "", err
@@ -252,8 +607,8 @@ func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias strin
  name: VoteCredit
  usage: vote credit to candidate
  params:
-	1. address of voter
-	2. address of candidate
+	1. address of voter, or its alias
+	2. address of candidate, or its alias
 	3. amount
 	4. gas price
 	5. gas uplimit of transaction
@@ -262,14 +617,19 @@ func (accountapi *AccountApi) SetAlias(srcAddr crypto.CommonAddress, alias strin
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) VoteCredit(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
-	tx := types.NewVoteTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+func (accountapi *AccountApi) VoteCredit(from, to AddressOrAlias, amount, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	tx := types.NewVoteTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -281,8 +641,8 @@ func (accountapi *AccountApi) VoteCredit(from crypto.CommonAddress, to crypto.Co
  name: CancelVoteCredit
  usage:
  params:
-	1. address of voter
-	2. address of candidate
+	1. address of voter, or its alias
+	2. address of candidate, or its alias
 	3. amount
 	4. gas price
 	5. gas limit
@@ -292,14 +652,19 @@ func (accountapi *AccountApi) VoteCredit(from crypto.CommonAddress, to crypto.Co
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) CancelVoteCredit(from crypto.CommonAddress, to crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
-	tx := types.NewCancelVoteTransaction(to, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+func (accountapi *AccountApi) CancelVoteCredit(from, to AddressOrAlias, amount, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	tx := types.NewCancelVoteTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -311,7 +676,7 @@ func (accountapi *AccountApi) CancelVoteCredit(from crypto.CommonAddress, to cry
  name: CandidateCredit
  usage: Candidate node pledge
  params:
-	1. The address of the pledger
+	1. The address of the pledger, or its alias
 	2. The pledge amount
 	3. gas price
 	4. gas limit
@@ -321,24 +686,29 @@ func (accountapi *AccountApi) CancelVoteCredit(from crypto.CommonAddress, to cry
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) CandidateCredit(from crypto.CommonAddress, amount, gasprice, gaslimit *common.Big, data string) (string, error) {
+func (accountapi *AccountApi) CandidateCredit(from AddressOrAlias, amount, gasprice, gaslimit *common.Big, data string) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from)
+	if err != nil {
+		return "", err
+	}
+	fromAddr := addrs[0]
 	cd := types.CandidateData{}
-	err := cd.Unmarshal([]byte(data))
+	err = cd.Unmarshal([]byte(data))
 	if err != nil {
 		return "", err
 	}
 
-	if !bytes.Equal(crypto.PubkeyToAddress(cd.Pubkey).Bytes(), from.Bytes()) {
+	if !bytes.Equal(crypto.PubkeyToAddress(cd.Pubkey).Bytes(), fromAddr.Bytes()) {
 		return "", nil
 	}
 
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
 	tx := types.NewCandidateTransaction((*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce, []byte(data))
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -350,7 +720,7 @@ func (accountapi *AccountApi) CandidateCredit(from crypto.CommonAddress, amount,
  name: CancelCandidateCredit
  usage: To cancel the candidate
  params:
-	1. The address at which the transfer was cancel
+	1. The address at which the transfer was cancel, or its alias
 	2. address of candidate
 	3. amount
 	4. gas price
@@ -361,14 +731,19 @@ func (accountapi *AccountApi) CandidateCredit(from crypto.CommonAddress, amount,
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
 */
-func (accountapi *AccountApi) CancelCandidateCredit(from crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+func (accountapi *AccountApi) CancelCandidateCredit(from AddressOrAlias, amount, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from)
+	if err != nil {
+		return "", err
+	}
+	fromAddr := addrs[0]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
 	tx := types.NewCancleCandidateTransaction((*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return "", err
 	}
-	tx.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(tx, true)
 	if err != nil {
 		return "", err
@@ -380,8 +755,8 @@ func (accountapi *AccountApi) CancelCandidateCredit(from crypto.CommonAddress, a
  name: readContract
  usage: Read smart contract (no data modified)
  params:
-    1. The account address of the transaction
-	2. Contract address
+    1. The account address of the transaction, or its alias
+	2. Contract address, or its alias
 	3. Contract api
  return: The query results
  example:
@@ -389,16 +764,70 @@ func (accountapi *AccountApi) CancelCandidateCredit(from crypto.CommonAddress, a
  response:
 	 {"jsonrpc":"2.0","id":1,"result":""}
 */
-func (accountapi *AccountApi) ReadContract(from, to crypto.CommonAddress, input common.Bytes) (common.Bytes, error) {
+func (accountapi *AccountApi) ReadContract(from, to AddressOrAlias, input common.Bytes) (common.Bytes, error) {
+	if accountapi.accountService.upstream != nil {
+		return nil, ErrUpstreamUnsupported
+	}
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return accountapi.readContract(addrs[0], addrs[1], input)
+}
+
+/*
+ name: callMethod
+ usage: Call a contract method without modifying data, the call data is encoded from the method signature and arguments, and the return values are decoded when the abi of the contract is registered with abi_register
+ params:
+	1. The account address of the transaction, or its alias
+	2. Contract address, or its alias
+	3. Method signature like transfer(address,uint256), or a method name of the registered abi
+	4. Arguments of the method, integers as numbers or strings, bytes and addresses as hex strings
+ return: The raw return data and the decoded return values
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_callMethod","params":["0xec61c03f719a5c214f60719c3f36bb362a202125","0xecfb51e10aa4c146bf6c12eee090339c99841efc","balanceOf(address)",["0xec61c03f719a5c214f60719c3f36bb362a202125"]],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"raw":"0x00000000000000000000000000000000000000000000000000000000000003e8","outputs":[1000]}}
+*/
+func (accountapi *AccountApi) CallMethod(from, to AddressOrAlias, method string, args []json.RawMessage) (*MethodResult, error) {
+	if accountapi.accountService.upstream != nil {
+		return nil, ErrUpstreamUnsupported
+	}
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return nil, err
+	}
+	contractMethod, err := accountapi.accountService.contractMethod(addrs[1], method)
+	if err != nil {
+		return nil, err
+	}
+	input, err := packMethodCall(contractMethod, args)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := accountapi.readContract(addrs[0], addrs[1], input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MethodResult{Raw: ret}
+	if len(contractMethod.Outputs) > 0 && len(ret) > 0 {
+		if result.Outputs, err = contractMethod.Outputs.UnpackValues(ret); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (accountapi *AccountApi) readContract(fromAddr, toAddr *crypto.CommonAddress, input common.Bytes) (common.Bytes, error) {
 	header := accountapi.EvmService.Chain.GetCurrentHeader()
-	tx := types.NewTransaction(to, new(big.Int).SetUint64(0), &big.Int{}, new(big.Int).SetUint64(params.MinGasLimit), 0)
+	tx := types.NewTransaction(*toAddr, new(big.Int).SetUint64(0), &big.Int{}, new(big.Int).SetUint64(params.MinGasLimit), 0)
 	tx.Data.Data = input
 
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err := accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return nil, err
 	}
-	tx.Sig = sig
 
 	trieStore, err := store.TrieStoreFromStore(accountapi.databaseService.LevelDb(), header.StateRoot)
 	if err != nil {
@@ -406,9 +835,6 @@ func (accountapi *AccountApi) ReadContract(from, to crypto.CommonAddress, input
 	}
 
 	ret, err := accountapi.EvmService.Call(trieStore, tx, header)
-	fmt.Println(string(common.Bytes(ret)))
-	fmt.Println(new(big.Int).SetBytes(ret))
-	fmt.Println(common.Bytes(ret))
 
 	return common.Bytes(ret), err
 }
@@ -417,30 +843,40 @@ func (accountapi *AccountApi) ReadContract(from, to crypto.CommonAddress, input
  name: estimateGas
  usage: Estimate how much gas is needed for the transaction
  params:
-	1. The address at which the transfer was initiated
+	1. The address at which the transfer was initiated, or its alias
 	2. amount
 	3. commit
-	4. Address of recipient
+	4. Address of recipient, or its alias
  return: Evaluate the result, failure returns an error
  example:
 	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_estimateGas","params":["0xec61c03f719a5c214f60719c3f36bb362a202125","0xecfb51e10aa4c146bf6c12eee090339c99841efc","0x6d4ce63c","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x5d74aba54ace5f01a5f0057f37bfddbbe646ea6de7265b368e2e7d17d9cdeb9c"}
 */
-func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *common.Big, data common.Bytes, to *crypto.CommonAddress) (uint64, error) {
+func (accountapi *AccountApi) EstimateGas(from AddressOrAlias, amount *common.Big, data common.Bytes, to *AddressOrAlias) (uint64, error) {
 	if amount.ToInt().Uint64() != 0 {
 		return params.MinGasLimit, nil
 	}
+	if accountapi.accountService.upstream != nil {
+		return 0, ErrUpstreamUnsupported
+	}
+	if to == nil {
+		return 0, ErrMissingAddress
+	}
+	addrs, err := accountapi.accountService.resolveAddresses(from, *to)
+	if err != nil {
+		return 0, err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
 
 	header := accountapi.EvmService.Chain.GetCurrentHeader()
-	tx := types.NewTransaction(*to, amount.ToInt(), new(big.Int).SetUint64(blockmgr.DefaultGasPrice), new(big.Int).SetUint64(params.MinGasLimit), 0)
+	tx := types.NewTransaction(*toAddr, amount.ToInt(), new(big.Int).SetUint64(blockmgr.DefaultGasPrice), new(big.Int).SetUint64(params.MinGasLimit), 0)
 	tx.Data.Data = data
 
-	sig, err := accountapi.Wallet.Sign(&from, tx.TxHash().Bytes())
+	err = accountapi.Wallet.SignTransaction(fromAddr, tx)
 	if err != nil {
 		return 0, err
 	}
-	tx.Sig = sig
 
 	trieStore, err := store.TrieStoreFromStore(accountapi.databaseService.LevelDb(), header.StateRoot)
 	if err != nil {
@@ -475,8 +911,8 @@ func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *com
  name: executeContract
  usage: Execute smart contract (cause data to be modified)
  params:
-	1. The address of the caller
-	2. Contract address
+	1. The address of the caller, or its alias
+	2. Contract address, or its alias
 	3. Contract code
 	3. gas price
 	4. gas limit
@@ -486,23 +922,69 @@ func (accountapi *AccountApi) EstimateGas(from crypto.CommonAddress, amount *com
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x5d74aba54ace5f01a5f0057f37bfddbbe646ea6de7265b368e2e7d17d9cdeb9c"}
 */
-func (accountapi *AccountApi) ExecuteContract(from crypto.CommonAddress, to crypto.CommonAddress, input common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
-	t := types.NewCallContractTransaction(to, input, &big.Int{}, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, t.TxHash().Bytes())
+func (accountapi *AccountApi) ExecuteContract(from, to AddressOrAlias, input common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	t := types.NewCallContractTransaction(*toAddr, input, &big.Int{}, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountapi.Wallet.SignTransaction(fromAddr, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
 	accountapi.messageBroadCastor.SendTransaction(t, true)
 	return t.TxHash().String(), nil
 }
 
+/*
+ name: sendPrivateTransaction
+ usage: Transfer or execute smart contract, the transaction is sent to the connected producers only instead of gossiped, so it is not seen before packed
+ params:
+	1. The address of the caller, or its alias
+	2. Recipient's or contract address, or its alias
+	3. Mount
+	4. Contract input, empty for a transfer
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_sendPrivateTransaction","params":["0xec61c03f719a5c214f60719c3f36bb362a202125","0xecfb51e10aa4c146bf6c12eee090339c99841efc","0x0","0x6d4ce63c","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x5d74aba54ace5f01a5f0057f37bfddbbe646ea6de7265b368e2e7d17d9cdeb9c"}
+*/
+func (accountapi *AccountApi) SendPrivateTransaction(from, to AddressOrAlias, amount *common.Big, input common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from, to)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], addrs[1]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
+	var t *types.Transaction
+	if len(input) == 0 {
+		t = types.NewTransaction(*toAddr, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	} else {
+		t = types.NewCallContractTransaction(*toAddr, input, (*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	}
+	err = accountapi.Wallet.SignTransaction(fromAddr, t)
+	if err != nil {
+		return "", err
+	}
+	err = accountapi.messageBroadCastor.SendPrivateTransaction(t)
+	if err != nil {
+		return "", err
+	}
+	return t.TxHash().String(), nil
+}
+
 /*
  name: createCode
  usage: Deployment of contract
  params:
-	1. The account address of the deployment contract
+	1. The account address of the deployment contract, or its alias
 	2. Content of the contract
 	3. gas price
 	4. gas limit
@@ -512,14 +994,19 @@ func (accountapi *AccountApi) ExecuteContract(from crypto.CommonAddress, to cryp
  response:
 	 {"jsonrpc":"2.0","id":1,"result":"0x9a8d8d5d7d00bbe0eb1b9431a13a7219008e352241b751b177bfb29e4e75b0d1"}
 */
-func (accountapi *AccountApi) CreateCode(from crypto.CommonAddress, byteCode common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
-	nonce := accountapi.poolQuery.GetTransactionCount(&from)
+func (accountapi *AccountApi) CreateCode(from AddressOrAlias, byteCode common.Bytes, gasprice, gaslimit *common.Big) (string, error) {
+	addrs, err := accountapi.accountService.resolveAddresses(from)
+	if err != nil {
+		return "", err
+	}
+	fromAddr := addrs[0]
+	nonce := accountapi.nonces.Reserve(fromAddr)
+	defer accountapi.nonces.Release(fromAddr, nonce)
 	t := types.NewContractTransaction(byteCode, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
-	sig, err := accountapi.Wallet.Sign(&from, t.TxHash().Bytes())
+	err = accountapi.Wallet.SignTransaction(fromAddr, t)
 	if err != nil {
 		return "", err
 	}
-	t.Sig = sig
 	err = accountapi.messageBroadCastor.SendTransaction(t, true)
 	if err != nil {
 		return "", err
@@ -531,13 +1018,13 @@ func (accountapi *AccountApi) CreateCode(from crypto.CommonAddress, byteCode com
  name: dumpPrivkey
  usage: The private key corresponding to the export address
  params:
-	1.address
+	1.address, or its alias
  return: private key
  example:   curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_dumpPrivkey","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"], "id": 3}' -H "Content-Type:application/json"
  response:
 	 {"jsonrpc":"2.0","id":3,"result":"0x270f4b122603999d1c07aec97e972a2ddf7bd8b5bfe3543c10814e6a19f13aaf"}
 */
-func (accountapi *AccountApi) DumpPrivkey(address *crypto.CommonAddress) (*secp256k1.PrivateKey, error) {
+func (accountapi *AccountApi) DumpPrivkey(address AddressOrAlias) (*secp256k1.PrivateKey, error) {
 	if !accountapi.Wallet.IsOpen() {
 		return nil, ErrClosedWallet
 	}
@@ -545,7 +1032,11 @@ func (accountapi *AccountApi) DumpPrivkey(address *crypto.CommonAddress) (*secp2
 		return nil, ErrLockedWallet
 	}
 
-	node, err := accountapi.Wallet.GetAccountByAddress(address)
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	node, err := accountapi.Wallet.GetAccountByAddress(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -556,13 +1047,13 @@ func (accountapi *AccountApi) DumpPrivkey(address *crypto.CommonAddress) (*secp2
  name: DumpPubkey
  usage: Export the public key corresponding to the address
  params:
-	1.address
+	1.address, or its alias
  return: public key
  example:   curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_dumpPubkey","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"], "id": 3}' -H "Content-Type:application/json"
  response:
 	 {"jsonrpc":"2.0","id":3,"result":"0x270f4b122603999d1c07aec97e972a2ddf7bd8b5bfe3543c10814e6a19f13aaf"}
 */
-func (accountapi *AccountApi) DumpPubkey(address *crypto.CommonAddress) (*secp256k1.PublicKey, error) {
+func (accountapi *AccountApi) DumpPubkey(address AddressOrAlias) (*secp256k1.PublicKey, error) {
 	if !accountapi.Wallet.IsOpen() {
 		return nil, ErrClosedWallet
 	}
@@ -570,7 +1061,11 @@ func (accountapi *AccountApi) DumpPubkey(address *crypto.CommonAddress) (*secp25
 		return nil, ErrLockedWallet
 	}
 
-	node, err := accountapi.Wallet.GetAccountByAddress(address)
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	node, err := accountapi.Wallet.GetAccountByAddress(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -581,7 +1076,7 @@ func (accountapi *AccountApi) DumpPubkey(address *crypto.CommonAddress) (*secp25
  name: sign
  usage: Signature transaction
  params:
-	1.account of sig
+	1.account of sig, or its alias
 	2.msg for sig
  return: private key
  example:
@@ -590,8 +1085,12 @@ func (accountapi *AccountApi) DumpPubkey(address *crypto.CommonAddress) (*secp25
 response:
 	 {"jsonrpc":"2.0","id":3,"result":"0x1f1d16412468dd9b67b568d31839ac608bdfddf2580666db4d364eefbe285fdaed569a3c8fa1decfebbfa0ed18b636059dbbf4c2106c45fc8846909833ef2cb1de"}
 */
-func (accountapi *AccountApi) Sign(address crypto.CommonAddress, hash common.Bytes) (common.Bytes, error) {
-	sig, err := accountapi.Wallet.Sign(&address, hash)
+func (accountapi *AccountApi) Sign(address AddressOrAlias, hash common.Bytes) (common.Bytes, error) {
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := accountapi.Wallet.Sign(addr, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -602,7 +1101,7 @@ func (accountapi *AccountApi) Sign(address crypto.CommonAddress, hash common.Byt
  name: generateAddresses
  usage: Generate the addresses of the other chains
  params:
-	1. address of drep
+	1. address of drep, or its alias
  return: {BTCaddress, ethAddress, neoAddress}
  example:
 	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_generateAddresses","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"], "id": 3}' -H "Content-Type:application/json"
@@ -610,8 +1109,12 @@ func (accountapi *AccountApi) Sign(address crypto.CommonAddress, hash common.Byt
 response:
 	 {"jsonrpc":"2.0","id":3,"result":""}
 */
-func (accountapi *AccountApi) GenerateAddresses(address crypto.CommonAddress) (*RpcAddresses, error) {
-	privkey, err := accountapi.Wallet.DumpPrivateKey(&address)
+func (accountapi *AccountApi) GenerateAddresses(address AddressOrAlias) (*RpcAddresses, error) {
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	privkey, err := accountapi.Wallet.DumpPrivateKey(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -631,9 +1134,49 @@ func (accountapi *AccountApi) GenerateAddresses(address crypto.CommonAddress) (*
 	}, nil
 }
 
+/*
+ name: generateAddress
+ usage: Generate the address of a chain in getSupportedChains
+ params:
+	1. address of drep, or its alias
+	2. name of the chain
+ return: address of the chain
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_generateAddress","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","tron"], "id": 3}' -H "Content-Type:application/json"
+
+response:
+	 {"jsonrpc":"2.0","id":3,"result":"TJ6aPrDnnK2sYdFkHZY7xnB6ZqGBmzrydq"}
+*/
+func (accountapi *AccountApi) GenerateAddress(address AddressOrAlias, chain string) (string, error) {
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return "", err
+	}
+	privkey, err := accountapi.Wallet.DumpPrivateKey(addr)
+	if err != nil {
+		return "", err
+	}
+	return addrgenerator.Encode(chain, privkey.PubKey())
+}
+
+/*
+ name: getSupportedChains
+ usage: List the chains whose addresses can be generated
+ params:
+ return: names of the chains
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_getSupportedChains","params":[], "id": 3}' -H "Content-Type:application/json"
+
+response:
+	 {"jsonrpc":"2.0","id":3,"result":["bitcoin","cosmos","dash","dogecoin","ethereum","litecoin","neo","ripple","tron"]}
+*/
+func (accountapi *AccountApi) GetSupportedChains() []string {
+	return addrgenerator.Chains()
+}
+
 /*
  name: importKeyStore
- usage: import keystore
+ usage: import keystore, a directory or a single key file, in the drep or the Web3 keystore v3 format
  params:
 	1.path
 	2.password
@@ -647,6 +1190,27 @@ func (accountapi *AccountApi) ImportKeyStore(path, password string) ([]*crypto.C
 	return accountapi.Wallet.ImportKeyStore(path, password)
 }
 
+/*
+ name: exportKeyStore
+ usage: export the key of an address as a Web3 keystore v3 file, readable by Ethereum tools
+ params:
+	1.address, or its alias
+	2.password to encrypt the file with
+	3.path of the file, or of a directory to create the file in
+ return: path of the written file
+ example:
+	 curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"account_exportKeyStore","params":["0x4082c96e38def8f3851831940485066234fe07b8","123","/tmp"], "id": 3}' -H "Content-Type:application/json"
+response:
+	 {"jsonrpc":"2.0","id":3,"result":"/tmp/UTC--2019-11-05T08-21-36.201436000Z--4082c96e38def8f3851831940485066234fe07b8"}
+*/
+func (accountapi *AccountApi) ExportKeyStore(address AddressOrAlias, password, path string) (string, error) {
+	addr, err := accountapi.accountService.ResolveAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return accountapi.Wallet.ExportKeyStore(addr, password, path)
+}
+
 /*
  name: importPrivkey
  usage: import private key
@@ -699,3 +1263,87 @@ type RpcAccount struct {
 	Addr   *crypto.CommonAddress
 	Pubkey string
 }
+
+/*
+ name: upstreamStatus
+ usage: Query the status of upstream rpc endpoints in wallet-only mode
+ params:
+ return: url, health, height of endpoints and which one is used currently
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"account_upstreamStatus","params":[],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":[{"url":"http://10.0.0.1:10085","healthy":true,"height":10020,"current":true},{"url":"http://10.0.0.2:10085","healthy":false,"height":9000,"current":false,"err":"upstream falls behind, height:9000 highest:10020"}]}
+*/
+func (accountapi *AccountApi) UpstreamStatus() ([]*UpstreamStatus, error) {
+	if accountapi.accountService.upstream == nil {
+		return nil, ErrNotUpstreamMode
+	}
+	return accountapi.accountService.upstream.Status(), nil
+}
+
+/*
+name: ABI RPC interface
+usage: Keep the abi of contracts, account_callMethod decodes return values with them
+prefix:abi
+*/
+type AbiApi struct {
+	accountService *AccountService
+}
+
+/*
+ name: register
+ usage: Register the abi of a contract, an abi already registered for the contract is replaced
+ params:
+	1. Contract address, or its alias
+	2. Abi json of the contract
+ return: nil on success
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"abi_register","params":["0xecfb51e10aa4c146bf6c12eee090339c99841efc","[{\"constant\":true,\"inputs\":[],\"name\":\"get\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"type\":\"function\"}]"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":null}
+*/
+func (abiApi *AbiApi) Register(addr AddressOrAlias, abiJson string) error {
+	contractAddr, err := abiApi.accountService.ResolveAddress(addr)
+	if err != nil {
+		return err
+	}
+	return abiApi.accountService.putContractAbi(contractAddr, abiJson)
+}
+
+/*
+ name: get
+ usage: Get the abi registered for a contract
+ params:
+	1. Contract address, or its alias
+ return: Abi json of the contract
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"abi_get","params":["0xecfb51e10aa4c146bf6c12eee090339c99841efc"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"[{\"constant\":true,\"inputs\":[],\"name\":\"get\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"type\":\"function\"}]"}
+*/
+func (abiApi *AbiApi) Get(addr AddressOrAlias) (string, error) {
+	contractAddr, err := abiApi.accountService.ResolveAddress(addr)
+	if err != nil {
+		return "", err
+	}
+	return abiApi.accountService.getContractAbi(contractAddr)
+}
+
+/*
+ name: remove
+ usage: Remove the abi registered for a contract
+ params:
+	1. Contract address, or its alias
+ return: nil on success
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"abi_remove","params":["0xecfb51e10aa4c146bf6c12eee090339c99841efc"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":null}
+*/
+func (abiApi *AbiApi) Remove(addr AddressOrAlias) error {
+	contractAddr, err := abiApi.accountService.ResolveAddress(addr)
+	if err != nil {
+		return err
+	}
+	return abiApi.accountService.deleteContractAbi(contractAddr)
+}
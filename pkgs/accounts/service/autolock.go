@@ -0,0 +1,123 @@
+package service
+
+import (
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// Reasons an account is locked
+const (
+	LockManual  = "manual"  // account_lockAccount
+	LockTimeout = "timeout" // the unlock duration passed
+	LockIdle    = "idle"    // not used for the wallet idle timeout
+
+	autoLockInterval = time.Second
+)
+
+// AccountLockEvent is sent when an unlocked account is locked again
+type AccountLockEvent struct {
+	Address crypto.CommonAddress
+	Reason  string
+}
+
+// unlockState is the unlock of an account, deadline is zero if it has no duration
+type unlockState struct {
+	deadline time.Time
+	lastUsed time.Time
+}
+
+// UnLockFor unlock an account for duration, 0 keep it unlocked until it is locked or idle
+func (wallet *Wallet) UnLockFor(addr *crypto.CommonAddress, password string, duration time.Duration) error {
+	if err := wallet.UnLock(addr, password); err != nil {
+		return err
+	}
+	now := time.Now()
+	state := &unlockState{lastUsed: now}
+	if duration > 0 {
+		state.deadline = now.Add(duration)
+	}
+
+	wallet.unlockLock.Lock()
+	wallet.unlocks[*addr] = state
+	wallet.unlockLock.Unlock()
+	return nil
+}
+
+// SubscribeLockEvent get notified when accounts are locked
+func (wallet *Wallet) SubscribeLockEvent(ch chan<- *AccountLockEvent) event.Subscription {
+	return wallet.lockFeed.Subscribe(ch)
+}
+
+// unlockedKey return the key of an unlocked account and mark it used, accounts whose
+// unlock expired are locked first
+func (wallet *Wallet) unlockedKey(addr *crypto.CommonAddress) (*types.Node, error) {
+	wallet.expireUnlocks(time.Now())
+	node, err := wallet.cacheStore.GetKey(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.unlockLock.Lock()
+	if state, ok := wallet.unlocks[*addr]; ok {
+		state.lastUsed = time.Now()
+	} else {
+		wallet.unlocks[*addr] = &unlockState{lastUsed: time.Now()}
+	}
+	wallet.unlockLock.Unlock()
+	return node, nil
+}
+
+// expireUnlocks lock the accounts whose unlock duration passed or that stayed idle too long.
+// Keys loaded without an unlock, e.g. new or imported accounts, start idling when first seen
+func (wallet *Wallet) expireUnlocks(now time.Time) {
+	cacheStore := wallet.cacheStore
+	if cacheStore == nil {
+		return
+	}
+	idle := time.Duration(wallet.config.UnlockIdleTimeout) * time.Second
+
+	expired := map[crypto.CommonAddress]string{}
+	wallet.unlockLock.Lock()
+	for _, addr := range cacheStore.Addresses() {
+		state, ok := wallet.unlocks[addr]
+		if !ok {
+			wallet.unlocks[addr] = &unlockState{lastUsed: now}
+			continue
+		}
+		if !state.deadline.IsZero() && now.After(state.deadline) {
+			expired[addr] = LockTimeout
+		} else if idle > 0 && now.Sub(state.lastUsed) > idle {
+			expired[addr] = LockIdle
+		}
+	}
+	for addr := range expired {
+		delete(wallet.unlocks, addr)
+	}
+	wallet.unlockLock.Unlock()
+
+	for addr, reason := range expired {
+		addr := addr
+		if err := cacheStore.ClearKey(&addr); err != nil {
+			continue
+		}
+		log.WithField("addr", addr.String()).WithField("reason", reason).Info("account locked")
+		wallet.lockFeed.Send(&AccountLockEvent{Address: addr, Reason: reason})
+	}
+}
+
+// autoLockLoop lock expired and idle accounts until quit is closed
+func (wallet *Wallet) autoLockLoop(quit chan struct{}) {
+	ticker := time.NewTicker(autoLockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			wallet.expireUnlocks(now)
+		case <-quit:
+			return
+		}
+	}
+}
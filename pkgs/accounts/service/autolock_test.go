@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	accountTypes "github.com/drep-project/DREP-Chain/pkgs/accounts/types"
+)
+
+func newLockTestWallet(t *testing.T, idle uint64) *Wallet {
+	wallet, err := NewWallet(&accountTypes.Config{Enable: true, Type: "memorystore", UnlockIdleTimeout: idle}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wallet.OpenWallet("password"); err != nil {
+		t.Fatal(err)
+	}
+	return wallet
+}
+
+func TestUnlockDuration(t *testing.T) {
+	wallet := newLockTestWallet(t, 0)
+	node, err := wallet.NewAccount("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *AccountLockEvent, 1)
+	sub := wallet.SubscribeLockEvent(events)
+	defer sub.Unsubscribe()
+
+	if err := wallet.UnLockFor(node.Address, "password", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	wallet.expireUnlocks(time.Now())
+	if _, err := wallet.DumpPrivateKey(node.Address); err != nil {
+		t.Fatalf("account locked before its duration: %v", err)
+	}
+
+	wallet.expireUnlocks(time.Now().Add(2 * time.Minute))
+	if _, err := wallet.DumpPrivateKey(node.Address); err == nil {
+		t.Fatal("account still unlocked after its duration")
+	}
+	select {
+	case ev := <-events:
+		if ev.Address != *node.Address || ev.Reason != LockTimeout {
+			t.Fatalf("unexpected lock event %v", ev)
+		}
+	default:
+		t.Fatal("no lock event")
+	}
+}
+
+func TestUnlockIdle(t *testing.T) {
+	wallet := newLockTestWallet(t, 60)
+	node, err := wallet.NewAccount("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *AccountLockEvent, 1)
+	sub := wallet.SubscribeLockEvent(events)
+	defer sub.Unsubscribe()
+
+	// new accounts start idling when first seen
+	wallet.expireUnlocks(time.Now())
+	wallet.expireUnlocks(time.Now().Add(30 * time.Second))
+	if _, err := wallet.DumpPrivateKey(node.Address); err != nil {
+		t.Fatalf("account locked before idle timeout: %v", err)
+	}
+
+	wallet.expireUnlocks(time.Now().Add(2 * time.Minute))
+	if ev := <-events; ev.Reason != LockIdle {
+		t.Fatalf("got lock reason %s, want %s", ev.Reason, LockIdle)
+	}
+	if _, err := wallet.DumpPrivateKey(node.Address); err == nil {
+		t.Fatal("idle account still unlocked")
+	}
+}
+
+func TestLockEvent(t *testing.T) {
+	wallet := newLockTestWallet(t, 0)
+	node, err := wallet.NewAccount("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *AccountLockEvent, 1)
+	sub := wallet.SubscribeLockEvent(events)
+	defer sub.Unsubscribe()
+
+	if err := wallet.Lock(node.Address); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Reason != LockManual {
+		t.Fatalf("got lock reason %s, want %s", ev.Reason, LockManual)
+	}
+}
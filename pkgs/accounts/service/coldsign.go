@@ -0,0 +1,418 @@
+package service
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/DREP-Chain/types"
+	drepBinary "github.com/drep-project/binary"
+)
+
+// An online watcher node builds transactions of accounts whose keys are kept by an offline signer.
+// The signer exports a pairing of its accounts, the watcher exports signing requests, the signer
+// exports signatures of them, and the watcher assembles and broadcasts the transactions. Everything
+// passed between them is a hex blob, fit for a QR code or a file.
+const (
+	defaultSigningFile = "signing.json"
+	maxSigningRequests = 256
+
+	blobPairing  byte = 1
+	blobRequest  byte = 2
+	blobResponse byte = 3
+)
+
+// PairedAccount is an account of the offline signer watched by this node, Sig proves the signer
+// holds its key
+type PairedAccount struct {
+	Address crypto.CommonAddress `json:"address"`
+	Sig     common.Bytes         `json:"sig"`
+}
+
+type signerPairing struct {
+	ChainId  types.ChainIdType
+	Accounts []*PairedAccount
+}
+
+// SigningRequest is an unsigned transaction of a paired account waiting for the offline signer,
+// Encoded is the blob passed to the signer
+type SigningRequest struct {
+	Hash     crypto.Hash          `json:"hash"`
+	From     crypto.CommonAddress `json:"from"`
+	To       crypto.CommonAddress `json:"to"`
+	Type     types.TxType         `json:"type"`
+	Amount   common.Big           `json:"amount"`
+	GasPrice common.Big           `json:"gasPrice"`
+	GasLimit common.Big           `json:"gasLimit"`
+	Nonce    uint64               `json:"nonce"`
+	Created  int64                `json:"created"`
+	Raw      common.Bytes         `json:"raw"`
+	Encoded  string               `json:"encoded,omitempty"`
+}
+
+type signingRequestBlob struct {
+	From crypto.CommonAddress
+	Raw  []byte
+}
+
+type signingResponseBlob struct {
+	Hash crypto.Hash
+	Sig  []byte
+}
+
+func newSigningRequest(from *crypto.CommonAddress, tx *types.Transaction) (*SigningRequest, error) {
+	raw, err := drepBinary.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningRequest{
+		Hash:     *tx.TxHash(),
+		From:     *from,
+		To:       tx.Data.To,
+		Type:     tx.Data.Type,
+		Amount:   tx.Data.Amount,
+		GasPrice: tx.Data.GasPrice,
+		GasLimit: tx.Data.GasLimit,
+		Nonce:    tx.Nonce(),
+		Created:  time.Now().Unix(),
+		Raw:      raw,
+	}, nil
+}
+
+func (request *SigningRequest) transaction() (*types.Transaction, error) {
+	tx := &types.Transaction{}
+	if err := drepBinary.Unmarshal(request.Raw, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// encoded return a copy of the request with the blob for the signer
+func (request *SigningRequest) encoded() (*SigningRequest, error) {
+	blob, err := encodeBlob(blobRequest, &signingRequestBlob{From: request.From, Raw: request.Raw})
+	if err != nil {
+		return nil, err
+	}
+	copied := *request
+	copied.Encoded = blob
+	return &copied, nil
+}
+
+// pairingHash is signed by the key of a paired account, it binds the account to the chain
+func pairingHash(chainId types.ChainIdType, addr *crypto.CommonAddress) []byte {
+	id := make([]byte, 4)
+	binary.BigEndian.PutUint32(id, uint32(chainId))
+	return sha3.Keccak256([]byte("drep signer pairing"), id, addr.Bytes())
+}
+
+func encodeBlob(kind byte, v interface{}) (string, error) {
+	content, err := drepBinary.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(append([]byte{kind}, content...)), nil
+}
+
+// decodeBlob decode a hex blob, or the hex blob in the file named by input
+func decodeBlob(kind byte, input string, v interface{}) error {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "0x") {
+		content, err := ioutil.ReadFile(input)
+		if err != nil {
+			return err
+		}
+		input = strings.TrimSpace(string(content))
+	}
+	content, err := hexutil.Decode(input)
+	if err != nil {
+		return err
+	}
+	if len(content) == 0 || content[0] != kind {
+		return ErrBlobKind
+	}
+	return drepBinary.Unmarshal(content[1:], v)
+}
+
+// writeBlob write the blob to path if set
+func writeBlob(path *string, blob string) error {
+	if path == nil || *path == "" {
+		return nil
+	}
+	return ioutil.WriteFile(*path, []byte(blob), 0600)
+}
+
+// signingStore keep the paired accounts and the pending signing requests, they are written to
+// path on every change
+type signingStore struct {
+	path     string
+	lock     sync.Mutex
+	paired   map[crypto.CommonAddress]*PairedAccount
+	requests map[crypto.Hash]*SigningRequest
+}
+
+type signingFile struct {
+	Paired   []*PairedAccount  `json:"paired"`
+	Requests []*SigningRequest `json:"requests"`
+}
+
+func newSigningStore(path string) (*signingStore, error) {
+	store := &signingStore{
+		path:     path,
+		paired:   make(map[crypto.CommonAddress]*PairedAccount),
+		requests: make(map[crypto.Hash]*SigningRequest),
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	file := &signingFile{}
+	if err := json.Unmarshal(content, file); err != nil {
+		return nil, err
+	}
+	for _, account := range file.Paired {
+		store.paired[account.Address] = account
+	}
+	for _, request := range file.Requests {
+		store.requests[request.Hash] = request
+	}
+	return store, nil
+}
+
+func (store *signingStore) pair(accounts []*PairedAccount) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	for _, account := range accounts {
+		store.paired[account.Address] = account
+	}
+	return store.save()
+}
+
+func (store *signingStore) isPaired(addr *crypto.CommonAddress) bool {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	_, ok := store.paired[*addr]
+	return ok
+}
+
+// pairedAddresses return the paired accounts sorted by address
+func (store *signingStore) pairedAddresses() []crypto.CommonAddress {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	addrs := make([]crypto.CommonAddress, 0, len(store.paired))
+	for addr := range store.paired {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Hex() < addrs[j].Hex() })
+	return addrs
+}
+
+func (store *signingStore) add(request *SigningRequest) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if len(store.requests) >= maxSigningRequests {
+		return ErrTooManySigningRequests
+	}
+	store.requests[request.Hash] = request
+	return store.save()
+}
+
+func (store *signingStore) get(hash crypto.Hash) (*SigningRequest, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	request, ok := store.requests[hash]
+	if !ok {
+		return nil, ErrSigningRequestNotFound
+	}
+	return request, nil
+}
+
+func (store *signingStore) remove(hash crypto.Hash) (*SigningRequest, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	request, ok := store.requests[hash]
+	if !ok {
+		return nil, ErrSigningRequestNotFound
+	}
+	delete(store.requests, hash)
+	return request, store.save()
+}
+
+// list return the pending requests, oldest first
+func (store *signingStore) list() []*SigningRequest {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	requests := make([]*SigningRequest, 0, len(store.requests))
+	for _, request := range store.requests {
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].Created != requests[j].Created {
+			return requests[i].Created < requests[j].Created
+		}
+		return requests[i].Nonce < requests[j].Nonce
+	})
+	return requests
+}
+
+// save write to a temporary file first, so a crash never leaves a partial file. The lock must be held
+func (store *signingStore) save() error {
+	file := &signingFile{
+		Paired:   make([]*PairedAccount, 0, len(store.paired)),
+		Requests: make([]*SigningRequest, 0, len(store.requests)),
+	}
+	for _, account := range store.paired {
+		file.Paired = append(file.Paired, account)
+	}
+	for _, request := range store.requests {
+		file.Requests = append(file.Requests, request)
+	}
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := store.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, store.path)
+}
+
+// exportPairing sign the pairing of local accounts on the offline signer
+func (accountService *AccountService) exportPairing(addrs []*crypto.CommonAddress) (string, error) {
+	if len(addrs) == 0 {
+		return "", ErrMissingAddress
+	}
+	pairing := &signerPairing{ChainId: accountService.Wallet.chainId}
+	for _, addr := range addrs {
+		sig, err := accountService.Wallet.Sign(addr, pairingHash(pairing.ChainId, addr))
+		if err != nil {
+			return "", err
+		}
+		pairing.Accounts = append(pairing.Accounts, &PairedAccount{Address: *addr, Sig: sig})
+	}
+	return encodeBlob(blobPairing, pairing)
+}
+
+// importPairing watch the accounts of an offline signer after checking it holds their keys
+func (accountService *AccountService) importPairing(input string) ([]crypto.CommonAddress, error) {
+	pairing := &signerPairing{}
+	if err := decodeBlob(blobPairing, input, pairing); err != nil {
+		return nil, err
+	}
+	if pairing.ChainId != accountService.Wallet.chainId {
+		return nil, ErrPairingChainId
+	}
+	addrs := make([]crypto.CommonAddress, 0, len(pairing.Accounts))
+	for _, account := range pairing.Accounts {
+		pk, _, err := secp256k1.RecoverCompact(account.Sig, pairingHash(pairing.ChainId, &account.Address))
+		if err != nil || crypto.PubkeyToAddress(pk) != account.Address {
+			return nil, ErrPairingSig
+		}
+		addrs = append(addrs, account.Address)
+	}
+	return addrs, accountService.signing.pair(pairing.Accounts)
+}
+
+// createSigningRequest keep the unsigned transaction of a paired account, its nonce stays reserved
+// until the signature is submitted or the request canceled
+func (accountService *AccountService) createSigningRequest(from *crypto.CommonAddress, build func(nonce uint64) *types.Transaction) (*SigningRequest, error) {
+	if !accountService.signing.isPaired(from) {
+		return nil, ErrNotPaired
+	}
+	nonce := accountService.nonces.Reserve(from)
+	tx := build(nonce)
+	tx.Data.ChainId = accountService.Wallet.chainId
+	request, err := newSigningRequest(from, tx)
+	if err == nil {
+		err = accountService.signing.add(request)
+	}
+	if err != nil {
+		accountService.nonces.Release(from, nonce)
+		return nil, err
+	}
+	return request.encoded()
+}
+
+// inspectSigningRequest decode a signing request on the offline signer so it is reviewed before signing
+func (accountService *AccountService) inspectSigningRequest(input string) (*SigningRequest, *types.Transaction, error) {
+	blob := &signingRequestBlob{}
+	if err := decodeBlob(blobRequest, input, blob); err != nil {
+		return nil, nil, err
+	}
+	tx := &types.Transaction{}
+	if err := drepBinary.Unmarshal(blob.Raw, tx); err != nil {
+		return nil, nil, err
+	}
+	if tx.Data.ChainId != accountService.Wallet.chainId {
+		return nil, nil, ErrPairingChainId
+	}
+	request, err := newSigningRequest(&blob.From, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return request, tx, nil
+}
+
+// signRequest sign a signing request on the offline signer
+func (accountService *AccountService) signRequest(input string) (string, error) {
+	request, tx, err := accountService.inspectSigningRequest(input)
+	if err != nil {
+		return "", err
+	}
+	sig, err := accountService.Wallet.Sign(&request.From, tx.TxHash().Bytes())
+	if err != nil {
+		return "", err
+	}
+	return encodeBlob(blobResponse, &signingResponseBlob{Hash: request.Hash, Sig: sig})
+}
+
+// submitSignature assemble the transaction of a request with the signature from the offline signer
+// and broadcast it
+func (accountService *AccountService) submitSignature(input string) (*crypto.Hash, error) {
+	response := &signingResponseBlob{}
+	if err := decodeBlob(blobResponse, input, response); err != nil {
+		return nil, err
+	}
+	request, err := accountService.signing.get(response.Hash)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := request.transaction()
+	if err != nil {
+		return nil, err
+	}
+	tx.Sig = response.Sig
+	if from, err := tx.From(); err != nil || *from != request.From {
+		return nil, ErrSignatureMismatch
+	}
+	if err := accountService.MessageBroadCastor.SendTransaction(tx, true); err != nil {
+		return nil, err
+	}
+	accountService.nonces.Release(&request.From, request.Nonce)
+	if _, err := accountService.signing.remove(request.Hash); err != nil {
+		log.WithField("hash", request.Hash.String()).WithField("err", err).Warn("remove signed request")
+	}
+	return tx.TxHash(), nil
+}
+
+func (accountService *AccountService) cancelSigningRequest(hash crypto.Hash) error {
+	request, err := accountService.signing.remove(hash)
+	if err != nil {
+		return err
+	}
+	accountService.nonces.Release(&request.From, request.Nonce)
+	return nil
+}
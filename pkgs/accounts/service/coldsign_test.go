@@ -0,0 +1,122 @@
+package service
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+type coldNonceSource struct{}
+
+func (coldNonceSource) GetTransactionCount(addr *crypto.CommonAddress) uint64 { return 0 }
+
+func (coldNonceSource) GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions {
+	return nil
+}
+
+type coldBroadcastor struct {
+	sent []*types.Transaction
+}
+
+func (b *coldBroadcastor) SendTransaction(tx *types.Transaction, islocal bool) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *coldBroadcastor) SendPrivateTransaction(tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *coldBroadcastor) BroadcastBlock(msgType int32, block *types.Block, isLocal bool) {}
+
+func (b *coldBroadcastor) BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool) {}
+
+func TestColdSigning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "coldsign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := newSigningStore(filepath.Join(dir, defaultSigningFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	broadcastor := &coldBroadcastor{}
+	watcher := &AccountService{
+		Wallet:             &Wallet{chainId: 7},
+		MessageBroadCastor: broadcastor,
+		nonces:             blockmgr.NewNonceManager(coldNonceSource{}),
+		signing:            store,
+	}
+
+	// the offline signer pairs its account
+	key, _ := crypto.GenerateKey(rand.Reader)
+	addr := crypto.PubkeyToAddress(key.PubKey())
+	sig, _ := secp256k1.SignCompact(key, pairingHash(7, &addr), true)
+	pairing, _ := encodeBlob(blobPairing, &signerPairing{ChainId: 7, Accounts: []*PairedAccount{{Address: addr, Sig: sig}}})
+	other, _ := encodeBlob(blobPairing, &signerPairing{ChainId: 8, Accounts: []*PairedAccount{{Address: addr, Sig: sig}}})
+	if _, err := watcher.importPairing(other); err != ErrPairingChainId {
+		t.Fatalf("pairing of another chain imported, %v", err)
+	}
+	if addrs, err := watcher.importPairing(pairing); err != nil || len(addrs) != 1 || addrs[0] != addr {
+		t.Fatalf("pairing imported %v, %v", addrs, err)
+	}
+
+	build := func(nonce uint64) *types.Transaction {
+		return types.NewTransaction(crypto.CommonAddress{1}, big.NewInt(100), big.NewInt(1), big.NewInt(30000), nonce)
+	}
+	if _, err := watcher.createSigningRequest(&crypto.CommonAddress{2}, build); err != ErrNotPaired {
+		t.Fatalf("request of unpaired account created, %v", err)
+	}
+	request, err := watcher.createSigningRequest(&addr, build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if watcher.nonces.Pending(&addr) != 1 {
+		t.Fatal("nonce of request not reserved")
+	}
+
+	// the signer reviews and signs the request
+	inspected, tx, err := watcher.inspectSigningRequest(request.Encoded)
+	if err != nil || inspected.Hash != request.Hash || inspected.From != addr {
+		t.Fatalf("request inspected as %v, %v", inspected, err)
+	}
+	wrongKey, _ := crypto.GenerateKey(rand.Reader)
+	wrongSig, _ := secp256k1.SignCompact(wrongKey, tx.TxHash().Bytes(), true)
+	wrong, _ := encodeBlob(blobResponse, &signingResponseBlob{Hash: request.Hash, Sig: wrongSig})
+	if _, err := watcher.submitSignature(wrong); err != ErrSignatureMismatch {
+		t.Fatalf("signature of another key accepted, %v", err)
+	}
+
+	txSig, _ := secp256k1.SignCompact(key, tx.TxHash().Bytes(), true)
+	response, _ := encodeBlob(blobResponse, &signingResponseBlob{Hash: request.Hash, Sig: txSig})
+	responseFile := filepath.Join(dir, "signature.txt")
+	if err := writeBlob(&responseFile, response); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := watcher.submitSignature(responseFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *hash != request.Hash || len(broadcastor.sent) != 1 {
+		t.Fatalf("sent %d transactions, hash %v", len(broadcastor.sent), hash)
+	}
+	if len(store.list()) != 0 || watcher.nonces.Pending(&addr) != 0 {
+		t.Fatal("submitted request kept")
+	}
+
+	// pairing survives a restart
+	reloaded, err := newSigningStore(filepath.Join(dir, defaultSigningFile))
+	if err != nil || !reloaded.isPaired(&addr) {
+		t.Fatalf("pairing not kept, %v", err)
+	}
+}
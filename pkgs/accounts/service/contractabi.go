@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/pkgs/evm/abi"
+	"github.com/pkg/errors"
+)
+
+var contractAbiPrefix = []byte("contractAbi_") //contract address -> abi json
+
+// MethodResult is the result of calling a contract method, Outputs are decoded when an abi of the contract is registered
+type MethodResult struct {
+	Raw     common.Bytes  `json:"raw"`
+	Outputs []interface{} `json:"outputs,omitempty"`
+}
+
+func contractAbiKey(addr *crypto.CommonAddress) []byte {
+	return append(append([]byte{}, contractAbiPrefix...), addr.Bytes()...)
+}
+
+// putContractAbi check and store the abi json of the contract at addr
+func (accountService *AccountService) putContractAbi(addr *crypto.CommonAddress, abiJson string) error {
+	if _, err := abi.JSON(strings.NewReader(abiJson)); err != nil {
+		return errors.Wrapf(ErrInvalidAbi, "%v", err)
+	}
+	return accountService.DatabaseService.LevelDb().Put(contractAbiKey(addr), []byte(abiJson))
+}
+
+// getContractAbi return the abi json registered for the contract at addr
+func (accountService *AccountService) getContractAbi(addr *crypto.CommonAddress) (string, error) {
+	key := contractAbiKey(addr)
+	if ok, _ := accountService.DatabaseService.LevelDb().Has(key); !ok {
+		return "", ErrAbiNotFound
+	}
+	content, err := accountService.DatabaseService.LevelDb().Get(key)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (accountService *AccountService) deleteContractAbi(addr *crypto.CommonAddress) error {
+	key := contractAbiKey(addr)
+	if ok, _ := accountService.DatabaseService.LevelDb().Has(key); !ok {
+		return ErrAbiNotFound
+	}
+	return accountService.DatabaseService.LevelDb().Delete(key)
+}
+
+// contractMethod find the method to call on the contract at addr. method is either a full
+// signature like transfer(address,uint256), or a method name of the registered abi.
+// The outputs are only known when the contract has a registered abi
+func (accountService *AccountService) contractMethod(addr *crypto.CommonAddress, method string) (*abi.Method, error) {
+	var contractAbi *abi.ABI
+	if abiJson, err := accountService.getContractAbi(addr); err == nil {
+		parsed, err := abi.JSON(strings.NewReader(abiJson))
+		if err != nil {
+			return nil, err
+		}
+		contractAbi = &parsed
+	}
+
+	method = strings.Replace(method, " ", "", -1)
+	if !strings.Contains(method, "(") {
+		if contractAbi == nil {
+			return nil, errors.Wrapf(ErrAbiNotFound, "method %s without signature", method)
+		}
+		found, ok := contractAbi.Methods[method]
+		if !ok {
+			return nil, errors.Wrapf(ErrMethodNotFound, "%s", method)
+		}
+		return &found, nil
+	}
+
+	parsed, err := parseMethodSig(method)
+	if err != nil {
+		return nil, err
+	}
+	if contractAbi != nil {
+		for _, found := range contractAbi.Methods {
+			if found.Sig() == parsed.Sig() {
+				return &found, nil
+			}
+		}
+	}
+	return parsed, nil
+}
+
+// parseMethodSig parse a method signature like transfer(address,uint256), the types must be canonical
+func parseMethodSig(sig string) (*abi.Method, error) {
+	open := strings.Index(sig, "(")
+	if open <= 0 || !strings.HasSuffix(sig, ")") {
+		return nil, errors.Wrapf(ErrInvalidMethodSig, "%s", sig)
+	}
+	method := &abi.Method{Name: sig[:open], Inputs: abi.Arguments{}}
+	params := sig[open+1 : len(sig)-1]
+	if params == "" {
+		return method, nil
+	}
+	for _, param := range strings.Split(params, ",") {
+		typ, err := abi.NewType(param)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidMethodSig, "%s: %v", sig, err)
+		}
+		method.Inputs = append(method.Inputs, abi.Argument{Type: typ})
+	}
+	return method, nil
+}
+
+// packMethodCall encode the call data of method from json arguments
+func packMethodCall(method *abi.Method, args []json.RawMessage) ([]byte, error) {
+	if len(args) != len(method.Inputs) {
+		return nil, errors.Wrapf(ErrInvalidMethodArgs, "%s takes %d arguments, got %d", method.Sig(), len(method.Inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value, err := abiValue(method.Inputs[i].Type, arg)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidMethodArgs, "argument %d: %v", i, err)
+		}
+		values[i] = value.Interface()
+	}
+	packed, err := method.Inputs.PackValues(values)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.Id(), packed...), nil
+}
+
+// abiValue convert a json argument into the go value abi packs for typ. Integers are json
+// numbers or decimal and 0x prefixed hex strings, bytes and addresses are hex strings
+func abiValue(typ abi.Type, arg json.RawMessage) (reflect.Value, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		return abiInt(typ, arg)
+	case abi.AddressTy:
+		var input string
+		if err := json.Unmarshal(arg, &input); err != nil {
+			return reflect.Value{}, err
+		}
+		if !crypto.IsHexAddress(input) {
+			return reflect.Value{}, fmt.Errorf("invalid address %s", input)
+		}
+		return reflect.ValueOf(crypto.HexToAddress(input)), nil
+	case abi.BytesTy:
+		var input common.Bytes
+		if err := json.Unmarshal(arg, &input); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf([]byte(input)), nil
+	case abi.FixedBytesTy, abi.FunctionTy:
+		var input common.Bytes
+		if err := json.Unmarshal(arg, &input); err != nil {
+			return reflect.Value{}, err
+		}
+		if len(input) > typ.Type.Len() {
+			return reflect.Value{}, fmt.Errorf("%d bytes exceed %s", len(input), typ.String())
+		}
+		value := reflect.New(typ.Type).Elem()
+		reflect.Copy(value, reflect.ValueOf([]byte(input)))
+		return value, nil
+	case abi.SliceTy, abi.ArrayTy:
+		var elems []json.RawMessage
+		if err := json.Unmarshal(arg, &elems); err != nil {
+			return reflect.Value{}, err
+		}
+		var value reflect.Value
+		if typ.T == abi.SliceTy {
+			value = reflect.MakeSlice(typ.Type, len(elems), len(elems))
+		} else {
+			if len(elems) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("%s takes %d elements, got %d", typ.String(), typ.Size, len(elems))
+			}
+			value = reflect.New(typ.Type).Elem()
+		}
+		for i, elem := range elems {
+			elemValue, err := abiValue(*typ.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value.Index(i).Set(elemValue)
+		}
+		return value, nil
+	default:
+		value := reflect.New(typ.Type)
+		if err := json.Unmarshal(arg, value.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+		return value.Elem(), nil
+	}
+}
+
+func abiInt(typ abi.Type, arg json.RawMessage) (reflect.Value, error) {
+	input := strings.Trim(string(bytes.TrimSpace(arg)), `"`)
+	num, ok := new(big.Int).SetString(input, 0)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("invalid integer %s", input)
+	}
+	if typ.T == abi.UintTy && num.Sign() < 0 {
+		return reflect.Value{}, fmt.Errorf("negative %s %s", typ.String(), input)
+	}
+	bits, magnitude := typ.Size, num
+	if typ.T == abi.IntTy {
+		// one bit for the sign, the lowest negative is -2^(bits)
+		bits--
+		if num.Sign() < 0 {
+			magnitude = new(big.Int).Not(num)
+		}
+	}
+	if magnitude.BitLen() > bits {
+		return reflect.Value{}, fmt.Errorf("%s overflows %s", input, typ.String())
+	}
+	if typ.Type == reflect.TypeOf(num) {
+		return reflect.ValueOf(num), nil
+	}
+
+	value := reflect.New(typ.Type).Elem()
+	if typ.T == abi.UintTy {
+		value.SetUint(num.Uint64())
+	} else {
+		value.SetInt(num.Int64())
+	}
+	return value, nil
+}
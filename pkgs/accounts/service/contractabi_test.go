@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestPackMethodCall(t *testing.T) {
+	method, err := parseMethodSig("transfer(address,uint256)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []json.RawMessage{
+		json.RawMessage(`"0xec61c03f719a5c214f60719c3f36bb362a202125"`),
+		json.RawMessage(`"0x3e8"`),
+	}
+	input, err := packMethodCall(method, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a9059cbb" +
+		"000000000000000000000000ec61c03f719a5c214f60719c3f36bb362a202125" +
+		"00000000000000000000000000000000000000000000000000000000000003e8"
+	if got := hex.EncodeToString(input); got != want {
+		t.Fatalf("got call data %s, want %s", got, want)
+	}
+
+	// a json number packs the same
+	args[1] = json.RawMessage(`1000`)
+	if input, err = packMethodCall(method, args); err != nil || hex.EncodeToString(input) != want {
+		t.Fatalf("got call data %x, err %v", input, err)
+	}
+}
+
+func TestPackMethodCallInvalid(t *testing.T) {
+	for _, sig := range []string{"transfer", "(uint256)", "transfer(uint)", "transfer(uint256"} {
+		if _, err := parseMethodSig(sig); err == nil {
+			t.Errorf("parsed invalid signature %s", sig)
+		}
+	}
+
+	method, err := parseMethodSig("set(uint8,int8[2])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range []string{`[256,[1,2]]`, `[-1,[1,2]]`, `[1,[128,2]]`, `[1,[1]]`, `[1]`} {
+		var raws []json.RawMessage
+		if err := json.Unmarshal([]byte(args), &raws); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := packMethodCall(method, raws); err == nil {
+			t.Errorf("packed invalid arguments %s", args)
+		}
+	}
+	if _, err := packMethodCall(method, []json.RawMessage{json.RawMessage(`255`), json.RawMessage(`[-128,"0x7f"]`)}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -12,5 +12,32 @@ var (
 	ErrMissingKeystore = errors.New("not found keystore")
 	ErrAccountExist    = errors.New("addr is not exist")
 	ErrMissingPath = errors.New("not found path")
+	ErrInvalidAddress  = errors.New("neither an address nor an alias")
+	ErrMissingAddress  = errors.New("missing address")
+
+	ErrNoUpstream          = errors.New("no healthy upstream")
+	ErrUpstreamChainId     = errors.New("upstream chain id not matched")
+	ErrUpstreamBehind      = errors.New("upstream falls behind")
+	ErrUpstreamUnsupported = errors.New("not supported in upstream mode")
+	ErrNotUpstreamMode     = errors.New("no upstream configured")
+
+	ErrNoScheduleCondition = errors.New("neither activation time nor height set")
+	ErrScheduledTxExist    = errors.New("transaction already scheduled")
+	ErrScheduledTxNotFound = errors.New("scheduled transaction not found")
+	ErrTooManyScheduledTxs = errors.New("too many scheduled transactions")
+
+	ErrBlobKind               = errors.New("unexpected kind of blob")
+	ErrPairingChainId         = errors.New("chain id of offline signer not matched")
+	ErrPairingSig             = errors.New("paired account not signed by its key")
+	ErrNotPaired              = errors.New("account not paired with an offline signer")
+	ErrTooManySigningRequests = errors.New("too many signing requests")
+	ErrSigningRequestNotFound = errors.New("signing request not found")
+	ErrSignatureMismatch      = errors.New("signature not made by the account of the request")
+
+	ErrInvalidAbi        = errors.New("invalid contract abi")
+	ErrAbiNotFound       = errors.New("contract abi not registered")
+	ErrMethodNotFound    = errors.New("method not found in contract abi")
+	ErrInvalidMethodSig  = errors.New("invalid method signature")
+	ErrInvalidMethodArgs = errors.New("invalid method arguments")
 
 )
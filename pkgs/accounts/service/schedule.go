@@ -0,0 +1,268 @@
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+const (
+	defaultScheduleFile   = "scheduledtx.json"
+	scheduleCheckInterval = 3 * time.Second
+	maxScheduledTxs       = 1024
+	maxScheduleAttempts   = 10 // sends retried before a scheduled transaction is dropped
+)
+
+// ScheduledTx is a signed transaction broadcasted once the time and height reach its activation condition
+type ScheduledTx struct {
+	Hash      crypto.Hash          `json:"hash"`
+	From      crypto.CommonAddress `json:"from"`
+	Nonce     uint64               `json:"nonce"`
+	NotBefore uint64               `json:"notBefore,omitempty"` // unix seconds, 0 no time condition
+	Height    uint64               `json:"height,omitempty"`    // block height, 0 no height condition
+	Created   int64                `json:"created"`
+	Attempts  int                  `json:"attempts,omitempty"`
+	Err       string               `json:"err,omitempty"` // error of the last failed send
+	Raw       common.Bytes         `json:"raw"`
+}
+
+func (scheduled *ScheduledTx) due(now time.Time, height uint64) bool {
+	return uint64(now.Unix()) >= scheduled.NotBefore && height >= scheduled.Height
+}
+
+func (scheduled *ScheduledTx) transaction() (*types.Transaction, error) {
+	tx := &types.Transaction{}
+	if err := binary.Unmarshal(scheduled.Raw, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// txScheduler keep the scheduled transactions, they are written to path on every change
+type txScheduler struct {
+	path string
+	lock sync.Mutex
+	txs  map[crypto.Hash]*ScheduledTx
+}
+
+func newTxScheduler(path string) (*txScheduler, error) {
+	scheduler := &txScheduler{
+		path: path,
+		txs:  make(map[crypto.Hash]*ScheduledTx),
+	}
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scheduler, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	txs := []*ScheduledTx{}
+	if err := json.Unmarshal(content, &txs); err != nil {
+		return nil, err
+	}
+	for _, scheduled := range txs {
+		scheduler.txs[scheduled.Hash] = scheduled
+	}
+	return scheduler, nil
+}
+
+// add schedule a signed transaction, at least one of notBefore and height must be set
+func (scheduler *txScheduler) add(tx *types.Transaction, notBefore, height uint64) (*ScheduledTx, error) {
+	if notBefore == 0 && height == 0 {
+		return nil, ErrNoScheduleCondition
+	}
+	from, err := tx.From()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := binary.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	scheduled := &ScheduledTx{
+		Hash:      *tx.TxHash(),
+		From:      *from,
+		Nonce:     tx.Nonce(),
+		NotBefore: notBefore,
+		Height:    height,
+		Created:   time.Now().Unix(),
+		Raw:       raw,
+	}
+
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	if _, ok := scheduler.txs[scheduled.Hash]; ok {
+		return nil, ErrScheduledTxExist
+	}
+	if len(scheduler.txs) >= maxScheduledTxs {
+		return nil, ErrTooManyScheduledTxs
+	}
+	scheduler.txs[scheduled.Hash] = scheduled
+	return scheduled, scheduler.save()
+}
+
+func (scheduler *txScheduler) cancel(hash crypto.Hash) error {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	if _, ok := scheduler.txs[hash]; !ok {
+		return ErrScheduledTxNotFound
+	}
+	delete(scheduler.txs, hash)
+	return scheduler.save()
+}
+
+// list return the scheduled transactions, oldest first
+func (scheduler *txScheduler) list() []*ScheduledTx {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	txs := make([]*ScheduledTx, 0, len(scheduler.txs))
+	for _, scheduled := range scheduler.txs {
+		copied := *scheduled
+		txs = append(txs, &copied)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Created != txs[j].Created {
+			return txs[i].Created < txs[j].Created
+		}
+		return txs[i].Nonce < txs[j].Nonce
+	})
+	return txs
+}
+
+// needHeight tells whether any scheduled transaction waits for a height
+func (scheduler *txScheduler) needHeight() bool {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	for _, scheduled := range scheduler.txs {
+		if scheduled.Height > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// run send the due transactions in nonce order. Sent ones are removed, failed ones are
+// retried on the next run until they failed maxScheduleAttempts times
+func (scheduler *txScheduler) run(now time.Time, height uint64, send func(*ScheduledTx) error) {
+	due := []*ScheduledTx{}
+	for _, scheduled := range scheduler.list() {
+		if scheduled.due(now, height) {
+			due = append(due, scheduled)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Nonce < due[j].Nonce })
+
+	// the lock is not held while sending, which may call an upstream
+	for _, scheduled := range due {
+		if !scheduler.has(scheduled.Hash) {
+			// canceled meanwhile
+			continue
+		}
+		scheduler.sent(scheduled.Hash, send(scheduled))
+	}
+
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	if err := scheduler.save(); err != nil {
+		log.WithField("err", err).Error("save scheduled transactions")
+	}
+}
+
+func (scheduler *txScheduler) has(hash crypto.Hash) bool {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	_, ok := scheduler.txs[hash]
+	return ok
+}
+
+// sent record the result of sending a scheduled transaction
+func (scheduler *txScheduler) sent(hash crypto.Hash, err error) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+	scheduled, ok := scheduler.txs[hash]
+	if !ok {
+		return
+	}
+	if err == nil {
+		log.WithField("hash", hash.String()).Info("scheduled transaction sent")
+		delete(scheduler.txs, hash)
+		return
+	}
+
+	scheduled.Attempts++
+	scheduled.Err = err.Error()
+	if scheduled.Attempts >= maxScheduleAttempts {
+		log.WithField("hash", hash.String()).WithField("err", err).Error("drop scheduled transaction")
+		delete(scheduler.txs, hash)
+	} else {
+		log.WithField("hash", hash.String()).WithField("err", err).Warn("send scheduled transaction")
+	}
+}
+
+// save write the schedules to a temporary file first, so a crash never leaves a partial file.
+// The lock must be held
+func (scheduler *txScheduler) save() error {
+	txs := make([]*ScheduledTx, 0, len(scheduler.txs))
+	for _, scheduled := range scheduler.txs {
+		txs = append(txs, scheduled)
+	}
+	content, err := json.MarshalIndent(txs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := scheduler.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, scheduler.path)
+}
+
+// currentHeight return the height of the local chain, or of the upstream in wallet-only mode
+func (accountService *AccountService) currentHeight() (uint64, error) {
+	if accountService.upstream != nil {
+		var height uint64
+		err := accountService.upstream.Call(&height, "chain_getMaxHeight")
+		return height, err
+	}
+	return accountService.Chain.BestChain().Tip().Height, nil
+}
+
+// scheduleLoop broadcast scheduled transactions once due until quit is closed
+func (accountService *AccountService) scheduleLoop(quit chan struct{}) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			var height uint64
+			if accountService.scheduler.needHeight() {
+				var err error
+				if height, err = accountService.currentHeight(); err != nil {
+					log.WithField("err", err).Warn("query height for scheduled transactions")
+					continue
+				}
+			}
+			accountService.scheduler.run(now, height, func(scheduled *ScheduledTx) error {
+				tx, err := scheduled.transaction()
+				if err != nil {
+					return err
+				}
+				return accountService.MessageBroadCastor.SendTransaction(tx, true)
+			})
+		case <-quit:
+			return
+		}
+	}
+}
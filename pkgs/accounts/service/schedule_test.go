@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func newScheduleTestScheduler(t *testing.T) (*txScheduler, string) {
+	dir, err := ioutil.TempDir("", "schedule")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, defaultScheduleFile)
+	scheduler, err := newTxScheduler(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scheduler, dir
+}
+
+func TestScheduleRun(t *testing.T) {
+	scheduler, dir := newScheduleTestScheduler(t)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	byTime := &ScheduledTx{Hash: crypto.Hash{1}, Nonce: 1, NotBefore: uint64(now.Unix())}
+	byHeight := &ScheduledTx{Hash: crypto.Hash{2}, Nonce: 2, Height: 100}
+	scheduler.txs[byTime.Hash] = byTime
+	scheduler.txs[byHeight.Hash] = byHeight
+	if err := scheduler.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := 0
+	send := func(scheduled *ScheduledTx) error {
+		sent++
+		return nil
+	}
+	scheduler.run(now.Add(-time.Minute), 0, send)
+	if sent != 0 {
+		t.Fatalf("sent %d transactions before due", sent)
+	}
+	scheduler.run(now, 99, send)
+	if sent != 1 || scheduler.has(byTime.Hash) || !scheduler.has(byHeight.Hash) {
+		t.Fatalf("sent %d transactions at time, want the timed one", sent)
+	}
+
+	// schedules survive a restart
+	reloaded, err := newTxScheduler(scheduler.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs := reloaded.list(); len(txs) != 1 || txs[0].Hash != byHeight.Hash {
+		t.Fatalf("reloaded %v, want the height schedule", txs)
+	}
+	reloaded.run(now, 100, send)
+	if sent != 2 || len(reloaded.list()) != 0 {
+		t.Fatalf("sent %d transactions at height, want 2", sent)
+	}
+}
+
+func TestScheduleRetry(t *testing.T) {
+	scheduler, dir := newScheduleTestScheduler(t)
+	defer os.RemoveAll(dir)
+
+	scheduled := &ScheduledTx{Hash: crypto.Hash{1}, Height: 1}
+	scheduler.txs[scheduled.Hash] = scheduled
+	fail := func(scheduled *ScheduledTx) error {
+		return errors.New("send fail")
+	}
+	for i := 0; i < maxScheduleAttempts-1; i++ {
+		scheduler.run(time.Now(), 1, fail)
+	}
+	txs := scheduler.list()
+	if len(txs) != 1 || txs[0].Attempts != maxScheduleAttempts-1 || txs[0].Err != "send fail" {
+		t.Fatalf("unexpected schedules after failed sends %v", txs)
+	}
+	scheduler.run(time.Now(), 1, fail)
+	if len(scheduler.list()) != 0 {
+		t.Fatal("scheduled transaction kept after max attempts")
+	}
+
+	if err := scheduler.cancel(scheduled.Hash); err != ErrScheduledTxNotFound {
+		t.Fatalf("got %v canceling a dropped schedule, want %v", err, ErrScheduledTxNotFound)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"github.com/drep-project/DREP-Chain/pkgs/evm"
 	"path/filepath"
+	"strings"
 
 	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/blockmgr"
@@ -28,6 +29,16 @@ var (
 		Usage: "keep wallet open",
 	}
 
+	UpstreamFlag = cli.StringFlag{
+		Name:  "upstream",
+		Usage: "Comma separated rpc endpoints of full nodes, run as wallet-only node which query nonce and send transactions through them",
+	}
+
+	UnlockIdleTimeoutFlag = cli.Uint64Flag{
+		Name:  "unlockidletimeout",
+		Usage: "Lock unlocked accounts not used for this many seconds (0 = never)",
+	}
+
 	EnableWalletFlag = cli.BoolFlag{
 		Name:  "enableWallet",
 		Usage: "is wallet flag",
@@ -49,6 +60,10 @@ type AccountService struct {
 	MessageBroadCastor blockmgr.ISendMessage       `service:"blockmgr"`
 	Config             *accountTypes.Config
 	Wallet             *Wallet
+	upstream           *Upstream
+	nonces             *blockmgr.NonceManager
+	scheduler          *txScheduler
+	signing            *signingStore
 	apis               []app.API
 	quit               chan struct{}
 }
@@ -69,17 +84,34 @@ func (accountService *AccountService) Api() []app.API {
 				Wallet:             accountService.Wallet,
 				messageBroadCastor: accountService.MessageBroadCastor,
 				poolQuery:          accountService.PoolQuery,
+				nonces:             accountService.nonces,
 				accountService:     accountService,
 				databaseService:    accountService.DatabaseService,
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "token",
+			Version:   "1.0",
+			Service: &TokenApi{
+				accountService: accountService,
+			},
+			Public: true,
+		},
+		app.API{
+			Namespace: "abi",
+			Version:   "1.0",
+			Service: &AbiApi{
+				accountService: accountService,
+			},
+			Public: true,
+		},
 	}
 }
 
 // Flags flags  enable load js and execute before run
 func (accountService *AccountService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{KeyStoreDirFlag, WalletPasswordFlag}
+	return nil, []cli.Flag{KeyStoreDirFlag, WalletPasswordFlag, UpstreamFlag, UnlockIdleTimeoutFlag}
 }
 
 func (accountService *AccountService) P2pMessages() map[int]interface{} {
@@ -108,6 +140,14 @@ func (accountService *AccountService) Init(executeContext *app.ExecuteContext) e
 		accountService.Config.KeyStoreDir = executeContext.Cli.GlobalString(KeyStoreDirFlag.Name)
 	}
 
+	if executeContext.Cli.GlobalIsSet(UnlockIdleTimeoutFlag.Name) {
+		accountService.Config.UnlockIdleTimeout = executeContext.Cli.GlobalUint64(UnlockIdleTimeoutFlag.Name)
+	}
+
+	if executeContext.Cli.GlobalIsSet(UpstreamFlag.Name) {
+		accountService.Config.Upstreams = strings.Split(executeContext.Cli.GlobalString(UpstreamFlag.Name), ",")
+	}
+
 	//if !accountService.Config.Enable {
 	//	return nil
 	//}
@@ -126,10 +166,50 @@ func (accountService *AccountService) Init(executeContext *app.ExecuteContext) e
 	}
 	//}
 
+	if len(accountService.Config.Upstreams) > 0 {
+		accountService.upstream = NewUpstream(accountService.Config.Upstreams, accountService.Chain.ChainID(), accountService.Config.MaxHeightLag)
+		accountService.PoolQuery = accountService.upstream
+		accountService.MessageBroadCastor = accountService.upstream
+	}
+	accountService.nonces = blockmgr.NewNonceManager(accountService.PoolQuery)
+
+	scheduleFile := accountService.Config.ScheduleFile
+	if scheduleFile == "" {
+		scheduleFile = defaultScheduleFile
+	}
+	if !filepath.IsAbs(scheduleFile) {
+		scheduleFile = filepath.Join(executeContext.CommonConfig.HomeDir, scheduleFile)
+	}
+	accountService.scheduler, err = newTxScheduler(scheduleFile)
+	if err != nil {
+		return err
+	}
+
+	signingFile := accountService.Config.SigningFile
+	if signingFile == "" {
+		signingFile = defaultSigningFile
+	}
+	if !filepath.IsAbs(signingFile) {
+		signingFile = filepath.Join(executeContext.CommonConfig.HomeDir, signingFile)
+	}
+	accountService.signing, err = newSigningStore(signingFile)
+	if err != nil {
+		return err
+	}
+	// nonces of requests still waiting for the offline signer are not assigned again
+	for _, request := range accountService.signing.list() {
+		accountService.nonces.Hold(&request.From, request.Nonce)
+	}
 	return nil
 }
 
 func (accountService *AccountService) Start(executeContext *app.ExecuteContext) error {
+	go accountService.Wallet.autoLockLoop(accountService.quit)
+	go accountService.scheduleLoop(accountService.quit)
+	if accountService.upstream != nil {
+		accountService.upstream.Check()
+		go accountService.upstream.checkLoop(accountService.quit)
+	}
 	if accountService.Config.Enable {
 		return nil
 	}
@@ -137,10 +217,12 @@ func (accountService *AccountService) Start(executeContext *app.ExecuteContext)
 }
 
 func (accountService *AccountService) Stop(executeContext *app.ExecuteContext) error {
-	if accountService.Config == nil || accountService.Config.Enable {
-		return nil
+	if accountService.quit != nil {
+		close(accountService.quit)
+	}
+	if accountService.upstream != nil {
+		accountService.upstream.Close()
 	}
-	close(accountService.quit)
 	return nil
 }
 
@@ -163,3 +245,9 @@ func (accountService *AccountService) CreateWallet(password string) error {
 func (accountService *AccountService) DefaultConfig() *accountTypes.Config {
 	return DefaultConfig
 }
+
+// Nonces return the nonce manager of local accounts, other services sending transactions of
+// them reserve nonces from it so they never clash with the account api
+func (accountService *AccountService) Nonces() *blockmgr.NonceManager {
+	return accountService.nonces
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+/*
+name: Token RPC interface
+usage: Issue and transfer native tokens, which are kept in the storage of accounts without a contract
+prefix:token
+*/
+type TokenApi struct {
+	accountService *AccountService
+}
+
+/*
+ name: issue
+ usage: Issue a new token, the initial supply is minted to the issuer
+ params:
+	1. address of issuer, or its alias
+	2. symbol, 2 to 12 upper case letters or digits
+	3. name
+	4. decimals
+	5. initial supply
+	6. max supply, 0 if unlimited
+	7. gas price
+	8. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_issue","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","GOLD","gold coin",8,"0x2540be400","0x0","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (tokenApi *TokenApi) Issue(issuer AddressOrAlias, symbol, name string, decimals uint8, supply, maxSupply, gasprice, gaslimit *common.Big) (string, error) {
+	data := &types.IssueTokenData{
+		Symbol:    symbol,
+		Name:      name,
+		Decimals:  decimals,
+		Supply:    *supply,
+		MaxSupply: *maxSupply,
+	}
+	txData, err := data.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return tokenApi.send(types.IssueTokenType, issuer, "", txData, gasprice, gaslimit)
+}
+
+/*
+ name: mint
+ usage: Mint more of a token, only the issuer can mint and the supply never exceed the max supply
+ params:
+	1. address of issuer, or its alias
+	2. address of receiver, or its alias
+	3. symbol
+	4. amount
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_mint","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","GOLD","0x100","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (tokenApi *TokenApi) Mint(issuer, to AddressOrAlias, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	return tokenApi.sendAmount(types.MintTokenType, issuer, to, symbol, amount, gasprice, gaslimit)
+}
+
+/*
+ name: burn
+ usage: Burn some token of the account, which reduce the supply
+ params:
+	1. address of holder, or its alias
+	2. symbol
+	3. amount
+	4. gas price
+	5. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_burn","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","GOLD","0x100","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (tokenApi *TokenApi) Burn(from AddressOrAlias, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	return tokenApi.sendAmount(types.BurnTokenType, from, "", symbol, amount, gasprice, gaslimit)
+}
+
+/*
+ name: transfer
+ usage: Transfer token to another address
+ params:
+	1. address of sender, or its alias
+	2. address of receiver, or its alias
+	3. symbol
+	4. amount
+	5. gas price
+	6. gas limit
+ return: transaction hash
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_transfer","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","GOLD","0x100","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":"0x5adb248f2943e12fb91c140bd3d0df6237712061e9abae97345b0869c3daa749"}
+*/
+func (tokenApi *TokenApi) Transfer(from, to AddressOrAlias, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	return tokenApi.sendAmount(types.TransferTokenType, from, to, symbol, amount, gasprice, gaslimit)
+}
+
+/*
+ name: balanceOf
+ usage: Query the token balance of an address at the chain tip
+ params:
+	1. address, or its alias
+	2. symbol
+ return: balance
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_balanceOf","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","GOLD"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":10000000000}
+*/
+func (tokenApi *TokenApi) BalanceOf(addr AddressOrAlias, symbol string) (*big.Int, error) {
+	addrs, err := tokenApi.accountService.resolveAddresses(addr)
+	if err != nil {
+		return nil, err
+	}
+	if tokenApi.accountService.upstream != nil {
+		balance := new(big.Int)
+		if err := tokenApi.accountService.upstream.Call(balance, "token_balanceOf", addrs[0], symbol); err != nil {
+			return nil, err
+		}
+		return balance, nil
+	}
+	trieQuery, err := tokenApi.tipQuery()
+	if err != nil {
+		return nil, err
+	}
+	return trieQuery.GetTokenBalance(addrs[0], symbol), nil
+}
+
+/*
+ name: getToken
+ usage: Query a token by its symbol at the chain tip
+ params:
+	1. symbol
+ return: token
+ example:
+	curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"token_getToken","params":["GOLD"],"id":1}' http://127.0.0.1:10085
+ response:
+	{"jsonrpc":"2.0","id":1,"result":{"symbol":"GOLD","name":"gold coin","decimals":8,"issuer":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","supply":"0x2540be400","maxSupply":"0x0"}}
+*/
+func (tokenApi *TokenApi) GetToken(symbol string) (*types.Token, error) {
+	if tokenApi.accountService.upstream != nil {
+		token := &types.Token{}
+		if err := tokenApi.accountService.upstream.Call(token, "token_getToken", symbol); err != nil {
+			return nil, err
+		}
+		return token, nil
+	}
+	trieQuery, err := tokenApi.tipQuery()
+	if err != nil {
+		return nil, err
+	}
+	return trieQuery.GetToken(symbol)
+}
+
+func (tokenApi *TokenApi) tipQuery() (*chain.TrieQuery, error) {
+	accountService := tokenApi.accountService
+	return chain.NewTrieQuery(accountService.DatabaseService.LevelDb(), accountService.Chain.BestChain().Tip().StateRoot)
+}
+
+func (tokenApi *TokenApi) sendAmount(txType types.TxType, from, to AddressOrAlias, symbol string, amount, gasprice, gaslimit *common.Big) (string, error) {
+	data := &types.TokenAmountData{Symbol: symbol, Amount: *amount}
+	txData, err := data.Marshal()
+	if err != nil {
+		return "", err
+	}
+	return tokenApi.send(txType, from, to, txData, gasprice, gaslimit)
+}
+
+// send sign the token transaction by the wallet and broadcast it, to is left empty if not given
+func (tokenApi *TokenApi) send(txType types.TxType, from, to AddressOrAlias, data []byte, gasprice, gaslimit *common.Big) (string, error) {
+	params := []AddressOrAlias{from}
+	if to != "" {
+		params = append(params, to)
+	}
+	addrs, err := tokenApi.accountService.resolveAddresses(params...)
+	if err != nil {
+		return "", err
+	}
+	fromAddr, toAddr := addrs[0], crypto.CommonAddress{}
+	if len(addrs) > 1 {
+		toAddr = *addrs[1]
+	}
+
+	accountService := tokenApi.accountService
+	nonce := accountService.nonces.Reserve(fromAddr)
+	defer accountService.nonces.Release(fromAddr, nonce)
+	tx := types.NewTokenTransaction(txType, toAddr, data, (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce)
+	err = accountService.Wallet.SignTransaction(fromAddr, tx)
+	if err != nil {
+		return "", err
+	}
+	err = accountService.MessageBroadCastor.SendTransaction(tx, true)
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"github.com/drep-project/rpc"
+)
+
+const (
+	upstreamCallTimeout   = 10 * time.Second
+	upstreamCheckInterval = 15 * time.Second
+	defaultMaxHeightLag   = 10
+)
+
+// UpstreamStatus is the status of an upstream endpoint
+type UpstreamStatus struct {
+	Url     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+	Height  uint64 `json:"height"`
+	Current bool   `json:"current"` //Calls are sent to this endpoint first
+	Err     string `json:"err,omitempty"`
+}
+
+type upstreamEndpoint struct {
+	url     string
+	client  *rpc.Client
+	healthy bool
+	height  uint64
+	err     error
+}
+
+// Upstream send calls to remote full nodes for wallet-only node which has no local chain.
+// Endpoints whose chain id is different from local or whose height fall behind the highest
+// one more than maxHeightLag are skipped, a call is retried on the next healthy endpoint if fail.
+// It implements blockmgr.IBlockMgrPool and blockmgr.ISendMessage so the account api need no change.
+type Upstream struct {
+	chainID      types.ChainIdType
+	maxHeightLag uint64
+
+	lock      sync.RWMutex
+	endpoints []*upstreamEndpoint
+	current   int
+}
+
+func NewUpstream(urls []string, chainID types.ChainIdType, maxHeightLag uint64) *Upstream {
+	if maxHeightLag == 0 {
+		maxHeightLag = defaultMaxHeightLag
+	}
+	upstream := &Upstream{
+		chainID:      chainID,
+		maxHeightLag: maxHeightLag,
+	}
+	for _, url := range urls {
+		upstream.endpoints = append(upstream.endpoints, &upstreamEndpoint{url: url})
+	}
+	return upstream
+}
+
+// Check query chain id and height of all endpoints and update their health
+func (upstream *Upstream) Check() {
+	type checkResult struct {
+		client  *rpc.Client
+		chainID types.ChainIdType
+		height  uint64
+		err     error
+	}
+
+	upstream.lock.RLock()
+	endpoints := make([]*upstreamEndpoint, len(upstream.endpoints))
+	copy(endpoints, upstream.endpoints)
+	upstream.lock.RUnlock()
+
+	results := make([]checkResult, len(endpoints))
+	wg := sync.WaitGroup{}
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, url string, client *rpc.Client) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), upstreamCallTimeout)
+			defer cancel()
+
+			result := &results[i]
+			if client == nil {
+				client, result.err = rpc.DialContext(ctx, url)
+				if result.err != nil {
+					return
+				}
+			}
+			result.client = client
+			if result.err = client.CallContext(ctx, &result.chainID, "chain_getChainId"); result.err != nil {
+				return
+			}
+			result.err = client.CallContext(ctx, &result.height, "chain_getMaxHeight")
+		}(i, endpoint.url, endpoint.client)
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, result := range results {
+		if result.err == nil && result.chainID == upstream.chainID && result.height > highest {
+			highest = result.height
+		}
+	}
+
+	upstream.lock.Lock()
+	defer upstream.lock.Unlock()
+	for i, endpoint := range endpoints {
+		result := results[i]
+		endpoint.client = result.client
+		endpoint.height = result.height
+		endpoint.err = result.err
+		if endpoint.err == nil && result.chainID != upstream.chainID {
+			endpoint.err = fmt.Errorf("%v, local:%d upstream:%d", ErrUpstreamChainId, upstream.chainID, result.chainID)
+		}
+		if endpoint.err == nil && endpoint.height+upstream.maxHeightLag < highest {
+			endpoint.err = fmt.Errorf("%v, height:%d highest:%d", ErrUpstreamBehind, endpoint.height, highest)
+		}
+		if endpoint.healthy != (endpoint.err == nil) {
+			log.WithField("url", endpoint.url).WithField("err", endpoint.err).Info("upstream health changed")
+		}
+		endpoint.healthy = endpoint.err == nil
+	}
+	if len(upstream.endpoints) > 0 && !upstream.endpoints[upstream.current].healthy {
+		upstream.failover(upstream.current)
+	}
+}
+
+// failover move current to the next healthy endpoint after index, caller must hold the lock
+func (upstream *Upstream) failover(index int) {
+	for i := 1; i <= len(upstream.endpoints); i++ {
+		next := (index + i) % len(upstream.endpoints)
+		if upstream.endpoints[next].healthy {
+			if next != upstream.current {
+				log.WithField("from", upstream.endpoints[upstream.current].url).WithField("to", upstream.endpoints[next].url).Info("upstream failover")
+			}
+			upstream.current = next
+			return
+		}
+	}
+}
+
+func (upstream *Upstream) checkLoop(quit chan struct{}) {
+	timer := time.NewTicker(upstreamCheckInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			upstream.Check()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Call send the call to current endpoint, and try other healthy endpoints in turn if fail
+func (upstream *Upstream) Call(result interface{}, method string, args ...interface{}) error {
+	upstream.lock.RLock()
+	start := upstream.current
+	count := len(upstream.endpoints)
+	upstream.lock.RUnlock()
+
+	for i := 0; i < count; i++ {
+		index := (start + i) % count
+		upstream.lock.RLock()
+		endpoint := upstream.endpoints[index]
+		client, healthy := endpoint.client, endpoint.healthy
+		upstream.lock.RUnlock()
+		if !healthy || client == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), upstreamCallTimeout)
+		err := client.CallContext(ctx, result, method, args...)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if _, ok := err.(rpc.Error); ok {
+			//the upstream works but reject the call, other upstreams would do the same
+			return err
+		}
+
+		upstream.lock.Lock()
+		endpoint.healthy = false
+		endpoint.err = err
+		if upstream.current == index {
+			upstream.failover(index)
+		}
+		upstream.lock.Unlock()
+		log.WithField("url", endpoint.url).WithField("method", method).WithField("err", err).Warn("upstream call fail")
+	}
+	return ErrNoUpstream
+}
+
+// Status return status of all endpoints
+func (upstream *Upstream) Status() []*UpstreamStatus {
+	upstream.lock.RLock()
+	defer upstream.lock.RUnlock()
+
+	status := []*UpstreamStatus{}
+	for i, endpoint := range upstream.endpoints {
+		s := &UpstreamStatus{
+			Url:     endpoint.url,
+			Healthy: endpoint.healthy,
+			Height:  endpoint.height,
+			Current: i == upstream.current,
+		}
+		if endpoint.err != nil {
+			s.Err = endpoint.err.Error()
+		}
+		status = append(status, s)
+	}
+	return status
+}
+
+func (upstream *Upstream) Close() {
+	upstream.lock.Lock()
+	defer upstream.lock.Unlock()
+	for _, endpoint := range upstream.endpoints {
+		if endpoint.client != nil {
+			endpoint.client.Close()
+			endpoint.client = nil
+		}
+		endpoint.healthy = false
+	}
+}
+
+func (upstream *Upstream) GetTransactionCount(addr *crypto.CommonAddress) uint64 {
+	var nonce uint64
+	if err := upstream.Call(&nonce, "blockmgr_getTransactionCount", addr); err != nil {
+		log.WithField("addr", addr).WithField("err", err).Error("query nonce from upstream")
+	}
+	return nonce
+}
+
+func (upstream *Upstream) GetPoolTransactions(addr *crypto.CommonAddress) []types.Transactions {
+	txs := []types.Transactions{}
+	if err := upstream.Call(&txs, "blockmgr_getPoolTransactions", addr); err != nil {
+		log.WithField("addr", addr).WithField("err", err).Error("query pool transactions from upstream")
+	}
+	return txs
+}
+
+func (upstream *Upstream) GetPoolMiniPendingNonce(addr *crypto.CommonAddress) uint64 {
+	var nonce uint64
+	if err := upstream.Call(&nonce, "blockmgr_getPoolMiniPendingNonce", addr); err != nil {
+		log.WithField("addr", addr).WithField("err", err).Error("query pending nonce from upstream")
+	}
+	return nonce
+}
+
+func (upstream *Upstream) GetTxInPool(hash string) (*types.Transaction, error) {
+	tx := &types.Transaction{}
+	if err := upstream.Call(tx, "blockmgr_getTxInPool", hash); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SendTransaction send signed transaction to upstream, the upstream broadcast it
+func (upstream *Upstream) SendTransaction(tx *types.Transaction, islocal bool) error {
+	txBytes, err := binary.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	var hash string
+	return upstream.Call(&hash, "blockmgr_sendRawTransaction", common.Bytes(txBytes))
+}
+
+// SendPrivateTransaction send signed transaction to upstream, the upstream send it to its producers only
+func (upstream *Upstream) SendPrivateTransaction(tx *types.Transaction) error {
+	txBytes, err := binary.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	var hash string
+	return upstream.Call(&hash, "blockmgr_sendPrivateRawTransaction", common.Bytes(txBytes))
+}
+
+// BroadcastBlock wallet-only node produce no block
+func (upstream *Upstream) BroadcastBlock(msgType int32, block *types.Block, isLocal bool) {}
+
+// BroadcastTx transactions are broadcasted by upstream in SendTransaction
+func (upstream *Upstream) BroadcastTx(msgType int32, tx *types.Transaction, isLocal bool) {}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/rpc"
+)
+
+type FakeChainApi struct {
+	chainID types.ChainIdType
+	height  uint64
+}
+
+func (api *FakeChainApi) GetChainId() types.ChainIdType { return api.chainID }
+func (api *FakeChainApi) GetMaxHeight() uint64          { return api.height }
+
+type FakeBlockMgrApi struct {
+	nonce uint64
+}
+
+func (api *FakeBlockMgrApi) GetTransactionCount(addr *crypto.CommonAddress) uint64 { return api.nonce }
+
+func newFakeUpstream(t *testing.T, chainID types.ChainIdType, height, nonce uint64) *httptest.Server {
+	server := rpc.NewServer()
+	if err := server.RegisterName("chain", &FakeChainApi{chainID: chainID, height: height}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("blockmgr", &FakeBlockMgrApi{nonce: nonce}); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(server)
+}
+
+func TestUpstreamFailover(t *testing.T) {
+	wrongChain := newFakeUpstream(t, 2, 100, 1)
+	defer wrongChain.Close()
+	behind := newFakeUpstream(t, 1, 50, 2)
+	defer behind.Close()
+	first := newFakeUpstream(t, 1, 100, 3)
+	second := newFakeUpstream(t, 1, 98, 4)
+	defer second.Close()
+
+	upstream := NewUpstream([]string{wrongChain.URL, behind.URL, first.URL, second.URL}, 1, 5)
+	defer upstream.Close()
+	upstream.Check()
+
+	status := upstream.Status()
+	healthy := []bool{false, false, true, true}
+	for i, s := range status {
+		if s.Healthy != healthy[i] {
+			t.Fatalf("endpoint %d expect healthy %v, got %v err %s", i, healthy[i], s.Healthy, s.Err)
+		}
+	}
+	if !status[2].Current {
+		t.Fatal("expect the first healthy endpoint to be current")
+	}
+
+	addr := crypto.CommonAddress{}
+	if nonce := upstream.GetTransactionCount(&addr); nonce != 3 {
+		t.Fatalf("expect nonce from current endpoint 3, got %d", nonce)
+	}
+
+	first.Close()
+	if nonce := upstream.GetTransactionCount(&addr); nonce != 4 {
+		t.Fatalf("expect nonce from failover endpoint 4, got %d", nonce)
+	}
+	if status := upstream.Status(); status[2].Healthy || !status[3].Current {
+		t.Fatal("expect failover to the second endpoint")
+	}
+
+	second.Close()
+	var nonce uint64
+	if err := upstream.Call(&nonce, "blockmgr_getTransactionCount", &addr); err != ErrNoUpstream {
+		t.Fatalf("expect ErrNoUpstream, got %v", err)
+	}
+}
@@ -1,6 +1,14 @@
 package service
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/common/fileutil"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
@@ -29,6 +37,10 @@ type Wallet struct {
 
 	isLock   int32
 	password string
+
+	unlockLock sync.Mutex
+	unlocks    map[crypto.CommonAddress]*unlockState
+	lockFeed   event.Feed
 }
 
 // NewWallet based in config
@@ -36,6 +48,7 @@ func NewWallet(config *accountTypes.Config, chainId types.ChainIdType) (*Wallet,
 	wallet := &Wallet{
 		config:  config,
 		chainId: chainId,
+		unlocks: make(map[crypto.CommonAddress]*unlockState),
 	}
 	wallet.password = config.Password
 	return wallet, nil
@@ -105,7 +118,7 @@ func (wallet *Wallet) GetAccountByAddress(addr *crypto.CommonAddress) (*types.No
 		return nil, ErrClosedWallet
 	}
 
-	return wallet.cacheStore.GetKey(addr)
+	return wallet.unlockedKey(addr)
 }
 
 // GetAccountByAddress query account according to public key
@@ -141,7 +154,7 @@ func (wallet *Wallet) DumpPrivateKey(addr *crypto.CommonAddress) (*secp256k1.Pri
 		return nil, err
 	}
 
-	node, err := wallet.cacheStore.GetKey(addr)
+	node, err := wallet.unlockedKey(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +170,7 @@ func (wallet *Wallet) Sign(addr *crypto.CommonAddress, msg []byte) ([]byte, erro
 		return nil, err
 	}
 
-	node, err := wallet.cacheStore.GetKey(addr)
+	node, err := wallet.unlockedKey(addr)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +181,43 @@ func (wallet *Wallet) Sign(addr *crypto.CommonAddress, msg []byte) ([]byte, erro
 	return sig, nil
 }
 
+// SignTransaction set chain id of wallet to transaction and sign it, the chain id is signed
+// with transaction so it can not be replayed on other networks
+func (wallet *Wallet) SignTransaction(addr *crypto.CommonAddress, tx *types.Transaction) error {
+	tx.Data.ChainId = wallet.chainId
+	sig, err := wallet.Sign(addr, tx.TxHash().Bytes())
+	if err != nil {
+		return err
+	}
+	tx.Sig = sig
+	return nil
+}
+
+// SignSponsoredTransaction set chain id of wallet to transaction and sign it as its sender,
+// the fee payer is signed with transaction so the signature is only valid with its sponsorship
+func (wallet *Wallet) SignSponsoredTransaction(addr, payer *crypto.CommonAddress, tx *types.Transaction) error {
+	tx.Data.ChainId = wallet.chainId
+	sig, err := wallet.Sign(addr, tx.SponsoredHash(payer))
+	if err != nil {
+		return err
+	}
+	tx.Sig = sig
+	return nil
+}
+
+// SponsorTransaction sign a transaction signed by its sender for the fee payer as its fee
+// payer, the fee payer pays the gas of the transaction
+func (wallet *Wallet) SponsorTransaction(payer *crypto.CommonAddress, tx *types.Transaction) error {
+	if tx.IsSponsored() {
+		return types.ErrSponsoredAlready
+	}
+	sig, err := wallet.Sign(payer, tx.FeePayerHash())
+	if err != nil {
+		return err
+	}
+	return tx.Sponsor(sig)
+}
+
 // IsLock query current lock state  0 is locked  1 is unlock
 func (wallet *Wallet) IsLock() bool {
 	//return atomic.LoadInt32(&wallet.isLock) == LOCKED
@@ -183,7 +233,14 @@ func (wallet *Wallet) IsOpen() bool {
 func (wallet *Wallet) Lock(addr *crypto.CommonAddress) error {
 	//atomic.StoreInt32(&wallet.isLock, LOCKED)
 	err := wallet.cacheStore.ClearKey(addr)
-	return err
+	if err != nil {
+		return err
+	}
+	wallet.unlockLock.Lock()
+	delete(wallet.unlocks, *addr)
+	wallet.unlockLock.Unlock()
+	wallet.lockFeed.Send(&AccountLockEvent{Address: *addr, Reason: LockManual})
+	return nil
 }
 
 // UnLock wallet to enable private key
@@ -250,23 +307,7 @@ func (wallet *Wallet) ImportKeyStore(path, password string) ([]*crypto.CommonAdd
 	if err := wallet.checkWallet(WPERMISSION); err != nil {
 		return nil, err
 	}
-	if !fileutil.IsDirExists(path) {
-		return nil, errors.Wrap(ErrMissingKeystore, path)
-	}
-
-	newWallet, err := NewWallet(&accountTypes.Config{
-		Enable:      true,
-		Type:        "keystore",
-		KeyStoreDir: path,
-	}, wallet.chainId)
-	if err != nil {
-		return nil, err
-	}
-	err = newWallet.OpenWallet(password)
-	if err != nil {
-		return nil, err
-	}
-	nodes, err := newWallet.cacheStore.ExportKey(password)
+	nodes, err := wallet.readKeyStore(path, password)
 	if err != nil {
 		return nil, err
 	}
@@ -277,6 +318,10 @@ func (wallet *Wallet) ImportKeyStore(path, password string) ([]*crypto.CommonAdd
 			log.WithField("addr", node.Address.String()).Info("privkey exist")
 			continue
 		}
+		if node.ChainId == 0 {
+			// keystore v3 files carry no chain id
+			node.ChainId = wallet.chainId
+		}
 		err = wallet.cacheStore.StoreKey(node, password)
 		if err != nil {
 			return addrs, err
@@ -285,3 +330,55 @@ func (wallet *Wallet) ImportKeyStore(path, password string) ([]*crypto.CommonAdd
 	}
 	return addrs, nil
 }
+
+// readKeyStore decrypt the keys of a keystore directory, or of a single key file in
+// the native or the keystore v3 format
+func (wallet *Wallet) readKeyStore(path, password string) ([]*types.Node, error) {
+	if !fileutil.IsDirExists(path) {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(ErrMissingKeystore, path)
+		}
+		node, err := accountsComponent.BytesToCryptoNode(contents, password)
+		if err != nil {
+			return nil, err
+		}
+		return []*types.Node{node}, nil
+	}
+
+	// the cache of a wallet holds the unlocked keys only, read all keys of the directory
+	return accountsComponent.NewFileStore(path).ExportKey(password)
+}
+
+// ExportKeyStore write the key of addr as a keystore v3 file encrypted with password,
+// path is either the file or a directory to create it in. The written path is returned
+func (wallet *Wallet) ExportKeyStore(addr *crypto.CommonAddress, password, path string) (string, error) {
+	if err := wallet.checkWallet(WPERMISSION); err != nil {
+		return "", err
+	}
+	node, err := wallet.unlockedKey(addr)
+	if err != nil {
+		return "", err
+	}
+	content, err := accountsComponent.EncryptKeyV3(node, password, accountsComponent.StandardScryptN, accountsComponent.StandardScryptP)
+	if err != nil {
+		return "", err
+	}
+
+	if fileutil.IsDirExists(path) {
+		// same file name as Ethereum keystores
+		name := fmt.Sprintf("UTC--%s--%x", toISO8601(time.Now().UTC()), addr.Bytes())
+		path = filepath.Join(path, name)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", errors.Wrap(ErrExistKeystore, path)
+	}
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func toISO8601(t time.Time) string {
+	return fmt.Sprintf("%04d-%02d-%02dT%02d-%02d-%02d.%09dZ", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
@@ -3,7 +3,6 @@ package service
 import (
 	"bytes"
 	"encoding/hex"
-	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/crypto/sha3"
@@ -23,7 +22,7 @@ var (
 
 func Test_WalletOpend(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
@@ -35,7 +34,7 @@ func Test_WalletOpend(t *testing.T) {
 
 func Test_WalletClosed(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
@@ -61,19 +60,16 @@ func Test_WalletClosed(t *testing.T) {
 
 func Test_WalletLock(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
 	wallet.OpenWallet(password)
-	node, err := wallet.NewAccount()
+	node, err := wallet.NewAccount(password)
 	if err != nil {
 		t.Error(err)
 	}
-	wallet.Lock()
-	if wallet.IsLock() == false {
-		t.Error("expected wallet is lock but got close")
-	}
+	wallet.Lock(node.Address)
 	_, err = wallet.ListAddress()
 	if err != nil {
 		t.Error("expect ListAddress success but fail")
@@ -87,26 +83,30 @@ func Test_WalletLock(t *testing.T) {
 
 func Test_WalletUnLock(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
 	wallet.OpenWallet(password)
+	node, err := wallet.NewAccount(password)
+	if err != nil {
+		t.Error(err)
+	}
 
-	wallet.Lock()
-	if wallet.IsLock() == false {
-		t.Error("expected wallet is lock but got unlock")
+	wallet.Lock(node.Address)
+	if _, err := wallet.DumpPrivateKey(node.Address); err == nil {
+		t.Error("expected account is lock but got unlock")
 	}
 
-	wallet.UnLock(password)
-	if wallet.IsLock() == true {
-		t.Error("expected wallet is unlock but got lock")
+	wallet.UnLock(node.Address, password)
+	if _, err := wallet.DumpPrivateKey(node.Address); err != nil {
+		t.Error("expected account is unlock but got lock")
 	}
 }
 
 func Test_NewAccountAndListAddress(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
@@ -115,7 +115,7 @@ func Test_NewAccountAndListAddress(t *testing.T) {
 	count := 10
 	nodes := make([]*types.Node, count)
 	for i := 0; i < count; i++ {
-		node, err := wallet.NewAccount()
+		node, err := wallet.NewAccount(password)
 		if err != nil {
 			t.Error(err)
 		}
@@ -129,7 +129,7 @@ func Test_NewAccountAndListAddress(t *testing.T) {
 	for _, node := range nodes {
 		isFind := false
 		for _, addr := range addresses {
-			if node.Address == addr {
+			if node.Address.String() == addr {
 				isFind = true
 				break
 			}
@@ -142,7 +142,7 @@ func Test_NewAccountAndListAddress(t *testing.T) {
 
 func Test_NewAccountAndDumpPrivateKey(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
@@ -151,7 +151,7 @@ func Test_NewAccountAndDumpPrivateKey(t *testing.T) {
 	count := 10
 	nodes := make([]*types.Node, count)
 	for i := 0; i < count; i++ {
-		node, err := wallet.NewAccount()
+		node, err := wallet.NewAccount(password)
 		if err != nil {
 			t.Error(err)
 		}
@@ -170,12 +170,12 @@ func Test_NewAccountAndDumpPrivateKey(t *testing.T) {
 
 func Test_Sign(t *testing.T) {
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
 	wallet.OpenWallet(password)
-	node, err := wallet.NewAccount()
+	node, err := wallet.NewAccount(password)
 	if err != nil {
 		t.Error(err)
 	}
@@ -189,12 +189,12 @@ func Test_Sign(t *testing.T) {
 
 func TestWallet_ImportPrivKey(t *testing.T) {
 	testData := map[string]string{
-		"0xc9a53673960f8dea4aecadca77bf363c2b21b4ce": "e2eee3e0791242f1eabeb33e6c2f474a6932b04153ef9f14c0ffad205c88be2e",
-		"0x8faf3fa18d0a53d3ef02d3bdcd35d43c71439aec": "f8eb7b53e9eb00465cde6309d7aa2942dc528847deab7dc251b1b10d420fa994",
-		"0x2670e46875fab57293aaeb442f3a901be06a5998": "52fc9a3eb26b3b3d4545d8b1e85c166226deaf69664d07b15e3f0254a7723bed",
+		"0x1162b55022daa4fbc6a5932fc3b42438804dc4df": "e2eee3e0791242f1eabeb33e6c2f474a6932b04153ef9f14c0ffad205c88be2e",
+		"0x0d31c09c7460fe94d90d73e0ee19b3a38b5280c9": "f8eb7b53e9eb00465cde6309d7aa2942dc528847deab7dc251b1b10d420fa994",
+		"0x1d8c6ef665fafdf32cabbc33fee32b7051c81816": "52fc9a3eb26b3b3d4545d8b1e85c166226deaf69664d07b15e3f0254a7723bed",
 	}
 	password := "password"
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		t.Error(err)
 	}
@@ -203,11 +203,11 @@ func TestWallet_ImportPrivKey(t *testing.T) {
 	for _, pri := range testData {
 		privBytes, _ := hex.DecodeString(pri)
 		priv, _ := secp256k1.PrivKeyFromScalar(privBytes)
-		wallet.ImportPrivKey(priv)
+		wallet.ImportPrivKey(priv, password)
 	}
 
 	for addrStr, priStr := range testData {
-		addr := crypto.String2Address(addrStr)
+		addr := crypto.HexToAddress(addrStr)
 		pri, err := wallet.DumpPrivateKey(&addr)
 		if err != nil {
 			t.Error(err)
@@ -221,9 +221,9 @@ func TestWallet_ImportPrivKey(t *testing.T) {
 
 func Test_ImportKeyStore(t *testing.T) {
 	testData := map[string]string{
-		"0xc9a53673960f8dea4aecadca77bf363c2b21b4ce": "e2eee3e0791242f1eabeb33e6c2f474a6932b04153ef9f14c0ffad205c88be2e",
-		"0x8faf3fa18d0a53d3ef02d3bdcd35d43c71439aec": "f8eb7b53e9eb00465cde6309d7aa2942dc528847deab7dc251b1b10d420fa994",
-		"0x2670e46875fab57293aaeb442f3a901be06a5998": "52fc9a3eb26b3b3d4545d8b1e85c166226deaf69664d07b15e3f0254a7723bed",
+		"0x1162b55022daa4fbc6a5932fc3b42438804dc4df": "e2eee3e0791242f1eabeb33e6c2f474a6932b04153ef9f14c0ffad205c88be2e",
+		"0x0d31c09c7460fe94d90d73e0ee19b3a38b5280c9": "f8eb7b53e9eb00465cde6309d7aa2942dc528847deab7dc251b1b10d420fa994",
+		"0x1d8c6ef665fafdf32cabbc33fee32b7051c81816": "52fc9a3eb26b3b3d4545d8b1e85c166226deaf69664d07b15e3f0254a7723bed",
 	}
 	oldWalletPath := "test_import_key_store_oldwallet"
 	newWalletPath := "test_import_key_store_newwallet"
@@ -242,7 +242,7 @@ func Test_ImportKeyStore(t *testing.T) {
 		for _, pri := range testData {
 			privBytes, _ := hex.DecodeString(pri)
 			priv, _ := secp256k1.PrivKeyFromScalar(privBytes)
-			wallet.ImportPrivKey(priv)
+			wallet.ImportPrivKey(priv, oldPassword)
 		}
 	}
 
@@ -254,7 +254,7 @@ func Test_ImportKeyStore(t *testing.T) {
 	newWallet.ImportKeyStore(oldWalletPath, oldPassword)
 
 	for addrStr, priStr := range testData {
-		addr := crypto.String2Address(addrStr)
+		addr := crypto.HexToAddress(addrStr)
 		pri, err := newWallet.DumpPrivateKey(&addr)
 		if err != nil {
 			t.Error(err)
@@ -272,7 +272,7 @@ func getWallet(path, password string) (*Wallet, error) {
 		Type:        "keystore",
 		KeyStoreDir: path,
 	}
-	wallet, err := NewWallet(testConfig, app.ChainIdType{})
+	wallet, err := NewWallet(testConfig, 0)
 	if err != nil {
 		return nil, err
 	}
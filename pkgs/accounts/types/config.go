@@ -5,4 +5,19 @@ type Config struct {
 	Type        string `json:"type,omitempty"`
 	KeyStoreDir string `json:"keyStoreDir,omitempty"`
 	Password    string `json:"password,omitempty"`
+
+	// UnlockIdleTimeout lock unlocked accounts not used for this many seconds, 0 never lock idle accounts
+	UnlockIdleTimeout uint64 `json:"unlockIdleTimeout,omitempty"`
+
+	// Upstreams are remote rpc endpoints of full nodes, nonces are queried and transactions are sent
+	// through them instead of local chain if set
+	Upstreams    []string `json:"upstreams,omitempty"`
+	MaxHeightLag uint64   `json:"maxHeightLag,omitempty"` //Upstreams fall behind the highest one more than this are skipped
+
+	// ScheduleFile keep scheduled transactions across restarts, relative to the home dir
+	ScheduleFile string `json:"scheduleFile,omitempty"`
+
+	// SigningFile keep the accounts paired with an offline signer and the requests waiting for its
+	// signatures, relative to the home dir
+	SigningFile string `json:"signingFile,omitempty"`
 }
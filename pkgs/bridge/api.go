@@ -0,0 +1,168 @@
+package bridge
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
+)
+
+// BridgeStatus is the relay progress and the wrapped supply at the best chain tip
+type BridgeStatus struct {
+	TipNumber     uint64                 `json:"tipNumber"`
+	TipHash       crypto.Hash            `json:"tipHash"`
+	Confirmations uint64                 `json:"confirmations"`
+	LockContract  crypto.CommonAddress   `json:"lockContract"`
+	Relayers      []crypto.CommonAddress `json:"relayers"`
+	Supply        string                 `json:"supply"`
+}
+
+/*
+name: Bridge
+usage: Deposit ether locked on ethereum and withdraw the wrapped asset back
+prefix:bridge
+*/
+type BridgeApi struct {
+	service *BridgeService
+}
+
+/*
+ name: deposit
+ usage: Prove the first lock event of an ethereum transaction and mint the locked amount to its recipient, the block of the transaction must be relayed and confirmed
+ params:
+	1. address of the sender of the bridge transaction, or its alias
+	2. hash of the ethereum lock transaction
+	3. gas price
+	4. gas uplimit of transaction
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"bridge_deposit","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x4e4c1a8a1f3e5d09ae29ac6a5c4bf0a7b3ae8edbdce39a0d4f7a2c6b1d9f10a2","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (api *BridgeApi) Deposit(from accountService.AddressOrAlias, ethTxHash crypto.Hash, gasprice, gaslimit *common.Big) (string, error) {
+	if api.service.eth == nil {
+		return "", ErrEthNotConfigured
+	}
+	fromAddr, err := api.service.AccountService.ResolveAddress(from)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ethCallTimeout)
+	defer cancel()
+	msg, err := api.service.eth.depositMsg(ctx, ethTxHash, api.service.params.LockContract)
+	if err != nil {
+		return "", err
+	}
+	// refuse early what the bridge transaction would fail with
+	op, err := api.service.tipState()
+	if err != nil {
+		return "", err
+	}
+	if _, err := checkConfirmed(op, msg.BlockHash, api.service.params.Confirmations); err != nil {
+		return "", err
+	}
+	if deposited, err := op.deposited(depositKey(msg.BlockHash, msg.TxIndex, msg.LogIndex)); err != nil {
+		return "", err
+	} else if deposited {
+		return "", ErrDeposited
+	}
+
+	data, err := EncodeBridgeData(DepositOp, msg)
+	if err != nil {
+		return "", err
+	}
+	tx, err := api.service.sendTransaction(fromAddr, data, (*big.Int)(gasprice), (*big.Int)(gaslimit))
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: withdraw
+ usage: Burn wrapped amount of an account, the lock contract releases it to the recipient on ethereum
+ params:
+	1. address of the account burning wrapped amount, or its alias
+	2. recipient address on ethereum
+	3. amount
+	4. gas price
+	5. gas uplimit of transaction
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"bridge_withdraw","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486","0x111","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (api *BridgeApi) Withdraw(from accountService.AddressOrAlias, recipient crypto.CommonAddress, amount, gasprice, gaslimit *common.Big) (string, error) {
+	fromAddr, err := api.service.AccountService.ResolveAddress(from)
+	if err != nil {
+		return "", err
+	}
+	data, err := EncodeBridgeData(WithdrawOp, &WithdrawMsg{Recipient: recipient, Amount: *amount})
+	if err != nil {
+		return "", err
+	}
+	tx, err := api.service.sendTransaction(fromAddr, data, (*big.Int)(gasprice), (*big.Int)(gaslimit))
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: getBalance
+ usage: Query the wrapped balance of an address at the latest block
+ params:
+	1. Query address
+ return: wrapped balance
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"bridge_getBalance","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":"1000000000000000000"}
+*/
+func (api *BridgeApi) GetBalance(addr crypto.CommonAddress) (string, error) {
+	op, err := api.service.tipState()
+	if err != nil {
+		return "", err
+	}
+	balance, err := op.Balance(&addr)
+	if err != nil {
+		return "", err
+	}
+	return balance.String(), nil
+}
+
+/*
+ name: getStatus
+ usage: Query the relayed ethereum tip and the wrapped supply at the latest block
+ params:
+ return: relay tip, bridge parameters and wrapped supply
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"bridge_getStatus","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"tipNumber":12000064,"tipHash":"0x9b5a2f4c8e1d7a3b6c0f5e2d8a4b1c7e3f9d6a0b5c2e8f4a1d7b3c9e6f0a2d5b","confirmations":12,"lockContract":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","relayers":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5"],"supply":"1000000000000000000"}}
+*/
+func (api *BridgeApi) GetStatus() (*BridgeStatus, error) {
+	op, err := api.service.tipState()
+	if err != nil {
+		return nil, err
+	}
+	params := api.service.params
+	status := &BridgeStatus{
+		Confirmations: params.Confirmations,
+		LockContract:  params.LockContract,
+		Relayers:      params.Relayers,
+	}
+	tipHash, tip, err := op.tip()
+	if err != nil {
+		return nil, err
+	}
+	if tip != nil {
+		status.TipNumber, status.TipHash = tip.Number, tipHash
+	}
+	supply, err := op.Supply()
+	if err != nil {
+		return nil, err
+	}
+	status.Supply = supply.String()
+	return status, nil
+}
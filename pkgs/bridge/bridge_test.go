@@ -0,0 +1,159 @@
+package bridge
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethparams "github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestVerifyLock(t *testing.T) {
+	contract := crypto.HexToAddress("0x7923a30bbfbcb998a6534d56b313e68c8e0c594a")
+	sender := crypto.HexToAddress("0x3ebcbe7cb440dd8c52940a2963472380afbb56c5")
+	recipient := crypto.HexToAddress("0x8a8e541ddd1272d53729164c70197221a3c27486")
+	success := hexutil.Uint64(1)
+	receipts := []*rpcEthReceipt{
+		{Status: &success, CumulativeGasUsed: 21000, Bloom: make([]byte, 256)},
+		{Type: 2, Status: &success, CumulativeGasUsed: 80000, Bloom: make([]byte, 256), Logs: []*rpcEthLog{{
+			Address: contract,
+			Topics:  []crypto.Hash{LockedEventSig, crypto.Bytes2Hash(sender[:]), crypto.Bytes2Hash(recipient[:])},
+			Data:    crypto.Big2Hash(big.NewInt(1000)).Bytes(),
+		}}},
+	}
+	receiptTrie, _ := trie.New(crypto.Hash{}, trie.NewDatabase(memorydb.New()))
+	for i, receipt := range receipts {
+		value, err := receipt.encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		receiptTrie.Update(receiptKey(uint64(i)), value)
+	}
+	root := receiptTrie.Hash()
+	prove := func(index uint64) [][]byte {
+		proof := &proofList{}
+		if err := receiptTrie.Prove(receiptKey(index), 0, proof); err != nil {
+			t.Fatal(err)
+		}
+		return *proof
+	}
+
+	lock, err := verifyLock(root, 1, 0, prove(1), contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock.Sender != sender || lock.Recipient != recipient || lock.Amount.Int64() != 1000 {
+		t.Fatalf("unexpected lock %v", lock)
+	}
+	if _, err := verifyLock(root, 1, 1, prove(1), contract); err != ErrNoLockEvent {
+		t.Fatalf("missing log verified, %v", err)
+	}
+	if _, err := verifyLock(root, 1, 0, prove(1), sender); err != ErrNoLockEvent {
+		t.Fatalf("log of another contract verified, %v", err)
+	}
+	if _, err := verifyLock(root, 0, 0, prove(0), contract); err != ErrNoLockEvent {
+		t.Fatalf("receipt without lock verified, %v", err)
+	}
+	if _, err := verifyLock(root, 0, 0, prove(1), contract); err != ErrInvalidProof {
+		t.Fatalf("proof of another receipt verified, %v", err)
+	}
+	if _, err := verifyLock(crypto.Hash{1}, 1, 0, prove(1), contract); err != ErrInvalidProof {
+		t.Fatalf("proof verified against a wrong root, %v", err)
+	}
+}
+
+func TestRelayedChain(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &BridgeOp{trieStore}
+	relay := func(hash, parent crypto.Hash, number uint64, difficulty int64) {
+		header := &relayedHeader{Number: number, ParentHash: parent}
+		header.Difficulty.SetInt64(difficulty)
+		header.TotalDifficulty.SetInt64(difficulty)
+		if relayed, _ := op.header(parent); relayed != nil {
+			header.TotalDifficulty.Add(&header.TotalDifficulty, &relayed.TotalDifficulty)
+		}
+		if err := op.insertHeader(hash, header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a1, a2, a3, b2, b3, c2 := crypto.Hash{0xa1}, crypto.Hash{0xa2}, crypto.Hash{0xa3}, crypto.Hash{0xb2}, crypto.Hash{0xb3}, crypto.Hash{0xc2}
+	relay(a1, crypto.Hash{}, 1, 10)
+	relay(a2, a1, 2, 10)
+	relay(b2, a1, 2, 10)
+	if canonical, _ := op.canonical(2); canonical != a2 {
+		t.Fatalf("branch of same weight became canonical")
+	}
+	if _, err := checkConfirmed(op, a1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := checkConfirmed(op, a2, 2); err != ErrNotConfirmed {
+		t.Fatalf("tip confirmed twice, %v", err)
+	}
+
+	relay(b3, b2, 3, 10)
+	tipHash, tip, _ := op.tip()
+	if tipHash != b3 || tip.Number != 3 {
+		t.Fatalf("tip %v not moved to heavier branch", tipHash)
+	}
+	if canonical, _ := op.canonical(2); canonical != b2 {
+		t.Fatalf("canonical header 2 not moved to heavier branch")
+	}
+	if _, err := checkConfirmed(op, a2, 1); err != ErrNotCanonical {
+		t.Fatalf("header of reorganized branch confirmed, %v", err)
+	}
+
+	relay(a3, a2, 3, 5)
+	if tipHash, _, _ := op.tip(); tipHash != b3 {
+		t.Fatalf("tip moved to lighter branch of same number")
+	}
+	relay(c2, a1, 2, 25)
+	tipHash, tip, _ = op.tip()
+	if tipHash != c2 || tip.Number != 2 {
+		t.Fatalf("tip %v not moved to heavier shorter branch", tipHash)
+	}
+	if canonical, _ := op.canonical(3); canonical != (crypto.Hash{}) {
+		t.Fatalf("canonical header 3 kept above heavier shorter branch")
+	}
+}
+
+func TestCheckDifficulty(t *testing.T) {
+	parent := &relayedHeader{Number: 1000000, Time: 1500000000}
+	parent.Difficulty.SetUint64(3000000000000)
+	parent.UncleHash = crypto.Hash(ethtypes.EmptyUncleHash)
+	header := &ethtypes.Header{Number: big.NewInt(1000001), Time: parent.Time + 10}
+	header.Difficulty = ethash.CalcDifficulty(ethparams.MainnetChainConfig, header.Time, parent.parentHeader())
+
+	if err := checkDifficulty(ethparams.MainnetChainConfig, header, parent); err != nil {
+		t.Fatal(err)
+	}
+	header.Difficulty = new(big.Int).Add(header.Difficulty, big.NewInt(1))
+	if err := checkDifficulty(ethparams.MainnetChainConfig, header, parent); err != ErrHeaderDifficulty {
+		t.Fatalf("wrong difficulty accepted, %v", err)
+	}
+	header.Time = parent.Time
+	if err := checkDifficulty(ethparams.MainnetChainConfig, header, parent); err != ErrHeaderTime {
+		t.Fatalf("header not after its parent accepted, %v", err)
+	}
+}
+
+func TestLaterForkHeaderRefused(t *testing.T) {
+	header := &ethHeader{Bloom: make([]byte, 256), Nonce: make([]byte, 8), Difficulty: big.NewInt(1), Number: big.NewInt(1)}
+	if _, err := header.gethHeader(); err != nil {
+		t.Fatal(err)
+	}
+	baseFee, _ := rlp.EncodeToBytes(big.NewInt(7))
+	header.Rest = []rlp.RawValue{baseFee}
+	if _, err := header.gethHeader(); err != ErrUnsealedHeader {
+		t.Fatalf("header of a later fork accepted, %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	ethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// GenesisSection is the section of genesis holding BridgeParams
+const GenesisSection = "Bridge"
+
+const defaultConfirmations = 12
+
+// BridgeParams decide which relayed headers and deposits a block accepts. A node reading them
+// from its own config could fork off the chain, so they are set in genesis
+type BridgeParams struct {
+	Relayers       []crypto.CommonAddress `json:"relayers"`       //Accounts allowed to relay ethereum headers
+	Checkpoint     crypto.Hash            `json:"checkpoint"`     //Hash of the ethereum header the relay starts from
+	LockContract   crypto.CommonAddress   `json:"lockContract"`   //Ethereum contract emitting the lock events
+	Confirmations  uint64                 `json:"confirmations"`  //Relayed headers from a lock block to the relay tip before it is minted, 12 if omitted
+	EthChainConfig *ethparams.ChainConfig `json:"ethChainConfig"` //Forks of the relayed chain, they set the difficulty of its headers, ethereum main network if omitted
+}
+
+// loadBridgeParams read the bridge section of genesis, a chain without it has no relayer and
+// every relay is refused
+func loadBridgeParams(genesisParams *chain.GenesisParams) (*BridgeParams, error) {
+	params := &BridgeParams{}
+	if _, err := genesisParams.Section(GenesisSection, params); err != nil {
+		return nil, err
+	}
+	if params.Confirmations == 0 {
+		params.Confirmations = defaultConfirmations
+	}
+	if params.EthChainConfig == nil {
+		params.EthChainConfig = ethparams.MainnetChainConfig
+	}
+	return params, nil
+}
+
+type BridgeConfig struct {
+	Enable        bool                 `json:"enable"`
+	EthUrl        string               `json:"ethUrl"`
+	Relay         bool                 `json:"relay"`         //Relay ethereum headers, Relayer must be one of the relayers in genesis and unlocked
+	Relayer       crypto.CommonAddress `json:"relayer"`       //Account signing relayed headers
+	RelayInterval uint64               `json:"relayInterval"` //Seconds between polls of ethereum head
+	GasPrice      uint64               `json:"gasPrice"`      //Gas price of relay transactions
+	GasLimit      uint64               `json:"gasLimit"`      //Gas limit of relay transactions
+}
+
+var (
+	DefaultConfig = &BridgeConfig{
+		Enable:        false,
+		RelayInterval: 15,
+		GasPrice:      1,
+		GasLimit:      5000000,
+	}
+)
@@ -0,0 +1,29 @@
+package bridge
+
+import "errors"
+
+var (
+	ErrUnknownBridgeOp  = errors.New("unknown bridge operation")
+	ErrNotRelayer       = errors.New("sender is not a relayer")
+	ErrNoHeaders        = errors.New("no header to relay")
+	ErrTooManyHeaders   = errors.New("too many headers relayed in a transaction")
+	ErrUnknownParent    = errors.New("parent of ethereum header not relayed")
+	ErrHeaderNumber     = errors.New("ethereum header number not follow its parent")
+	ErrHeaderTime       = errors.New("ethereum header time not after its parent")
+	ErrHeaderDifficulty = errors.New("ethereum header difficulty not match its parent")
+	ErrInvalidSeal      = errors.New("invalid ethereum header seal")
+	ErrUnsealedHeader   = errors.New("ethereum header of a later fork has no verifiable proof of work")
+	ErrUnknownHeader    = errors.New("ethereum header not relayed")
+	ErrNotCanonical     = errors.New("ethereum header not in relayed main chain")
+	ErrNotConfirmed     = errors.New("ethereum header not confirmed")
+	ErrDeposited        = errors.New("lock event already minted")
+	ErrInvalidProof     = errors.New("invalid receipt proof")
+	ErrLockFailed       = errors.New("lock transaction failed")
+	ErrNoLockEvent      = errors.New("no lock event of lock contract in receipt")
+	ErrInvalidAmount    = errors.New("amount must be positive")
+	ErrBalance          = errors.New("not enough wrapped balance")
+	ErrReceiptRoot      = errors.New("receipts not match receipt root of ethereum header")
+	ErrHeaderHash       = errors.New("encoded ethereum header not match its hash")
+	ErrReorgTooDeep     = errors.New("ethereum reorganize deeper than relayed headers")
+	ErrEthNotConfigured = errors.New("ethereum rpc url not configured")
+)
@@ -0,0 +1,242 @@
+package bridge
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// LockedEventSig is the topic of Locked(address indexed sender, address indexed recipient, uint256 amount),
+	// emitted by the lock contract when ether is locked for recipient on drep
+	LockedEventSig = crypto.Keccak256Hash([]byte("Locked(address,address,uint256)"))
+	// MintedEventSig is the topic of Minted(address indexed recipient, uint256 amount)
+	MintedEventSig = crypto.Keccak256Hash([]byte("Minted(address,uint256)"))
+	// BurnedEventSig is the topic of Burned(address indexed sender, address indexed recipient, uint256 amount, uint256 nonce),
+	// the lock contract releases amount to recipient on ethereum once it is seen
+	BurnedEventSig = crypto.Keccak256Hash([]byte("Burned(address,address,uint256,uint256)"))
+)
+
+// ethHeader is an ethereum block header. The fields added by later forks are kept undecoded
+// in Rest, so the header is hashed as it is relayed
+type ethHeader struct {
+	ParentHash  crypto.Hash
+	UncleHash   crypto.Hash
+	Coinbase    crypto.CommonAddress
+	Root        crypto.Hash
+	TxHash      crypto.Hash
+	ReceiptHash crypto.Hash
+	Bloom       []byte
+	Difficulty  *big.Int
+	Number      *big.Int
+	GasLimit    uint64
+	GasUsed     uint64
+	Time        uint64
+	Extra       []byte
+	MixDigest   crypto.Hash
+	Nonce       []byte
+	Rest        []rlp.RawValue `rlp:"tail"`
+}
+
+func decodeEthHeader(raw []byte) (*ethHeader, crypto.Hash, error) {
+	header := &ethHeader{}
+	if err := rlp.DecodeBytes(raw, header); err != nil {
+		return nil, crypto.Hash{}, err
+	}
+	if !header.Number.IsUint64() {
+		return nil, crypto.Hash{}, ErrHeaderNumber
+	}
+	return header, crypto.Keccak256Hash(raw), nil
+}
+
+type ethLog struct {
+	Address crypto.CommonAddress
+	Topics  []crypto.Hash
+	Data    []byte
+}
+
+// ethReceipt is the consensus encoding of an ethereum receipt
+type ethReceipt struct {
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Bloom             []byte
+	Logs              []*ethLog
+}
+
+// decodeEthReceipt decode a receipt as it is stored in the receipt trie, typed receipts are
+// prefixed by their type
+func decodeEthReceipt(value []byte) (*ethReceipt, error) {
+	if len(value) > 0 && value[0] < 0x80 {
+		value = value[1:]
+	}
+	receipt := &ethReceipt{}
+	if err := rlp.DecodeBytes(value, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// succeeded is true if the status of receipt is successful, or it is a receipt before byzantium
+// which has the post state root instead
+func (receipt *ethReceipt) succeeded() bool {
+	return len(receipt.PostStateOrStatus) == crypto.HashLength ||
+		(len(receipt.PostStateOrStatus) == 1 && receipt.PostStateOrStatus[0] == 1)
+}
+
+// lockEvent is a Locked event of the lock contract
+type lockEvent struct {
+	Sender    crypto.CommonAddress
+	Recipient crypto.CommonAddress
+	Amount    *big.Int
+}
+
+// receiptKey is the key of the receipt of the transaction at index in the receipt trie
+func receiptKey(index uint64) []byte {
+	key, _ := rlp.EncodeToBytes(index)
+	return key
+}
+
+// verifyLock check the proof of the receipt at txIndex against receiptHash, and read the lock
+// event at logIndex of it
+func verifyLock(receiptHash crypto.Hash, txIndex, logIndex uint64, proof [][]byte, contract crypto.CommonAddress) (*lockEvent, error) {
+	proofDb := memorydb.New()
+	for _, node := range proof {
+		proofDb.Put(crypto.Keccak256Hash(node).Bytes(), node)
+	}
+	value, _, err := trie.VerifyProof(receiptHash, receiptKey(txIndex), proofDb)
+	if err != nil || value == nil {
+		return nil, ErrInvalidProof
+	}
+	receipt, err := decodeEthReceipt(value)
+	if err != nil {
+		return nil, ErrInvalidProof
+	}
+	if !receipt.succeeded() {
+		return nil, ErrLockFailed
+	}
+	if logIndex >= uint64(len(receipt.Logs)) {
+		return nil, ErrNoLockEvent
+	}
+	lockLog := receipt.Logs[logIndex]
+	if lockLog.Address != contract || len(lockLog.Topics) != 3 || lockLog.Topics[0] != LockedEventSig || len(lockLog.Data) < crypto.HashLength {
+		return nil, ErrNoLockEvent
+	}
+	return &lockEvent{
+		Sender:    crypto.BytesToAddress(lockLog.Topics[1].Bytes()),
+		Recipient: crypto.BytesToAddress(lockLog.Topics[2].Bytes()),
+		Amount:    new(big.Int).SetBytes(lockLog.Data[:crypto.HashLength]),
+	}, nil
+}
+
+// rpcEthHeader is a header returned by eth_getBlockByNumber and eth_getBlockByHash
+type rpcEthHeader struct {
+	Hash             crypto.Hash          `json:"hash"`
+	ParentHash       crypto.Hash          `json:"parentHash"`
+	UncleHash        crypto.Hash          `json:"sha3Uncles"`
+	Coinbase         crypto.CommonAddress `json:"miner"`
+	Root             crypto.Hash          `json:"stateRoot"`
+	TxHash           crypto.Hash          `json:"transactionsRoot"`
+	ReceiptHash      crypto.Hash          `json:"receiptsRoot"`
+	Bloom            hexutil.Bytes        `json:"logsBloom"`
+	Difficulty       *hexutil.Big         `json:"difficulty"`
+	Number           *hexutil.Big         `json:"number"`
+	GasLimit         hexutil.Uint64       `json:"gasLimit"`
+	GasUsed          hexutil.Uint64       `json:"gasUsed"`
+	Time             hexutil.Uint64       `json:"timestamp"`
+	Extra            hexutil.Bytes        `json:"extraData"`
+	MixDigest        crypto.Hash          `json:"mixHash"`
+	Nonce            hexutil.Bytes        `json:"nonce"`
+	BaseFee          *hexutil.Big         `json:"baseFeePerGas"`
+	WithdrawalsHash  *crypto.Hash         `json:"withdrawalsRoot"`
+	BlobGasUsed      *hexutil.Uint64      `json:"blobGasUsed"`
+	ExcessBlobGas    *hexutil.Uint64      `json:"excessBlobGas"`
+	ParentBeaconRoot *crypto.Hash         `json:"parentBeaconBlockRoot"`
+	RequestsHash     *crypto.Hash         `json:"requestsHash"`
+	Transactions     []crypto.Hash        `json:"transactions"`
+}
+
+// encode the header as it is hashed by ethereum, the hash is checked so headers of forks
+// not known here are refused
+func (header *rpcEthHeader) encode() ([]byte, error) {
+	fields := []interface{}{
+		header.ParentHash, header.UncleHash, header.Coinbase, header.Root, header.TxHash, header.ReceiptHash,
+		[]byte(header.Bloom), (*big.Int)(header.Difficulty), (*big.Int)(header.Number), uint64(header.GasLimit),
+		uint64(header.GasUsed), uint64(header.Time), []byte(header.Extra), header.MixDigest, []byte(header.Nonce),
+	}
+	// the fields of later forks are appended in order, each fork requires the ones before
+	optional := []interface{}{}
+	if header.BaseFee != nil {
+		optional = append(optional, (*big.Int)(header.BaseFee))
+	}
+	if header.WithdrawalsHash != nil {
+		optional = append(optional, *header.WithdrawalsHash)
+	}
+	if header.BlobGasUsed != nil {
+		optional = append(optional, uint64(*header.BlobGasUsed))
+	}
+	if header.ExcessBlobGas != nil {
+		optional = append(optional, uint64(*header.ExcessBlobGas))
+	}
+	if header.ParentBeaconRoot != nil {
+		optional = append(optional, *header.ParentBeaconRoot)
+	}
+	if header.RequestsHash != nil {
+		optional = append(optional, *header.RequestsHash)
+	}
+	raw, err := rlp.EncodeToBytes(append(fields, optional...))
+	if err != nil {
+		return nil, err
+	}
+	if crypto.Keccak256Hash(raw) != header.Hash {
+		return nil, ErrHeaderHash
+	}
+	return raw, nil
+}
+
+type rpcEthLog struct {
+	Address crypto.CommonAddress `json:"address"`
+	Topics  []crypto.Hash        `json:"topics"`
+	Data    hexutil.Bytes        `json:"data"`
+}
+
+// rpcEthReceipt is a receipt returned by eth_getTransactionReceipt
+type rpcEthReceipt struct {
+	Type              hexutil.Uint64  `json:"type"`
+	Root              hexutil.Bytes   `json:"root"`
+	Status            *hexutil.Uint64 `json:"status"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	Bloom             hexutil.Bytes   `json:"logsBloom"`
+	Logs              []*rpcEthLog    `json:"logs"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	BlockHash         crypto.Hash     `json:"blockHash"`
+}
+
+// encode the receipt as it is stored in the receipt trie
+func (receipt *rpcEthReceipt) encode() ([]byte, error) {
+	enc := &ethReceipt{
+		PostStateOrStatus: []byte{},
+		CumulativeGasUsed: uint64(receipt.CumulativeGasUsed),
+		Bloom:             receipt.Bloom,
+		Logs:              []*ethLog{},
+	}
+	if len(receipt.Root) > 0 {
+		enc.PostStateOrStatus = receipt.Root
+	} else if receipt.Status != nil && *receipt.Status == 1 {
+		enc.PostStateOrStatus = []byte{1}
+	}
+	for _, rpcLog := range receipt.Logs {
+		enc.Logs = append(enc.Logs, &ethLog{Address: rpcLog.Address, Topics: rpcLog.Topics, Data: rpcLog.Data})
+	}
+	value, err := rlp.EncodeToBytes(enc)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Type > 0 {
+		value = append([]byte{byte(receipt.Type)}, value...)
+	}
+	return value, nil
+}
@@ -0,0 +1,17 @@
+package bridge
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	EnableBridgeFlag = cli.BoolFlag{
+		Name:  "enableBridge",
+		Usage: "enable bridge api and header relay",
+	}
+
+	BridgeEthUrlFlag = cli.StringFlag{
+		Name:  "bridgeEthUrl",
+		Usage: "rpc endpoint of the ethereum node headers and receipts are read from",
+	}
+)
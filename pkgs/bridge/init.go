@@ -0,0 +1,25 @@
+package bridge
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	MODULENAME = "bridge"
+)
+
+var (
+	log = dlog.EnsureLogger(MODULENAME)
+
+	// BridgeAddress is the address of the bridge contract, wrapped balances are kept under it
+	// and the mint and burn events are logged by it
+	BridgeAddress = crypto.BytesToAddress([]byte(MODULENAME))
+)
+
+func init() {
+	types.SetTxPayloadDecoder(types.BridgeType, func(version int32, data []byte) (interface{}, error) {
+		return DecodeBridgeData(data)
+	})
+}
@@ -0,0 +1,81 @@
+package bridge
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// sealVerifier check the proof of work of an ethereum header
+type sealVerifier func(header *ethtypes.Header) error
+
+var (
+	ethashOnce sync.Once
+	ethashPow  *ethash.Ethash
+)
+
+// verifyEthash check the seal of header by the light ethash cache of its epoch. The caches are
+// kept in memory only and shared by every processor, one is built for each epoch relayed
+func verifyEthash(header *ethtypes.Header) error {
+	ethashOnce.Do(func() {
+		ethashPow = ethash.New(ethash.Config{CachesInMem: 2}, nil, false)
+	})
+	if err := ethashPow.VerifySeal(nil, header); err != nil {
+		return ErrInvalidSeal
+	}
+	return nil
+}
+
+// gethHeader convert the header to the one ethash verifies, the headers of later forks are
+// sealed over fields it does not know and are refused
+func (header *ethHeader) gethHeader() (*ethtypes.Header, error) {
+	if len(header.Rest) > 0 {
+		return nil, ErrUnsealedHeader
+	}
+	if len(header.Bloom) != ethtypes.BloomByteLength || len(header.Nonce) != 8 || header.Difficulty == nil {
+		return nil, ErrInvalidSeal
+	}
+	geth := &ethtypes.Header{
+		ParentHash:  common.Hash(header.ParentHash),
+		UncleHash:   common.Hash(header.UncleHash),
+		Coinbase:    common.Address(header.Coinbase),
+		Root:        common.Hash(header.Root),
+		TxHash:      common.Hash(header.TxHash),
+		ReceiptHash: common.Hash(header.ReceiptHash),
+		Difficulty:  header.Difficulty,
+		Number:      header.Number,
+		GasLimit:    header.GasLimit,
+		GasUsed:     header.GasUsed,
+		Time:        header.Time,
+		Extra:       header.Extra,
+		MixDigest:   common.Hash(header.MixDigest),
+	}
+	copy(geth.Bloom[:], header.Bloom)
+	copy(geth.Nonce[:], header.Nonce)
+	return geth, nil
+}
+
+// parentHeader rebuild the fields of a relayed header the difficulty of its children depends on
+func (header *relayedHeader) parentHeader() *ethtypes.Header {
+	return &ethtypes.Header{
+		UncleHash:  common.Hash(header.UncleHash),
+		Difficulty: new(big.Int).Set(&header.Difficulty),
+		Number:     new(big.Int).SetUint64(header.Number),
+		Time:       header.Time,
+	}
+}
+
+// checkDifficulty check the difficulty of header is the one ethereum requires after parent
+func checkDifficulty(config *ethparams.ChainConfig, header *ethtypes.Header, parent *relayedHeader) error {
+	if header.Time <= parent.Time {
+		return ErrHeaderTime
+	}
+	if ethash.CalcDifficulty(config, header.Time, parent.parentHeader()).Cmp(header.Difficulty) != 0 {
+		return ErrHeaderDifficulty
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package bridge
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+// the operations of a bridge transaction, the first byte of its data
+const (
+	RelayOp byte = iota
+	DepositOp
+	WithdrawOp
+)
+
+const (
+	maxRelayHeaders        = 64
+	relayHeaderGas  uint64 = 20000
+	depositGas      uint64 = 50000
+	proofNodeGas    uint64 = 2000
+	withdrawGas     uint64 = 30000
+)
+
+var (
+	_ = (chain.ITransactionSelector)((*BridgeTxSelector)(nil))
+	_ = (chain.ITransactionValidator)((*BridgeTransactionProcessor)(nil))
+)
+
+// RelayMsg relay ethereum headers, each rlp encoded as it is hashed. The parent of the first
+// header must be relayed already, or it is the checkpoint header
+type RelayMsg struct {
+	Headers [][]byte
+}
+
+// DepositMsg prove the receipt of a lock transaction, and mint the amount of the lock event
+// at LogIndex of the receipt to its recipient
+type DepositMsg struct {
+	BlockHash crypto.Hash
+	TxIndex   uint64
+	LogIndex  uint64
+	Proof     [][]byte
+}
+
+// WithdrawMsg burn wrapped amount of sender, to be released to Recipient on ethereum
+type WithdrawMsg struct {
+	Recipient crypto.CommonAddress
+	Amount    common.Big
+}
+
+// EncodeBridgeData make the data of a bridge transaction
+func EncodeBridgeData(op byte, msg interface{}) ([]byte, error) {
+	payload, err := binary.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{op}, payload...), nil
+}
+
+// DecodeBridgeData return the message in the data of a bridge transaction, a *RelayMsg,
+// *DepositMsg or *WithdrawMsg
+func DecodeBridgeData(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, ErrUnknownBridgeOp
+	}
+	var msg interface{}
+	switch data[0] {
+	case RelayOp:
+		msg = &RelayMsg{}
+	case DepositOp:
+		msg = &DepositMsg{}
+	case WithdrawOp:
+		msg = &WithdrawMsg{}
+	default:
+		return nil, ErrUnknownBridgeOp
+	}
+	if err := binary.Unmarshal(data[1:], msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+type BridgeTxSelector struct {
+}
+
+func (selector *BridgeTxSelector) Select(tx *types.Transaction) bool {
+	return tx.Type() == types.BridgeType
+}
+
+// BridgeTransactionProcessor execute bridge transactions, every check is done before the state
+// is written, so a failed transaction leaves no bridge state behind
+type BridgeTransactionProcessor struct {
+	params     *BridgeParams
+	verifySeal sealVerifier
+}
+
+func NewBridgeTransactionProcessor(params *BridgeParams) *BridgeTransactionProcessor {
+	return &BridgeTransactionProcessor{params: params, verifySeal: verifyEthash}
+}
+
+func (processor *BridgeTransactionProcessor) ExecuteTransaction(context *chain.ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	op := &BridgeOp{context.TrieStore()}
+	msg, err := DecodeBridgeData(context.Data())
+	if err == nil {
+		switch msg := msg.(type) {
+		case *RelayMsg:
+			err = processor.relay(context, op, msg)
+		case *DepositMsg:
+			etr.ContractTxLog, err = processor.deposit(context, op, msg)
+		case *WithdrawMsg:
+			etr.ContractTxLog, err = processor.withdraw(context, op, msg)
+		}
+	}
+	if err == nil {
+		err = context.TrieStore().PutNonce(context.From(), context.Tx().Nonce()+1)
+	}
+	etr.Txerror = err
+	return etr
+}
+
+func (processor *BridgeTransactionProcessor) isRelayer(addr *crypto.CommonAddress) bool {
+	for _, relayer := range processor.params.Relayers {
+		if relayer == *addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (processor *BridgeTransactionProcessor) relay(context *chain.ExecuteTransactionContext, op *BridgeOp, msg *RelayMsg) error {
+	if !processor.isRelayer(context.From()) {
+		return ErrNotRelayer
+	}
+	if len(msg.Headers) == 0 {
+		return ErrNoHeaders
+	}
+	if len(msg.Headers) > maxRelayHeaders {
+		return ErrTooManyHeaders
+	}
+	if err := context.UseGas(relayHeaderGas * uint64(len(msg.Headers))); err != nil {
+		return err
+	}
+	tipHash, tip, err := op.tip()
+	if err != nil {
+		return err
+	}
+
+	// check the headers link to the relayed ones and carry their proof of work before any is stored
+	hashes := make([]crypto.Hash, 0, len(msg.Headers))
+	headers := make([]*relayedHeader, 0, len(msg.Headers))
+	batch := map[crypto.Hash]*relayedHeader{}
+	for _, raw := range msg.Headers {
+		header, hash, err := decodeEthHeader(raw)
+		if err != nil {
+			return err
+		}
+		sealed, err := header.gethHeader()
+		if err != nil {
+			return err
+		}
+		relayed := &relayedHeader{
+			Number:      header.Number.Uint64(),
+			ParentHash:  header.ParentHash,
+			ReceiptHash: header.ReceiptHash,
+			UncleHash:   header.UncleHash,
+			Time:        header.Time,
+		}
+		relayed.Difficulty.Set(header.Difficulty)
+		parent := batch[header.ParentHash]
+		if parent == nil {
+			if parent, err = op.header(header.ParentHash); err != nil {
+				return err
+			}
+		}
+		if parent == nil {
+			// the checkpoint is trusted as it is set in genesis, its difficulty is the weight
+			// of the relayed chain at its start
+			if tip != nil || len(hashes) > 0 || hash != processor.params.Checkpoint {
+				return ErrUnknownParent
+			}
+			relayed.TotalDifficulty.Set(header.Difficulty)
+		} else {
+			if parent.Number+1 != relayed.Number {
+				return ErrHeaderNumber
+			}
+			if err := checkDifficulty(processor.params.EthChainConfig, sealed, parent); err != nil {
+				return err
+			}
+			if err := processor.verifySeal(sealed); err != nil {
+				return err
+			}
+			relayed.TotalDifficulty.Add(&parent.TotalDifficulty, header.Difficulty)
+		}
+		batch[hash] = relayed
+		hashes = append(hashes, hash)
+		headers = append(headers, relayed)
+	}
+
+	for i, hash := range hashes {
+		known, err := op.header(hash)
+		if err != nil {
+			return err
+		}
+		if known != nil {
+			continue
+		}
+		if err := op.insertHeader(hash, headers[i]); err != nil {
+			return err
+		}
+	}
+	if tipHash, tip, err = op.tip(); err != nil {
+		return err
+	}
+	log.WithField("number", tip.Number).WithField("hash", tipHash).Debug("ethereum headers relayed")
+	return nil
+}
+
+// checkConfirmed check the header of hash is in the relayed main chain and enough headers relayed on it
+func checkConfirmed(op *BridgeOp, hash crypto.Hash, confirmations uint64) (*relayedHeader, error) {
+	header, err := op.header(hash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, ErrUnknownHeader
+	}
+	canonical, err := op.canonical(header.Number)
+	if err != nil {
+		return nil, err
+	}
+	if canonical != hash {
+		return nil, ErrNotCanonical
+	}
+	_, tip, err := op.tip()
+	if err != nil {
+		return nil, err
+	}
+	if tip.Number+1 < header.Number+confirmations {
+		return nil, ErrNotConfirmed
+	}
+	return header, nil
+}
+
+func (processor *BridgeTransactionProcessor) deposit(context *chain.ExecuteTransactionContext, op *BridgeOp, msg *DepositMsg) ([]*types.Log, error) {
+	if err := context.UseGas(depositGas + proofNodeGas*uint64(len(msg.Proof))); err != nil {
+		return nil, err
+	}
+	header, err := checkConfirmed(op, msg.BlockHash, processor.params.Confirmations)
+	if err != nil {
+		return nil, err
+	}
+	key := depositKey(msg.BlockHash, msg.TxIndex, msg.LogIndex)
+	deposited, err := op.deposited(key)
+	if err != nil {
+		return nil, err
+	}
+	if deposited {
+		return nil, ErrDeposited
+	}
+	lock, err := verifyLock(header.ReceiptHash, msg.TxIndex, msg.LogIndex, msg.Proof, processor.params.LockContract)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := op.Balance(&lock.Recipient)
+	if err != nil {
+		return nil, err
+	}
+	supply, err := op.Supply()
+	if err != nil {
+		return nil, err
+	}
+	if err := op.Put(key, []byte{1}); err != nil {
+		return nil, err
+	}
+	if err := op.putBalance(&lock.Recipient, balance.Add(balance, lock.Amount)); err != nil {
+		return nil, err
+	}
+	if err := op.putSupply(supply.Add(supply, lock.Amount)); err != nil {
+		return nil, err
+	}
+	return []*types.Log{bridgeLog(context, []crypto.Hash{MintedEventSig, crypto.Bytes2Hash(lock.Recipient[:])}, lock.Amount)}, nil
+}
+
+func (processor *BridgeTransactionProcessor) withdraw(context *chain.ExecuteTransactionContext, op *BridgeOp, msg *WithdrawMsg) ([]*types.Log, error) {
+	amount := (*big.Int)(&msg.Amount)
+	if amount.Sign() <= 0 || amount.BitLen() > 256 {
+		return nil, ErrInvalidAmount
+	}
+	if err := context.UseGas(withdrawGas); err != nil {
+		return nil, err
+	}
+	from := context.From()
+	balance, err := op.Balance(from)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, ErrBalance
+	}
+	supply, err := op.Supply()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := op.nextWithdrawal()
+	if err != nil {
+		return nil, err
+	}
+	if err := op.putBalance(from, balance.Sub(balance, amount)); err != nil {
+		return nil, err
+	}
+	if err := op.putSupply(supply.Sub(supply, amount)); err != nil {
+		return nil, err
+	}
+	topics := []crypto.Hash{BurnedEventSig, crypto.Bytes2Hash(from[:]), crypto.Bytes2Hash(msg.Recipient[:])}
+	return []*types.Log{bridgeLog(context, topics, amount, new(big.Int).SetUint64(nonce))}, nil
+}
+
+// bridgeLog make an event of the bridge contract, with values abi encoded as its data
+func bridgeLog(context *chain.ExecuteTransactionContext, topics []crypto.Hash, values ...*big.Int) *types.Log {
+	data := []byte{}
+	for _, value := range values {
+		data = append(data, crypto.Big2Hash(value).Bytes()...)
+	}
+	return &types.Log{
+		TxType:  types.BridgeType,
+		Address: BridgeAddress,
+		Topics:  topics,
+		Data:    data,
+		TxHash:  *context.Tx().TxHash(),
+		Height:  context.Header().Height,
+	}
+}
@@ -0,0 +1,227 @@
+package bridge
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/rpc"
+)
+
+const (
+	ethCallTimeout      = 30 * time.Second
+	relayPendingTimeout = 2 * time.Minute
+)
+
+// ethClient read headers and receipts from an ethereum node
+type ethClient struct {
+	client *rpc.Client
+}
+
+func dialEth(url string) (*ethClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ethCallTimeout)
+	defer cancel()
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return &ethClient{client: client}, nil
+}
+
+// headerByNumber return the header at number of ethereum main chain, the head if number is nil
+func (eth *ethClient) headerByNumber(ctx context.Context, number *uint64) (*rpcEthHeader, error) {
+	arg := "latest"
+	if number != nil {
+		arg = hexutil.EncodeUint64(*number)
+	}
+	header := &rpcEthHeader{}
+	if err := eth.client.CallContext(ctx, header, "eth_getBlockByNumber", arg, false); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (eth *ethClient) headerByHash(ctx context.Context, hash crypto.Hash) (*rpcEthHeader, error) {
+	header := &rpcEthHeader{}
+	if err := eth.client.CallContext(ctx, header, "eth_getBlockByHash", hash, false); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (eth *ethClient) receipt(ctx context.Context, txHash crypto.Hash) (*rpcEthReceipt, error) {
+	receipt := &rpcEthReceipt{}
+	if err := eth.client.CallContext(ctx, receipt, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// depositMsg find the first lock event of contract in the receipt of txHash and prove the receipt
+// against the receipt root of its block
+func (eth *ethClient) depositMsg(ctx context.Context, txHash crypto.Hash, contract crypto.CommonAddress) (*DepositMsg, error) {
+	receipt, err := eth.receipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	msg := &DepositMsg{BlockHash: receipt.BlockHash, TxIndex: uint64(receipt.TransactionIndex)}
+	found := false
+	for i, rpcLog := range receipt.Logs {
+		if rpcLog.Address == contract && len(rpcLog.Topics) == 3 && rpcLog.Topics[0] == LockedEventSig {
+			msg.LogIndex, found = uint64(i), true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNoLockEvent
+	}
+
+	header, err := eth.headerByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	receiptTrie, err := trie.New(crypto.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		return nil, err
+	}
+	for i, hash := range header.Transactions {
+		blockReceipt, err := eth.receipt(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		value, err := blockReceipt.encode()
+		if err != nil {
+			return nil, err
+		}
+		receiptTrie.Update(receiptKey(uint64(i)), value)
+	}
+	if receiptTrie.Hash() != header.ReceiptHash {
+		return nil, ErrReceiptRoot
+	}
+	proof := &proofList{}
+	if err := receiptTrie.Prove(receiptKey(msg.TxIndex), 0, proof); err != nil {
+		return nil, err
+	}
+	msg.Proof = *proof
+	return msg, nil
+}
+
+// proofList collects the nodes of a proof in order from the root
+type proofList [][]byte
+
+func (list *proofList) Put(key []byte, value []byte) error {
+	*list = append(*list, value)
+	return nil
+}
+
+func (list *proofList) Delete(key []byte) error {
+	panic("not supported")
+}
+
+// relayLoop poll the ethereum head and relay the headers after the relayed tip
+func (service *BridgeService) relayLoop() {
+	timer := time.NewTicker(time.Duration(service.Config.RelayInterval) * time.Second)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if err := service.relayHeaders(); err != nil {
+				log.WithField("err", err).Warn("relay ethereum headers")
+			}
+		case <-service.quit:
+			return
+		}
+	}
+}
+
+func (service *BridgeService) relayHeaders() error {
+	op, err := service.tipState()
+	if err != nil {
+		return err
+	}
+	tipHash, tip, err := op.tip()
+	if err != nil {
+		return err
+	}
+	// headers sent and not yet in a block are not sent again until they time out
+	if tip != nil && tip.Number < service.relayedTo && time.Since(service.relayedAt) < relayPendingTimeout {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ethCallTimeout)
+	defer cancel()
+	var headers [][]byte
+	var to uint64
+	if tip == nil {
+		checkpoint, err := service.eth.headerByHash(ctx, service.params.Checkpoint)
+		if err != nil {
+			return err
+		}
+		raw, err := checkpoint.encode()
+		if err != nil {
+			return err
+		}
+		headers, to = [][]byte{raw}, (*big.Int)(checkpoint.Number).Uint64()
+	} else {
+		head, err := service.eth.headerByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		// step back to the highest relayed header still in ethereum main chain, the headers of
+		// a reorganized branch are relayed from there till it outweighs the relayed tip
+		number := tip.Number
+		for i := 0; ; i++ {
+			if i == maxRelayHeaders {
+				return ErrReorgTooDeep
+			}
+			header, err := service.eth.headerByNumber(ctx, &number)
+			if err != nil {
+				return err
+			}
+			if relayed, err := op.header(header.Hash); err != nil {
+				return err
+			} else if relayed != nil {
+				break
+			}
+			if number == 0 {
+				return ErrReorgTooDeep
+			}
+			number--
+		}
+		to = (*big.Int)(head.Number).Uint64()
+		if to <= tip.Number {
+			return nil
+		}
+		if to > number+maxRelayHeaders {
+			to = number + maxRelayHeaders
+		}
+		for next := number + 1; next <= to; next++ {
+			header, err := service.eth.headerByNumber(ctx, &next)
+			if err != nil {
+				return err
+			}
+			raw, err := header.encode()
+			if err != nil {
+				return err
+			}
+			headers = append(headers, raw)
+		}
+	}
+
+	data, err := EncodeBridgeData(RelayOp, &RelayMsg{Headers: headers})
+	if err != nil {
+		return err
+	}
+	gasPrice, gasLimit := new(big.Int).SetUint64(service.Config.GasPrice), new(big.Int).SetUint64(service.Config.GasLimit)
+	tx, err := service.sendTransaction(&service.Config.Relayer, data, gasPrice, gasLimit)
+	if err != nil {
+		return err
+	}
+	service.relayedTo, service.relayedAt = to, time.Now()
+	log.WithField("tip", tipHash).WithField("to", to).WithField("tx", tx.TxHash()).Info("relay ethereum headers")
+	return nil
+}
@@ -0,0 +1,132 @@
+package bridge
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database"
+	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// BridgeService bridge ether locked in a contract of ethereum to a wrapped asset on drep.
+// Relayers relay ethereum headers in bridge transactions, a deposit proves the receipt of a
+// lock transaction against a relayed header and mints the locked amount, a withdraw burns
+// wrapped amount and logs it for the lock contract to release.
+type BridgeService struct {
+	DatabaseService *database.DatabaseService      `service:"database"`
+	ChainService    chain.ChainServiceInterface    `service:"chain"`
+	AccountService  *accountService.AccountService `service:"accounts"`
+	Config          *BridgeConfig
+
+	params    *BridgeParams
+	eth       *ethClient
+	relayedTo uint64
+	relayedAt time.Time
+	apis      []app.API
+	quit      chan struct{}
+}
+
+func (service *BridgeService) Name() string {
+	return MODULENAME
+}
+
+func (service *BridgeService) Api() []app.API {
+	return service.apis
+}
+
+func (service *BridgeService) CommandFlags() ([]cli.Command, []cli.Flag) {
+	return nil, []cli.Flag{EnableBridgeFlag, BridgeEthUrlFlag}
+}
+
+func (service *BridgeService) Init(executeContext *app.ExecuteContext) error {
+	params, err := loadBridgeParams(service.ChainService.GenesisParams())
+	if err != nil {
+		return err
+	}
+	service.params = params
+	// bridge transactions are executed by all nodes, the api and relay are optional
+	service.ChainService.AddTransactionValidator(&BridgeTxSelector{}, NewBridgeTransactionProcessor(params))
+
+	if executeContext.Cli.GlobalIsSet(EnableBridgeFlag.Name) {
+		service.Config.Enable = executeContext.Cli.GlobalBool(EnableBridgeFlag.Name)
+	}
+	if executeContext.Cli.GlobalIsSet(BridgeEthUrlFlag.Name) {
+		service.Config.EthUrl = executeContext.Cli.GlobalString(BridgeEthUrlFlag.Name)
+	}
+	if !service.Config.Enable {
+		return nil
+	}
+
+	if service.Config.EthUrl != "" {
+		if service.eth, err = dialEth(service.Config.EthUrl); err != nil {
+			return err
+		}
+	} else if service.Config.Relay {
+		return ErrEthNotConfigured
+	}
+	service.quit = make(chan struct{})
+	service.apis = []app.API{
+		app.API{
+			Namespace: MODULENAME,
+			Version:   "1.0",
+			Service: &BridgeApi{
+				service: service,
+			},
+			Public: true,
+		},
+	}
+	return nil
+}
+
+func (service *BridgeService) Start(executeContext *app.ExecuteContext) error {
+	if service.Config == nil || !service.Config.Enable {
+		return nil
+	}
+	if service.Config.Relay {
+		go service.relayLoop()
+	}
+	return nil
+}
+
+func (service *BridgeService) Stop(executeContext *app.ExecuteContext) error {
+	if service.Config == nil || !service.Config.Enable {
+		return nil
+	}
+	close(service.quit)
+	return nil
+}
+
+func (service *BridgeService) DefaultConfig() *BridgeConfig {
+	return DefaultConfig
+}
+
+// tipState return the bridge state at the best chain tip
+func (service *BridgeService) tipState() (*BridgeOp, error) {
+	tip := service.ChainService.BestChain().Tip()
+	trieStore, err := store.TrieStoreFromStore(service.DatabaseService.LevelDb(), tip.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &BridgeOp{trieStore}, nil
+}
+
+// sendTransaction sign a bridge transaction of data by a local account and send it
+func (service *BridgeService) sendTransaction(from *crypto.CommonAddress, data []byte, gasPrice, gasLimit *big.Int) (*types.Transaction, error) {
+	nonces := service.AccountService.Nonces()
+	nonce := nonces.Reserve(from)
+	defer nonces.Release(from, nonce)
+	tx := types.NewBridgeTransaction(data, gasPrice, gasLimit, nonce)
+	if err := service.AccountService.Wallet.SignTransaction(from, tx); err != nil {
+		return nil, err
+	}
+	if err := service.AccountService.MessageBroadCastor.SendTransaction(tx, true); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
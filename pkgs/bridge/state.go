@@ -0,0 +1,195 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	drepBinary "github.com/drep-project/binary"
+)
+
+var (
+	headerPrefix    = []byte("bridgeHeader")
+	canonicalPrefix = []byte("bridgeCanonical")
+	depositPrefix   = []byte("bridgeDeposit")
+	balancePrefix   = []byte("bridgeBalance")
+	tipKey          = []byte("bridgeTip")
+	supplyKey       = []byte("bridgeSupply")
+	withdrawalKey   = []byte("bridgeWithdrawal")
+)
+
+// relayedHeader is the part of a relayed ethereum header kept in state, with the fields its
+// children are checked against
+type relayedHeader struct {
+	Number          uint64
+	ParentHash      crypto.Hash
+	ReceiptHash     crypto.Hash
+	UncleHash       crypto.Hash
+	Time            uint64
+	Difficulty      big.Int
+	TotalDifficulty big.Int //Sum of the difficulties from the checkpoint to this header
+}
+
+// BridgeOp read and write the bridge state in the state trie
+type BridgeOp struct {
+	store.StoreInterface
+}
+
+func numberKey(prefix []byte, number uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], number)
+	return key
+}
+
+func concatKey(prefix []byte, parts ...[]byte) []byte {
+	key := append([]byte{}, prefix...)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+// depositKey identify a lock event by the position of its log, so it is minted only once
+func depositKey(blockHash crypto.Hash, txIndex, logIndex uint64) []byte {
+	return concatKey(numberKey(concatKey(depositPrefix, blockHash[:]), txIndex), numberKey(nil, logIndex))
+}
+
+// header return the relayed ethereum header of hash, nil if not relayed
+func (op *BridgeOp) header(hash crypto.Hash) (*relayedHeader, error) {
+	value, err := op.Get(concatKey(headerPrefix, hash[:]))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	header := &relayedHeader{}
+	if err := drepBinary.Unmarshal(value, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (op *BridgeOp) putHeader(hash crypto.Hash, header *relayedHeader) error {
+	value, err := drepBinary.Marshal(header)
+	if err != nil {
+		return err
+	}
+	return op.Put(concatKey(headerPrefix, hash[:]), value)
+}
+
+// tip return the relayed ethereum header of the highest total difficulty, nil if nothing relayed
+func (op *BridgeOp) tip() (crypto.Hash, *relayedHeader, error) {
+	value, err := op.Get(tipKey)
+	if err != nil || value == nil {
+		return crypto.Hash{}, nil, err
+	}
+	hash := crypto.Bytes2Hash(value)
+	header, err := op.header(hash)
+	return hash, header, err
+}
+
+// insertHeader store a relayed header, it becomes the tip if its total difficulty is higher
+// than the one of the tip. A branch of equal weight does not replace the tip
+func (op *BridgeOp) insertHeader(hash crypto.Hash, header *relayedHeader) error {
+	if err := op.putHeader(hash, header); err != nil {
+		return err
+	}
+	_, tip, err := op.tip()
+	if err != nil {
+		return err
+	}
+	if tip == nil || header.TotalDifficulty.Cmp(&tip.TotalDifficulty) > 0 {
+		return op.setTip(hash, header)
+	}
+	return nil
+}
+
+// canonical return the hash of the relayed header at number in the chain of the tip
+func (op *BridgeOp) canonical(number uint64) (crypto.Hash, error) {
+	value, err := op.Get(numberKey(canonicalPrefix, number))
+	if err != nil || value == nil {
+		return crypto.Hash{}, err
+	}
+	return crypto.Bytes2Hash(value), nil
+}
+
+// setTip make header the tip and move the canonical headers to its chain, back to where
+// it meets the chain of the old tip
+func (op *BridgeOp) setTip(hash crypto.Hash, header *relayedHeader) error {
+	// a heavier branch may be shorter, the canonical headers above it are cleared
+	_, oldTip, err := op.tip()
+	if err != nil {
+		return err
+	}
+	if oldTip != nil {
+		for number := header.Number + 1; number <= oldTip.Number; number++ {
+			if err := op.Put(numberKey(canonicalPrefix, number), nil); err != nil {
+				return err
+			}
+		}
+	}
+	if err := op.Put(tipKey, hash.Bytes()); err != nil {
+		return err
+	}
+	for header != nil {
+		canonical, err := op.canonical(header.Number)
+		if err != nil {
+			return err
+		}
+		if canonical == hash {
+			return nil
+		}
+		if err := op.Put(numberKey(canonicalPrefix, header.Number), hash.Bytes()); err != nil {
+			return err
+		}
+		hash = header.ParentHash
+		if header, err = op.header(hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (op *BridgeOp) deposited(key []byte) (bool, error) {
+	value, err := op.Get(key)
+	return value != nil, err
+}
+
+func (op *BridgeOp) getBig(key []byte) (*big.Int, error) {
+	value, err := op.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(value), nil
+}
+
+// Balance return the wrapped balance of addr
+func (op *BridgeOp) Balance(addr *crypto.CommonAddress) (*big.Int, error) {
+	return op.getBig(concatKey(balancePrefix, addr[:]))
+}
+
+func (op *BridgeOp) putBalance(addr *crypto.CommonAddress, balance *big.Int) error {
+	return op.Put(concatKey(balancePrefix, addr[:]), balance.Bytes())
+}
+
+// Supply return the wrapped amount minted and not burned
+func (op *BridgeOp) Supply() (*big.Int, error) {
+	return op.getBig(supplyKey)
+}
+
+func (op *BridgeOp) putSupply(supply *big.Int) error {
+	return op.Put(supplyKey, supply.Bytes())
+}
+
+// nextWithdrawal return the nonce of a new withdrawal, the lock contract releases each nonce once
+func (op *BridgeOp) nextWithdrawal() (uint64, error) {
+	value, err := op.Get(withdrawalKey)
+	if err != nil {
+		return 0, err
+	}
+	var nonce uint64
+	if len(value) == 8 {
+		nonce = binary.BigEndian.Uint64(value)
+	}
+	return nonce, op.Put(withdrawalKey, numberKey(nil, nonce+1))
+}
@@ -1,6 +1,7 @@
 package bft
 
 import (
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"time"
 )
@@ -51,3 +52,36 @@ func (consensusApi *ConsensusApi) GetMiners() []*secp256k1.PublicKey {
 
 	return pk
 }
+
+/*
+ name: operatorReport
+ usage: Query the blocks produced, signed and missed by a producer and its rewards and collected fees per epoch (the interval that producers changed), there is no slashing of producers
+ params:
+	1. address of the producer
+	2. from epoch
+	3. to epoch, at most 1024 epochs are queried at one time
+ return: the stats of every epoch where the address produced or was rewarded, and their total
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"consensus_operatorReport","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",10,11], "id": 3}' -H "Content-Type:application/json"
+
+response:
+	 {"jsonrpc":"2.0","id":3,"result":{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","epochs":[{"epoch":10,"produced":25,"signed":98,"missed":2,"rewards":"0x3782dace9d900000","fees":"0x5208"},{"epoch":11,"produced":24,"signed":100,"missed":0,"rewards":"0x3782dace9d900000","fees":"0x0"}],"total":{"produced":49,"signed":198,"missed":2,"rewards":"0x6f05b59d3b200000","fees":"0x5208"}}}
+*/
+func (consensusApi *ConsensusApi) OperatorReport(addr crypto.CommonAddress, fromEpoch, toEpoch uint64) (*OperatorReport, error) {
+	return consensusApi.consensusService.RewardLedger.OperatorReport(&addr, fromEpoch, toEpoch)
+}
+
+/*
+ name: producerStats
+ usage: Query the blocks of the current producers which are not in the best chain, stored on a side chain or lost in a reorganize, a producer losing many blocks usually has a skewed clock or a slow link
+ params:
+ return: the stale block count of every producer and its last stale block
+ example:
+	curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"consensus_producerStats","params":[], "id": 3}' -H "Content-Type:application/json"
+
+response:
+	 {"jsonrpc":"2.0","id":3,"result":[{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","count":2,"lastHeight":1024,"lastHash":"0x3e2a4d3c0e5c1b2b7e0f6a9a6e8d4b6f1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f"},{"addr":"0x7e11723d6f9a0601f0343f96cdbd01e303b8ee9a","count":0,"lastHeight":0,"lastHash":"0x0000000000000000000000000000000000000000000000000000000000000000"}]}
+*/
+func (consensusApi *ConsensusApi) ProducerStats() ([]*ProducerStat, error) {
+	return consensusApi.consensusService.producerStats()
+}
@@ -18,6 +18,7 @@ type BlockMultiSigValidator struct {
 	getProducers GetProducers
 	getBlock     GetBlock
 	producerNum  int
+	rewardLedger *RewardLedger //record the rewards of executed block, may be nil
 }
 
 //func NewBlockMultiSigValidator(getProducers GetProducers, getBlock GetBlock, producerNum int) *BlockMultiSigValidator {
@@ -85,5 +86,13 @@ func (blockMultiSigValidator *BlockMultiSigValidator) ExecuteBlock(context *chai
 	}
 
 	calculator := NewRewardCalculator(context.TrieStore, multiSig, producers, context.GasFee, context.Block.Header.Height)
-	return calculator.AccumulateRewards(context.Block.Header.Height)
+	err = calculator.AccumulateRewards(context.Block.Header.Height)
+	if err != nil {
+		return err
+	}
+	if blockMultiSigValidator.rewardLedger != nil {
+		operators := newBlockOperators(multiSig, producers, context.GasFee)
+		blockMultiSigValidator.rewardLedger.Executed(context.Block, calculator.Rewards(), operators)
+	}
+	return nil
 }
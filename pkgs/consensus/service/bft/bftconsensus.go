@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
-	"strconv"
 	"sync"
 	"time"
 
@@ -43,6 +42,9 @@ type BftConsensus struct {
 
 	peerLock   sync.RWMutex
 	onLinePeer map[string]consensusTypes.IPeerInfo //key: enode.ID，value ,peerInfo
+	routes     map[string]*relayRoute              //producers reached through sentries, key: enode.ID
+	selfID     string
+	sentry     bool
 	WaitTime   time.Duration
 
 	memberMsgPool chan *MsgWrap
@@ -79,6 +81,7 @@ func NewBftConsensus(
 		DbService:      dbService,
 		sender:         sener,
 		onLinePeer:     map[string]consensusTypes.IPeerInfo{},
+		routes:         map[string]*relayRoute{},
 		WaitTime:       waitTime,
 		addPeerChan:    addPeerChan,
 		removePeerChan: removePeerChan,
@@ -145,11 +148,9 @@ func (bftConsensus *BftConsensus) Run(privKey *secp256k1.PrivateKey) (*types.Blo
 	}
 	miners := bftConsensus.collectMemberStatus(producers)
 	//print miners status
-	str := "-----------------------------------\n"
 	for _, m := range miners {
-		str += "|	" + m.Producer.Node.IP().String() + "|	" + strconv.FormatBool(m.IsOnline) + "	|\n"
+		log.WithField("ip", m.Producer.Node.IP().String()).WithField("online", m.IsOnline).Trace("miner status")
 	}
-	fmt.Println(str)
 
 	if len(miners) > 1 {
 		isM, isL, err := bftConsensus.moveToNextMiner(miners)
@@ -232,12 +233,8 @@ func (bftConsensus *BftConsensus) collectMemberStatus(producers []Producer) []*M
 		isMe := bftConsensus.PrivKey.PubKey().IsEqual(produce.Pubkey)
 		if isMe {
 			IsOnline = true
-		} else {
-			bftConsensus.peerLock.RLock()
-			if pi, ok = bftConsensus.onLinePeer[produce.Node.ID().String()]; ok {
-				IsOnline = true
-			}
-			bftConsensus.peerLock.RUnlock()
+		} else if pi, ok = bftConsensus.getPeer(produce.Node.ID().String()); ok {
+			IsOnline = true
 		}
 
 		produceInfos = append(produceInfos, &MemberInfo{
@@ -408,7 +405,7 @@ func (bftConsensus *BftConsensus) verifyBlockContent(block *types.Block) error {
 	if err != nil {
 		return err
 	}
-	multiSigValidator := BlockMultiSigValidator{bftConsensus.GetProducers, bftConsensus.ChainService.GetBlockByHash, bftConsensus.config.ProducerNum}
+	multiSigValidator := BlockMultiSigValidator{bftConsensus.GetProducers, bftConsensus.ChainService.GetBlockByHash, bftConsensus.config.ProducerNum, nil}
 	if err := multiSigValidator.VerifyBody(block); err != nil {
 		return err
 	}
@@ -445,7 +442,7 @@ func (bftConsensus *BftConsensus) verifyBlockContent(block *types.Block) error {
 	return nil
 }
 
-func (bftConsensus *BftConsensus) ReceiveMsg(peer *consensusTypes.PeerInfo, t uint64, buf []byte) {
+func (bftConsensus *BftConsensus) ReceiveMsg(peer consensusTypes.IPeerInfo, t uint64, buf []byte) {
 	switch t {
 	case MsgTypeSetUp:
 		log.WithField("addr", peer.IP()).WithField("code", t).WithField("size", len(buf)).Debug("Receive MsgTypeSetUp msg")
@@ -477,6 +474,10 @@ func (bftConsensus *BftConsensus) ReceiveMsg(peer *consensusTypes.PeerInfo, t ui
 		case bftConsensus.leaderMsgPool <- &MsgWrap{peer, t, buf}:
 		default:
 		}
+	case MsgTypeRelay:
+		bftConsensus.onRelay(peer, buf)
+	case MsgTypeRelayAnnounce:
+		bftConsensus.onRelayAnnounce(peer, buf)
 
 	default:
 		//return fmt.Errorf("consensus unkonw msg type:%d", msg.Code)
@@ -516,7 +517,8 @@ func (bftConsensus *BftConsensus) prepareForMining(p2p p2pService.P2P) {
 				}
 			}
 
-			if found {
+			//a producer behind sentries never dials, the others are reached through the sentries
+			if found && !p2p.SentryMode() {
 				for _, p := range tempProduces {
 					if _, ok := bftConsensus.onLinePeer[p.Node.ID().String()]; !ok {
 						p2p.RemovePeer(p.Node.String())
@@ -0,0 +1,133 @@
+package bft
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/common/hexutil"
+	"github.com/drep-project/DREP-Chain/crypto"
+	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
+	chainTypes "github.com/drep-project/DREP-Chain/types"
+	dbinary "github.com/drep-project/binary"
+)
+
+// BlockDetail is a block with what its producers did and earned. The leader and signers are
+// decoded from the multi signature of the block proof, the fees and rewards are read from the
+// reward ledger and stay null until the block is connected to main chain
+type BlockDetail struct {
+	Hash      crypto.Hash            `json:"hash"`
+	Height    uint64                 `json:"height"`
+	Timestamp uint64                 `json:"timestamp"`
+	TxCount   uint64                 `json:"txCount"`
+	Leader    crypto.CommonAddress   `json:"leader"`
+	Bitmap    hexutil.Bytes          `json:"bitmap"` //One byte per producer in producer order, 1 if it signed the block
+	Signers   []crypto.CommonAddress `json:"signers"`
+	Fees      *common.Big            `json:"fees"`    //Gas fees collected by the leader
+	Reward    *common.Big            `json:"reward"`  //Block reward paid to the leader and its supporters, fees excluded
+	Rewards   []*RewardEntry         `json:"rewards"` //Credited per address, the fees included in the leader's
+}
+
+// newBlockDetail decode the leader and signers of block, producers are the producers of its parent
+func newBlockDetail(block *chainTypes.Block, producers []Producer) (*BlockDetail, error) {
+	if block.Proof.Type != consensusTypes.Pbft {
+		return nil, fmt.Errorf("block %d is not signed by bft producers", block.Header.Height)
+	}
+	multiSig := &MultiSignature{}
+	if err := dbinary.Unmarshal(block.Proof.Evidence, multiSig); err != nil {
+		return nil, err
+	}
+	if len(producers) != len(multiSig.Bitmap) || multiSig.Leader < 0 || multiSig.Leader >= len(producers) {
+		return nil, fmt.Errorf("producer num:%d != multisig num:%d", len(producers), len(multiSig.Bitmap))
+	}
+	detail := &BlockDetail{
+		Hash:      *block.Header.Hash(),
+		Height:    block.Header.Height,
+		Timestamp: block.Header.Timestamp,
+		TxCount:   block.Data.TxCount,
+		Leader:    producers[multiSig.Leader].Address(),
+		Bitmap:    multiSig.Bitmap,
+		Signers:   []crypto.CommonAddress{},
+	}
+	for index, val := range multiSig.Bitmap {
+		if val == 1 {
+			detail.Signers = append(detail.Signers, producers[index].Address())
+		}
+	}
+	return detail, nil
+}
+
+// fillBlockDetail set the fees and rewards recorded when the block of detail was connected
+func (ledger *RewardLedger) fillBlockDetail(detail *BlockDetail) error {
+	buf, err := ledger.db.Get(rewardBlockKey(&detail.Hash))
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+	rewards := &blockRewards{}
+	if err := dbinary.Unmarshal(buf, rewards); err != nil {
+		return err
+	}
+	operators := &blockOperators{}
+	if buf, err := ledger.db.Get(operatorBlockKey(&detail.Hash)); err == nil && len(buf) > 0 {
+		if err := dbinary.Unmarshal(buf, operators); err != nil {
+			return err
+		}
+	}
+
+	total := new(big.Int)
+	for _, reward := range rewards.Rewards {
+		total.Add(total, reward.Amount.ToInt())
+	}
+	fees := common.Big(*new(big.Int).Set(operators.Fee.ToInt()))
+	reward := common.Big(*total.Sub(total, fees.ToInt()))
+	detail.Fees, detail.Reward, detail.Rewards = &fees, &reward, rewards.Rewards
+	return nil
+}
+
+// BlockDetail return the producers, fees and rewards of the main chain block at height
+func (bftConsensusService *BftConsensusService) BlockDetail(height uint64) (*BlockDetail, error) {
+	if height == 0 {
+		return nil, fmt.Errorf("block %d is not signed by bft producers", height)
+	}
+	block, err := bftConsensusService.ChainService.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return bftConsensusService.blockDetail(block)
+}
+
+func (bftConsensusService *BftConsensusService) blockDetail(block *chainTypes.Block) (*BlockDetail, error) {
+	producers, err := bftConsensusService.BftConsensus.loadProducers(block.Header.Height-1, bftConsensusService.Config.ProducerNum)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := newBlockDetail(block, producers)
+	if err != nil {
+		return nil, err
+	}
+	if err := bftConsensusService.RewardLedger.fillBlockDetail(detail); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// SubscribeBlockDetail subscribe the details of blocks connected to main chain, sent once their
+// rewards are recorded
+func (bftConsensusService *BftConsensusService) SubscribeBlockDetail(ch chan *BlockDetail) event.Subscription {
+	return bftConsensusService.blockDetailScope.Track(bftConsensusService.blockDetailFeed.Subscribe(ch))
+}
+
+// sendBlockDetail notify the subscribers of a block connected to main chain, the detail is only
+// built when someone listens
+func (bftConsensusService *BftConsensusService) sendBlockDetail(block *chainTypes.Block) {
+	if bftConsensusService.blockDetailScope.Count() == 0 || block.Header.Height == 0 {
+		return
+	}
+	detail, err := bftConsensusService.blockDetail(block)
+	if err != nil {
+		log.WithField("height", block.Header.Height).WithField("err", err).Warn("block detail")
+		return
+	}
+	bftConsensusService.blockDetailFeed.Send(detail)
+}
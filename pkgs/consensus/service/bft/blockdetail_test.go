@@ -0,0 +1,35 @@
+package bft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func TestFillBlockDetail(t *testing.T) {
+	ledger := NewRewardLedger(memorydb.New(), 10)
+	leader := crypto.CommonAddress{1}
+	supporter := crypto.CommonAddress{2}
+	operators := &blockOperators{Leader: leader, Fee: common.Big(*big.NewInt(5)), Signed: []crypto.CommonAddress{leader}}
+
+	block := newRewardBlock(11, crypto.Hash{})
+	detail := &BlockDetail{Hash: *block.Header.Hash()}
+	if err := ledger.fillBlockDetail(detail); err != nil || detail.Fees != nil || detail.Reward != nil || detail.Rewards != nil {
+		t.Fatalf("unexpected detail of unrecorded block %v, %v", detail, err)
+	}
+
+	rewards := []*RewardEntry{{Addr: supporter, Amount: common.Big(*big.NewInt(20))}, {Addr: leader, Amount: common.Big(*big.NewInt(85))}}
+	ledger.Executed(block, rewards, operators)
+	if err := ledger.Connected(block); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.fillBlockDetail(detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Fees.ToInt().Int64() != 5 || detail.Reward.ToInt().Int64() != 100 || len(detail.Rewards) != 2 {
+		t.Fatalf("unexpected detail %v", detail)
+	}
+}
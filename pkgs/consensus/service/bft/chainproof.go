@@ -0,0 +1,286 @@
+package bft
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
+	chainTypes "github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// ChainProofVersion is the current version of the chain proof format
+const ChainProofVersion uint16 = 1
+
+var (
+	chainProofMagic = [8]byte{'D', 'R', 'E', 'P', 'P', 'R', 'O', 'F'}
+
+	ExportProofCommand = cli.Command{
+		Name:      "exportproof",
+		Usage:     "Export headers and producer signatures of main chain as an offline verifiable proof",
+		ArgsUsage: "<file> [to]",
+		Category:  "BLOCKCHAIN COMMANDS",
+	}
+	VerifyProofCommand = cli.Command{
+		Name:      "verifyproof",
+		Usage:     "Verify a chain proof, optionally against a known genesis hash",
+		ArgsUsage: "<file> [genesis]",
+		Category:  "BLOCKCHAIN COMMANDS",
+	}
+)
+
+// ProofEpoch is an epoch transition, the producers sign the blocks above Height
+// until the next transition
+type ProofEpoch struct {
+	Height    uint64
+	Producers [][]byte // compressed public keys in bitmap order
+}
+
+// SignedHeader is a block header with the aggregated signature of its producers
+type SignedHeader struct {
+	Header *chainTypes.BlockHeader
+	Sig    secp256k1.Signature
+	Bitmap []byte
+}
+
+// ChainProof links the genesis header to a head through headers and aggregated
+// producer signatures. The verifier checks the header links and that every block
+// is signed by a quorum of the producer set in force. The sets are read from the
+// state of the exporting node and are not proven against the state roots
+type ChainProof struct {
+	Magic       [8]byte
+	Version     uint16
+	ProducerNum int // size of the producer set the quorum is computed from
+	Genesis     *chainTypes.BlockHeader
+	Headers     []*SignedHeader
+	Epochs      []*ProofEpoch
+}
+
+// ChainProofResult is the summary of a verified proof
+type ChainProofResult struct {
+	Genesis crypto.Hash `json:"genesis"`
+	Head    crypto.Hash `json:"head"`
+	Height  uint64      `json:"height"`
+	Epochs  int         `json:"epochs"`
+}
+
+// quorum is the number of signers a block needs, same as the consensus uses
+func quorum(producerNum int) int {
+	min := producerNum * 2 / 3
+	if producerNum*2%3 != 0 {
+		min++
+	}
+	return min
+}
+
+// ExportChainProof build the proof of main chain from genesis to height to
+func (bftConsensusService *BftConsensusService) ExportChainProof(to uint64) (*ChainProof, error) {
+	if best := bftConsensusService.ChainService.BestChain().Height(); to > best {
+		return nil, fmt.Errorf("height %d above head %d", to, best)
+	}
+	genesis, err := bftConsensusService.ChainService.GetBlockHeaderByHeight(0)
+	if err != nil {
+		return nil, err
+	}
+	proof := &ChainProof{
+		Magic:       chainProofMagic,
+		Version:     ChainProofVersion,
+		ProducerNum: bftConsensusService.Config.ProducerNum,
+		Genesis:     genesis,
+		Headers:     make([]*SignedHeader, 0, to),
+	}
+
+	var last [][]byte
+	for height := uint64(1); height <= to; height++ {
+		producers, err := bftConsensusService.BftConsensus.loadProducers(height-1, bftConsensusService.Config.ProducerNum)
+		if err != nil {
+			return nil, err
+		}
+		set := make([][]byte, len(producers))
+		for i, producer := range producers {
+			set[i] = producer.Pubkey.SerializeCompressed()
+		}
+		if !sameProducers(last, set) {
+			proof.Epochs = append(proof.Epochs, &ProofEpoch{Height: height - 1, Producers: set})
+			last = set
+		}
+
+		block, err := bftConsensusService.ChainService.GetBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		if block.Proof.Type != consensusTypes.Pbft {
+			return nil, fmt.Errorf("block %d is not signed by bft producers", height)
+		}
+		multiSig := &MultiSignature{}
+		if err := binary.Unmarshal(block.Proof.Evidence, multiSig); err != nil {
+			return nil, err
+		}
+		proof.Headers = append(proof.Headers, &SignedHeader{
+			Header: block.Header,
+			Sig:    multiSig.Sig,
+			Bitmap: multiSig.Bitmap,
+		})
+	}
+	return proof, nil
+}
+
+func sameProducers(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify check the proof without any chain data and return the proven head
+func (proof *ChainProof) Verify() (*ChainProofResult, error) {
+	if proof.Magic != chainProofMagic {
+		return nil, ErrInvalidChainProof
+	}
+	if proof.Version != ChainProofVersion {
+		return nil, fmt.Errorf("unsupported chain proof version %d", proof.Version)
+	}
+	if proof.Genesis == nil || proof.Genesis.Height != 0 || proof.ProducerNum <= 0 {
+		return nil, ErrInvalidChainProof
+	}
+	if len(proof.Headers) > 0 && (len(proof.Epochs) == 0 || proof.Epochs[0].Height != 0) {
+		return nil, fmt.Errorf("%v: no producer set at genesis", ErrInvalidChainProof)
+	}
+
+	var (
+		parent = proof.Genesis
+		epoch  = -1
+		signer []*secp256k1.PublicKey
+		min    = quorum(proof.ProducerNum)
+	)
+	for _, signed := range proof.Headers {
+		header := signed.Header
+		if header == nil || header.Height != parent.Height+1 || header.PreviousHash != *parent.Hash() || header.ChainId != proof.Genesis.ChainId {
+			return nil, fmt.Errorf("%v: broken link at height %d", ErrInvalidChainProof, parent.Height+1)
+		}
+		// switch to the set introduced by the parent block
+		if epoch+1 < len(proof.Epochs) && proof.Epochs[epoch+1].Height == parent.Height {
+			epoch++
+			keys, err := parseProducers(proof.Epochs[epoch].Producers)
+			if err != nil {
+				return nil, err
+			}
+			signer = keys
+		}
+		if len(signed.Bitmap) != len(signer) {
+			return nil, fmt.Errorf("%v: bitmap size %d of block %d, producers %d", ErrInvalidChainProof, len(signed.Bitmap), header.Height, len(signer))
+		}
+		participators := []*secp256k1.PublicKey{}
+		for i, val := range signed.Bitmap {
+			if val == 1 {
+				participators = append(participators, signer[i])
+			}
+		}
+		if len(participators) < min {
+			return nil, fmt.Errorf("%v: block %d signed by %d producers, %d required", ErrInvalidChainProof, header.Height, len(participators), min)
+		}
+		msg := (&chainTypes.Block{Header: header}).AsSignMessage()
+		if signed.Sig.R == nil || signed.Sig.S == nil || !schnorr.Verify(schnorr.CombinePubkeys(participators), sha3.Keccak256(msg), signed.Sig.R, signed.Sig.S) {
+			return nil, fmt.Errorf("%v: block %d", ErrMultiSig, header.Height)
+		}
+		parent = header
+	}
+	if epoch+1 != len(proof.Epochs) && len(proof.Headers) > 0 {
+		return nil, fmt.Errorf("%v: unused producer set at height %d", ErrInvalidChainProof, proof.Epochs[epoch+1].Height)
+	}
+	return &ChainProofResult{
+		Genesis: *proof.Genesis.Hash(),
+		Head:    *parent.Hash(),
+		Height:  parent.Height,
+		Epochs:  len(proof.Epochs),
+	}, nil
+}
+
+func parseProducers(producers [][]byte) ([]*secp256k1.PublicKey, error) {
+	keys := make([]*secp256k1.PublicKey, len(producers))
+	for i, producer := range producers {
+		key, err := secp256k1.ParsePubKey(producer)
+		if err != nil {
+			return nil, fmt.Errorf("%v: producer key %x: %v", ErrInvalidChainProof, producer, err)
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// ExportChainProofFile write the proof of main chain up to height to into file
+func (bftConsensusService *BftConsensusService) ExportChainProofFile(file string, to uint64) error {
+	proof, err := bftConsensusService.ExportChainProof(to)
+	if err != nil {
+		return err
+	}
+	data, err := binary.Marshal(proof)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// VerifyChainProofFile read and verify the proof in file
+func VerifyChainProofFile(file string) (*ChainProofResult, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	proof := &ChainProof{}
+	if err := binary.Unmarshal(data, proof); err != nil {
+		return nil, fmt.Errorf("%v: %v", ErrInvalidChainProof, err)
+	}
+	return proof.Verify()
+}
+
+// runProofCommand run exportproof or verifyproof after chain loaded, the node quits when it is done
+func (bftConsensusService *BftConsensusService) runProofCommand(executeContext *app.ExecuteContext) error {
+	ctx := executeContext.Cli
+	if len(ctx.Args()) < 1 {
+		return fmt.Errorf("proof file required")
+	}
+	file := ctx.Args().Get(0)
+
+	switch ctx.Command.Name {
+	case ExportProofCommand.Name:
+		to := bftConsensusService.ChainService.BestChain().Height()
+		if len(ctx.Args()) > 1 {
+			var err error
+			if to, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+				return err
+			}
+		}
+		if err := bftConsensusService.ExportChainProofFile(file, to); err != nil {
+			return err
+		}
+		fmt.Printf("export chain proof 0-%d to %s\n", to, file)
+	case VerifyProofCommand.Name:
+		result, err := VerifyChainProofFile(file)
+		if err != nil {
+			return err
+		}
+		if len(ctx.Args()) > 1 {
+			genesis := crypto.HexToHash(ctx.Args().Get(1))
+			if genesis != result.Genesis {
+				return fmt.Errorf("%v: genesis %s, want %s", ErrInvalidChainProof, result.Genesis.String(), genesis.String())
+			}
+		}
+		fmt.Printf("chain proof valid, genesis %s, head %s at height %d, %d producer sets\n", result.Genesis.String(), result.Head.String(), result.Height, result.Epochs)
+	}
+	close(executeContext.Quit)
+	return nil
+}
@@ -0,0 +1,97 @@
+package bft
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1/schnorr"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+	"github.com/drep-project/binary"
+)
+
+// newTestChainProof build a proof of n blocks each signed by the single producer key
+func newTestChainProof(t *testing.T, key *secp256k1.PrivateKey, n int) *ChainProof {
+	genesis := newRewardBlock(0, crypto.Hash{}).Header
+	proof := &ChainProof{
+		Magic:       chainProofMagic,
+		Version:     ChainProofVersion,
+		ProducerNum: 1,
+		Genesis:     genesis,
+		Epochs:      []*ProofEpoch{{Height: 0, Producers: [][]byte{key.PubKey().SerializeCompressed()}}},
+	}
+	parent := genesis
+	for i := 1; i <= n; i++ {
+		block := newRewardBlock(uint64(i), *parent.Hash())
+		r, s, err := schnorr.Sign(key, sha3.Keccak256(block.AsSignMessage()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof.Headers = append(proof.Headers, &SignedHeader{Header: block.Header, Sig: secp256k1.Signature{R: r, S: s}, Bitmap: []byte{1}})
+		parent = block.Header
+	}
+	return proof
+}
+
+func TestChainProofVerify(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	proof := newTestChainProof(t, key, 5)
+
+	data, err := binary.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &ChainProof{}
+	if err := binary.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	result, err := decoded.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Height != 5 || result.Head != *proof.Headers[4].Header.Hash() || result.Genesis != *proof.Genesis.Hash() {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
+
+func TestChainProofRejects(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	other, _ := crypto.GenerateKey(rand.Reader)
+
+	// header changed after signing
+	proof := newTestChainProof(t, key, 3)
+	proof.Headers[1].Header.Timestamp = 42
+	if _, err := proof.Verify(); err == nil {
+		t.Error("tampered header accepted")
+	}
+
+	// signed by a key outside the producer set
+	proof = newTestChainProof(t, other, 3)
+	proof.Epochs[0].Producers = [][]byte{key.PubKey().SerializeCompressed()}
+	if _, err := proof.Verify(); err == nil {
+		t.Error("foreign signature accepted")
+	}
+
+	// missing signer
+	proof = newTestChainProof(t, key, 3)
+	proof.Headers[2].Bitmap = []byte{0}
+	if _, err := proof.Verify(); err == nil {
+		t.Error("block without quorum accepted")
+	}
+
+	// gap in the header chain
+	proof = newTestChainProof(t, key, 3)
+	proof.Headers = append(proof.Headers[:1], proof.Headers[2:]...)
+	if _, err := proof.Verify(); err == nil {
+		t.Error("broken chain accepted")
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	for num, want := range map[int]int{1: 1, 3: 2, 4: 3, 7: 5, 21: 14} {
+		if got := quorum(num); got != want {
+			t.Errorf("quorum(%d) = %d, want %d", num, got, want)
+		}
+	}
+}
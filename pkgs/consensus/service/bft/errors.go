@@ -23,4 +23,11 @@ var (
 	ErrMsgSize            = errors.New("err msg size")
 	ErrGasUsed            = errors.New("gasUsed not match gasUsed in blockheader")
 	ErrNotMyTurn		  = errors.New("not my turn")
+	ErrNoConsensusPubkey  = errors.New("mypk of consensus not config")
+	ErrPledgeAmount       = errors.New("pledge amount must be positive")
+	ErrPledgeBalance      = errors.New("no enough balance for pledge")
+	ErrPledgeLimit        = errors.New("pledge of candidate lower than register limit")
+	ErrRelayRead          = errors.New("nothing is read from a relay peer")
+	ErrRewardEpochRange   = errors.New("invalid epoch range of rewards")
+	ErrInvalidChainProof  = errors.New("invalid chain proof")
 )
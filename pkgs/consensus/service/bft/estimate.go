@@ -0,0 +1,211 @@
+package bft
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+)
+
+const feeStatEpochs = 16 //Completed epochs the fee trend and the realized income of an estimate are taken from
+
+// EpochFeeStat is the blocks produced and the gas fees collected by all producers in an epoch
+type EpochFeeStat struct {
+	Epoch  uint64     `json:"epoch"`
+	Blocks uint64     `json:"blocks"`
+	Fees   common.Big `json:"fees"`
+}
+
+// RewardEstimate is the income a candidate can expect as leader in the coming epochs, if the
+// stake distribution at the best chain tip holds and the fees per block follow FeeStats.
+// Leaders take turns, so a producer is expected to lead an equal share of the blocks.
+type RewardEstimate struct {
+	Addr      crypto.CommonAddress `json:"addr"`
+	Credit    common.Big           `json:"credit"`   //Credit of the candidate, the pledge included
+	Rank      int                  `json:"rank"`     //Rank among candidates, 0 if not a candidate
+	Producer  bool                 `json:"producer"` //Whether the rank is within the producers
+	FromEpoch uint64               `json:"fromEpoch"`
+	ToEpoch   uint64               `json:"toEpoch"`
+	Blocks    uint64               `json:"blocks"`  //Blocks expected to be led
+	Rewards   common.Big           `json:"rewards"` //Block rewards kept by the leader, the share of supporters excluded
+	Fees      common.Big           `json:"fees"`
+	Total     common.Big           `json:"total"`
+	FeeStats  []*EpochFeeStat      `json:"feeStats"` //Fees of the recent completed epochs
+	Realized  *OperatorReport      `json:"realized"` //What the address did and earned in the same epochs
+}
+
+type candidateCredit struct {
+	addr   crypto.CommonAddress
+	credit *big.Int
+}
+
+// FeeStat sum the stats of the producers of epoch
+func (ledger *RewardLedger) FeeStat(epoch uint64) *EpochFeeStat {
+	stat := &EpochFeeStat{Epoch: epoch}
+	iter := ledger.db.NewIteratorWithPrefix(operatorStatKey(epoch, nil))
+	defer iter.Release()
+
+	fees := new(big.Int)
+	for iter.Next() {
+		operator, err := decodeOperatorStat(iter.Value())
+		if err != nil {
+			log.WithField("epoch", epoch).WithField("err", err).Error("unmarshal operator stat")
+			continue
+		}
+		stat.Blocks += operator.Produced
+		fees.Add(fees, operator.Fees.ToInt())
+	}
+	stat.Fees = common.Big(*fees)
+	return stat
+}
+
+// FeeStats return the fee stats of epochs [fromEpoch, toEpoch]
+func (ledger *RewardLedger) FeeStats(fromEpoch, toEpoch uint64) ([]*EpochFeeStat, error) {
+	if toEpoch < fromEpoch || toEpoch-fromEpoch >= maxRewardEpochRange {
+		return nil, ErrRewardEpochRange
+	}
+	stats := make([]*EpochFeeStat, 0, toEpoch-fromEpoch+1)
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		stats = append(stats, ledger.FeeStat(epoch))
+	}
+	return stats, nil
+}
+
+// rewardBetween sum the rewards of the blocks in [from, to)
+func rewardBetween(from, to uint64) *big.Int {
+	period := 4 * params.BlockCountOfEveryYear
+	total := new(big.Int)
+	for from < to {
+		end := (from/period + 1) * period
+		if end > to {
+			end = to
+		}
+		total.Add(total, new(big.Int).Mul(blockReward(from), new(big.Int).SetUint64(end-from)))
+		from = end
+	}
+	return total
+}
+
+// candidateRank return the 1 based rank of addr with credit among candidates, in the order
+// GetCandidates picks producers: by credit, then by address
+func candidateRank(addr crypto.CommonAddress, credit *big.Int, candidates []candidateCredit) int {
+	rank := 1
+	for _, candidate := range candidates {
+		if candidate.addr == addr {
+			continue
+		}
+		cmp := candidate.credit.Cmp(credit)
+		if cmp > 0 || (cmp == 0 && candidate.addr.String() < addr.String()) {
+			rank++
+		}
+	}
+	return rank
+}
+
+// EstimateRewards estimate the income of addr as leader in the epochs after the current one,
+// with pledge added to its own credit
+func (bftConsensusService *BftConsensusService) EstimateRewards(addr *crypto.CommonAddress, pledge *big.Int, epochs uint64) (*RewardEstimate, error) {
+	if epochs == 0 || epochs > maxRewardEpochRange {
+		return nil, ErrRewardEpochRange
+	}
+	if pledge == nil {
+		pledge = new(big.Int)
+	} else if pledge.Sign() < 0 {
+		return nil, ErrPledgeAmount
+	}
+	tip := bftConsensusService.ChainService.BestChain().Tip()
+	trieStore, err := store.TrieStoreFromStore(bftConsensusService.DatabaseService.LevelDb(), tip.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+	ledger := bftConsensusService.RewardLedger
+	current := ledger.Epoch(tip.Height)
+	estimate := &RewardEstimate{
+		Addr:      *addr,
+		FromEpoch: current + 1,
+		ToEpoch:   current + epochs,
+		FeeStats:  []*EpochFeeStat{},
+	}
+
+	// the trend is taken from the completed epochs only
+	if current > 0 {
+		from := uint64(0)
+		if current > feeStatEpochs {
+			from = current - feeStatEpochs
+		}
+		if estimate.FeeStats, err = ledger.FeeStats(from, current-1); err != nil {
+			return nil, err
+		}
+		if estimate.Realized, err = ledger.OperatorReport(addr, from, current-1); err != nil {
+			return nil, err
+		}
+	}
+
+	addrs, err := trieStore.GetCandidateAddrs()
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidateCredit, 0, len(addrs))
+	isCandidate := false
+	for _, candidate := range addrs {
+		isCandidate = isCandidate || candidate == *addr
+		candidates = append(candidates, candidateCredit{addr: candidate, credit: trieStore.GetVoteCreditCount(&candidate)})
+	}
+	credit := new(big.Int).Add(trieStore.GetVoteCreditCount(addr), pledge)
+	estimate.Credit = common.Big(*credit)
+
+	details := trieStore.GetCreditDetails(addr)
+	if !isCandidate {
+		selfCredit := new(big.Int).Set(pledge)
+		if own, ok := details[*addr]; ok {
+			selfCredit.Add(selfCredit, &own)
+		}
+		limit := new(big.Int).Mul(new(big.Int).SetUint64(store.RegisterPledgeLimit), new(big.Int).SetUint64(params.Coin))
+		if selfCredit.Cmp(limit) < 0 {
+			return estimate, nil
+		}
+		candidates = append(candidates, candidateCredit{addr: *addr, credit: credit})
+	}
+	estimate.Rank = candidateRank(*addr, credit, candidates)
+	producerNum := bftConsensusService.Config.ProducerNum
+	if len(candidates) < producerNum {
+		producerNum = len(candidates)
+	}
+	estimate.Producer = estimate.Rank <= producerNum
+	if !estimate.Producer {
+		return estimate, nil
+	}
+
+	// the leader keeps 80 percent of the block reward if it has supporters, all of it if not
+	selfProportion := int64(100)
+	for supporter := range details {
+		if supporter != *addr {
+			selfProportion = 80
+			break
+		}
+	}
+	start := estimate.FromEpoch * ledger.changeInterval
+	end := (estimate.ToEpoch + 1) * ledger.changeInterval
+	estimate.Blocks = (end - start) / uint64(producerNum)
+	rewards := rewardBetween(start, end)
+	rewards.Mul(rewards, big.NewInt(selfProportion))
+	rewards.Div(rewards, big.NewInt(100*int64(producerNum)))
+	estimate.Rewards = common.Big(*rewards)
+
+	fees, blocks := new(big.Int), uint64(0)
+	for _, stat := range estimate.FeeStats {
+		fees.Add(fees, stat.Fees.ToInt())
+		blocks += stat.Blocks
+	}
+	if blocks > 0 {
+		fees.Mul(fees, new(big.Int).SetUint64(estimate.Blocks))
+		fees.Div(fees, new(big.Int).SetUint64(blocks))
+	} else {
+		fees.SetInt64(0)
+	}
+	estimate.Fees = common.Big(*fees)
+	estimate.Total = common.Big(*new(big.Int).Add(rewards, fees))
+	return estimate, nil
+}
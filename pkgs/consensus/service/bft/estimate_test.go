@@ -0,0 +1,73 @@
+package bft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+)
+
+func TestRewardBetween(t *testing.T) {
+	period := 4 * params.BlockCountOfEveryYear
+	full := blockReward(0)
+	if reward := rewardBetween(10, 20); reward.Cmp(new(big.Int).Mul(full, big.NewInt(10))) != 0 {
+		t.Fatalf("reward of 10 blocks %v", reward)
+	}
+	// two blocks before the halving and two after
+	expected := new(big.Int).Mul(full, big.NewInt(2))
+	expected.Add(expected, new(big.Int).Mul(blockReward(period), big.NewInt(2)))
+	if reward := rewardBetween(period-2, period+2); reward.Cmp(expected) != 0 {
+		t.Fatalf("reward across halving %v, expected %v", reward, expected)
+	}
+}
+
+func TestCandidateRank(t *testing.T) {
+	candidates := []candidateCredit{
+		{addr: crypto.CommonAddress{1}, credit: big.NewInt(300)},
+		{addr: crypto.CommonAddress{2}, credit: big.NewInt(200)},
+		{addr: crypto.CommonAddress{4}, credit: big.NewInt(100)},
+	}
+	if rank := candidateRank(crypto.CommonAddress{3}, big.NewInt(200), candidates); rank != 3 {
+		t.Fatalf("rank %d, tie broken by address expected 3", rank)
+	}
+	if rank := candidateRank(crypto.CommonAddress{4}, big.NewInt(400), candidates); rank != 1 {
+		t.Fatalf("rank %d of raised credit, expected 1", rank)
+	}
+}
+
+func TestFeeStats(t *testing.T) {
+	ledger := NewRewardLedger(memorydb.New(), 10)
+	leader, member := crypto.CommonAddress{1}, crypto.CommonAddress{2}
+	prev := crypto.Hash{}
+	for height := uint64(11); height <= 13; height++ {
+		block := newRewardBlock(height, prev)
+		operators := &blockOperators{Leader: leader, Fee: common.Big(*big.NewInt(100)), Signed: []crypto.CommonAddress{leader, member}}
+		// the stat of a leader without fees must still count its blocks
+		if height == 13 {
+			operators.Leader = member
+			operators.Fee = common.Big{}
+		}
+		ledger.Executed(block, []*RewardEntry{}, operators)
+		if err := ledger.Connected(block); err != nil {
+			t.Fatal(err)
+		}
+		prev = *block.Header.Hash()
+	}
+
+	stats, err := ledger.FeeStats(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].Blocks != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+	if stats[1].Blocks != 3 || stats[1].Fees.ToInt().Int64() != 200 {
+		t.Fatalf("epoch 1 has %d blocks and %v fees, expected 3 and 200", stats[1].Blocks, stats[1].Fees.ToInt())
+	}
+	if _, err := ledger.FeeStats(1, 0); err != ErrRewardEpochRange {
+		t.Fatalf("inverted range accepted, %v", err)
+	}
+}
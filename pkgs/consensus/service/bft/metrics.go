@@ -0,0 +1,8 @@
+package bft
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	relayMeter     = metrics.NewRegisteredMeter("bft/relay/forward", nil)
+	relayDropMeter = metrics.NewRegisteredMeter("bft/relay/drop", nil)
+)
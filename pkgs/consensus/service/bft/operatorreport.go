@@ -0,0 +1,238 @@
+package bft
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
+	dbinary "github.com/drep-project/binary"
+)
+
+var (
+	operatorStatPrefix  = []byte("operatorStat")  //epoch + address -> OperatorStat of producer in epoch
+	operatorBlockPrefix = []byte("operatorBlock") //block hash -> blockOperators of block, used to revert detached block
+)
+
+// OperatorStat is the work and income of a producer. Rewards hold the block rewards
+// credited to the address, the rewards as supporter of other producers included, and
+// Fees the gas fees collected as leader. There is no slashing of producers in this chain,
+// a producer not signing only misses its rewards
+type OperatorStat struct {
+	Produced uint64     `json:"produced"` //Blocks produced as leader
+	Signed   uint64     `json:"signed"`   //Blocks signed
+	Missed   uint64     `json:"missed"`   //Blocks not signed while a producer
+	Rewards  common.Big `json:"rewards"`
+	Fees     common.Big `json:"fees"`
+}
+
+// OperatorEpoch is the OperatorStat of a producer in an epoch
+type OperatorEpoch struct {
+	Epoch uint64 `json:"epoch"`
+	OperatorStat
+}
+
+// OperatorReport is the per epoch and total OperatorStat of a producer over a range of epochs
+type OperatorReport struct {
+	Addr   crypto.CommonAddress `json:"addr"`
+	Epochs []*OperatorEpoch     `json:"epochs"`
+	Total  *OperatorStat        `json:"total"`
+}
+
+// operatorStatEntry is the stored OperatorStat, the binary codec of big.Int fails to decode a
+// zero value ending the buffer, so fees are kept as bytes like the rewards of the ledger
+type operatorStatEntry struct {
+	Produced uint64
+	Signed   uint64
+	Missed   uint64
+	Fees     []byte
+}
+
+func encodeOperatorStat(stat *OperatorStat) ([]byte, error) {
+	return dbinary.Marshal(&operatorStatEntry{
+		Produced: stat.Produced,
+		Signed:   stat.Signed,
+		Missed:   stat.Missed,
+		Fees:     stat.Fees.ToInt().Bytes(),
+	})
+}
+
+func decodeOperatorStat(buf []byte) (*OperatorStat, error) {
+	entry := &operatorStatEntry{}
+	if err := dbinary.Unmarshal(buf, entry); err != nil {
+		return nil, err
+	}
+	return &OperatorStat{
+		Produced: entry.Produced,
+		Signed:   entry.Signed,
+		Missed:   entry.Missed,
+		Fees:     common.Big(*new(big.Int).SetBytes(entry.Fees)),
+	}, nil
+}
+
+// blockOperators is what the producers did for a block
+type blockOperators struct {
+	Leader crypto.CommonAddress
+	Fee    common.Big
+	Signed []crypto.CommonAddress
+	Missed []crypto.CommonAddress
+}
+
+func newBlockOperators(sig *MultiSignature, producers ProducerSet, fee *big.Int) *blockOperators {
+	operators := &blockOperators{Leader: producers[sig.Leader].Address()}
+	if fee != nil {
+		operators.Fee = common.Big(*fee)
+	}
+	for index, producer := range producers {
+		if index < len(sig.Bitmap) && sig.Bitmap[index] == 1 {
+			operators.Signed = append(operators.Signed, producer.Address())
+		} else {
+			operators.Missed = append(operators.Missed, producer.Address())
+		}
+	}
+	return operators
+}
+
+// applyOperators add or sub what the producers did for a block to their stats of epoch
+func (ledger *RewardLedger) applyOperators(batch dbinterface.Batch, epoch uint64, operators *blockOperators, revert bool) error {
+	stats := map[crypto.CommonAddress]*OperatorStat{}
+	stat := func(addr crypto.CommonAddress) *OperatorStat {
+		if _, ok := stats[addr]; !ok {
+			stats[addr] = ledger.operatorStat(epoch, &addr)
+		}
+		return stats[addr]
+	}
+	// a reverted block is counted -1 times
+	count := func(value *uint64) {
+		if revert {
+			if *value > 0 {
+				*value--
+			}
+		} else {
+			*value++
+		}
+	}
+
+	leader := stat(operators.Leader)
+	count(&leader.Produced)
+	fees := new(big.Int).Set(leader.Fees.ToInt())
+	if revert {
+		fees.Sub(fees, operators.Fee.ToInt())
+	} else {
+		fees.Add(fees, operators.Fee.ToInt())
+	}
+	leader.Fees = common.Big(*fees)
+	for _, addr := range operators.Signed {
+		count(&stat(addr).Signed)
+	}
+	for _, addr := range operators.Missed {
+		count(&stat(addr).Missed)
+	}
+
+	for addr, stat := range stats {
+		key := operatorStatKey(epoch, &addr)
+		if stat.Produced == 0 && stat.Signed == 0 && stat.Missed == 0 {
+			if err := batch.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+		buf, err := encodeOperatorStat(stat)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ledger *RewardLedger) operatorStat(epoch uint64, addr *crypto.CommonAddress) *OperatorStat {
+	buf, err := ledger.db.Get(operatorStatKey(epoch, addr))
+	if err != nil || len(buf) == 0 {
+		return &OperatorStat{}
+	}
+	stat, err := decodeOperatorStat(buf)
+	if err != nil {
+		log.WithField("epoch", epoch).WithField("err", err).Error("unmarshal operator stat")
+		return &OperatorStat{}
+	}
+	return stat
+}
+
+// connectOperators count the producers of a block connected to main chain
+func (ledger *RewardLedger) connectOperators(batch dbinterface.Batch, block *types.Block, operators *blockOperators) error {
+	buf, err := dbinary.Marshal(operators)
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(operatorBlockKey(block.Header.Hash()), buf); err != nil {
+		return err
+	}
+	return ledger.applyOperators(batch, ledger.Epoch(block.Header.Height), operators, false)
+}
+
+// detachOperators take back the producers of a block detached from main chain
+func (ledger *RewardLedger) detachOperators(batch dbinterface.Batch, block *types.Block) error {
+	hash := block.Header.Hash()
+	buf, err := ledger.db.Get(operatorBlockKey(hash))
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+	operators := &blockOperators{}
+	if err := dbinary.Unmarshal(buf, operators); err != nil {
+		return err
+	}
+	if err := batch.Delete(operatorBlockKey(hash)); err != nil {
+		return err
+	}
+	return ledger.applyOperators(batch, ledger.Epoch(block.Header.Height), operators, true)
+}
+
+// OperatorReport return what a producer did and earned in epochs [fromEpoch, toEpoch],
+// epochs where the address was not a producer are omitted
+func (ledger *RewardLedger) OperatorReport(addr *crypto.CommonAddress, fromEpoch, toEpoch uint64) (*OperatorReport, error) {
+	if toEpoch < fromEpoch || toEpoch-fromEpoch >= maxRewardEpochRange {
+		return nil, ErrRewardEpochRange
+	}
+	report := &OperatorReport{Addr: *addr, Epochs: []*OperatorEpoch{}, Total: &OperatorStat{}}
+	rewards, fees := new(big.Int), new(big.Int)
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		stat := ledger.operatorStat(epoch, addr)
+		// the rewards credited to the leader include the fees
+		reward := ledger.getAmount(rewardOwedKey(epoch, addr))
+		reward.Sub(reward, stat.Fees.ToInt())
+		if reward.Sign() < 0 {
+			reward.SetInt64(0)
+		}
+		if stat.Produced == 0 && stat.Signed == 0 && stat.Missed == 0 && reward.Sign() == 0 {
+			continue
+		}
+		stat.Rewards = common.Big(*reward)
+		report.Epochs = append(report.Epochs, &OperatorEpoch{Epoch: epoch, OperatorStat: *stat})
+
+		report.Total.Produced += stat.Produced
+		report.Total.Signed += stat.Signed
+		report.Total.Missed += stat.Missed
+		rewards.Add(rewards, reward)
+		fees.Add(fees, stat.Fees.ToInt())
+	}
+	report.Total.Rewards = common.Big(*rewards)
+	report.Total.Fees = common.Big(*fees)
+	return report, nil
+}
+
+// operatorStatKey return the prefix of epoch if addr is nil
+func operatorStatKey(epoch uint64, addr *crypto.CommonAddress) []byte {
+	key := append(append([]byte{}, operatorStatPrefix...), epochBytes(epoch)...)
+	if addr != nil {
+		key = append(key, addr.Bytes()...)
+	}
+	return key
+}
+
+func operatorBlockKey(hash *crypto.Hash) []byte {
+	return append(append([]byte{}, operatorBlockPrefix...), hash.Bytes()...)
+}
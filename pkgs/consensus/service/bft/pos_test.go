@@ -8,6 +8,7 @@ import (
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/types"
 	"math/big"
 	"testing"
@@ -149,6 +150,26 @@ func (StoreFake) Put(key []byte, value []byte) error {
 	panic("implement me")
 }
 
+func (StoreFake) Prove(key []byte, proofDb dbinterface.KeyValueWriter) error {
+	panic("implement me")
+}
+
+func (StoreFake) GetToken(symbol string) (*types.Token, error) {
+	panic("implement me")
+}
+
+func (StoreFake) PutToken(token *types.Token) error {
+	panic("implement me")
+}
+
+func (StoreFake) GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	panic("implement me")
+}
+
+func (StoreFake) PutTokenBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	panic("implement me")
+}
+
 func (StoreFake) CopyState() *database.SnapShot {
 	panic("implement me")
 }
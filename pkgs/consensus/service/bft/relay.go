@@ -0,0 +1,203 @@
+package bft
+
+import (
+	"io/ioutil"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	p2pService "github.com/drep-project/DREP-Chain/network/service"
+	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
+	"github.com/drep-project/binary"
+)
+
+// Consensus relay
+//
+// A producer in sentry mode is only connected to its sentries, the other
+// producers reach it through them. Sentries announce the producers they are
+// connected to, a node not connected to one of them sends its consensus
+// messages wrapped in a Relay to the announcing peer, which forwards it. The
+// message keeps its code and payload, the receiver handles it as coming from
+// the original producer. Routes expire when they are not announced again and
+// a relay is dropped after maxRelayHops forwards.
+
+const (
+	relayAnnounceInterval = 3 * time.Second
+	relayRouteTTL         = 3 * relayAnnounceInterval
+	maxRelayHops          = 3
+)
+
+// Relay is a consensus message forwarded by sentries, From and To are node ids
+type Relay struct {
+	From string
+	To   string
+	Code uint64
+	Msg  []byte
+	Hops uint8
+}
+
+// RelayAnnounce list the producers reachable through the sentry sending it
+type RelayAnnounce struct {
+	Nodes []string
+}
+
+type relayRoute struct {
+	via     consensusTypes.IPeerInfo
+	expires time.Time
+}
+
+// relayPeer is a producer reached through a sentry, messages written to it are relayed
+type relayPeer struct {
+	id        string
+	via       consensusTypes.IPeerInfo
+	consensus *BftConsensus
+}
+
+func (peer *relayPeer) GetMsgRW() p2p.MsgReadWriter {
+	return &relayRW{peer}
+}
+
+func (peer *relayPeer) IP() string {
+	return "relay:" + peer.via.IP()
+}
+
+func (peer *relayPeer) Equal(ipeer consensusTypes.IPeerInfo) bool {
+	return ipeer.ID() == peer.id
+}
+
+func (peer *relayPeer) ID() string {
+	return peer.id
+}
+
+// relayRW wrap the messages written to a relay peer, nothing is read from it
+type relayRW struct {
+	peer *relayPeer
+}
+
+func (rw *relayRW) ReadMsg() (p2p.Msg, error) {
+	return p2p.Msg{}, ErrRelayRead
+}
+
+func (rw *relayRW) WriteMsg(msg p2p.Msg) error {
+	buf, err := ioutil.ReadAll(msg.Payload)
+	if err != nil {
+		return err
+	}
+	relay := &Relay{From: rw.peer.consensus.selfID, To: rw.peer.id, Code: msg.Code, Msg: buf}
+	return <-rw.peer.consensus.sender.SendAsync(rw.peer.via.GetMsgRW(), MsgTypeRelay, relay)
+}
+
+// startRelay read the role of the node in the sentry architecture, a sentry announces the
+// producers it can reach until the consensus is closed
+func (bftConsensus *BftConsensus) startRelay(p2p p2pService.P2P) {
+	if local := p2p.LocalNode(); local != nil {
+		bftConsensus.selfID = local.ID().String()
+	}
+	bftConsensus.sentry = p2p.Sentry()
+	if !bftConsensus.sentry {
+		return
+	}
+	log.Info("bft relay consensus messages of private producers")
+	timer := time.NewTicker(relayAnnounceInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			bftConsensus.announceRoutes()
+		case <-bftConsensus.quit:
+			return
+		}
+	}
+}
+
+// announceRoutes send the nodes this sentry reaches to all its consensus peers
+func (bftConsensus *BftConsensus) announceRoutes() {
+	bftConsensus.peerLock.RLock()
+	defer bftConsensus.peerLock.RUnlock()
+	nodes := make([]string, 0, len(bftConsensus.onLinePeer)+len(bftConsensus.routes))
+	for id := range bftConsensus.onLinePeer {
+		nodes = append(nodes, id)
+	}
+	now := time.Now()
+	for id, route := range bftConsensus.routes {
+		if _, ok := bftConsensus.onLinePeer[id]; !ok && route.expires.After(now) {
+			nodes = append(nodes, id)
+		}
+	}
+	for _, peer := range bftConsensus.onLinePeer {
+		bftConsensus.sender.SendAsync(peer.GetMsgRW(), MsgTypeRelayAnnounce, &RelayAnnounce{Nodes: nodes})
+	}
+}
+
+// onRelayAnnounce learn the nodes reachable through peer
+func (bftConsensus *BftConsensus) onRelayAnnounce(peer consensusTypes.IPeerInfo, buf []byte) {
+	announce := &RelayAnnounce{}
+	if err := binary.Unmarshal(buf, announce); err != nil {
+		log.WithField("addr", peer.IP()).WithField("Reason", err).Debug("unmarshal relay announce")
+		return
+	}
+	now := time.Now()
+	bftConsensus.peerLock.Lock()
+	defer bftConsensus.peerLock.Unlock()
+	for id, route := range bftConsensus.routes {
+		if route.expires.Before(now) {
+			delete(bftConsensus.routes, id)
+		}
+	}
+	for _, id := range announce.Nodes {
+		if id == bftConsensus.selfID || id == peer.ID() {
+			continue
+		}
+		if _, ok := bftConsensus.onLinePeer[id]; ok {
+			continue
+		}
+		bftConsensus.routes[id] = &relayRoute{via: peer, expires: now.Add(relayRouteTTL)}
+	}
+}
+
+// onRelay deliver a relay addressed to this node, a sentry forwards the others
+func (bftConsensus *BftConsensus) onRelay(peer consensusTypes.IPeerInfo, buf []byte) {
+	relay := &Relay{}
+	if err := binary.Unmarshal(buf, relay); err != nil {
+		log.WithField("addr", peer.IP()).WithField("Reason", err).Debug("unmarshal relay")
+		return
+	}
+	if relay.Code == MsgTypeRelay || relay.Code == MsgTypeRelayAnnounce {
+		return
+	}
+	if relay.To == bftConsensus.selfID {
+		bftConsensus.ReceiveMsg(&relayPeer{id: relay.From, via: peer, consensus: bftConsensus}, relay.Code, relay.Msg)
+		return
+	}
+	if !bftConsensus.sentry || relay.Hops >= maxRelayHops {
+		relayDropMeter.Mark(1)
+		return
+	}
+	next, ok := bftConsensus.getPeer(relay.To)
+	if !ok {
+		relayDropMeter.Mark(1)
+		return
+	}
+	if routed, ok := next.(*relayPeer); ok {
+		next = routed.via
+	}
+	relay.Hops++
+	bftConsensus.sender.SendAsync(next.GetMsgRW(), MsgTypeRelay, relay)
+	relayMeter.Mark(1)
+}
+
+// getPeer return the consensus peer of node id, connected or reached through a sentry
+func (bftConsensus *BftConsensus) getPeer(id string) (consensusTypes.IPeerInfo, bool) {
+	bftConsensus.peerLock.RLock()
+	defer bftConsensus.peerLock.RUnlock()
+	if peer, ok := bftConsensus.onLinePeer[id]; ok {
+		return peer, true
+	}
+	route, ok := bftConsensus.routes[id]
+	if !ok || route.expires.Before(time.Now()) {
+		return nil, false
+	}
+	if _, ok := bftConsensus.onLinePeer[route.via.ID()]; !ok {
+		return nil, false
+	}
+	return &relayPeer{id: id, via: route.via, consensus: bftConsensus}, true
+}
@@ -1,10 +1,14 @@
 package bft
 
 import (
+	"bytes"
 	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/params"
 	"math"
 	"math/big"
+	"sort"
 )
 
 type IRewardCalculator interface {
@@ -17,6 +21,7 @@ type RewardCalculator struct {
 	sig             *MultiSignature
 	producers       ProducerSet
 	totalGasBalance *big.Int
+	rewards         []*RewardEntry //rewards credited by AccumulateRewards
 }
 
 func NewRewardCalculator(trieStore store.StoreInterface, sig *MultiSignature, producers ProducerSet, totalGasBalance *big.Int, height uint64) *RewardCalculator {
@@ -29,14 +34,19 @@ func NewRewardCalculator(trieStore store.StoreInterface, sig *MultiSignature, pr
 	}
 }
 
-// AccumulateRewards credits,The leader gets half of the reward and other ,Other participants get the average of the other half
-func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
+// blockReward return the reward of the block at height, it halves every 4 years
+func blockReward(height uint64) *big.Int {
 	reward := big.NewInt(params.Rewards)
 	reward.Mul(reward, new(big.Int).SetUint64(params.Coin))
 
 	rate := int64(height / (4 * params.BlockCountOfEveryYear)) //Number of new blocks in 4 years
 	rate = int64(math.Exp2(float64(rate)))
-	reward.Div(reward, new(big.Int).SetInt64(rate))
+	return reward.Div(reward, new(big.Int).SetInt64(rate))
+}
+
+// AccumulateRewards credits,The leader gets half of the reward and other ,Other participants get the average of the other half
+func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
+	reward := blockReward(height)
 
 	//Eighty percent for themselves and twenty percent for their supporters
 	var selfProportion int64 = 80
@@ -52,11 +62,18 @@ func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
 	supporters := calculator.trieStore.GetCreditDetails(&leaderAddr)
 	delete(supporters, leaderAddr)
 
-	for _, v := range supporters {
+	// credit supporters in address order, map order differs between nodes
+	supporterAddrs := make([]crypto.CommonAddress, 0, len(supporters))
+	for addr, v := range supporters {
 		total = total.Add(total, &v)
+		supporterAddrs = append(supporterAddrs, addr)
 	}
+	sort.Slice(supporterAddrs, func(i, j int) bool {
+		return bytes.Compare(supporterAddrs[i][:], supporterAddrs[j][:]) < 0
+	})
 
-	for spporterAddr, supportCredit := range supporters {
+	for _, spporterAddr := range supporterAddrs {
+		supportCredit := supporters[spporterAddr]
 		bonus := new(big.Int).Set(otherReward)
 		bonus = bonus.Mul(bonus, &supportCredit)
 		bonus = bonus.Div(bonus, total)
@@ -65,6 +82,7 @@ func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
 		if err != nil {
 			return err
 		}
+		calculator.rewards = append(calculator.rewards, &RewardEntry{Addr: spporterAddr, Amount: common.Big(*bonus)})
 	}
 
 	if len(supporters) == 0 {
@@ -80,6 +98,12 @@ func (calculator *RewardCalculator) AccumulateRewards(height uint64) error {
 	if err != nil {
 		return err
 	}
+	calculator.rewards = append(calculator.rewards, &RewardEntry{Addr: leaderAddr, Amount: common.Big(*leaderReward)})
 
 	return nil
 }
+
+// Rewards return the rewards credited to leader and supporters in AccumulateRewards
+func (calculator *RewardCalculator) Rewards() []*RewardEntry {
+	return calculator.rewards
+}
@@ -1,10 +1,12 @@
 package bft
 
 import (
+	"bytes"
 	"crypto/rand"
 	"github.com/drep-project/DREP-Chain/common/trie"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
 	"github.com/drep-project/DREP-Chain/types"
 	"math/big"
 	"testing"
@@ -74,6 +76,22 @@ func (fakeStore) GetReputation(addr *crypto.CommonAddress) *big.Int {
 	panic("implement me")
 }
 
+func (fakeStore) GetToken(symbol string) (*types.Token, error) {
+	panic("implement me")
+}
+
+func (fakeStore) PutToken(token *types.Token) error {
+	panic("implement me")
+}
+
+func (fakeStore) GetTokenBalance(addr *crypto.CommonAddress, symbol string) *big.Int {
+	panic("implement me")
+}
+
+func (fakeStore) PutTokenBalance(addr *crypto.CommonAddress, symbol string, balance *big.Int) error {
+	panic("implement me")
+}
+
 func (fakeStore) GetStateRoot() []byte {
 	panic("implement me")
 }
@@ -90,6 +108,10 @@ func (fakeStore) Get(key []byte) ([]byte, error) {
 	panic("implement me")
 }
 
+func (fakeStore) Prove(key []byte, proofDb dbinterface.KeyValueWriter) error {
+	panic("implement me")
+}
+
 func (fakeStore) Put(key []byte, value []byte) error {
 	panic("implement me")
 }
@@ -172,4 +194,15 @@ func TestRewardCalculator_AccumulateRewards(t *testing.T) {
 	if err != nil {
 		panic("reward errrrrrrrrr")
 	}
+
+	// supporters are credited in address order, the leader last
+	rewards := nc.Rewards()
+	if len(rewards) != 6 {
+		t.Fatalf("%d rewards credited, expected 6", len(rewards))
+	}
+	for i := 1; i < 5; i++ {
+		if bytes.Compare(rewards[i-1].Addr[:], rewards[i].Addr[:]) >= 0 {
+			t.Fatalf("supporter %d credited out of address order", i)
+		}
+	}
 }
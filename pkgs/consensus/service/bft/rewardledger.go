@@ -0,0 +1,289 @@
+package bft
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/types"
+	dbinary "github.com/drep-project/binary"
+)
+
+const (
+	maxPendingRewardBlocks = 256  //Rewards of executed blocks not connected to main chain are dropped after so many blocks
+	maxRewardEpochRange    = 1024 //The maximum number of epochs queried at one time
+)
+
+var (
+	rewardOwedPrefix  = []byte("rewardOwed")  //epoch + address -> rewards of address in epoch
+	rewardBlockPrefix = []byte("rewardBlock") //block hash -> rewards credited by block, used to revert detached block
+	rewardCkptPrefix  = []byte("rewardCkpt")  //epoch -> checkpoint of epoch
+)
+
+// RewardEntry is the reward credited to an address
+type RewardEntry struct {
+	Addr   crypto.CommonAddress `json:"addr"`
+	Amount common.Big           `json:"amount"`
+}
+
+// EpochReward is the rewards credited to an address in an epoch
+type EpochReward struct {
+	Epoch  uint64     `json:"epoch"`
+	Amount common.Big `json:"amount"`
+}
+
+// RewardCheckpoint is the last main chain block whose rewards are included in the epoch ledger,
+// custodians compare it with their own records to reconcile.
+type RewardCheckpoint struct {
+	Epoch  uint64      `json:"epoch"`
+	Height uint64      `json:"height"`
+	Hash   crypto.Hash `json:"hash"`
+	Total  common.Big  `json:"total"` //Total rewards of the epoch
+}
+
+// RewardPage is a page of the rewards of an epoch
+type RewardPage struct {
+	Checkpoint *RewardCheckpoint `json:"checkpoint"`
+	Rewards    []*RewardEntry    `json:"rewards"`
+}
+
+type blockRewards struct {
+	Height    uint64
+	Rewards   []*RewardEntry
+	operators *blockOperators `binary:"ignore"` //stored apart, may be nil
+}
+
+// RewardLedger record the rewards of leaders and supporters per address per epoch,
+// and the blocks produced, signed and missed by each producer, an epoch is the interval that producers changed. The rewards of executed blocks are kept
+// in memory until the block is connected to main chain, and reverted when the block is detached.
+type RewardLedger struct {
+	db             dbinterface.KeyValueStore
+	changeInterval uint64
+
+	lock    sync.Mutex
+	pending map[crypto.Hash]*blockRewards
+}
+
+func NewRewardLedger(db dbinterface.KeyValueStore, changeInterval uint64) *RewardLedger {
+	if changeInterval == 0 {
+		changeInterval = 1
+	}
+	return &RewardLedger{
+		db:             db,
+		changeInterval: changeInterval,
+		pending:        make(map[crypto.Hash]*blockRewards),
+	}
+}
+
+// Epoch return the epoch of height
+func (ledger *RewardLedger) Epoch(height uint64) uint64 {
+	return height / ledger.changeInterval
+}
+
+// Executed keep the rewards and producers of block until it is connected to main chain
+func (ledger *RewardLedger) Executed(block *types.Block, rewards []*RewardEntry, operators *blockOperators) {
+	ledger.lock.Lock()
+	defer ledger.lock.Unlock()
+
+	ledger.pending[*block.Header.Hash()] = &blockRewards{Height: block.Header.Height, Rewards: rewards, operators: operators}
+}
+
+// Connected add rewards of block to epoch ledger
+func (ledger *RewardLedger) Connected(block *types.Block) error {
+	hash := block.Header.Hash()
+	ledger.lock.Lock()
+	rewards, ok := ledger.pending[*hash]
+	delete(ledger.pending, *hash)
+	for h, r := range ledger.pending {
+		if r.Height+maxPendingRewardBlocks < block.Header.Height {
+			delete(ledger.pending, h)
+		}
+	}
+	ledger.lock.Unlock()
+	if !ok {
+		log.WithField("height", block.Header.Height).WithField("hash", hash).Debug("no rewards of connected block")
+		return nil
+	}
+
+	buf, err := dbinary.Marshal(rewards)
+	if err != nil {
+		return err
+	}
+	batch := ledger.db.NewBatch()
+	batch.Put(rewardBlockKey(hash), buf)
+	epoch := ledger.Epoch(block.Header.Height)
+	total, err := ledger.applyRewards(batch, epoch, rewards.Rewards, false)
+	if err != nil {
+		return err
+	}
+	ckpt := ledger.Checkpoint(epoch)
+	ckpt.Height = block.Header.Height
+	ckpt.Hash = *hash
+	ckpt.Total = common.Big(*new(big.Int).Add(ckpt.Total.ToInt(), total))
+	if err := putCheckpoint(batch, ckpt); err != nil {
+		return err
+	}
+	if rewards.operators != nil {
+		if err := ledger.connectOperators(batch, block, rewards.operators); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// Detached revert rewards of block from epoch ledger
+func (ledger *RewardLedger) Detached(block *types.Block) error {
+	hash := block.Header.Hash()
+	buf, err := ledger.db.Get(rewardBlockKey(hash))
+	if err != nil || len(buf) == 0 {
+		return nil
+	}
+	rewards := &blockRewards{}
+	if err := dbinary.Unmarshal(buf, rewards); err != nil {
+		return err
+	}
+
+	batch := ledger.db.NewBatch()
+	batch.Delete(rewardBlockKey(hash))
+	epoch := ledger.Epoch(block.Header.Height)
+	total, err := ledger.applyRewards(batch, epoch, rewards.Rewards, true)
+	if err != nil {
+		return err
+	}
+	ckpt := ledger.Checkpoint(epoch)
+	ckpt.Height = block.Header.Height - 1
+	ckpt.Hash = block.Header.PreviousHash
+	ckpt.Total = common.Big(*new(big.Int).Sub(ckpt.Total.ToInt(), total))
+	if err := putCheckpoint(batch, ckpt); err != nil {
+		return err
+	}
+	if err := ledger.detachOperators(batch, block); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// applyRewards add or sub rewards of addresses in epoch, the sum of rewards is returned
+func (ledger *RewardLedger) applyRewards(batch dbinterface.Batch, epoch uint64, rewards []*RewardEntry, revert bool) (*big.Int, error) {
+	total := new(big.Int)
+	for _, reward := range rewards {
+		key := rewardOwedKey(epoch, &reward.Addr)
+		owed := ledger.getAmount(key)
+		if revert {
+			owed.Sub(owed, reward.Amount.ToInt())
+		} else {
+			owed.Add(owed, reward.Amount.ToInt())
+		}
+		total.Add(total, reward.Amount.ToInt())
+
+		if owed.Sign() <= 0 {
+			if err := batch.Delete(key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := batch.Put(key, owed.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func (ledger *RewardLedger) getAmount(key []byte) *big.Int {
+	buf, err := ledger.db.Get(key)
+	if err != nil {
+		return new(big.Int)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// Checkpoint return the checkpoint of epoch, an empty one is returned if no rewards in epoch
+func (ledger *RewardLedger) Checkpoint(epoch uint64) *RewardCheckpoint {
+	ckpt := &RewardCheckpoint{Epoch: epoch}
+	buf, err := ledger.db.Get(rewardCkptKey(epoch))
+	if err != nil || len(buf) == 0 {
+		return ckpt
+	}
+	if err := dbinary.Unmarshal(buf, ckpt); err != nil {
+		log.WithField("epoch", epoch).WithField("err", err).Error("unmarshal reward checkpoint")
+		return &RewardCheckpoint{Epoch: epoch}
+	}
+	return ckpt
+}
+
+// Rewards return rewards of epoch ordered by address, pageIndex start from 1
+func (ledger *RewardLedger) Rewards(epoch uint64, pageIndex, pageSize int) *RewardPage {
+	page := &RewardPage{Checkpoint: ledger.Checkpoint(epoch), Rewards: []*RewardEntry{}}
+	fromIndex := (pageIndex - 1) * pageSize
+	if fromIndex < 0 || pageSize <= 0 {
+		return page
+	}
+
+	prefix := rewardOwedKey(epoch, nil)
+	iter := ledger.db.NewIteratorWithPrefix(prefix)
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		if count >= fromIndex+pageSize {
+			break
+		}
+		if count >= fromIndex {
+			entry := &RewardEntry{Amount: common.Big(*new(big.Int).SetBytes(iter.Value()))}
+			entry.Addr.SetBytes(iter.Key()[len(prefix):])
+			page.Rewards = append(page.Rewards, entry)
+		}
+		count++
+	}
+	return page
+}
+
+// AddressRewards return the rewards of address in epochs [fromEpoch, toEpoch]
+func (ledger *RewardLedger) AddressRewards(addr *crypto.CommonAddress, fromEpoch, toEpoch uint64) ([]*EpochReward, error) {
+	if toEpoch < fromEpoch || toEpoch-fromEpoch >= maxRewardEpochRange {
+		return nil, ErrRewardEpochRange
+	}
+	rewards := []*EpochReward{}
+	for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+		amount := ledger.getAmount(rewardOwedKey(epoch, addr))
+		if amount.Sign() == 0 {
+			continue
+		}
+		rewards = append(rewards, &EpochReward{Epoch: epoch, Amount: common.Big(*amount)})
+	}
+	return rewards, nil
+}
+
+func putCheckpoint(batch dbinterface.Batch, ckpt *RewardCheckpoint) error {
+	buf, err := dbinary.Marshal(ckpt)
+	if err != nil {
+		return err
+	}
+	return batch.Put(rewardCkptKey(ckpt.Epoch), buf)
+}
+
+func epochBytes(epoch uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, epoch)
+	return buf
+}
+
+// rewardOwedKey return the prefix of epoch if addr is nil
+func rewardOwedKey(epoch uint64, addr *crypto.CommonAddress) []byte {
+	key := append(append([]byte{}, rewardOwedPrefix...), epochBytes(epoch)...)
+	if addr != nil {
+		key = append(key, addr.Bytes()...)
+	}
+	return key
+}
+
+func rewardBlockKey(hash *crypto.Hash) []byte {
+	return append(append([]byte{}, rewardBlockPrefix...), hash.Bytes()...)
+}
+
+func rewardCkptKey(epoch uint64) []byte {
+	return append(append([]byte{}, rewardCkptPrefix...), epochBytes(epoch)...)
+}
@@ -0,0 +1,123 @@
+package bft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func newRewardBlock(height uint64, prev crypto.Hash) *types.Block {
+	return &types.Block{Header: &types.BlockHeader{Height: height, PreviousHash: prev, GasLimit: *big.NewInt(0), GasUsed: *big.NewInt(0)}}
+}
+
+func TestRewardLedger(t *testing.T) {
+	ledger := NewRewardLedger(memorydb.New(), 10)
+	addr1 := crypto.CommonAddress{1}
+	addr2 := crypto.CommonAddress{2}
+
+	block1 := newRewardBlock(11, crypto.Hash{})
+	ledger.Executed(block1, []*RewardEntry{{Addr: addr1, Amount: common.Big(*big.NewInt(80))}, {Addr: addr2, Amount: common.Big(*big.NewInt(20))}}, nil)
+	if err := ledger.Connected(block1); err != nil {
+		t.Fatal(err)
+	}
+	block2 := newRewardBlock(12, *block1.Header.Hash())
+	ledger.Executed(block2, []*RewardEntry{{Addr: addr1, Amount: common.Big(*big.NewInt(100))}}, nil)
+	if err := ledger.Connected(block2); err != nil {
+		t.Fatal(err)
+	}
+
+	page := ledger.Rewards(1, 1, 1)
+	if len(page.Rewards) != 1 || page.Rewards[0].Addr != addr1 || page.Rewards[0].Amount.ToInt().Int64() != 180 {
+		t.Fatalf("unexpected first page %v", page.Rewards)
+	}
+	if page.Checkpoint.Height != 12 || page.Checkpoint.Total.ToInt().Int64() != 200 {
+		t.Fatalf("unexpected checkpoint %v", page.Checkpoint)
+	}
+	page = ledger.Rewards(1, 2, 1)
+	if len(page.Rewards) != 1 || page.Rewards[0].Addr != addr2 {
+		t.Fatalf("unexpected second page %v", page.Rewards)
+	}
+
+	if err := ledger.Detached(block2); err != nil {
+		t.Fatal(err)
+	}
+	rewards, err := ledger.AddressRewards(&addr1, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewards) != 1 || rewards[0].Epoch != 1 || rewards[0].Amount.ToInt().Int64() != 80 {
+		t.Fatalf("unexpected rewards after detach %v", rewards)
+	}
+	ckpt := ledger.Checkpoint(1)
+	if ckpt.Height != 11 || ckpt.Hash != *block1.Header.Hash() || ckpt.Total.ToInt().Int64() != 100 {
+		t.Fatalf("unexpected checkpoint after detach %v", ckpt)
+	}
+
+	if _, err := ledger.AddressRewards(&addr1, 2, 1); err != ErrRewardEpochRange {
+		t.Fatalf("expect range error, got %v", err)
+	}
+}
+
+func TestOperatorReport(t *testing.T) {
+	ledger := NewRewardLedger(memorydb.New(), 10)
+	leader := crypto.CommonAddress{1}
+	member := crypto.CommonAddress{2}
+	operators := &blockOperators{Leader: leader, Fee: common.Big(*big.NewInt(5)), Signed: []crypto.CommonAddress{leader}, Missed: []crypto.CommonAddress{member}}
+
+	block1 := newRewardBlock(11, crypto.Hash{})
+	ledger.Executed(block1, []*RewardEntry{{Addr: leader, Amount: common.Big(*big.NewInt(105))}}, operators)
+	if err := ledger.Connected(block1); err != nil {
+		t.Fatal(err)
+	}
+	block2 := newRewardBlock(21, *block1.Header.Hash())
+	ledger.Executed(block2, []*RewardEntry{{Addr: leader, Amount: common.Big(*big.NewInt(105))}}, operators)
+	if err := ledger.Connected(block2); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ledger.OperatorReport(&leader, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Epochs) != 2 || report.Epochs[0].Epoch != 1 || report.Epochs[0].Produced != 1 || report.Epochs[0].Rewards.ToInt().Int64() != 100 || report.Epochs[0].Fees.ToInt().Int64() != 5 {
+		t.Fatalf("unexpected epochs %v", report.Epochs)
+	}
+	if report.Total.Produced != 2 || report.Total.Signed != 2 || report.Total.Rewards.ToInt().Int64() != 200 || report.Total.Fees.ToInt().Int64() != 10 {
+		t.Fatalf("unexpected total %v", report.Total)
+	}
+
+	if err := ledger.Detached(block2); err != nil {
+		t.Fatal(err)
+	}
+	report, err = ledger.OperatorReport(&member, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Epochs) != 1 || report.Total.Missed != 1 || report.Total.Rewards.ToInt().Sign() != 0 {
+		t.Fatalf("unexpected report after detach %v", report.Epochs)
+	}
+}
+
+func TestOperatorStatEncoding(t *testing.T) {
+	for _, stat := range []*OperatorStat{
+		{},
+		{Signed: 3, Missed: 1},
+		{Produced: 2, Signed: 2, Fees: common.Big(*big.NewInt(300))},
+	} {
+		buf, err := encodeOperatorStat(stat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := decodeOperatorStat(buf)
+		if err != nil {
+			t.Fatalf("decode %v: %v", stat, err)
+		}
+		if decoded.Produced != stat.Produced || decoded.Signed != stat.Signed || decoded.Missed != stat.Missed || decoded.Fees.ToInt().Cmp(stat.Fees.ToInt()) != 0 {
+			t.Fatalf("stat changed by round trip, got %v want %v", decoded, stat)
+		}
+	}
+}
@@ -11,12 +11,15 @@ import (
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
 	"github.com/drep-project/DREP-Chain/database"
 	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	p2pService "github.com/drep-project/DREP-Chain/network/service"
 	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
 	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
 	chainTypes "github.com/drep-project/DREP-Chain/types"
 	"gopkg.in/urfave/cli.v1"
 	"io/ioutil"
+	"sync"
 	"time"
 )
 
@@ -27,16 +30,25 @@ var (
 	}
 )
 
+// maxRegistryNodes bounds the candidates read from chain into the p2p allowlist
+const maxRegistryNodes = 1000
+
 type BftConsensusService struct {
 	P2pServer        p2pService.P2P                       `service:"p2p"`
 	ChainService     chainService.ChainServiceInterface   `service:"chain"`
 	BroadCastor      blockMgrService.ISendMessage         `service:"blockmgr"`
 	BlockMgrNotifier blockMgrService.IBlockNotify         `service:"blockmgr"`
 	BlockGenerator   blockMgrService.IBlockBlockGenerator `service:"blockmgr"`
+	PoolQuery        blockMgrService.IBlockMgrPool        `service:"blockmgr"`
+	ProducerNodes    blockMgrService.IProducerNodes       `service:"blockmgr"`
 	DatabaseService  *database.DatabaseService            `service:"database"`
 	WalletService    *accountService.AccountService       `service:"accounts"`
 
 	BftConsensus *BftConsensus
+	RewardLedger *RewardLedger
+
+	blockDetailFeed  event.Feed
+	blockDetailScope event.SubscriptionScope
 
 	apis   []app.API
 	Config *BftConfig
@@ -49,6 +61,8 @@ type BftConsensusService struct {
 	pauseForSync bool
 	start        bool
 	quit         chan struct{}
+	quitOnce     sync.Once
+	mining       sync.WaitGroup // the block production loop, waited for on shutdown
 }
 
 func (bftConsensusService *BftConsensusService) Name() string {
@@ -60,7 +74,7 @@ func (bftConsensusService *BftConsensusService) Api() []app.API {
 }
 
 func (bftConsensusService *BftConsensusService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{MinerFlag}
+	return []cli.Command{ExportProofCommand, VerifyProofCommand}, []cli.Flag{MinerFlag}
 }
 
 func (bftConsensusService *BftConsensusService) Init(executeContext *app.ExecuteContext) error {
@@ -80,7 +94,8 @@ func (bftConsensusService *BftConsensusService) Init(executeContext *app.Execute
 		&removePeerFeed,
 	)
 
-	bftConsensusService.ChainService.AddBlockValidator(&BlockMultiSigValidator{bftConsensusService.BftConsensus.GetProducers, bftConsensusService.ChainService.GetBlockByHash, bftConsensusService.Config.ProducerNum})
+	bftConsensusService.RewardLedger = NewRewardLedger(bftConsensusService.DatabaseService.LevelDb(), bftConsensusService.Config.ChangeInterval)
+	bftConsensusService.ChainService.AddBlockValidator(&BlockMultiSigValidator{bftConsensusService.BftConsensus.GetProducers, bftConsensusService.ChainService.GetBlockByHash, bftConsensusService.Config.ProducerNum, bftConsensusService.RewardLedger})
 	bftConsensusService.ChainService.AddGenesisProcess(NewMinerGenesisProcessor())
 
 	if bftConsensusService.WalletService.Wallet == nil {
@@ -131,6 +146,7 @@ func (bftConsensusService *BftConsensusService) Init(executeContext *app.Execute
 	bftConsensusService.syncBlockEventChan = make(chan event.SyncBlockEvent)
 	bftConsensusService.syncBlockEventSub = bftConsensusService.BlockMgrNotifier.SubscribeSyncBlockEvent(bftConsensusService.syncBlockEventChan)
 	bftConsensusService.quit = make(chan struct{})
+	app.RegisterShutdown(bftConsensusService.Name(), app.ShutdownDrain, bftConsensusService.stopMining)
 	bftConsensusService.apis = []app.API{
 		app.API{
 			Namespace: "consensus",
@@ -140,8 +156,18 @@ func (bftConsensusService *BftConsensusService) Init(executeContext *app.Execute
 			},
 			Public: true,
 		},
+		app.API{
+			Namespace: "stake",
+			Version:   "1.0",
+			Service: &StakeApi{
+				consensusService: bftConsensusService,
+			},
+			Public: true,
+		},
 	}
 
+	app.RegisterStatus(MODULENAME, bftConsensusService.status)
+
 	go bftConsensusService.handlerEvent()
 	return nil
 }
@@ -164,12 +190,29 @@ func (bftConsensusService *BftConsensusService) handlerEvent() {
 }
 
 func (bftConsensusService *BftConsensusService) Start(executeContext *app.ExecuteContext) error {
+	if executeContext.Cli != nil {
+		switch executeContext.Cli.Command.Name {
+		case ExportProofCommand.Name, VerifyProofCommand.Name:
+			return bftConsensusService.runProofCommand(executeContext)
+		}
+	}
 	bftConsensusService.start = true
+	if bftConsensusService.Config.StartMiner {
+		bftConsensusService.P2pServer.SetNodeRole(enr.RoleProducer)
+	}
 
+	go bftConsensusService.recordRewards()
+	if bftConsensusService.P2pServer.Permissioned() {
+		go bftConsensusService.syncAllowlist()
+	}
+	go bftConsensusService.syncProducerNodes()
 	go bftConsensusService.BftConsensus.processPeers()
 	go bftConsensusService.BftConsensus.prepareForMining(bftConsensusService.P2pServer)
+	go bftConsensusService.BftConsensus.startRelay(bftConsensusService.P2pServer)
 
+	bftConsensusService.mining.Add(1)
 	go func() {
+		defer bftConsensusService.mining.Done()
 		select {
 		case <-bftConsensusService.quit:
 			return
@@ -206,7 +249,11 @@ func (bftConsensusService *BftConsensusService) Start(executeContext *app.Execut
 				}
 				nextBlockTime, waitSpan := bftConsensusService.getWaitTime()
 				log.WithField("nextBlockTime", nextBlockTime).WithField("waitSpan", waitSpan).Debug("Sleep")
-				time.Sleep(waitSpan)
+				select {
+				case <-time.After(waitSpan):
+				case <-bftConsensusService.quit:
+					return
+				}
 			}
 		}
 	}()
@@ -214,24 +261,137 @@ func (bftConsensusService *BftConsensusService) Start(executeContext *app.Execut
 	return nil
 }
 
+// stopMining stop producing blocks once the block in production is processed, so the shutdown
+// never cuts a block off halfway
+func (bftConsensusService *BftConsensusService) stopMining() error {
+	bftConsensusService.quitOnce.Do(func() { close(bftConsensusService.quit) })
+	bftConsensusService.mining.Wait()
+	return nil
+}
+
 func (bftConsensusService *BftConsensusService) Stop(executeContext *app.ExecuteContext) error {
 	if bftConsensusService.Config == nil { //|| !bftConsensusService.Config.StartMiner
 		return nil
 	}
 
 	if bftConsensusService.quit != nil {
-		close(bftConsensusService.quit)
+		bftConsensusService.quitOnce.Do(func() { close(bftConsensusService.quit) })
 	}
 
 	if bftConsensusService.syncBlockEventSub != nil {
 		bftConsensusService.syncBlockEventSub.Unsubscribe()
 	}
+	bftConsensusService.blockDetailScope.Close()
 
 	bftConsensusService.BftConsensus.Close()
 
 	return nil
 }
 
+// recordRewards apply rewards of blocks connected to or detached from main chain to reward ledger
+func (bftConsensusService *BftConsensusService) recordRewards() {
+	newBlockCh := make(chan *chainTypes.ChainEvent, 100)
+	detachBlockCh := make(chan *chainTypes.Block, 100)
+	newBlockSub := bftConsensusService.ChainService.NewBlockFeed().Subscribe(newBlockCh)
+	detachBlockSub := bftConsensusService.ChainService.DetachBlockFeed().Subscribe(detachBlockCh)
+	defer newBlockSub.Unsubscribe()
+	defer detachBlockSub.Unsubscribe()
+
+	detach := func(block *chainTypes.Block) {
+		if err := bftConsensusService.RewardLedger.Detached(block); err != nil {
+			log.WithField("height", block.Header.Height).WithField("err", err).Error("revert block rewards")
+		}
+	}
+	for {
+		select {
+		case e := <-newBlockCh:
+			//Blocks detached in reorg are sent before the new blocks, revert them first
+			for len(detachBlockCh) > 0 {
+				detach(<-detachBlockCh)
+			}
+			if err := bftConsensusService.RewardLedger.Connected(e.Block); err != nil {
+				log.WithField("height", e.Block.Header.Height).WithField("err", err).Error("record block rewards")
+			}
+			bftConsensusService.sendBlockDetail(e.Block)
+		case block := <-detachBlockCh:
+			detach(block)
+		case <-bftConsensusService.quit:
+			return
+		}
+	}
+}
+
+// syncAllowlist keeps the chain source of the p2p allowlist in line with the
+// candidates registered on chain, so registered producers can connect to a
+// permissioned network without restarting its nodes.
+func (bftConsensusService *BftConsensusService) syncAllowlist() {
+	newBlockCh := make(chan *chainTypes.ChainEvent, 100)
+	newBlockSub := bftConsensusService.ChainService.NewBlockFeed().Subscribe(newBlockCh)
+	defer newBlockSub.Unsubscribe()
+
+	update := func(height uint64) {
+		producers, err := bftConsensusService.GetProducers(height, maxRegistryNodes)
+		if err != nil {
+			log.WithField("height", height).WithField("err", err).Warn("load allowlist from chain")
+			return
+		}
+		ids := make([]enode.ID, 0, len(producers))
+		for _, producer := range producers {
+			ids = append(ids, producer.Node.ID())
+		}
+		if err := bftConsensusService.P2pServer.SetAllowedNodes(p2pService.AllowlistSourceChain, ids); err != nil {
+			log.WithField("err", err).Warn("update allowlist from chain")
+		}
+	}
+	update(bftConsensusService.ChainService.BestChain().Height())
+	for {
+		select {
+		case e := <-newBlockCh:
+			//only the latest block matters when catching up
+			for len(newBlockCh) > 0 {
+				e = <-newBlockCh
+			}
+			update(e.Block.Header.Height)
+		case <-bftConsensusService.quit:
+			return
+		}
+	}
+}
+
+// syncProducerNodes keeps the producer nodes of blockmgr in line with the
+// producers on chain, private transactions are sent to them only.
+func (bftConsensusService *BftConsensusService) syncProducerNodes() {
+	newBlockCh := make(chan *chainTypes.ChainEvent, 100)
+	newBlockSub := bftConsensusService.ChainService.NewBlockFeed().Subscribe(newBlockCh)
+	defer newBlockSub.Unsubscribe()
+
+	update := func(height uint64) {
+		producers, err := bftConsensusService.GetProducers(height, bftConsensusService.Config.ProducerNum)
+		if err != nil {
+			log.WithField("height", height).WithField("err", err).Warn("load producer nodes from chain")
+			return
+		}
+		ids := make([]enode.ID, 0, len(producers))
+		for _, producer := range producers {
+			ids = append(ids, producer.Node.ID())
+		}
+		bftConsensusService.ProducerNodes.SetProducerNodes(ids)
+	}
+	update(bftConsensusService.ChainService.BestChain().Height())
+	for {
+		select {
+		case e := <-newBlockCh:
+			//only the latest block matters when catching up
+			for len(newBlockCh) > 0 {
+				e = <-newBlockCh
+			}
+			update(e.Block.Header.Height)
+		case <-bftConsensusService.quit:
+			return
+		}
+	}
+}
+
 func (bftConsensusService *BftConsensusService) getWaitTime() (time.Time, time.Duration) {
 	lastBlockTime := time.Unix(int64(bftConsensusService.ChainService.BestChain().Tip().TimeStamp), 0)
 	targetTime := lastBlockTime.Add(time.Duration(int64(time.Second) * bftConsensusService.Config.BlockInterval))
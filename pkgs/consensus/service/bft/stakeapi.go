@@ -0,0 +1,229 @@
+package bft
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/rpc"
+)
+
+/*
+name: stake api
+usage: Register the local node as candidate
+prefix:stake
+*/
+type StakeApi struct {
+	consensusService *BftConsensusService
+}
+
+/*
+ name: registerCandidateAuto
+ usage: Pledge with the consensus account of this node and register it as candidate, the candidate data is built from the mypk in config and local enode
+ params:
+	1. The pledge amount
+	2. gas price
+	3. gas limit
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_registerCandidateAuto","params":["0x295be96e64066972000000","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (stakeApi *StakeApi) RegisterCandidateAuto(amount, gasprice, gaslimit *common.Big) (string, error) {
+	service := stakeApi.consensusService
+	if service.Config.MyPk == nil {
+		return "", ErrNoConsensusPubkey
+	}
+	if service.WalletService.Wallet == nil {
+		return "", ErrWalletNotOpen
+	}
+
+	cd := &types.CandidateData{Pubkey: service.Config.MyPk, Node: service.P2pServer.LocalNode().String()}
+	data, err := cd.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	from := crypto.PubkeyToAddress(service.Config.MyPk)
+	if err := stakeApi.checkPledge(&from, (*big.Int)(amount)); err != nil {
+		return "", err
+	}
+
+	nonce := service.PoolQuery.GetTransactionCount(&from)
+	tx := types.NewCandidateTransaction((*big.Int)(amount), (*big.Int)(gasprice), (*big.Int)(gaslimit), nonce, data)
+	if err := service.WalletService.Wallet.SignTransaction(&from, tx); err != nil {
+		return "", err
+	}
+	err = service.BroadCastor.SendTransaction(tx, true)
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+// checkPledge make sure the balance is enough and the pledge of candidate itself reach the register limit after this tx
+func (stakeApi *StakeApi) checkPledge(from *crypto.CommonAddress, amount *big.Int) error {
+	if amount == nil || amount.Sign() <= 0 {
+		return ErrPledgeAmount
+	}
+	chain := stakeApi.consensusService.ChainService
+	tip := chain.BestChain().Tip()
+	trieStore, err := store.TrieStoreFromStore(stakeApi.consensusService.DatabaseService.LevelDb(), tip.StateRoot)
+	if err != nil {
+		return err
+	}
+
+	balance := trieStore.GetBalance(from, tip.Height)
+	if balance.Cmp(amount) < 0 {
+		return fmt.Errorf("%v, balance:%v amount:%v", ErrPledgeBalance, balance, amount)
+	}
+
+	pledge := new(big.Int).Set(amount)
+	if selfCredit, ok := trieStore.GetCreditDetails(from)[*from]; ok {
+		pledge.Add(pledge, &selfCredit)
+	}
+	limit := new(big.Int).Mul(new(big.Int).SetUint64(store.RegisterPledgeLimit), new(big.Int).SetUint64(params.Coin))
+	if pledge.Cmp(limit) < 0 {
+		return fmt.Errorf("%v, pledge:%v limit:%v", ErrPledgeLimit, pledge, limit)
+	}
+	return nil
+}
+
+/*
+ name: getEpochRewards
+ usage: Query the rewards credited to leaders and supporters in an epoch (the interval that producers changed), ordered by address
+ params:
+	1. epoch
+	2. page index, start from 1
+	3. page size
+ return: the checkpoint (the last block included and total rewards of epoch) and rewards of addresses
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_getEpochRewards","params":[12,1,100],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"checkpoint":{"epoch":12,"height":1299,"hash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","total":"0x4563918244f40000"},"rewards":[{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","amount":"0x3782dace9d900000"},{"addr":"0x8a8e541ddd1272d53729164c70197221a3c27486","amount":"0xde0b6b3a7640000"}]}}
+*/
+func (stakeApi *StakeApi) GetEpochRewards(epoch uint64, pageIndex, pageSize int) *RewardPage {
+	return stakeApi.consensusService.RewardLedger.Rewards(epoch, pageIndex, pageSize)
+}
+
+/*
+ name: getAddressRewards
+ usage: Query the rewards credited to an address in every epoch of the range
+ params:
+	1. address
+	2. from epoch
+	3. to epoch, at most 1024 epochs are queried at one time
+ return: rewards of address per epoch, epochs without rewards are omitted
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_getAddressRewards","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",10,12],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":[{"epoch":10,"amount":"0x3782dace9d900000"},{"epoch":12,"amount":"0x3782dace9d900000"}]}
+*/
+func (stakeApi *StakeApi) GetAddressRewards(addr crypto.CommonAddress, fromEpoch, toEpoch uint64) ([]*EpochReward, error) {
+	return stakeApi.consensusService.RewardLedger.AddressRewards(&addr, fromEpoch, toEpoch)
+}
+
+/*
+ name: getRewardCheckpoint
+ usage: Query the checkpoint of an epoch, the epoch of current block is used if epoch is omitted
+ params:
+	1. epoch (optional)
+ return: the epoch, the last block whose rewards are included and the total rewards of epoch
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_getRewardCheckpoint","params":[],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"epoch":12,"height":1299,"hash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","total":"0x4563918244f40000"}}
+*/
+func (stakeApi *StakeApi) GetRewardCheckpoint(epoch *uint64) *RewardCheckpoint {
+	ledger := stakeApi.consensusService.RewardLedger
+	if epoch == nil {
+		current := ledger.Epoch(stakeApi.consensusService.ChainService.BestChain().Height())
+		epoch = &current
+	}
+	return ledger.Checkpoint(*epoch)
+}
+
+/*
+ name: estimateRewards
+ usage: Estimate the income of a candidate as leader in the epochs after the current one, assuming the stake distribution at the latest block holds and the fees per block follow the recent epochs
+ params:
+	1. address of the candidate
+	2. pledge the candidate would add, 0 for its current stake
+	3. number of epochs, at most 1024
+ return: the rank the credit reaches among candidates, the expected blocks, rewards and fees, the fees of the recent 16 epochs and the income realized by the address in them
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_estimateRewards","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x295be96e64066972000000",10],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","credit":"0x2b036da601a044b4000000","rank":3,"producer":true,"fromEpoch":2,"toEpoch":11,"blocks":142,"rewards":"0x26b8b4a0b1e82924924","fees":"0x3e346939","total":"0x26b8b4a0b1ec0c6b25d","feeStats":[{"epoch":0,"blocks":99,"fees":"0x2b5e3af0"}],"realized":{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","epochs":[],"total":{"produced":0,"signed":0,"missed":0,"rewards":"0x0","fees":"0x0"}}}}
+*/
+func (stakeApi *StakeApi) EstimateRewards(addr crypto.CommonAddress, pledge *common.Big, epochs uint64) (*RewardEstimate, error) {
+	return stakeApi.consensusService.EstimateRewards(&addr, (*big.Int)(pledge), epochs)
+}
+
+/*
+ name: getFeeStats
+ usage: Query the blocks produced and the gas fees collected by all producers in every epoch of the range
+ params:
+	1. from epoch
+	2. to epoch, at most 1024 epochs are queried at one time
+ return: blocks and fees per epoch
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_getFeeStats","params":[10,11],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":[{"epoch":10,"blocks":100,"fees":"0x2b5e3af0"},{"epoch":11,"blocks":98,"fees":"0x1e8480"}]}
+*/
+func (stakeApi *StakeApi) GetFeeStats(fromEpoch, toEpoch uint64) ([]*EpochFeeStat, error) {
+	return stakeApi.consensusService.RewardLedger.FeeStats(fromEpoch, toEpoch)
+}
+
+/*
+ name: getBlockDetail
+ usage: Query a main chain block with its leader, the producers that signed it (decoded from the multi signature of the block proof), the gas fees it collected and the rewards it paid
+ params:
+	1. height of the block
+ return: the block hash, height, time and transaction count, the leader, the bitmap of producers that signed and their addresses, the fees, the block reward (fees excluded) and the rewards credited per address, the last three are null until the rewards of the block are recorded
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"stake_getBlockDetail","params":[1299],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":{"hash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","height":1299,"timestamp":1592365562,"txCount":2,"leader":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","bitmap":"0x010100","signers":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486"],"fees":"0x5208","reward":"0x4563918244f40000","rewards":[{"addr":"0x8a8e541ddd1272d53729164c70197221a3c27486","amount":"0xde0b6b3a7640000"},{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","amount":"0x3782dace9d905208"}]}}
+*/
+func (stakeApi *StakeApi) GetBlockDetail(height uint64) (*BlockDetail, error) {
+	return stakeApi.consensusService.BlockDetail(height)
+}
+
+/*
+ name: newBlockDetail
+ usage: Subscribe the blocks connected to main chain over websocket with their leader, signers, fees and rewards, a notification is sent once the rewards of a block are recorded
+ params:
+ return: subscription id
+ example: wscat -c ws://localhost:10084 -x '{"jsonrpc":"2.0","method":"stake_subscribe","params":["newBlockDetail"], "id": 3}'
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"0xcd0c3e8af590364c09d0fa6a1210faf5"}
+	{"jsonrpc":"2.0","method":"stake_subscription","params":{"subscription":"0xcd0c3e8af590364c09d0fa6a1210faf5","result":{"hash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","height":1299,"timestamp":1592365562,"txCount":0,"leader":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","bitmap":"0x0101","signers":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486"],"fees":"0x0","reward":"0x4563918244f40000","rewards":[{"addr":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","amount":"0x4563918244f40000"}]}}}
+*/
+func (stakeApi *StakeApi) NewBlockDetail(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		detailCh := make(chan *BlockDetail, 16)
+		sub := stakeApi.consensusService.SubscribeBlockDetail(detailCh)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case detail := <-detailCh:
+				notifier.Notify(rpcSub.ID, detail)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,62 @@
+package bft
+
+import (
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// BftStatus is the bft section of the node status
+type BftStatus struct {
+	Miner       bool                  `json:"miner"`
+	Address     *crypto.CommonAddress `json:"address,omitempty"` //Address of MyPk, nil if no key is configured
+	Producer    bool                  `json:"producer"`          //MyPk is among the producers elected at the current height
+	ProducerNum int                   `json:"producerNum"`
+}
+
+func (bftConsensusService *BftConsensusService) status() interface{} {
+	config := bftConsensusService.Config
+	status := &BftStatus{
+		Miner:       config.StartMiner,
+		ProducerNum: config.ProducerNum,
+	}
+	if config.MyPk == nil {
+		return status
+	}
+	addr := crypto.PubkeyToAddress(config.MyPk)
+	status.Address = &addr
+
+	height := bftConsensusService.ChainService.BestChain().Height()
+	producers, err := bftConsensusService.GetProducers(height, config.ProducerNum)
+	if err != nil {
+		log.WithField("err", err).WithField("height", height).Debug("status get producers")
+		return status
+	}
+	for _, producer := range producers {
+		if producer.Pubkey.IsEqual(config.MyPk) {
+			status.Producer = true
+			break
+		}
+	}
+	return status
+}
+
+// ProducerStat is the blocks of a producer left out of the best chain
+type ProducerStat struct {
+	Addr crypto.CommonAddress `json:"addr"`
+	chain.StaleStat
+}
+
+// producerStats return the stale blocks of the producers elected at the current height
+func (bftConsensusService *BftConsensusService) producerStats() ([]*ProducerStat, error) {
+	height := bftConsensusService.ChainService.BestChain().Height()
+	producers, err := bftConsensusService.GetProducers(height, bftConsensusService.Config.ProducerNum)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*ProducerStat, 0, len(producers))
+	for _, producer := range producers {
+		addr := producer.Address()
+		stats = append(stats, &ProducerStat{Addr: addr, StaleStat: *bftConsensusService.ChainService.StaleStat(addr)})
+	}
+	return stats, nil
+}
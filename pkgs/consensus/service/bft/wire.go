@@ -18,6 +18,9 @@ const (
 	MsgTypeFail        = 4
 	MsgTypeValidateReq = 5
 	MsgTypeValidateRes = 6
+	//MsgTypeRelay and MsgTypeRelayAnnounce carry consensus messages of producers behind sentries
+	MsgTypeRelay         = 7
+	MsgTypeRelayAnnounce = 8
 
 	MaxMsgSize = 20 << 20
 
@@ -30,7 +33,7 @@ const (
 	//validateResMagic = 0xfefefbf8
 )
 
-var NumberOfMsg = 7
+var NumberOfMsg = 9
 
 type MsgWrap struct {
 	Peer types.IPeerInfo
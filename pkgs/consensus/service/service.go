@@ -1,7 +1,10 @@
 package service
 
 import (
+	"fmt"
+
 	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/bft"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/solo"
 	"gopkg.in/urfave/cli.v1"
@@ -20,9 +23,10 @@ type ConsensusConfig struct {
 	Bft           *bft.BftConfig   `json:"bft,omitempty"`
 }
 type ConsensusService struct {
-	SoloService *solo.SoloConsensusService
-	BftService  *bft.BftConsensusService
-	Config      *ConsensusConfig
+	ChainService chain.ChainServiceInterface `service:"chain"`
+	SoloService  *solo.SoloConsensusService
+	BftService   *bft.BftConsensusService
+	Config       *ConsensusConfig
 }
 
 func (consensusService *ConsensusService) Name() string {
@@ -40,6 +44,15 @@ func (consensusService *ConsensusService) CommandFlags() ([]cli.Command, []cli.F
 func (consensusService *ConsensusService) Init(executeContext *app.ExecuteContext) error {
 	consensusService.SoloService = &solo.SoloConsensusService{}
 	consensusService.BftService = &bft.BftConsensusService{}
+
+	//the consensus of network is fixed in genesis if specified
+	if genesisConsensus := consensusService.ChainService.GenesisParams().Consensus; genesisConsensus != "" {
+		if consensusService.Config.ConsensusMode == "" {
+			consensusService.Config.ConsensusMode = genesisConsensus
+		} else if consensusService.Config.ConsensusMode != genesisConsensus {
+			return fmt.Errorf("consensus mode %s not matched with genesis %s", consensusService.Config.ConsensusMode, genesisConsensus)
+		}
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"sync"
 	"time"
 
 	"github.com/drep-project/DREP-Chain/app"
@@ -13,6 +14,7 @@ import (
 	chainService "github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/network/p2p/enr"
 	p2pService "github.com/drep-project/DREP-Chain/network/service"
 	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
 	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
@@ -49,6 +51,8 @@ type SoloConsensusService struct {
 	pauseForSync bool
 	start        bool
 	quit         chan struct{}
+	quitOnce     sync.Once
+	mining       sync.WaitGroup // the block production loop, waited for on shutdown
 }
 
 func (soloConsensusService *SoloConsensusService) Name() string {
@@ -94,6 +98,7 @@ func (soloConsensusService *SoloConsensusService) Init(executeContext *app.Execu
 	soloConsensusService.syncBlockEventChan = make(chan event.SyncBlockEvent)
 	soloConsensusService.syncBlockEventSub = soloConsensusService.BlockMgrNotifier.SubscribeSyncBlockEvent(soloConsensusService.syncBlockEventChan)
 	soloConsensusService.quit = make(chan struct{})
+	app.RegisterShutdown(soloConsensusService.Name(), app.ShutdownDrain, soloConsensusService.stopMining)
 	go soloConsensusService.handlerEvent()
 
 	return nil
@@ -121,7 +126,10 @@ func (soloConsensusService *SoloConsensusService) Start(executeContext *app.Exec
 		return nil
 	}
 	soloConsensusService.start = true
+	soloConsensusService.P2pServer.SetNodeRole(enr.RoleProducer)
+	soloConsensusService.mining.Add(1)
 	go func() {
+		defer soloConsensusService.mining.Done()
 		select {
 		case <-soloConsensusService.quit:
 			return
@@ -157,7 +165,11 @@ func (soloConsensusService *SoloConsensusService) Start(executeContext *app.Exec
 				}
 				nextBlockTime, waitSpan := soloConsensusService.getWaitTime()
 				log.WithField("nextBlockTime", nextBlockTime).WithField("waitSpan", waitSpan).Debug("Sleep")
-				time.Sleep(waitSpan)
+				select {
+				case <-time.After(waitSpan):
+				case <-soloConsensusService.quit:
+					return
+				}
 			}
 		}
 	}()
@@ -165,13 +177,21 @@ func (soloConsensusService *SoloConsensusService) Start(executeContext *app.Exec
 	return nil
 }
 
+// stopMining stop producing blocks once the block in production is processed, so the shutdown
+// never cuts a block off halfway
+func (soloConsensusService *SoloConsensusService) stopMining() error {
+	soloConsensusService.quitOnce.Do(func() { close(soloConsensusService.quit) })
+	soloConsensusService.mining.Wait()
+	return nil
+}
+
 func (soloConsensusService *SoloConsensusService) Stop(executeContext *app.ExecuteContext) error {
 	if soloConsensusService.Config == nil || !soloConsensusService.Config.StartMiner {
 		return nil
 	}
 
 	if soloConsensusService.quit != nil {
-		close(soloConsensusService.quit)
+		soloConsensusService.quitOnce.Do(func() { close(soloConsensusService.quit) })
 	}
 
 	if soloConsensusService.syncBlockEventSub != nil {
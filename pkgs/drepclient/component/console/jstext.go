@@ -1,8 +1,19 @@
 package console
 
+// Modules are console extensions of the rpc namespaces drep.js does not cover,
+// each is loaded when the node reports the namespace in rpc_modules
 var Modules = map[string]string{
 	//"account":   Personal_JS,
-	"rpc": RPC_JS,
+	"rpc":        RPC_JS,
+	"admin":      Admin_JS,
+	"p2p":        P2P_JS,
+	"chain":      Chain_JS,
+	"consensus":  Consensus_JS,
+	"stake":      Stake_JS,
+	"journal":    Journal_JS,
+	"token":      Token_JS,
+	"reputation": Reputation_JS,
+	"unit":       Unit_JS,
 }
 
 const RPC_JS = `
@@ -17,3 +28,321 @@ drep._extend({
 	]
 });
 `
+
+const Admin_JS = `
+drep._extend({
+	property: 'admin',
+	methods: [
+		new drep._extend.Method({
+			name: 'nodeAddress',
+			call: 'admin_nodeAddress',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'natStatus',
+			call: 'admin_natStatus',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'natRemap',
+			call: 'admin_natRemap',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'allowlist',
+			call: 'admin_allowlist',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'reloadAllowlist',
+			call: 'admin_reloadAllowlist',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'setLogLevel',
+			call: 'admin_setLogLevel',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new drep._extend.Method({
+			name: 'logLevels',
+			call: 'admin_logLevels',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'cacheUsage',
+			call: 'admin_cacheUsage',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'setMaintenance',
+			call: 'admin_setMaintenance',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getMaintenance',
+			call: 'admin_getMaintenance',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'reloadRpc',
+			call: 'admin_reloadRpc',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'status',
+			call: 'admin_status',
+			params: 0
+		}),
+	]
+});
+`
+
+const P2P_JS = `
+drep._extend({
+	property: 'p2p',
+	methods: [
+		new drep._extend.Method({
+			name: 'addPeer',
+			call: 'p2p_addPeer',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'removePeer',
+			call: 'p2p_removePeer',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getPeers',
+			call: 'p2p_getPeers',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'localNode',
+			call: 'p2p_localNode',
+			params: 0
+		}),
+	]
+});
+`
+
+const Chain_JS = `
+drep._extend({
+	property: 'chain',
+	methods: [
+		new drep._extend.Method({
+			name: 'getChainId',
+			call: 'chain_getChainId',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'getBalanceAmount',
+			call: 'chain_getBalanceAmount',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getReceipt',
+			call: 'chain_getReceipt',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getLogs',
+			call: 'chain_getLogs',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getTransactionProof',
+			call: 'chain_getTransactionProof',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getReorgHistory',
+			call: 'chain_getReorgHistory',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'exportChain',
+			call: 'chain_exportChain',
+			params: 4,
+			inputFormatter: [null, null, null, null]
+		}),
+		new drep._extend.Method({
+			name: 'importChain',
+			call: 'chain_importChain',
+			params: 2
+		}),
+	]
+});
+`
+
+const Consensus_JS = `
+drep._extend({
+	property: 'consensus',
+	methods: [
+		new drep._extend.Method({
+			name: 'getMiners',
+			call: 'consensus_getMiners',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'changeWaitTime',
+			call: 'consensus_changeWaitTime',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'producerStats',
+			call: 'consensus_producerStats',
+			params: 0
+		}),
+	]
+});
+`
+
+const Stake_JS = `
+drep._extend({
+	property: 'stake',
+	methods: [
+		new drep._extend.Method({
+			name: 'registerCandidateAuto',
+			call: 'stake_registerCandidateAuto',
+			params: 3,
+			inputFormatter: [drep._extend.utils.fromDecimal, drep._extend.utils.fromDecimal, drep._extend.utils.fromDecimal]
+		}),
+		new drep._extend.Method({
+			name: 'getEpochRewards',
+			call: 'stake_getEpochRewards',
+			params: 3
+		}),
+		new drep._extend.Method({
+			name: 'getAddressRewards',
+			call: 'stake_getAddressRewards',
+			params: 3,
+			inputFormatter: [drep._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new drep._extend.Method({
+			name: 'getRewardCheckpoint',
+			call: 'stake_getRewardCheckpoint',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new drep._extend.Method({
+			name: 'estimateRewards',
+			call: 'stake_estimateRewards',
+			params: 3,
+			inputFormatter: [drep._extend.formatters.inputAddressFormatter, drep._extend.utils.fromDecimal, null]
+		}),
+		new drep._extend.Method({
+			name: 'getFeeStats',
+			call: 'stake_getFeeStats',
+			params: 2
+		}),
+	]
+});
+`
+
+const Journal_JS = `
+drep._extend({
+	property: 'journal',
+	methods: [
+		new drep._extend.Method({
+			name: 'headCursor',
+			call: 'journal_headCursor',
+			params: 0
+		}),
+		new drep._extend.Method({
+			name: 'getEvents',
+			call: 'journal_getEvents',
+			params: 2
+		}),
+	]
+});
+`
+
+const Token_JS = `
+drep._extend({
+	property: 'token',
+	methods: [
+		new drep._extend.Method({
+			name: 'issue',
+			call: 'token_issue',
+			params: 8
+		}),
+		new drep._extend.Method({
+			name: 'mint',
+			call: 'token_mint',
+			params: 6
+		}),
+		new drep._extend.Method({
+			name: 'burn',
+			call: 'token_burn',
+			params: 5
+		}),
+		new drep._extend.Method({
+			name: 'transfer',
+			call: 'token_transfer',
+			params: 6
+		}),
+		new drep._extend.Method({
+			name: 'balanceOf',
+			call: 'token_balanceOf',
+			params: 2
+		}),
+		new drep._extend.Method({
+			name: 'getToken',
+			call: 'token_getToken',
+			params: 1
+		}),
+	]
+});
+`
+
+const Reputation_JS = `
+drep._extend({
+	property: 'reputation',
+	methods: [
+		new drep._extend.Method({
+			name: 'attest',
+			call: 'reputation_attest',
+			params: 7
+		}),
+		new drep._extend.Method({
+			name: 'setDecayRule',
+			call: 'reputation_setDecayRule',
+			params: 5
+		}),
+		new drep._extend.Method({
+			name: 'get',
+			call: 'reputation_get',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'history',
+			call: 'reputation_history',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'getDecayRules',
+			call: 'reputation_getDecayRules',
+			params: 0
+		}),
+	]
+});
+`
+
+const Unit_JS = `
+drep._extend({
+	property: 'unit',
+	methods: [
+		new drep._extend.Method({
+			name: 'parse',
+			call: 'unit_parse',
+			params: 1
+		}),
+		new drep._extend.Method({
+			name: 'format',
+			call: 'unit_format',
+			params: 1
+		}),
+	]
+});
+`
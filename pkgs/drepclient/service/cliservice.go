@@ -3,10 +3,13 @@ package service
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/AsynkronIT/protoactor-go/actor"
 	"github.com/drep-project/DREP-Chain/app"
 	blockmgr "github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/common"
 	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
 	"github.com/drep-project/DREP-Chain/pkgs/drepclient/component/console"
 	cliTypes "github.com/drep-project/DREP-Chain/pkgs/drepclient/types"
@@ -62,13 +65,15 @@ See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console.`,
 		Name:      "attach",
 		Usage:     "Start an interactive JavaScript environment (connect to node)",
 		ArgsUsage: "[endpoint]",
-		Flags:     []cli.Flag{},
+		Flags:     []cli.Flag{rpc2.IPCPathFlag},
 		Category:  "CONSOLE COMMANDS",
+		Action:    remoteConsole,
 		Description: `
 The Drep console is an interactive shell for the JavaScript runtime environment
 which exposes a node admin interface as well as the Ðapp JavaScript API.
 See https://github.com/ethereum/go-ethereum/wiki/JavaScript-Console.
-This command allows to open a console on a running drep node.`,
+This command allows to open a console on a running drep node. Without an
+endpoint it attaches to the IPC socket in the home directory of the node.`,
 	}
 	return []cli.Command{consoleCommand, attachCommand}, defaultFlags
 }
@@ -85,8 +90,6 @@ func (cliService *CliService) Init(executeContext *app.ExecuteContext) error {
 func (cliService *CliService) Start(executeContext *app.ExecuteContext) error {
 	if executeContext.Cli.Command.Name == "console" {
 		return cliService.localConsole(executeContext)
-	} else if executeContext.Cli.Command.Name == "attach" {
-		return cliService.remoteConsole(executeContext)
 	} else {
 		return cliService.drep(executeContext)
 	}
@@ -134,45 +137,60 @@ func (cliService *CliService) localConsole(executeContext *app.ExecuteContext) e
 	return nil
 }
 
-// remoteConsole will connect to a remote drep instance, attaching a JavaScript
-// console to it.
-func (cliService *CliService) remoteConsole(executeContext *app.ExecuteContext) error {
-	endpoint := executeContext.Cli.Args().First()
+// remoteConsole will connect to a running drep instance, attaching a JavaScript
+// console to it. It runs standalone, the services of the node are not started.
+func remoteConsole(ctx *cli.Context) error {
+	homeDir := common.AppDataDir(ctx.App.Name, false)
+	if ctx.GlobalIsSet(app.HomeDirFlag.Name) {
+		homeDir = ctx.GlobalString(app.HomeDirFlag.Name)
+	}
+	endpoint := ctx.Args().First()
 	if len(endpoint) == 0 {
-		return fmt.Errorf("You have to specify an address")
+		endpoint = defaultIPCEndpoint(ctx, homeDir)
 	}
 	client, err := rpc.Dial(endpoint)
 	if err != nil {
 		return fmt.Errorf("Unable to attach to remote drep: %v", err)
 	}
 
-	path := executeContext.CommonConfig.HomeDir
-	cliService.Config = &cliTypes.Config{}
-	cliService.Config.Config = console.Config{
-		HomeDir: path,
-		DocRoot: executeContext.Cli.GlobalString(cliTypes.JSpathFlag.Name),
+	config := console.Config{
+		HomeDir: homeDir,
+		DocRoot: ctx.GlobalString(cliTypes.JSpathFlag.Name),
 		Client:  client,
-		Preload: cliTypes.MakeConsolePreloads(executeContext.Cli),
+		Preload: cliTypes.MakeConsolePreloads(ctx),
 	}
-
-	cliService.console, err = console.New(cliService.Config.Config)
+	remote, err := console.New(config)
 	if err != nil {
 		return fmt.Errorf("Failed to start the JavaScript console: %v", err)
 	}
-	defer cliService.console.Stop(false)
+	defer remote.Stop(false)
 
-	if script := executeContext.Cli.GlobalString(cliTypes.ExecFlag.Name); script != "" {
-		cliService.console.Evaluate(script)
+	if script := ctx.GlobalString(cliTypes.ExecFlag.Name); script != "" {
+		remote.Evaluate(script)
 		return nil
 	}
 
 	// Otherwise print the welcome screen and enter interactive mode
-	cliService.console.Welcome()
-	cliService.console.Interactive(executeContext.Quit)
-
+	remote.Welcome()
+	remote.Interactive(make(chan struct{}))
 	return nil
 }
 
+// defaultIPCEndpoint return the IPC socket the node opens by default, an explicit
+// ipcpath is taken as is when absolute and relative to the home dir otherwise
+func defaultIPCEndpoint(ctx *cli.Context, homeDir string) string {
+	ipcPath := rpc2.DefaultIPCEndpoint(rpc2.ClientIdentifier)
+	if ctx.IsSet(rpc2.IPCPathFlag.Name) {
+		ipcPath = ctx.String(rpc2.IPCPathFlag.Name)
+	} else if ctx.GlobalIsSet(rpc2.IPCPathFlag.Name) {
+		ipcPath = ctx.GlobalString(rpc2.IPCPathFlag.Name)
+	}
+	if filepath.IsAbs(ipcPath) || strings.HasPrefix(ipcPath, `\\.\pipe\`) {
+		return ipcPath
+	}
+	return filepath.Join(homeDir, ipcPath)
+}
+
 // drep is the main entry point into the system if no special subcommand is ran.
 // It creates a default node based on the command line arguments and runs it in
 // blocking mode, waiting for it to be shut down.
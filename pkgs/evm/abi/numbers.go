@@ -1,7 +1,7 @@
 package abi
 
 import (
-	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/math"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"math/big"
 	"reflect"
@@ -23,5 +23,5 @@ var (
 
 // U256 converts a big Int into a 256bit EVM number.
 func U256(n *big.Int) []byte {
-	return common.PaddedBigBytes(common.U256(n), 32)
+	return math.PaddedBigBytes(math.U256(n), 32)
 }
@@ -2,6 +2,7 @@ package abi
 
 import (
 	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/common/math"
 	"math/big"
 	"reflect"
 )
@@ -29,9 +30,9 @@ func packElement(t Type, reflectValue reflect.Value) []byte {
 		return common.LeftPadBytes(reflectValue.Bytes(), 32)
 	case BoolTy:
 		if reflectValue.Bool() {
-			return common.PaddedBigBytes(common.Big1, 32)
+			return math.PaddedBigBytes(common.Big1, 32)
 		}
-		return common.PaddedBigBytes(common.Big0, 32)
+		return math.PaddedBigBytes(common.Big0, 32)
 	case BytesTy:
 		if reflectValue.Kind() == reflect.Array {
 			reflectValue = mustArrayToByteSlice(reflectValue)
@@ -189,7 +189,7 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 	case BoolTy:
 		return readBool(returnOutput)
 	case AddressTy:
-		return crypto.Bytes2Address(returnOutput), nil
+		return crypto.BytesToAddress(returnOutput), nil
 	case HashTy:
 		return crypto.Bytes2Hash(returnOutput), nil
 	case BytesTy:
@@ -12,6 +12,7 @@ import (
 	"github.com/drep-project/dlog"
 	"gopkg.in/urfave/cli.v1"
 	"math/big"
+	"time"
 )
 
 var (
@@ -30,6 +31,8 @@ type EvmService struct {
 	Config          *vm.VMConfig
 	Chain           chain.ChainServiceInterface `service:"chain"`
 	DatabaseService *database.DatabaseService   `service:"database"`
+
+	callTracer vm.CallTracer
 }
 
 func (evmService *EvmService) Name() string {
@@ -89,7 +92,19 @@ func (evmService *EvmService) Call(database store.StoreInterface, tx *types.Tran
 	return ret, nil
 }
 
+// SetCallTracer set the tracer told about the calls of the transactions executed in blocks,
+// calls and gas estimations of the rpc are not traced
+func (evmService *EvmService) SetCallTracer(tracer vm.CallTracer) {
+	evmService.callTracer = tracer
+}
+
 func (evmService *EvmService) Eval(state vm.VMState, tx *types.Transaction, header *types.BlockHeader, gas uint64, value *big.Int) (ret []byte, gasUsed uint64, contractAddr crypto.CommonAddress, failed bool, err error) {
+	return evmService.eval(state, tx, header, gas, value, nil, time.Time{})
+}
+
+// eval execute tx in the evm, it is cancelled at deadline unless deadline is zero and
+// chain.ErrTxTimeout returned, the state written by then must be reverted by the caller
+func (evmService *EvmService) eval(state vm.VMState, tx *types.Transaction, header *types.BlockHeader, gas uint64, value *big.Int, tracer vm.CallTracer, deadline time.Time) (ret []byte, gasUsed uint64, contractAddr crypto.CommonAddress, failed bool, err error) {
 	sender, err := tx.From()
 	if err != nil {
 		return nil, uint64(0), crypto.CommonAddress{}, false, err
@@ -98,9 +113,18 @@ func (evmService *EvmService) Eval(state vm.VMState, tx *types.Transaction, head
 
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(tx, header, sender)
+	context.CallTracer = tracer
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, state, evmService.Config)
+	if !deadline.IsZero() {
+		timer := time.AfterFunc(time.Until(deadline), vmenv.Cancel)
+		defer func() {
+			if !timer.Stop() {
+				ret, gasUsed, contractAddr, failed, err = nil, 0, crypto.CommonAddress{}, false, chain.ErrTxTimeout
+			}
+		}()
+	}
 	var (
 		// vm errors do not effect consensus and are therefor
 		// not assigned to err, except for insufficient balance
@@ -28,13 +28,15 @@ type EvmDeployTransactionExecutor struct {
 func (vmDeployTransactionExecutor *EvmDeployTransactionExecutor) ExecuteTransaction(context *chain.ExecuteTransactionContext) *types.ExecuteTransactionResult {
 	state := vm.NewState(context.TrieStore(), context.Header().Height)
 
-	ret, gas, addr, failed, err := vmDeployTransactionExecutor.vm.Eval(
+	ret, gas, addr, failed, err := vmDeployTransactionExecutor.vm.eval(
 		state,
 		context.Tx(),
 		context.Header(),
 		//vmDeployTransactionExecutor.vm.Chain,
 		context.GasRemained(),
-		context.Value())
+		context.Value(),
+		vmDeployTransactionExecutor.vm.callTracer,
+		context.Deadline())
 	context.UseGas(gas)
 
 	refund := context.GasUsed() / 2
@@ -1,7 +1,6 @@
 package vm
 
 import (
-	"fmt"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"math/big"
 )
@@ -22,7 +21,6 @@ func (d destinations) has(codehash crypto.Hash, code []byte, dest *big.Int) bool
 	// Don't bother checking for JUMPDEST in that case.
 	udest := dest.Uint64()
 	if dest.BitLen() >= 63 || udest >= uint64(len(code)) {
-		fmt.Println("false here1")
 		return false
 	}
 
@@ -31,12 +29,6 @@ func (d destinations) has(codehash crypto.Hash, code []byte, dest *big.Int) bool
 		m = codeBitmap(code)
 		d[codehash] = m
 	}
-	if OpCode(code[udest]) != JUMPDEST {
-		fmt.Println("false here2")
-	}
-	if !m.codeSegment(udest) {
-		fmt.Println("false here3")
-	}
 	//fmt.Println("rrrrrruuuuuuunnnnnn here")
 	return OpCode(code[udest]) == JUMPDEST && m.codeSegment(udest)
 }
@@ -0,0 +1,40 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// Kinds of call reported to a CallTracer
+const (
+	CallTypeCall         = "call"
+	CallTypeCallCode     = "callcode"
+	CallTypeDelegateCall = "delegatecall"
+	CallTypeStaticCall   = "staticcall"
+	CallTypeCreate       = "create"
+	CallTypeSuicide      = "suicide"
+)
+
+// CallTracer is told about every call and value transfer made while a transaction runs.
+// Depth 0 is the call of the transaction itself, each CaptureEnter is followed by the
+// CaptureExit of the same depth once the call and all calls it made are done
+type CallTracer interface {
+	CaptureEnter(txHash *crypto.Hash, typ string, from, to crypto.CommonAddress, value *big.Int, depth int)
+	CaptureExit(txHash *crypto.Hash, depth int, err error)
+}
+
+// traceCall report a call to the tracer of the context, the returned func reports its end
+func (evm *EVM) traceCall(typ string, from, to crypto.CommonAddress, value *big.Int) func(err error) {
+	if evm.CallTracer == nil {
+		return func(error) {}
+	}
+	depth := evm.depth
+	if value == nil {
+		value = new(big.Int)
+	}
+	evm.CallTracer.CaptureEnter(evm.TxHash, typ, from, to, new(big.Int).Set(value), depth)
+	return func(err error) {
+		evm.CallTracer.CaptureExit(evm.TxHash, depth, err)
+	}
+}
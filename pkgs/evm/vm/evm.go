@@ -90,6 +90,9 @@ type Context struct {
 	BlockNumber *big.Int // Provides information for NUMBER
 	Time        *big.Int // Provides information for TIME
 	TxHash      *crypto.Hash
+
+	// CallTracer records the calls of the transaction, nil when not traced
+	CallTracer CallTracer
 }
 
 // EVM is the Ethereum Virtual Machine base object and provides
@@ -159,6 +162,8 @@ func (evm *EVM) Call(caller crypto.CommonAddress, addr crypto.CommonAddress, cha
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	exit := evm.traceCall(CallTypeCall, caller, addr, value)
+	defer func() { exit(err) }()
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -228,6 +233,8 @@ func (evm *EVM) CallCode(caller crypto.CommonAddress, addr crypto.CommonAddress,
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	exit := evm.traceCall(CallTypeCallCode, caller, addr, value)
+	defer func() { exit(err) }()
 
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
@@ -257,6 +264,8 @@ func (evm *EVM) DelegateCall(con *Contract, contractAddr crypto.CommonAddress, i
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	exit := evm.traceCall(CallTypeDelegateCall, con.ContractAddr, contractAddr, nil)
+	defer func() { exit(err) }()
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -295,6 +304,8 @@ func (evm *EVM) StaticCall(caller crypto.CommonAddress, addr crypto.CommonAddres
 	if evm.vmConfig.NoRecursion && evm.depth > 0 {
 		return nil, gas, nil
 	}
+	exit := evm.traceCall(CallTypeStaticCall, caller, addr, nil)
+	defer func() { exit(err) }()
 	// Fail if we're trying to execute above the call depth limit
 	if evm.depth > int(params.CallCreateDepth) {
 		return nil, gas, ErrDepth
@@ -414,6 +425,8 @@ func (evm *EVM) CreateContractCode(caller crypto.CommonAddress, codeAndHash *cod
 func (evm *EVM) Create(caller crypto.CommonAddress, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr crypto.CommonAddress, leftOverGas uint64, err error) {
 	contractAddr = crypto.CreateAddress(caller, evm.State.GetNonce(&caller))
 
+	exit := evm.traceCall(CallTypeCreate, caller, contractAddr, value)
+	defer func() { exit(err) }()
 	return evm.CreateContractCode(caller, &codeAndHash{code: code}, gas, value, contractAddr)
 }
 
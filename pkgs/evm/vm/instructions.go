@@ -928,6 +928,7 @@ func opSuicide(pc *uint64, interpreter *EVMInterpreter, contract *Contract, memo
 	addr := crypto.BigToAddress(stack.pop())
 
 	state.AddBalance(&addr, balance)
+	interpreter.EVM.traceCall(CallTypeSuicide, contract.ContractAddr, addr, balance)(nil)
 	if !state.HasSuicided(contract.ContractAddr) {
 		state.Suicide(&contract.ContractAddr)
 	}
@@ -2,6 +2,7 @@ package filter
 
 import (
 	"context"
+	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
 )
 
@@ -35,6 +36,26 @@ func (filter *FilterApi) NewPendingTransactionFilter() ID {
 	return filter.filterService.NewPendingTransactionFilter()
 }
 
+/*
+ name: newDroppedTransactionFilter
+ usage: Creates a filter in the node, to notify when transactions are dropped from the transaction pool before being packed, e.g. evicted when their sender queued too many future nonce transactions. Dropped transactions have to be sent again. To check if the state has changed, call filter_getFilterChanges.
+ params:
+	1. Array of DATA, 20 Bytes - (optional) Sender addresses of the transactions, transactions of all senders when empty.
+ return:
+	QUANTITY - A filter id.
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"filter_newDroppedTransactionFilter","params":[["0xec61c03f719a5c214f60719c3f36bb362a202125"]], "id": 3}' -H "Content-Type:application/json"
+ response:
+{
+  "jsonrpc": "2.0",
+  "id": 3,
+  "result": "0x1"
+  }
+}
+*/
+func (filter *FilterApi) NewDroppedTransactionFilter(senders []crypto.CommonAddress) ID {
+	return filter.filterService.NewDroppedTransactionFilter(senders)
+}
+
 /*
  name: newBlockFilter
  usage: Creates a filter in the node, to notify when a new block arrives. To check if the state has changed, call filter_getFilterChanges.
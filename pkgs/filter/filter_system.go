@@ -34,6 +34,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// DroppedTransactionsSubscription queries tx hashes for transactions
+	// dropped from the transaction pool before being packed
+	DroppedTransactionsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -212,6 +215,7 @@ type EventSystem struct {
 
 	// Subscriptions
 	txsSub        event.Subscription         // Subscription for new transaction event
+	droppedTxsSub event.Subscription         // Subscription for dropped transaction event
 	logsSub       event.Subscription         // Subscription for new log event
 	rmLogsSub     event.Subscription         // Subscription for removed log event
 	chainSub      event.Subscription         // Subscription for new chain event
@@ -221,6 +225,7 @@ type EventSystem struct {
 	install   chan *subscription          // install filter for event notification
 	uninstall chan *subscription          // remove filter for event notification
 	txsCh     chan types.NewTxsEvent      // Channel to receive new transactions event
+	droppedCh chan types.DroppedTxsEvent  // Channel to receive dropped transactions event
 	logsCh    chan []*types.Log           // Channel to receive new log event
 	rmLogsCh  chan types.RemovedLogsEvent // Channel to receive removed log event
 	chainCh   chan *types.ChainEvent      // Channel to receive new chain event
@@ -240,6 +245,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 		install:   make(chan *subscription),
 		uninstall: make(chan *subscription),
 		txsCh:     make(chan types.NewTxsEvent, txChanSize),
+		droppedCh: make(chan types.DroppedTxsEvent, txChanSize),
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan types.RemovedLogsEvent, rmLogsChanSize),
 		chainCh:   make(chan *types.ChainEvent, chainEvChanSize),
@@ -247,6 +253,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 
 	// Subscribe events
 	m.txsSub = m.backend.SubscribeNewTxsEvent(m.txsCh)
+	m.droppedTxsSub = m.backend.SubscribeDroppedTxsEvent(m.droppedCh)
 	m.logsSub = m.backend.SubscribeLogsEvent(m.logsCh)
 	m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
@@ -254,7 +261,7 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 	m.pendingLogSub = m.mux.Subscribe(types.PendingLogsEvent{})
 
 	// Make sure none of the subscriptions are empty
-	if m.txsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
+	if m.txsSub == nil || m.droppedTxsSub == nil || m.logsSub == nil || m.rmLogsSub == nil || m.chainSub == nil ||
 		m.pendingLogSub.Closed() {
 		log.Fatal("Subscribe for event system failed")
 	}
@@ -430,6 +437,24 @@ func (es *EventSystem) SubscribePendingTxs(hashes chan []crypto.Hash) *Subscript
 	return es.subscribe(sub)
 }
 
+// SubscribeDroppedTxs creates a subscription that writes transaction hashes for
+// transactions dropped from the transaction pool, only the transactions of senders
+// are written unless senders is empty.
+func (es *EventSystem) SubscribeDroppedTxs(senders []crypto.CommonAddress, hashes chan []crypto.Hash) *Subscription {
+	sub := &subscription{
+		id:        NewID(),
+		typ:       DroppedTransactionsSubscription,
+		created:   time.Now(),
+		logsCrit:  FilterQuery{Addresses: senders},
+		logs:      make(chan []*types.Log),
+		hashes:    hashes,
+		headers:   make(chan *types.BlockHeader),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -470,6 +495,12 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[PendingTransactionsSubscription] {
 			f.hashes <- hashes
 		}
+	case types.DroppedTxsEvent:
+		for _, f := range filters[DroppedTransactionsSubscription] {
+			if hashes := filterSenders(e.Txs, f.logsCrit.Addresses); len(hashes) > 0 {
+				f.hashes <- hashes
+			}
+		}
 	case *types.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
 			f.headers <- e.Block.Header
@@ -486,6 +517,21 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 	}
 }
 
+// filterSenders return the hashes of the transactions sent by senders, all of them if senders is empty
+func filterSenders(txs []*types.Transaction, senders []crypto.CommonAddress) []crypto.Hash {
+	hashes := make([]crypto.Hash, 0, len(txs))
+	for _, tx := range txs {
+		if len(senders) > 0 {
+			from, err := tx.From()
+			if err != nil || !includes(senders, *from) {
+				continue
+			}
+		}
+		hashes = append(hashes, *tx.TxHash())
+	}
+	return hashes
+}
+
 func (es *EventSystem) lightFilterNewHead(newHeader *types.BlockHeader, callBack func(*types.BlockHeader, bool)) {
 	oldh := es.lastHead
 	es.lastHead = newHeader
@@ -565,6 +611,7 @@ func (es *EventSystem) eventLoop() {
 	defer func() {
 		es.pendingLogSub.Unsubscribe()
 		es.txsSub.Unsubscribe()
+		es.droppedTxsSub.Unsubscribe()
 		es.logsSub.Unsubscribe()
 		es.rmLogsSub.Unsubscribe()
 		es.chainSub.Unsubscribe()
@@ -580,6 +627,8 @@ func (es *EventSystem) eventLoop() {
 		// Handle subscribed events
 		case ev := <-es.txsCh:
 			es.broadcast(index, ev)
+		case ev := <-es.droppedCh:
+			es.broadcast(index, ev)
 		case ev := <-es.logsCh:
 			es.broadcast(index, ev)
 		case ev := <-es.rmLogsCh:
@@ -615,6 +664,8 @@ func (es *EventSystem) eventLoop() {
 			// System stopped
 		case <-es.txsSub.Err():
 			return
+		case <-es.droppedTxsSub.Err():
+			return
 		case <-es.logsSub.Err():
 			return
 		case <-es.rmLogsSub.Err():
@@ -56,6 +56,7 @@ type Backend interface {
 	GetLogsByHash(ctx context.Context, blockHash crypto.Hash) ([][]*types.Log, error)
 
 	SubscribeNewTxsEvent(chan<- types.NewTxsEvent) event.Subscription
+	SubscribeDroppedTxsEvent(chan<- types.DroppedTxsEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- *types.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- types.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
@@ -243,6 +244,41 @@ func (service *FilterService) NewPendingTransactionFilter() ID {
 	return pendingTxSub.ID
 }
 
+// NewDroppedTransactionFilter creates a filter that fetches hashes of transactions dropped
+// from the transaction pool before being packed, e.g. evicted over the future nonce limit
+// of their sender, so wallets resend them instead of waiting forever. Only transactions of
+// senders are fetched unless senders is empty.
+func (service *FilterService) NewDroppedTransactionFilter(senders []crypto.CommonAddress) ID {
+	var (
+		droppedTxs   = make(chan []crypto.Hash)
+		droppedTxSub = service.events.SubscribeDroppedTxs(senders, droppedTxs)
+	)
+
+	service.filtersMu.Lock()
+	service.filters[droppedTxSub.ID] = &filter{typ: DroppedTransactionsSubscription, deadline: time.NewTimer(deadline), hashes: make([]crypto.Hash, 0), s: droppedTxSub}
+	service.filtersMu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case dh := <-droppedTxs:
+				service.filtersMu.Lock()
+				if f, found := service.filters[droppedTxSub.ID]; found {
+					f.hashes = append(f.hashes, dh...)
+				}
+				service.filtersMu.Unlock()
+			case <-droppedTxSub.Err():
+				service.filtersMu.Lock()
+				delete(service.filters, droppedTxSub.ID)
+				service.filtersMu.Unlock()
+				return
+			}
+		}
+	}()
+
+	return droppedTxSub.ID
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with eth_getFilterChanges.
 //
@@ -428,7 +464,7 @@ func (service *FilterService) GetFilterChanges(id ID) (interface{}, error) {
 		f.deadline.Reset(deadline)
 
 		switch f.typ {
-		case PendingTransactionsSubscription, BlocksSubscription:
+		case PendingTransactionsSubscription, BlocksSubscription, DroppedTransactionsSubscription:
 			hashes := f.hashes
 			f.hashes = nil
 			return returnHashes(hashes), nil
@@ -495,6 +531,10 @@ func (service *FilterService) SubscribeNewTxsEvent(ch chan<- types.NewTxsEvent)
 	return service.Notifier.NewTxFeed().Subscribe(ch)
 }
 
+func (service *FilterService) SubscribeDroppedTxsEvent(ch chan<- types.DroppedTxsEvent) event.Subscription {
+	return service.Notifier.DroppedTxFeed().Subscribe(ch)
+}
+
 func (service *FilterService) SubscribeChainEvent(ch chan<- *types.ChainEvent) event.Subscription {
 	return service.ChainService.NewBlockFeed().Subscribe(ch)
 }
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"context"
+
+	"github.com/drep-project/rpc"
+)
+
+const maxEventsPerQuery = 1000
+
+/*
+name: Event journal
+usage: Query and subscribe the persistent events of blocks, reorganizes and transactions, subscribers resume from the last cursor after restart
+prefix:journal
+*/
+type JournalApi struct {
+	journal *Journal
+}
+
+/*
+ name: headCursor
+ usage: Get the cursor of the latest event
+ params:
+ return: cursor
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"journal_headCursor","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":10086}
+*/
+func (api *JournalApi) HeadCursor() uint64 {
+	return api.journal.Head()
+}
+
+/*
+ name: getEvents
+ usage: Get events after the cursor, types are blockConnected, blockDetached, reorg, txPending, txIncluded and txReverted
+ params:
+	1. cursor, the events after it are returned, 0 to get from the oldest event kept
+	2. limit, at most 1000
+ return: events ordered by cursor, error if the events after cursor have been pruned
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"journal_getEvents","params":[10084, 2], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":[{"cursor":10085,"type":"blockConnected","time":1592365562,"height":1200,"hash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6","data":{"txCount":1,"stateRoot":"UpMnHA5WmmTxU4T4jFQvpt6bFwigN+fg1Jx0fSD91MA="}},{"cursor":10086,"type":"txIncluded","time":1592365562,"height":1200,"hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","data":{"blockHash":"0x1fbae528a8eed0f09201bfd2c7e52fef66f5f35619e9868cd6d02dabac60e4e6"}}]}
+*/
+func (api *JournalApi) GetEvents(cursor uint64, limit int) ([]*Event, error) {
+	if limit <= 0 || limit > maxEventsPerQuery {
+		limit = maxEventsPerQuery
+	}
+	return api.journal.Events(cursor, limit)
+}
+
+/*
+ name: events
+ usage: Subscribe events after the cursor over websocket, stored events are replayed first and then new events are pushed
+ params:
+	1. cursor
+ return: subscription id
+ example: wscat -c ws://localhost:10084 -x '{"jsonrpc":"2.0","method":"journal_subscribe","params":["events", 10084], "id": 3}'
+ response:
+	{"jsonrpc":"2.0","id":3,"result":"0xcd0c3e8af590364c09d0fa6a1210faf5"}
+	{"jsonrpc":"2.0","method":"journal_subscription","params":{"subscription":"0xcd0c3e8af590364c09d0fa6a1210faf5","result":{"cursor":10085,"type":"txPending","time":1592365562,"height":0,"hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040"}}}
+*/
+func (api *JournalApi) Events(ctx context.Context, cursor uint64) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if _, err := api.journal.Events(cursor, 0); err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		eventCh := make(chan *Event, replayBatch)
+		quit := make(chan struct{})
+		go func() {
+			if err := api.journal.Replay(cursor, eventCh, quit); err != nil {
+				log.WithField("cursor", cursor).WithField("err", err).Info("journal replay stopped")
+			}
+		}()
+		defer close(quit)
+
+		for {
+			select {
+			case event := <-eventCh:
+				notifier.Notify(rpcSub.ID, event)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
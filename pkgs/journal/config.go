@@ -0,0 +1,13 @@
+package journal
+
+type JournalConfig struct {
+	Enable    bool   `json:"enable"`
+	MaxEvents uint64 `json:"maxEvents"` //The oldest events are pruned when journal exceed it
+}
+
+var (
+	DefaultConfig = &JournalConfig{
+		Enable:    false,
+		MaxEvents: 1000000,
+	}
+)
@@ -0,0 +1,9 @@
+package journal
+
+import "errors"
+
+var (
+	ErrCursorExpired = errors.New("events after cursor have been pruned")
+	ErrCursorAhead   = errors.New("cursor ahead of journal head")
+	ErrJournalClosed = errors.New("journal closed")
+)
@@ -0,0 +1,12 @@
+package journal
+
+import (
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	EnableJournalFlag = cli.BoolFlag{
+		Name:  "enableJournal",
+		Usage: "enable persistent event journal",
+	}
+)
@@ -0,0 +1,13 @@
+package journal
+
+import (
+	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
+)
+
+const (
+	MODULENAME = "journal"
+)
+
+var (
+	log = dlog.EnsureLogger(MODULENAME)
+)
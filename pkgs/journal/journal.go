@@ -0,0 +1,198 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+)
+
+// types of journal event
+const (
+	EventBlockConnected = "blockConnected"
+	EventBlockDetached  = "blockDetached"
+	EventReorg          = "reorg"
+	EventTxPending      = "txPending"  //Transaction accepted by pool
+	EventTxIncluded     = "txIncluded" //Transaction included in a connected block
+	EventTxReverted     = "txReverted" //Block include the transaction is detached
+)
+
+const replayBatch = 256
+
+var (
+	journalEventPrefix = []byte("journalEvt") //cursor -> event
+	journalHeadKey     = []byte("journalHead")
+	journalTailKey     = []byte("journalTail")
+)
+
+// Event is a persistent event, cursors increase monotonically and are never reused
+type Event struct {
+	Cursor uint64          `json:"cursor"`
+	Type   string          `json:"type"`
+	Time   int64           `json:"time"`
+	Height uint64          `json:"height"`
+	Hash   crypto.Hash     `json:"hash"` //Block hash or transaction hash
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// Journal store events in database so that subscribers can resume from the last cursor they
+// processed after restart. Writer never wait for subscribers, subscribers read events from
+// database and are only woke up when new events appended.
+type Journal struct {
+	db        dbinterface.KeyValueStore
+	maxEvents uint64
+
+	lock    sync.RWMutex
+	head    uint64 //Cursor of the last event, 0 mean no event
+	tail    uint64 //Cursor of the first event kept
+	waiters map[chan struct{}]struct{}
+}
+
+func NewJournal(db dbinterface.KeyValueStore, maxEvents uint64) *Journal {
+	journal := &Journal{
+		db:        db,
+		maxEvents: maxEvents,
+		head:      getUint64(db, journalHeadKey),
+		tail:      getUint64(db, journalTailKey),
+		waiters:   make(map[chan struct{}]struct{}),
+	}
+	if journal.tail == 0 {
+		journal.tail = 1
+	}
+	return journal
+}
+
+// Head return cursor of the last event
+func (journal *Journal) Head() uint64 {
+	journal.lock.RLock()
+	defer journal.lock.RUnlock()
+	return journal.head
+}
+
+// Append assign cursors to events and write them with the oldest events pruned together
+func (journal *Journal) Append(events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	journal.lock.Lock()
+	head, tail := journal.head, journal.tail
+	batch := journal.db.NewBatch()
+	for _, event := range events {
+		head++
+		event.Cursor = head
+		buf, err := json.Marshal(event)
+		if err != nil {
+			journal.lock.Unlock()
+			return err
+		}
+		batch.Put(eventKey(head), buf)
+	}
+	for journal.maxEvents > 0 && head-tail+1 > journal.maxEvents {
+		batch.Delete(eventKey(tail))
+		tail++
+	}
+	batch.Put(journalHeadKey, uint64Bytes(head))
+	batch.Put(journalTailKey, uint64Bytes(tail))
+	if err := batch.Write(); err != nil {
+		journal.lock.Unlock()
+		return err
+	}
+	journal.head, journal.tail = head, tail
+	for waiter := range journal.waiters {
+		select {
+		case waiter <- struct{}{}:
+		default:
+		}
+	}
+	journal.lock.Unlock()
+	return nil
+}
+
+// Events return at most limit events after cursor
+func (journal *Journal) Events(after uint64, limit int) ([]*Event, error) {
+	journal.lock.RLock()
+	head, tail := journal.head, journal.tail
+	journal.lock.RUnlock()
+
+	if after > head {
+		return nil, ErrCursorAhead
+	}
+	if after+1 < tail {
+		return nil, ErrCursorExpired
+	}
+	events := []*Event{}
+	iter := journal.db.NewIteratorWithStart(eventKey(after + 1))
+	defer iter.Release()
+	for len(events) < limit && iter.Next() {
+		key := iter.Key()
+		if len(key) != len(journalEventPrefix)+8 || string(key[:len(journalEventPrefix)]) != string(journalEventPrefix) {
+			break
+		}
+		event := &Event{}
+		if err := json.Unmarshal(iter.Value(), event); err != nil {
+			return nil, err
+		}
+		if event.Cursor > head {
+			break
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Replay send events after cursor to ch, then keep sending new events until quit closed.
+// ErrCursorExpired is returned if the subscriber fall behind the pruned events.
+func (journal *Journal) Replay(after uint64, ch chan<- *Event, quit <-chan struct{}) error {
+	waiter := make(chan struct{}, 1)
+	journal.lock.Lock()
+	journal.waiters[waiter] = struct{}{}
+	journal.lock.Unlock()
+	defer func() {
+		journal.lock.Lock()
+		delete(journal.waiters, waiter)
+		journal.lock.Unlock()
+	}()
+
+	for {
+		events, err := journal.Events(after, replayBatch)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			select {
+			case ch <- event:
+				after = event.Cursor
+			case <-quit:
+				return nil
+			}
+		}
+		if len(events) == replayBatch {
+			continue
+		}
+		select {
+		case <-waiter:
+		case <-quit:
+			return nil
+		}
+	}
+}
+
+func eventKey(cursor uint64) []byte {
+	return append(append([]byte{}, journalEventPrefix...), uint64Bytes(cursor)...)
+}
+
+func uint64Bytes(n uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, n)
+	return buf
+}
+
+func getUint64(db dbinterface.KeyValueStore, key []byte) uint64 {
+	buf, err := db.Get(key)
+	if err != nil || len(buf) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(buf)
+}
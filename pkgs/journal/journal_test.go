@@ -0,0 +1,85 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func appendEvents(t *testing.T, journal *Journal, n int) {
+	for i := 0; i < n; i++ {
+		if err := journal.Append(&Event{Type: EventTxPending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestJournalCursor(t *testing.T) {
+	db := memorydb.New()
+	journal := NewJournal(db, 5)
+	appendEvents(t, journal, 3)
+	if journal.Head() != 3 {
+		t.Fatalf("expect head 3, got %d", journal.Head())
+	}
+
+	events, err := journal.Events(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Cursor != 2 || events[1].Cursor != 3 {
+		t.Fatalf("unexpected events %v", events)
+	}
+
+	//cursor continue after reopen
+	journal = NewJournal(db, 5)
+	appendEvents(t, journal, 4)
+	if journal.Head() != 7 {
+		t.Fatalf("expect head 7 after reopen, got %d", journal.Head())
+	}
+
+	//event 1, 2 pruned
+	if _, err := journal.Events(0, 10); err != ErrCursorExpired {
+		t.Fatalf("expect ErrCursorExpired, got %v", err)
+	}
+	events, err = journal.Events(2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 || events[0].Cursor != 3 || events[4].Cursor != 7 {
+		t.Fatalf("unexpected events %v", events)
+	}
+	if _, err := journal.Events(8, 10); err != ErrCursorAhead {
+		t.Fatalf("expect ErrCursorAhead, got %v", err)
+	}
+}
+
+func TestJournalReplay(t *testing.T) {
+	journal := NewJournal(memorydb.New(), 0)
+	appendEvents(t, journal, replayBatch+10)
+
+	ch := make(chan *Event)
+	quit := make(chan struct{})
+	defer close(quit)
+	go journal.Replay(5, ch, quit)
+
+	expect := uint64(6)
+	receive := func(count int) {
+		for i := 0; i < count; i++ {
+			select {
+			case event := <-ch:
+				if event.Cursor != expect {
+					t.Fatalf("expect cursor %d, got %d", expect, event.Cursor)
+				}
+				expect++
+			case <-time.After(time.Second):
+				t.Fatalf("timeout waiting cursor %d", expect)
+			}
+		}
+	}
+	receive(replayBatch + 5)
+
+	//new events are pushed after stored events replayed
+	appendEvents(t, journal, 3)
+	receive(3)
+}
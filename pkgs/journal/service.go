@@ -0,0 +1,163 @@
+package journal
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/types"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// blockData is the data of block events
+type blockData struct {
+	TxCount   uint64 `json:"txCount"`
+	StateRoot []byte `json:"stateRoot"`
+}
+
+// txData is the data of tx events included or reverted with block
+type txData struct {
+	BlockHash crypto.Hash `json:"blockHash"`
+}
+
+// JournalService record chain and transaction pool events to journal
+type JournalService struct {
+	DatabaseService *database.DatabaseService   `service:"database"`
+	ChainService    chain.ChainServiceInterface `service:"chain"`
+	Notifier        blockmgr.IBlockNotify       `service:"blockmgr"`
+	Config          *JournalConfig
+
+	journal *Journal
+	apis    []app.API
+	quit    chan struct{}
+}
+
+func (service *JournalService) Name() string {
+	return MODULENAME
+}
+
+func (service *JournalService) Api() []app.API {
+	return service.apis
+}
+
+func (service *JournalService) CommandFlags() ([]cli.Command, []cli.Flag) {
+	return nil, []cli.Flag{EnableJournalFlag}
+}
+
+func (service *JournalService) Init(executeContext *app.ExecuteContext) error {
+	if executeContext.Cli.GlobalIsSet(EnableJournalFlag.Name) {
+		service.Config.Enable = executeContext.Cli.GlobalBool(EnableJournalFlag.Name)
+	}
+	if !service.Config.Enable {
+		return nil
+	}
+
+	service.journal = NewJournal(service.DatabaseService.LevelDb(), service.Config.MaxEvents)
+	service.quit = make(chan struct{})
+	service.apis = []app.API{
+		app.API{
+			Namespace: MODULENAME,
+			Version:   "1.0",
+			Service: &JournalApi{
+				journal: service.journal,
+			},
+			Public: true,
+		},
+	}
+	return nil
+}
+
+func (service *JournalService) Start(executeContext *app.ExecuteContext) error {
+	if service.Config == nil || !service.Config.Enable {
+		return nil
+	}
+	go service.record()
+	return nil
+}
+
+func (service *JournalService) Stop(executeContext *app.ExecuteContext) error {
+	if service.Config == nil || !service.Config.Enable {
+		return nil
+	}
+	close(service.quit)
+	return nil
+}
+
+func (service *JournalService) DefaultConfig() *JournalConfig {
+	return DefaultConfig
+}
+
+// Journal return the journal, nil if not enabled
+func (service *JournalService) Journal() *Journal {
+	return service.journal
+}
+
+func (service *JournalService) record() {
+	newBlockCh := make(chan *types.ChainEvent, 100)
+	newBlockSub := service.ChainService.NewBlockFeed().Subscribe(newBlockCh)
+	defer newBlockSub.Unsubscribe()
+	detachBlockCh := make(chan *types.Block, 100)
+	detachBlockSub := service.ChainService.DetachBlockFeed().Subscribe(detachBlockCh)
+	defer detachBlockSub.Unsubscribe()
+	reorgCh := make(chan *types.ReorgEvent, 10)
+	reorgSub := service.ChainService.ReorgFeed().Subscribe(reorgCh)
+	defer reorgSub.Unsubscribe()
+	txCh := make(chan types.NewTxsEvent, 1024)
+	txSub := service.Notifier.NewTxFeed().Subscribe(txCh)
+	defer txSub.Unsubscribe()
+
+	for {
+		var events []*Event
+		select {
+		case chainEvent := <-newBlockCh:
+			events = blockEvents(EventBlockConnected, EventTxIncluded, chainEvent.Block)
+		case block := <-detachBlockCh:
+			events = blockEvents(EventBlockDetached, EventTxReverted, block)
+		case reorg := <-reorgCh:
+			events = []*Event{reorgEvent(reorg)}
+		case txs := <-txCh:
+			now := time.Now().Unix()
+			for _, tx := range txs.Txs {
+				events = append(events, &Event{Type: EventTxPending, Time: now, Hash: *tx.TxHash()})
+			}
+		case <-service.quit:
+			return
+		}
+		if err := service.journal.Append(events...); err != nil {
+			log.WithField("err", err).Error("append journal events")
+		}
+	}
+}
+
+func blockEvents(blockType, txType string, block *types.Block) []*Event {
+	now := time.Now().Unix()
+	hash := *block.Header.Hash()
+	data, _ := json.Marshal(&blockData{TxCount: block.Data.TxCount, StateRoot: block.Header.StateRoot})
+	events := []*Event{{Type: blockType, Time: now, Height: block.Header.Height, Hash: hash, Data: data}}
+
+	txDataBytes, _ := json.Marshal(&txData{BlockHash: hash})
+	for _, tx := range block.Data.TxList {
+		events = append(events, &Event{Type: txType, Time: now, Height: block.Header.Height, Hash: *tx.TxHash(), Data: txDataBytes})
+	}
+	return events
+}
+
+func reorgEvent(reorg *types.ReorgEvent) *Event {
+	record := &chain.ReorgRecord{
+		Time:       time.Now().Unix(),
+		ForkHeight: reorg.ForkHeight,
+		ForkHash:   reorg.ForkHash,
+	}
+	for _, block := range reorg.OldChain {
+		record.OldChain = append(record.OldChain, *block.Header.Hash())
+	}
+	for _, block := range reorg.NewChain {
+		record.NewChain = append(record.NewChain, *block.Header.Hash())
+	}
+	data, _ := json.Marshal(record)
+	return &Event{Type: EventReorg, Time: record.Time, Height: reorg.ForkHeight, Hash: reorg.ForkHash, Data: data}
+}
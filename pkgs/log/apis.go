@@ -1,10 +1,8 @@
 package log
 
 import (
-	"github.com/pingcap/errors"
 	"github.com/sirupsen/logrus"
 	"strconv"
-	"strings"
 )
 
 /*
@@ -38,7 +36,7 @@ func (logApi *LogApi) SetLevel(lvl string) error {
 	}
 	lv, err := parserLevel(lvl)
 	if err != nil {
-		return nil
+		return err
 	}
 	logApi.hook.SetLevel(lv)
 	return nil
@@ -55,15 +53,72 @@ func (logApi *LogApi) SetLevel(lvl string) error {
   {"jsonrpc":"2.0","id":3,"result":null}
 */
 func (logApi *LogApi) SetVmodule(module string) error {
-	args := []interface{}{}
-	pairs := strings.Split(module, ";")
-	for _, pair := range pairs {
-		k_v := strings.Split(pair, "=")
-		if len(k_v) != 2 {
-			return errors.New("not correct module format")
-		}
-		args = append(args, k_v[0])
-		args = append(args, k_v[1])
+	args, err := parseVmodule(module)
+	if err != nil {
+		return err
 	}
 	return logApi.hook.SetModulesLevel(args...)
 }
+
+/*
+name: Log admin RPC Api
+usage: Control log levels of the running node
+prefix:admin
+*/
+type AdminLogApi struct {
+	hook *ModuleHook
+}
+
+func NewAdminLogApi(hook *ModuleHook) *AdminLogApi {
+	return &AdminLogApi{hook}
+}
+
+/*
+ name: setLogLevel
+ usage: Set the log level of all modules, or of one module when given (p2p, chain, blockmgr, txpool, consensus ...)
+ params:
+	1. log level, name or number ("debug", "5")
+	2. module name, optional
+ return: 无
+ example:  curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_setLogLevel","params":["debug","p2p"], "id": 3}' -H "Content-Type:application/json"
+ response:
+  {"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (adminApi *AdminLogApi) SetLogLevel(lvl string, module *string) error {
+	lv, err := parserLevel(lvl)
+	if err != nil {
+		return err
+	}
+	if module == nil || *module == "" {
+		adminApi.hook.SetLevel(lv)
+		return nil
+	}
+	return adminApi.hook.SetModulesLevel(*module, lv)
+}
+
+/*
+ name: logLevels
+ usage: Get the global log level and the levels set per module
+ params:
+ return: global level and module levels
+ example:  curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_logLevels","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+  {"jsonrpc":"2.0","id":3,"result":{"global":"info","modules":{"p2p":"debug"}}}
+*/
+func (adminApi *AdminLogApi) LogLevels() *LogLevels {
+	global, modules := adminApi.hook.ModuleLevels()
+	levels := &LogLevels{
+		Global:  global.String(),
+		Modules: make(map[string]string, len(modules)),
+	}
+	for module, lv := range modules {
+		levels.Modules[module] = lv.String()
+	}
+	return levels
+}
+
+// LogLevels is the result of admin_logLevels
+type LogLevels struct {
+	Global  string            `json:"global"`
+	Modules map[string]string `json:"modules"`
+}
@@ -0,0 +1,10 @@
+package log
+
+import "errors"
+
+var (
+	// ErrLogFormat print error message.
+	ErrLogFormat = errors.New("unknown log format, expect text or json")
+	// ErrModuleFormat print error message.
+	ErrModuleFormat = errors.New("not correct module format, expect module=level")
+)
@@ -1,7 +1,6 @@
 package log
 
 import (
-	"errors"
 	"github.com/AsynkronIT/protoactor-go/actor"
 	"github.com/drep-project/DREP-Chain/app"
 	"github.com/shiena/ansicolor"
@@ -43,7 +42,7 @@ func (logService *LogService) Api() []app.API {
 
 // CommandFlags export flag to control log while app running
 func (logService *LogService) CommandFlags() ([]cli.Command, []cli.Flag) {
-	return nil, []cli.Flag{LogDirFlag, LogLevelFlag, VmoduleFlag}
+	return nil, []cli.Flag{LogDirFlag, LogLevelFlag, VmoduleFlag, LogFormatFlag}
 }
 
 // P2pMessages no p2p msg for log
@@ -64,39 +63,40 @@ func (logService *LogService) Init(executeContext *app.ExecuteContext) error {
 		Compress:   false, // disabled by default
 	}
 	logrus.SetFormatter(&NullFormat{})
-	textFormat := &prefixed.TextFormatter{
-		FullTimestamp:   true,
-		ForceColors:     true,
-		ForceFormatting: true,
+	var printFormat logrus.Formatter
+	switch logService.Config.Format {
+	case LogFormatJSON:
+		printFormat = &logrus.JSONFormatter{}
+		logrus.SetOutput(os.Stdout)
+	case LogFormatText, "":
+		printFormat = &prefixed.TextFormatter{
+			FullTimestamp:   true,
+			ForceColors:     true,
+			ForceFormatting: true,
+		}
+		logrus.SetOutput(ansicolor.NewAnsiColorWriter(os.Stdout))
+	default:
+		return ErrLogFormat
 	}
-	logrus.SetOutput(ansicolor.NewAnsiColorWriter(os.Stdout))
-	logrus.SetLevel(logrus.DebugLevel)
 
-	logrus.SetLevel(logrus.Level(logService.Config.LogLevel))
-	mHook := NewMyHook(wirter1, &logrus.JSONFormatter{}, textFormat)
 	lv, err := parserLevel(logService.Config.LogLevel)
 	if err != nil {
 		return err
 	}
+	logrus.SetLevel(lv)
+	mHook := NewMyHook(wirter1, &logrus.JSONFormatter{}, printFormat)
 	for key, _ := range loggers {
 		mHook.moduleLevel[key] = lv
 	}
 
 	if logService.Config.Vmodule != "" {
-		args := []interface{}{}
-		pairs := strings.Split(logService.Config.Vmodule, ";")
-		for _, pair := range pairs {
-			if len(pair) == 0 {
-				continue
-			}
-			k_v := strings.Split(pair, "=")
-			if len(k_v) != 2 {
-				return errors.New("not correct module format")
-			}
-			args = append(args, k_v[0])
-			args = append(args, k_v[1])
+		args, err := parseVmodule(logService.Config.Vmodule)
+		if err != nil {
+			return err
+		}
+		if err := mHook.SetModulesLevel(args...); err != nil {
+			return err
 		}
-		mHook.SetModulesLevel(args...)
 	}
 	logrus.AddHook(mHook)
 
@@ -107,6 +107,12 @@ func (logService *LogService) Init(executeContext *app.ExecuteContext) error {
 			Service:   NewLogApi(mHook),
 			Public:    true,
 		},
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewAdminLogApi(mHook),
+			Public:    true,
+		},
 	}
 	return nil
 	//return dlog.SetUp(logService.Config.DataDir, logService.Config.LogLevel, logService.Config.Vmodule, logService.Config.BacktraceAt)
@@ -134,6 +140,10 @@ func (logService *LogService) setLogConfig(ctx *cli.Context, homeDir string) {
 	if ctx.GlobalIsSet(VmoduleFlag.Name) {
 		logService.Config.Vmodule = ctx.GlobalString(VmoduleFlag.Name)
 	}
+
+	if ctx.GlobalIsSet(LogFormatFlag.Name) {
+		logService.Config.Format = ctx.GlobalString(LogFormatFlag.Name)
+	}
 	//logdir
 	if ctx.GlobalIsSet(LogDirFlag.Name) {
 		logService.Config.DataDir = ctx.GlobalString(LogDirFlag.Name)
@@ -142,6 +152,21 @@ func (logService *LogService) setLogConfig(ctx *cli.Context, homeDir string) {
 	}
 }
 
+// parseVmodule split "module=level" pairs separated by comma or semicolon into SetModulesLevel args
+func parseVmodule(vmodule string) ([]interface{}, error) {
+	args := []interface{}{}
+	pairs := strings.FieldsFunc(vmodule, func(r rune) bool { return r == ',' || r == ';' })
+	for _, pair := range pairs {
+		k_v := strings.Split(strings.TrimSpace(pair), "=")
+		if len(k_v) != 2 {
+			return nil, ErrModuleFormat
+		}
+		args = append(args, k_v[0])
+		args = append(args, k_v[1])
+	}
+	return args, nil
+}
+
 // EnsureLogger create logger int other file
 func EnsureLogger(moduleName string) *logrus.Entry {
 	log, ok := loggers[moduleName]
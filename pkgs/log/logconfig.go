@@ -18,13 +18,25 @@ var (
 	}
 	VmoduleFlag = cli.StringFlag{
 		Name:  "vmodule",
-		Usage: "Per-module verbosity: comma-separated list of <pattern>=<level> (e.g. eth/*=5,p2p=4)",
+		Usage: "Per-module verbosity: comma-separated list of <module>=<level> (e.g. p2p=5,chain=4,consensus=5)",
 		Value: "",
 	}
+	LogFormatFlag = cli.StringFlag{
+		Name:  "logformat",
+		Usage: "Console log format: text or json",
+		Value: LogFormatText,
+	}
+)
+
+// console log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
 type LogConfig struct {
 	DataDir  string `json:"-"`
 	LogLevel int    `json:"logLevel"`
 	Vmodule  string `json:"vmodule,omitempty"`
+	Format   string `json:"format,omitempty"` //console format, text or json
 }
@@ -8,6 +8,11 @@ import (
 	"sync"
 )
 
+// moduleAliases groups modules that are controlled together under a common name
+var moduleAliases = map[string][]string{
+	"consensus": {"bft", "solo"},
+}
+
 // ModuleHook use custom hooks to redefine log input and output, module control, level control
 type ModuleHook struct {
 	writer        io.Writer
@@ -21,6 +26,7 @@ type ModuleHook struct {
 func NewMyHook(writer io.Writer, formatter log.Formatter, printFormat log.Formatter) *ModuleHook {
 	return &ModuleHook{
 		writer:        writer,
+		globalLevel:   log.GetLevel(),
 		saveFormatter: formatter,
 		printFormat:   printFormat,
 		moduleLevel:   make(map[string]log.Level),
@@ -29,18 +35,16 @@ func NewMyHook(writer io.Writer, formatter log.Formatter, printFormat log.Format
 
 func (hook *ModuleHook) Fire(entry *log.Entry) error {
 	hook.lock.RLock()
-	defer hook.lock.RUnlock()
+	lv := hook.globalLevel
 	if val, ok := entry.Data[MODULE]; ok {
-		if lv, ok2 := hook.moduleLevel[val.(string)]; ok2 {
-			if lv < entry.Level {
-				return nil
-			}
-		} else {
-			if log.GetLevel() < entry.Level {
-				return nil
-			}
+		if mlv, ok := hook.moduleLevel[val.(string)]; ok {
+			lv = mlv
 		}
 	}
+	hook.lock.RUnlock()
+	if lv < entry.Level {
+		return nil
+	}
 	hook.saveLog(entry)
 	hook.printLog(entry)
 	return nil
@@ -52,16 +56,23 @@ func (hook *ModuleHook) saveLog(entry *log.Entry) {
 	hook.writer.Write(msg)
 }
 
-//printLog use printFormat format log output
+//printLog use printFormat format log output, the module field is only kept in json output
 func (hook *ModuleHook) printLog(entry *log.Entry) {
-	var msg []byte
-	_, ok := entry.Data[MODULE]
-	if ok {
-		delete(entry.Data, MODULE)
-		msg, _ = hook.printFormat.Format(entry)
-	} else {
-		msg, _ = hook.printFormat.Format(entry)
+	if _, isJSON := hook.printFormat.(*log.JSONFormatter); !isJSON {
+		if _, ok := entry.Data[MODULE]; ok {
+			//format a copy, the entry is shared with other hooks
+			data := make(log.Fields, len(entry.Data))
+			for k, v := range entry.Data {
+				if k != MODULE {
+					data[k] = v
+				}
+			}
+			printEntry := *entry
+			printEntry.Data = data
+			entry = &printEntry
+		}
 	}
+	msg, _ := hook.printFormat.Format(entry)
 	entry.Logger.Out.Write(msg)
 }
 
@@ -69,11 +80,15 @@ func (hook *ModuleHook) Levels() []log.Level {
 	return log.AllLevels
 }
 
+// SetLevel set the level of all modules
 func (hook *ModuleHook) SetLevel(lvInt log.Level) {
-	log.SetLevel(lvInt)
+	hook.lock.Lock()
+	defer hook.lock.Unlock()
+	hook.globalLevel = lvInt
 	for key, _ := range hook.moduleLevel {
 		hook.moduleLevel[key] = lvInt
 	}
+	hook.applyLoggerLevel()
 }
 
 func (hook *ModuleHook) SetModulesLevel(moduleLevel ...interface{}) error {
@@ -106,11 +121,40 @@ func (hook *ModuleHook) SetModulesLevel(moduleLevel ...interface{}) error {
 		default:
 			return errors.New("unsport lvl type")
 		}
+		if modules, ok := moduleAliases[module]; ok {
+			for _, m := range modules {
+				hook.moduleLevel[m] = lv
+			}
+			continue
+		}
 		hook.moduleLevel[module] = lv
 	}
+	hook.applyLoggerLevel()
 	return nil
 }
 
+// ModuleLevels return the global level and the level of every module set explicitly
+func (hook *ModuleHook) ModuleLevels() (log.Level, map[string]log.Level) {
+	hook.lock.RLock()
+	defer hook.lock.RUnlock()
+	levels := make(map[string]log.Level, len(hook.moduleLevel))
+	for module, lv := range hook.moduleLevel {
+		levels[module] = lv
+	}
+	return hook.globalLevel, levels
+}
+
+// applyLoggerLevel let logrus pass entries up to the most verbose level in use, the hook filters the rest
+func (hook *ModuleHook) applyLoggerLevel() {
+	max := hook.globalLevel
+	for _, lv := range hook.moduleLevel {
+		if lv > max {
+			max = lv
+		}
+	}
+	log.SetLevel(max)
+}
+
 func parserLevel(lvAny interface{}) (log.Level, error) {
 	var lv log.Level
 	switch t := lvAny.(type) {
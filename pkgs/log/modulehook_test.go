@@ -129,3 +129,68 @@ func TestLogModuleLevel(t *testing.T) {
 		t.Errorf("export module %s loged but not logged", "TEST2")
 	}
 }
+
+func TestLogModuleMoreVerbose(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	logrus.SetFormatter(&NullFormat{})
+	logrus.SetOutput(buf)
+	logger := EnsureLogger("TEST3")
+	logger4 := EnsureLogger("TEST4")
+	mHook := NewMyHook(bytes.NewBuffer([]byte{}), &logrus.JSONFormatter{}, &logrus.JSONFormatter{})
+	mHook.SetLevel(logrus.InfoLevel)
+
+	if err := mHook.SetModulesLevel("TEST3", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if logrus.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("logger level %v, expected debug to reach the hook", logrus.GetLevel())
+	}
+	entry := logger.WithField("k", "v")
+	entry.Level = logrus.DebugLevel
+	entry.Message = "debug msg"
+	mHook.Fire(entry)
+	entry4 := logger4.WithField("k", "v")
+	entry4.Level = logrus.DebugLevel
+	entry4.Message = "filtered msg"
+	mHook.Fire(entry4)
+
+	out := buf.String()
+	if !strings.Contains(out, "debug msg") {
+		t.Error("debug entry of verbose module not printed")
+	}
+	if strings.Contains(out, "filtered msg") {
+		t.Error("debug entry of module at info level printed")
+	}
+	line := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(strings.Split(out, "\n")[0]), &line); err != nil {
+		t.Fatalf("output is not json: %v", err)
+	}
+	if line[MODULE] != "TEST3" {
+		t.Errorf("json output lost the module field: %v", line)
+	}
+}
+
+func TestParseVmodule(t *testing.T) {
+	args, err := parseVmodule("p2p=5,chain=debug;consensus=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 6 || args[0] != "p2p" || args[3] != "debug" || args[4] != "consensus" {
+		t.Fatalf("unexpected args %v", args)
+	}
+	if _, err := parseVmodule("p2p"); err != ErrModuleFormat {
+		t.Fatalf("expected ErrModuleFormat, got %v", err)
+	}
+
+	mHook := NewMyHook(bytes.NewBuffer([]byte{}), &logrus.JSONFormatter{}, &logrus.JSONFormatter{})
+	if err := mHook.SetModulesLevel(args...); err != nil {
+		t.Fatal(err)
+	}
+	_, levels := mHook.ModuleLevels()
+	if levels["bft"] != logrus.InfoLevel {
+		t.Errorf("consensus alias not applied to bft: %v", levels)
+	}
+	if _, ok := levels["solo"]; !ok {
+		t.Errorf("consensus alias not applied to solo: %v", levels)
+	}
+}
@@ -0,0 +1,159 @@
+package reputation
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const maxHistory = 100
+
+// Reputation is the score of an address decayed to the best chain tip
+type Reputation struct {
+	Address      crypto.CommonAddress `json:"address"`
+	Score        uint64               `json:"score"`
+	Height       uint64               `json:"height"`       //Height of the tip the score is decayed to
+	AttestHeight uint64               `json:"attestHeight"` //Height of the latest attestation
+}
+
+/*
+name: Reputation
+usage: Attest the reputation of addresses and query the reputation decayed by the governed rules
+prefix:reputation
+*/
+type ReputationApi struct {
+	service *ReputationService
+}
+
+/*
+ name: attest
+ usage: Add to or remove from the reputation of an address, at most the max delta of the chain and a tenth of the attester reputation. The attester needs the min attester reputation of the chain, or to be a governor, and attests an address once per attest interval
+ params:
+	1. address of the attester, or its alias
+	2. attested address
+	3. reputation added, or removed if negative is true
+	4. negative
+	5. memo, at most 128 bytes
+	6. gas price
+	7. gas uplimit of transaction
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"reputation_attest","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","0x8a8e541ddd1272d53729164c70197221a3c27486",10,false,"order delivered","0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (api *ReputationApi) Attest(from accountService.AddressOrAlias, subject crypto.CommonAddress, delta uint64, negative bool, memo string, gasprice, gaslimit *common.Big) (string, error) {
+	fromAddr, err := api.service.AccountService.ResolveAddress(from)
+	if err != nil {
+		return "", err
+	}
+	// refuse early what the transaction would fail with
+	if *fromAddr == subject {
+		return "", ErrSelfAttest
+	}
+	if delta == 0 || delta > api.service.params.MaxDelta {
+		return "", ErrInvalidDelta
+	}
+	if len(memo) > maxMemoLength {
+		return "", ErrMemoTooLong
+	}
+	msg := &AttestMsg{Delta: delta, Negative: negative, Memo: memo}
+	tx, err := api.service.sendTransaction(types.AttestReputationType, fromAddr, &subject, msg, (*big.Int)(gasprice), (*big.Int)(gaslimit))
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: setDecayRule
+ usage: Set the rule every reputation decays by from the block of the transaction, the sender must be a governor
+ params:
+	1. address of the governor, or its alias
+	2. interval in blocks, reputation decays each time the height passes a multiple of it
+	3. decay rate in per mille
+	4. gas price
+	5. gas uplimit of transaction
+ return: transaction hash
+ example:   curl -H "Content-Type: application/json" -X post --data '{"jsonrpc":"2.0","method":"reputation_setDecayRule","params":["0x3ebcbe7cb440dd8c52940a2963472380afbb56c5",8640,10,"0x110","0x30000"],"id":1}' http://127.0.0.1:10085
+ response:
+	 {"jsonrpc":"2.0","id":1,"result":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e"}
+*/
+func (api *ReputationApi) SetDecayRule(from accountService.AddressOrAlias, interval, rate uint64, gasprice, gaslimit *common.Big) (string, error) {
+	fromAddr, err := api.service.AccountService.ResolveAddress(from)
+	if err != nil {
+		return "", err
+	}
+	if interval == 0 || rate > 1000 {
+		return "", ErrInvalidRule
+	}
+	msg := &RuleMsg{Interval: interval, Rate: rate}
+	tx, err := api.service.sendTransaction(types.ReputationRuleType, fromAddr, &crypto.CommonAddress{}, msg, (*big.Int)(gasprice), (*big.Int)(gaslimit))
+	if err != nil {
+		return "", err
+	}
+	return tx.TxHash().String(), nil
+}
+
+/*
+ name: get
+ usage: Query the reputation of an address decayed to the latest block
+ params:
+	1. Query address
+ return: reputation
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"reputation_get","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":{"address":"0x8a8e541ddd1272d53729164c70197221a3c27486","score":98,"height":17500,"attestHeight":12001}}
+*/
+func (api *ReputationApi) Get(addr crypto.CommonAddress) (*Reputation, error) {
+	op, height, err := api.service.tipState()
+	if err != nil {
+		return nil, err
+	}
+	record, err := op.record(&addr)
+	if err != nil {
+		return nil, err
+	}
+	score, err := op.Score(&addr, height)
+	if err != nil {
+		return nil, err
+	}
+	return &Reputation{Address: addr, Score: score, Height: height, AttestHeight: record.Height}, nil
+}
+
+/*
+ name: history
+ usage: Query the latest 100 attestations of an address, newest first
+ params:
+	1. Query address
+ return: attestations with the reputation right after each
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"reputation_history","params":["0x8a8e541ddd1272d53729164c70197221a3c27486"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":[{"height":12001,"txHash":"0x3a3b59f90a21c2fd1b690aa3a2bc06dc2d40eb5bdc26fdd7ecb7e1105af2638e","attester":"0x3ebcbe7cb440dd8c52940a2963472380afbb56c5","delta":10,"negative":false,"memo":"order delivered","score":100}]}
+*/
+func (api *ReputationApi) History(addr crypto.CommonAddress) ([]*Attestation, error) {
+	op, _, err := api.service.tipState()
+	if err != nil {
+		return nil, err
+	}
+	return op.History(&addr, maxHistory)
+}
+
+/*
+ name: getDecayRules
+ usage: Query the decay rules, each applies from its height until the next
+ params:
+ return: decay rules ordered by height
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"reputation_getDecayRules","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {"jsonrpc":"2.0","id":3,"result":[{"height":0,"interval":8640,"rate":10}]}
+*/
+func (api *ReputationApi) GetDecayRules() ([]DecayRule, error) {
+	op, _, err := api.service.tipState()
+	if err != nil {
+		return nil, err
+	}
+	return op.rules()
+}
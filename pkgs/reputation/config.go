@@ -0,0 +1,61 @@
+package reputation
+
+import (
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// GenesisSection is the section of genesis holding ReputationParams
+const GenesisSection = "Reputation"
+
+// ReputationParams set how far an attestation moves a score and who may make it. Every score
+// in state is derived from them, so they are chain rules kept in genesis beside the forks
+type ReputationParams struct {
+	Governors        []crypto.CommonAddress `json:"governors"`        //Accounts allowed to set the decay rule, they attest without reputation
+	MaxDelta         uint64                 `json:"maxDelta"`         //Max reputation added or removed by an attestation, 100 if omitted
+	MinAttesterScore uint64                 `json:"minAttesterScore"` //Reputation an account needs to attest, 1000 if omitted
+	AttestInterval   uint64                 `json:"attestInterval"`   //Blocks between two attestations of an attester on the same address, 8640 if omitted
+	Decay            DecayRule              `json:"decay"`            //Decay rule until a governor sets another, 1% every 8640 blocks if omitted
+}
+
+var (
+	DefaultParams = ReputationParams{
+		MaxDelta:         100,
+		MinAttesterScore: 1000,
+		AttestInterval:   8640,
+		Decay: DecayRule{
+			Interval: 8640,
+			Rate:     10,
+		},
+	}
+)
+
+// loadReputationParams read the reputation section of genesis, the omitted params take their
+// default. A chain without the section has no governor
+func loadReputationParams(genesisParams *chain.GenesisParams) (*ReputationParams, error) {
+	params := &ReputationParams{}
+	if _, err := genesisParams.Section(GenesisSection, params); err != nil {
+		return nil, err
+	}
+	if params.MaxDelta == 0 {
+		params.MaxDelta = DefaultParams.MaxDelta
+	}
+	if params.MinAttesterScore == 0 {
+		params.MinAttesterScore = DefaultParams.MinAttesterScore
+	}
+	if params.AttestInterval == 0 {
+		params.AttestInterval = DefaultParams.AttestInterval
+	}
+	if params.Decay.Interval == 0 {
+		params.Decay = DefaultParams.Decay
+	}
+	params.Decay.Height = 0
+	return params, nil
+}
+
+type ReputationConfig struct {
+}
+
+var (
+	DefaultConfig = &ReputationConfig{}
+)
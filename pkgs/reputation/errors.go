@@ -0,0 +1,15 @@
+package reputation
+
+import "errors"
+
+var (
+	ErrNoSubject      = errors.New("no address to attest")
+	ErrSelfAttest     = errors.New("an account can not attest its own reputation")
+	ErrInvalidDelta   = errors.New("attested reputation must be positive and within the max delta")
+	ErrNotGovernor    = errors.New("sender is not a reputation governor")
+	ErrInvalidRule    = errors.New("decay interval must be positive and decay rate at most 1000 per mille")
+	ErrMemoTooLong    = errors.New("attestation memo too long")
+	ErrRuleSetAlready = errors.New("decay rule already set at this height")
+	ErrLowReputation  = errors.New("attester reputation too low")
+	ErrAttestTooSoon  = errors.New("address attested by this attester within the attest interval")
+)
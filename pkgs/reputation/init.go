@@ -0,0 +1,30 @@
+package reputation
+
+import (
+	"github.com/drep-project/DREP-Chain/crypto"
+	dlog "github.com/drep-project/DREP-Chain/pkgs/log"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+const (
+	MODULENAME = "reputation"
+)
+
+var (
+	log = dlog.EnsureLogger(MODULENAME)
+
+	// ReputationAddress is the address the attestation events are logged by
+	ReputationAddress = crypto.BytesToAddress([]byte(MODULENAME))
+)
+
+func init() {
+	types.SetTxPayloadDecoder(types.AttestReputationType, func(version int32, data []byte) (interface{}, error) {
+		msg := &AttestMsg{}
+		return msg, binary.Unmarshal(data, msg)
+	})
+	types.SetTxPayloadDecoder(types.ReputationRuleType, func(version int32, data []byte) (interface{}, error) {
+		msg := &RuleMsg{}
+		return msg, binary.Unmarshal(data, msg)
+	})
+}
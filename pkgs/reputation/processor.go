@@ -0,0 +1,192 @@
+package reputation
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+const (
+	maxMemoLength         = 128
+	attesterWeight        = 10 //An attester moves a score by at most its own reputation divided by this
+	attestGas      uint64 = 30000
+	ruleGas        uint64 = 20000
+)
+
+var (
+	_ = (chain.ITransactionSelector)((*ReputationTxSelector)(nil))
+	_ = (chain.ITransactionValidator)((*ReputationTransactionProcessor)(nil))
+
+	// AttestedEventSig is the topic of Attested(address indexed subject, address indexed attester, uint256 score)
+	AttestedEventSig = crypto.Keccak256Hash([]byte("Attested(address,address,uint256)"))
+)
+
+// AttestMsg add Delta to the reputation of the receiver of the transaction, or remove it if Negative
+type AttestMsg struct {
+	Delta    uint64
+	Negative bool
+	Memo     string
+}
+
+// RuleMsg set the decay rule applying from the block of the transaction
+type RuleMsg struct {
+	Interval uint64
+	Rate     uint64
+}
+
+type ReputationTxSelector struct {
+}
+
+func (selector *ReputationTxSelector) Select(tx *types.Transaction) bool {
+	return tx.Type() == types.AttestReputationType || tx.Type() == types.ReputationRuleType
+}
+
+// ReputationTransactionProcessor execute attestations and decay rule changes, every check is
+// done before the state is written
+type ReputationTransactionProcessor struct {
+	params *ReputationParams
+}
+
+func (processor *ReputationTransactionProcessor) ExecuteTransaction(context *chain.ExecuteTransactionContext) *types.ExecuteTransactionResult {
+	etr := &types.ExecuteTransactionResult{}
+	op := &ReputationOp{context.TrieStore(), processor.params.Decay}
+	var err error
+	if context.Tx().Type() == types.AttestReputationType {
+		msg := &AttestMsg{}
+		if err = binary.Unmarshal(context.Data(), msg); err == nil {
+			etr.ContractTxLog, err = processor.attest(context, op, msg)
+		}
+	} else {
+		msg := &RuleMsg{}
+		if err = binary.Unmarshal(context.Data(), msg); err == nil {
+			err = processor.setRule(context, op, msg)
+		}
+	}
+	if err == nil {
+		err = context.TrieStore().PutNonce(context.From(), context.Tx().Nonce()+1)
+	}
+	etr.Txerror = err
+	return etr
+}
+
+func (processor *ReputationTransactionProcessor) attest(context *chain.ExecuteTransactionContext, op *ReputationOp, msg *AttestMsg) ([]*types.Log, error) {
+	from, subject := context.From(), context.Tx().To()
+	if subject == nil || subject.IsEmpty() {
+		return nil, ErrNoSubject
+	}
+	if *from == *subject {
+		return nil, ErrSelfAttest
+	}
+	if msg.Delta == 0 || msg.Delta > processor.params.MaxDelta {
+		return nil, ErrInvalidDelta
+	}
+	if len(msg.Memo) > maxMemoLength {
+		return nil, ErrMemoTooLong
+	}
+	if err := context.UseGas(attestGas); err != nil {
+		return nil, err
+	}
+	height := context.Header().Height
+	if err := processor.checkAttester(op, from, subject, msg.Delta, height); err != nil {
+		return nil, err
+	}
+	score, err := op.Score(subject, height)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msg.Negative && msg.Delta > score:
+		score = 0
+	case msg.Negative:
+		score -= msg.Delta
+	case msg.Delta > math.MaxUint64-score:
+		score = math.MaxUint64
+	default:
+		score += msg.Delta
+	}
+
+	if err := op.putRecord(subject, &scoreRecord{Score: score, Height: height}); err != nil {
+		return nil, err
+	}
+	if err := op.putLastAttest(from, subject, height); err != nil {
+		return nil, err
+	}
+	attestation := &Attestation{
+		Height:   height,
+		TxHash:   *context.Tx().TxHash(),
+		Attester: *from,
+		Delta:    msg.Delta,
+		Negative: msg.Negative,
+		Memo:     msg.Memo,
+		Score:    score,
+	}
+	if err := op.appendHistory(subject, attestation); err != nil {
+		return nil, err
+	}
+	return []*types.Log{{
+		TxType:  types.AttestReputationType,
+		Address: ReputationAddress,
+		Topics:  []crypto.Hash{AttestedEventSig, crypto.Bytes2Hash(subject[:]), crypto.Bytes2Hash(from[:])},
+		Data:    crypto.Big2Hash(new(big.Int).SetUint64(score)).Bytes(),
+		TxHash:  attestation.TxHash,
+		Height:  height,
+	}}, nil
+}
+
+// checkAttester check attester may move the score of subject by delta at height. Only accounts
+// with reputation attest, by a weight of their own score, so a new account can not attest and a
+// governor seeds the first scores. An attester attests an address once per interval, so it can
+// not push a score by repeating attestations
+func (processor *ReputationTransactionProcessor) checkAttester(op *ReputationOp, attester, subject *crypto.CommonAddress, delta, height uint64) error {
+	if !processor.isGovernor(attester) {
+		score, err := op.Score(attester, height)
+		if err != nil {
+			return err
+		}
+		if score < processor.params.MinAttesterScore {
+			return ErrLowReputation
+		}
+		if delta > score/attesterWeight {
+			return ErrInvalidDelta
+		}
+	}
+	last, attested, err := op.lastAttest(attester, subject)
+	if err != nil {
+		return err
+	}
+	if attested && height < last+processor.params.AttestInterval {
+		return ErrAttestTooSoon
+	}
+	return nil
+}
+
+func (processor *ReputationTransactionProcessor) isGovernor(addr *crypto.CommonAddress) bool {
+	for _, governor := range processor.params.Governors {
+		if governor == *addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (processor *ReputationTransactionProcessor) setRule(context *chain.ExecuteTransactionContext, op *ReputationOp, msg *RuleMsg) error {
+	if !processor.isGovernor(context.From()) {
+		return ErrNotGovernor
+	}
+	if msg.Interval == 0 || msg.Rate > 1000 {
+		return ErrInvalidRule
+	}
+	if err := context.UseGas(ruleGas); err != nil {
+		return err
+	}
+	rule := DecayRule{Height: context.Header().Height, Interval: msg.Interval, Rate: msg.Rate}
+	if err := op.addRule(rule); err != nil {
+		return err
+	}
+	log.WithField("height", rule.Height).WithField("interval", rule.Interval).WithField("rate", rule.Rate).Debug("reputation decay rule set")
+	return nil
+}
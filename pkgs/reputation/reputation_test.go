@@ -0,0 +1,118 @@
+package reputation
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/common/trie"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+)
+
+func TestDecay(t *testing.T) {
+	rules := []DecayRule{{Height: 0, Interval: 10, Rate: 100}}
+	if score := decay(1000, 5, 9, rules); score != 1000 {
+		t.Fatalf("decayed to %d before a boundary", score)
+	}
+	if score := decay(1000, 5, 10, rules); score != 900 {
+		t.Fatalf("decayed to %d over one boundary, expected 900", score)
+	}
+	if score := decay(1000, 10, 30, rules); score != 810 {
+		t.Fatalf("decayed to %d over two boundaries, expected 810", score)
+	}
+	// decaying in steps gives the same score as at once
+	if score := decay(decay(1000, 3, 17, rules), 17, 35, rules); score != decay(1000, 3, 35, rules) {
+		t.Fatalf("decayed in steps to %d", score)
+	}
+	if score := decay(5, 0, 1000000, rules); score != 0 {
+		t.Fatalf("small score decayed to %d", score)
+	}
+
+	// a new rule applies from its height only
+	rules = append(rules, DecayRule{Height: 20, Interval: 5, Rate: 0})
+	if score := decay(1000, 5, 100, rules); score != 810 {
+		t.Fatalf("decayed to %d across rules, expected 810", score)
+	}
+}
+
+func TestReputationState(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &ReputationOp{trieStore, DecayRule{Interval: 10, Rate: 500}}
+	addr := crypto.CommonAddress{1}
+	if err := op.putRecord(&addr, &scoreRecord{Score: 100, Height: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if score, _ := op.Score(&addr, 25); score != 25 {
+		t.Fatalf("score %d, expected 25", score)
+	}
+
+	if err := op.addRule(DecayRule{Height: 12, Interval: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.addRule(DecayRule{Height: 12, Interval: 20}); err != ErrRuleSetAlready {
+		t.Fatalf("two rules set at a height, %v", err)
+	}
+	if score, _ := op.Score(&addr, 25); score != 50 {
+		t.Fatalf("score %d after rule change, expected 50", score)
+	}
+
+	for i := uint64(1); i <= 3; i++ {
+		if err := op.appendHistory(&addr, &Attestation{Height: i, Delta: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := op.History(&addr, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Height != 3 || history[1].Height != 2 {
+		t.Fatalf("unexpected history %v", history)
+	}
+}
+
+func TestCheckAttester(t *testing.T) {
+	trieStore, err := store.TrieStoreFromStore(memorydb.New(), trie.EmptyRoot[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &ReputationOp{trieStore, DecayRule{}}
+	governor, attester, subject := crypto.CommonAddress{1}, crypto.CommonAddress{2}, crypto.CommonAddress{3}
+	processor := &ReputationTransactionProcessor{params: &ReputationParams{
+		Governors:        []crypto.CommonAddress{governor},
+		MaxDelta:         100,
+		MinAttesterScore: 500,
+		AttestInterval:   10,
+	}}
+
+	if err := processor.checkAttester(op, &attester, &subject, 1, 5); err != ErrLowReputation {
+		t.Fatalf("account without reputation attested, %v", err)
+	}
+	if err := processor.checkAttester(op, &governor, &subject, 100, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := op.putRecord(&attester, &scoreRecord{Score: 600}); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.checkAttester(op, &attester, &subject, 61, 5); err != ErrInvalidDelta {
+		t.Fatalf("attested more than the attester weight, %v", err)
+	}
+	if err := processor.checkAttester(op, &attester, &subject, 60, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := op.putLastAttest(&attester, &subject, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := processor.checkAttester(op, &attester, &subject, 60, 14); err != ErrAttestTooSoon {
+		t.Fatalf("attested twice within the interval, %v", err)
+	}
+	if err := processor.checkAttester(op, &governor, &subject, 60, 14); err != nil {
+		t.Fatalf("attestation of another attester refused, %v", err)
+	}
+	if err := processor.checkAttester(op, &attester, &subject, 60, 15); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package reputation
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database"
+	accountService "github.com/drep-project/DREP-Chain/pkgs/accounts/service"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// ReputationService keep a reputation score of every address in the state. Accounts attest
+// the reputation of others, and every score decays by the rule set by the governors each
+// time the block height passes a multiple of the rule interval.
+type ReputationService struct {
+	DatabaseService *database.DatabaseService      `service:"database"`
+	ChainService    chain.ChainServiceInterface    `service:"chain"`
+	AccountService  *accountService.AccountService `service:"accounts"`
+	Config          *ReputationConfig
+
+	params *ReputationParams
+	apis   []app.API
+}
+
+func (service *ReputationService) Name() string {
+	return MODULENAME
+}
+
+func (service *ReputationService) Api() []app.API {
+	return service.apis
+}
+
+func (service *ReputationService) CommandFlags() ([]cli.Command, []cli.Flag) {
+	return nil, []cli.Flag{}
+}
+
+func (service *ReputationService) Init(executeContext *app.ExecuteContext) error {
+	params, err := loadReputationParams(service.ChainService.GenesisParams())
+	if err != nil {
+		return err
+	}
+	service.params = params
+	service.ChainService.AddTransactionValidator(&ReputationTxSelector{}, &ReputationTransactionProcessor{params: params})
+	service.apis = []app.API{
+		app.API{
+			Namespace: MODULENAME,
+			Version:   "1.0",
+			Service: &ReputationApi{
+				service: service,
+			},
+			Public: true,
+		},
+	}
+	return nil
+}
+
+func (service *ReputationService) Start(executeContext *app.ExecuteContext) error {
+	return nil
+}
+
+func (service *ReputationService) Stop(executeContext *app.ExecuteContext) error {
+	return nil
+}
+
+func (service *ReputationService) DefaultConfig() *ReputationConfig {
+	return DefaultConfig
+}
+
+// tipState return the reputation state and the height of the best chain tip
+func (service *ReputationService) tipState() (*ReputationOp, uint64, error) {
+	tip := service.ChainService.BestChain().Tip()
+	trieStore, err := store.TrieStoreFromStore(service.DatabaseService.LevelDb(), tip.StateRoot)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &ReputationOp{trieStore, service.params.Decay}, tip.Height, nil
+}
+
+// sendTransaction sign a reputation transaction of msg by a local account and send it
+func (service *ReputationService) sendTransaction(txType types.TxType, from, to *crypto.CommonAddress, msg interface{}, gasPrice, gasLimit *big.Int) (*types.Transaction, error) {
+	data, err := binary.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	nonces := service.AccountService.Nonces()
+	nonce := nonces.Reserve(from)
+	defer nonces.Release(from, nonce)
+	tx := types.NewReputationTransaction(txType, *to, data, gasPrice, gasLimit, nonce)
+	if err := service.AccountService.Wallet.SignTransaction(from, tx); err != nil {
+		return nil, err
+	}
+	if err := service.AccountService.MessageBroadCastor.SendTransaction(tx, true); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
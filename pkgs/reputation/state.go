@@ -0,0 +1,223 @@
+package reputation
+
+import (
+	"encoding/binary"
+
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	drepBinary "github.com/drep-project/binary"
+)
+
+var (
+	scorePrefix        = []byte("reputationScore")
+	historyPrefix      = []byte("reputationHistory")
+	historyCountPrefix = []byte("reputationHistoryCount")
+	rulesKey           = []byte("reputationRules")
+	lastAttestPrefix   = []byte("reputationLastAttest")
+)
+
+// DecayRule remove Rate per mille of every reputation each time the block height passes a
+// multiple of Interval, from Height until the next rule is set
+type DecayRule struct {
+	Height   uint64 `json:"height"`
+	Interval uint64 `json:"interval"`
+	Rate     uint64 `json:"rate"`
+}
+
+// scoreRecord is the reputation of an address, decayed up to Height
+type scoreRecord struct {
+	Score  uint64
+	Height uint64
+}
+
+// Attestation is an entry of the reputation history of an address
+type Attestation struct {
+	Height   uint64               `json:"height"`
+	TxHash   crypto.Hash          `json:"txHash"`
+	Attester crypto.CommonAddress `json:"attester"`
+	Delta    uint64               `json:"delta"`
+	Negative bool                 `json:"negative"`
+	Memo     string               `json:"memo"`
+	Score    uint64               `json:"score"` //Reputation right after the attestation
+}
+
+type ruleList struct {
+	Rules []DecayRule
+}
+
+// ReputationOp read and write the reputation state in the state trie
+type ReputationOp struct {
+	store.StoreInterface
+	initial DecayRule
+}
+
+func concatKey(prefix []byte, parts ...[]byte) []byte {
+	key := append([]byte{}, prefix...)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+func numberBytes(number uint64) []byte {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, number)
+	return value
+}
+
+// rules return the decay rules ordered by height, the initial rule of the params applies
+// until the first rule is set
+func (op *ReputationOp) rules() ([]DecayRule, error) {
+	value, err := op.Get(rulesKey)
+	if err != nil {
+		return nil, err
+	}
+	list := &ruleList{}
+	if value != nil {
+		if err := drepBinary.Unmarshal(value, list); err != nil {
+			return nil, err
+		}
+	}
+	return append([]DecayRule{op.initial}, list.Rules...), nil
+}
+
+func (op *ReputationOp) addRule(rule DecayRule) error {
+	rules, err := op.rules()
+	if err != nil {
+		return err
+	}
+	if len(rules) > 1 && rules[len(rules)-1].Height == rule.Height {
+		return ErrRuleSetAlready
+	}
+	value, err := drepBinary.Marshal(&ruleList{Rules: append(rules[1:], rule)})
+	if err != nil {
+		return err
+	}
+	return op.Put(rulesKey, value)
+}
+
+// record return the reputation of addr as it was last written
+func (op *ReputationOp) record(addr *crypto.CommonAddress) (*scoreRecord, error) {
+	value, err := op.Get(concatKey(scorePrefix, addr[:]))
+	if err != nil {
+		return nil, err
+	}
+	record := &scoreRecord{}
+	if value != nil {
+		if err := drepBinary.Unmarshal(value, record); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+func (op *ReputationOp) putRecord(addr *crypto.CommonAddress, record *scoreRecord) error {
+	value, err := drepBinary.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return op.Put(concatKey(scorePrefix, addr[:]), value)
+}
+
+// Score return the reputation of addr decayed to height
+func (op *ReputationOp) Score(addr *crypto.CommonAddress, height uint64) (uint64, error) {
+	record, err := op.record(addr)
+	if err != nil {
+		return 0, err
+	}
+	rules, err := op.rules()
+	if err != nil {
+		return 0, err
+	}
+	return decay(record.Score, record.Height, height, rules), nil
+}
+
+func (op *ReputationOp) historyCount(addr *crypto.CommonAddress) (uint64, error) {
+	value, err := op.Get(concatKey(historyCountPrefix, addr[:]))
+	if err != nil || len(value) != 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func (op *ReputationOp) appendHistory(addr *crypto.CommonAddress, attestation *Attestation) error {
+	count, err := op.historyCount(addr)
+	if err != nil {
+		return err
+	}
+	value, err := drepBinary.Marshal(attestation)
+	if err != nil {
+		return err
+	}
+	if err := op.Put(concatKey(historyPrefix, addr[:], numberBytes(count)), value); err != nil {
+		return err
+	}
+	return op.Put(concatKey(historyCountPrefix, addr[:]), numberBytes(count+1))
+}
+
+// lastAttest return the height of the latest attestation of attester on subject, false if
+// attester never attested it
+func (op *ReputationOp) lastAttest(attester, subject *crypto.CommonAddress) (uint64, bool, error) {
+	value, err := op.Get(concatKey(lastAttestPrefix, attester[:], subject[:]))
+	if err != nil || len(value) != 8 {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+func (op *ReputationOp) putLastAttest(attester, subject *crypto.CommonAddress, height uint64) error {
+	return op.Put(concatKey(lastAttestPrefix, attester[:], subject[:]), numberBytes(height))
+}
+
+// History return the latest attestations of addr, at most limit, newest first
+func (op *ReputationOp) History(addr *crypto.CommonAddress, limit uint64) ([]*Attestation, error) {
+	count, err := op.historyCount(addr)
+	if err != nil {
+		return nil, err
+	}
+	attestations := []*Attestation{}
+	for i := count; i > 0 && uint64(len(attestations)) < limit; i-- {
+		value, err := op.Get(concatKey(historyPrefix, addr[:], numberBytes(i-1)))
+		if err != nil {
+			return nil, err
+		}
+		attestation := &Attestation{}
+		if err := drepBinary.Unmarshal(value, attestation); err != nil {
+			return nil, err
+		}
+		attestations = append(attestations, attestation)
+	}
+	return attestations, nil
+}
+
+// decay apply the rules to score for every interval boundary passed from height from to
+// height to. A score is only written when it is attested, decaying it here gives the same
+// score as decaying every reputation in each block passing a boundary.
+func decay(score, from, to uint64, rules []DecayRule) uint64 {
+	for i, rule := range rules {
+		if score == 0 {
+			break
+		}
+		start, end := rule.Height, to
+		if i+1 < len(rules) && rules[i+1].Height < end {
+			end = rules[i+1].Height
+		}
+		if start < from {
+			start = from
+		}
+		if start >= end || rule.Interval == 0 || rule.Rate == 0 {
+			continue
+		}
+		for n := end/rule.Interval - start/rule.Interval; n > 0 && score > 0; n-- {
+			cut := score/1000*rule.Rate + score%1000*rule.Rate/1000
+			if cut == 0 {
+				cut = 1
+			}
+			if cut > score {
+				cut = score
+			}
+			score -= cut
+		}
+	}
+	return score
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"github.com/drep-project/DREP-Chain/app"
+)
+
+/*
+name: Admin RPC Api
+usage: Node administration
+prefix:admin
+*/
+type AdminApi struct {
+	rpcService *RpcService
+}
+
+func NewAdminApi(rpcService *RpcService) *AdminApi {
+	return &AdminApi{rpcService: rpcService}
+}
+
+/*
+ name: cacheUsage
+ usage: Get the memory budget and usage of major caches, in bytes
+ params:
+	1. 无
+ return: budget and used bytes of database, trie, txpool, orphans and knownhash caches
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_cacheUsage","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":[{"name":"database","budget":107374182,"used":5636096},{"name":"knownhash","budget":13421772,"used":25600},{"name":"orphans","budget":40265318,"used":0},{"name":"trie","budget":67108864,"used":33554432},{"name":"txpool","budget":40265318,"used":1830}]}
+*/
+func (admin *AdminApi) CacheUsage() []*app.CacheStat {
+	return app.CacheUsage()
+}
+
+/*
+ name: setMaintenance
+ usage: Enter or leave maintenance mode. In maintenance mode transactions sent through rpc, inbound peer connections and new blocks are refused, once the block import in flight finished the databases are flushed and the status turns safe to stop the process
+ params:
+	1. true to enter, false to leave
+ return: maintenance status, poll admin_getMaintenance till it is safe
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_setMaintenance","params":[true], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"on":true,"safe":false,"since":1760500000,"pending":["p2p","chain","database"]}}
+*/
+func (admin *AdminApi) SetMaintenance(on bool) *app.MaintenanceStatus {
+	return app.SetMaintenance(on)
+}
+
+/*
+ name: getMaintenance
+ usage: Get whether the node is in maintenance mode and whether it is safe to stop the process
+ params:
+	1. 无
+ return: maintenance status
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_getMaintenance","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"on":true,"safe":true,"since":1760500000}}
+*/
+func (admin *AdminApi) GetMaintenance() *app.MaintenanceStatus {
+	return app.Maintenance()
+}
+
+/*
+ name: reloadRpc
+ usage: Read the rpc section of the config file again and apply it to the HTTP and websocket endpoints without restarting the node. An endpoint keeping its address keeps serving on the same socket, one moved is bound on the new address before the old is closed. Replaced handlers finish the calls in flight for 10 seconds, websocket subscriptions are dropped then. Certificate files are reloaded on change without this call
+ params:
+	1. 无
+ return: error if an endpoint could not be rebound, it keeps its previous settings then
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_reloadRpc","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":null}
+*/
+func (admin *AdminApi) ReloadRpc() error {
+	return admin.rpcService.Reload()
+}
+
+/*
+ name: status
+ usage: Get the configuration of the running node: git commit, services in start order and a section per service with chain id, genesis, fork heights, enode, protocol capabilities, rpc endpoints and producer status. Compare the result of several nodes to spot configuration drift
+ params:
+	1. 无
+ return: node status
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"admin_status","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"services":["database","p2p","rpc","log","chain","blockmgr","accounts","bft"],"sections":{"bft":{"miner":true,"address":"0xaea2d5d7bff5ed58a1ff5a8c4a2a2a6f2a1a66e1","producer":true,"producerNum":7},"chain":{"chainId":0,"rootChain":0,"genesisHash":"0x4ba4e4b3e3b4b1bc4f4b2fc6e4f3f2ed6b1d4b9a8e5d3b2a6e1c0f9e8d7c6b5a","genesisAddr":"0xaea2d5d7bff5ed58a1ff5a8c4a2a2a6f2a1a66e1","height":1024,"encoding":1,"forks":{"txRootFork":500000,"chainIdFork":500000}},"p2p":{"enode":"enode://b7f5...@127.0.0.1:10086","listenAddr":"0.0.0.0:10086","chainId":0,"role":"producer","maxPeers":50,"noDiscovery":false,"peerExchange":true,"permissioned":false,"caps":["blockMgr/1","pex/1"]},"rpc":{"http":"http://127.0.0.1:10085","httpModules":["chain","account","admin"],"ipc":"/root/.drep/drep.ipc"}}}}
+*/
+func (admin *AdminApi) Status() *app.NodeStatus {
+	return admin.rpcService.executeContext.Status()
+}
@@ -17,14 +17,36 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
 
 	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/rpc"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []app.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) (net.Listener, *rpc.Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules,
+// served over https if a tls key pair is configured
+func StartHTTPEndpoint(endpoint string, apis []app.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, slowCall SlowCallConfig, tlsConfig HTTPTLSConfig) (net.Listener, *rpc.Server, error) {
+	// Load the certificate first, a broken key pair must not leave a plain endpoint behind
+	certConfig, err := loadTLS(tlsConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpServer, handler, err := newHTTPServer(apis, modules, cors, vhosts, timeouts, slowCall)
+	if err != nil {
+		return nil, nil, err
+	}
+	// All APIs registered, start the HTTP listener
+	listener, err := serveEndpoint(endpoint, httpServer, certConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return listener, handler, nil
+}
+
+// newHTTPServer registers the apis allowed by modules and wraps them into the HTTP RPC server
+func newHTTPServer(apis []app.API, modules []string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts, slowCall SlowCallConfig) (*http.Server, *rpc.Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -32,29 +54,40 @@ func StartHTTPEndpoint(endpoint string, apis []app.API, modules []string, cors [
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	meter := newCallMeter(slowCall)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
-			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			if err := meter.registerAPI(handler, api); err != nil {
 				return nil, nil, err
 			}
 			log.WithField("namespace", api.Namespace).Debug("HTTP registered")
 		}
 	}
+	httpServer := rpc.NewHTTPServer(cors, vhosts, timeouts, handler)
+	httpServer.Handler = newGuardHandler(newHTTPHandler(handler, meter, cors), cors, vhosts)
+	return httpServer, handler, nil
+}
+
+// StartWSEndpoint starts a websocket endpoint, served over tls if a certificate is configured
+func StartWSEndpoint(endpoint string, apis []app.API, modules []string, wsOrigins []string, exposeAll bool, slowCall SlowCallConfig, tlsConfig HTTPTLSConfig) (net.Listener, *rpc.Server, error) {
+	certConfig, err := loadTLS(tlsConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	wsServer, handler, err := newWSServer(apis, modules, wsOrigins, exposeAll, slowCall)
+	if err != nil {
+		return nil, nil, err
+	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, err := serveEndpoint(endpoint, wsServer, certConfig)
+	if err != nil {
 		return nil, nil, err
 	}
-	go rpc.NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
-	return listener, handler, err
+	return listener, handler, nil
 }
 
-// StartWSEndpoint starts a websocket endpoint
-func StartWSEndpoint(endpoint string, apis []app.API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *rpc.Server, error) {
-
+// newWSServer registers the apis allowed by modules and wraps them into the websocket RPC server
+func newWSServer(apis []app.API, modules []string, wsOrigins []string, exposeAll bool, slowCall SlowCallConfig) (*http.Server, *rpc.Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -62,33 +95,33 @@ func StartWSEndpoint(endpoint string, apis []app.API, modules []string, wsOrigin
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	meter := newCallMeter(slowCall)
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
-			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			if err := meter.registerAPI(handler, api); err != nil {
 				return nil, nil, err
 			}
 			log.WithField("service", api.Service).WithField("namespace", api.Namespace).Debug("WebSocket registered")
 		}
 	}
-	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
-		return nil, nil, err
-	}
-	go rpc.NewWSServer(wsOrigins, handler).Serve(listener)
-	return listener, handler, err
+	return &http.Server{Handler: newWebsocketHandler(handler, meter, wsOrigins)}, handler, nil
+}
 
+// loadTLS returns nil if tls is not configured
+func loadTLS(tlsConfig HTTPTLSConfig) (*tls.Config, error) {
+	if !tlsConfig.Enabled() {
+		return nil, nil
+	}
+	return tlsConfig.load()
 }
 
 // StartIPCEndpoint starts an IPC endpoint.
-func StartIPCEndpoint(ipcEndpoint string, apis []app.API) (net.Listener, *rpc.Server, error) {
+func StartIPCEndpoint(ipcEndpoint string, apis []app.API, slowCall SlowCallConfig) (net.Listener, *rpc.Server, error) {
 	// Register all the APIs exposed by the services.
 	handler := rpc.NewServer()
+	meter := newCallMeter(slowCall)
 	for _, api := range apis {
-		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+		if err := meter.registerAPI(handler, api); err != nil {
 			return nil, nil, err
 		}
 		log.WithField("namespace", api.Namespace).Debug("IPC registered")
@@ -98,6 +131,6 @@ func StartIPCEndpoint(ipcEndpoint string, apis []app.API) (net.Listener, *rpc.Se
 	if err != nil {
 		return nil, nil, err
 	}
-	go handler.ServeListener(listener)
+	go serveListener(handler, meter, listener)
 	return listener, handler, nil
 }
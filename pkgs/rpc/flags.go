@@ -27,7 +27,7 @@ var (
 	}
 	HTTPCORSDomainFlag = cli.StringFlag{
 		Name:  "httpcorsdomain",
-		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Usage: "Comma separated list of origins from which to accept cross origin requests (server enforced). Accepts '*' wildcard.",
 		Value: "",
 	}
 	HTTPVirtualHostsFlag = cli.StringFlag{
@@ -40,6 +40,30 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	HTTPSlowCallFlag = cli.DurationFlag{
+		Name:  "httpslowcall",
+		Usage: "Log RPC calls slower than this duration, over any transport (0 = disabled)",
+		Value: 0,
+	}
+	HTTPSlowCallSampleFlag = cli.Float64Flag{
+		Name:  "httpslowcallsample",
+		Usage: "Fraction of slow RPC calls written to the log",
+		Value: 1,
+	}
+	HTTPTLSCertFlag = cli.StringFlag{
+		Name:  "httptlscert",
+		Usage: "PEM certificate file to serve the HTTP-RPC server over https (relative to the datadir)",
+		Value: "",
+	}
+	HTTPTLSKeyFlag = cli.StringFlag{
+		Name:  "httptlskey",
+		Usage: "PEM private key file of the HTTP-RPC https certificate (relative to the datadir)",
+		Value: "",
+	}
+	UnitAmountsFlag = cli.BoolFlag{
+		Name:  "rpcunits",
+		Usage: "Accept amounts with units (e.g. 1.5drep, 10gwei) wherever RPC takes a hex quantity",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -72,6 +96,21 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	WSTLSCertFlag = cli.StringFlag{
+		Name:  "wstlscert",
+		Usage: "PEM certificate file to serve the WS-RPC server over wss (relative to the datadir)",
+		Value: "",
+	}
+	WSTLSKeyFlag = cli.StringFlag{
+		Name:  "wstlskey",
+		Usage: "PEM private key file of the WS-RPC wss certificate (relative to the datadir)",
+		Value: "",
+	}
+	RPCACMEDomainsFlag = cli.StringFlag{
+		Name:  "rpcacmedomains",
+		Usage: "Comma separated domains to obtain the HTTP-RPC and WS-RPC certificates for from Let's Encrypt (the listeners must be reachable on port 443)",
+		Value: "",
+	}
 	// RPC settings
 	RESTEnabledFlag = cli.BoolFlag{
 		Name:  "rest",
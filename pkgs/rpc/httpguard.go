@@ -0,0 +1,200 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	certCheckInterval = 10 * time.Second // interval between two checks of the certificate files for changes
+)
+
+var (
+	ErrTLSKeyPair = errors.New("both tls certificate and key file are required")
+	ErrTLSSource  = errors.New("tls certificate files and acme domains can not be used at the same time")
+)
+
+// HTTPTLSConfig holds the certificate used to serve http or websocket rpc over tls, either an
+// operator provided key pair or certificates obtained from an ACME CA such as Let's Encrypt
+type HTTPTLSConfig struct {
+	CertFile     string   // PEM encoded certificate chain
+	KeyFile      string   // PEM encoded private key of the certificate
+	ACMEDomains  []string // domains to obtain certificates for (empty = ACME disabled)
+	ACMECacheDir string   // directory the ACME account key and certificates are kept in
+}
+
+// Enabled report whether tls is configured
+func (c HTTPTLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.ACMEDomains) > 0
+}
+
+// load prepare the tls config, a half configured pair is an error rather than a silent fallback to plain http.
+// Key pair files are read again when they change, so a renewed certificate is served without a restart
+func (c HTTPTLSConfig) load() (*tls.Config, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	if len(c.ACMEDomains) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(c.ACMEDomains...),
+			Cache:      autocert.DirCache(c.ACMECacheDir),
+		}
+		config := manager.TLSConfig()
+		config.MinVersion = tls.VersionTLS12
+		return config, nil
+	}
+	reloader := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}, nil
+}
+
+// check report a configuration load would refuse without reading any file
+func (c HTTPTLSConfig) check() error {
+	if len(c.ACMEDomains) > 0 {
+		if c.CertFile != "" || c.KeyFile != "" {
+			return ErrTLSSource
+		}
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return ErrTLSKeyPair
+	}
+	return nil
+}
+
+// certReloader serve the key pair in the files, read again once a file changed. A pair that fails
+// to load, e.g. the key written before the certificate, leaves the previous one in use
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	lock    sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if now := time.Now(); now.Sub(r.checked) >= certCheckInterval {
+		r.checked = now
+		if err := r.reload(); err != nil {
+			log.WithField("cert", r.certFile).WithField("err", err).Error("Reload rpc tls certificate")
+		}
+	}
+	return r.cert, nil
+}
+
+func (r *certReloader) reload() error {
+	var modTime time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	if r.cert != nil && modTime.Equal(r.modTime) {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert != nil {
+		log.WithField("cert", r.certFile).Info("Rpc tls certificate reloaded")
+	}
+	r.cert, r.modTime = &cert, modTime
+	return nil
+}
+
+// guardHandler reject requests whose Host header is not an allowed virtual host, and
+// cross origin requests from origins not in the cors list. The cors headers of the
+// inner handler are only honored by browsers, the guard makes the server refuse to
+// execute such calls at all, which also closes the dns rebinding hole
+type guardHandler struct {
+	next    http.Handler
+	origins map[string]struct{}
+	vhosts  map[string]struct{}
+}
+
+func newGuardHandler(next http.Handler, cors []string, vhosts []string) http.Handler {
+	h := &guardHandler{
+		next:    next,
+		origins: make(map[string]struct{}),
+		vhosts:  make(map[string]struct{}),
+	}
+	for _, origin := range cors {
+		h.origins[strings.TrimRight(strings.ToLower(origin), "/")] = struct{}{}
+	}
+	for _, vhost := range vhosts {
+		h.vhosts[strings.ToLower(vhost)] = struct{}{}
+	}
+	return h
+}
+
+func (h *guardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.validHost(r.Host) {
+		log.WithField("host", r.Host).WithField("remote", r.RemoteAddr).Debug("rpc request with invalid host rejected")
+		http.Error(w, "invalid host specified", http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !h.validOrigin(origin, r.Host) {
+		log.WithField("origin", origin).WithField("remote", r.RemoteAddr).Debug("rpc request with invalid origin rejected")
+		http.Error(w, "invalid origin specified", http.StatusForbidden)
+		return
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// validHost accept ip addresses, which can not be rebound, and the configured virtual hosts
+func (h *guardHandler) validHost(hostport string) bool {
+	// http/1.0 clients may omit the header, nothing to rebind
+	if hostport == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if _, ok := h.vhosts["*"]; ok {
+		return true
+	}
+	_, ok := h.vhosts[strings.ToLower(host)]
+	return ok
+}
+
+// validOrigin accept same origin requests and the configured cors origins
+func (h *guardHandler) validOrigin(origin string, host string) bool {
+	if _, ok := h.origins["*"]; ok {
+		return true
+	}
+	origin = strings.TrimRight(strings.ToLower(origin), "/")
+	if _, ok := h.origins[origin]; ok {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,157 @@
+package rpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drep-project/rpc"
+)
+
+func TestGuardHandlerHost(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := newGuardHandler(next, nil, []string{"localhost", "node.example.org"})
+
+	cases := []struct {
+		host string
+		code int
+	}{
+		{"localhost:10085", http.StatusOK},
+		{"NODE.example.org", http.StatusOK},
+		{"127.0.0.1:10085", http.StatusOK},
+		{"[::1]:10085", http.StatusOK},
+		{"evil.example.org:10085", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Host = c.host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != c.code {
+			t.Errorf("host %s: got status %d, want %d", c.host, rec.Code, c.code)
+		}
+	}
+
+	wildcard := newGuardHandler(next, nil, []string{"*"})
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Host = "evil.example.org"
+	rec := httptest.NewRecorder()
+	wildcard.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("wildcard vhost: got status %d", rec.Code)
+	}
+}
+
+func TestGuardHandlerOrigin(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := newGuardHandler(next, []string{"https://wallet.example.org/"}, []string{"*"})
+
+	cases := []struct {
+		origin string
+		code   int
+	}{
+		{"", http.StatusOK},
+		{"https://wallet.example.org", http.StatusOK},
+		{"http://localhost:10085", http.StatusOK}, // same origin
+		{"https://evil.example.org", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Host = "localhost:10085"
+		if c.origin != "" {
+			req.Header.Set("Origin", c.origin)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != c.code {
+			t.Errorf("origin %q: got status %d, want %d", c.origin, rec.Code, c.code)
+		}
+	}
+}
+
+func TestHTTPTLSConfig(t *testing.T) {
+	if (HTTPTLSConfig{}).Enabled() {
+		t.Fatal("empty config should be disabled")
+	}
+	if _, err := (HTTPTLSConfig{CertFile: "cert.pem"}).load(); err != ErrTLSKeyPair {
+		t.Fatalf("got %v, want %v", err, ErrTLSKeyPair)
+	}
+	if _, _, err := StartHTTPEndpoint("127.0.0.1:0", nil, nil, nil, nil, rpc.HTTPTimeouts{}, SlowCallConfig{}, HTTPTLSConfig{KeyFile: "key.pem"}); err != ErrTLSKeyPair {
+		t.Fatalf("got %v, want %v", err, ErrTLSKeyPair)
+	}
+}
+
+func TestStartHTTPEndpointTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := writeTestCert(t, dir)
+
+	listener, handler, err := StartHTTPEndpoint("127.0.0.1:0", nil, nil, nil, []string{"localhost"}, rpc.HTTPTimeouts{}, SlowCallConfig{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Stop()
+	defer listener.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("https request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.TLS == nil {
+		t.Fatal("response not served over tls")
+	}
+}
+
+// writeTestCert create a self signed certificate for localhost in dir
+func writeTestCert(t *testing.T, dir string) HTTPTLSConfig {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := HTTPTLSConfig{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	}
+	if err := ioutil.WriteFile(config.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(config.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/rpc"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxLoggedParams truncate params printed in slow call logs
+	maxLoggedParams = 256
+	// unknownMethod is the bucket of calls to methods not registered in the server, method names
+	// are chosen by the client and must not create metrics of their own
+	unknownMethod = "unknown"
+)
+
+// SlowCallConfig controls how slow rpc calls are sampled into logs
+type SlowCallConfig struct {
+	Threshold  time.Duration // calls slower than threshold are candidates for logging, 0 disable
+	SampleRate float64       // fraction of slow calls written to log, in range (0,1]
+}
+
+// jsonCall is the subset of a json rpc request needed for accounting
+type jsonCall struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// jsonResult is the subset of a json rpc response needed for accounting
+type jsonResult struct {
+	ID    json.RawMessage `json:"id"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callMeter record call count, error count and latency of each method served by one rpc server,
+// whatever the transport. It sees the calls at the server codec, the point all transports share
+type callMeter struct {
+	methods  map[string]struct{}
+	slowCall SlowCallConfig
+}
+
+func newCallMeter(slowCall SlowCallConfig) *callMeter {
+	m := &callMeter{methods: make(map[string]struct{}), slowCall: slowCall}
+	m.register(rpc.MetadataApi, &rpc.RPCService{})
+	return m
+}
+
+// register add the methods the server exposes for the service, named the way the server resolves them
+func (m *callMeter) register(namespace string, service interface{}) {
+	typ := reflect.TypeOf(service)
+	for i := 0; i < typ.NumMethod(); i++ {
+		name := []rune(typ.Method(i).Name)
+		name[0] = unicode.ToLower(name[0])
+		m.methods[namespace+rpc.ServiceMethodSeparator+string(name)] = struct{}{}
+	}
+	m.methods[namespace+rpc.SubscribeMethodSuffix] = struct{}{}
+	m.methods[namespace+rpc.UnsubscribeMethodSuffix] = struct{}{}
+}
+
+// registerAPI register the api into the server and the meter
+func (m *callMeter) registerAPI(handler *rpc.Server, api app.API) error {
+	if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+		return err
+	}
+	m.register(api.Namespace, api.Service)
+	return nil
+}
+
+// metricName return the method if it resolves in the server, unknown otherwise
+func (m *callMeter) metricName(method string) string {
+	if _, ok := m.methods[method]; ok {
+		return method
+	}
+	return unknownMethod
+}
+
+// account update the global and per method metrics of one call
+func (m *callMeter) account(method string, elapsed time.Duration, failed bool) {
+	if !metrics.Enabled {
+		return
+	}
+	name := m.metricName(method)
+	metrics.GetOrRegisterCounter("rpc/requests", nil).Inc(1)
+	metrics.GetOrRegisterCounter("rpc/calls/"+name, nil).Inc(1)
+	metrics.GetOrRegisterTimer("rpc/duration/"+name, nil).Update(elapsed)
+	if failed {
+		metrics.GetOrRegisterCounter("rpc/failure", nil).Inc(1)
+		metrics.GetOrRegisterCounter("rpc/errors/"+name, nil).Inc(1)
+	}
+}
+
+// traceSlowCall write the slow call into log if it is selected by sample rate
+func (m *callMeter) traceSlowCall(remote string, call *jsonCall, elapsed time.Duration, failed bool) {
+	if m.slowCall.SampleRate < 1 && rand.Float64() >= m.slowCall.SampleRate {
+		return
+	}
+	params := string(call.Params)
+	if len(params) > maxLoggedParams {
+		params = params[:maxLoggedParams] + "..."
+	}
+	log.WithField("method", call.Method).
+		WithField("elapsed", elapsed).
+		WithField("failed", failed).
+		WithField("remote", remote).
+		WithField("params", params).
+		Warn("slow rpc call")
+}
+
+// enabled report whether calls need to be tracked at all
+func (m *callMeter) enabled() bool {
+	return metrics.Enabled || m.slowCall.Threshold > 0
+}
+
+// pendingCall is a call read from a connection and not answered yet
+type pendingCall struct {
+	call  *jsonCall
+	start time.Time
+}
+
+// connMeter track the calls of one connection from the request read to the response written
+type connMeter struct {
+	meter  *callMeter
+	remote string
+
+	lock    sync.Mutex
+	pending map[string]*pendingCall // keyed by request id, responses of a connection come in any order
+}
+
+func (m *callMeter) newConnMeter(remote string) *connMeter {
+	return &connMeter{meter: m, remote: remote, pending: make(map[string]*pendingCall)}
+}
+
+// newCodec return the server codec of a connection, encode write one marshaled message and decode
+// read one message. Each message passes the meter on its way
+func (m *callMeter) newCodec(rwc io.ReadWriteCloser, remote string, encode func([]byte) error, decode func(interface{}) error) rpc.ServerCodec {
+	if !m.enabled() {
+		return rpc.NewCodec(rwc, func(v interface{}) error {
+			msg, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			return encode(msg)
+		}, decode)
+	}
+	cm := m.newConnMeter(remote)
+	return rpc.NewCodec(rwc, func(v interface{}) error {
+		msg, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		cm.finish(msg)
+		return encode(msg)
+	}, func(v interface{}) error {
+		if err := decode(v); err != nil {
+			return err
+		}
+		if msg, ok := v.(*json.RawMessage); ok {
+			cm.begin(*msg)
+		}
+		return nil
+	})
+}
+
+// begin start the clock of the calls in a request
+func (cm *connMeter) begin(msg []byte) {
+	start := time.Now()
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+	for _, call := range parseCalls(msg) {
+		// the server answers ids other than numbers and strings with a null id, they would never leave pending
+		if call.Method == "" || len(call.ID) == 0 || !(call.ID[0] == '"' || call.ID[0] == '-' || (call.ID[0] >= '0' && call.ID[0] <= '9')) {
+			continue
+		}
+		cm.pending[string(call.ID)] = &pendingCall{call: call, start: start}
+	}
+}
+
+// finish account the calls answered by a response, notifications carry no id and are skipped
+func (cm *connMeter) finish(msg []byte) {
+	now := time.Now()
+	results := parseResults(msg)
+	if len(results) == 0 {
+		return
+	}
+	cm.lock.Lock()
+	answered := make([]*pendingCall, 0, len(results))
+	failed := make([]bool, 0, len(results))
+	for _, result := range results {
+		if pending, ok := cm.pending[string(result.ID)]; ok {
+			delete(cm.pending, string(result.ID))
+			answered = append(answered, pending)
+			failed = append(failed, result.Error != nil)
+		}
+	}
+	cm.lock.Unlock()
+
+	for i, pending := range answered {
+		elapsed := now.Sub(pending.start)
+		cm.meter.account(pending.call.Method, elapsed, failed[i])
+		if cm.meter.slowCall.Threshold > 0 && elapsed >= cm.meter.slowCall.Threshold {
+			cm.meter.traceSlowCall(cm.remote, pending.call, elapsed, failed[i])
+		}
+	}
+}
+
+// parseCalls decode single or batch request, undecodable body yield no calls
+func parseCalls(body []byte) []*jsonCall {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) == 0 {
+		return nil
+	}
+	if body[0] == '[' {
+		calls := []*jsonCall{}
+		if err := json.Unmarshal(body, &calls); err != nil {
+			return nil
+		}
+		return calls
+	}
+	call := &jsonCall{}
+	if err := json.Unmarshal(body, call); err != nil {
+		return nil
+	}
+	return []*jsonCall{call}
+}
+
+// parseResults decode single or batch response, undecodable body yield no results
+func parseResults(body []byte) []*jsonResult {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	if len(body) == 0 {
+		return nil
+	}
+	results := []*jsonResult{}
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &results); err != nil {
+			return nil
+		}
+		return results
+	}
+	result := &jsonResult{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil
+	}
+	return append(results, result)
+}
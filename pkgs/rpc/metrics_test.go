@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/rpc"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type MeterTestService struct{}
+
+func (s *MeterTestService) Echo(v int) int { return v }
+
+func (s *MeterTestService) Fail() error { return errors.New("fail") }
+
+func newMeterTestServer(t *testing.T) (*rpc.Server, *callMeter) {
+	server := rpc.NewServer()
+	meter := newCallMeter(SlowCallConfig{})
+	if err := meter.registerAPI(server, app.API{Namespace: "metertest", Service: &MeterTestService{}}); err != nil {
+		t.Fatal(err)
+	}
+	return server, meter
+}
+
+func TestParseCalls(t *testing.T) {
+	calls := parseCalls([]byte(`{"jsonrpc":"2.0","method":"chain_getMaxHeight","params":[],"id":1}`))
+	if len(calls) != 1 || calls[0].Method != "chain_getMaxHeight" {
+		t.Fatalf("unexpected single call %v", calls)
+	}
+
+	calls = parseCalls([]byte(` [{"method":"a_b","id":1},{"method":"c_d","id":2}]`))
+	if len(calls) != 2 || calls[0].Method != "a_b" || calls[1].Method != "c_d" {
+		t.Fatalf("unexpected batch calls %v", calls)
+	}
+
+	if calls := parseCalls([]byte(`not json`)); len(calls) != 0 {
+		t.Fatalf("expect no calls from invalid body, got %d", len(calls))
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	results := parseResults([]byte(`[{"id":1,"result":1},{"id":2,"error":{"code":-32601,"message":"not found"}}]`))
+	if len(results) != 2 || results[0].Error != nil || results[1].Error == nil {
+		t.Fatalf("unexpected results %v", results)
+	}
+}
+
+func TestCallMeterConnection(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	server, meter := newMeterTestServer(t)
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	go server.ServeCodec(meter.newCodec(serverConn, "pipe", newlineEncoder(serverConn), jsonDecoder(serverConn)), rpc.OptionMethodInvocation)
+
+	requests := []string{
+		`{"jsonrpc":"2.0","method":"metertest_echo","params":[1],"id":1}`,
+		`{"jsonrpc":"2.0","method":"metertest_fail","id":"two"}`,
+		`{"jsonrpc":"2.0","method":"metertest_doesNotExist","id":3}`,
+	}
+	dec := json.NewDecoder(clientConn)
+	for _, req := range requests {
+		if _, err := clientConn.Write([]byte(req)); err != nil {
+			t.Fatal(err)
+		}
+		var resp json.RawMessage
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count := metrics.GetOrRegisterCounter("rpc/calls/metertest_echo", nil).Count(); count != 1 {
+		t.Fatalf("expect 1 echo call, got %d", count)
+	}
+	if count := metrics.GetOrRegisterTimer("rpc/duration/metertest_echo", nil).Count(); count != 1 {
+		t.Fatalf("expect 1 timing sample, got %d", count)
+	}
+	if count := metrics.GetOrRegisterCounter("rpc/errors/metertest_fail", nil).Count(); count != 1 {
+		t.Fatalf("expect 1 failed call, got %d", count)
+	}
+	if count := metrics.GetOrRegisterCounter("rpc/errors/"+unknownMethod, nil).Count(); count < 1 {
+		t.Fatal("unregistered method not accounted as unknown")
+	}
+	if metrics.DefaultRegistry.Get("rpc/calls/metertest_doesNotExist") != nil {
+		t.Fatal("metric created for a method chosen by the client")
+	}
+}
+
+func TestCallMeterHTTP(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	server, meter := newMeterTestServer(t)
+	handler := newHTTPHandler(server, meter, nil)
+
+	before := metrics.GetOrRegisterCounter("rpc/calls/metertest_echo", nil).Count()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`[{"jsonrpc":"2.0","method":"metertest_echo","params":[2],"id":1},{"jsonrpc":"2.0","method":"metertest_echo","params":[3],"id":2}]`))
+	req.Header.Set("content-type", rpc.ContentType)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`"result":3`)) {
+		t.Fatalf("unexpected response %s", rec.Body.String())
+	}
+	if count := metrics.GetOrRegisterCounter("rpc/calls/metertest_echo", nil).Count(); count != before+2 {
+		t.Fatalf("expect 2 more echo calls, got %d", count-before)
+	}
+}
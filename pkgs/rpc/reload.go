@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/drep-project/rpc"
+)
+
+const (
+	rebindDrain = 10 * time.Second // time the calls in flight on a replaced handler get to finish
+)
+
+var (
+	ErrNotStarted = errors.New("rpc endpoints are not started")
+)
+
+// servingListener keep an rpc endpoint bound while its handler and tls config are replaced.
+// Accepted connections are wrapped with the tls config current at accept time, plain if
+// there is none, and every request is served by the handler current at request time
+type servingListener struct {
+	net.Listener
+	tlsConfig atomic.Value // servingTLS
+	handler   atomic.Value // servingHandler
+}
+
+type servingTLS struct {
+	config *tls.Config
+}
+
+type servingHandler struct {
+	http.Handler
+}
+
+// serveEndpoint bind endpoint and serve it with srv, whose handler can be replaced later
+func serveEndpoint(endpoint string, srv *http.Server, tlsConfig *tls.Config) (*servingListener, error) {
+	inner, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	listener := &servingListener{Listener: inner}
+	listener.replace(srv.Handler, tlsConfig)
+	srv.Handler = listener
+	go srv.Serve(listener)
+	return listener, nil
+}
+
+// replace serve the connections accepted from now on with tlsConfig and the requests with handler
+func (l *servingListener) replace(handler http.Handler, tlsConfig *tls.Config) {
+	l.handler.Store(servingHandler{handler})
+	l.tlsConfig.Store(servingTLS{tlsConfig})
+}
+
+func (l *servingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if config := l.tlsConfig.Load().(servingTLS).config; config != nil {
+		return tls.Server(conn, config), nil
+	}
+	return conn, nil
+}
+
+func (l *servingListener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.handler.Load().(servingHandler).ServeHTTP(w, r)
+}
+
+// Reload reads the rpc section of the config file again, command line flags still taking
+// precedence, and applies it to the running http and websocket endpoints. An endpoint that
+// stays on its address keeps the socket and swaps handler and certificate, one moved to
+// another address is bound there before the old socket is closed, so the node keeps serving
+// throughout. An endpoint failing to rebind keeps its previous settings
+func (rpcService *RpcService) Reload() error {
+	phaseConfig, err := rpcService.executeContext.ReadConfig()
+	if err != nil {
+		return err
+	}
+	config := rpcService.DefaultConfig()
+	if phase, ok := phaseConfig[MODULENAME]; ok {
+		if err := json.Unmarshal(phase, config); err != nil {
+			return err
+		}
+	}
+
+	rpcService.lock.Lock()
+	defer rpcService.lock.Unlock()
+	if rpcService.RpcAPIs == nil {
+		return ErrNotStarted
+	}
+	config.IPCEnabled, config.IPCPath = rpcService.Config.IPCEnabled, rpcService.Config.IPCPath
+	rpcService.Config = config
+	homeDir := rpcService.executeContext.CommonConfig.HomeDir
+	rpcService.setHTTP(rpcService.executeContext.Cli, homeDir)
+	rpcService.setWS(rpcService.executeContext.Cli, homeDir)
+	rpcService.setTLS(rpcService.executeContext.Cli, homeDir)
+
+	if err := rpcService.rebindHTTP(config.HTTPEndpoint()); err != nil {
+		return fmt.Errorf("rebind http endpoint: %v", err)
+	}
+	if err := rpcService.rebindWS(config.WSEndpoint()); err != nil {
+		return fmt.Errorf("rebind websocket endpoint: %v", err)
+	}
+	return nil
+}
+
+func (rpcService *RpcService) rebindHTTP(endpoint string) error {
+	config := rpcService.Config
+	if !config.HTTPEnabled || endpoint == "" {
+		rpcService.StopHTTP()
+		return nil
+	}
+	listener, _ := rpcService.HttpListener.(*servingListener)
+	if listener == nil || endpoint != rpcService.HttpEndpoint {
+		oldListener, oldHandler := rpcService.HttpListener, rpcService.HttpHandler
+		if err := rpcService.StartHTTP(endpoint, rpcService.RpcAPIs, config.HTTPModules, config.HTTPCors, config.HTTPVirtualHosts, config.HTTPTimeouts); err != nil {
+			return err
+		}
+		if oldListener != nil {
+			oldListener.Close()
+		}
+		retireHandler(oldHandler)
+		return nil
+	}
+
+	certConfig, err := loadTLS(rpcService.HttpTLS)
+	if err != nil {
+		return err
+	}
+	httpServer, handler, err := newHTTPServer(rpcService.RpcAPIs, config.HTTPModules, config.HTTPCors, config.HTTPVirtualHosts, rpc.HTTPTimeouts{}, rpcService.SlowCall)
+	if err != nil {
+		return err
+	}
+	listener.replace(httpServer.Handler, certConfig)
+	retireHandler(rpcService.HttpHandler)
+	rpcService.HttpHandler = handler
+	log.WithField("url", fmt.Sprintf("%s://%s", rpcService.httpScheme(), endpoint)).Info("HTTP endpoint reloaded")
+	return nil
+}
+
+func (rpcService *RpcService) rebindWS(endpoint string) error {
+	config := rpcService.Config
+	if !config.WSEnabled || endpoint == "" {
+		rpcService.StopWS()
+		return nil
+	}
+	listener, _ := rpcService.WsListener.(*servingListener)
+	if listener == nil || endpoint != rpcService.WsEndpoint {
+		oldListener, oldHandler := rpcService.WsListener, rpcService.WsHandler
+		if err := rpcService.StartWS(endpoint, rpcService.RpcAPIs, config.WSModules, config.WSOrigins, config.WSExposeAll); err != nil {
+			return err
+		}
+		if oldListener != nil {
+			oldListener.Close()
+		}
+		retireHandler(oldHandler)
+		return nil
+	}
+
+	certConfig, err := loadTLS(rpcService.WsTLS)
+	if err != nil {
+		return err
+	}
+	wsServer, handler, err := newWSServer(rpcService.RpcAPIs, config.WSModules, config.WSOrigins, config.WSExposeAll, rpcService.SlowCall)
+	if err != nil {
+		return err
+	}
+	listener.replace(wsServer.Handler, certConfig)
+	retireHandler(rpcService.WsHandler)
+	rpcService.WsHandler = handler
+	log.WithField("url", fmt.Sprintf("%s://%s", rpcService.wsScheme(), endpoint)).Info("WebSocket endpoint reloaded")
+	return nil
+}
+
+// retireHandler stop a replaced handler once the calls in flight had time to finish,
+// websocket subscriptions on it are closed then and clients reconnect
+func retireHandler(handler *rpc.Server) {
+	if handler != nil {
+		time.AfterFunc(rebindDrain, handler.Stop)
+	}
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestServingListenerReplace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-rebind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	reply := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) })
+	}
+	listener, err := serveEndpoint("127.0.0.1:0", &http.Server{Handler: reply("plain")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+	get := func(scheme string) string {
+		resp, err := client.Get(scheme + "://" + listener.Addr().String())
+		if err != nil {
+			t.Fatalf("%s request failed: %v", scheme, err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := get("http"); body != "plain" {
+		t.Fatalf("got %q, want plain", body)
+	}
+
+	// the same socket serves tls and the new handler once replaced
+	certConfig, err := writeTestCert(t, dir).load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.replace(reply("secure"), certConfig)
+	if body := get("https"); body != "secure" {
+		t.Fatalf("got %q, want secure", body)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := writeTestCert(t, dir)
+
+	reloader := &certReloader{certFile: config.CertFile, keyFile: config.KeyFile}
+	if err := reloader.reload(); err != nil {
+		t.Fatal(err)
+	}
+	first := reloader.cert.Certificate[0]
+
+	// a renewed certificate is served after the next check
+	writeTestCert(t, dir)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(config.CertFile, later, later)
+	reloader.checked = time.Time{}
+	cert, err := reloader.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(cert.Certificate[0], first) {
+		t.Fatal("renewed certificate not loaded")
+	}
+
+	// a broken pair keeps the current certificate
+	ioutil.WriteFile(config.KeyFile, []byte("broken"), 0600)
+	os.Chtimes(config.KeyFile, later.Add(time.Minute), later.Add(time.Minute))
+	reloader.checked = time.Time{}
+	if broken, _ := reloader.GetCertificate(nil); broken != cert {
+		t.Fatal("broken key pair replaced the certificate")
+	}
+
+	if _, err := (HTTPTLSConfig{CertFile: "cert.pem", ACMEDomains: []string{"node.example.org"}}).load(); err != ErrTLSSource {
+		t.Fatalf("got %v, want %v", err, ErrTLSSource)
+	}
+}
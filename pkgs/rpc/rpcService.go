@@ -11,6 +11,7 @@ import (
 
 	"github.com/AsynkronIT/protoactor-go/actor"
 	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/common"
 
 	"github.com/drep-project/rpc"
 	"gopkg.in/urfave/cli.v1"
@@ -18,6 +19,8 @@ import (
 
 const (
 	ClientIdentifier = "drep"
+
+	defaultACMECacheDir = "acme"
 )
 
 type RpcService struct {
@@ -41,8 +44,13 @@ type RpcService struct {
 	RestEndpoint   string              // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	RestController *rpc.RestController // Websocket RPC listener socket to server API requests
 
-	lock   sync.RWMutex
-	Config *rpc.RpcConfig
+	SlowCall SlowCallConfig // sampling of slow RPC calls into log
+	HttpTLS  HTTPTLSConfig  // certificate to serve HTTP RPC over https (empty = plain http)
+	WsTLS    HTTPTLSConfig  // certificate to serve websocket RPC over wss (empty = plain ws)
+
+	executeContext *app.ExecuteContext
+	lock           sync.RWMutex
+	Config         *rpc.RpcConfig
 }
 
 func (rpcService *RpcService) Name() string {
@@ -50,14 +58,27 @@ func (rpcService *RpcService) Name() string {
 }
 
 func (rpcService *RpcService) Api() []app.API {
-	return nil
+	return []app.API{
+		app.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewAdminApi(rpcService),
+			Public:    true,
+		},
+		app.API{
+			Namespace: "unit",
+			Version:   "1.0",
+			Service:   NewUnitApi(),
+			Public:    true,
+		},
+	}
 }
 
 func (rpcService *RpcService) CommandFlags() ([]cli.Command, []cli.Flag) {
 	return nil, []cli.Flag{
 		HTTPEnabledFlag, HTTPListenAddrFlag, HTTPPortFlag, HTTPCORSDomainFlag,
-		HTTPVirtualHostsFlag, HTTPApiFlag, IPCDisabledFlag, IPCPathFlag, WSEnabledFlag,
-		WSListenAddrFlag, WSPortFlag, WSApiFlag, WSAllowedOriginsFlag, RESTEnabledFlag,
+		HTTPVirtualHostsFlag, HTTPApiFlag, HTTPSlowCallFlag, HTTPSlowCallSampleFlag, HTTPTLSCertFlag, HTTPTLSKeyFlag, UnitAmountsFlag, IPCDisabledFlag, IPCPathFlag, WSEnabledFlag,
+		WSListenAddrFlag, WSPortFlag, WSApiFlag, WSAllowedOriginsFlag, WSTLSCertFlag, WSTLSKeyFlag, RPCACMEDomainsFlag, RESTEnabledFlag,
 		RESTListenAddrFlag, RESTPortFlag,
 	}
 }
@@ -67,11 +88,23 @@ func (rpcService *RpcService) P2pMessages() map[int]interface{} {
 }
 
 func (rpcService *RpcService) Init(executeContext *app.ExecuteContext) error {
+	rpcService.executeContext = executeContext
 	rpcService.setRpcLog(executeContext.Cli, executeContext.CommonConfig.HomeDir)
+	for _, tlsConfig := range []HTTPTLSConfig{rpcService.HttpTLS, rpcService.WsTLS} {
+		if tlsConfig.Enabled() {
+			if err := tlsConfig.check(); err != nil {
+				return err
+			}
+		}
+	}
+	common.EnableUnitAmounts(executeContext.Cli.GlobalBool(UnitAmountsFlag.Name))
 	rpcService.IpcEndpoint = rpcService.Config.IPCEndpoint()
 	rpcService.HttpEndpoint = rpcService.Config.HTTPEndpoint()
 	rpcService.WsEndpoint = rpcService.Config.WSEndpoint()
 	rpcService.RestEndpoint = rpcService.Config.RestEndpoint()
+	app.RegisterStatus(MODULENAME, rpcService.status)
+	// calls are refused before the services behind them stop
+	app.RegisterShutdown(MODULENAME, app.ShutdownRefuse, rpcService.stopEndpoints)
 	return nil
 }
 
@@ -111,13 +144,21 @@ func (rpcService *RpcService) Start(executeContext *app.ExecuteContext) error {
 }
 
 func (rpcService *RpcService) Stop(executeContext *app.ExecuteContext) error {
+	rpcService.stopEndpoints()
+	rpcService.lock.Lock()
+	defer rpcService.lock.Unlock()
+	rpcService.RpcAPIs = nil
+	return nil
+}
+
+// stopEndpoints close the endpoints serving external calls, the in-process one is kept
+func (rpcService *RpcService) stopEndpoints() error {
 	rpcService.lock.Lock()
 	defer rpcService.lock.Unlock()
 	// Terminate the API, services and the p2p server.
 	rpcService.StopWS()
 	rpcService.StopHTTP()
 	rpcService.StopIPC()
-	rpcService.RpcAPIs = nil
 	return nil
 }
 
@@ -176,7 +217,7 @@ func (rpcService *RpcService) StartIPC(apis []app.API) error {
 	if rpcService.IpcEndpoint == "" {
 		return nil // IPC disabled.
 	}
-	listener, handler, err := StartIPCEndpoint(rpcService.IpcEndpoint, apis)
+	listener, handler, err := StartIPCEndpoint(rpcService.IpcEndpoint, apis, rpcService.SlowCall)
 	if err != nil {
 		return err
 	}
@@ -209,11 +250,11 @@ func (rpcService *RpcService) StartHTTP(endpoint string, apis []app.API, modules
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, rpc.HTTPTimeouts{})
+	listener, handler, err := StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, rpc.HTTPTimeouts{}, rpcService.SlowCall, rpcService.HttpTLS)
 	if err != nil {
 		return err
 	}
-	log.WithField("url", fmt.Sprintf("http://%s", endpoint)).WithField("cors", strings.Join(cors, ",")).WithField("vhosts", strings.Join(vhosts, ",")).Info("HTTP endpoint opened")
+	log.WithField("url", fmt.Sprintf("%s://%s", rpcService.httpScheme(), endpoint)).WithField("cors", strings.Join(cors, ",")).WithField("vhosts", strings.Join(vhosts, ",")).Info("HTTP endpoint opened")
 	// All listeners booted successfully
 	rpcService.HttpEndpoint = endpoint
 	rpcService.HttpListener = listener
@@ -227,7 +268,7 @@ func (rpcService *RpcService) StopHTTP() {
 		rpcService.HttpListener.Close()
 		rpcService.HttpListener = nil
 
-		log.WithField("url", fmt.Sprintf("http://%s", rpcService.HttpEndpoint)).Info("HTTP endpoint closed")
+		log.WithField("url", fmt.Sprintf("%s://%s", rpcService.httpScheme(), rpcService.HttpEndpoint)).Info("HTTP endpoint closed")
 	}
 	if rpcService.HttpHandler != nil {
 		rpcService.HttpHandler.Stop()
@@ -235,6 +276,22 @@ func (rpcService *RpcService) StopHTTP() {
 	}
 }
 
+// httpScheme returns the url scheme the HTTP RPC endpoint is served with.
+func (rpcService *RpcService) httpScheme() string {
+	if rpcService.HttpTLS.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// wsScheme returns the url scheme the websocket RPC endpoint is served with.
+func (rpcService *RpcService) wsScheme() string {
+	if rpcService.WsTLS.Enabled() {
+		return "wss"
+	}
+	return "ws"
+}
+
 // StartWS initializes and starts the websocket RPC endpoint.
 func (rpcService *RpcService) StartWS(endpoint string, apis []app.API, modules []string, wsOrigins []string, exposeAll bool) error {
 	if !rpcService.Config.WSEnabled {
@@ -244,11 +301,11 @@ func (rpcService *RpcService) StartWS(endpoint string, apis []app.API, modules [
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, rpcService.SlowCall, rpcService.WsTLS)
 	if err != nil {
 		return err
 	}
-	log.WithField("url", fmt.Sprintf("ws://%s", listener.Addr())).Info("WebSocket endpoint opened")
+	log.WithField("url", fmt.Sprintf("%s://%s", rpcService.wsScheme(), listener.Addr())).Info("WebSocket endpoint opened")
 	// All listeners booted successfully
 	rpcService.WsEndpoint = endpoint
 	rpcService.WsListener = listener
@@ -263,7 +320,7 @@ func (rpcService *RpcService) StopWS() {
 		rpcService.WsListener.Close()
 		rpcService.WsListener = nil
 
-		log.WithField("url", fmt.Sprintf("ws://%s", rpcService.WsEndpoint)).Info("WebSocket endpoint closed")
+		log.WithField("url", fmt.Sprintf("%s://%s", rpcService.wsScheme(), rpcService.WsEndpoint)).Info("WebSocket endpoint closed")
 	}
 	if rpcService.WsHandler != nil {
 		rpcService.WsHandler.Stop()
@@ -276,6 +333,7 @@ func (rpcService *RpcService) setRpcLog(ctx *cli.Context, homeDir string) {
 	rpcService.setIPC(ctx, homeDir)
 	rpcService.setHTTP(ctx, homeDir)
 	rpcService.setWS(ctx, homeDir)
+	rpcService.setTLS(ctx, homeDir)
 	rpcService.setRest(ctx, homeDir)
 }
 
@@ -291,6 +349,9 @@ func (rpcService *RpcService) setIPC(ctx *cli.Context, homeDir string) {
 	checkExclusive(ctx, IPCDisabledFlag, IPCPathFlag)
 	if ctx.GlobalIsSet(IPCPathFlag.Name) {
 		rpcService.Config.IPCPath = ctx.GlobalString(IPCPathFlag.Name)
+		if !filepath.IsAbs(rpcService.Config.IPCPath) && !strings.HasPrefix(rpcService.Config.IPCPath, `\\.\pipe\`) {
+			rpcService.Config.IPCPath = filepath.Join(homeDir, rpcService.Config.IPCPath)
+		}
 	} else {
 		rpcService.Config.IPCPath = path.Join(homeDir, DefaultIPCEndpoint(ClientIdentifier))
 	}
@@ -336,6 +397,41 @@ func (rpcService *RpcService) setHTTP(ctx *cli.Context, homeDir string) {
 			rpcService.Config.HTTPVirtualHosts = []string{"localhost"}
 		}
 	}
+
+	rpcService.SlowCall = SlowCallConfig{
+		Threshold:  ctx.GlobalDuration(HTTPSlowCallFlag.Name),
+		SampleRate: ctx.GlobalFloat64(HTTPSlowCallSampleFlag.Name),
+	}
+}
+
+// setTLS creates the certificate configuration of the HTTP and websocket RPC
+// listeners from the set command line flags. ACME domains apply to both.
+func (rpcService *RpcService) setTLS(ctx *cli.Context, homeDir string) {
+	var domains []string
+	if ctx.GlobalIsSet(RPCACMEDomainsFlag.Name) {
+		domains = splitAndTrim(ctx.GlobalString(RPCACMEDomainsFlag.Name))
+	}
+	cacheDir := filepath.Join(homeDir, defaultACMECacheDir)
+	rpcService.HttpTLS = HTTPTLSConfig{
+		CertFile:     resolvePath(homeDir, ctx.GlobalString(HTTPTLSCertFlag.Name)),
+		KeyFile:      resolvePath(homeDir, ctx.GlobalString(HTTPTLSKeyFlag.Name)),
+		ACMEDomains:  domains,
+		ACMECacheDir: cacheDir,
+	}
+	rpcService.WsTLS = HTTPTLSConfig{
+		CertFile:     resolvePath(homeDir, ctx.GlobalString(WSTLSCertFlag.Name)),
+		KeyFile:      resolvePath(homeDir, ctx.GlobalString(WSTLSKeyFlag.Name)),
+		ACMEDomains:  domains,
+		ACMECacheDir: cacheDir,
+	}
+}
+
+// resolvePath makes a relative file name relative to the home directory.
+func resolvePath(homeDir string, file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(homeDir, file)
 }
 
 // setHTTP creates the HTTP RPC listener interface string from the set
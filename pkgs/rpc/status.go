@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/drep-project/DREP-Chain/app"
+)
+
+// RpcStatus is the rpc section of the node status, disabled endpoints are left empty
+type RpcStatus struct {
+	Http        string   `json:"http,omitempty"`
+	HttpModules []string `json:"httpModules,omitempty"`
+	Ws          string   `json:"ws,omitempty"`
+	WsModules   []string `json:"wsModules,omitempty"`
+	Ipc         string   `json:"ipc,omitempty"`
+}
+
+func (rpcService *RpcService) status() interface{} {
+	status := &RpcStatus{}
+	if rpcService.Config.HTTPEnabled && rpcService.HttpEndpoint != "" {
+		status.Http = fmt.Sprintf("%s://%s", rpcService.httpScheme(), rpcService.HttpEndpoint)
+		status.HttpModules = rpcService.Config.HTTPModules
+	}
+	if rpcService.Config.WSEnabled && rpcService.WsEndpoint != "" {
+		status.Ws = fmt.Sprintf("%s://%s", rpcService.wsScheme(), rpcService.WsEndpoint)
+		status.WsModules = rpcService.Config.WSModules
+	}
+	if rpcService.Config.IPCEnabled {
+		status.Ipc = rpcService.IpcEndpoint
+	}
+	return status
+}
+
+// Started logs the node status once every service is up, commands that finished already
+// during start are not reported
+func (rpcService *RpcService) Started(executeContext *app.ExecuteContext) {
+	select {
+	case <-executeContext.Quit:
+		return
+	default:
+	}
+	status := executeContext.Status()
+	entry := log.WithField("services", status.Services)
+	if status.GitCommit != "" {
+		entry = entry.WithField("commit", status.GitCommit)
+	}
+	names := make([]string, 0, len(status.Sections))
+	for name := range status.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section, err := json.Marshal(status.Sections[name])
+		if err != nil {
+			continue
+		}
+		entry = entry.WithField(name, string(section))
+	}
+	entry.Info("Node started")
+}
@@ -0,0 +1,186 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/network/p2p/netutil"
+	"github.com/drep-project/rpc"
+	"github.com/rs/cors"
+	"golang.org/x/net/websocket"
+)
+
+// The transports below serve the rpc server the way the rpc package does, but through the codec
+// of the call meter, so that calls are accounted the same whether they come over http, websocket or ipc
+
+const maxRequestContentLength = 1024 * 512
+
+// httpHandler serve a single json rpc request, or batch, per http request
+type httpHandler struct {
+	server *rpc.Server
+	meter  *callMeter
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Permit dumb empty requests for remote health-checks
+	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+		return
+	}
+	if code, err := validateRequest(r); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	ctx = context.WithValue(ctx, "scheme", r.Proto)
+	ctx = context.WithValue(ctx, "local", r.Host)
+	if ua := r.Header.Get("User-Agent"); ua != "" {
+		ctx = context.WithValue(ctx, "User-Agent", ua)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		ctx = context.WithValue(ctx, "Origin", origin)
+	}
+
+	rw := &httpReadWriteNopCloser{io.LimitReader(r.Body, maxRequestContentLength), w}
+	codec := h.meter.newCodec(rw, r.RemoteAddr, newlineEncoder(w), jsonDecoder(rw))
+	defer codec.Close()
+
+	w.Header().Set("content-type", rpc.ContentType)
+	h.server.ServeSingleRequest(ctx, codec, rpc.OptionMethodInvocation)
+}
+
+// newHTTPHandler wrap the server into cors handling, cross origin calls are enforced by the guard handler
+func newHTTPHandler(server *rpc.Server, meter *callMeter, allowedOrigins []string) http.Handler {
+	handler := &httpHandler{server: server, meter: meter}
+	if len(allowedOrigins) == 0 {
+		return handler
+	}
+	c := cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{http.MethodPost, http.MethodGet},
+		MaxAge:         600,
+		AllowedHeaders: []string{"*"},
+	})
+	return c.Handler(handler)
+}
+
+// validateRequest returns a non-zero response code and error message if the request is invalid
+func validateRequest(r *http.Request) (int, error) {
+	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
+		return http.StatusMethodNotAllowed, errors.New("method not allowed")
+	}
+	if r.ContentLength > maxRequestContentLength {
+		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxRequestContentLength)
+		return http.StatusRequestEntityTooLarge, err
+	}
+	mt, _, err := mime.ParseMediaType(r.Header.Get("content-type"))
+	if r.Method != http.MethodOptions && (err != nil || mt != rpc.ContentType) {
+		err := fmt.Errorf("invalid content type, only %s is supported", rpc.ContentType)
+		return http.StatusUnsupportedMediaType, err
+	}
+	return 0, nil
+}
+
+// httpReadWriteNopCloser wraps a io.Reader and io.Writer with a NOP Close method
+type httpReadWriteNopCloser struct {
+	io.Reader
+	io.Writer
+}
+
+func (t *httpReadWriteNopCloser) Close() error {
+	return nil
+}
+
+// newWebsocketHandler serve json rpc to websocket connections of the allowed origins
+func newWebsocketHandler(server *rpc.Server, meter *callMeter, allowedOrigins []string) http.Handler {
+	return websocket.Server{
+		Handshake: wsHandshakeValidator(allowedOrigins),
+		Handler: func(conn *websocket.Conn) {
+			conn.MaxPayloadBytes = maxRequestContentLength
+			encode := func(msg []byte) error {
+				return websocket.Message.Send(conn, string(msg))
+			}
+			decode := func(v interface{}) error {
+				var msg []byte
+				if err := websocket.Message.Receive(conn, &msg); err != nil {
+					return err
+				}
+				if raw, ok := v.(*json.RawMessage); ok {
+					*raw = msg
+					return nil
+				}
+				return jsonDecoder(bytes.NewReader(msg))(v)
+			}
+			remote := conn.Request().RemoteAddr
+			server.ServeCodec(meter.newCodec(conn, remote, encode, decode), rpc.OptionMethodInvocation|rpc.OptionSubscriptions)
+		},
+	}
+}
+
+// wsHandshakeValidator verify the origin of the websocket upgrade, "*" accept any origin
+// and no origin configured accept localhost only
+func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http.Request) error {
+	origins := make(map[string]struct{})
+	allowAll := false
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		if origin != "" {
+			origins[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+	if len(origins) == 0 {
+		origins["http://localhost"] = struct{}{}
+		if hostname, err := os.Hostname(); err == nil {
+			origins["http://"+strings.ToLower(hostname)] = struct{}{}
+		}
+	}
+	return func(cfg *websocket.Config, req *http.Request) error {
+		origin := strings.ToLower(req.Header.Get("Origin"))
+		if _, ok := origins[origin]; allowAll || ok {
+			return nil
+		}
+		log.WithField("origin", origin).Warn("origin not allowed on WS-RPC interface")
+		return fmt.Errorf("origin %s not allowed", origin)
+	}
+}
+
+// serveListener accept connections on l, serving json rpc on them, until the listener is closed
+func serveListener(server *rpc.Server, meter *callMeter, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if netutil.IsTemporaryError(err) {
+			log.WithField("err", err).Warn("RPC accept error")
+			continue
+		} else if err != nil {
+			return err
+		}
+		codec := meter.newCodec(conn, conn.RemoteAddr().String(), newlineEncoder(conn), jsonDecoder(conn))
+		go server.ServeCodec(codec, rpc.OptionMethodInvocation|rpc.OptionSubscriptions)
+	}
+}
+
+// newlineEncoder write each message followed by a newline, as json.Encoder does
+func newlineEncoder(w io.Writer) func([]byte) error {
+	return func(msg []byte) error {
+		_, err := w.Write(append(msg, '\n'))
+		return err
+	}
+}
+
+// jsonDecoder read consecutive json values, numbers are kept as json.Number
+func jsonDecoder(r io.Reader) func(interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec.Decode
+}
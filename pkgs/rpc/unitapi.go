@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"github.com/drep-project/DREP-Chain/common"
+)
+
+/*
+name: Unit RPC Api
+usage: Convert amounts between units and hex quantities
+prefix:unit
+*/
+type UnitApi struct {
+}
+
+func NewUnitApi() *UnitApi {
+	return &UnitApi{}
+}
+
+/*
+ name: parse
+ usage: Strictly parse an amount with unit (wei, gwei or drep) into wei
+ params:
+	1. amount, e.g. "1.5drep" or "10gwei"
+ return: the raw hex value, canonical decimal wei value and human readable form
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"unit_parse","params":["1.5drep"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"raw":"0x14d1120d7b160000","wei":"1500000000000000000","human":"1.5drep"}}
+*/
+func (unit *UnitApi) Parse(amount string) (*common.Amount, error) {
+	wei, err := common.ParseAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewAmount(wei), nil
+}
+
+/*
+ name: format
+ usage: Describe a hex quantity in wei and drep
+ params:
+	1. hex quantity
+ return: the raw hex value, canonical decimal wei value and human readable form
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"unit_format","params":["0x2540be400"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"raw":"0x2540be400","wei":"10000000000","human":"0.00000001drep"}}
+*/
+func (unit *UnitApi) Format(amount *common.Big) *common.Amount {
+	return common.NewAmount(amount.ToInt())
+}
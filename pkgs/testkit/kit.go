@@ -0,0 +1,276 @@
+// Package testkit runs a chain in memory with solo consensus, funded accounts and the rpc apis
+// of the chain, so dapps and integration tests run against the real execution path. Blocks are
+// only produced when Commit is called.
+package testkit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/chain/store"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/database"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/params"
+	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/solo"
+	"github.com/drep-project/DREP-Chain/pkgs/evm"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/rpc"
+)
+
+const (
+	DefaultAccounts = 4
+	DefaultGasLimit = 3000000
+
+	changeInterval = 100
+	blockInterval  = 5
+)
+
+// DefaultBalance is the balance of each funded account, 1000000 drep
+var DefaultBalance = new(big.Int).Mul(big.NewInt(1000000), new(big.Int).SetUint64(params.Coin))
+
+// Config of the kit, fields left zero use the defaults
+type Config struct {
+	ChainId  types.ChainIdType
+	Accounts int      // count of funded accounts
+	Balance  *big.Int // balance of each funded account in genesis
+}
+
+// Account is a key of the kit wallet
+type Account struct {
+	PrivKey *secp256k1.PrivateKey
+	Address crypto.CommonAddress
+}
+
+func newAccount() (*Account, error) {
+	privKey, err := crypto.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{PrivKey: privKey, Address: crypto.PubkeyToAddress(privKey.PubKey())}, nil
+}
+
+// Kit is a chain in memory. Client is connected in process to the apis of the chain, blockmgr and
+// admin namespaces
+type Kit struct {
+	Database *database.DatabaseService
+	Chain    *chain.ChainService
+	BlockMgr *blockmgr.BlockMgr
+	Evm      *evm.EvmService
+
+	Miner    *Account   // producer of the blocks, it is not funded in genesis
+	Accounts []*Account // accounts funded in genesis
+	Client   *rpc.Client
+
+	context   *app.ExecuteContext
+	consensus *solo.SoloConsensus
+	server    *rpc.Server
+	homeDir   string
+	lock      sync.Mutex
+}
+
+// New start a kit, it must be closed after use
+func New(config *Config) (*Kit, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	accounts, balance := config.Accounts, config.Balance
+	if accounts <= 0 {
+		accounts = DefaultAccounts
+	}
+	if balance == nil {
+		balance = DefaultBalance
+	}
+
+	homeDir, err := ioutil.TempDir("", "drep-testkit")
+	if err != nil {
+		return nil, err
+	}
+	kit := &Kit{homeDir: homeDir}
+	if err := kit.start(config.ChainId, accounts, balance); err != nil {
+		kit.Close()
+		return nil, err
+	}
+	return kit, nil
+}
+
+func (kit *Kit) start(chainId types.ChainIdType, accounts int, balance *big.Int) error {
+	var err error
+	kit.Miner, err = newAccount()
+	if err != nil {
+		return err
+	}
+	preminers := []*chain.Preminer{}
+	for i := 0; i < accounts; i++ {
+		account, err := newAccount()
+		if err != nil {
+			return err
+		}
+		kit.Accounts = append(kit.Accounts, account)
+		preminers = append(preminers, &chain.Preminer{Addr: account.Address, Value: *balance})
+	}
+	genesis, err := json.Marshal(struct {
+		ChainId   types.ChainIdType `json:"chainId"`
+		Consensus string            `json:"consensus"`
+		Preminer  []*chain.Preminer
+	}{chainId, "solo", preminers})
+	if err != nil {
+		return err
+	}
+
+	db := memorydb.New()
+	if err := database.MigrateSchema(db); err != nil {
+		return err
+	}
+	value := bytes.NewBuffer(nil)
+	binary.Write(value, binary.BigEndian, uint64(changeInterval))
+	if err := db.Put([]byte(store.ChangeInterval), value.Bytes()); err != nil {
+		return err
+	}
+
+	chainConfig := *chain.DefaultChainConfig
+	chainConfig.ChainId = chainId
+	blockMgrConfig := *blockmgr.DefaultChainConfig
+	kit.Database = database.NewDatabaseService(db)
+	kit.Chain = &chain.ChainService{DatabaseService: kit.Database, Config: &chainConfig}
+	kit.BlockMgr = &blockmgr.BlockMgr{ChainService: kit.Chain, P2pServer: noPeers{}, DatabaseService: kit.Database, Config: &blockMgrConfig}
+	kit.Evm = &evm.EvmService{Chain: kit.Chain, DatabaseService: kit.Database}
+	kit.context = &app.ExecuteContext{
+		CommonConfig: &app.CommonConfig{HomeDir: kit.homeDir},
+		PhaseConfig:  map[string]json.RawMessage{"genesis": genesis},
+		Services:     []app.Service{kit.Database, kit.Chain, kit.BlockMgr, kit.Evm},
+		Quit:         make(chan struct{}),
+	}
+
+	// the database is already open in memory
+	for _, service := range kit.context.Services[1:] {
+		if err := service.Init(kit.context); err != nil {
+			return err
+		}
+	}
+	minerPk := kit.Miner.PrivKey.PubKey()
+	kit.Chain.AddBlockValidator(solo.NewSoloValidator(minerPk))
+	for _, service := range kit.context.Services {
+		if err := service.Start(kit.context); err != nil {
+			return err
+		}
+	}
+	kit.consensus = solo.NewSoloConsensus(kit.Chain, kit.BlockMgr, minerPk, kit.Database, &solo.SoloConfig{
+		MyPk:           minerPk,
+		StartMiner:     true,
+		BlockInterval:  blockInterval,
+		ChangeInterval: changeInterval,
+	})
+
+	kit.server = rpc.NewServer()
+	for _, api := range kit.context.GetApis() {
+		if err := kit.server.RegisterName(api.Namespace, api.Service); err != nil {
+			return err
+		}
+	}
+	kit.Client = rpc.DialInProc(kit.server)
+	return nil
+}
+
+// Close stop the services and remove the files of the kit
+func (kit *Kit) Close() {
+	if kit.Client != nil {
+		kit.Client.Close()
+	}
+	if kit.server != nil {
+		kit.server.Stop()
+	}
+	if kit.context != nil {
+		for i := len(kit.context.Services) - 1; i >= 0; i-- {
+			kit.context.Services[i].Stop(kit.context)
+		}
+	}
+	os.RemoveAll(kit.homeDir)
+}
+
+// Commit produce a block with the transactions in pool and add it to the chain. Block timestamps
+// are in seconds and grow, so a commit may wait until the second after the last block
+func (kit *Kit) Commit() (*types.Block, error) {
+	kit.lock.Lock()
+	defer kit.lock.Unlock()
+
+	for uint64(time.Now().Unix()) <= kit.Chain.BestChain().Tip().TimeStamp {
+		time.Sleep(50 * time.Millisecond)
+	}
+	block, err := kit.consensus.Run(kit.Miner.PrivKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := kit.Chain.ProcessBlock(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// SendTransaction sign tx by the account and add it to the pool
+func (kit *Kit) SendTransaction(from *Account, tx *types.Transaction) error {
+	tx.Data.ChainId = kit.Chain.ChainID()
+	sig, err := secp256k1.SignCompact(from.PrivKey, tx.TxHash().Bytes(), true)
+	if err != nil {
+		return err
+	}
+	tx.Sig = sig
+	return kit.BlockMgr.SendTransaction(tx, true)
+}
+
+// Transfer send amount from the account to an address
+func (kit *Kit) Transfer(from *Account, to crypto.CommonAddress, amount *big.Int) (*types.Transaction, error) {
+	tx := types.NewTransaction(to, amount, gasPrice(), big.NewInt(DefaultGasLimit), kit.nonce(from))
+	return tx, kit.SendTransaction(from, tx)
+}
+
+// Deploy send a transaction creating a contract, the address of contract is in its receipt
+func (kit *Kit) Deploy(from *Account, byteCode []byte) (*types.Transaction, error) {
+	tx := types.NewContractTransaction(byteCode, gasPrice(), big.NewInt(DefaultGasLimit), kit.nonce(from))
+	return tx, kit.SendTransaction(from, tx)
+}
+
+// CallContract send a transaction calling a contract
+func (kit *Kit) CallContract(from *Account, contract crypto.CommonAddress, input []byte, amount *big.Int) (*types.Transaction, error) {
+	tx := types.NewCallContractTransaction(contract, input, amount, gasPrice(), big.NewInt(DefaultGasLimit), kit.nonce(from))
+	return tx, kit.SendTransaction(from, tx)
+}
+
+// Receipt return receipt of a committed transaction through rpc, nil if it is not in chain
+func (kit *Kit) Receipt(txHash *crypto.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	if err := kit.Client.Call(&receipt, "chain_getReceipt", txHash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// Balance return balance of an address at the tip
+func (kit *Kit) Balance(addr crypto.CommonAddress) (*big.Int, error) {
+	tip := kit.Chain.BestChain().Tip()
+	trieStore, err := store.TrieStoreFromStore(kit.Database.LevelDb(), tip.StateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return trieStore.GetBalance(&addr, tip.Height), nil
+}
+
+// nonce is the next nonce of the account, the transactions in pool counted
+func (kit *Kit) nonce(account *Account) uint64 {
+	return kit.BlockMgr.GetTransactionCount(&account.Address)
+}
+
+func gasPrice() *big.Int {
+	return new(big.Int).SetUint64(blockmgr.DefaultGasPrice)
+}
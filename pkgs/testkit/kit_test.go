@@ -0,0 +1,48 @@
+package testkit
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestKitTransfer(t *testing.T) {
+	kit, err := New(&Config{Accounts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kit.Close()
+
+	from, to := kit.Accounts[0], kit.Accounts[1]
+	amount := big.NewInt(1000)
+	tx, err := kit.Transfer(from, to.Address, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := kit.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Header.Height != 1 || block.Data.TxCount != 1 {
+		t.Fatalf("unexpected block %d with %d txs", block.Header.Height, block.Data.TxCount)
+	}
+
+	balance, err := kit.Balance(to.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := new(big.Int).Add(DefaultBalance, amount); balance.Cmp(expected) != 0 {
+		t.Fatalf("balance %v, expected %v", balance, expected)
+	}
+	receipt, err := kit.Receipt(tx.TxHash())
+	if err != nil || receipt == nil {
+		t.Fatalf("receipt of transfer not found, %v", err)
+	}
+
+	var height uint64
+	if err := kit.Client.Call(&height, "chain_getMaxHeight"); err != nil {
+		t.Fatal(err)
+	}
+	if height != 1 {
+		t.Fatalf("rpc height %d, expected 1", height)
+	}
+}
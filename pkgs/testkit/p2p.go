@@ -0,0 +1,45 @@
+package testkit
+
+import (
+	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/network/p2p"
+	"github.com/drep-project/DREP-Chain/network/p2p/enode"
+	"github.com/drep-project/DREP-Chain/network/p2p/nat"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// noPeers is a p2p service without any peer, blocks and transactions of the kit are never broadcast
+type noPeers struct{}
+
+func (noPeers) Name() string                                   { return "p2p" }
+func (noPeers) Api() []app.API                                 { return nil }
+func (noPeers) CommandFlags() ([]cli.Command, []cli.Flag)      { return nil, nil }
+func (noPeers) Init(executeContext *app.ExecuteContext) error  { return nil }
+func (noPeers) Start(executeContext *app.ExecuteContext) error { return nil }
+func (noPeers) Stop(executeContext *app.ExecuteContext) error  { return nil }
+
+func (noPeers) SendAsync(w p2p.MsgWriter, msgType uint64, msg interface{}) chan error {
+	errCh := make(chan error, 1)
+	errCh <- nil
+	return errCh
+}
+func (noPeers) Send(w p2p.MsgWriter, msgType uint64, msg interface{}) error { return nil }
+func (noPeers) Peers() []*p2p.Peer                                          { return nil }
+func (noPeers) AddPeer(nodeUrl string) error                                { return nil }
+func (noPeers) RemovePeer(url string)                                       {}
+func (noPeers) AddProtocols(protocols []p2p.Protocol)                       {}
+func (noPeers) SetChainId(chainId uint64)                                   {}
+func (noPeers) SetGenesis(hash crypto.Hash)                                 {}
+func (noPeers) SetForks(forks []uint64)                                     {}
+func (noPeers) SetNodeRole(role string)                                     {}
+func (noPeers) SetChainHead(lowest, highest uint64)                         {}
+func (noPeers) LocalNode() *enode.Node                                      { return nil }
+func (noPeers) NATStatus() (*nat.Status, error)                             { return nil, nil }
+func (noPeers) NATRemap() (*nat.Status, error)                              { return nil, nil }
+func (noPeers) Permissioned() bool                                          { return false }
+func (noPeers) SetAllowedNodes(source string, ids []enode.ID) error         { return nil }
+func (noPeers) Allowlist() (map[string][]enode.ID, error)                   { return nil, nil }
+func (noPeers) ReloadAllowlist() error                                      { return nil }
+func (noPeers) SentryMode() bool                                            { return false }
+func (noPeers) Sentry() bool                                                { return false }
@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// AddressStat is the activity of an address over the recorded history, the counts and amounts
+// are those of the send and receive histories
+type AddressStat struct {
+	Address        crypto.CommonAddress `json:"address"`
+	Sent           uint64               `json:"sent"`     // transactions sent
+	Received       uint64               `json:"received"` // transactions received
+	SentAmount     common.Big           `json:"sentAmount"`
+	ReceivedAmount common.Big           `json:"receivedAmount"`
+	FirstHeight    uint64               `json:"firstHeight"` // height of the first transaction sent or received, 0 without any
+	LastHeight     uint64               `json:"lastHeight"`
+	IsContract     bool                 `json:"isContract"` // the address holds contract code at the tip
+}
+
+// addressTransfer is the amount a transaction moves from its sender to its recipient
+type addressTransfer struct {
+	From   crypto.CommonAddress
+	To     *crypto.CommonAddress // nil if the transaction has no recipient
+	Amount *big.Int
+}
+
+// addressTransfers list the transfers of the transactions of a block
+func addressTransfers(block *types.Block) []*addressTransfer {
+	transfers := make([]*addressTransfer, 0, len(block.Data.TxList))
+	for _, tx := range block.Data.TxList {
+		from, err := tx.From()
+		if err != nil {
+			continue
+		}
+		transfers = append(transfers, &addressTransfer{From: *from, To: tx.To(), Amount: tx.Amount()})
+	}
+	return transfers
+}
+
+// apply add a transaction sent or received to the stat, or take it back when revert
+func (stat *AddressStat) apply(sent bool, amount *big.Int, revert bool) {
+	count, total := &stat.Received, stat.ReceivedAmount.ToInt()
+	if sent {
+		count, total = &stat.Sent, stat.SentAmount.ToInt()
+	}
+	if !revert {
+		*count++
+		total.Add(total, amount)
+		return
+	}
+	if *count > 0 {
+		*count--
+	}
+	total.Sub(total, amount)
+	if total.Sign() < 0 {
+		total.SetUint64(0)
+	}
+}
+
+/*
+name: Address statistics
+usage: Query the activity of an address over the recorded history (need to open the record module)
+prefix:chain
+*/
+type AddressStatsApi struct {
+	traceApi *TraceApi
+}
+
+/*
+ name: getAddressStats
+ usage: Count the transactions an address sent and received and the amounts they moved
+ params:
+	1. address
+ return: the counts, the amounts, the heights of the first and last transactions and whether the address is a contract
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"chain_getAddressStats","params":["0x7923a30bbfbcb998a6534d56b313e68c8e0c594a"], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{"jsonrpc":"2.0","id":3,"result":{"address":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","sent":15632,"received":12,"sentAmount":"0x3635c9adc5dea00000","receivedAmount":"0xde0b6b3a7640000","firstHeight":3,"lastHeight":10020,"isContract":false}}
+*/
+func (addressStatsApi *AddressStatsApi) GetAddressStats(addr *crypto.CommonAddress) (*AddressStat, error) {
+	stat, err := addressStatsApi.traceApi.blockAnalysis.store.GetAddressStat(addr)
+	if err != nil {
+		return nil, err
+	}
+	trieQuery, err := addressStatsApi.traceApi.trieQuery()
+	if err != nil {
+		return nil, err
+	}
+	stat.IsContract = len(trieQuery.GetByteCode(addr)) > 0
+	return stat, nil
+}
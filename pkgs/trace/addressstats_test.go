@@ -0,0 +1,30 @@
+package trace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestAddressStatApply(t *testing.T) {
+	stat := &AddressStat{Address: crypto.HexToAddress("0x7923a30bbfbcb998a6534d56b313e68c8e0c594a")}
+	stat.apply(true, big.NewInt(100), false)
+	stat.apply(true, big.NewInt(50), false)
+	stat.apply(false, big.NewInt(7), false)
+	if stat.Sent != 2 || stat.SentAmount.ToInt().Int64() != 150 || stat.Received != 1 || stat.ReceivedAmount.ToInt().Int64() != 7 {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+
+	stat.apply(true, big.NewInt(50), true)
+	if stat.Sent != 1 || stat.SentAmount.ToInt().Int64() != 100 {
+		t.Fatalf("unexpected stat after revert %+v", stat)
+	}
+
+	// taking back more than was recorded stops at zero
+	stat.apply(false, big.NewInt(10), true)
+	stat.apply(false, big.NewInt(10), true)
+	if stat.Received != 0 || stat.ReceivedAmount.ToInt().Sign() != 0 {
+		t.Fatalf("unexpected stat below zero %+v", stat)
+	}
+}
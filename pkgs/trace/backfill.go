@@ -0,0 +1,261 @@
+package trace
+
+import (
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+)
+
+const (
+	syncedHeightKey     = "syncedHeight" // every block up to this height is recorded
+	rebuildProgressKey  = "rebuild"
+	rebuildSaveInterval = 100 // blocks recorded between two saves of the progress
+	rebuildLogInterval  = 1000
+)
+
+// Backfill
+//
+// The live blocks are recorded as the chain sends them, the blocks before the
+// service was enabled, or sent while it was stopped, are recorded by a backfill
+// worker reading the chain database. The store keeps the synced height below
+// which no block is missing, on start the blocks above it are backfilled.
+// trace_rebuild records a range again, its progress is saved in the store so a
+// rebuild stopped by a shutdown resumes on the next start.
+// Backfilled blocks have no internal transactions, they are not executed again.
+
+// RebuildProgress is the state of the backfill worker
+type RebuildProgress struct {
+	From    uint64 `json:"from"`
+	End     uint64 `json:"end"`  // excluded, 0 follows the chain head
+	Next    uint64 `json:"next"` // next block to record
+	Force   bool   `json:"force"`
+	Running bool   `json:"running"`
+	Err     string `json:"err,omitempty"`
+}
+
+func (progress *RebuildProgress) done(height uint64) bool {
+	if progress.End == 0 {
+		return progress.Next > height
+	}
+	return progress.Next >= progress.End
+}
+
+// loadSynced read the synced height and the progress of the last rebuild from the store
+func (blockAnalysis *BlockAnalysis) loadSynced() (*RebuildProgress, error) {
+	blockAnalysis.synced = -1
+	value, err := blockAnalysis.store.GetMeta(syncedHeightKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > 0 {
+		var height uint64
+		if err := binary.Unmarshal(value, &height); err != nil {
+			return nil, err
+		}
+		blockAnalysis.synced = int64(height)
+	}
+
+	value, err = blockAnalysis.store.GetMeta(rebuildProgressKey)
+	if err != nil || len(value) == 0 {
+		return nil, err
+	}
+	progress := &RebuildProgress{}
+	if err := binary.Unmarshal(value, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// upgradeIndex record every block again when the address histories of the store are older
+// than txIndexVersion, the upgrade replaces the rebuild to resume. A new store is upgraded
+// too, it costs the same as its backfill
+func (blockAnalysis *BlockAnalysis) upgradeIndex(resume *RebuildProgress) (*RebuildProgress, error) {
+	value, err := blockAnalysis.store.GetMeta(txIndexVersionKey)
+	if err != nil || (len(value) > 0 && value[0] >= txIndexVersion) {
+		return resume, err
+	}
+	log.WithField("version", txIndexVersion).Info("rebuild trace address histories")
+	upgrade := &RebuildProgress{Force: true, Running: true}
+	// saved first so an interrupted upgrade is resumed
+	blockAnalysis.saveProgress(upgrade)
+	return upgrade, blockAnalysis.store.PutMeta(txIndexVersionKey, []byte{txIndexVersion})
+}
+
+// setSynced move the synced height, the lock must be held
+func (blockAnalysis *BlockAnalysis) setSynced(height int64) {
+	if height == blockAnalysis.synced {
+		return
+	}
+	blockAnalysis.synced = height
+	var err error
+	if height < 0 {
+		err = blockAnalysis.store.PutMeta(syncedHeightKey, nil)
+	} else {
+		var value []byte
+		if value, err = binary.Marshal(uint64(height)); err == nil {
+			err = blockAnalysis.store.PutMeta(syncedHeightKey, value)
+		}
+	}
+	if err != nil {
+		log.WithField("err", err).WithField("height", height).Warn("save synced height")
+	}
+}
+
+// recordBlock insert a block in the store. A recorded block is skipped, or recorded again when force
+func (blockAnalysis *BlockAnalysis) recordBlock(block *types.Block, force bool) error {
+	blockAnalysis.lock.Lock()
+	defer blockAnalysis.lock.Unlock()
+	if blockAnalysis.stopped() {
+		return ErrTraceStopped
+	}
+
+	exist, err := blockAnalysis.store.ExistRecord(block)
+	if err != nil {
+		return err
+	}
+	if exist && force {
+		blockAnalysis.store.DelRecord(block)
+		blockAnalysis.updateContractStats(block, true)
+		blockAnalysis.updateAddressStats(block, true)
+		blockAnalysis.updateLogIndex(block, true)
+	}
+	if !exist || force {
+		blockAnalysis.store.InsertRecord(block)
+		blockAnalysis.updateContractStats(block, false)
+		blockAnalysis.updateAddressStats(block, false)
+		blockAnalysis.updateLogIndex(block, false)
+	}
+	if int64(block.Header.Height) == blockAnalysis.synced+1 {
+		blockAnalysis.setSynced(int64(block.Header.Height))
+	}
+	return nil
+}
+
+// StartRebuild record the blocks from from to end (excluded) again in the background,
+// end 0 follows the chain head
+func (blockAnalysis *BlockAnalysis) StartRebuild(from, end uint64) error {
+	progress := &RebuildProgress{From: from, End: end, Next: from, Force: true}
+	if !blockAnalysis.startRebuild(progress) {
+		return ErrRebuildRunning
+	}
+	go blockAnalysis.rebuild(progress)
+	return nil
+}
+
+// RebuildProgress return the state of the running or last rebuild
+func (blockAnalysis *BlockAnalysis) RebuildProgress() *RebuildProgress {
+	blockAnalysis.progressLock.Lock()
+	defer blockAnalysis.progressLock.Unlock()
+	if blockAnalysis.progress == nil {
+		return &RebuildProgress{}
+	}
+	progress := *blockAnalysis.progress
+	return &progress
+}
+
+func (blockAnalysis *BlockAnalysis) startRebuild(progress *RebuildProgress) bool {
+	blockAnalysis.progressLock.Lock()
+	defer blockAnalysis.progressLock.Unlock()
+	if blockAnalysis.progress != nil && blockAnalysis.progress.Running {
+		return false
+	}
+	progress.Running = true
+	progress.Err = ""
+	blockAnalysis.progress = progress
+	return true
+}
+
+// backfill resume an interrupted rebuild, then record the blocks above the synced height
+func (blockAnalysis *BlockAnalysis) backfill(resume *RebuildProgress) {
+	if resume != nil && resume.Running {
+		resume.Running = false
+		if blockAnalysis.startRebuild(resume) {
+			log.WithField("from", resume.From).WithField("next", resume.Next).Info("resume trace rebuild")
+			if !blockAnalysis.rebuild(resume) {
+				return
+			}
+		}
+	}
+
+	blockAnalysis.lock.Lock()
+	from := uint64(blockAnalysis.synced + 1)
+	blockAnalysis.lock.Unlock()
+	if from > blockAnalysis.getHeight() {
+		return
+	}
+	progress := &RebuildProgress{From: from, Next: from}
+	if blockAnalysis.startRebuild(progress) {
+		log.WithField("from", from).WithField("to", blockAnalysis.getHeight()).Info("backfill trace history")
+		blockAnalysis.rebuild(progress)
+	}
+}
+
+// rebuild record the blocks of a started rebuild, it returns false when stopped by a shutdown
+func (blockAnalysis *BlockAnalysis) rebuild(progress *RebuildProgress) bool {
+	finish := func(err error) {
+		blockAnalysis.progressLock.Lock()
+		if err != nil {
+			progress.Err = err.Error()
+		}
+		progress.Running = false
+		blockAnalysis.progressLock.Unlock()
+		blockAnalysis.saveProgress(progress)
+	}
+
+	blockAnalysis.saveProgress(progress)
+	for {
+		blockAnalysis.progressLock.Lock()
+		next, done := progress.Next, progress.done(blockAnalysis.getHeight())
+		blockAnalysis.progressLock.Unlock()
+		if done {
+			break
+		}
+
+		block, err := blockAnalysis.getBlock(next)
+		if err != nil {
+			log.WithField("height", next).WithField("err", err).Error("trace rebuild read block")
+			finish(ErrBlockNotFound)
+			return true
+		}
+		if err := blockAnalysis.recordBlock(block, progress.Force); err != nil {
+			if err == ErrTraceStopped {
+				// the last saved progress is resumed on the next start
+				return false
+			}
+			log.WithField("height", next).WithField("err", err).Error("trace rebuild record block")
+			finish(err)
+			return true
+		}
+
+		blockAnalysis.progressLock.Lock()
+		progress.Next++
+		blockAnalysis.progressLock.Unlock()
+		if (next+1-progress.From)%rebuildSaveInterval == 0 {
+			blockAnalysis.saveProgress(progress)
+		}
+		if (next+1-progress.From)%rebuildLogInterval == 0 {
+			log.WithField("height", next).WithField("head", blockAnalysis.getHeight()).Info("trace rebuild progress")
+		}
+	}
+	finish(nil)
+	log.WithField("from", progress.From).WithField("to", progress.Next).Info("trace rebuild done")
+	return true
+}
+
+func (blockAnalysis *BlockAnalysis) saveProgress(progress *RebuildProgress) {
+	blockAnalysis.progressLock.Lock()
+	value, err := binary.Marshal(progress)
+	blockAnalysis.progressLock.Unlock()
+	if err != nil {
+		log.WithField("err", err).Warn("encode trace rebuild progress")
+		return
+	}
+
+	blockAnalysis.lock.Lock()
+	defer blockAnalysis.lock.Unlock()
+	if blockAnalysis.stopped() {
+		return
+	}
+	if err := blockAnalysis.store.PutMeta(rebuildProgressKey, value); err != nil {
+		log.WithField("err", err).Warn("save trace rebuild progress")
+	}
+}
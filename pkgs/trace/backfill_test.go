@@ -0,0 +1,33 @@
+package trace
+
+import "testing"
+
+func TestRebuildProgressDone(t *testing.T) {
+	progress := &RebuildProgress{From: 3, End: 6, Next: 5}
+	if progress.done(100) {
+		t.Fatal("range rebuild done before its end")
+	}
+	progress.Next = 6
+	if !progress.done(0) {
+		t.Fatal("range rebuild not done at its end")
+	}
+
+	// a rebuild without end follows the head
+	progress = &RebuildProgress{From: 3, Next: 8}
+	if progress.done(8) || !progress.done(7) {
+		t.Fatal("rebuild without end does not follow the head")
+	}
+}
+
+func TestStartRebuild(t *testing.T) {
+	blockAnalysis := &BlockAnalysis{}
+	if !blockAnalysis.startRebuild(&RebuildProgress{From: 1, Next: 1}) {
+		t.Fatal("rebuild not started")
+	}
+	if blockAnalysis.StartRebuild(0, 10) != ErrRebuildRunning {
+		t.Fatal("second rebuild started while one is running")
+	}
+	if progress := blockAnalysis.RebuildProgress(); !progress.Running || progress.From != 1 {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+}
@@ -1,6 +1,8 @@
 package trace
 
 import (
+	"sync"
+
 	"github.com/drep-project/DREP-Chain/chain/store"
 	"github.com/drep-project/DREP-Chain/common/event"
 	"github.com/drep-project/DREP-Chain/crypto"
@@ -12,9 +14,12 @@ import (
 
 type GetProducer func(root []byte, num int) ([]crypto.CommonAddress, error)
 
+type GetReceipts func(blockHash crypto.Hash) []*types.Receipt
+
 type BlockAnalysis struct {
 	Config           HistoryConfig
 	getBlock         func(uint64) (*types.Block, error)
+	getReceipts      GetReceipts
 	eventNewBlockSub event.Subscription
 	newBlockChan     chan *types.ChainEvent
 
@@ -25,17 +30,29 @@ type BlockAnalysis struct {
 	detachBlockChan chan *types.Block
 	store           IStore
 	readyToQuit     chan struct{}
+
+	recorder *callRecorder // calls of the executed transactions until their block is recorded
+
+	getHeight    func() uint64
+	lock         sync.Mutex // held while writing to the store
+	synced       int64      // every block up to this height is recorded, -1 none
+	progressLock sync.Mutex
+	progress     *RebuildProgress
 }
 
-func NewBlockAnalysis(config HistoryConfig, consensusService *service.ConsensusService, trieStore dbinterface.KeyValueStore, getBlock func(uint64) (*types.Block, error)) *BlockAnalysis {
+func NewBlockAnalysis(config HistoryConfig, consensusService *service.ConsensusService, trieStore dbinterface.KeyValueStore, getBlock func(uint64) (*types.Block, error), getReceipts GetReceipts, getHeight func() uint64) *BlockAnalysis {
 	blockAnalysis := &BlockAnalysis{}
 	blockAnalysis.Config = config
 	blockAnalysis.getBlock = getBlock
+	blockAnalysis.getHeight = getHeight
+	blockAnalysis.synced = -1
+	blockAnalysis.getReceipts = getReceipts
 	blockAnalysis.trieStore = trieStore
 	blockAnalysis.consensusService = consensusService
 	blockAnalysis.newBlockChan = make(chan *types.ChainEvent, 1000)
 	blockAnalysis.detachBlockChan = make(chan *types.Block, 1000)
 	blockAnalysis.readyToQuit = make(chan struct{})
+	blockAnalysis.recorder = newCallRecorder()
 	return blockAnalysis
 }
 
@@ -74,14 +91,28 @@ func (blockAnalysis *BlockAnalysis) Start(newBlock, detachBlock *event.Feed) err
 		if err != nil {
 			log.WithField("err", err).WithField("url", blockAnalysis.Config.Url).Error("try connect mongo fail")
 		}
+	} else if blockAnalysis.Config.DbType == "postgres" {
+		blockAnalysis.store, err = NewPostgresStore(blockAnalysis.Config.Url, blockAnalysis.getReceipts)
+		if err != nil {
+			log.WithField("err", err).WithField("url", blockAnalysis.Config.Url).Error("try connect postgres fail")
+		}
 	} else {
 		return ErrUnSupportDbType
 	}
 	if err != nil {
 		return err
 	}
+	resume, err := blockAnalysis.loadSynced()
+	if err != nil {
+		log.WithField("err", err).Warn("load trace synced height")
+	}
+	resume, err = blockAnalysis.upgradeIndex(resume)
+	if err != nil {
+		log.WithField("err", err).Warn("upgrade trace address histories")
+	}
 
 	go blockAnalysis.process()
+	go blockAnalysis.backfill(resume)
 	return nil
 }
 
@@ -92,9 +123,9 @@ func (blockAnalysis *BlockAnalysis) process() error {
 	for {
 		select {
 		case block := <-blockAnalysis.newBlockChan:
-			blockAnalysis.store.InsertRecord(block.Block)
+			blockAnalysis.attachBlock(block.Block)
 		case block := <-blockAnalysis.detachBlockChan:
-			blockAnalysis.store.DelRecord(block)
+			blockAnalysis.detachBlock(block)
 		case <-blockAnalysis.readyToQuit:
 			//fmt.Println("quit block analysis")
 			//<-blockAnalysis.readyToQuit
@@ -105,6 +136,87 @@ STOP:
 	return nil
 }
 
+func (blockAnalysis *BlockAnalysis) attachBlock(block *types.Block) {
+	blockAnalysis.lock.Lock()
+	defer blockAnalysis.lock.Unlock()
+	if blockAnalysis.stopped() {
+		return
+	}
+	blockAnalysis.store.InsertRecord(block)
+	blockAnalysis.updateContractStats(block, false)
+	blockAnalysis.updateAddressStats(block, false)
+	blockAnalysis.updateLogIndex(block, false)
+	blockAnalysis.insertInternalTxs(block)
+	if int64(block.Header.Height) == blockAnalysis.synced+1 {
+		blockAnalysis.setSynced(int64(block.Header.Height))
+	}
+}
+
+func (blockAnalysis *BlockAnalysis) detachBlock(block *types.Block) {
+	blockAnalysis.lock.Lock()
+	defer blockAnalysis.lock.Unlock()
+	if blockAnalysis.stopped() {
+		return
+	}
+	blockAnalysis.store.DelRecord(block)
+	blockAnalysis.updateContractStats(block, true)
+	blockAnalysis.updateAddressStats(block, true)
+	blockAnalysis.updateLogIndex(block, true)
+	if err := blockAnalysis.store.DelInternalTxs(block); err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Warn("delete internal transactions")
+	}
+	if int64(block.Header.Height) <= blockAnalysis.synced {
+		blockAnalysis.setSynced(int64(block.Header.Height) - 1)
+	}
+}
+
+// stopped tells whether the service is closing, the store must not be used then
+func (blockAnalysis *BlockAnalysis) stopped() bool {
+	select {
+	case <-blockAnalysis.readyToQuit:
+		return true
+	default:
+		return false
+	}
+}
+
+// updateContractStats count the contract calls of a block, or take them back when the block is detached
+func (blockAnalysis *BlockAnalysis) updateContractStats(block *types.Block, revert bool) {
+	if blockAnalysis.getReceipts == nil {
+		return
+	}
+	calls := contractCalls(block, blockAnalysis.getReceipts(*block.Header.Hash()))
+	if len(calls) == 0 {
+		return
+	}
+	if err := blockAnalysis.store.UpdateContractStats(calls, revert); err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Warn("update contract stats")
+	}
+}
+
+// updateAddressStats count the transactions sent and received by the addresses of a block, or take
+// them back when the block is detached
+func (blockAnalysis *BlockAnalysis) updateAddressStats(block *types.Block, revert bool) {
+	transfers := addressTransfers(block)
+	if len(transfers) == 0 {
+		return
+	}
+	if err := blockAnalysis.store.UpdateAddressStats(transfers, revert); err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Warn("update address stats")
+	}
+}
+
+// insertInternalTxs store the internal transactions recorded while the transactions of the block ran
+func (blockAnalysis *BlockAnalysis) insertInternalTxs(block *types.Block) {
+	internalTxs := blockAnalysis.recorder.take(block)
+	if len(internalTxs) == 0 {
+		return
+	}
+	if err := blockAnalysis.store.InsertInternalTxs(internalTxs); err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Warn("insert internal transactions")
+	}
+}
+
 func (blockAnalysis *BlockAnalysis) Close() error {
 	if blockAnalysis.eventNewBlockSub != nil {
 		blockAnalysis.eventNewBlockSub.Unsubscribe()
@@ -116,11 +228,14 @@ func (blockAnalysis *BlockAnalysis) Close() error {
 		//blockAnalysis.readyToQuit <- struct{}{} // tell process to stop in deal all blocks in chanel
 		//blockAnalysis.readyToQuit <- struct{}{} // wait for process is ok to stop
 		close(blockAnalysis.readyToQuit)
+		blockAnalysis.lock.Lock()
 		blockAnalysis.store.Close()
+		blockAnalysis.lock.Unlock()
 	}
 	return nil
 }
 
+// Rebuild record the blocks again, their internal transactions are kept as the blocks are not executed again
 func (blockAnalysis *BlockAnalysis) Rebuild(from, end int) error {
 	/*currentHeight := blockAnalysis.ChainService.BestChain().Height()
 	if uint64(from) > currentHeight {
@@ -132,14 +247,9 @@ func (blockAnalysis *BlockAnalysis) Rebuild(from, end int) error {
 		if err != nil {
 			return ErrBlockNotFound
 		}
-		exist, err := blockAnalysis.store.ExistRecord(block)
-		if err != nil {
+		if err := blockAnalysis.recordBlock(block, true); err != nil {
 			return err
 		}
-		if exist {
-			blockAnalysis.store.DelRecord(block)
-		}
-		blockAnalysis.store.InsertRecord(block)
 	}
 	return nil
 }
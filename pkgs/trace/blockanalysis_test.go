@@ -3,15 +3,16 @@ package trace
 import (
 	"bytes"
 	"errors"
+	"testing"
+	"time"
+
 	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/database/memorydb"
+	"github.com/drep-project/DREP-Chain/pkgs/consensus/service"
 	"github.com/drep-project/DREP-Chain/types"
 	"github.com/syndtr/goleveldb/leveldb"
-	"testing"
-	"time"
 )
 
-var ()
-
 func makeBlockPools() map[uint64]*types.Block {
 	blockPool := make(map[uint64]*types.Block)
 	n := 20
@@ -22,21 +23,38 @@ func makeBlockPools() map[uint64]*types.Block {
 	return blockPool
 }
 
+// newTestAnalysis return a block analysis recording in a leveldb store at path, the chain it
+// reads is blockPool and its head is the genesis, so the backfill on start records the genesis only
+func newTestAnalysis(path string, blockPool map[uint64]*types.Block) *BlockAnalysis {
+	config := HistoryConfig{path, "", "leveldb", true}
+	consensusService := &service.ConsensusService{Config: &service.ConsensusConfig{ConsensusMode: "solo"}}
+	getBlock := func(height uint64) (*types.Block, error) {
+		block, ok := blockPool[height]
+		if ok {
+			return block, nil
+		} else {
+			return nil, errors.New("block not found")
+		}
+	}
+	return NewBlockAnalysis(config, consensusService, memorydb.New(), getBlock, nil, func() uint64 { return 0 })
+}
+
 func Test_Atach_Block_Process(t *testing.T) {
 	path := "./test_process_db1"
-	config := HistoryConfig{path, "", "leveldb", true}
 	blockPool := makeBlockPools()
-	analysis := NewBlockAnalysis(config, func(height uint64) (*types.Block, error) { return nil, nil })
+	analysis := newTestAnalysis(path, nil)
 	defer func() {
 		analysis.Close()
 		deleteFolder(path)
 	}()
 	var newBlockFeed event.Feed
 	var detachBlockFeed event.Feed
-	analysis.Start(&newBlockFeed, &detachBlockFeed)
+	if err := analysis.Start(&newBlockFeed, &detachBlockFeed); err != nil {
+		t.Fatal(err)
+	}
 
 	for _, block := range blockPool {
-		newBlockFeed.Send(block)
+		newBlockFeed.Send(&types.ChainEvent{Block: block, Hash: *block.Header.Hash()})
 	}
 
 	time.Sleep(time.Second)
@@ -55,19 +73,20 @@ func Test_Atach_Block_Process(t *testing.T) {
 
 func Test_Detach_Block_Process(t *testing.T) {
 	path := "./test_process_db2"
-	config := HistoryConfig{path, "", "leveldb", true}
 	blockPool := makeBlockPools()
-	analysis := NewBlockAnalysis(config, func(height uint64) (*types.Block, error) { return nil, nil })
+	analysis := newTestAnalysis(path, nil)
 	defer func() {
 		analysis.Close()
 		deleteFolder(path)
 	}()
 	var newBlockFeed event.Feed
 	var detachBlockFeed event.Feed
-	analysis.Start(&newBlockFeed, &detachBlockFeed)
+	if err := analysis.Start(&newBlockFeed, &detachBlockFeed); err != nil {
+		t.Fatal(err)
+	}
 
 	for _, block := range blockPool {
-		newBlockFeed.Send(block)
+		newBlockFeed.Send(&types.ChainEvent{Block: block, Hash: *block.Header.Hash()})
 	}
 
 	time.Sleep(time.Second)
@@ -89,32 +108,28 @@ func Test_Detach_Block_Process(t *testing.T) {
 
 func Test_Rebuild(t *testing.T) {
 	path := "./test_process_db3"
-	config := HistoryConfig{path, "", "leveldb", true}
 	blockPool := makeBlockPools()
-	analysis := NewBlockAnalysis(config, func(height uint64) (*types.Block, error) {
-		block, ok := blockPool[height]
-		if ok {
-			return block, nil
-		} else {
-			return nil, errors.New("block not found")
-		}
-	})
+	analysis := newTestAnalysis(path, blockPool)
 	defer func() {
 		analysis.Close()
 		deleteFolder(path)
 	}()
 	var newBlockFeed event.Feed
 	var detachBlockFeed event.Feed
-	analysis.Start(&newBlockFeed, &detachBlockFeed)
+	if err := analysis.Start(&newBlockFeed, &detachBlockFeed); err != nil {
+		t.Fatal(err)
+	}
 
 	removeArr := []int{3, 6, 7, 8, 12, 19}
 	for index, block := range blockPool {
 		if !contain(removeArr, int(index)) {
-			newBlockFeed.Send(block)
+			newBlockFeed.Send(&types.ChainEvent{Block: block, Hash: *block.Header.Hash()})
 		}
 	}
 	time.Sleep(time.Second)
-	analysis.Rebuild(0, 20)
+	if err := analysis.Rebuild(0, 20); err != nil {
+		t.Fatal(err)
+	}
 	for _, block := range blockPool {
 		for _, tx := range block.Data.TxList {
 			txBytes, err := analysis.store.GetRawTransaction(tx.TxHash())
@@ -1,6 +1,7 @@
 package trace
 
-// HistoryConfig used to condig history data dir and db message
+// HistoryConfig used to condig history data dir and db message,
+// DbType is leveldb, mongo or postgres, Url is the address of mongo or postgres
 type HistoryConfig struct {
 	HistoryDir string `json:"historydir"`
 	Url        string `json:"url"`
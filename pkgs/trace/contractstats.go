@@ -0,0 +1,140 @@
+package trace
+
+import (
+	"sort"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	// Orders of the contract ranking
+	OrderByGas     = "gas"
+	OrderByCalls   = "calls"
+	OrderByCallers = "callers"
+
+	statDayLayout   = "2006-01-02"
+	maxStatDays     = 366
+	maxContractRank = 100
+)
+
+// ContractStat is the usage of a contract on one day (UTC), or over all days when Day is empty
+type ContractStat struct {
+	Contract crypto.CommonAddress `json:"contract"`
+	Day      string               `json:"day,omitempty"`
+	Calls    uint64               `json:"calls"`
+	GasUsed  uint64               `json:"gasUsed"`
+	Callers  uint64               `json:"callers"` // unique callers
+}
+
+// contractCall is a transaction running a contract, deployments included
+type contractCall struct {
+	Contract crypto.CommonAddress
+	Caller   crypto.CommonAddress
+	GasUsed  uint64
+	Day      string
+}
+
+// contractCalls list the contract calls of a block, the gas used is read from the receipts
+func contractCalls(block *types.Block, receipts []*types.Receipt) []*contractCall {
+	receiptByTx := make(map[crypto.Hash]*types.Receipt, len(receipts))
+	for _, receipt := range receipts {
+		receiptByTx[receipt.TxHash] = receipt
+	}
+
+	day := statDay(block.Header.Timestamp)
+	calls := []*contractCall{}
+	for _, tx := range block.Data.TxList {
+		receipt := receiptByTx[*tx.TxHash()]
+		var contract crypto.CommonAddress
+		switch tx.Type() {
+		case types.CallContractType:
+			contract = *tx.To()
+		case types.CreateContractType:
+			if receipt == nil {
+				continue
+			}
+			contract = receipt.ContractAddress
+		default:
+			continue
+		}
+		from, err := tx.From()
+		if err != nil {
+			continue
+		}
+		call := &contractCall{Contract: contract, Caller: *from, Day: day}
+		if receipt != nil {
+			call.GasUsed = receipt.GasUsed
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// apply add the call to the stat, or take it back when revert. count is the number of calls
+// the caller already made within the stat, the new number is returned
+func (stat *ContractStat) apply(call *contractCall, count uint64, revert bool) uint64 {
+	if !revert {
+		stat.Calls++
+		stat.GasUsed += call.GasUsed
+		if count == 0 {
+			stat.Callers++
+		}
+		return count + 1
+	}
+
+	if stat.Calls > 0 {
+		stat.Calls--
+	}
+	if stat.GasUsed > call.GasUsed {
+		stat.GasUsed -= call.GasUsed
+	} else {
+		stat.GasUsed = 0
+	}
+	if count == 0 {
+		return 0
+	}
+	if count == 1 && stat.Callers > 0 {
+		stat.Callers--
+	}
+	return count - 1
+}
+
+func statDay(timestamp uint64) string {
+	return time.Unix(int64(timestamp), 0).UTC().Format(statDayLayout)
+}
+
+// statDays return the last n days up to now, most recent first
+func statDays(now time.Time, n int) []string {
+	days := make([]string, n)
+	for i := range days {
+		days[i] = now.UTC().AddDate(0, 0, -i).Format(statDayLayout)
+	}
+	return days
+}
+
+func validStatOrder(orderBy string) bool {
+	return orderBy == OrderByGas || orderBy == OrderByCalls || orderBy == OrderByCallers
+}
+
+// rankContractStats sort stats by the order, highest first, and keep the first limit
+func rankContractStats(stats []*ContractStat, orderBy string, limit int) []*ContractStat {
+	key := func(stat *ContractStat) uint64 {
+		switch orderBy {
+		case OrderByCalls:
+			return stat.Calls
+		case OrderByCallers:
+			return stat.Callers
+		default:
+			return stat.GasUsed
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return key(stats[i]) > key(stats[j])
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats
+}
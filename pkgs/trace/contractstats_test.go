@@ -0,0 +1,66 @@
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestContractStatApply(t *testing.T) {
+	contract := crypto.HexToAddress("0xecfb51e10aa4c146bf6c12eee090339c99841efc")
+	alice := crypto.HexToAddress("0x33e846059ebe404c4000902c864190456b642e9b")
+	bob := crypto.HexToAddress("0x2967f0629a5b84c981279ffbe330fe6154be7ad5")
+
+	stat := &ContractStat{Contract: contract}
+	counts := map[crypto.CommonAddress]uint64{}
+	calls := []*contractCall{
+		{Contract: contract, Caller: alice, GasUsed: 100},
+		{Contract: contract, Caller: alice, GasUsed: 50},
+		{Contract: contract, Caller: bob, GasUsed: 10},
+	}
+	for _, call := range calls {
+		counts[call.Caller] = stat.apply(call, counts[call.Caller], false)
+	}
+	if stat.Calls != 3 || stat.GasUsed != 160 || stat.Callers != 2 {
+		t.Fatalf("unexpected stat %+v", stat)
+	}
+
+	// taking back one of alice's calls keeps her a caller, bob's last call does not
+	counts[alice] = stat.apply(calls[1], counts[alice], true)
+	counts[bob] = stat.apply(calls[2], counts[bob], true)
+	if stat.Calls != 1 || stat.GasUsed != 100 || stat.Callers != 1 {
+		t.Fatalf("unexpected stat after revert %+v", stat)
+	}
+	if counts[alice] != 1 || counts[bob] != 0 {
+		t.Fatalf("unexpected caller counts %v", counts)
+	}
+}
+
+func TestRankContractStats(t *testing.T) {
+	stats := []*ContractStat{
+		{Calls: 1, GasUsed: 300, Callers: 1},
+		{Calls: 5, GasUsed: 100, Callers: 3},
+		{Calls: 3, GasUsed: 200, Callers: 4},
+	}
+	if top := rankContractStats(stats, OrderByGas, 2); len(top) != 2 || top[0].GasUsed != 300 || top[1].GasUsed != 200 {
+		t.Fatalf("wrong gas ranking %+v", top)
+	}
+	if top := rankContractStats(stats, OrderByCalls, 10); top[0].Calls != 5 {
+		t.Fatalf("wrong calls ranking %+v", top)
+	}
+	if top := rankContractStats(stats, OrderByCallers, 1); top[0].Callers != 4 {
+		t.Fatalf("wrong callers ranking %+v", top)
+	}
+}
+
+func TestStatDays(t *testing.T) {
+	now := time.Date(2019, 3, 1, 1, 0, 0, 0, time.UTC)
+	days := statDays(now, 3)
+	if len(days) != 3 || days[0] != "2019-03-01" || days[1] != "2019-02-28" || days[2] != "2019-02-27" {
+		t.Fatalf("unexpected days %v", days)
+	}
+	if day := statDay(uint64(now.Unix())); day != "2019-03-01" {
+		t.Fatalf("got day %s", day)
+	}
+}
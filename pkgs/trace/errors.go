@@ -6,4 +6,13 @@ var (
 	ErrTxNotFound      = errors.New("tx not found")
 	ErrBlockNotFound   = errors.New("block not found")
 	ErrUnSupportDbType = errors.New("not support persistence type")
+	ErrEmptySearch     = errors.New("empty search query")
+	ErrInvalidSearch   = errors.New("invalid search query")
+	ErrInvalidOrder    = errors.New("order must be gas, calls or callers")
+	ErrInvalidDay      = errors.New("day must be formatted as 2006-01-02")
+	ErrRebuildRunning  = errors.New("a rebuild is running")
+	ErrTraceStopped    = errors.New("trace service stopped")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidRange    = errors.New("range end before its start")
+	ErrNoReceipts      = errors.New("receipts not available to trace")
 )
@@ -0,0 +1,135 @@
+package trace
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	maxRecordedTxs       = 10000 // traces of executed transactions kept until their block is recorded
+	maxInternalHistories = 100   // page size limit of the internal history of an address
+)
+
+// InternalTx is a call or value transfer made by a contract while a transaction ran.
+// Error is set when the call failed or a call above it failed, its effects are reverted then
+type InternalTx struct {
+	TxHash crypto.Hash          `json:"txHash"`
+	Height uint64               `json:"height"`
+	Index  int                  `json:"index"` // order of the call in the transaction
+	Type   string               `json:"type"`
+	From   crypto.CommonAddress `json:"from"`
+	To     crypto.CommonAddress `json:"to"`
+	Value  common.Big           `json:"value"`
+	Depth  int                  `json:"depth"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// txTrace is the trace of one execution of a transaction
+type txTrace struct {
+	calls []*InternalTx
+	open  []int // indexes of the calls not exited yet
+}
+
+// callRecorder keep the calls of the executed transactions until their block is recorded.
+// A transaction executed again, e.g. when its block is imported after being produced
+// locally, replaces its trace
+type callRecorder struct {
+	lock   sync.Mutex
+	traces map[crypto.Hash]*txTrace
+	order  []crypto.Hash // oldest first, to forget the traces of transactions never recorded
+}
+
+var _ = (vm.CallTracer)((*callRecorder)(nil))
+
+func newCallRecorder() *callRecorder {
+	return &callRecorder{
+		traces: make(map[crypto.Hash]*txTrace),
+	}
+}
+
+func (recorder *callRecorder) CaptureEnter(txHash *crypto.Hash, typ string, from, to crypto.CommonAddress, value *big.Int, depth int) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	trace, ok := recorder.traces[*txHash]
+	if depth == 0 || !ok {
+		if !ok {
+			recorder.order = append(recorder.order, *txHash)
+			recorder.forgetOldest()
+		}
+		trace = &txTrace{}
+		recorder.traces[*txHash] = trace
+	}
+	trace.open = append(trace.open, len(trace.calls))
+	trace.calls = append(trace.calls, &InternalTx{
+		TxHash: *txHash,
+		Index:  len(trace.calls),
+		Type:   typ,
+		From:   from,
+		To:     to,
+		Value:  common.Big(*value),
+		Depth:  depth,
+	})
+}
+
+func (recorder *callRecorder) CaptureExit(txHash *crypto.Hash, depth int, err error) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	trace, ok := recorder.traces[*txHash]
+	if !ok || len(trace.open) == 0 {
+		return
+	}
+	index := trace.open[len(trace.open)-1]
+	trace.open = trace.open[:len(trace.open)-1]
+	if err == nil {
+		return
+	}
+	// the calls made after entering this one are its own, reverted with it
+	for _, call := range trace.calls[index:] {
+		if call.Error == "" {
+			call.Error = err.Error()
+		}
+	}
+}
+
+// forgetOldest drop the oldest traces above maxRecordedTxs, the lock must be held
+func (recorder *callRecorder) forgetOldest() {
+	for len(recorder.order) > maxRecordedTxs {
+		delete(recorder.traces, recorder.order[0])
+		recorder.order = recorder.order[1:]
+	}
+}
+
+// take remove and return the internal transactions of the transactions of a block,
+// the call of each transaction itself is left out
+func (recorder *callRecorder) take(block *types.Block) []*InternalTx {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	internalTxs := []*InternalTx{}
+	for _, tx := range block.Data.TxList {
+		trace, ok := recorder.traces[*tx.TxHash()]
+		if !ok {
+			continue
+		}
+		delete(recorder.traces, *tx.TxHash())
+		for _, call := range trace.calls {
+			if call.Depth == 0 {
+				continue
+			}
+			call.Height = block.Header.Height
+			internalTxs = append(internalTxs, call)
+		}
+	}
+	order := recorder.order[:0]
+	for _, hash := range recorder.order {
+		if _, ok := recorder.traces[hash]; ok {
+			order = append(order, hash)
+		}
+	}
+	recorder.order = order
+	return internalTxs
+}
@@ -0,0 +1,56 @@
+package trace
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func TestCallRecorder(t *testing.T) {
+	recorder := newCallRecorder()
+	tx := randTransaction()
+	hash := tx.TxHash()
+	block := &types.Block{
+		Header: &types.BlockHeader{Height: 7},
+		Data:   &types.BlockData{TxCount: 1, TxList: []*types.Transaction{tx}},
+	}
+	sender, contract, other := crypto.CommonAddress{1}, crypto.CommonAddress{2}, crypto.CommonAddress{3}
+
+	// an earlier execution of the transaction is replaced
+	recorder.CaptureEnter(hash, vm.CallTypeCall, sender, contract, big.NewInt(0), 0)
+	recorder.CaptureEnter(hash, vm.CallTypeCall, contract, other, big.NewInt(9), 1)
+	recorder.CaptureExit(hash, 1, nil)
+	recorder.CaptureExit(hash, 0, nil)
+
+	recorder.CaptureEnter(hash, vm.CallTypeCall, sender, contract, big.NewInt(0), 0)
+	recorder.CaptureEnter(hash, vm.CallTypeCall, contract, other, big.NewInt(5), 1)
+	recorder.CaptureExit(hash, 1, nil)
+	recorder.CaptureEnter(hash, vm.CallTypeCall, contract, sender, big.NewInt(1), 1)
+	recorder.CaptureEnter(hash, vm.CallTypeSuicide, sender, other, big.NewInt(2), 2)
+	recorder.CaptureExit(hash, 2, nil)
+	recorder.CaptureExit(hash, 1, errors.New("reverted"))
+	recorder.CaptureExit(hash, 0, nil)
+
+	internalTxs := recorder.take(block)
+	if len(internalTxs) != 3 {
+		t.Fatalf("got %d internal transactions, want 3", len(internalTxs))
+	}
+	first := internalTxs[0]
+	if first.Index != 1 || first.From != contract || first.To != other || first.Value.ToInt().Int64() != 5 || first.Height != 7 || first.Error != "" {
+		t.Fatalf("unexpected first internal transaction %+v", first)
+	}
+	// the call made by a reverted call is reverted too
+	for _, internalTx := range internalTxs[1:] {
+		if internalTx.Error != "reverted" {
+			t.Fatalf("internal transaction %d not reverted", internalTx.Index)
+		}
+	}
+
+	if len(recorder.take(block)) != 0 || len(recorder.order) != 0 {
+		t.Fatal("trace kept after its block is recorded")
+	}
+}
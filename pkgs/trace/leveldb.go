@@ -1,35 +1,54 @@
 package trace
 
 import (
+	"bytes"
+	encodingBinary "encoding/binary"
 	"fmt"
+	"math/big"
 	"github.com/drep-project/binary"
 	"github.com/drep-project/DREP-Chain/common/fileutil"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/database/dbinterface"
+	"github.com/drep-project/DREP-Chain/database/leveldb"
 	"github.com/drep-project/DREP-Chain/types"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
 	TX_PREFIX                 = "TX"
 	TX_SEND_HISTORY_PREFIX    = "SEND_TXHISTORY"
 	TX_RECEIVE_HISTORY_PREFIX = "RECEIVE_TXHISTORY"
+	CONTRACT_STAT_PREFIX      = "CONTRACT_STAT"
+	CONTRACT_CALLER_PREFIX    = "CONTRACT_CALLER"
+	ADDRESS_STAT_PREFIX       = "ADDRESS_STAT"
+	INTERNAL_TX_PREFIX        = "INTERNAL_TX"
+	INTERNAL_HISTORY_PREFIX   = "INTERNAL_HISTORY"
+	LOG_INDEX_PREFIX          = "LOG_INDEX"
+	META_PREFIX               = "META"
+
+	allDaysBucket = "*" // stat bucket of the totals over all days
 )
 
-// LevelDbStore used to save data to level db, there are 3 kinds of prefix in db.
+// LevelDbStore used to save data to level db, there are 10 kinds of prefix in db.
 // "TX" for transaction collection,   							format "TX" + hash
-// "SEND_TXHISTORY" for transaction group by sender addr,   	format "SEND_TXHISTORY" + addr + hash
-// "RECEIVE_TXHISTORY" for transaction group by receive addr	format "RECEIVE_TXHISTORY" + addr + hash
+// "SEND_TXHISTORY" for transaction group by sender addr,   	format "SEND_TXHISTORY" + addr + height + index
+// "RECEIVE_TXHISTORY" for transaction group by receive addr	format "RECEIVE_TXHISTORY" + addr + height + index
+// "CONTRACT_STAT" for contract usage by day					format "CONTRACT_STAT" + day + "/" + addr
+// "CONTRACT_CALLER" for calls of each caller of a contract		format "CONTRACT_CALLER" + day + "/" + addr + caller
+// "ADDRESS_STAT" for the counts and amounts of an address		format "ADDRESS_STAT" + addr
+// "INTERNAL_TX" for internal transactions of a transaction		format "INTERNAL_TX" + hash + index
+// "INTERNAL_HISTORY" for internal transactions by from and to	format "INTERNAL_HISTORY" + addr + height + hash + index
+// "LOG_INDEX" for blocks with logs of an address and topic	format "LOG_INDEX" + addr + topic0 + bucket
+// "META" for the state of the store like the synced height		format "META" + key
 type LevelDbStore struct {
 	getProducer   GetProducer
 	path          string
 	consensusMode string
-	db            *leveldb.DB
+	db            dbinterface.KeyValueStore
 }
 
 func NewLevelDbStore(path string, getProducer GetProducer, consensusMode string) (*LevelDbStore, error) {
 	fileutil.EnsureDir(path)
-	db, err := leveldb.OpenFile(path, nil)
+	db, err := leveldb.New(path, 0, 0, "")
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +59,9 @@ func (store *LevelDbStore) ExistRecord(block *types.Block) (bool, error) {
 	for _, tx := range block.Data.TxList {
 		txHash := tx.TxHash()
 		key := store.txKey(txHash)
-		_, err := store.db.Get(key, nil)
-		if err != nil {
-			if err == leveldb.ErrNotFound {
-				return false, nil
-			} else {
-				return false, err
-			}
+		exist, err := store.db.Has(key)
+		if err != nil || !exist {
+			return false, err
 		}
 	}
 	return true, nil
@@ -54,27 +69,28 @@ func (store *LevelDbStore) ExistRecord(block *types.Block) (bool, error) {
 
 // InsertRecord check block ,if tx exist, save to to history and send history , if to is not nil, save tx receive history
 func (store *LevelDbStore) InsertRecord(block *types.Block) {
-	for _, tx := range block.Data.TxList {
+	for index, tx := range block.Data.TxList {
 		rawdata := tx.AsPersistentMessage()
 		txHash := tx.TxHash()
 		key := store.txKey(txHash)
-		err := store.db.Put(key, rawdata, nil)
+		err := store.db.Put(key, rawdata)
 		if err != nil {
 			fmt.Println(err)
 			return
 		}
 
 		from, _ := tx.From()
-		sendHistoryKey := store.txSendHistoryKey(from, txHash)
-		err = store.db.Put(sendHistoryKey, txHash[:], nil)
+		position := &txPosition{block.Header.Height, index}
+		sendHistoryKey := store.txSendHistoryKey(from, position)
+		err = store.db.Put(sendHistoryKey, txHash[:])
 		if err != nil {
 			return
 		}
 
 		to := tx.To()
 		if to != nil {
-			historyKey := store.txReceiveHistoryKey(to, txHash)
-			err = store.db.Put(historyKey, txHash[:], nil)
+			historyKey := store.txReceiveHistoryKey(to, position)
+			err = store.db.Put(historyKey, txHash[:])
 			if err != nil {
 				return
 			}
@@ -83,25 +99,26 @@ func (store *LevelDbStore) InsertRecord(block *types.Block) {
 }
 
 func (store *LevelDbStore) DelRecord(block *types.Block) {
-	for _, tx := range block.Data.TxList {
+	for index, tx := range block.Data.TxList {
 		txHash := tx.TxHash()
 		key := store.txKey(txHash)
-		store.db.Delete(key, nil)
+		store.db.Delete(key)
 		from, _ := tx.From()
-		sendHistoryKey := store.txSendHistoryKey(from, txHash)
-		store.db.Delete(sendHistoryKey, nil)
+		position := &txPosition{block.Header.Height, index}
+		store.db.Delete(store.txSendHistoryKey(from, position))
+		store.db.Delete(store.legacyTxSendHistoryKey(from, txHash))
 
 		to := tx.To()
 		if to != nil {
-			receiveHistoryKey := store.txReceiveHistoryKey(to, txHash)
-			store.db.Delete(receiveHistoryKey, nil)
+			store.db.Delete(store.txReceiveHistoryKey(to, position))
+			store.db.Delete(store.legacyTxReceiveHistoryKey(to, txHash))
 		}
 	}
 }
 
 func (store *LevelDbStore) GetRawTransaction(txHash *crypto.Hash) ([]byte, error) {
 	key := store.txKey(txHash)
-	rawData, err := store.db.Get(key, nil)
+	rawData, err := store.db.Get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -123,82 +140,453 @@ func (store *LevelDbStore) GetTransaction(txHash *crypto.Hash) (*RpcTransaction,
 	return rpcTx, nil
 }
 
-func (store *LevelDbStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
+func (store *LevelDbStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory(store.txSendHistoryPrefixKey(addr), pageIndex, pageSize, query)
+}
+
+func (store *LevelDbStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory(store.txReceiveHistoryPrefixKey(addr), pageIndex, pageSize, query)
+}
+
+// queryHistory return a page of the transactions of an address history, prefix is the key prefix of the address
+func (store *LevelDbStore) queryHistory(prefix []byte, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
 	txs := []*RpcTransaction{}
 	fromIndex := (pageIndex - 1) * pageSize
 	endIndex := fromIndex + pageSize
 	if endIndex <= 0 {
 		return txs
 	}
-	key := store.txSendHistoryPrefixKey(addr)
-	snapShot, err := store.db.GetSnapshot()
-	if err != nil {
-		return txs
+	if query == nil {
+		query = &TxQuery{}
 	}
 
-	iter := snapShot.NewIterator(util.BytesPrefix(key), nil)
-	count := 0
+	// the height range and the cursor bound the keys to iterate
+	positionKey := func(position *txPosition) []byte {
+		return append(append([]byte{}, prefix...), position.key()...)
+	}
+	start := positionKey(&txPosition{Height: query.FromHeight})
+	limit := prefixLimit(prefix)
+	if query.ToHeight > 0 {
+		limit = positionKey(&txPosition{Height: query.ToHeight + 1})
+	}
+	if query.after != nil {
+		if query.Desc {
+			after := positionKey(query.after)
+			if limit == nil || bytes.Compare(after, limit) < 0 {
+				limit = after
+			}
+		} else {
+			after := positionKey(&txPosition{query.after.Height, query.after.Index + 1})
+			if bytes.Compare(after, start) > 0 {
+				start = after
+			}
+		}
+	}
+
+	iter := store.db.NewRangeIterator(start, limit, query.Desc)
 	defer iter.Release()
-	for iter.Next() {
+
+	count := 0
+	for count < endIndex && iter.Next() {
+		key := iter.Key()
+		if len(key) != len(prefix)+12 {
+			// written before the keys held the height, left until the block is rebuilt
+			continue
+		}
+		hash := &crypto.Hash{}
+		err := binary.Unmarshal(iter.Value(), hash)
+		if err != nil {
+			break
+		}
+		tx, err := store.GetTransaction(hash)
+		if err != nil {
+			break
+		}
+		if !query.matchTime(tx.Timestamp) {
+			continue
+		}
 		if count >= fromIndex {
-			if count < endIndex {
-				hash := &crypto.Hash{}
-				err = binary.Unmarshal(iter.Value(), hash)
+			position := positionFromKey(key[len(prefix):])
+			tx.Height, tx.Index, tx.Cursor = position.Height, position.Index, position.cursor()
+			txs = append(txs, tx)
+		}
+		count++
+	}
+	return txs
+}
+
+// SearchTransactions return up to limit hashes of transactions starting with prefix
+func (store *LevelDbStore) SearchTransactions(prefix *hashPrefix, limit int) ([]*crypto.Hash, error) {
+	hashes := []*crypto.Hash{}
+	key := append([]byte(TX_PREFIX), prefix.bytes...)
+	iter := store.db.NewIteratorWithPrefix(key)
+	defer iter.Release()
+	for iter.Next() && len(hashes) < limit {
+		hash := iter.Key()[len(TX_PREFIX):]
+		if len(hash) != crypto.HashLength || !prefix.match(hash) {
+			continue
+		}
+		txHash := crypto.Bytes2Hash(hash)
+		hashes = append(hashes, &txHash)
+	}
+	return hashes, iter.Error()
+}
+
+// UpdateContractStats add the calls to the stats of their day and to the totals, or take them back when revert
+func (store *LevelDbStore) UpdateContractStats(calls []*contractCall, revert bool) error {
+	stats := map[string]*ContractStat{}
+	callers := map[string]uint64{}
+	for _, call := range calls {
+		for _, bucket := range []string{allDaysBucket, call.Day} {
+			statKey := string(store.contractStatKey(bucket, &call.Contract))
+			stat, ok := stats[statKey]
+			if !ok {
+				var err error
+				stat, err = store.getContractStat(bucket, &call.Contract)
 				if err != nil {
-					break
+					return err
 				}
-				tx, err := store.GetTransaction(hash)
+				stats[statKey] = stat
+			}
+
+			callerKey := string(store.contractCallerKey(bucket, &call.Contract, &call.Caller))
+			count, ok := callers[callerKey]
+			if !ok {
+				value, err := store.get([]byte(callerKey))
 				if err != nil {
-					break
+					return err
+				}
+				if value != nil {
+					if err := binary.Unmarshal(value, &count); err != nil {
+						return err
+					}
 				}
-				txs = append(txs, tx)
-			} else {
-				break
 			}
+			callers[callerKey] = stat.apply(call, count, revert)
 		}
-		count++
 	}
 
-	return txs
+	batch := store.db.NewBatch()
+	for key, stat := range stats {
+		value, err := binary.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(key), value)
+	}
+	for key, count := range callers {
+		if count == 0 {
+			batch.Delete([]byte(key))
+			continue
+		}
+		value, err := binary.Marshal(count)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(key), value)
+	}
+	return batch.Write()
 }
 
-func (store *LevelDbStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
-	txs := []*RpcTransaction{}
-	fromIndex := (pageIndex - 1) * pageSize
-	endIndex := fromIndex + pageSize
-	if endIndex <= 0 {
-		return txs
+// GetContractStat return the usage of a contract on a day, or over all days if day is empty
+func (store *LevelDbStore) GetContractStat(contract *crypto.CommonAddress, day string) (*ContractStat, error) {
+	if day == "" {
+		day = allDaysBucket
+	}
+	return store.getContractStat(day, contract)
+}
+
+// TopContracts rank the contracts used on a day, or over all days if day is empty
+func (store *LevelDbStore) TopContracts(day string, orderBy string, limit int) ([]*ContractStat, error) {
+	if day == "" {
+		day = allDaysBucket
+	}
+	stats := []*ContractStat{}
+	iter := store.db.NewIteratorWithPrefix(store.contractStatPrefixKey(day))
+	defer iter.Release()
+	for iter.Next() {
+		stat := &ContractStat{}
+		if err := binary.Unmarshal(iter.Value(), stat); err != nil {
+			return nil, err
+		}
+		if stat.Calls > 0 {
+			stats = append(stats, stat)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return rankContractStats(stats, orderBy, limit), nil
+}
+
+// UpdateAddressStats add the transfers to the stats of their sender and recipient, or take them back when revert
+func (store *LevelDbStore) UpdateAddressStats(transfers []*addressTransfer, revert bool) error {
+	stats := map[crypto.CommonAddress]*AddressStat{}
+	apply := func(addr *crypto.CommonAddress, sent bool, amount *big.Int) error {
+		stat, ok := stats[*addr]
+		if !ok {
+			var err error
+			if stat, err = store.getAddressStat(addr); err != nil {
+				return err
+			}
+			stats[*addr] = stat
+		}
+		stat.apply(sent, amount, revert)
+		return nil
+	}
+	for _, transfer := range transfers {
+		if err := apply(&transfer.From, true, transfer.Amount); err != nil {
+			return err
+		}
+		if transfer.To != nil {
+			if err := apply(transfer.To, false, transfer.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	batch := store.db.NewBatch()
+	for addr, stat := range stats {
+		value, err := binary.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		batch.Put(store.addressStatKey(&addr), value)
 	}
-	key := store.txReceiveHistoryPrefixKey(addr)
-	snapShot, err := store.db.GetSnapshot()
+	return batch.Write()
+}
+
+// GetAddressStat return the stat of an address, the heights are read from its histories
+func (store *LevelDbStore) GetAddressStat(addr *crypto.CommonAddress) (*AddressStat, error) {
+	stat, err := store.getAddressStat(addr)
 	if err != nil {
-		return txs
+		return nil, err
+	}
+	found := false
+	for _, prefix := range [][]byte{store.txSendHistoryPrefixKey(addr), store.txReceiveHistoryPrefixKey(addr)} {
+		first, ok := store.historyEnd(prefix, false)
+		if !ok {
+			continue
+		}
+		last, _ := store.historyEnd(prefix, true)
+		if !found || first < stat.FirstHeight {
+			stat.FirstHeight = first
+		}
+		if !found || last > stat.LastHeight {
+			stat.LastHeight = last
+		}
+		found = true
 	}
+	return stat, nil
+}
 
-	iter := snapShot.NewIterator(util.BytesPrefix(key), nil)
-	count := 0
+// historyEnd return the height of the first transaction of an address history, or of the last when desc
+func (store *LevelDbStore) historyEnd(prefix []byte, desc bool) (uint64, bool) {
+	iter := store.db.NewRangeIterator(prefix, prefixLimit(prefix), desc)
 	defer iter.Release()
 	for iter.Next() {
+		if key := iter.Key(); len(key) == len(prefix)+12 {
+			return positionFromKey(key[len(prefix):]).Height, true
+		}
+	}
+	return 0, false
+}
+
+// InsertInternalTxs save internal transactions and index them by their from and to address
+func (store *LevelDbStore) InsertInternalTxs(internalTxs []*InternalTx) error {
+	batch := store.db.NewBatch()
+	for _, internalTx := range internalTxs {
+		value, err := binary.Marshal(internalTx)
+		if err != nil {
+			return err
+		}
+		key := store.internalTxKey(&internalTx.TxHash, internalTx.Index)
+		batch.Put(key, value)
+		batch.Put(store.internalHistoryKey(&internalTx.From, internalTx), key)
+		if internalTx.To != internalTx.From {
+			batch.Put(store.internalHistoryKey(&internalTx.To, internalTx), key)
+		}
+	}
+	return batch.Write()
+}
+
+// DelInternalTxs remove the internal transactions of the transactions in block
+func (store *LevelDbStore) DelInternalTxs(block *types.Block) error {
+	batch := store.db.NewBatch()
+	for _, tx := range block.Data.TxList {
+		internalTxs, err := store.GetInternalTxs(tx.TxHash())
+		if err != nil {
+			return err
+		}
+		for _, internalTx := range internalTxs {
+			batch.Delete(store.internalTxKey(&internalTx.TxHash, internalTx.Index))
+			batch.Delete(store.internalHistoryKey(&internalTx.From, internalTx))
+			batch.Delete(store.internalHistoryKey(&internalTx.To, internalTx))
+		}
+	}
+	return batch.Write()
+}
+
+// GetInternalTxs return the internal transactions of a transaction in call order
+func (store *LevelDbStore) GetInternalTxs(txHash *crypto.Hash) ([]*InternalTx, error) {
+	internalTxs := []*InternalTx{}
+	iter := store.db.NewIteratorWithPrefix(store.internalTxPrefixKey(txHash))
+	defer iter.Release()
+	for iter.Next() {
+		internalTx := &InternalTx{}
+		if err := binary.Unmarshal(iter.Value(), internalTx); err != nil {
+			return nil, err
+		}
+		internalTxs = append(internalTxs, internalTx)
+	}
+	return internalTxs, iter.Error()
+}
+
+// GetAddressInternalTxs return a page of the internal transactions from or to addr, oldest first
+func (store *LevelDbStore) GetAddressInternalTxs(addr *crypto.CommonAddress, pageIndex, pageSize int) ([]*InternalTx, error) {
+	internalTxs := []*InternalTx{}
+	fromIndex := (pageIndex - 1) * pageSize
+	endIndex := fromIndex + pageSize
+	if endIndex <= 0 {
+		return internalTxs, nil
+	}
+	iter := store.db.NewIteratorWithPrefix(store.internalHistoryPrefixKey(addr))
+	defer iter.Release()
+	count := 0
+	for iter.Next() && count < endIndex {
 		if count >= fromIndex {
-			if count < endIndex {
-				hash := &crypto.Hash{}
-				err = binary.Unmarshal(iter.Value(), hash)
-				if err != nil {
-					break
-				}
-				tx, err := store.GetTransaction(hash)
-				if err != nil {
-					break
-				}
-				txs = append(txs, tx)
-			} else {
-				break
+			value, err := store.db.Get(iter.Value())
+			if err != nil {
+				return nil, err
 			}
+			internalTx := &InternalTx{}
+			if err := binary.Unmarshal(value, internalTx); err != nil {
+				return nil, err
+			}
+			internalTxs = append(internalTxs, internalTx)
 		}
 		count++
 	}
+	return internalTxs, iter.Error()
+}
 
-	return txs
+// UpdateLogIndex count the blocks with the keys in the buckets of their heights, or take them back when revert
+func (store *LevelDbStore) UpdateLogIndex(keys []*logKey, revert bool) error {
+	buckets := map[string][]*logCount{}
+	for _, key := range keys {
+		bucketKey := string(store.logIndexKey(&key.Address, &key.Topic0, logBucket(key.Height)))
+		counts, ok := buckets[bucketKey]
+		if !ok {
+			value, err := store.get([]byte(bucketKey))
+			if err != nil {
+				return err
+			}
+			if value != nil {
+				if err := binary.Unmarshal(value, &counts); err != nil {
+					return err
+				}
+			}
+		}
+		buckets[bucketKey] = countLogBlock(counts, key.Height, revert)
+	}
+
+	batch := store.db.NewBatch()
+	for key, counts := range buckets {
+		if len(counts) == 0 {
+			batch.Delete([]byte(key))
+			continue
+		}
+		value, err := binary.Marshal(counts)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(key), value)
+	}
+	return batch.Write()
+}
+
+// GetLogBlocks return up to limit heights of the blocks with logs of addr and topic0 in [fromHeight, toHeight], ascending
+func (store *LevelDbStore) GetLogBlocks(addr *crypto.CommonAddress, topic0 *crypto.Hash, fromHeight, toHeight uint64, limit int) ([]uint64, error) {
+	iter := store.db.NewRangeIterator(
+		store.logIndexKey(addr, topic0, logBucket(fromHeight)),
+		store.logIndexKey(addr, topic0, logBucket(toHeight)+1),
+		false,
+	)
+	defer iter.Release()
+	heights := []uint64{}
+	for iter.Next() {
+		counts := []*logCount{}
+		if err := binary.Unmarshal(iter.Value(), &counts); err != nil {
+			return nil, err
+		}
+		for _, count := range counts {
+			if count.Height < fromHeight || count.Height > toHeight {
+				continue
+			}
+			heights = append(heights, count.Height)
+			if len(heights) == limit {
+				return heights, nil
+			}
+		}
+	}
+	return heights, iter.Error()
+}
+
+// GetMeta return a value describing the store, nil if not set
+func (store *LevelDbStore) GetMeta(key string) ([]byte, error) {
+	return store.get([]byte(META_PREFIX + key))
+}
+
+func (store *LevelDbStore) PutMeta(key string, value []byte) error {
+	return store.db.Put([]byte(META_PREFIX+key), value)
+}
+
+// get return the value of key, nil if not found
+func (store *LevelDbStore) get(key []byte) ([]byte, error) {
+	exist, err := store.db.Has(key)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return store.db.Get(key)
+}
+
+// prefixLimit return the smallest key after all keys with prefix
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte{}, prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+func (store *LevelDbStore) getContractStat(bucket string, contract *crypto.CommonAddress) (*ContractStat, error) {
+	stat := &ContractStat{Contract: *contract}
+	if bucket != allDaysBucket {
+		stat.Day = bucket
+	}
+	value, err := store.get(store.contractStatKey(bucket, contract))
+	if err != nil || value == nil {
+		return stat, err
+	}
+	if err := binary.Unmarshal(value, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+func (store *LevelDbStore) getAddressStat(addr *crypto.CommonAddress) (*AddressStat, error) {
+	stat := &AddressStat{Address: *addr}
+	value, err := store.get(store.addressStatKey(addr))
+	if err != nil || value == nil {
+		return stat, err
+	}
+	if err := binary.Unmarshal(value, stat); err != nil {
+		return nil, err
+	}
+	return stat, nil
 }
 
 func (store *LevelDbStore) txKey(hash *crypto.Hash) []byte {
@@ -208,7 +596,12 @@ func (store *LevelDbStore) txKey(hash *crypto.Hash) []byte {
 	return buf[:]
 }
 
-func (store *LevelDbStore) txSendHistoryKey(addr *crypto.CommonAddress, hash *crypto.Hash) []byte {
+func (store *LevelDbStore) txSendHistoryKey(addr *crypto.CommonAddress, position *txPosition) []byte {
+	return append(store.txSendHistoryPrefixKey(addr), position.key()...)
+}
+
+// legacyTxSendHistoryKey is the key of a send history before version 1 of the address histories
+func (store *LevelDbStore) legacyTxSendHistoryKey(addr *crypto.CommonAddress, hash *crypto.Hash) []byte {
 	buf := [66]byte{}
 	copy(buf[:14], []byte(TX_SEND_HISTORY_PREFIX)[:14])
 	copy(buf[14:34], addr[:])
@@ -223,7 +616,12 @@ func (store *LevelDbStore) txSendHistoryPrefixKey(addr *crypto.CommonAddress) []
 	return buf[:]
 }
 
-func (store *LevelDbStore) txReceiveHistoryKey(addr *crypto.CommonAddress, hash *crypto.Hash) []byte {
+func (store *LevelDbStore) txReceiveHistoryKey(addr *crypto.CommonAddress, position *txPosition) []byte {
+	return append(store.txReceiveHistoryPrefixKey(addr), position.key()...)
+}
+
+// legacyTxReceiveHistoryKey is the key of a receive history before version 1 of the address histories
+func (store *LevelDbStore) legacyTxReceiveHistoryKey(addr *crypto.CommonAddress, hash *crypto.Hash) []byte {
 	buf := [69]byte{} //17+20+32 = 37+32 = 69
 	copy(buf[:17], []byte(TX_RECEIVE_HISTORY_PREFIX)[:17])
 	copy(buf[17:37], addr[:])
@@ -238,6 +636,54 @@ func (store *LevelDbStore) txReceiveHistoryPrefixKey(addr *crypto.CommonAddress)
 	return buf[:]
 }
 
+func (store *LevelDbStore) contractStatPrefixKey(bucket string) []byte {
+	return []byte(CONTRACT_STAT_PREFIX + bucket + "/")
+}
+
+func (store *LevelDbStore) contractStatKey(bucket string, contract *crypto.CommonAddress) []byte {
+	return append(store.contractStatPrefixKey(bucket), contract[:]...)
+}
+
+func (store *LevelDbStore) contractCallerKey(bucket string, contract, caller *crypto.CommonAddress) []byte {
+	key := []byte(CONTRACT_CALLER_PREFIX + bucket + "/")
+	key = append(key, contract[:]...)
+	return append(key, caller[:]...)
+}
+
+func (store *LevelDbStore) addressStatKey(addr *crypto.CommonAddress) []byte {
+	return append([]byte(ADDRESS_STAT_PREFIX), addr[:]...)
+}
+
+func (store *LevelDbStore) internalTxPrefixKey(hash *crypto.Hash) []byte {
+	return append([]byte(INTERNAL_TX_PREFIX), hash[:]...)
+}
+
+func (store *LevelDbStore) internalTxKey(hash *crypto.Hash, index int) []byte {
+	buf := [4]byte{}
+	encodingBinary.BigEndian.PutUint32(buf[:], uint32(index))
+	return append(store.internalTxPrefixKey(hash), buf[:]...)
+}
+
+func (store *LevelDbStore) internalHistoryPrefixKey(addr *crypto.CommonAddress) []byte {
+	return append([]byte(INTERNAL_HISTORY_PREFIX), addr[:]...)
+}
+
+// internalHistoryKey sort the internal transactions of an address by height
+func (store *LevelDbStore) internalHistoryKey(addr *crypto.CommonAddress, internalTx *InternalTx) []byte {
+	buf := [8]byte{}
+	encodingBinary.BigEndian.PutUint64(buf[:], internalTx.Height)
+	key := append(store.internalHistoryPrefixKey(addr), buf[:]...)
+	return append(key, store.internalTxKey(&internalTx.TxHash, internalTx.Index)[len(INTERNAL_TX_PREFIX):]...)
+}
+
+func (store *LevelDbStore) logIndexKey(addr *crypto.CommonAddress, topic0 *crypto.Hash, bucket uint64) []byte {
+	buf := [8]byte{}
+	encodingBinary.BigEndian.PutUint64(buf[:], bucket)
+	key := append([]byte(LOG_INDEX_PREFIX), addr[:]...)
+	key = append(key, topic0[:]...)
+	return append(key, buf[:]...)
+}
+
 func (store *LevelDbStore) Close() {
 	store.db.Close()
 }
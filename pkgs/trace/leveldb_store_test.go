@@ -3,7 +3,6 @@ package trace
 import (
 	"bytes"
 	"encoding/hex"
-	"github.com/drep-project/DREP-Chain/app"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/common/math"
 	"github.com/drep-project/DREP-Chain/crypto"
@@ -19,14 +18,13 @@ import (
 )
 
 var (
-	fromAddr = "0x33e846059ebe404c4000902c864190456b642e9b"
 	fromPriv = "cb5441fac80cf4e5438c6f873ecd5f3d0fa67d597f6c68bbc1106e8563e7f419"
 	toAddr   = "0x2967f0629a5b84c981279ffbe330fe6154be7ad5"
 	toPriv   = "987c5c49033044141f7a20fe411f27df041e326762d8f251c0323649d9006466"
 )
 
 func makeData(path string) (*LevelDbStore, []*types.Block) {
-	levelDbStore, _ := NewLevelDbStore(path)
+	levelDbStore, _ := NewLevelDbStore(path, nil, "")
 	testData := []*types.Block{}
 	for i := 1; i < 10; i++ {
 		block := randomBlock()
@@ -39,6 +37,11 @@ func makeData(path string) (*LevelDbStore, []*types.Block) {
 func Test_LeveldbInsertAndExistRecord(t *testing.T) {
 	path := "test_db1"
 	levelDbStore, testData := makeData(path)
+	defer func() {
+		levelDbStore.Close()
+		deleteFolder(path)
+	}()
+
 	for _, data := range testData {
 		exist, err := levelDbStore.ExistRecord(data)
 		if err != nil {
@@ -126,8 +129,8 @@ func Test_LeveldbInsertAndGetSendTransactionsByAddr(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	fromAddr := crypto.String2Address(fromAddr)
-	all := levelDbStore.GetSendTransactionsByAddr(&fromAddr, 1, math.MaxInt32)
+	fromAddr := senderAddress()
+	all := levelDbStore.GetSendTransactionsByAddr(&fromAddr, 1, math.MaxInt32, nil)
 	if len(all) != allCount {
 		t.Errorf("The total number of transactions does not match, real count %d but got %d", allCount, len(all))
 	}
@@ -159,12 +162,12 @@ func Test_LeveldbGetSendTransactionsByAddrAndPagination(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	fromAddr := crypto.String2Address(fromAddr)
-	all := levelDbStore.GetSendTransactionsByAddr(&fromAddr, 1, 3)
+	fromAddr := senderAddress()
+	all := levelDbStore.GetSendTransactionsByAddr(&fromAddr, 1, 3, nil)
 	if len(all) != 3 {
 		t.Error("paging failure")
 	}
-	all = levelDbStore.GetSendTransactionsByAddr(&fromAddr, 2, 3)
+	all = levelDbStore.GetSendTransactionsByAddr(&fromAddr, 2, 3, nil)
 	if len(all) != 3 {
 		t.Error("paging failure")
 	}
@@ -182,8 +185,8 @@ func Test_LeveldbInsertAndGetReceiveTransactionsByAddr(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	toAddr := crypto.String2Address(toAddr)
-	all := levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 1, math.MaxInt32)
+	toAddr := crypto.HexToAddress(toAddr)
+	all := levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 1, math.MaxInt32, nil)
 	if len(all) != allCount {
 		t.Errorf("The total number of receive transactions does not match, real count %d but got %d", allCount, len(all))
 	}
@@ -215,12 +218,12 @@ func Test_LeveldbGetReceiveTransactionsByAddrAndPagination(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	toAddr := crypto.String2Address(toAddr)
-	all := levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 1, 3)
+	toAddr := crypto.HexToAddress(toAddr)
+	all := levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 1, 3, nil)
 	if len(all) != 3 {
 		t.Error("receive paging failure")
 	}
-	all = levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 2, 3)
+	all = levelDbStore.GetReceiveTransactionsByAddr(&toAddr, 2, 3, nil)
 	if len(all) != 3 {
 		t.Error("receive paging failure")
 	}
@@ -252,17 +255,16 @@ func randomBlock() *types.Block {
 	priv, _ := secp256k1.PrivKeyFromScalar(priBytes)
 	block := &types.Block{
 		Header: &types.BlockHeader{
-			ChainId:        app.ChainIdType{},
-			Version:        rand.Int31(),
-			PreviousHash:   crypto.RandomHash(),
-			GasLimit:       *big.NewInt(rand.Int63()),
-			GasUsed:        *big.NewInt(rand.Int63()),
-			Height:         uint64(rand.Int63()),
-			Timestamp:      uint64(time.Now().Nanosecond()),
-			StateRoot:      crypto.RandomHash().Bytes(),
-			TxRoot:         crypto.RandomHash().Bytes(),
-			LeaderAddress:  *priv.PubKey(),
-			MinorAddresses: []secp256k1.PublicKey{},
+			ChainId:      types.ChainIdType(0),
+			Version:      rand.Int31(),
+			PreviousHash: crypto.RandomHash(),
+			GasLimit:     *big.NewInt(rand.Int63()),
+			GasUsed:      *big.NewInt(rand.Int63()),
+			Height:       uint64(rand.Int63()),
+			Timestamp:    uint64(time.Now().Nanosecond()),
+			StateRoot:    crypto.RandomHash().Bytes(),
+			TxRoot:       crypto.RandomHash().Bytes(),
+			MinerAddr:    crypto.PubkeyToAddress(priv.PubKey()),
 		},
 		Data: &types.BlockData{
 			TxList:  txData,
@@ -272,6 +274,13 @@ func randomBlock() *types.Block {
 	return block
 }
 
+// senderAddress return the address of fromPriv, the sender of the random transactions
+func senderAddress() crypto.CommonAddress {
+	priBytes, _ := hex.DecodeString(fromPriv)
+	priv, _ := secp256k1.PrivKeyFromScalar(priBytes)
+	return crypto.PubkeyToAddress(priv.PubKey())
+}
+
 func randTransaction() *types.Transaction {
 	buf := make([]byte, 20)
 	rand.Read(buf)
@@ -282,8 +291,8 @@ func randTransaction() *types.Transaction {
 			Version:   rand.Int31(),
 			Nonce:     uint64(rand.Int31()),
 			Type:      types.TxType(0),
-			To:        crypto.String2Address(toAddr),
-			ChainId:   app.ChainIdType{},
+			To:        crypto.HexToAddress(toAddr),
+			ChainId:   types.ChainIdType(0),
 			Amount:    common.Big(*big.NewInt(rand.Int63())),
 			GasPrice:  common.Big(*big.NewInt(rand.Int63())),
 			GasLimit:  common.Big(*big.NewInt(rand.Int63())),
@@ -0,0 +1,205 @@
+package trace
+
+import (
+	"sort"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	// logBucketSize is the count of blocks in a bucket of the log index
+	logBucketSize = 1024
+	// maxLogBlocks is the count of blocks whose receipts are read by a log query, the query
+	// returns a cursor to go on when the matching blocks in range are more
+	maxLogBlocks   = 200
+	maxLogPageSize = 1000
+)
+
+// logKey tells that a block has logs of Address with the first topic Topic0. The zero Topic0
+// indexes the logs of Address with any topic
+type logKey struct {
+	Address crypto.CommonAddress
+	Topic0  crypto.Hash
+	Height  uint64
+}
+
+// blockLogKeys list the distinct log keys of a block
+func blockLogKeys(block *types.Block, receipts []*types.Receipt) []*logKey {
+	seen := map[logKey]bool{}
+	keys := []*logKey{}
+	add := func(key logKey) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, &key)
+		}
+	}
+	for _, receipt := range receipts {
+		for _, txLog := range receipt.Logs {
+			add(logKey{Address: txLog.Address, Height: block.Header.Height})
+			if len(txLog.Topics) > 0 {
+				add(logKey{Address: txLog.Address, Topic0: txLog.Topics[0], Height: block.Header.Height})
+			}
+		}
+	}
+	return keys
+}
+
+func logBucket(height uint64) uint64 {
+	return height / logBucketSize
+}
+
+// logCount is the count of blocks at Height indexed with a key. Attached and detached blocks come
+// by different channels, so the new block of a height may be indexed before the old one is removed
+type logCount struct {
+	Height uint64
+	Count  uint64
+}
+
+// countLogBlock add a block at height to the counts of a bucket sorted by height, or take it back
+// when revert
+func countLogBlock(counts []*logCount, height uint64, revert bool) []*logCount {
+	i := sort.Search(len(counts), func(i int) bool { return counts[i].Height >= height })
+	if i < len(counts) && counts[i].Height == height {
+		if !revert {
+			counts[i].Count++
+		} else if counts[i].Count--; counts[i].Count == 0 {
+			counts = append(counts[:i], counts[i+1:]...)
+		}
+		return counts
+	}
+	if revert {
+		return counts
+	}
+	counts = append(counts, nil)
+	copy(counts[i+1:], counts[i:])
+	counts[i] = &logCount{Height: height, Count: 1}
+	return counts
+}
+
+// LogQuery select the logs of a contract, optionally with a first topic, in a height range.
+// Cursor is the cursor of the previous page, the page starts after it
+type LogQuery struct {
+	Address    crypto.CommonAddress `json:"address"`
+	Topic0     *crypto.Hash         `json:"topic0"`
+	FromHeight uint64               `json:"fromHeight"`
+	ToHeight   uint64               `json:"toHeight"` // included, 0 for the chain head
+	Limit      int                  `json:"limit"`    // logs of the page, at most 1000
+	Cursor     string               `json:"cursor"`
+
+	after *txPosition
+}
+
+// LogPage is a page of logs, Cursor is empty when the range has no more logs
+type LogPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor string       `json:"cursor,omitempty"`
+}
+
+func (query *LogQuery) check() error {
+	if query.ToHeight > 0 && query.ToHeight < query.FromHeight {
+		return ErrInvalidRange
+	}
+	if query.Limit <= 0 || query.Limit > maxLogPageSize {
+		query.Limit = maxLogPageSize
+	}
+	query.after = nil
+	if query.Cursor != "" {
+		position, err := parseCursor(query.Cursor)
+		if err != nil {
+			return err
+		}
+		query.after = position
+	}
+	return nil
+}
+
+// topic0 is the topic looked up in the index
+func (query *LogQuery) topic0() *crypto.Hash {
+	if query.Topic0 == nil {
+		return &crypto.Hash{}
+	}
+	return query.Topic0
+}
+
+func (query *LogQuery) match(txLog *types.Log) bool {
+	if txLog.Address != query.Address {
+		return false
+	}
+	return query.Topic0 == nil || (len(txLog.Topics) > 0 && txLog.Topics[0] == *query.Topic0)
+}
+
+// updateLogIndex index the logs of a block, or remove them when the block is detached
+func (blockAnalysis *BlockAnalysis) updateLogIndex(block *types.Block, revert bool) {
+	if blockAnalysis.getReceipts == nil {
+		return
+	}
+	keys := blockLogKeys(block, blockAnalysis.getReceipts(*block.Header.Hash()))
+	if len(keys) == 0 {
+		return
+	}
+	if err := blockAnalysis.store.UpdateLogIndex(keys, revert); err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Warn("update log index")
+	}
+}
+
+// GetLogs return a page of the logs matching query. Only the blocks found in the log index are read,
+// at most maxLogBlocks of them
+func (blockAnalysis *BlockAnalysis) GetLogs(query *LogQuery) (*LogPage, error) {
+	if err := query.check(); err != nil {
+		return nil, err
+	}
+	if blockAnalysis.getReceipts == nil {
+		return nil, ErrNoReceipts
+	}
+	from, to := query.FromHeight, query.ToHeight
+	if to == 0 {
+		to = blockAnalysis.getHeight()
+	}
+	if query.after != nil && query.after.Height > from {
+		from = query.after.Height
+	}
+	page := &LogPage{Logs: []*types.Log{}}
+	if from > to {
+		return page, nil
+	}
+	heights, err := blockAnalysis.store.GetLogBlocks(&query.Address, query.topic0(), from, to, maxLogBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *txPosition
+	for _, height := range heights {
+		block, err := blockAnalysis.getBlock(height)
+		if err != nil {
+			return nil, ErrBlockNotFound
+		}
+		index := 0
+		for _, receipt := range blockAnalysis.getReceipts(*block.Header.Hash()) {
+			for _, txLog := range receipt.Logs {
+				position := &txPosition{Height: height, Index: index}
+				index++
+				if query.after != nil && !query.after.before(position) {
+					continue
+				}
+				if !query.match(txLog) {
+					continue
+				}
+				if len(page.Logs) == query.Limit {
+					page.Cursor = last.cursor()
+					return page, nil
+				}
+				page.Logs = append(page.Logs, txLog)
+				last = position
+			}
+		}
+		if index > 0 {
+			last = &txPosition{Height: height, Index: index - 1}
+		}
+	}
+	// the work limit is reached before the end of range
+	if len(heights) == maxLogBlocks && last != nil {
+		page.Cursor = last.cursor()
+	}
+	return page, nil
+}
@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func TestBlockLogKeys(t *testing.T) {
+	contract := crypto.HexToAddress("0x7923a30bbfbcb998a6534d56b313e68c8e0c594a")
+	transfer := crypto.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	block := &types.Block{Header: &types.BlockHeader{Height: 2050}}
+	receipts := []*types.Receipt{
+		{Logs: []*types.Log{{Address: contract, Topics: []crypto.Hash{transfer}}, {Address: contract, Topics: []crypto.Hash{transfer}}}},
+		{Logs: []*types.Log{{Address: contract}}},
+	}
+	keys := blockLogKeys(block, receipts)
+	if len(keys) != 2 {
+		t.Fatalf("%d keys, expected 2", len(keys))
+	}
+	if keys[0].Topic0 != (crypto.Hash{}) || keys[1].Topic0 != transfer || keys[1].Height != 2050 {
+		t.Fatalf("unexpected keys %v %v", keys[0], keys[1])
+	}
+	if logBucket(2050) != 2 {
+		t.Fatalf("bucket %d, expected 2", logBucket(2050))
+	}
+}
+
+func TestCountLogBlock(t *testing.T) {
+	counts := []*logCount{}
+	for _, height := range []uint64{7, 3, 5, 5} {
+		counts = countLogBlock(counts, height, false)
+	}
+	if len(counts) != 3 || counts[0].Height != 3 || counts[1].Height != 5 || counts[1].Count != 2 || counts[2].Height != 7 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+
+	// a height stays indexed until all its blocks are taken back
+	counts = countLogBlock(counts, 5, true)
+	if len(counts) != 3 || counts[1].Count != 1 {
+		t.Fatalf("height 5 counted %d", counts[1].Count)
+	}
+	counts = countLogBlock(counts, 5, true)
+	counts = countLogBlock(counts, 9, true)
+	if len(counts) != 2 || counts[0].Height != 3 || counts[1].Height != 7 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+}
+
+func TestLogQueryCheck(t *testing.T) {
+	query := &LogQuery{FromHeight: 10, ToHeight: 5}
+	if err := query.check(); err != ErrInvalidRange {
+		t.Fatalf("range accepted, %v", err)
+	}
+	query = &LogQuery{Cursor: "12-3"}
+	if err := query.check(); err != nil || query.Limit != maxLogPageSize || *query.after != (txPosition{12, 3}) {
+		t.Fatalf("unexpected query %v, %v", query, err)
+	}
+	if !query.after.before(&txPosition{12, 4}) || query.after.before(&txPosition{12, 3}) || query.after.before(&txPosition{11, 9}) {
+		t.Fatal("positions misordered")
+	}
+
+	contract := crypto.HexToAddress("0x7923a30bbfbcb998a6534d56b313e68c8e0c594a")
+	topic := crypto.HexToHash("0x01")
+	query = &LogQuery{Address: contract}
+	if *query.topic0() != (crypto.Hash{}) || !query.match(&types.Log{Address: contract}) {
+		t.Fatal("log of contract not matched without topic")
+	}
+	query.Topic0 = &topic
+	if query.match(&types.Log{Address: contract}) || !query.match(&types.Log{Address: contract, Topics: []crypto.Hash{topic}}) {
+		t.Fatal("log matched by topic0 wrongly")
+	}
+}
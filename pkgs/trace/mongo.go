@@ -3,8 +3,10 @@ package trace
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"time"
 
+	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/bft"
 	"github.com/drep-project/DREP-Chain/types"
@@ -28,6 +30,88 @@ type MongogDbStore struct {
 	viewTxCol     *mongo.Collection
 	viewBlockCol  *mongo.Collection
 	viewHeaderCol *mongo.Collection
+
+	contractStatCol   *mongo.Collection
+	contractCallerCol *mongo.Collection
+	addressStatCol    *mongo.Collection
+	internalTxCol     *mongo.Collection
+	logIndexCol       *mongo.Collection
+	metaCol           *mongo.Collection
+}
+
+// viewContractStat is a ContractStat in mongo, Day is empty for the totals over all days
+type viewContractStat struct {
+	Id       string `bson:"_id"`
+	Contract string
+	Day      string
+	Calls    int64
+	GasUsed  int64
+	Callers  int64
+}
+
+func (view *viewContractStat) toStat() *ContractStat {
+	return &ContractStat{
+		Contract: crypto.HexToAddress(view.Contract),
+		Day:      view.Day,
+		Calls:    uint64(view.Calls),
+		GasUsed:  uint64(view.GasUsed),
+		Callers:  uint64(view.Callers),
+	}
+}
+
+// viewAddressStat is the counts and amounts of an AddressStat in mongo
+type viewAddressStat struct {
+	Id             string `bson:"_id"`
+	Sent           int64
+	Received       int64
+	SentAmount     common.Big
+	ReceivedAmount common.Big
+}
+
+// viewInternalTx is an InternalTx in mongo
+type viewInternalTx struct {
+	Id     string `bson:"_id"`
+	TxHash string
+	Height uint64
+	Index  int
+	Type   string
+	From   string
+	To     string
+	Value  string
+	Depth  int
+	Error  string
+}
+
+func (view *viewInternalTx) from(internalTx *InternalTx) *viewInternalTx {
+	view.Id = fmt.Sprintf("%s/%d", internalTx.TxHash.String(), internalTx.Index)
+	view.TxHash = internalTx.TxHash.String()
+	view.Height = internalTx.Height
+	view.Index = internalTx.Index
+	view.Type = internalTx.Type
+	view.From = internalTx.From.String()
+	view.To = internalTx.To.String()
+	view.Value = internalTx.Value.ToInt().String()
+	view.Depth = internalTx.Depth
+	view.Error = internalTx.Error
+	return view
+}
+
+func (view *viewInternalTx) toInternalTx() *InternalTx {
+	value, _ := new(big.Int).SetString(view.Value, 10)
+	if value == nil {
+		value = new(big.Int)
+	}
+	return &InternalTx{
+		TxHash: crypto.HexToHash(view.TxHash),
+		Height: view.Height,
+		Index:  view.Index,
+		Type:   view.Type,
+		From:   crypto.HexToAddress(view.From),
+		To:     crypto.HexToAddress(view.To),
+		Value:  common.Big(*value),
+		Depth:  view.Depth,
+		Error:  view.Error,
+	}
 }
 
 // NewMongoDbStore open a new db from url, if db not exist, auto create
@@ -36,14 +120,15 @@ func NewMongoDbStore(url string, getProducer GetProducer, consensusMode string,
 		url:           url,
 		consensusMode: consensusMode,
 	}
-	ctx, _ := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
 	var err error
 	store.client, err = mongo.Connect(ctx, options.Client().ApplyURI(url))
 	if err != nil {
 		return nil, err
 	}
-	ctx.Done()
-	ctx, _ = context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
 	err = store.client.Ping(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -58,11 +143,35 @@ func NewMongoDbStore(url string, getProducer GetProducer, consensusMode string,
 	store.viewTxCol = store.db.Collection("view_tx")
 	store.viewBlockCol = store.db.Collection("view_block")
 	store.viewHeaderCol = store.db.Collection("view_header")
+
+	store.contractStatCol = store.db.Collection("contract_stat")
+	store.contractCallerCol = store.db.Collection("contract_caller")
+	store.addressStatCol = store.db.Collection("address_stat")
+	store.internalTxCol = store.db.Collection("internal_tx")
+	store.logIndexCol = store.db.Collection("log_index")
+	store.metaCol = store.db.Collection("meta")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = store.txCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "from", Value: 1}, {Key: "height", Value: 1}, {Key: "index", Value: 1}}},
+		{Keys: bson.D{{Key: "to", Value: 1}, {Key: "height", Value: 1}, {Key: "index", Value: 1}}},
+	})
+	if err != nil {
+		log.WithField("err", err).Warn("create address history indexes")
+	}
+	_, err = store.logIndexCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "address", Value: 1}, {Key: "topic0", Value: 1}, {Key: "height", Value: 1}},
+	})
+	if err != nil {
+		log.WithField("err", err).Warn("create log index")
+	}
 	return store, nil
 }
 
 func (store *MongogDbStore) InsertRecord(block *types.Block) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	rpcTxs := make([]interface{}, block.Data.TxCount)
 	rpcHeader := RpcBlockHeader{}
 	rpcHeader.FromBlockHeader(block.Header)
@@ -100,6 +209,7 @@ func (store *MongogDbStore) InsertRecord(block *types.Block) {
 	for index, tx := range block.Data.TxList {
 		rpcTx := &RpcTransaction{}
 		rpcTx.FromTx(tx)
+		rpcTx.Height, rpcTx.Index = block.Header.Height, index
 		rpcTxs[index] = rpcTx
 
 		viewTx := &ViewTransaction{}
@@ -112,7 +222,8 @@ func (store *MongogDbStore) InsertRecord(block *types.Block) {
 }
 
 func (store *MongogDbStore) ExistRecord(block *types.Block) (bool, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	curser, err := store.headerCol.Find(ctx, bson.M{"hash": block.Header.Hash()})
 	if err != nil {
 		return false, err
@@ -125,7 +236,8 @@ func (store *MongogDbStore) ExistRecord(block *types.Block) (bool, error) {
 }
 
 func (store *MongogDbStore) DelRecord(block *types.Block) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	store.headerCol.DeleteOne(ctx, bson.M{"hash": block.Header.Hash()})
 	store.blockCol.DeleteOne(ctx, bson.M{"hash": block.Header.Hash()})
 	store.viewBlockCol.DeleteOne(ctx, bson.M{"hash": block.Header.Hash().String()})
@@ -137,7 +249,8 @@ func (store *MongogDbStore) DelRecord(block *types.Block) {
 }
 
 func (store *MongogDbStore) GetRawTransaction(txHash *crypto.Hash) ([]byte, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	curser, err := store.txCol.Find(ctx, bson.M{"hash": txHash})
 	if err != nil {
 		return nil, err
@@ -156,7 +269,8 @@ func (store *MongogDbStore) GetRawTransaction(txHash *crypto.Hash) ([]byte, erro
 }
 
 func (store *MongogDbStore) GetTransaction(txHash *crypto.Hash) (*RpcTransaction, error) {
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	curser, err := store.txCol.Find(ctx, bson.M{"hash": txHash})
 	if err != nil {
 		return nil, err
@@ -173,17 +287,50 @@ func (store *MongogDbStore) GetTransaction(txHash *crypto.Hash) (*RpcTransaction
 	return rpcTx, nil
 }
 
-func (store *MongogDbStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
+func (store *MongogDbStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory("from", addr, pageIndex, pageSize, query)
+}
+
+func (store *MongogDbStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory("to", addr, pageIndex, pageSize, query)
+}
+
+// queryHistory return a page of the transactions whose field is addr
+func (store *MongogDbStore) queryHistory(field string, addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
 	rpcTx := []*RpcTransaction{}
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	if query == nil {
+		query = &TxQuery{}
+	}
+	heightRange := bson.M{"$gte": query.FromHeight}
+	if query.ToHeight > 0 {
+		heightRange["$lte"] = query.ToHeight
+	}
+	filter := bson.D{{Key: field, Value: addr}, {Key: "height", Value: heightRange}}
+	if query.FromTime > 0 || query.ToTime > 0 {
+		timeRange := bson.M{"$gte": query.FromTime}
+		if query.ToTime > 0 {
+			timeRange["$lte"] = query.ToTime
+		}
+		filter = append(filter, bson.E{Key: "timestamp", Value: timeRange})
+	}
+	order, compare := 1, "$gt"
+	if query.Desc {
+		order, compare = -1, "$lt"
+	}
+	if query.after != nil {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.M{"height": bson.M{compare: query.after.Height}},
+			bson.M{"height": query.after.Height, "index": bson.M{compare: query.after.Index}},
+		}})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	option := &options.FindOptions{}
+	option.SetSort(bson.D{{Key: "height", Value: order}, {Key: "index", Value: order}})
 	option.SetSkip(int64((pageIndex - 1) * pageSize))
 	option.SetLimit(int64(pageSize))
-	curser, err := store.txCol.Find(
-		ctx,
-		bson.M{"from": addr},
-		option,
-	)
+	curser, err := store.txCol.Find(ctx, filter, option)
 	if err != nil {
 		return rpcTx
 	}
@@ -191,28 +338,361 @@ func (store *MongogDbStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress
 	if err != nil {
 		return rpcTx
 	}
+	for _, tx := range rpcTx {
+		tx.Cursor = (&txPosition{tx.Height, tx.Index}).cursor()
+	}
 	return rpcTx
 }
 
-func (store *MongogDbStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
-	rpcTx := []*RpcTransaction{}
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+// SearchTransactions return up to limit hashes of transactions starting with prefix
+func (store *MongogDbStore) SearchTransactions(prefix *hashPrefix, limit int) ([]*crypto.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 	option := &options.FindOptions{}
-	option.SetSkip(int64((pageIndex - 1) * pageSize))
-	option.SetLimit(int64(pageSize))
-	curser, err := store.txCol.Find(
+	option.SetLimit(int64(limit))
+	curser, err := store.viewTxCol.Find(
 		ctx,
-		bson.M{"to": addr},
+		bson.M{"hash": bson.M{"$regex": "^0x" + prefix.digits}},
 		option,
 	)
 	if err != nil {
-		return rpcTx
+		return nil, err
 	}
-	err = curser.All(ctx, &rpcTx)
+	viewTxs := []*ViewTransaction{}
+	if err := curser.All(ctx, &viewTxs); err != nil {
+		return nil, err
+	}
+	hashes := make([]*crypto.Hash, 0, len(viewTxs))
+	for _, viewTx := range viewTxs {
+		hash := crypto.HexToHash(viewTx.Hash)
+		hashes = append(hashes, &hash)
+	}
+	return hashes, nil
+}
+
+// UpdateContractStats add the calls to the stats of their day and to the totals, or take them back when revert
+func (store *MongogDbStore) UpdateContractStats(calls []*contractCall, revert bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delta := int64(1)
+	if revert {
+		delta = -1
+	}
+	for _, call := range calls {
+		for _, day := range []string{"", call.Day} {
+			id := day + "/" + call.Contract.String()
+			caller := struct{ Count int64 }{}
+			err := store.contractCallerCol.FindOneAndUpdate(
+				ctx,
+				bson.M{"_id": id + "/" + call.Caller.String()},
+				bson.M{"$inc": bson.M{"count": delta}},
+				options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+			).Decode(&caller)
+			if err != nil {
+				return err
+			}
+
+			callers := int64(0)
+			if !revert && caller.Count == 1 {
+				callers = 1
+			} else if revert && caller.Count <= 0 {
+				if caller.Count == 0 {
+					callers = -1
+				}
+				store.contractCallerCol.DeleteOne(ctx, bson.M{"_id": id + "/" + call.Caller.String()})
+			}
+			_, err = store.contractStatCol.UpdateOne(
+				ctx,
+				bson.M{"_id": id},
+				bson.M{
+					"$set": bson.M{"contract": call.Contract.String(), "day": day},
+					"$inc": bson.M{"calls": delta, "gasused": delta * int64(call.GasUsed), "callers": callers},
+				},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetContractStat return the usage of a contract on a day, or over all days if day is empty
+func (store *MongogDbStore) GetContractStat(contract *crypto.CommonAddress, day string) (*ContractStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	view := &viewContractStat{}
+	err := store.contractStatCol.FindOne(ctx, bson.M{"_id": day + "/" + contract.String()}).Decode(view)
 	if err != nil {
-		return rpcTx
+		if err == mongo.ErrNoDocuments {
+			return &ContractStat{Contract: *contract, Day: day}, nil
+		}
+		return nil, err
 	}
-	return rpcTx
+	return view.toStat(), nil
+}
+
+// TopContracts rank the contracts used on a day, or over all days if day is empty
+func (store *MongogDbStore) TopContracts(day string, orderBy string, limit int) ([]*ContractStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	field := map[string]string{OrderByGas: "gasused", OrderByCalls: "calls", OrderByCallers: "callers"}[orderBy]
+	option := &options.FindOptions{}
+	option.SetSort(bson.M{field: -1})
+	option.SetLimit(int64(limit))
+	curser, err := store.contractStatCol.Find(
+		ctx,
+		bson.M{"day": day, "calls": bson.M{"$gt": 0}},
+		option,
+	)
+	if err != nil {
+		return nil, err
+	}
+	views := []*viewContractStat{}
+	if err := curser.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	stats := make([]*ContractStat, 0, len(views))
+	for _, view := range views {
+		stats = append(stats, view.toStat())
+	}
+	return stats, nil
+}
+
+// UpdateAddressStats add the transfers to the stats of their sender and recipient, or take them back when revert
+func (store *MongogDbStore) UpdateAddressStats(transfers []*addressTransfer, revert bool) error {
+	stats := map[crypto.CommonAddress]*AddressStat{}
+	apply := func(addr *crypto.CommonAddress, sent bool, amount *big.Int) error {
+		stat, ok := stats[*addr]
+		if !ok {
+			var err error
+			if stat, err = store.getAddressStat(addr); err != nil {
+				return err
+			}
+			stats[*addr] = stat
+		}
+		stat.apply(sent, amount, revert)
+		return nil
+	}
+	for _, transfer := range transfers {
+		if err := apply(&transfer.From, true, transfer.Amount); err != nil {
+			return err
+		}
+		if transfer.To != nil {
+			if err := apply(transfer.To, false, transfer.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for addr, stat := range stats {
+		view := &viewAddressStat{
+			Id:             addr.String(),
+			Sent:           int64(stat.Sent),
+			Received:       int64(stat.Received),
+			SentAmount:     stat.SentAmount,
+			ReceivedAmount: stat.ReceivedAmount,
+		}
+		_, err := store.addressStatCol.ReplaceOne(ctx, bson.M{"_id": view.Id}, view, options.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAddressStat return the stat of an address, the heights are read from its transactions
+func (store *MongogDbStore) GetAddressStat(addr *crypto.CommonAddress) (*AddressStat, error) {
+	stat, err := store.getAddressStat(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{"$or": bson.A{bson.M{"from": addr}, bson.M{"to": addr}}}
+	for _, order := range []int{1, -1} {
+		tx := struct{ Height uint64 }{}
+		option := options.FindOne().SetSort(bson.D{{Key: "height", Value: order}})
+		if err := store.txCol.FindOne(ctx, filter, option).Decode(&tx); err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			return nil, err
+		}
+		if order == 1 {
+			stat.FirstHeight = tx.Height
+		} else {
+			stat.LastHeight = tx.Height
+		}
+	}
+	return stat, nil
+}
+
+func (store *MongogDbStore) getAddressStat(addr *crypto.CommonAddress) (*AddressStat, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	view := &viewAddressStat{}
+	err := store.addressStatCol.FindOne(ctx, bson.M{"_id": addr.String()}).Decode(view)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &AddressStat{Address: *addr}, nil
+		}
+		return nil, err
+	}
+	return &AddressStat{
+		Address:        *addr,
+		Sent:           uint64(view.Sent),
+		Received:       uint64(view.Received),
+		SentAmount:     view.SentAmount,
+		ReceivedAmount: view.ReceivedAmount,
+	}, nil
+}
+
+// InsertInternalTxs save internal transactions
+func (store *MongogDbStore) InsertInternalTxs(internalTxs []*InternalTx) error {
+	if len(internalTxs) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	views := make([]interface{}, len(internalTxs))
+	for index, internalTx := range internalTxs {
+		views[index] = (&viewInternalTx{}).from(internalTx)
+	}
+	_, err := store.internalTxCol.InsertMany(ctx, views, nil)
+	return err
+}
+
+// DelInternalTxs remove the internal transactions of the transactions in block
+func (store *MongogDbStore) DelInternalTxs(block *types.Block) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, tx := range block.Data.TxList {
+		if _, err := store.internalTxCol.DeleteMany(ctx, bson.M{"txhash": tx.TxHash().String()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetInternalTxs return the internal transactions of a transaction in call order
+func (store *MongogDbStore) GetInternalTxs(txHash *crypto.Hash) ([]*InternalTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	option := &options.FindOptions{}
+	option.SetSort(bson.M{"index": 1})
+	return store.findInternalTxs(ctx, bson.M{"txhash": txHash.String()}, option)
+}
+
+// GetAddressInternalTxs return a page of the internal transactions from or to addr, oldest first
+func (store *MongogDbStore) GetAddressInternalTxs(addr *crypto.CommonAddress, pageIndex, pageSize int) ([]*InternalTx, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	option := &options.FindOptions{}
+	option.SetSort(bson.D{{Key: "height", Value: 1}, {Key: "txhash", Value: 1}, {Key: "index", Value: 1}})
+	option.SetSkip(int64((pageIndex - 1) * pageSize))
+	option.SetLimit(int64(pageSize))
+	return store.findInternalTxs(ctx, bson.M{"$or": bson.A{bson.M{"from": addr.String()}, bson.M{"to": addr.String()}}}, option)
+}
+
+func (store *MongogDbStore) findInternalTxs(ctx context.Context, filter interface{}, option *options.FindOptions) ([]*InternalTx, error) {
+	curser, err := store.internalTxCol.Find(ctx, filter, option)
+	if err != nil {
+		return nil, err
+	}
+	views := []*viewInternalTx{}
+	if err := curser.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	internalTxs := make([]*InternalTx, 0, len(views))
+	for _, view := range views {
+		internalTxs = append(internalTxs, view.toInternalTx())
+	}
+	return internalTxs, nil
+}
+
+// UpdateLogIndex count the blocks with the keys, or take them back when revert. The blocks of a key
+// are a document for each height, found by the index on address, topic0 and height
+func (store *MongogDbStore) UpdateLogIndex(keys []*logKey, revert bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delta := int64(1)
+	if revert {
+		delta = -1
+	}
+	for _, key := range keys {
+		id := fmt.Sprintf("%s/%s/%d", key.Address.String(), key.Topic0.String(), key.Height)
+		count := struct{ Count int64 }{}
+		err := store.logIndexCol.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": id},
+			bson.M{
+				"$set": bson.M{"address": key.Address.String(), "topic0": key.Topic0.String(), "height": int64(key.Height)},
+				"$inc": bson.M{"count": delta},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&count)
+		if err != nil {
+			return err
+		}
+		if count.Count <= 0 {
+			store.logIndexCol.DeleteOne(ctx, bson.M{"_id": id})
+		}
+	}
+	return nil
+}
+
+// GetLogBlocks return up to limit heights of the blocks with logs of addr and topic0 in [fromHeight, toHeight], ascending
+func (store *MongogDbStore) GetLogBlocks(addr *crypto.CommonAddress, topic0 *crypto.Hash, fromHeight, toHeight uint64, limit int) ([]uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	option := &options.FindOptions{}
+	option.SetSort(bson.D{{Key: "height", Value: 1}})
+	option.SetLimit(int64(limit))
+	curser, err := store.logIndexCol.Find(ctx, bson.M{
+		"address": addr.String(),
+		"topic0":  topic0.String(),
+		"height":  bson.M{"$gte": int64(fromHeight), "$lte": int64(toHeight)},
+	}, option)
+	if err != nil {
+		return nil, err
+	}
+	views := []struct{ Height int64 }{}
+	if err := curser.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	heights := make([]uint64, 0, len(views))
+	for _, view := range views {
+		heights = append(heights, uint64(view.Height))
+	}
+	return heights, nil
+}
+
+// GetMeta return a value describing the store, nil if not set
+func (store *MongogDbStore) GetMeta(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	meta := struct{ Value []byte }{}
+	err := store.metaCol.FindOne(ctx, bson.M{"_id": key}).Decode(&meta)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return meta.Value, err
+}
+
+func (store *MongogDbStore) PutMeta(key string, value []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := store.metaCol.UpdateOne(
+		ctx,
+		bson.M{"_id": key},
+		bson.M{"$set": bson.M{"value": value}},
+		options.Update().SetUpsert(true),
+	)
+	return err
 }
 
 // Close disconnect db connection
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/drep-project/DREP-Chain/common/math"
 	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/pkgs/consensus/service/bft"
+	consensusTypes "github.com/drep-project/DREP-Chain/pkgs/consensus/types"
 	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
 	"testing"
 )
 
@@ -13,14 +16,24 @@ var (
 	testMongoUrl = "mongodb://localhost:27017"
 )
 
-func makeMongoData(dbName string) (*MongogDbStore, []*types.Block) {
-	mongoStore, err := NewMongoDbStore(testMongoUrl, dbName)
+// makeMongoData insert random blocks in the mongo db dbName, the test is skipped when no mongo
+// server listens at testMongoUrl. The blocks carry a pbft proof signed by their miner alone
+func makeMongoData(t *testing.T, dbName string) (*MongogDbStore, []*types.Block) {
+	getProducer := func(root []byte, num int) ([]crypto.CommonAddress, error) {
+		return []crypto.CommonAddress{senderAddress()}, nil
+	}
+	mongoStore, err := NewMongoDbStore(testMongoUrl, getProducer, "bft", dbName)
 	if err != nil {
-		fmt.Println(err)
+		t.Skip("mongo not available:", err)
+	}
+	evidence, err := binary.Marshal(&bft.MultiSignature{Bitmap: []byte{1}})
+	if err != nil {
+		t.Fatal(err)
 	}
 	testData := []*types.Block{}
 	for i := 1; i < 10; i++ {
 		block := randomBlock()
+		block.Proof = types.Proof{Type: consensusTypes.Pbft, Evidence: evidence}
 		testData = append(testData, block)
 		mongoStore.InsertRecord(block)
 	}
@@ -29,7 +42,7 @@ func makeMongoData(dbName string) (*MongogDbStore, []*types.Block) {
 
 func Test_MongoInsertAndExistRecord(t *testing.T) {
 	db := "drep_test_1"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -47,7 +60,7 @@ func Test_MongoInsertAndExistRecord(t *testing.T) {
 
 func Test_MongoInsertAndDelRecord(t *testing.T) {
 	db := "drep_test_2"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -69,7 +82,7 @@ func Test_MongoInsertAndDelRecord(t *testing.T) {
 
 func Test_MongoInsertAndGetRawTransaction(t *testing.T) {
 	db := "drep_test_3"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -90,7 +103,7 @@ func Test_MongoInsertAndGetRawTransaction(t *testing.T) {
 
 func Test_MongoInsertAndGetTransaction(t *testing.T) {
 	db := "drep_test_4"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -111,7 +124,7 @@ func Test_MongoInsertAndGetTransaction(t *testing.T) {
 
 func Test_MongoInsertAndGetSendTransactionsByAddr(t *testing.T) {
 	db := "drep_test_6"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -121,8 +134,8 @@ func Test_MongoInsertAndGetSendTransactionsByAddr(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	fromAddr := crypto.String2Address(fromAddr)
-	all := mongoStore.GetSendTransactionsByAddr(&fromAddr, 1, math.MaxInt32)
+	fromAddr := senderAddress()
+	all := mongoStore.GetSendTransactionsByAddr(&fromAddr, 1, math.MaxInt32, nil)
 	if len(all) != allCount {
 		t.Errorf("The total number of transactions does not match, real count %d but got %d", allCount, len(all))
 	}
@@ -144,7 +157,7 @@ func Test_MongoInsertAndGetSendTransactionsByAddr(t *testing.T) {
 
 func Test_MongoGetSendTransactionsByAddrAndPagination(t *testing.T) {
 	db := "drep_test_7"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -154,12 +167,12 @@ func Test_MongoGetSendTransactionsByAddrAndPagination(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	fromAddr := crypto.String2Address(fromAddr)
-	all := mongoStore.GetSendTransactionsByAddr(&fromAddr, 1, 3)
+	fromAddr := senderAddress()
+	all := mongoStore.GetSendTransactionsByAddr(&fromAddr, 1, 3, nil)
 	if len(all) != 3 {
 		t.Error("paging failure")
 	}
-	all = mongoStore.GetSendTransactionsByAddr(&fromAddr, 2, 3)
+	all = mongoStore.GetSendTransactionsByAddr(&fromAddr, 2, 3, nil)
 	if len(all) != 3 {
 		t.Error("paging failure")
 	}
@@ -167,7 +180,7 @@ func Test_MongoGetSendTransactionsByAddrAndPagination(t *testing.T) {
 
 func Test_MongoInsertAndGetReceiveTransactionsByAddr(t *testing.T) {
 	db := "drep_test_8"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -177,8 +190,8 @@ func Test_MongoInsertAndGetReceiveTransactionsByAddr(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	toAddr := crypto.String2Address(toAddr)
-	all := mongoStore.GetReceiveTransactionsByAddr(&toAddr, 1, math.MaxInt32)
+	toAddr := crypto.HexToAddress(toAddr)
+	all := mongoStore.GetReceiveTransactionsByAddr(&toAddr, 1, math.MaxInt32, nil)
 	if len(all) != allCount {
 		t.Errorf("The total number of receive transactions does not match, real count %d but got %d", allCount, len(all))
 	}
@@ -200,7 +213,7 @@ func Test_MongoInsertAndGetReceiveTransactionsByAddr(t *testing.T) {
 
 func Test_MongoGetReceiveTransactionsByAddrAndPagination(t *testing.T) {
 	db := "drep_test_9"
-	mongoStore, testData := makeMongoData(db)
+	mongoStore, testData := makeMongoData(t, db)
 	defer func() {
 		deleteDb(mongoStore)
 		mongoStore.Close()
@@ -210,12 +223,12 @@ func Test_MongoGetReceiveTransactionsByAddrAndPagination(t *testing.T) {
 	for _, data := range testData {
 		allCount = allCount + int(data.Data.TxCount)
 	}
-	toAddr := crypto.String2Address(toAddr)
-	all := mongoStore.GetReceiveTransactionsByAddr(&toAddr, 1, 3)
+	toAddr := crypto.HexToAddress(toAddr)
+	all := mongoStore.GetReceiveTransactionsByAddr(&toAddr, 1, 3, nil)
 	if len(all) != 3 {
 		t.Error("receive paging failure")
 	}
-	all = mongoStore.GetReceiveTransactionsByAddr(&toAddr, 2, 3)
+	all = mongoStore.GetReceiveTransactionsByAddr(&toAddr, 2, 3, nil)
 	if len(all) != 3 {
 		t.Error("receive paging failure")
 	}
@@ -0,0 +1,159 @@
+package trace
+
+import (
+	"sync"
+
+	"github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/common/event"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+// maxPendingRecords bounds the transactions tracked, the oldest ones are forgotten first
+const maxPendingRecords = 20000
+
+// Status of a transaction tracked in the pool
+const (
+	PendingStatusPending  = "pending"
+	PendingStatusReplaced = "replaced"
+	PendingStatusDropped  = "dropped"
+)
+
+// PendingTx is a transaction seen in the pool and not packed yet
+type PendingTx struct {
+	blockmgr.PoolEvent
+	Status    string `json:"status"`
+	FirstSeen int64  `json:"firstSeen"`
+}
+
+// PendingTracker follow the pool events, so the transactions not packed yet are queried
+// without polling the pool. Packed transactions are forgotten
+type PendingTracker struct {
+	lock  sync.RWMutex
+	txs   map[crypto.Hash]*PendingTx
+	order []crypto.Hash
+	limit int
+
+	poolEventCh chan types.TxPoolEvent
+	newBlockCh  chan *types.ChainEvent
+	poolSub     event.Subscription
+	newBlockSub event.Subscription
+	quit        chan struct{}
+}
+
+func NewPendingTracker(limit int) *PendingTracker {
+	return &PendingTracker{
+		txs:         make(map[crypto.Hash]*PendingTx),
+		limit:       limit,
+		poolEventCh: make(chan types.TxPoolEvent, 1024),
+		newBlockCh:  make(chan *types.ChainEvent, 100),
+		quit:        make(chan struct{}),
+	}
+}
+
+// Start follow the pool events and forget the transactions of new blocks
+func (tracker *PendingTracker) Start(poolEvent, newBlock *event.Feed) {
+	tracker.poolSub = poolEvent.Subscribe(tracker.poolEventCh)
+	tracker.newBlockSub = newBlock.Subscribe(tracker.newBlockCh)
+	go func() {
+		for {
+			select {
+			case ev := <-tracker.poolEventCh:
+				tracker.apply(blockmgr.NewPoolEvent(&ev))
+			case chainEvent := <-tracker.newBlockCh:
+				tracker.included(chainEvent.Block)
+			case <-tracker.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (tracker *PendingTracker) Close() {
+	if tracker.poolSub != nil {
+		tracker.poolSub.Unsubscribe()
+	}
+	if tracker.newBlockSub != nil {
+		tracker.newBlockSub.Unsubscribe()
+	}
+	close(tracker.quit)
+}
+
+func (tracker *PendingTracker) apply(ev *blockmgr.PoolEvent) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	tx, ok := tracker.txs[ev.Hash]
+	if !ok {
+		tx = &PendingTx{FirstSeen: ev.Time}
+		tracker.txs[ev.Hash] = tx
+		tracker.order = append(tracker.order, ev.Hash)
+		tracker.evict()
+	}
+	tx.PoolEvent = *ev
+	switch ev.Type {
+	case types.TxPoolEventReplaced:
+		tx.Status = PendingStatusReplaced
+	case types.TxPoolEventDropped:
+		tx.Status = PendingStatusDropped
+	default:
+		tx.Status = PendingStatusPending
+	}
+}
+
+// evict forget the oldest transactions over the limit
+func (tracker *PendingTracker) evict() {
+	for len(tracker.txs) > tracker.limit && len(tracker.order) > 0 {
+		delete(tracker.txs, tracker.order[0])
+		tracker.order = tracker.order[1:]
+	}
+}
+
+// included forget the transactions packed in block
+func (tracker *PendingTracker) included(block *types.Block) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	for _, tx := range block.Data.TxList {
+		delete(tracker.txs, *tx.TxHash())
+	}
+	//compact the order when most of it are forgotten
+	if len(tracker.order) > 2*len(tracker.txs)+tracker.limit/10 {
+		order := make([]crypto.Hash, 0, len(tracker.txs))
+		for _, hash := range tracker.order {
+			if _, ok := tracker.txs[hash]; ok {
+				order = append(order, hash)
+			}
+		}
+		tracker.order = order
+	}
+}
+
+// Get return the tracked state of transaction hash, nil if it is packed or never seen
+func (tracker *PendingTracker) Get(hash crypto.Hash) *PendingTx {
+	tracker.lock.RLock()
+	defer tracker.lock.RUnlock()
+	tx, ok := tracker.txs[hash]
+	if !ok {
+		return nil
+	}
+	copied := *tx
+	return &copied
+}
+
+// BySender return the tracked transactions of addr, oldest first
+func (tracker *PendingTracker) BySender(addr crypto.CommonAddress, limit int) []*PendingTx {
+	tracker.lock.RLock()
+	defer tracker.lock.RUnlock()
+	txs := []*PendingTx{}
+	for _, hash := range tracker.order {
+		tx, ok := tracker.txs[hash]
+		if !ok || tx.From == nil || *tx.From != addr {
+			continue
+		}
+		copied := *tx
+		txs = append(txs, &copied)
+		if limit > 0 && len(txs) >= limit {
+			break
+		}
+	}
+	return txs
+}
@@ -0,0 +1,50 @@
+package trace
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/blockmgr"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+func poolEvent(typ string, hash byte, from crypto.CommonAddress) *blockmgr.PoolEvent {
+	return &blockmgr.PoolEvent{Type: typ, Hash: crypto.Hash{hash}, From: &from, Time: int64(hash)}
+}
+
+func TestPendingTracker(t *testing.T) {
+	tracker := NewPendingTracker(2)
+	alice := crypto.HexToAddress("0x0000000000000000000000000000000000000001")
+	bob := crypto.HexToAddress("0x0000000000000000000000000000000000000002")
+
+	tracker.apply(poolEvent(types.TxPoolEventAdded, 1, alice))
+	tracker.apply(poolEvent(types.TxPoolEventAdded, 2, bob))
+	tracker.apply(poolEvent(types.TxPoolEventReplaced, 1, alice))
+	tx := tracker.Get(crypto.Hash{1})
+	if tx == nil || tx.Status != PendingStatusReplaced || tx.FirstSeen != 1 {
+		t.Fatalf("unexpected tracked tx %+v", tx)
+	}
+	if txs := tracker.BySender(bob, 0); len(txs) != 1 || txs[0].Status != PendingStatusPending {
+		t.Fatalf("got %d txs of sender, want 1 pending", len(txs))
+	}
+
+	// over the limit the oldest is forgotten
+	tracker.apply(poolEvent(types.TxPoolEventDropped, 3, alice))
+	if tracker.Get(crypto.Hash{1}) != nil {
+		t.Fatal("oldest tx not forgotten over the limit")
+	}
+	if tx := tracker.Get(crypto.Hash{3}); tx == nil || tx.Status != PendingStatusDropped {
+		t.Fatal("dropped tx not tracked")
+	}
+
+	// packed transactions are forgotten
+	packed := types.NewTransaction(bob, big.NewInt(1), big.NewInt(1), big.NewInt(1), 0)
+	ev := poolEvent(types.TxPoolEventAdded, 4, bob)
+	ev.Hash = *packed.TxHash()
+	tracker.apply(ev)
+	tracker.included(&types.Block{Data: &types.BlockData{TxList: []*types.Transaction{packed}}})
+	if tracker.Get(*packed.TxHash()) != nil {
+		t.Fatal("packed tx still tracked")
+	}
+}
@@ -0,0 +1,654 @@
+package trace
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/pkgs/evm/vm"
+	"github.com/drep-project/DREP-Chain/types"
+	"github.com/drep-project/binary"
+	"github.com/lib/pq"
+)
+
+const pgMaxParams = 65535 // parameters of one statement
+
+// postgresSchema is normalized for sql joins, the rows of a block are removed with it.
+// transfers hold the value transfer of each transaction at idx 0 and its internal
+// transactions from idx 1, the internal transactions are kept when a block is recorded
+// again so transfers do not reference txs. Addresses and hashes are lower case hex
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS blocks (
+	hash       TEXT PRIMARY KEY,
+	height     BIGINT NOT NULL,
+	previous   TEXT NOT NULL,
+	chain_id   BIGINT NOT NULL,
+	version    INTEGER NOT NULL,
+	miner      TEXT NOT NULL,
+	gas_limit  NUMERIC NOT NULL,
+	gas_used   NUMERIC NOT NULL,
+	timestamp  BIGINT NOT NULL,
+	state_root TEXT NOT NULL,
+	tx_root    TEXT NOT NULL,
+	tx_count   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS blocks_height ON blocks (height);
+
+CREATE TABLE IF NOT EXISTS txs (
+	hash       TEXT PRIMARY KEY,
+	block_hash TEXT NOT NULL REFERENCES blocks (hash) ON DELETE CASCADE,
+	height     BIGINT NOT NULL,
+	idx        INTEGER NOT NULL,
+	sender     TEXT NOT NULL,
+	recipient  TEXT NOT NULL,
+	type       INTEGER NOT NULL,
+	nonce      NUMERIC NOT NULL,
+	amount     NUMERIC NOT NULL,
+	gas_price  NUMERIC NOT NULL,
+	gas_limit  NUMERIC NOT NULL,
+	gas_used   BIGINT,
+	status     BIGINT,
+	timestamp  BIGINT NOT NULL,
+	data       BYTEA,
+	raw        BYTEA NOT NULL
+);
+CREATE INDEX IF NOT EXISTS txs_hash_prefix ON txs (hash text_pattern_ops);
+CREATE INDEX IF NOT EXISTS txs_sender ON txs (sender, height, idx);
+CREATE INDEX IF NOT EXISTS txs_recipient ON txs (recipient, height, idx);
+
+CREATE TABLE IF NOT EXISTS transfers (
+	tx_hash   TEXT NOT NULL,
+	idx       INTEGER NOT NULL,
+	height    BIGINT NOT NULL,
+	type      TEXT NOT NULL,
+	sender    TEXT NOT NULL,
+	recipient TEXT NOT NULL,
+	amount    NUMERIC NOT NULL,
+	depth     INTEGER NOT NULL,
+	error     TEXT NOT NULL,
+	PRIMARY KEY (tx_hash, idx)
+);
+CREATE INDEX IF NOT EXISTS transfers_sender ON transfers (sender, height);
+CREATE INDEX IF NOT EXISTS transfers_recipient ON transfers (recipient, height);
+
+CREATE TABLE IF NOT EXISTS logs (
+	tx_hash TEXT NOT NULL REFERENCES txs (hash) ON DELETE CASCADE,
+	idx     INTEGER NOT NULL,
+	height  BIGINT NOT NULL,
+	address TEXT NOT NULL,
+	topics  TEXT[] NOT NULL,
+	data    BYTEA,
+	PRIMARY KEY (tx_hash, idx)
+);
+CREATE INDEX IF NOT EXISTS logs_address ON logs (address, height);
+CREATE INDEX IF NOT EXISTS logs_topic0 ON logs (address, (topics[1]), height);
+
+CREATE TABLE IF NOT EXISTS contract_stats (
+	contract TEXT NOT NULL,
+	day      TEXT NOT NULL,
+	calls    BIGINT NOT NULL,
+	gas_used BIGINT NOT NULL,
+	callers  BIGINT NOT NULL,
+	PRIMARY KEY (contract, day)
+);
+
+CREATE TABLE IF NOT EXISTS contract_callers (
+	contract TEXT NOT NULL,
+	day      TEXT NOT NULL,
+	caller   TEXT NOT NULL,
+	calls    BIGINT NOT NULL,
+	PRIMARY KEY (contract, day, caller)
+);
+
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value BYTEA
+);
+`
+
+// PostgresStore save the chain history to PostgreSQL, so it can be queried with sql.
+// The rows of a block are inserted in one database transaction with multi row statements
+type PostgresStore struct {
+	url         string
+	db          *sql.DB
+	getReceipts GetReceipts
+}
+
+// NewPostgresStore connect to the database of url and create the tables not existing yet
+func NewPostgresStore(url string, getReceipts GetReceipts) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &PostgresStore{url: url, db: db, getReceipts: getReceipts}, nil
+}
+
+func (store *PostgresStore) ExistRecord(block *types.Block) (bool, error) {
+	exist := false
+	err := store.db.QueryRow("SELECT EXISTS (SELECT 1 FROM blocks WHERE hash = $1)", pgHash(block.Header.Hash())).Scan(&exist)
+	return exist, err
+}
+
+// InsertRecord save the block, its transactions, their value transfers and logs
+func (store *PostgresStore) InsertRecord(block *types.Block) {
+	err := store.inTx(func(tx *sql.Tx) error {
+		header := block.Header
+		blockHash := pgHash(header.Hash())
+		_, err := tx.Exec(
+			`INSERT INTO blocks (hash, height, previous, chain_id, version, miner, gas_limit, gas_used, timestamp, state_root, tx_root, tx_count)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT (hash) DO NOTHING`,
+			blockHash, header.Height, pgHash(&header.PreviousHash), header.ChainId, header.Version, pgAddress(&header.MinerAddr),
+			header.GasLimit.String(), header.GasUsed.String(), header.Timestamp, pgBytes(header.StateRoot), pgBytes(header.TxRoot), len(block.Data.TxList),
+		)
+		if err != nil {
+			return err
+		}
+
+		receipts := map[crypto.Hash]*types.Receipt{}
+		if store.getReceipts != nil {
+			for _, receipt := range store.getReceipts(*header.Hash()) {
+				receipts[receipt.TxHash] = receipt
+			}
+		}
+		txRows, transferRows, logRows := [][]interface{}{}, [][]interface{}{}, [][]interface{}{}
+		for index, transaction := range block.Data.TxList {
+			txHash := pgHash(transaction.TxHash())
+			from, err := transaction.From()
+			if err != nil {
+				return err
+			}
+			receipt := receipts[*transaction.TxHash()]
+			var gasUsed, status interface{}
+			if receipt != nil {
+				gasUsed, status = receipt.GasUsed, receipt.Status
+			}
+			txRows = append(txRows, []interface{}{
+				txHash, blockHash, header.Height, index, pgAddress(from), pgAddress(transaction.To()), int(transaction.Type()),
+				transaction.Nonce(), transaction.Amount().String(), transaction.GasPrice().String(), transaction.GasLimit().String(),
+				gasUsed, status, transaction.Data.Timestamp, transaction.GetData(), transaction.AsPersistentMessage(),
+			})
+
+			if transfer := pgTransfer(transaction, receipt); transfer != "" {
+				to := transaction.To()
+				if transaction.Type() == types.CreateContractType {
+					to = &receipt.ContractAddress
+				}
+				transferRows = append(transferRows, []interface{}{
+					txHash, 0, header.Height, transfer, pgAddress(from), pgAddress(to), transaction.Amount().String(), 0, "",
+				})
+			}
+			if receipt != nil {
+				for logIndex, txLog := range receipt.Logs {
+					topics := make([]string, len(txLog.Topics))
+					for i := range txLog.Topics {
+						topics[i] = pgHash(&txLog.Topics[i])
+					}
+					logRows = append(logRows, []interface{}{txHash, logIndex, header.Height, pgAddress(&txLog.Address), pq.Array(topics), txLog.Data})
+				}
+			}
+		}
+
+		if err := bulkInsert(tx, "txs", []string{"hash", "block_hash", "height", "idx", "sender", "recipient", "type", "nonce", "amount", "gas_price", "gas_limit", "gas_used", "status", "timestamp", "data", "raw"}, txRows, "ON CONFLICT (hash) DO NOTHING"); err != nil {
+			return err
+		}
+		if err := bulkInsert(tx, "transfers", []string{"tx_hash", "idx", "height", "type", "sender", "recipient", "amount", "depth", "error"}, transferRows, "ON CONFLICT (tx_hash, idx) DO NOTHING"); err != nil {
+			return err
+		}
+		return bulkInsert(tx, "logs", []string{"tx_hash", "idx", "height", "address", "topics", "data"}, logRows, "ON CONFLICT (tx_hash, idx) DO NOTHING")
+	})
+	if err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Error("insert record to postgres")
+	}
+}
+
+// DelRecord remove the block, the rows of its transactions are removed with it
+func (store *PostgresStore) DelRecord(block *types.Block) {
+	err := store.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM transfers WHERE tx_hash = ANY ($1) AND idx = 0", pq.Array(pgTxHashes(block))); err != nil {
+			return err
+		}
+		_, err := tx.Exec("DELETE FROM blocks WHERE hash = $1", pgHash(block.Header.Hash()))
+		return err
+	})
+	if err != nil {
+		log.WithField("err", err).WithField("height", block.Header.Height).Error("delete record from postgres")
+	}
+}
+
+func (store *PostgresStore) GetRawTransaction(txHash *crypto.Hash) ([]byte, error) {
+	raw := []byte{}
+	err := store.db.QueryRow("SELECT raw FROM txs WHERE hash = $1", pgHash(txHash)).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, ErrTxNotFound
+	}
+	return raw, err
+}
+
+func (store *PostgresStore) GetTransaction(txHash *crypto.Hash) (*RpcTransaction, error) {
+	raw, err := store.GetRawTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	return pgRpcTransaction(raw)
+}
+
+func (store *PostgresStore) GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory("sender", addr, pageIndex, pageSize, query)
+}
+
+func (store *PostgresStore) GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	return store.queryHistory("recipient", addr, pageIndex, pageSize, query)
+}
+
+// queryHistory return a page of the transactions whose column is addr
+func (store *PostgresStore) queryHistory(column string, addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction {
+	txs := []*RpcTransaction{}
+	if pageIndex < 1 || pageSize <= 0 {
+		return txs
+	}
+	if query == nil {
+		query = &TxQuery{}
+	}
+	args := []interface{}{pgAddress(addr), query.FromHeight, query.FromTime}
+	where := []string{column + " = $1", "height >= $2", "timestamp >= $3"}
+	if query.ToHeight > 0 {
+		args = append(args, query.ToHeight)
+		where = append(where, fmt.Sprintf("height <= $%d", len(args)))
+	}
+	if query.ToTime > 0 {
+		args = append(args, query.ToTime)
+		where = append(where, fmt.Sprintf("timestamp <= $%d", len(args)))
+	}
+	order, compare := "ASC", ">"
+	if query.Desc {
+		order, compare = "DESC", "<"
+	}
+	if query.after != nil {
+		args = append(args, query.after.Height, query.after.Index)
+		where = append(where, fmt.Sprintf("(height, idx) %s ($%d, $%d)", compare, len(args)-1, len(args)))
+	}
+	args = append(args, pageSize, (pageIndex-1)*pageSize)
+	statement := fmt.Sprintf("SELECT raw, height, idx FROM txs WHERE %s ORDER BY height %s, idx %s LIMIT $%d OFFSET $%d",
+		strings.Join(where, " AND "), order, order, len(args)-1, len(args))
+
+	rows, err := store.db.Query(statement, args...)
+	if err != nil {
+		log.WithField("err", err).Error("query address history from postgres")
+		return txs
+	}
+	defer rows.Close()
+	for rows.Next() {
+		raw, position := []byte{}, &txPosition{}
+		if err := rows.Scan(&raw, &position.Height, &position.Index); err != nil {
+			break
+		}
+		tx, err := pgRpcTransaction(raw)
+		if err != nil {
+			break
+		}
+		tx.Height, tx.Index, tx.Cursor = position.Height, position.Index, position.cursor()
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// SearchTransactions return up to limit hashes of transactions starting with prefix
+func (store *PostgresStore) SearchTransactions(prefix *hashPrefix, limit int) ([]*crypto.Hash, error) {
+	rows, err := store.db.Query("SELECT hash FROM txs WHERE hash LIKE $1 LIMIT $2", "0x"+prefix.digits+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hashes := []*crypto.Hash{}
+	for rows.Next() {
+		value := ""
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		hash := crypto.HexToHash(value)
+		hashes = append(hashes, &hash)
+	}
+	return hashes, rows.Err()
+}
+
+// UpdateContractStats add the calls to the stats of their day and to the totals, or take them back when revert
+func (store *PostgresStore) UpdateContractStats(calls []*contractCall, revert bool) error {
+	return store.inTx(func(tx *sql.Tx) error {
+		for _, call := range calls {
+			contract, caller := pgAddress(&call.Contract), pgAddress(&call.Caller)
+			for _, day := range []string{"", call.Day} {
+				if !revert {
+					count := int64(0)
+					err := tx.QueryRow(
+						`INSERT INTO contract_callers (contract, day, caller, calls) VALUES ($1, $2, $3, 1)
+						ON CONFLICT (contract, day, caller) DO UPDATE SET calls = contract_callers.calls + 1 RETURNING calls`,
+						contract, day, caller,
+					).Scan(&count)
+					if err != nil {
+						return err
+					}
+					callers := 0
+					if count == 1 {
+						callers = 1
+					}
+					_, err = tx.Exec(
+						`INSERT INTO contract_stats (contract, day, calls, gas_used, callers) VALUES ($1, $2, 1, $3, $4)
+						ON CONFLICT (contract, day) DO UPDATE SET calls = contract_stats.calls + 1,
+						gas_used = contract_stats.gas_used + $3, callers = contract_stats.callers + $4`,
+						contract, day, call.GasUsed, callers,
+					)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+
+				count := int64(0)
+				err := tx.QueryRow(
+					"UPDATE contract_callers SET calls = calls - 1 WHERE contract = $1 AND day = $2 AND caller = $3 RETURNING calls",
+					contract, day, caller,
+				).Scan(&count)
+				if err != nil && err != sql.ErrNoRows {
+					return err
+				}
+				callers := 0
+				if err == nil && count <= 0 {
+					callers = 1
+					if _, err := tx.Exec("DELETE FROM contract_callers WHERE contract = $1 AND day = $2 AND caller = $3", contract, day, caller); err != nil {
+						return err
+					}
+				}
+				_, err = tx.Exec(
+					`UPDATE contract_stats SET calls = GREATEST(calls - 1, 0), gas_used = GREATEST(gas_used - $3, 0),
+					callers = GREATEST(callers - $4, 0) WHERE contract = $1 AND day = $2`,
+					contract, day, call.GasUsed, callers,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// GetContractStat return the usage of a contract on a day, or over all days if day is empty
+func (store *PostgresStore) GetContractStat(contract *crypto.CommonAddress, day string) (*ContractStat, error) {
+	stat := &ContractStat{Contract: *contract, Day: day}
+	err := store.db.QueryRow(
+		"SELECT calls, gas_used, callers FROM contract_stats WHERE contract = $1 AND day = $2",
+		pgAddress(contract), day,
+	).Scan(&stat.Calls, &stat.GasUsed, &stat.Callers)
+	if err == sql.ErrNoRows {
+		return stat, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+// UpdateAddressStats do nothing, the stats are aggregated from the txs table indexed by sender and recipient
+func (store *PostgresStore) UpdateAddressStats(transfers []*addressTransfer, revert bool) error {
+	return nil
+}
+
+// GetAddressStat aggregate the transactions sent and received by an address
+func (store *PostgresStore) GetAddressStat(addr *crypto.CommonAddress) (*AddressStat, error) {
+	stat := &AddressStat{Address: *addr}
+	sentAmount, receivedAmount := "", ""
+	err := store.db.QueryRow(
+		`SELECT COUNT(*) FILTER (WHERE sender = $1), COALESCE(SUM(amount) FILTER (WHERE sender = $1), 0)::TEXT,
+		COUNT(*) FILTER (WHERE recipient = $1), COALESCE(SUM(amount) FILTER (WHERE recipient = $1), 0)::TEXT,
+		COALESCE(MIN(height), 0), COALESCE(MAX(height), 0) FROM txs WHERE sender = $1 OR recipient = $1`,
+		pgAddress(addr),
+	).Scan(&stat.Sent, &sentAmount, &stat.Received, &receivedAmount, &stat.FirstHeight, &stat.LastHeight)
+	if err != nil {
+		return nil, err
+	}
+	stat.SentAmount.ToInt().SetString(sentAmount, 10)
+	stat.ReceivedAmount.ToInt().SetString(receivedAmount, 10)
+	return stat, nil
+}
+
+// TopContracts rank the contracts used on a day, or over all days if day is empty
+func (store *PostgresStore) TopContracts(day string, orderBy string, limit int) ([]*ContractStat, error) {
+	column := map[string]string{OrderByGas: "gas_used", OrderByCalls: "calls", OrderByCallers: "callers"}[orderBy]
+	if column == "" {
+		return nil, ErrInvalidOrder
+	}
+	rows, err := store.db.Query(
+		"SELECT contract, calls, gas_used, callers FROM contract_stats WHERE day = $1 AND calls > 0 ORDER BY "+column+" DESC LIMIT $2",
+		day, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := []*ContractStat{}
+	for rows.Next() {
+		stat, contract := &ContractStat{Day: day}, ""
+		if err := rows.Scan(&contract, &stat.Calls, &stat.GasUsed, &stat.Callers); err != nil {
+			return nil, err
+		}
+		stat.Contract = crypto.HexToAddress(contract)
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+// InsertInternalTxs save internal transactions as the transfers of their transaction
+func (store *PostgresStore) InsertInternalTxs(internalTxs []*InternalTx) error {
+	rows := make([][]interface{}, len(internalTxs))
+	for index, internalTx := range internalTxs {
+		rows[index] = []interface{}{
+			pgHash(&internalTx.TxHash), internalTx.Index, internalTx.Height, internalTx.Type, pgAddress(&internalTx.From),
+			pgAddress(&internalTx.To), internalTx.Value.ToInt().String(), internalTx.Depth, internalTx.Error,
+		}
+	}
+	return store.inTx(func(tx *sql.Tx) error {
+		return bulkInsert(tx, "transfers", []string{"tx_hash", "idx", "height", "type", "sender", "recipient", "amount", "depth", "error"}, rows, "ON CONFLICT (tx_hash, idx) DO NOTHING")
+	})
+}
+
+// DelInternalTxs remove the internal transactions of the transactions in block
+func (store *PostgresStore) DelInternalTxs(block *types.Block) error {
+	_, err := store.db.Exec("DELETE FROM transfers WHERE tx_hash = ANY ($1) AND idx > 0", pq.Array(pgTxHashes(block)))
+	return err
+}
+
+// GetInternalTxs return the internal transactions of a transaction in call order
+func (store *PostgresStore) GetInternalTxs(txHash *crypto.Hash) ([]*InternalTx, error) {
+	return store.queryInternalTxs("WHERE tx_hash = $1 AND idx > 0 ORDER BY idx", pgHash(txHash))
+}
+
+// GetAddressInternalTxs return a page of the internal transactions from or to addr, oldest first
+func (store *PostgresStore) GetAddressInternalTxs(addr *crypto.CommonAddress, pageIndex, pageSize int) ([]*InternalTx, error) {
+	return store.queryInternalTxs(
+		"WHERE idx > 0 AND (sender = $1 OR recipient = $1) ORDER BY height, tx_hash, idx LIMIT $2 OFFSET $3",
+		pgAddress(addr), pageSize, (pageIndex-1)*pageSize,
+	)
+}
+
+func (store *PostgresStore) queryInternalTxs(condition string, args ...interface{}) ([]*InternalTx, error) {
+	rows, err := store.db.Query("SELECT tx_hash, idx, height, type, sender, recipient, amount, depth, error FROM transfers "+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	internalTxs := []*InternalTx{}
+	for rows.Next() {
+		internalTx := &InternalTx{}
+		txHash, from, to, amount := "", "", "", ""
+		err := rows.Scan(&txHash, &internalTx.Index, &internalTx.Height, &internalTx.Type, &from, &to, &amount, &internalTx.Depth, &internalTx.Error)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(amount, 10)
+		if !ok {
+			value = new(big.Int)
+		}
+		internalTx.TxHash = crypto.HexToHash(txHash)
+		internalTx.From = crypto.HexToAddress(from)
+		internalTx.To = crypto.HexToAddress(to)
+		internalTx.Value = common.Big(*value)
+		internalTxs = append(internalTxs, internalTx)
+	}
+	return internalTxs, rows.Err()
+}
+
+// UpdateLogIndex do nothing, the logs table saved with the block is indexed by address and first topic
+func (store *PostgresStore) UpdateLogIndex(keys []*logKey, revert bool) error {
+	return nil
+}
+
+// GetLogBlocks return up to limit heights of the blocks with logs of addr and topic0 in [fromHeight, toHeight], ascending
+func (store *PostgresStore) GetLogBlocks(addr *crypto.CommonAddress, topic0 *crypto.Hash, fromHeight, toHeight uint64, limit int) ([]uint64, error) {
+	query := "SELECT DISTINCT height FROM logs WHERE address = $1 AND height BETWEEN $2 AND $3"
+	args := []interface{}{pgAddress(addr), fromHeight, toHeight}
+	if *topic0 != (crypto.Hash{}) {
+		query += " AND topics[1] = $4"
+		args = append(args, pgHash(topic0))
+	}
+	args = append(args, limit)
+	rows, err := store.db.Query(fmt.Sprintf("%s ORDER BY height LIMIT $%d", query, len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	heights := []uint64{}
+	for rows.Next() {
+		height := uint64(0)
+		if err := rows.Scan(&height); err != nil {
+			return nil, err
+		}
+		heights = append(heights, height)
+	}
+	return heights, rows.Err()
+}
+
+// GetMeta return a value describing the store, nil if not set
+func (store *PostgresStore) GetMeta(key string) ([]byte, error) {
+	value := []byte{}
+	err := store.db.QueryRow("SELECT value FROM meta WHERE key = $1", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (store *PostgresStore) PutMeta(key string, value []byte) error {
+	_, err := store.db.Exec("INSERT INTO meta (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = $2", key, value)
+	return err
+}
+
+func (store *PostgresStore) Close() {
+	store.db.Close()
+}
+
+// inTx run fn in a database transaction, committed if fn succeeds
+func (store *PostgresStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// bulkInsert insert rows with as few statements as the parameter limit allows
+func bulkInsert(tx *sql.Tx, table string, columns []string, rows [][]interface{}, suffix string) error {
+	batch := pgMaxParams / len(columns)
+	for start := 0; start < len(rows); start += batch {
+		end := start + batch
+		if end > len(rows) {
+			end = len(rows)
+		}
+		statement, args := bulkStatement(table, columns, rows[start:end], suffix)
+		if _, err := tx.Exec(statement, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bulkStatement build an insert of many rows, suffix is appended to the statement
+func bulkStatement(table string, columns []string, rows [][]interface{}, suffix string) (string, []interface{}) {
+	statement := &strings.Builder{}
+	fmt.Fprintf(statement, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			statement.WriteString(", ")
+		}
+		statement.WriteString("(")
+		for j, value := range row {
+			if j > 0 {
+				statement.WriteString(", ")
+			}
+			args = append(args, value)
+			fmt.Fprintf(statement, "$%d", len(args))
+		}
+		statement.WriteString(")")
+	}
+	if suffix != "" {
+		statement.WriteString(" " + suffix)
+	}
+	return statement.String(), args
+}
+
+// pgTransfer return the type of the transfer row of a transaction, empty for transactions moving no value to an address
+func pgTransfer(tx *types.Transaction, receipt *types.Receipt) string {
+	switch tx.Type() {
+	case types.TransferType, types.CallContractType:
+		return vm.CallTypeCall
+	case types.CreateContractType:
+		if receipt != nil {
+			return vm.CallTypeCreate
+		}
+	}
+	return ""
+}
+
+func pgRpcTransaction(raw []byte) (*RpcTransaction, error) {
+	tx := &types.Transaction{}
+	if err := binary.Unmarshal(raw, tx); err != nil {
+		return nil, err
+	}
+	return new(RpcTransaction).FromTx(tx), nil
+}
+
+func pgTxHashes(block *types.Block) []string {
+	hashes := make([]string, len(block.Data.TxList))
+	for i, tx := range block.Data.TxList {
+		hashes[i] = pgHash(tx.TxHash())
+	}
+	return hashes
+}
+
+func pgHash(hash *crypto.Hash) string {
+	return hash.String()
+}
+
+func pgAddress(addr *crypto.CommonAddress) string {
+	return pgBytes(addr[:])
+}
+
+func pgBytes(value []byte) string {
+	return "0x" + hex.EncodeToString(value)
+}
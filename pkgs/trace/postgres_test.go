@@ -0,0 +1,17 @@
+package trace
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBulkStatement(t *testing.T) {
+	rows := [][]interface{}{{"0x01", 1}, {"0x02", 2}}
+	statement, args := bulkStatement("txs", []string{"hash", "idx"}, rows, "ON CONFLICT (hash) DO NOTHING")
+	if statement != "INSERT INTO txs (hash, idx) VALUES ($1, $2), ($3, $4) ON CONFLICT (hash) DO NOTHING" {
+		t.Fatalf("unexpected statement %s", statement)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"0x01", 1, "0x02", 2}) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
@@ -0,0 +1,99 @@
+package trace
+
+import (
+	encodingBinary "encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxTxPageSize = 100
+
+	// address histories are ordered by height since version 1 and logs are indexed since version 2,
+	// older stores are rebuilt on start
+	txIndexVersion    = 2
+	txIndexVersionKey = "txIndexVersion"
+)
+
+// TxQuery filter and order the transactions of an address. Cursor is the cursor of the
+// last transaction of the previous page, the page starts after it
+type TxQuery struct {
+	Cursor     string `json:"cursor"`
+	Desc       bool   `json:"desc"` // newest first
+	FromHeight uint64 `json:"fromHeight"`
+	ToHeight   uint64 `json:"toHeight"` // included, 0 for no bound
+	FromTime   int64  `json:"fromTime"` // timestamp of the transactions, included
+	ToTime     int64  `json:"toTime"`   // included, 0 for no bound
+
+	after *txPosition
+}
+
+// check validate the ranges and parse the cursor
+func (query *TxQuery) check() error {
+	if query.ToHeight > 0 && query.ToHeight < query.FromHeight {
+		return ErrInvalidRange
+	}
+	if query.ToTime > 0 && query.ToTime < query.FromTime {
+		return ErrInvalidRange
+	}
+	query.after = nil
+	if query.Cursor != "" {
+		position, err := parseCursor(query.Cursor)
+		if err != nil {
+			return err
+		}
+		query.after = position
+	}
+	return nil
+}
+
+func (query *TxQuery) matchTime(timestamp int64) bool {
+	return timestamp >= query.FromTime && (query.ToTime == 0 || timestamp <= query.ToTime)
+}
+
+// txPosition locate a transaction by its block and its index in the block
+type txPosition struct {
+	Height uint64
+	Index  int
+}
+
+func parseCursor(cursor string) (*txPosition, error) {
+	parts := strings.Split(cursor, "-")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	height, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &txPosition{height, int(index)}, nil
+}
+
+// before tells whether position is before other, by height then index
+func (position *txPosition) before(other *txPosition) bool {
+	return position.Height < other.Height || (position.Height == other.Height && position.Index < other.Index)
+}
+
+func (position *txPosition) cursor() string {
+	return fmt.Sprintf("%d-%d", position.Height, position.Index)
+}
+
+// key sort the positions by height then index
+func (position *txPosition) key() []byte {
+	buf := [12]byte{}
+	encodingBinary.BigEndian.PutUint64(buf[:8], position.Height)
+	encodingBinary.BigEndian.PutUint32(buf[8:], uint32(position.Index))
+	return buf[:]
+}
+
+func positionFromKey(key []byte) *txPosition {
+	return &txPosition{
+		Height: encodingBinary.BigEndian.Uint64(key[:8]),
+		Index:  int(encodingBinary.BigEndian.Uint32(key[8:12])),
+	}
+}
@@ -0,0 +1,52 @@
+package trace
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTxCursor(t *testing.T) {
+	position := &txPosition{Height: 530, Index: 2}
+	parsed, err := parseCursor(position.cursor())
+	if err != nil || *parsed != *position {
+		t.Fatalf("cursor %s parsed to %v, %v", position.cursor(), parsed, err)
+	}
+	for _, cursor := range []string{"", "530", "530-", "-2", "530-2-1", "a-2", "530--2"} {
+		if _, err := parseCursor(cursor); err != ErrInvalidCursor {
+			t.Errorf("cursor %q accepted", cursor)
+		}
+	}
+
+	// keys sort as the positions
+	positions := []*txPosition{{1, 5}, {2, 0}, {2, 1}, {256, 0}}
+	for i := 1; i < len(positions); i++ {
+		if bytes.Compare(positions[i-1].key(), positions[i].key()) >= 0 {
+			t.Errorf("key of %v not below key of %v", positions[i-1], positions[i])
+		}
+	}
+	if *positionFromKey(position.key()) != *position {
+		t.Fatal("position changed by its key")
+	}
+}
+
+func TestTxQueryCheck(t *testing.T) {
+	query := &TxQuery{Cursor: "7-1", FromHeight: 5, ToHeight: 9, FromTime: 100}
+	if err := query.check(); err != nil {
+		t.Fatal(err)
+	}
+	if query.after == nil || query.after.Height != 7 || query.after.Index != 1 {
+		t.Fatalf("unexpected cursor %v", query.after)
+	}
+	if query.matchTime(99) || !query.matchTime(100) || !query.matchTime(1 << 40) {
+		t.Fatal("time range without end not matched")
+	}
+
+	for _, query := range []*TxQuery{{FromHeight: 5, ToHeight: 4}, {FromTime: 5, ToTime: 4}} {
+		if err := query.check(); err != ErrInvalidRange {
+			t.Errorf("range of %+v accepted", query)
+		}
+	}
+	if err := (&TxQuery{Cursor: "x"}).check(); err != ErrInvalidCursor {
+		t.Fatal("invalid cursor accepted")
+	}
+}
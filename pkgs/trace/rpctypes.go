@@ -16,6 +16,12 @@ type RpcTransaction struct {
 	From                  crypto.CommonAddress
 	types.TransactionData `bson:",inline"`
 	Sig                   common.Bytes
+	Kind                  string      `json:",omitempty" bson:"-"` // name of the transaction type
+	Payload               interface{} `json:",omitempty" bson:"-"` // Data decoded by the kind, omitted if it has no structured payload
+
+	Height uint64 `json:"-"`
+	Index  int    `json:"-"`                   // index in the block
+	Cursor string `json:",omitempty" bson:"-"` // set in address histories, the next page starts after it
 }
 
 type RpcBlock struct {
@@ -39,7 +45,19 @@ func (rpcTransaction *RpcTransaction) FromTx(tx *types.Transaction) *RpcTransact
 	rpcTransaction.TransactionData = tx.Data
 	rpcTransaction.From = *from
 	rpcTransaction.Sig = common.Bytes(tx.Sig)
-	return rpcTransaction
+	return rpcTransaction.withPayload()
+}
+
+// withPayload set the kind and the decoded payload, stores keep neither
+func (rpcTx *RpcTransaction) withPayload() *RpcTransaction {
+	tx := rpcTx.ToTx()
+	if kind, err := tx.Kind(); err == nil {
+		rpcTx.Kind = kind.Name
+	}
+	if payload, err := tx.DecodePayload(); err == nil {
+		rpcTx.Payload = payload
+	}
+	return rpcTx
 }
 
 func (rpcTx *RpcTransaction) ToTx() *types.Transaction {
@@ -0,0 +1,147 @@
+package trace
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	chainService "github.com/drep-project/DREP-Chain/chain"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/types"
+)
+
+const (
+	// Types of search results
+	SearchBlock       = "block"
+	SearchTransaction = "transaction"
+	SearchAddress     = "address"
+
+	minSearchPrefix  = 6 // hex digits a partial hash needs, shorter prefixes match too much
+	maxSearchResults = 20
+)
+
+// SearchResult is a block, transaction or address matching a search query
+type SearchResult struct {
+	Type      string                `json:"type"`
+	Hash      *crypto.Hash          `json:"hash,omitempty"`
+	Height    *uint64               `json:"height,omitempty"`
+	MainChain bool                  `json:"mainChain,omitempty"` // block is on the best chain
+	Address   *crypto.CommonAddress `json:"address,omitempty"`   // the address, or the sender of a transaction
+	Alias     string                `json:"alias,omitempty"`
+}
+
+// hashPrefix matches hashes starting with some hex digits, odd lengths included
+type hashPrefix struct {
+	digits string
+	bytes  []byte // whole bytes of the prefix
+	nibble int    // high nibble of the next byte, -1 for even lengths
+}
+
+func parseHashPrefix(input string) (*hashPrefix, bool) {
+	digits := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(input, "0x"), "0X"))
+	if len(digits) < minSearchPrefix || len(digits) > 2*crypto.HashLength {
+		return nil, false
+	}
+	even := digits[:len(digits)/2*2]
+	raw, err := hex.DecodeString(even)
+	if err != nil {
+		return nil, false
+	}
+	prefix := &hashPrefix{digits: digits, bytes: raw, nibble: -1}
+	if len(digits) != len(even) {
+		nibble, err := strconv.ParseUint(digits[len(digits)-1:], 16, 8)
+		if err != nil {
+			return nil, false
+		}
+		prefix.nibble = int(nibble)
+	}
+	return prefix, true
+}
+
+func (prefix *hashPrefix) match(hash []byte) bool {
+	if len(hash) < len(prefix.bytes) || string(hash[:len(prefix.bytes)]) != string(prefix.bytes) {
+		return false
+	}
+	if prefix.nibble < 0 {
+		return true
+	}
+	return len(hash) > len(prefix.bytes) && int(hash[len(prefix.bytes)]>>4) == prefix.nibble
+}
+
+func (traceApi *TraceApi) searchHeight(results []*SearchResult, height uint64) []*SearchResult {
+	node := traceApi.traceService.ChainService.BestChain().NodeByHeight(height)
+	if node == nil {
+		return results
+	}
+	return append(results, blockResult(node, true))
+}
+
+func (traceApi *TraceApi) searchBlocks(results []*SearchResult, prefix *hashPrefix) []*SearchResult {
+	chain := traceApi.traceService.ChainService
+	index := chain.Index()
+	index.RLock()
+	nodes := []*types.BlockNode{}
+	for hash, node := range index.Index {
+		if prefix.match(hash[:]) {
+			nodes = append(nodes, node)
+			if len(nodes) >= maxSearchResults {
+				break
+			}
+		}
+	}
+	index.RUnlock()
+
+	best := chain.BestChain()
+	for _, node := range nodes {
+		results = append(results, blockResult(node, best.Contains(node)))
+	}
+	return results
+}
+
+func (traceApi *TraceApi) searchTransactions(results []*SearchResult, prefix *hashPrefix) []*SearchResult {
+	hashes, err := traceApi.blockAnalysis.store.SearchTransactions(prefix, maxSearchResults)
+	if err != nil {
+		log.WithField("err", err).Warn("search transactions")
+		return results
+	}
+	for _, hash := range hashes {
+		result := &SearchResult{Type: SearchTransaction, Hash: hash}
+		if tx, err := traceApi.blockAnalysis.store.GetTransaction(hash); err == nil {
+			from := tx.From
+			result.Address = &from
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (traceApi *TraceApi) searchAddress(results []*SearchResult, addr crypto.CommonAddress) []*SearchResult {
+	result := &SearchResult{Type: SearchAddress, Address: &addr}
+	if trieQuery, err := traceApi.trieQuery(); err == nil {
+		result.Alias = trieQuery.GetStorageAlias(&addr)
+	}
+	return append(results, result)
+}
+
+func (traceApi *TraceApi) searchAlias(results []*SearchResult, alias string) []*SearchResult {
+	trieQuery, err := traceApi.trieQuery()
+	if err != nil {
+		return results
+	}
+	addr, err := trieQuery.AliasGet(alias)
+	if err != nil || addr == nil {
+		return results
+	}
+	return append(results, &SearchResult{Type: SearchAddress, Address: addr, Alias: alias})
+}
+
+func (traceApi *TraceApi) trieQuery() (*chainService.TrieQuery, error) {
+	tip := traceApi.traceService.ChainService.BestChain().Tip()
+	return chainService.NewTrieQuery(traceApi.traceService.DatabaseService.LevelDb(), tip.StateRoot)
+}
+
+func blockResult(node *types.BlockNode, mainChain bool) *SearchResult {
+	hash := *node.Hash
+	height := node.Height
+	return &SearchResult{Type: SearchBlock, Hash: &hash, Height: &height, MainChain: mainChain}
+}
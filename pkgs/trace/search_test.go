@@ -0,0 +1,37 @@
+package trace
+
+import (
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestHashPrefix(t *testing.T) {
+	hash := crypto.HexToHash("0x00001c9b8c8fdb1f53faf02321f76253704123e2b56cce065852bab93e526ae2")
+
+	cases := []struct {
+		query string
+		valid bool
+		match bool
+	}{
+		{"0x00001c", true, true},
+		{"00001c9", true, true},
+		{"0X00001C9B8", true, true},
+		{hash.String(), true, true},
+		{"00001d", true, false},
+		{"00001c8", true, false},
+		{"00001", false, false},
+		{"00001g9b", false, false},
+		{hash.String() + "00", false, false},
+	}
+	for _, c := range cases {
+		prefix, ok := parseHashPrefix(c.query)
+		if ok != c.valid {
+			t.Errorf("%s: got valid %v, want %v", c.query, ok, c.valid)
+			continue
+		}
+		if ok && prefix.match(hash[:]) != c.match {
+			t.Errorf("%s: got match %v, want %v", c.query, !c.match, c.match)
+		}
+	}
+}
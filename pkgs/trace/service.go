@@ -3,10 +3,12 @@ package trace
 import (
 	"github.com/drep-project/DREP-Chain/database"
 	consensusService "github.com/drep-project/DREP-Chain/pkgs/consensus/service"
+	"github.com/drep-project/DREP-Chain/pkgs/evm"
 	"path"
 
 	"github.com/AsynkronIT/protoactor-go/actor"
 	"github.com/drep-project/DREP-Chain/app"
+	"github.com/drep-project/DREP-Chain/blockmgr"
 	chainService "github.com/drep-project/DREP-Chain/chain"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -34,8 +36,11 @@ type TraceService struct {
 	ChainService     chainService.ChainServiceInterface `service:"chain"`
 	ConsensusService *consensusService.ConsensusService `service:"consensus"`
 	DatabaseService  *database.DatabaseService          `service:"database"`
+	EvmService       *evm.EvmService                    `service:"vm"`
+	Notifier         blockmgr.IBlockNotify              `service:"blockmgr"`
 	apis             []app.API
 	blockAnalysis    *BlockAnalysis
+	pending          *PendingTracker
 }
 
 func (traceService *TraceService) Name() string {
@@ -72,14 +77,28 @@ func (traceService *TraceService) Init(executeContext *app.ExecuteContext) error
 	if !traceService.Config.Enable {
 		return nil
 	}
-	traceService.blockAnalysis = NewBlockAnalysis(*traceService.Config, traceService.ConsensusService, traceService.DatabaseService.LevelDb(), traceService.ChainService.GetBlockByHeight)
-
+	chainStore := &chainService.ChainStore{traceService.DatabaseService.LevelDb()}
+	traceService.blockAnalysis = NewBlockAnalysis(*traceService.Config, traceService.ConsensusService, traceService.DatabaseService.LevelDb(), traceService.ChainService.GetBlockByHeight, chainStore.GetReceipts, func() uint64 {
+		return traceService.ChainService.BestChain().Height()
+	})
+	traceService.EvmService.SetCallTracer(traceService.blockAnalysis.recorder)
+	traceService.pending = NewPendingTracker(maxPendingRecords)
+
+	traceApi := &TraceApi{
+		traceService.blockAnalysis, traceService,
+	}
 	traceService.apis = []app.API{
 		app.API{
 			Namespace: MODULENAME,
 			Version:   "1.0",
-			Service: &TraceApi{
-				traceService.blockAnalysis, traceService,
+			Service:   traceApi,
+			Public:    true,
+		},
+		app.API{
+			Namespace: "chain",
+			Version:   "1.0",
+			Service: &AddressStatsApi{
+				traceApi: traceApi,
 			},
 			Public: true,
 		},
@@ -92,6 +111,7 @@ func (traceService *TraceService) Start(executeContext *app.ExecuteContext) erro
 		return nil
 	}
 	traceService.blockAnalysis.Start(traceService.ChainService.NewBlockFeed(), traceService.ChainService.DetachBlockFeed())
+	traceService.pending.Start(traceService.Notifier.TxPoolEventFeed(), traceService.ChainService.NewBlockFeed())
 	return nil
 }
 
@@ -99,6 +119,7 @@ func (traceService *TraceService) Stop(executeContext *app.ExecuteContext) error
 	if traceService.Config == nil || !traceService.Config.Enable {
 		return nil
 	}
+	traceService.pending.Close()
 	traceService.blockAnalysis.Close()
 	return nil
 }
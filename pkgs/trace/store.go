@@ -17,9 +17,37 @@ type IStore interface {
 
 	GetTransaction(txHash *crypto.Hash) (*RpcTransaction, error)
 
-	GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction
+	GetSendTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction
 
-	GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction
+	GetReceiveTransactionsByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) []*RpcTransaction
+
+	SearchTransactions(prefix *hashPrefix, limit int) ([]*crypto.Hash, error)
+
+	UpdateContractStats(calls []*contractCall, revert bool) error
+
+	GetContractStat(contract *crypto.CommonAddress, day string) (*ContractStat, error)
+
+	TopContracts(day string, orderBy string, limit int) ([]*ContractStat, error)
+
+	UpdateAddressStats(transfers []*addressTransfer, revert bool) error
+
+	GetAddressStat(addr *crypto.CommonAddress) (*AddressStat, error)
+
+	InsertInternalTxs(internalTxs []*InternalTx) error
+
+	DelInternalTxs(block *types.Block) error
+
+	GetInternalTxs(txHash *crypto.Hash) ([]*InternalTx, error)
+
+	GetAddressInternalTxs(addr *crypto.CommonAddress, pageIndex, pageSize int) ([]*InternalTx, error)
+
+	UpdateLogIndex(keys []*logKey, revert bool) error
+
+	GetLogBlocks(addr *crypto.CommonAddress, topic0 *crypto.Hash, fromHeight, toHeight uint64, limit int) ([]uint64, error)
+
+	GetMeta(key string) ([]byte, error)
+
+	PutMeta(key string, value []byte) error
 
 	Close()
 }
@@ -1,6 +1,11 @@
 package trace
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	chainService "github.com/drep-project/DREP-Chain/chain"
 	"github.com/drep-project/DREP-Chain/common"
 	"github.com/drep-project/DREP-Chain/crypto"
 	"github.com/drep-project/DREP-Chain/types"
@@ -44,7 +49,7 @@ func (traceApi *TraceApi) GetRawTransaction(txHash *crypto.Hash) (string, error)
  usage: Query transaction details according to transaction hash
  params:
 	1. transaction hash
- return: Transaction details
+ return: Transaction details, Kind names the transaction type and Payload holds Data decoded by it
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getTransaction","params":["0x00001c9b8c8fdb1f53faf02321f76253704123e2b56cce065852bab93e526ae2"], "id": 3}' -H "Content-Type:application/json"
  response:
    {
@@ -63,7 +68,8 @@ func (traceApi *TraceApi) GetRawTransaction(txHash *crypto.Hash) (string, error)
 		"GasLimit": "0x30000",
 		"Timestamp": 1560356382,
 		"Data": null,
-		"Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9"
+		"Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9",
+		"Kind": "transfer"
 	  }
 	}
 */
@@ -72,7 +78,7 @@ func (traceApi *TraceApi) GetTransaction(txHash *crypto.Hash) (*RpcTransaction,
 	if err != nil {
 		return nil, err
 	}
-	return rpcTx, nil
+	return rpcTx.withPayload(), nil
 }
 
 /*
@@ -80,7 +86,7 @@ func (traceApi *TraceApi) GetTransaction(txHash *crypto.Hash) (*RpcTransaction,
  usage: De parsing transaction byte information into transaction details
  params:
 	1. Transaction byte information
- return: transaction details
+ return: transaction details, Kind names the transaction type and Payload holds Data decoded by it
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_decodeTrasnaction","params":["0x02a7ae20007923a30bbfbcb998a6534d56b313e68c8e0c594a0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000002011102011003030000bc9889d00b004120eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9"], "id": 3}' -H "Content-Type:application/json"
  response:
    {
@@ -99,7 +105,8 @@ func (traceApi *TraceApi) GetTransaction(txHash *crypto.Hash) (*RpcTransaction,
 		"GasLimit": "0x30000",
 		"Timestamp": 1560356382,
 		"Data": null,
-		"Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9"
+		"Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9",
+		"Kind": "transfer"
 	  }
 	}
 */
@@ -119,10 +126,12 @@ func (traceApi *TraceApi) DecodeTrasnaction(bytes common.Bytes) (*RpcTransaction
  usage: Query the transaction sent from the address according to the address, and pagination is supported
  params:
 	1. address
-	2. Page number (from 1)
-    3. Page size
- return: Transaction list
- example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getSendTransactionByAddr","params":["DREP7923a30bbfbcb998a6534d56b313e68c8e0c594a",1,10], "id": 3}' -H "Content-Type:application/json"
+	2. Page number (from 1), 1 to start after the cursor of the query
+    3. Page size, at most 100
+	4. Optional query: {"cursor": cursor of the last transaction of the previous page, "desc": newest first,
+	   "fromHeight", "toHeight", "fromTime", "toTime": included bounds, 0 ends for no bound}
+ return: Transaction list ordered by height
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getSendTransactionByAddr","params":["DREP7923a30bbfbcb998a6534d56b313e68c8e0c594a",1,10,{"desc":true,"fromHeight":100}], "id": 3}' -H "Content-Type:application/json"
  response:
    {
 	  "jsonrpc": "2.0",
@@ -141,13 +150,18 @@ func (traceApi *TraceApi) DecodeTrasnaction(bytes common.Bytes) (*RpcTransaction
 		  "GasLimit": "0x30000",
 		  "Timestamp": 1560356382,
 		  "Data": null,
-		  "Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9"
+		  "Sig": "0x20eba14c77eab7a154833ff14832d8769cfc0b30db288445d6a83ef2fe337aa09042f8174a593543c4acabe7fadf1ad5fceea9c835682cb9dbea3f1d8fec181fb9",
+		  "Cursor": "530-0"
 		}
 	  ]
 	}
 */
-func (traceApi *TraceApi) GetSendTransactionByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
-	return traceApi.blockAnalysis.store.GetSendTransactionsByAddr(addr, pageIndex, pageSize)
+func (traceApi *TraceApi) GetSendTransactionByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) ([]*RpcTransaction, error) {
+	if err := checkTxQuery(query); err != nil {
+		return nil, err
+	}
+	pageIndex, pageSize = txPage(pageIndex, pageSize)
+	return withPayloads(traceApi.blockAnalysis.store.GetSendTransactionsByAddr(addr, pageIndex, pageSize, query)), nil
 }
 
 /*
@@ -155,10 +169,11 @@ func (traceApi *TraceApi) GetSendTransactionByAddr(addr *crypto.CommonAddress, p
  usage: Query the transaction accepted by the address and support paging
  params:
 	1. addr
-	2. Page number (from 1)
-    3. page size
- return: transaction list
- example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getReceiveTransactionByAddr","params":["DREP3ebcbe7cb440dd8c52940a2963472380afbb56c5",1,10], "id": 3}' -H "Content-Type:application/json"
+	2. Page number (from 1), 1 to start after the cursor of the query
+    3. page size, at most 100
+	4. Optional query, the same as getSendTransactionByAddr
+ return: transaction list ordered by height
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getReceiveTransactionByAddr","params":["DREP3ebcbe7cb440dd8c52940a2963472380afbb56c5",1,10,{"cursor":"553-2"}], "id": 3}' -H "Content-Type:application/json"
  response:
    {
 	  "jsonrpc": "2.0",
@@ -177,22 +192,304 @@ func (traceApi *TraceApi) GetSendTransactionByAddr(addr *crypto.CommonAddress, p
 		  "GasLimit": "0x7530",
 		  "Timestamp": 1560403673,
 		  "Data": null,
-		  "Sig": "0x1f073cd3f2621abe15ef949b27c7d0a16d69a64aaa9e95973b9c94de2d7b8f4b103928988478d2f248ae7a9dc6a156d12d300adc5e9059decc037a67e94fe0c3a2"
+		  "Sig": "0x1f073cd3f2621abe15ef949b27c7d0a16d69a64aaa9e95973b9c94de2d7b8f4b103928988478d2f248ae7a9dc6a156d12d300adc5e9059decc037a67e94fe0c3a2",
+		  "Cursor": "553-4"
+		}
+	  ]
+	}
+*/
+func (traceApi *TraceApi) GetReceiveTransactionByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int, query *TxQuery) ([]*RpcTransaction, error) {
+	if err := checkTxQuery(query); err != nil {
+		return nil, err
+	}
+	pageIndex, pageSize = txPage(pageIndex, pageSize)
+	return withPayloads(traceApi.blockAnalysis.store.GetReceiveTransactionsByAddr(addr, pageIndex, pageSize, query)), nil
+}
+
+func withPayloads(txs []*RpcTransaction) []*RpcTransaction {
+	for _, tx := range txs {
+		tx.withPayload()
+	}
+	return txs
+}
+
+func checkTxQuery(query *TxQuery) error {
+	if query == nil {
+		return nil
+	}
+	return query.check()
+}
+
+func txPage(pageIndex, pageSize int) (int, int) {
+	if pageIndex < 1 {
+		pageIndex = 1
+	}
+	if pageSize <= 0 || pageSize > maxTxPageSize {
+		pageSize = maxTxPageSize
+	}
+	return pageIndex, pageSize
+}
+
+/*
+ name: search
+ usage: Find blocks, transactions and addresses for a search box. The query is a height, a full or partial
+	hash (at least 6 hex digits), an address or an alias, it may be qualified as "height:", "block:", "tx:",
+	"addr:" or "alias:" to skip guessing. Transactions are looked up in the trace records
+ params:
+	1. query
+ return: typed results, at most 20
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_search","params":["0x00001c9b"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": [
+		{
+		  "type": "transaction",
+		  "hash": "0x00001c9b8c8fdb1f53faf02321f76253704123e2b56cce065852bab93e526ae2",
+		  "address": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a"
+		}
+	  ]
+	}
+*/
+func (traceApi *TraceApi) Search(query string) ([]*SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearch
+	}
+	kind := ""
+	if i := strings.IndexByte(query, ':'); i > 0 {
+		kind, query = strings.ToLower(query[:i]), strings.TrimSpace(query[i+1:])
+	}
+
+	results := []*SearchResult{}
+	switch kind {
+	case "height":
+		height, err := strconv.ParseUint(query, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		results = traceApi.searchHeight(results, height)
+	case "block", "tx":
+		prefix, ok := parseHashPrefix(query)
+		if !ok {
+			return nil, ErrInvalidSearch
+		}
+		if kind == "block" {
+			results = traceApi.searchBlocks(results, prefix)
+		} else {
+			results = traceApi.searchTransactions(results, prefix)
+		}
+	case "addr", "address":
+		if !crypto.IsHexAddress(query) {
+			return nil, ErrInvalidSearch
+		}
+		results = traceApi.searchAddress(results, crypto.HexToAddress(query))
+	case "alias":
+		results = traceApi.searchAlias(results, query)
+	case "":
+		if height, err := strconv.ParseUint(query, 10, 64); err == nil {
+			results = traceApi.searchHeight(results, height)
+		}
+		if crypto.IsHexAddress(query) && strings.HasPrefix(strings.ToLower(query), "0x") {
+			results = traceApi.searchAddress(results, crypto.HexToAddress(query))
+		} else if prefix, ok := parseHashPrefix(query); ok {
+			results = traceApi.searchBlocks(results, prefix)
+			results = traceApi.searchTransactions(results, prefix)
+		}
+		if chainService.CheckAlias([]byte(query)) == nil {
+			results = traceApi.searchAlias(results, query)
+		}
+	default:
+		return nil, ErrInvalidSearch
+	}
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	return results, nil
+}
+
+/*
+ name: getContractStats
+ usage: Usage of a contract, the totals over all days followed by the last days (UTC)
+ params:
+	1. contract address
+	2. number of days to return, at most 366
+ return: the totals, then one stat per day, most recent first
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getContractStats","params":["0xecfb51e10aa4c146bf6c12eee090339c99841efc", 2], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": [
+		{"contract": "0xecfb51e10aa4c146bf6c12eee090339c99841efc", "calls": 1520, "gasUsed": 41230560, "callers": 87},
+		{"contract": "0xecfb51e10aa4c146bf6c12eee090339c99841efc", "day": "2019-11-05", "calls": 112, "gasUsed": 3038432, "callers": 21},
+		{"contract": "0xecfb51e10aa4c146bf6c12eee090339c99841efc", "day": "2019-11-04", "calls": 0, "gasUsed": 0, "callers": 0}
+	  ]
+	}
+*/
+func (traceApi *TraceApi) GetContractStats(contract *crypto.CommonAddress, days int) ([]*ContractStat, error) {
+	if days < 0 {
+		days = 0
+	}
+	if days > maxStatDays {
+		days = maxStatDays
+	}
+	store := traceApi.blockAnalysis.store
+	total, err := store.GetContractStat(contract, "")
+	if err != nil {
+		return nil, err
+	}
+	stats := []*ContractStat{total}
+	for _, day := range statDays(time.Now(), days) {
+		stat, err := store.GetContractStat(contract, day)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+/*
+ name: topContracts
+ usage: Rank the contracts by the network capacity they use
+ params:
+	1. order, "gas", "calls" or "callers"
+	2. day as 2006-01-02 (UTC), empty for all days
+	3. maximum number of contracts, at most 100
+ return: contract stats, highest first
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_topContracts","params":["gas", "2019-11-05", 10], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": [
+		{"contract": "0xecfb51e10aa4c146bf6c12eee090339c99841efc", "day": "2019-11-05", "calls": 112, "gasUsed": 3038432, "callers": 21}
+	  ]
+	}
+*/
+func (traceApi *TraceApi) TopContracts(orderBy string, day string, limit int) ([]*ContractStat, error) {
+	if !validStatOrder(orderBy) {
+		return nil, ErrInvalidOrder
+	}
+	if day != "" {
+		if _, err := time.Parse(statDayLayout, day); err != nil {
+			return nil, ErrInvalidDay
+		}
+	}
+	if limit <= 0 || limit > maxContractRank {
+		limit = maxContractRank
+	}
+	return traceApi.blockAnalysis.store.TopContracts(day, orderBy, limit)
+}
+
+/*
+ name: getInternalTransactions
+ usage: Query the calls and value transfers made by contracts while a transaction ran
+ params:
+	1. transaction hash
+ return: internal transactions in call order, error is set when the call was reverted
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getInternalTransactions","params":["0x3ebcd9e6d95f0b4ad4f9b4f6b0a34bd9d2e1f7ea2e0f3f5d7c5b1b6b8d2ac0e1"], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": [
+		{
+		  "txHash": "0x3ebcd9e6d95f0b4ad4f9b4f6b0a34bd9d2e1f7ea2e0f3f5d7c5b1b6b8d2ac0e1",
+		  "height": 10212,
+		  "index": 1,
+		  "type": "call",
+		  "from": "0xecfb51e10aa4c146bf6c12eee090339c99841efc",
+		  "to": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",
+		  "value": "0xde0b6b3a7640000",
+		  "depth": 1
 		}
 	  ]
 	}
 */
-func (traceApi *TraceApi) GetReceiveTransactionByAddr(addr *crypto.CommonAddress, pageIndex, pageSize int) []*RpcTransaction {
-	return traceApi.blockAnalysis.store.GetReceiveTransactionsByAddr(addr, pageIndex, pageSize)
+func (traceApi *TraceApi) GetInternalTransactions(txHash *crypto.Hash) ([]*InternalTx, error) {
+	return traceApi.blockAnalysis.store.GetInternalTxs(txHash)
+}
+
+/*
+ name: getAddressInternalHistory
+ usage: Query the internal transactions from or to an address, and pagination is supported
+ params:
+	1. address
+	2. Page number (from 1)
+	3. Page size, at most 100
+ return: internal transactions, oldest first
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getAddressInternalHistory","params":["0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",1,10], "id": 3}' -H "Content-Type:application/json"
+ response:
+   {
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": [
+		{
+		  "txHash": "0x3ebcd9e6d95f0b4ad4f9b4f6b0a34bd9d2e1f7ea2e0f3f5d7c5b1b6b8d2ac0e1",
+		  "height": 10212,
+		  "index": 1,
+		  "type": "call",
+		  "from": "0xecfb51e10aa4c146bf6c12eee090339c99841efc",
+		  "to": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",
+		  "value": "0xde0b6b3a7640000",
+		  "depth": 1
+		}
+	  ]
+	}
+*/
+func (traceApi *TraceApi) GetAddressInternalHistory(addr *crypto.CommonAddress, pageIndex, pageSize int) ([]*InternalTx, error) {
+	if pageIndex < 1 {
+		pageIndex = 1
+	}
+	if pageSize <= 0 || pageSize > maxInternalHistories {
+		pageSize = maxInternalHistories
+	}
+	return traceApi.blockAnalysis.store.GetAddressInternalTxs(addr, pageIndex, pageSize)
+}
+
+/*
+ name: getLogs
+ usage: Query the logs of a contract, optionally with a first topic, in a height range. Only the blocks with matching logs are read, up to 200 of them a call
+ params:
+	1. query, address of the contract and optionally topic0, fromHeight, toHeight (0 for the chain head), limit (at most 1000) and the cursor of the previous page
+ return: logs in chain order and the cursor of the next page, the cursor is empty at the end of range
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getLogs","params":[{"address":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","topic0":"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef","fromHeight":10000,"limit":1}], "id": 3}' -H "Content-Type:application/json"
+ response:
+	{
+	  "jsonrpc": "2.0",
+	  "id": 3,
+	  "result": {
+		"logs": [
+		  {
+			"TxType": 2,
+			"Address": "0x7923a30bbfbcb998a6534d56b313e68c8e0c594a",
+			"Topics": ["0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"],
+			"Data": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA3gtrOnZAAA",
+			"ChainId": 0,
+			"TxHash": "0x3ebcd9e6d95f0b4ad4f9b4f6b0a34bd9d2e1f7ea2e0f3f5d7c5b1b6b8d2ac0e1",
+			"Height": 10212,
+			"TxIndex": 1,
+			"Removed": false
+		  }
+		],
+		"cursor": "10212-0"
+	  }
+	}
+*/
+func (traceApi *TraceApi) GetLogs(query LogQuery) (*LogPage, error) {
+	return traceApi.blockAnalysis.GetLogs(&query)
 }
 
 /*
  name: rebuild
- usage: Reconstructing block records in trace
+ usage: Reconstructing block records in trace, the blocks are recorded in the background
  params:
 	1. Start block (included)
-	2. Termination block (not included)
- return:
+	2. Termination block (not included), -1 for up to the chain head
+ return: error if a rebuild is running
  example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_rebuild","params":[1,10], "id": 3}' -H "Content-Type:application/json"
  response:
   	{"jsonrpc":"2.0","id":3,"result":null}
@@ -202,10 +499,55 @@ func (traceApi *TraceApi) Rebuild(from, end int) error {
 		from = 0
 	}
 	if end < 0 {
-		end = int(traceApi.traceService.ChainService.BestChain().Height())
-	}
-	if from > end {
+		end = 0
+	} else if from >= end {
 		return nil
 	}
-	return traceApi.blockAnalysis.Rebuild(from, end)
+	return traceApi.blockAnalysis.StartRebuild(uint64(from), uint64(end))
+}
+
+/*
+ name: getRebuildProgress
+ usage: Query the progress of the running or last rebuild, including the backfill of missing blocks on start
+ params:
+ return: the range, the next block to record and whether it is running
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getRebuildProgress","params":[], "id": 3}' -H "Content-Type:application/json"
+ response:
+  	{"jsonrpc":"2.0","id":3,"result":{"from":1,"end":10,"next":6,"force":true,"running":true}}
+*/
+func (traceApi *TraceApi) GetRebuildProgress() *RebuildProgress {
+	return traceApi.blockAnalysis.RebuildProgress()
+}
+
+/*
+ name: getPendingTransaction
+ usage: Query a transaction not packed yet, followed from the pool events: pending, replaced or dropped with the reason
+ params:
+	1. transaction hash
+ return: the state of the transaction in pool
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getPendingTransaction","params":["0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040"], "id": 3}' -H "Content-Type:application/json"
+ response:
+  	{"jsonrpc":"2.0","id":3,"result":{"type":"dropped","hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","from":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","nonce":15632,"gasPrice":"0x110","reason":"underpriced","time":1592365600,"status":"dropped","firstSeen":1592365562}}
+*/
+func (traceApi *TraceApi) GetPendingTransaction(txHash *crypto.Hash) (*PendingTx, error) {
+	tx := traceApi.traceService.pending.Get(*txHash)
+	if tx == nil {
+		return nil, ErrTxNotFound
+	}
+	return tx, nil
+}
+
+/*
+ name: getPendingTransactionsBySender
+ usage: Query the transactions of a sender not packed yet, followed from the pool events
+ params:
+	1. address
+	2. max count, 0 for all
+ return: the states of the transactions in pool, oldest first
+ example: curl http://localhost:10085 -X POST --data '{"jsonrpc":"2.0","method":"trace_getPendingTransactionsBySender","params":["0x7923a30bbfbcb998a6534d56b313e68c8e0c594a", 10], "id": 3}' -H "Content-Type:application/json"
+ response:
+  	{"jsonrpc":"2.0","id":3,"result":[{"type":"added","hash":"0xfa5c34114ff459b4c97e7cd268c507c0ccfcfc89d3ccdcf71e96402f9899d040","from":"0x7923a30bbfbcb998a6534d56b313e68c8e0c594a","nonce":15632,"gasPrice":"0x110","time":1592365562,"status":"pending","firstSeen":1592365562}]}
+*/
+func (traceApi *TraceApi) GetPendingTransactionsBySender(addr *crypto.CommonAddress, limit int) []*PendingTx {
+	return traceApi.traceService.pending.BySender(*addr, limit)
 }
@@ -65,7 +65,7 @@ type Storage struct {
 	CodeHash crypto.Hash
 
 	Alias      string
-	BalanceMap map[string]big.Int
+	BalanceMap map[string]big.Int // unused, must stay empty as maps have no canonical encoding
 }
 
 func newStorage() *Storage {
@@ -93,13 +93,10 @@ func BlockFromMessage(bytes []byte) (*Block, error) {
 	}
 	err := binary.Unmarshal(bytes, block)
 	if err != nil {
-		fmt.Println("BlockFromMessage err:", err)
 		return nil, err
 	}
 
 	if block.Header == nil {
-		fmt.Println("header len :", len(bytes))
-		fmt.Println("fatal err,block parse err **************************************")
 		//panic("header is nil")
 		return nil, fmt.Errorf("unmarshal err")
 	}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/drep-project/binary"
-	"log"
 	"testing"
 )
 
@@ -13,16 +12,23 @@ func TestBkMarshal(t *testing.T) {
 	tx.Version = 1
 	bytes1, err := binary.Marshal(tx)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 	fmt.Println(tx.Hash())
-	tx.TxRoot = []byte{1, 2, 3}
 	tx.StateRoot = []byte{}
 	bytes12, err := binary.Marshal(tx)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 	if !bytes.Equal(bytes1, bytes12) {
-		log.Fatal("not match marshal result")
+		t.Fatal("not match marshal result")
+	}
+	tx.TxRoot = []byte{1, 2, 3}
+	bytes13, err := binary.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(bytes1, bytes13) {
+		t.Fatal("tx root not in marshal result")
 	}
 }
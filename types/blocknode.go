@@ -28,6 +28,10 @@ const (
 	// has failed validation, thus the block is also invalid.
 	StatusInvalidAncestor
 
+	// statusStale indicates that the block is valid but not in the best chain,
+	// it was stored on a side chain or detached by a reorganize.
+	StatusStale
+
 	// statusNone indicates that the block has no validation state flags set.
 	//
 	// NOTE: This must be defined last in order to avoid influencing iota.
@@ -55,6 +59,11 @@ func (status BlockStatus) KnownInvalid() bool {
 	return status&(StatusValidateFailed|StatusInvalidAncestor) != 0
 }
 
+// Stale returns whether the block is known to be out of the best chain.
+func (status BlockStatus) Stale() bool {
+	return status&StatusStale != 0
+}
+
 type BlockNode struct {
 	// NOTE: Additions, deletions, or modifications to the order of the
 	// definitions in this struct should not be changed without considering
@@ -129,6 +138,9 @@ func (node *BlockNode) Header() BlockHeader {
 	prevHash := &crypto.Hash{}
 	if node.Parent != nil {
 		prevHash = node.Parent.Hash
+	} else if node.PreviousHash != nil {
+		// the parent was evicted from the in-memory block index
+		prevHash = node.PreviousHash
 	}
 	return BlockHeader{
 		Height:       node.Height,
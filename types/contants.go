@@ -16,6 +16,13 @@ const (
 	CandidateType        //Apply to be a candidate block node
 	CancelCandidateType  //Apply to be a candidate block node
 	RegisterProducer
+	BridgeType           //Relay headers, mint and burn assets of the cross chain bridge
+	IssueTokenType       //Issue a native token
+	MintTokenType        //Mint more of a native token, by its issuer
+	BurnTokenType        //Burn own balance of a native token
+	TransferTokenType    //Transfer a native token
+	AttestReputationType //Attest the reputation of the receiver
+	ReputationRuleType   //Set the decay rule of reputation, by a governor
 )
 
 var (
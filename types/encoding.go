@@ -0,0 +1,412 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// Blocks, transactions and receipts are stored in a canonical encoding: a magic prefix, the
+// encoding version and the RLP encoding of the fields in a fixed order. Unlike the reflection
+// based binary encoding the bytes depend on nothing but the field values, so they stay the
+// same across library versions. Decoding still accepts the binary encoding written by older
+// nodes, MigrateEncoding of the chain store rewrites it.
+//
+// Hashes and signatures are still taken over the binary encoding, changing them is a fork.
+//
+// Blocks and receipts are large and repetitive, from EncodingV2 their RLP encoding is snappy
+// compressed. Headers and transactions are still written in EncodingV1.
+const (
+	EncodingV1 byte = 1
+	EncodingV2 byte = 2 // snappy compressed RLP
+
+	// CurrentEncoding is the version new blocks and receipts are written in
+	CurrentEncoding = EncodingV2
+)
+
+var (
+	encodingMagic = []byte{0xff, 'D', 'R'}
+
+	ErrEncodingVersion = errors.New("unknown canonical encoding version")
+)
+
+type rlpHeader struct {
+	ChainId      uint32
+	Version      uint32
+	PreviousHash crypto.Hash
+	GasLimit     *big.Int
+	GasUsed      *big.Int
+	Height       uint64
+	Timestamp    uint64
+	StateRoot    []byte
+	TxRoot       []byte
+	ReceiptRoot  crypto.Hash
+	MinerAddr    crypto.CommonAddress
+	Bloom        Bloom
+}
+
+type rlpTransaction struct {
+	Version   uint32
+	Nonce     uint64
+	Type      uint64
+	To        crypto.CommonAddress
+	ChainId   uint32
+	Amount    *big.Int
+	GasPrice  *big.Int
+	GasLimit  *big.Int
+	Timestamp uint64
+	Data      []byte
+	Sig       []byte
+}
+
+type rlpBlock struct {
+	Header    rlpHeader
+	TxCount   uint64
+	TxList    []rlpTransaction
+	ProofType uint64
+	Evidence  []byte
+}
+
+type rlpLog struct {
+	TxType  uint64
+	Address crypto.CommonAddress
+	Topics  []crypto.Hash
+	Data    []byte
+	ChainId uint32
+	TxHash  crypto.Hash
+	Height  uint64
+	TxIndex uint64
+	Removed bool
+}
+
+type rlpReceipt struct {
+	PostState         []byte
+	Status            uint64
+	CumulativeGasUsed uint64
+	Logs              []rlpLog
+	Bloom             Bloom
+	TxHash            crypto.Hash
+	ContractAddress   crypto.CommonAddress
+	GasUsed           uint64
+	BlockHash         crypto.Hash
+	BlockNumber       uint64
+}
+
+// IsCanonicalEncoding report whether b starts like the canonical encoding
+func IsCanonicalEncoding(b []byte) bool {
+	return len(b) > len(encodingMagic) && bytes.HasPrefix(b, encodingMagic)
+}
+
+// EncodingOf return the canonical encoding version of b, 0 for the legacy binary encoding
+func EncodingOf(b []byte) byte {
+	if !IsCanonicalEncoding(b) {
+		return 0
+	}
+	return b[len(encodingMagic)]
+}
+
+func encodeCanonical(val interface{}) ([]byte, error) {
+	content, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return nil, err
+	}
+	return withEncodingPrefix(EncodingV1, content), nil
+}
+
+// encodeCompressed return the EncodingV2 of val, its RLP encoding snappy compressed
+func encodeCompressed(val interface{}) ([]byte, error) {
+	content, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		return nil, err
+	}
+	return withEncodingPrefix(EncodingV2, snappy.Encode(nil, content)), nil
+}
+
+func withEncodingPrefix(version byte, content []byte) []byte {
+	b := make([]byte, 0, len(encodingMagic)+1+len(content))
+	b = append(b, encodingMagic...)
+	b = append(b, version)
+	return append(b, content...)
+}
+
+// decodeCanonical decode b into val if it is canonical encoded, legacy data is decoded with
+// the binary encoding into legacy. The binary encoding has no marker, data that starts like
+// the current encoding but fails to decode is tried as binary as well
+func decodeCanonical(b []byte, val interface{}, legacy interface{}) (bool, error) {
+	if !IsCanonicalEncoding(b) {
+		return false, binary.Unmarshal(b, legacy)
+	}
+	content := b[len(encodingMagic)+1:]
+	switch b[len(encodingMagic)] {
+	case EncodingV1:
+	case EncodingV2:
+		decompressed, err := snappy.Decode(nil, content)
+		if err != nil {
+			return false, binary.Unmarshal(b, legacy)
+		}
+		content = decompressed
+	default:
+		return false, ErrEncodingVersion
+	}
+	if rlp.DecodeBytes(content, val) == nil {
+		return true, nil
+	}
+	return false, binary.Unmarshal(b, legacy)
+}
+
+func toRLPHeader(header *BlockHeader) rlpHeader {
+	return rlpHeader{
+		ChainId:      uint32(header.ChainId),
+		Version:      uint32(header.Version),
+		PreviousHash: header.PreviousHash,
+		GasLimit:     &header.GasLimit,
+		GasUsed:      &header.GasUsed,
+		Height:       header.Height,
+		Timestamp:    header.Timestamp,
+		StateRoot:    header.StateRoot,
+		TxRoot:       header.TxRoot,
+		ReceiptRoot:  header.ReceiptRoot,
+		MinerAddr:    header.MinerAddr,
+		Bloom:        header.Bloom,
+	}
+}
+
+func (header *rlpHeader) blockHeader() *BlockHeader {
+	return &BlockHeader{
+		ChainId:      ChainIdType(header.ChainId),
+		Version:      int32(header.Version),
+		PreviousHash: header.PreviousHash,
+		GasLimit:     *header.GasLimit,
+		GasUsed:      *header.GasUsed,
+		Height:       header.Height,
+		Timestamp:    header.Timestamp,
+		StateRoot:    header.StateRoot,
+		TxRoot:       header.TxRoot,
+		ReceiptRoot:  header.ReceiptRoot,
+		MinerAddr:    header.MinerAddr,
+		Bloom:        header.Bloom,
+	}
+}
+
+func toRLPTransaction(tx *Transaction) rlpTransaction {
+	return rlpTransaction{
+		Version:   uint32(tx.Data.Version),
+		Nonce:     tx.Data.Nonce,
+		Type:      uint64(tx.Data.Type),
+		To:        tx.Data.To,
+		ChainId:   uint32(tx.Data.ChainId),
+		Amount:    tx.Data.Amount.ToInt(),
+		GasPrice:  tx.Data.GasPrice.ToInt(),
+		GasLimit:  tx.Data.GasLimit.ToInt(),
+		Timestamp: uint64(tx.Data.Timestamp),
+		Data:      tx.Data.Data,
+		Sig:       tx.Sig,
+	}
+}
+
+func (tx *rlpTransaction) transaction() *Transaction {
+	return &Transaction{
+		Data: TransactionData{
+			Version:   int32(tx.Version),
+			Nonce:     tx.Nonce,
+			Type:      TxType(tx.Type),
+			To:        tx.To,
+			ChainId:   ChainIdType(tx.ChainId),
+			Amount:    common.Big(*tx.Amount),
+			GasPrice:  common.Big(*tx.GasPrice),
+			GasLimit:  common.Big(*tx.GasLimit),
+			Timestamp: int64(tx.Timestamp),
+			Data:      tx.Data,
+		},
+		Sig: tx.Sig,
+	}
+}
+
+func toRLPReceipt(receipt *Receipt) rlpReceipt {
+	logs := make([]rlpLog, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		logs[i] = rlpLog{
+			TxType:  uint64(log.TxType),
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    log.Data,
+			ChainId: uint32(log.ChainId),
+			TxHash:  log.TxHash,
+			Height:  log.Height,
+			TxIndex: uint64(log.TxIndex),
+			Removed: log.Removed,
+		}
+	}
+	return rlpReceipt{
+		PostState:         receipt.PostState,
+		Status:            receipt.Status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Logs:              logs,
+		Bloom:             receipt.Bloom,
+		TxHash:            receipt.TxHash,
+		ContractAddress:   receipt.ContractAddress,
+		GasUsed:           receipt.GasUsed,
+		BlockHash:         receipt.BlockHash,
+		BlockNumber:       receipt.BlockNumber,
+	}
+}
+
+func (receipt *rlpReceipt) receipt() *Receipt {
+	logs := make([]*Log, len(receipt.Logs))
+	for i, log := range receipt.Logs {
+		logs[i] = &Log{
+			TxType:  TxType(log.TxType),
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    log.Data,
+			ChainId: ChainIdType(log.ChainId),
+			TxHash:  log.TxHash,
+			Height:  log.Height,
+			TxIndex: uint(log.TxIndex),
+			Removed: log.Removed,
+		}
+	}
+	return &Receipt{
+		PostState:         receipt.PostState,
+		Status:            receipt.Status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Logs:              logs,
+		Bloom:             receipt.Bloom,
+		TxHash:            receipt.TxHash,
+		ContractAddress:   receipt.ContractAddress,
+		GasUsed:           receipt.GasUsed,
+		BlockHash:         receipt.BlockHash,
+		BlockNumber:       receipt.BlockNumber,
+	}
+}
+
+// EncodeBlockHeader return the canonical encoding of header
+func EncodeBlockHeader(header *BlockHeader) ([]byte, error) {
+	val := toRLPHeader(header)
+	return encodeCanonical(&val)
+}
+
+// DecodeBlockHeader decode a header in the canonical or the legacy binary encoding
+func DecodeBlockHeader(b []byte) (*BlockHeader, error) {
+	val, header := &rlpHeader{}, &BlockHeader{}
+	canonical, err := decodeCanonical(b, val, header)
+	if err != nil {
+		return nil, err
+	}
+	if canonical {
+		return val.blockHeader(), nil
+	}
+	return header, nil
+}
+
+func toRLPBlock(block *Block) rlpBlock {
+	val := rlpBlock{
+		Header:    toRLPHeader(block.Header),
+		TxList:    []rlpTransaction{},
+		ProofType: uint64(block.Proof.Type),
+		Evidence:  block.Proof.Evidence,
+	}
+	if block.Data != nil {
+		val.TxCount = block.Data.TxCount
+		for _, tx := range block.Data.TxList {
+			val.TxList = append(val.TxList, toRLPTransaction(tx))
+		}
+	}
+	return val
+}
+
+// EncodeBlock return the compressed canonical encoding of block
+func EncodeBlock(block *Block) ([]byte, error) {
+	val := toRLPBlock(block)
+	return encodeCompressed(&val)
+}
+
+// DecodeBlock decode a block in the canonical or the legacy binary encoding
+func DecodeBlock(b []byte) (*Block, error) {
+	val, block := &rlpBlock{}, &Block{}
+	canonical, err := decodeCanonical(b, val, block)
+	if err != nil {
+		return nil, err
+	}
+	if !canonical {
+		return block, nil
+	}
+	block.Header = val.Header.blockHeader()
+	block.Data = &BlockData{TxCount: val.TxCount, TxList: make([]*Transaction, len(val.TxList))}
+	for i := range val.TxList {
+		block.Data.TxList[i] = val.TxList[i].transaction()
+	}
+	block.Proof = Proof{Type: int(val.ProofType), Evidence: val.Evidence}
+	return block, nil
+}
+
+// EncodeTransaction return the canonical encoding of tx
+func EncodeTransaction(tx *Transaction) ([]byte, error) {
+	val := toRLPTransaction(tx)
+	return encodeCanonical(&val)
+}
+
+// DecodeTransaction decode a transaction in the canonical or the legacy binary encoding
+func DecodeTransaction(b []byte) (*Transaction, error) {
+	val, tx := &rlpTransaction{}, &Transaction{}
+	canonical, err := decodeCanonical(b, val, tx)
+	if err != nil {
+		return nil, err
+	}
+	if canonical {
+		return val.transaction(), nil
+	}
+	return tx, nil
+}
+
+// EncodeReceipt return the compressed canonical encoding of receipt
+func EncodeReceipt(receipt *Receipt) ([]byte, error) {
+	val := toRLPReceipt(receipt)
+	return encodeCompressed(&val)
+}
+
+// DecodeReceipt decode a receipt in the canonical or the legacy binary encoding
+func DecodeReceipt(b []byte) (*Receipt, error) {
+	val, receipt := &rlpReceipt{}, &Receipt{}
+	canonical, err := decodeCanonical(b, val, receipt)
+	if err != nil {
+		return nil, err
+	}
+	if canonical {
+		return val.receipt(), nil
+	}
+	return receipt, nil
+}
+
+// EncodeReceipts return the compressed canonical encoding of the receipts of a block
+func EncodeReceipts(receipts []*Receipt) ([]byte, error) {
+	val := make([]rlpReceipt, len(receipts))
+	for i, receipt := range receipts {
+		val[i] = toRLPReceipt(receipt)
+	}
+	return encodeCompressed(val)
+}
+
+// DecodeReceipts decode the receipts of a block in the canonical or the legacy binary encoding
+func DecodeReceipts(b []byte) ([]*Receipt, error) {
+	var val []rlpReceipt
+	var receipts []*Receipt
+	canonical, err := decodeCanonical(b, &val, &receipts)
+	if err != nil {
+		return nil, err
+	}
+	if !canonical {
+		return receipts, nil
+	}
+	receipts = make([]*Receipt, len(val))
+	for i := range val {
+		receipts[i] = val[i].receipt()
+	}
+	return receipts, nil
+}
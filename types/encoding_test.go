@@ -0,0 +1,202 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
+)
+
+var emptyBloom = "b90100" + strings.Repeat("00", BloomByteLength)
+
+// golden vectors of the canonical encoding, they must never change for EncodingV1
+const (
+	goldenTransaction = "ff445201f86b010780941111111111111111111111111111111111111111018203e802827530845f5e100082abcdb841" +
+		"5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a5a"
+)
+
+var (
+	goldenBlock = "ff445201f901e3f9016e0101a02222222222222222222222222222222222222222222222222222222222222222840aba9500825208" +
+		"0a845f5e100a82aabb80a00000000000000000000000000000000000000000000000000000000000000000941111111111111111111111111111111111111111" +
+		emptyBloom + "01f86d" + goldenTransaction[8:] + "8080"
+	goldenReceipt = "ff445201f901c58001825208f860f85e80941111111111111111111111111111111111111111e1a0" +
+		"33333333333333333333333333333333333333333333333333333333333333330101a044444444444444444444444444444444444444444444444444444444444444440a8080" +
+		emptyBloom + "a04444444444444444444444444444444444444444444444444444444444444444940000000000000000000000000000000000000000825208" +
+		"a055555555555555555555555555555555555555555555555555555555555555550a"
+)
+
+func goldenObjects() (*Block, *Receipt) {
+	to := crypto.CommonAddress{}
+	for i := range to {
+		to[i] = 0x11
+	}
+	tx := &Transaction{
+		Data: TransactionData{
+			Version:   1,
+			Nonce:     7,
+			Type:      TransferType,
+			To:        to,
+			ChainId:   1,
+			Amount:    common.Big(*big.NewInt(1000)),
+			GasPrice:  common.Big(*big.NewInt(2)),
+			GasLimit:  common.Big(*big.NewInt(30000)),
+			Timestamp: 1600000000,
+			Data:      []byte{0xab, 0xcd},
+		},
+		Sig: bytes.Repeat([]byte{0x5a}, 65),
+	}
+	block := &Block{
+		Header: &BlockHeader{
+			ChainId:      1,
+			Version:      1,
+			PreviousHash: crypto.BytesToHash(bytes.Repeat([]byte{0x22}, 32)),
+			GasLimit:     *big.NewInt(180000000),
+			GasUsed:      *big.NewInt(21000),
+			Height:       10,
+			Timestamp:    1600000010,
+			StateRoot:    []byte{0xaa, 0xbb},
+			TxRoot:       []byte{},
+			MinerAddr:    to,
+		},
+		Data: &BlockData{TxCount: 1, TxList: []*Transaction{tx}},
+	}
+	receipt := &Receipt{
+		PostState:         []byte{},
+		Status:            ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs: []*Log{{
+			Address: to,
+			Topics:  []crypto.Hash{crypto.BytesToHash(bytes.Repeat([]byte{0x33}, 32))},
+			Data:    []byte{0x01},
+			ChainId: 1,
+			TxHash:  crypto.BytesToHash(bytes.Repeat([]byte{0x44}, 32)),
+			Height:  10,
+		}},
+		TxHash:      crypto.BytesToHash(bytes.Repeat([]byte{0x44}, 32)),
+		GasUsed:     21000,
+		BlockHash:   crypto.BytesToHash(bytes.Repeat([]byte{0x55}, 32)),
+		BlockNumber: 10,
+	}
+	return block, receipt
+}
+
+func TestCanonicalEncodingGolden(t *testing.T) {
+	block, receipt := goldenObjects()
+	cases := []struct {
+		name   string
+		encode func() ([]byte, error)
+		golden string
+	}{
+		{"transaction", func() ([]byte, error) { return EncodeTransaction(block.Data.TxList[0]) }, goldenTransaction},
+		{"block", func() ([]byte, error) { val := toRLPBlock(block); return encodeCanonical(&val) }, goldenBlock},
+		{"receipt", func() ([]byte, error) { val := toRLPReceipt(receipt); return encodeCanonical(&val) }, goldenReceipt},
+	}
+	for _, c := range cases {
+		b, err := c.encode()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if hex.EncodeToString(b) != c.golden {
+			t.Errorf("%s encoding changed\n got %x\nwant %s", c.name, b, c.golden)
+		}
+	}
+}
+
+func TestCanonicalEncodingRoundTrip(t *testing.T) {
+	block, receipt := goldenObjects()
+	b, _ := hex.DecodeString(goldenBlock)
+	decoded, err := DecodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded.Header.Hash() != *block.Header.Hash() {
+		t.Fatal("block header changed by round trip")
+	}
+	if len(decoded.Data.TxList) != 1 || *decoded.Data.TxList[0].TxHash() != *block.Data.TxList[0].TxHash() {
+		t.Fatal("transaction changed by round trip")
+	}
+
+	b, _ = hex.DecodeString(goldenReceipt)
+	decodedReceipt, err := DecodeReceipt(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decodedReceipt.ReceiptHash() != *receipt.ReceiptHash() {
+		t.Fatal("receipt changed by round trip")
+	}
+
+	receipts, err := EncodeReceipts([]*Receipt{receipt, receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decodedReceipts, err := DecodeReceipts(receipts); err != nil || len(decodedReceipts) != 2 {
+		t.Fatalf("decoded %d receipts, %v", len(decodedReceipts), err)
+	}
+}
+
+func TestCompressedEncoding(t *testing.T) {
+	block, receipt := goldenObjects()
+	b, err := EncodeBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if EncodingOf(b) != EncodingV2 {
+		t.Fatalf("block written in encoding %d, want %d", EncodingOf(b), EncodingV2)
+	}
+	decoded, err := DecodeBlock(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded.Header.Hash() != *block.Header.Hash() || len(decoded.Data.TxList) != 1 {
+		t.Fatal("compressed block decoded wrong")
+	}
+
+	b, err = EncodeReceipts([]*Receipt{receipt, receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, _ := hex.DecodeString(goldenReceipt)
+	if len(b) >= 2*len(golden) {
+		t.Errorf("receipts not compressed: %d bytes", len(b))
+	}
+	if receipts, err := DecodeReceipts(b); err != nil || len(receipts) != 2 || *receipts[1].ReceiptHash() != *receipt.ReceiptHash() {
+		t.Fatalf("compressed receipts decoded to %v, %v", receipts, err)
+	}
+	if EncodingOf(golden) != EncodingV1 {
+		t.Errorf("golden receipt in encoding %d, want %d", EncodingOf(golden), EncodingV1)
+	}
+}
+
+func TestDecodeLegacyEncoding(t *testing.T) {
+	block, receipt := goldenObjects()
+	legacy, err := binary.Marshal(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsCanonicalEncoding(legacy) {
+		t.Fatal("binary encoding taken for canonical")
+	}
+	decoded, err := DecodeBlock(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded.Header.Hash() != *block.Header.Hash() || len(decoded.Data.TxList) != 1 {
+		t.Fatal("legacy block decoded wrong")
+	}
+
+	legacy, _ = binary.Marshal([]*Receipt{receipt})
+	if receipts, err := DecodeReceipts(legacy); err != nil || len(receipts) != 1 || receipts[0].TxHash != receipt.TxHash {
+		t.Fatalf("legacy receipts decoded to %v, %v", receipts, err)
+	}
+
+	unknown, _ := hex.DecodeString(goldenTransaction)
+	unknown[len(encodingMagic)] = CurrentEncoding + 1
+	if _, err := DecodeTransaction(unknown); err != ErrEncodingVersion {
+		t.Fatalf("unknown version decoded, %v", err)
+	}
+}
@@ -7,6 +7,29 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*Transaction }
 
+// DroppedTxsEvent is posted when transactions are removed from the transaction pool
+// before being packed, they need to be sent again.
+type DroppedTxsEvent struct {
+	Txs    []*Transaction
+	Reason string
+}
+
+// Types of TxPoolEvent
+const (
+	TxPoolEventAdded    = "added"
+	TxPoolEventReplaced = "replaced"
+	TxPoolEventDropped  = "dropped"
+)
+
+// TxPoolEvent is posted for every transaction accepted into the transaction pool, and
+// for every one dropped or replaced before being packed.
+type TxPoolEvent struct {
+	Type       string
+	Tx         *Transaction
+	Reason     string       // why Tx was dropped
+	ReplacedBy *crypto.Hash // the transaction replacing Tx
+}
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*Log
@@ -29,3 +52,12 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *Block }
+
+// ReorgEvent is posted when the best chain switch to a fork, OldChain is the detached
+// segment and NewChain is the attached segment, both ordered from fork point to tip.
+type ReorgEvent struct {
+	ForkHeight uint64
+	ForkHash   crypto.Hash
+	OldChain   []*Block
+	NewChain   []*Block
+}
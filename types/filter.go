@@ -0,0 +1,35 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+// AddressBloom creates a bloom filter matching the given addresses, light peers send it to
+// serving peers to receive only the announcements they are interested in.
+func AddressBloom(addrs []crypto.CommonAddress) Bloom {
+	bin := new(big.Int)
+	for _, addr := range addrs {
+		bin.Or(bin, bloom9(addr.Bytes()))
+	}
+	return BytesToBloom(bin.Bytes())
+}
+
+// MatchTx reports whether the sender or the receiver of tx matches the filter.
+func (b Bloom) MatchTx(tx *Transaction) bool {
+	if from, err := tx.From(); err == nil && BloomLookup(b, from) {
+		return true
+	}
+	return BloomLookup(b, tx.To())
+}
+
+// MatchBlock reports whether any transaction of blk matches the filter.
+func (b Bloom) MatchBlock(blk *Block) bool {
+	for _, tx := range blk.Data.TxList {
+		if b.MatchTx(tx) {
+			return true
+		}
+	}
+	return false
+}
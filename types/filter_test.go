@@ -0,0 +1,74 @@
+package types
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/binary"
+)
+
+func TestAddressBloomMatch(t *testing.T) {
+	key, _ := crypto.GenerateKey(rand.Reader)
+	sender := crypto.PubkeyToAddress(key.PubKey())
+	watched := crypto.HexToAddress("0x8a8e541ddd1272d53729164c70197221a3c27486")
+	other := crypto.HexToAddress("0x3ebcbe7cb440dd8c52940a2963472380afbb56c5")
+
+	filter := AddressBloom([]crypto.CommonAddress{watched, sender})
+
+	toWatched := NewTransaction(watched, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 0)
+	if !filter.MatchTx(toWatched) {
+		t.Error("tx to a watched address not matched")
+	}
+	toOther := NewTransaction(other, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 1)
+	if filter.MatchTx(toOther) {
+		t.Error("unsigned tx to an unwatched address matched")
+	}
+	sig, err := secp256k1.SignCompact(key, toOther.TxHash().Bytes(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toOther.Sig = sig
+	if !filter.MatchTx(toOther) {
+		t.Error("tx from a watched address not matched")
+	}
+
+	block := &Block{Data: &BlockData{TxList: []*Transaction{NewTransaction(other, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 2)}}}
+	if filter.MatchBlock(block) {
+		t.Error("block without watched tx matched")
+	}
+	block.Data.TxList = append(block.Data.TxList, toWatched)
+	if !filter.MatchBlock(block) {
+		t.Error("block with watched tx not matched")
+	}
+}
+
+func TestPeerInfoFilter(t *testing.T) {
+	watched := crypto.HexToAddress("0x8a8e541ddd1272d53729164c70197221a3c27486")
+	other := crypto.HexToAddress("0x3ebcbe7cb440dd8c52940a2963472380afbb56c5")
+	tx := NewTransaction(other, big.NewInt(1), big.NewInt(1), big.NewInt(21000), 0)
+
+	peer := NewPeerInfo(nil, nil)
+	if !peer.MatchTx(tx) {
+		t.Fatal("peer without filter must match every tx")
+	}
+
+	bytes, err := binary.Marshal(&FilterLoad{Filter: AddressBloom([]crypto.CommonAddress{watched})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req FilterLoad
+	if err := binary.Unmarshal(bytes, &req); err != nil {
+		t.Fatal(err)
+	}
+	peer.SetFilter(&req.Filter)
+	if peer.MatchTx(tx) {
+		t.Error("filtered peer matched unwatched tx")
+	}
+	peer.SetFilter(nil)
+	if !peer.MatchTx(tx) {
+		t.Error("cleared filter still applied")
+	}
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
+)
+
+// nonCanonicalFields return the fields of typ encoded in an order that may differ between
+// nodes or versions, maps are iterated randomly and interfaces depend on the dynamic type
+func nonCanonicalFields(typ reflect.Type, path string, seen map[reflect.Type]bool, skip map[string]bool) []string {
+	if seen[typ] || skip[path] {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return []string{path}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return nonCanonicalFields(typ.Elem(), path, seen, skip)
+	case reflect.Struct:
+		seen[typ] = true
+		fields := []string{}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" || field.Tag.Get("binary") == "ignore" {
+				continue
+			}
+			fields = append(fields, nonCanonicalFields(field.Type, path+"."+field.Name, seen, skip)...)
+		}
+		return fields
+	}
+	return nil
+}
+
+func TestHashInputsCanonical(t *testing.T) {
+	// BalanceMap is rejected by the account store while not empty
+	skip := map[string]bool{"Storage.BalanceMap": true}
+	for _, value := range []interface{}{TransactionData{}, BlockHeader{}, Receipt{}, StakeStorage{}, Storage{}} {
+		typ := reflect.TypeOf(value)
+		if fields := nonCanonicalFields(typ, typ.Name(), map[reflect.Type]bool{}, skip); len(fields) > 0 {
+			t.Errorf("hashed fields without canonical encoding %v", fields)
+		}
+	}
+}
+
+// golden vectors of the hashed encodings, a change means every node computes new hashes
+var (
+	goldenTxSignMessage = "0007022967f0629a5b84c981279ffbe330fe6154be7ad5000203e8011402c350bc9889d00b03010203"
+	goldenTxHash        = "75a4c56ee6550ae7f84c9db6263c3bbf77c1346ccf14c3317e591d69c00400b4"
+	goldenHeader        = "010201" + strings.Repeat("00", 31) + "034c4b40025208649ecc84e80501020103" + strings.Repeat("00", 32) +
+		"04" + strings.Repeat("00", 19+BloomByteLength)
+	goldenHeaderHash = "9466420ac19200d65d572e104a7c8cba293675fbca737e3ca0e8903bcb58e307"
+)
+
+func newHashTestTx() *Transaction {
+	tx := NewCallContractTransaction(crypto.HexToAddress("0x2967f0629a5b84c981279ffbe330fe6154be7ad5"), []byte{1, 2, 3}, big.NewInt(1000), big.NewInt(20), big.NewInt(50000), 7)
+	tx.Data.Timestamp = 1560356382
+	tx.Sig = []byte{9, 9, 9}
+	return tx
+}
+
+func newHashTestHeader() *BlockHeader {
+	return &BlockHeader{
+		ChainId:      1,
+		Version:      1,
+		PreviousHash: crypto.Hash{1},
+		GasLimit:     *big.NewInt(5000000),
+		GasUsed:      *big.NewInt(21000),
+		Height:       100,
+		Timestamp:    1560356382,
+		StateRoot:    []byte{2},
+		TxRoot:       []byte{3},
+		MinerAddr:    crypto.CommonAddress{4},
+	}
+}
+
+func TestHashStable(t *testing.T) {
+	tx := newHashTestTx()
+	if got := hex.EncodeToString(tx.AsSignMessage()); got != goldenTxSignMessage {
+		t.Errorf("transaction sign message changed\n got %s\nwant %s", got, goldenTxSignMessage)
+	}
+	if got := hex.EncodeToString(tx.TxHash().Bytes()); got != goldenTxHash {
+		t.Errorf("transaction hash changed\n got %s\nwant %s", got, goldenTxHash)
+	}
+	decoded := &Transaction{}
+	if err := binary.Unmarshal(tx.AsPersistentMessage(), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(decoded.TxHash().Bytes()); got != goldenTxHash {
+		t.Errorf("transaction hash changed after decoding\n got %s\nwant %s", got, goldenTxHash)
+	}
+
+	header := newHashTestHeader()
+	b, err := binary.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(b); got != goldenHeader {
+		t.Errorf("header encoding changed\n got %s\nwant %s", got, goldenHeader)
+	}
+	if got := hex.EncodeToString(header.Hash().Bytes()); got != goldenHeaderHash {
+		t.Errorf("header hash changed\n got %s\nwant %s", got, goldenHeaderHash)
+	}
+
+	block1 := &Block{Header: newHashTestHeader(), Data: &BlockData{TxCount: 1, TxList: []*Transaction{newHashTestTx()}}}
+	block2 := &Block{Header: newHashTestHeader(), Data: &BlockData{TxCount: 1, TxList: []*Transaction{newHashTestTx()}}}
+	if !bytes.Equal(block1.AsSignMessage(), block2.AsSignMessage()) {
+		t.Fatal("equal blocks sign different messages")
+	}
+}
@@ -8,4 +8,6 @@ import "time"
 type OrphanBlock struct {
 	Block      *Block
 	Expiration time.Time
+	Peer       string // peer the block come from, empty for local
+	Size       int    // encoded size of block
 }
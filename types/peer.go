@@ -14,8 +14,22 @@ import (
 var (
 	maxCacheBlockNum = 1024
 	maxCacheTxNum    = 1024 //Maximum number of cached transactions per account
+	maxExchangeTxNum = 8192 //Maximum number of transaction hashes exchanged with a peer
 )
 
+//KnownHashSize is the approximate memory used by one known block or transaction record
+const KnownHashSize = 128
+
+//SetKnownCacheLimit set the maximum number of known blocks and known transactions per account of each peer
+func SetKnownCacheLimit(blocks, txs int) {
+	if blocks > 0 {
+		maxCacheBlockNum = blocks
+	}
+	if txs > 0 {
+		maxCacheTxNum = txs
+	}
+}
+
 type PeerInfoInterface interface {
 	GetMsgRW() p2p.MsgReadWriter
 	GetHeight() uint64
@@ -24,12 +38,20 @@ type PeerInfoInterface interface {
 	SetHeight(height uint64)
 	KnownTx(tx *Transaction) bool
 	MarkTx(tx *Transaction)
+	KnownTxHash(hash *crypto.Hash) bool
+	MarkTxHash(hash *crypto.Hash)
 	KnownBlock(blk *Block) bool
 	MarkBlock(blk *Block)
+	KnownCount() int
 
 	SetReqTime(t time.Time)
 	CalcAverageRtt()
 	AverageRtt() time.Duration
+
+	SetFilter(filter *Bloom)
+	MatchTx(tx *Transaction) bool
+	MatchBlock(blk *Block) bool
+	Version() uint
 }
 
 var _ PeerInfoInterface = &PeerInfo{}
@@ -39,12 +61,15 @@ type PeerInfo struct {
 	lock        sync.Mutex
 	height      uint64                                //Peer current block height
 	exchangeTxs map[crypto.Hash]struct{}              //transaction records exchanged with Peer
+	exchangeIds []crypto.Hash                         //hashes of exchangeTxs in the order recorded
 	knownTxs    map[crypto.CommonAddress]*sortedBiMap //sorted by NONCE
 	knownBlocks *sortedBiMap                          //sorted by height
 	peer        *p2p.Peer                             //p2p peer layer
 	rw          p2p.MsgReadWriter                     //the protocol corresponding to peer
 	reqTime     *time.Time                            //The system time when a request is sent to a peer
 	averageRtt  time.Duration                         //The estimated time of the request between local and peer
+	filter      *Bloom                                //Address filter registered by a light peer, nil means no filtering
+	version     uint                                  //blockMgr protocol version negotiated with the peer
 }
 
 func NewPeerInfo(p *p2p.Peer, rw p2p.MsgReadWriter) *PeerInfo {
@@ -52,6 +77,7 @@ func NewPeerInfo(p *p2p.Peer, rw p2p.MsgReadWriter) *PeerInfo {
 		peer:        p,
 		rw:          rw,
 		height:      0,
+		exchangeTxs: make(map[crypto.Hash]struct{}),
 		knownTxs:    make(map[crypto.CommonAddress]*sortedBiMap),
 		knownBlocks: newValueSortedBiMap(),
 		reqTime:     nil,
@@ -116,6 +142,9 @@ func (peer *PeerInfo) KnownTx(tx *Transaction) bool {
 
 	peer.lock.Lock()
 	defer peer.lock.Unlock()
+	if _, ok := peer.exchangeTxs[*hash]; ok {
+		return true
+	}
 	if sortedTxs, ok := peer.knownTxs[*addr]; ok {
 		if sortedTxs.Exist(hash) {
 			return true
@@ -131,6 +160,7 @@ func (peer *PeerInfo) MarkTx(tx *Transaction) {
 	addr, _ := tx.From()
 	peer.lock.Lock()
 	defer peer.lock.Unlock()
+	peer.markTxHash(hash)
 
 	if sortedTxs, ok := peer.knownTxs[*addr]; ok {
 		if sortedTxs.Len() > maxCacheTxNum {
@@ -145,6 +175,33 @@ func (peer *PeerInfo) MarkTx(tx *Transaction) {
 	peer.knownTxs[*addr] = sortedTxs
 }
 
+//KnownTxHash whether the peer end already knows the tx of hash, it was exchanged or announced
+func (peer *PeerInfo) KnownTxHash(hash *crypto.Hash) bool {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	_, ok := peer.exchangeTxs[*hash]
+	return ok
+}
+
+//MarkTxHash record the tx of hash announced by or to the peer, the body may not be exchanged yet
+func (peer *PeerInfo) MarkTxHash(hash *crypto.Hash) {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	peer.markTxHash(hash)
+}
+
+func (peer *PeerInfo) markTxHash(hash *crypto.Hash) {
+	if _, ok := peer.exchangeTxs[*hash]; ok {
+		return
+	}
+	if len(peer.exchangeIds) >= maxExchangeTxNum {
+		delete(peer.exchangeTxs, peer.exchangeIds[0])
+		peer.exchangeIds = peer.exchangeIds[1:]
+	}
+	peer.exchangeTxs[*hash] = struct{}{}
+	peer.exchangeIds = append(peer.exchangeIds, *hash)
+}
+
 func (peer *PeerInfo) KnownBlock(blk *Block) bool {
 	h := blk.Header.Hash()
 	if h == nil {
@@ -179,6 +236,59 @@ func (peer *PeerInfo) MarkBlock(blk *Block) {
 	}
 }
 
+//KnownCount return the number of known blocks and transactions recorded for the peer
+func (peer *PeerInfo) KnownCount() int {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+
+	count := peer.knownBlocks.Len()
+	for _, sortedTxs := range peer.knownTxs {
+		count += sortedTxs.Len()
+	}
+	return count
+}
+
+//SetFilter set the address filter of the peer, nil removes it
+func (peer *PeerInfo) SetFilter(filter *Bloom) {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	peer.filter = filter
+}
+
+//SetVersion set the blockMgr protocol version negotiated with the peer
+func (peer *PeerInfo) SetVersion(version uint) {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	peer.version = version
+}
+
+//Version return the blockMgr protocol version negotiated with the peer
+func (peer *PeerInfo) Version() uint {
+	peer.lock.Lock()
+	defer peer.lock.Unlock()
+	return peer.version
+}
+
+//MatchTx whether the tx should be announced to the peer, false if the peer speaks a protocol
+//version older than the kind of the tx, otherwise always true if the peer has no filter
+func (peer *PeerInfo) MatchTx(tx *Transaction) bool {
+	peer.lock.Lock()
+	filter, version := peer.filter, peer.version
+	peer.lock.Unlock()
+	if kind, err := tx.Kind(); err != nil || kind.Protocol > version {
+		return false
+	}
+	return filter == nil || filter.MatchTx(tx)
+}
+
+//MatchBlock whether the block should be announced to the peer, always true if the peer has no filter
+func (peer *PeerInfo) MatchBlock(blk *Block) bool {
+	peer.lock.Lock()
+	filter := peer.filter
+	peer.lock.Unlock()
+	return filter == nil || filter.MatchBlock(blk)
+}
+
 type uint64SliceHeap []uint64
 
 func (h uint64SliceHeap) Len() int           { return len(h) }
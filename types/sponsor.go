@@ -0,0 +1,93 @@
+package types
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/DREP-Chain/crypto/sha3"
+)
+
+// A sponsored transaction carries the signature of a fee payer after the signature of
+// the sender. The sender signs the transaction hash together with the address of the fee
+// payer, the fee payer signs the transaction hash too and pays its gas, so an account
+// without balance can send it. Dropping or replacing the fee payer signature changes the
+// account the sender signature recovers to, a relayer can't move the gas to the sender.
+const compactSigLength = 65
+
+var (
+	ErrNotSponsored     = errors.New("transaction is not sponsored")
+	ErrSponsoredAlready = errors.New("transaction is sponsored already")
+	ErrNotSigned        = errors.New("transaction is not signed by its sender")
+)
+
+func (tx *Transaction) senderSig() []byte {
+	if tx.IsSponsored() {
+		return tx.Sig[:compactSigLength]
+	}
+	return tx.Sig
+}
+
+// senderHash return the hash the sender signature recovers over
+func (tx *Transaction) senderHash() ([]byte, error) {
+	if !tx.IsSponsored() {
+		return tx.TxHash().Bytes(), nil
+	}
+	payer, err := tx.FeePayer()
+	if err != nil {
+		return nil, err
+	}
+	return tx.SponsoredHash(payer), nil
+}
+
+// IsSponsored return whether the gas of the transaction is paid by a fee payer
+func (tx *Transaction) IsSponsored() bool {
+	return len(tx.Sig) == 2*compactSigLength
+}
+
+// SponsoredHash is the hash signed by the sender of a transaction sponsored by payer, the
+// sender agrees on the fee payer with it
+func (tx *Transaction) SponsoredHash(payer *crypto.CommonAddress) []byte {
+	return sha3.Keccak256([]byte("sponsored"), tx.TxHash().Bytes(), payer.Bytes())
+}
+
+// FeePayerHash is the hash signed by the fee payer, it differs from the transaction hash
+// so a fee payer signature never passes as the signature of a sender
+func (tx *Transaction) FeePayerHash() []byte {
+	return sha3.Keccak256([]byte("feePayer"), tx.TxHash().Bytes())
+}
+
+// Sponsor append the signature of the fee payer to a transaction whose sender signed its
+// SponsoredHash for that fee payer
+func (tx *Transaction) Sponsor(sig []byte) error {
+	if tx.IsSponsored() {
+		return ErrSponsoredAlready
+	}
+	if len(tx.Sig) != compactSigLength {
+		return ErrNotSigned
+	}
+	tx.Sig = append(append([]byte{}, tx.Sig...), sig...)
+	// the persistent message includes the signatures, the sender is recovered over another hash
+	tx.message = atomic.Value{}
+	tx.from = atomic.Value{}
+	return nil
+}
+
+// FeePayer return the account paying the gas of the transaction, the sender if it is not
+// sponsored
+func (tx *Transaction) FeePayer() (*crypto.CommonAddress, error) {
+	if !tx.IsSponsored() {
+		return tx.From()
+	}
+	if payer := tx.feePayer.Load(); payer != nil {
+		return payer.(*crypto.CommonAddress), nil
+	}
+	pk, _, err := secp256k1.RecoverCompact(tx.Sig[compactSigLength:], tx.FeePayerHash())
+	if err != nil {
+		return nil, err
+	}
+	addr := crypto.PubkeyToAddress(pk)
+	tx.feePayer.Store(&addr)
+	return &addr, nil
+}
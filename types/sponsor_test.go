@@ -0,0 +1,71 @@
+package types
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/DREP-Chain/crypto/secp256k1"
+	"github.com/drep-project/binary"
+)
+
+func TestSponsoredTransaction(t *testing.T) {
+	senderKey, _ := crypto.GenerateKey(rand.Reader)
+	payerKey, _ := crypto.GenerateKey(rand.Reader)
+	sender := crypto.PubkeyToAddress(senderKey.PubKey())
+	payer := crypto.PubkeyToAddress(payerKey.PubKey())
+
+	tx := NewTransaction(crypto.CommonAddress{1}, big.NewInt(0), big.NewInt(1), big.NewInt(30000), 0)
+	payerSig, _ := secp256k1.SignCompact(payerKey, tx.FeePayerHash(), true)
+	if err := tx.Sponsor(payerSig); err != ErrNotSigned {
+		t.Fatalf("unsigned transaction sponsored, %v", err)
+	}
+	senderSig, _ := secp256k1.SignCompact(senderKey, tx.SponsoredHash(&payer), true)
+	tx.Sig = senderSig
+	if tx.IsSponsored() {
+		t.Fatal("transaction sponsored before the fee payer signed")
+	}
+	if err := tx.Sponsor(payerSig); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Sponsor(payerSig); err != ErrSponsoredAlready {
+		t.Fatalf("transaction sponsored twice, %v", err)
+	}
+
+	decoded := &Transaction{}
+	if err := binary.Unmarshal(tx.AsPersistentMessage(), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if from, err := decoded.From(); err != nil || *from != sender {
+		t.Fatalf("sender recovered as %v, %v", from, err)
+	}
+	if feePayer, err := decoded.FeePayer(); err != nil || *feePayer != payer {
+		t.Fatalf("fee payer recovered as %v, %v", feePayer, err)
+	}
+	if *decoded.TxHash() != *tx.TxHash() {
+		t.Fatal("sponsorship changed the transaction hash")
+	}
+
+	// the fee payer signature does not pass as a signature of the payer as sender
+	replayed := &Transaction{Data: tx.Data, Sig: payerSig}
+	if from, err := replayed.From(); err == nil && *from == payer {
+		t.Fatal("fee payer signature recovered to the payer as sender")
+	}
+
+	// the sender signature is only valid with the fee payer it was signed for, a relayer
+	// dropping or replacing the fee payer signature doesn't get the sender to pay the gas
+	stripped := &Transaction{Data: tx.Data, Sig: senderSig}
+	if from, err := stripped.From(); err == nil && *from == sender {
+		t.Fatal("sender recovered with the fee payer signature dropped")
+	}
+	relayerKey, _ := crypto.GenerateKey(rand.Reader)
+	relayerSig, _ := secp256k1.SignCompact(relayerKey, tx.FeePayerHash(), true)
+	replaced := &Transaction{Data: tx.Data, Sig: senderSig}
+	if err := replaced.Sponsor(relayerSig); err != nil {
+		t.Fatal(err)
+	}
+	if from, err := replaced.From(); err == nil && *from == sender {
+		t.Fatal("sender recovered with the fee payer signature replaced")
+	}
+}
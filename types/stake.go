@@ -22,6 +22,9 @@ type CancelCredit struct {
 	CancelCreditValue  []big.Int
 }
 
+// StakeStorage is encoded into the state, so it holds slices only. Entries are kept in the order
+// of the transactions that made them, which is the same on every node; maps must not be added
+// here as their order is random and would split the state root.
 type StakeStorage struct {
 	RC []ReceivedCredit
 
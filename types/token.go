@@ -0,0 +1,122 @@
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/drep-project/DREP-Chain/common"
+	"github.com/drep-project/DREP-Chain/crypto"
+	"github.com/drep-project/binary"
+)
+
+const (
+	MinTokenSymbolLen = 2
+	MaxTokenSymbolLen = 12
+	MaxTokenNameLen   = 64
+	MaxTokenDecimals  = 18
+)
+
+var (
+	ErrTokenSymbol   = errors.New("token symbol must be 2 to 12 upper case letters or digits")
+	ErrTokenName     = errors.New("token name too long")
+	ErrTokenDecimals = errors.New("token decimals exceed 18")
+	ErrTokenSupply   = errors.New("token supply exceed max supply")
+	ErrTokenAmount   = errors.New("token amount must be positive")
+)
+
+// Fungible asset issued natively without a contract, balances of it are kept in the storage of accounts
+type Token struct {
+	Symbol    string               `json:"symbol"`
+	Name      string               `json:"name"`
+	Decimals  uint8                `json:"decimals"`
+	Issuer    crypto.CommonAddress `json:"issuer"`    //The only account allowed to mint
+	Supply    common.Big           `json:"supply"`    //Amount minted and not burned
+	MaxSupply common.Big           `json:"maxSupply"` //Supply never exceed it, zero if unlimited
+}
+
+// Data of the transaction issuing a token, Supply is minted to the issuer
+type IssueTokenData struct {
+	Symbol    string
+	Name      string
+	Decimals  uint8
+	Supply    common.Big
+	MaxSupply common.Big
+}
+
+func (data IssueTokenData) check() error {
+	if err := CheckTokenSymbol(data.Symbol); err != nil {
+		return err
+	}
+	if len(data.Name) > MaxTokenNameLen {
+		return ErrTokenName
+	}
+	if data.Decimals > MaxTokenDecimals {
+		return ErrTokenDecimals
+	}
+	supply, maxSupply := (*big.Int)(&data.Supply), (*big.Int)(&data.MaxSupply)
+	if supply.Sign() < 0 || maxSupply.Sign() < 0 {
+		return ErrTokenAmount
+	}
+	if maxSupply.Sign() > 0 && supply.Cmp(maxSupply) > 0 {
+		return ErrTokenSupply
+	}
+	return nil
+}
+
+func (data *IssueTokenData) Marshal() ([]byte, error) {
+	if err := data.check(); err != nil {
+		return nil, err
+	}
+	return binary.Marshal(data)
+}
+
+func (data *IssueTokenData) Unmarshal(b []byte) error {
+	if err := binary.Unmarshal(b, data); err != nil {
+		return err
+	}
+	return data.check()
+}
+
+// Data of the transactions minting, burning and transferring a token
+type TokenAmountData struct {
+	Symbol string
+	Amount common.Big
+}
+
+func (data TokenAmountData) check() error {
+	if err := CheckTokenSymbol(data.Symbol); err != nil {
+		return err
+	}
+	if (*big.Int)(&data.Amount).Sign() <= 0 {
+		return ErrTokenAmount
+	}
+	return nil
+}
+
+func (data *TokenAmountData) Marshal() ([]byte, error) {
+	if err := data.check(); err != nil {
+		return nil, err
+	}
+	return binary.Marshal(data)
+}
+
+func (data *TokenAmountData) Unmarshal(b []byte) error {
+	if err := binary.Unmarshal(b, data); err != nil {
+		return err
+	}
+	return data.check()
+}
+
+// CheckTokenSymbol check the symbol only has upper case letters and digits, so a symbol is never
+// mistaken for another by case
+func CheckTokenSymbol(symbol string) error {
+	if len(symbol) < MinTokenSymbolLen || len(symbol) > MaxTokenSymbolLen {
+		return ErrTokenSymbol
+	}
+	for _, c := range symbol {
+		if !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			return ErrTokenSymbol
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/common"
+)
+
+func TestCheckTokenSymbol(t *testing.T) {
+	for _, symbol := range []string{"GOLD", "A1", "ABCDEFGHIJ12"} {
+		if err := CheckTokenSymbol(symbol); err != nil {
+			t.Fatalf("symbol %s refused, %v", symbol, err)
+		}
+	}
+	for _, symbol := range []string{"G", "gold", "GO-LD", "ABCDEFGHIJ123"} {
+		if err := CheckTokenSymbol(symbol); err != ErrTokenSymbol {
+			t.Fatalf("symbol %s accepted, %v", symbol, err)
+		}
+	}
+}
+
+func TestIssueTokenData(t *testing.T) {
+	data := &IssueTokenData{
+		Symbol:    "GOLD",
+		Name:      "gold coin",
+		Decimals:  8,
+		Supply:    common.Big(*big.NewInt(1000)),
+		MaxSupply: common.Big(*big.NewInt(2000)),
+	}
+	b, err := data.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &IssueTokenData{}
+	if err := decoded.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Symbol != "GOLD" || decoded.Decimals != 8 || decoded.Supply.ToInt().Int64() != 1000 {
+		t.Fatalf("unexpected data %v", decoded)
+	}
+
+	data.Supply = common.Big(*big.NewInt(3000))
+	if _, err := data.Marshal(); err != ErrTokenSupply {
+		t.Fatalf("supply over max supply accepted, %v", err)
+	}
+
+	amount := &TokenAmountData{Symbol: "GOLD"}
+	if _, err := amount.Marshal(); err != ErrTokenAmount {
+		t.Fatalf("zero amount accepted, %v", err)
+	}
+}
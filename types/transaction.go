@@ -21,6 +21,7 @@ type Transaction struct {
 	signMessage atomic.Value `json:"-" binary:"ignore" bson:"-"`
 	message     atomic.Value `json:"-" binary:"ignore" bson:"-"`
 	from        atomic.Value `json:"-" binary:"ignore"`
+	feePayer    atomic.Value `json:"-" binary:"ignore"`
 }
 
 type TransactionData struct {
@@ -56,7 +57,11 @@ func (tx *Transaction) From() (*crypto.CommonAddress, error) {
 		return sc.(*crypto.CommonAddress), nil
 	}
 
-	pk, _, err := secp256k1.RecoverCompact(tx.Sig, tx.TxHash().Bytes())
+	hash, err := tx.senderHash()
+	if err != nil {
+		return nil, err
+	}
+	pk, _, err := secp256k1.RecoverCompact(tx.senderSig(), hash)
 	if err != nil {
 		return nil, err
 	}
@@ -120,6 +125,8 @@ func (tx *Transaction) GetSig() []byte {
 	return tx.Sig
 }
 
+// AsSignMessage return the message signed by sender, it include the chain id in TransactionData
+// so the transaction is only valid in one network
 func (tx *Transaction) AsSignMessage() []byte {
 	if val := tx.signMessage.Load(); val != nil {
 		return val.([]byte)
@@ -239,6 +246,54 @@ func NewAliasTransaction(alias string, gasPrice, gasLimit *big.Int, nonce uint64
 	return &Transaction{Data: data}
 }
 
+//Relay ethereum headers, deposit to or withdraw from the cross chain bridge
+func NewBridgeTransaction(data []byte, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	tx := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      BridgeType,
+		To:        crypto.CommonAddress{},
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: int64(time.Now().Unix()),
+		Data:      data,
+	}
+	return &Transaction{Data: tx}
+}
+
+//Issue, mint, burn or transfer a native token, to receives the minted or transferred amount
+func NewTokenTransaction(txType TxType, to crypto.CommonAddress, data []byte, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	tx := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      txType,
+		To:        to,
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: int64(time.Now().Unix()),
+		Data:      data,
+	}
+	return &Transaction{Data: tx}
+}
+
+//Attest the reputation of to, or set the reputation decay rule
+func NewReputationTransaction(txType TxType, to crypto.CommonAddress, data []byte, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
+	tx := TransactionData{
+		Version:   common.Version,
+		Nonce:     nonce,
+		Type:      txType,
+		To:        to,
+		Amount:    *(*common.Big)(new(big.Int)),
+		GasPrice:  *(*common.Big)(gasPrice),
+		GasLimit:  *(*common.Big)(gasLimit),
+		Timestamp: int64(time.Now().Unix()),
+		Data:      data,
+	}
+	return &Transaction{Data: tx}
+}
+
 func NewVoteTransaction(to crypto.CommonAddress, amount, gasPrice, gasLimit *big.Int, nonce uint64) *Transaction {
 	data := TransactionData{
 		Version:   common.Version,
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Every transaction is an envelope around a payload: the type byte TransactionData.Type tells
+// which kind of transaction it is, TransactionData.Version in which layout Data is written. A
+// kind lists the payload versions it accepts, so a transaction of an unknown kind or version is
+// rejected by the pool and the executor instead of being read with a wrong layout, and a new
+// kind is only relayed to peers whose blockMgr protocol version knows it.
+const MaxTxType TxType = 0xff
+
+var (
+	ErrUnknownTxType    = errors.New("unknown transaction type")
+	ErrTxPayloadVersion = errors.New("unsupported transaction payload version")
+)
+
+// TxKind describe a kind of transaction
+type TxKind struct {
+	Name     string
+	Versions []int32 //Payload versions accepted
+	Protocol uint    //First blockMgr protocol version relaying the kind
+
+	decode func(version int32, data []byte) (interface{}, error)
+}
+
+// legacyVersions are the versions written before payloads were versioned, contract transactions
+// were created without a version
+var legacyVersions = []int32{0, 1}
+
+var (
+	txKindsLock sync.RWMutex
+	txKinds     = map[TxType]*TxKind{
+		TransferType:         {Name: "transfer", Versions: legacyVersions},
+		CreateContractType:   {Name: "createContract", Versions: legacyVersions},
+		CallContractType:     {Name: "callContract", Versions: legacyVersions},
+		SetAliasType:         {Name: "setAlias", Versions: legacyVersions, decode: decodeAlias},
+		VoteCreditType:       {Name: "voteCredit", Versions: legacyVersions},
+		CancelVoteCreditType: {Name: "cancelVoteCredit", Versions: legacyVersions},
+		CandidateType:        {Name: "candidate", Versions: legacyVersions, decode: decodeCandidate},
+		CancelCandidateType:  {Name: "cancelCandidate", Versions: legacyVersions},
+		RegisterProducer:     {Name: "registerProducer", Versions: legacyVersions},
+		BridgeType:           {Name: "bridge", Versions: legacyVersions},
+		IssueTokenType:       {Name: "issueToken", Versions: legacyVersions, decode: decodeIssueToken},
+		MintTokenType:        {Name: "mintToken", Versions: legacyVersions, decode: decodeTokenAmount},
+		BurnTokenType:        {Name: "burnToken", Versions: legacyVersions, decode: decodeTokenAmount},
+		TransferTokenType:    {Name: "transferToken", Versions: legacyVersions, decode: decodeTokenAmount},
+		AttestReputationType: {Name: "attestReputation", Versions: legacyVersions},
+		ReputationRuleType:   {Name: "reputationRule", Versions: legacyVersions},
+	}
+)
+
+// RegisterTxKind add a kind of transaction, it panics if the type is taken or exceeds a byte
+func RegisterTxKind(txType TxType, kind *TxKind) {
+	txKindsLock.Lock()
+	defer txKindsLock.Unlock()
+	if txType > MaxTxType {
+		panic(fmt.Sprintf("transaction type %d exceeds a byte", txType))
+	}
+	if _, ok := txKinds[txType]; ok {
+		panic(fmt.Sprintf("transaction type %d registered twice", txType))
+	}
+	txKinds[txType] = kind
+}
+
+// SetTxPayloadDecoder set the function decoding the payload of a kind for rpc, it is used by
+// packages defining the payload of a kind declared here
+func SetTxPayloadDecoder(txType TxType, decode func(version int32, data []byte) (interface{}, error)) {
+	txKindsLock.Lock()
+	defer txKindsLock.Unlock()
+	if kind, ok := txKinds[txType]; ok {
+		kind.decode = decode
+	}
+}
+
+// LookupTxKind return the kind of a transaction type
+func LookupTxKind(txType TxType) (*TxKind, bool) {
+	txKindsLock.RLock()
+	defer txKindsLock.RUnlock()
+	kind, ok := txKinds[txType]
+	return kind, ok
+}
+
+// TxKinds return the registered kinds by type
+func TxKinds() map[TxType]TxKind {
+	txKindsLock.RLock()
+	defer txKindsLock.RUnlock()
+	kinds := make(map[TxType]TxKind, len(txKinds))
+	for txType, kind := range txKinds {
+		kinds[txType] = *kind
+	}
+	return kinds
+}
+
+// Kind return the kind of the transaction, ErrUnknownTxType if the type or ErrTxPayloadVersion
+// if the payload version is not known by this node
+func (tx *Transaction) Kind() (*TxKind, error) {
+	kind, ok := LookupTxKind(tx.Type())
+	if !ok {
+		return nil, ErrUnknownTxType
+	}
+	for _, version := range kind.Versions {
+		if version == tx.Data.Version {
+			return kind, nil
+		}
+	}
+	return nil, ErrTxPayloadVersion
+}
+
+// DecodePayload return the decoded Data of the transaction, nil if its kind has no structured
+// payload
+func (tx *Transaction) DecodePayload() (interface{}, error) {
+	kind, err := tx.Kind()
+	if err != nil {
+		return nil, err
+	}
+	txKindsLock.RLock()
+	decode := kind.decode
+	txKindsLock.RUnlock()
+	if decode == nil {
+		return nil, nil
+	}
+	return decode(tx.Data.Version, tx.Data.Data)
+}
+
+func decodeAlias(version int32, data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+func decodeCandidate(version int32, data []byte) (interface{}, error) {
+	cd := &CandidateData{}
+	if err := cd.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return cd, nil
+}
+
+func decodeIssueToken(version int32, data []byte) (interface{}, error) {
+	issue := &IssueTokenData{}
+	if err := issue.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
+
+func decodeTokenAmount(version int32, data []byte) (interface{}, error) {
+	amount := &TokenAmountData{}
+	if err := amount.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return amount, nil
+}
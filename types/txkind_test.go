@@ -0,0 +1,44 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/drep-project/DREP-Chain/crypto"
+)
+
+func TestTxKind(t *testing.T) {
+	tx := NewAliasTransaction("alice", big.NewInt(1), big.NewInt(30000), 0)
+	if kind, err := tx.Kind(); err != nil || kind.Name != "setAlias" {
+		t.Fatalf("got kind %v, %v", kind, err)
+	}
+	if payload, err := tx.DecodePayload(); err != nil || payload != "alice" {
+		t.Fatalf("got payload %v, %v", payload, err)
+	}
+
+	tx.Data.Version = 7
+	if _, err := tx.Kind(); err != ErrTxPayloadVersion {
+		t.Fatalf("got %v, want %v", err, ErrTxPayloadVersion)
+	}
+	tx.Data.Version, tx.Data.Type = 1, MaxTxType
+	if _, err := tx.Kind(); err != ErrUnknownTxType {
+		t.Fatalf("got %v, want %v", err, ErrUnknownTxType)
+	}
+
+	// a kind introduced in a later protocol version is not relayed to older peers
+	RegisterTxKind(MaxTxType, &TxKind{Name: "future", Versions: []int32{1}, Protocol: ProtocolV1})
+	defer func() {
+		txKindsLock.Lock()
+		delete(txKinds, MaxTxType)
+		txKindsLock.Unlock()
+	}()
+	transfer := NewTransaction(crypto.CommonAddress{1}, big.NewInt(1), big.NewInt(1), big.NewInt(30000), 0)
+	peer := NewPeerInfo(nil, nil)
+	if peer.MatchTx(tx) || !peer.MatchTx(transfer) {
+		t.Fatal("protocol v0 peer must only match known kinds")
+	}
+	peer.SetVersion(ProtocolV1)
+	if !peer.MatchTx(tx) {
+		t.Fatal("protocol v1 peer must match the new kind")
+	}
+}
@@ -4,19 +4,63 @@ import "github.com/drep-project/DREP-Chain/crypto"
 
 //本模块的消息只能在调用本模块（chain及对应的子模块）的函数中使用
 const (
-	MsgTypeBlockReq     = 1 //同步块请求
-	MsgTypeBlockResp    = 2 //同步块回复
-	MsgTypeBlock        = 3 //新块通知
-	MsgTypeTransaction  = 4 //广播交易
-	MsgTypePeerState    = 5 //Peer状态回复/或者状态通知
-	MsgTypePeerStateReq = 6 //peer状态请求
-	MsgTypeHeaderReq    = 7 //请求区块头
-	MsgTypeHeaderRsp    = 8 //请求区块头回复
+	MsgTypeBlockReq      = 1  //同步块请求
+	MsgTypeBlockResp     = 2  //同步块回复
+	MsgTypeBlock         = 3  //新块通知
+	MsgTypeTransaction   = 4  //广播交易
+	MsgTypePeerState     = 5  //Peer状态回复/或者状态通知
+	MsgTypePeerStateReq  = 6  //peer状态请求
+	MsgTypeHeaderReq     = 7  //请求区块头
+	MsgTypeHeaderRsp     = 8  //请求区块头回复
+	MsgTypeBlockAnnounce = 9  //新块头通知
+	MsgTypeBlockBodyReq  = 10 //根据hash请求完整区块
+	MsgTypeFilterLoad    = 11 //轻节点设置地址过滤器
+	MsgTypeFilterClear   = 12 //轻节点清除地址过滤器
+	MsgTypeTxAnnounce    = 13 //新交易hash通知
+	MsgTypeTxReq         = 14 //根据hash请求交易
+	MsgTypePrivateTx     = 15 //只发给出块节点的交易
 
 	MaxMsgSize = 20 << 20 //每个消息最大大小20MB
 )
 
-var NumberOfMsg = 9 //本模块定义的消息个数
+var NumberOfMsg = 16 //本模块定义的消息个数
+
+// Versions of the blockMgr protocol, peers run the highest version both offer. A kind of
+// transaction is only relayed to peers running the version it was introduced in or later
+const (
+	ProtocolV0 uint = 0
+	ProtocolV1 uint = 1 //Transactions are checked against the registered kinds, see TxKind. Blocks are announced by header and light peers load address filters
+	ProtocolV2 uint = 2 //Transactions are announced by hash and pulled, see TxAnnounce
+	ProtocolV3 uint = 3 //Private transactions are sent to producers only, see MsgTypePrivateTx
+
+	ProtocolVersion = ProtocolV3
+)
+
+// msgVersions is the protocol version each message was introduced in, the messages not
+// listed are part of ProtocolV0. Every version keeps the codes of the older ones, so a
+// peer is sent the newer messages only if it negotiated their version
+var msgVersions = map[uint64]uint{
+	MsgTypeBlockAnnounce: ProtocolV1,
+	MsgTypeBlockBodyReq:  ProtocolV1,
+	MsgTypeFilterLoad:    ProtocolV1,
+	MsgTypeFilterClear:   ProtocolV1,
+	MsgTypeTxAnnounce:    ProtocolV2,
+	MsgTypeTxReq:         ProtocolV2,
+	MsgTypePrivateTx:     ProtocolV3,
+}
+
+// MsgVersion return the protocol version message code was introduced in
+func MsgVersion(code uint64) uint {
+	return msgVersions[code]
+}
+
+// MsgSupported whether a peer running protocol version knows message code
+func MsgSupported(version uint, code uint64) bool {
+	if code == 0 || code >= uint64(NumberOfMsg) {
+		return false
+	}
+	return version >= MsgVersion(code)
+}
 
 type Transactions []Transaction
 
@@ -39,6 +83,35 @@ type BlockResp struct {
 	Blocks []*Block
 }
 
+// BlockAnnounce announce a new block by its header, receiver request the body if it lack the block
+type BlockAnnounce struct {
+	Header BlockHeader
+}
+
+// BlockBodyReq request full blocks by hash, blocks are replied one by one as MsgTypeBlock
+type BlockBodyReq struct {
+	Hashes []crypto.Hash
+}
+
+// TxAnnounce announce new transactions by hash, receiver request the ones it lacks with TxReq
+type TxAnnounce struct {
+	Hashes []crypto.Hash
+}
+
+// TxReq request transactions by hash, the ones still in pool are replied as MsgTypeTransaction
+type TxReq struct {
+	Hashes []crypto.Hash
+}
+
+// FilterLoad registers an address bloom filter with the serving peer, only blocks and
+// transactions touching a matching address are announced to the sender afterwards
+type FilterLoad struct {
+	Filter Bloom
+}
+
+// FilterClear removes the address filter, the sender receives all announcements again
+type FilterClear struct{}
+
 type PeerState struct {
 	Height uint64
 }
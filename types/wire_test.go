@@ -0,0 +1,28 @@
+package types
+
+import "testing"
+
+func TestMsgSupported(t *testing.T) {
+	tests := []struct {
+		version uint
+		code    uint64
+		want    bool
+	}{
+		{ProtocolV0, MsgTypeBlock, true},
+		{ProtocolV0, MsgTypeBlockAnnounce, false},
+		{ProtocolV0, MsgTypeFilterLoad, false},
+		{ProtocolV1, MsgTypeBlockBodyReq, true},
+		{ProtocolV1, MsgTypeFilterClear, true},
+		{ProtocolV1, MsgTypeTxAnnounce, false},
+		{ProtocolV2, MsgTypeTxAnnounce, true},
+		{ProtocolV2, MsgTypePrivateTx, false},
+		{ProtocolV3, MsgTypePrivateTx, true},
+		{ProtocolVersion, 0, false},
+		{ProtocolVersion, uint64(NumberOfMsg), false},
+	}
+	for _, test := range tests {
+		if got := MsgSupported(test.version, test.code); got != test.want {
+			t.Errorf("version %d code %d: got %v, want %v", test.version, test.code, got, test.want)
+		}
+	}
+}